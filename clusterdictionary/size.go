@@ -12,6 +12,9 @@ import (
 const (
 	// SizeAlefDev is the definition of a cluster supporting dev purposes.
 	SizeAlefDev = "SizeAlefDev"
+	// SizeAlefDevSpot is the definition of a cluster supporting dev purposes
+	// running on spot instances.
+	SizeAlefDevSpot = "SizeAlefDevSpot"
 	// SizeAlef500 is the key representing a cluster supporting 500 users.
 	SizeAlef500 = "SizeAlef500"
 	// SizeAlef1000 is the key representing a cluster supporting 1000 users.
@@ -28,15 +31,18 @@ type size struct {
 	NodeInstanceType   string
 	NodeMinCount       int64
 	NodeMaxCount       int64
+
+	NodeMixedInstancesPolicy *model.MixedInstancesPolicyRequest
 }
 
 // ValidSizes is a mapping of a size keyword to kops cluster configuration.
 var ValidSizes = map[string]size{
-	SizeAlefDev:   sizeAlefDev,
-	SizeAlef500:   sizeAlef500,
-	SizeAlef1000:  sizeAlef1000,
-	SizeAlef5000:  sizeAlef5000,
-	SizeAlef10000: sizeAlef10000,
+	SizeAlefDev:     sizeAlefDev,
+	SizeAlefDevSpot: sizeAlefDevSpot,
+	SizeAlef500:     sizeAlef500,
+	SizeAlef1000:    sizeAlef1000,
+	SizeAlef5000:    sizeAlef5000,
+	SizeAlef10000:   sizeAlef10000,
 }
 
 // sizeAlefDev is a cluster sized for development and testing.
@@ -48,6 +54,20 @@ var sizeAlefDev = size{
 	NodeMaxCount:       2,
 }
 
+// sizeAlefDevSpot is a cluster sized for development and testing with the
+// worker nodes running on spot instances.
+var sizeAlefDevSpot = size{
+	MasterInstanceType: "t3.medium",
+	MasterCount:        1,
+	NodeInstanceType:   "t3.medium",
+	NodeMinCount:       2,
+	NodeMaxCount:       2,
+	NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{
+		Instances:      []string{"t3.medium", "t3a.medium", "t2.medium"},
+		SpotPercentage: 100,
+	},
+}
+
 // sizeAlef500 is a cluster sized for 500 users.
 var sizeAlef500 = size{
 	MasterInstanceType: "t3.medium",
@@ -107,6 +127,7 @@ func ApplyToCreateClusterRequest(size string, request *model.CreateClusterReques
 	request.NodeInstanceType = values.NodeInstanceType
 	request.NodeMinCount = values.NodeMinCount
 	request.NodeMaxCount = values.NodeMaxCount
+	request.NodeMixedInstancesPolicy = values.mixedInstancesPolicyRequest()
 
 	return nil
 }
@@ -126,6 +147,25 @@ func ApplyToPatchClusterSizeRequest(size string, request *model.PatchClusterSize
 	request.NodeInstanceType = &values.NodeInstanceType
 	request.NodeMinCount = &values.NodeMinCount
 	request.NodeMaxCount = &values.NodeMaxCount
+	request.NodeMixedInstancesPolicy = values.mixedInstancesPolicyRequest()
+	if request.NodeMixedInstancesPolicy == nil {
+		// Sizes without a mixed instances policy run on on-demand instances
+		// only, so remove any existing policy.
+		request.NodeMixedInstancesPolicy = &model.MixedInstancesPolicyRequest{}
+	}
 
 	return nil
 }
+
+// mixedInstancesPolicyRequest returns a copy of the mixed instances policy of
+// the size or nil if the size has none.
+func (s size) mixedInstancesPolicyRequest() *model.MixedInstancesPolicyRequest {
+	if s.NodeMixedInstancesPolicy == nil {
+		return nil
+	}
+
+	policy := *s.NodeMixedInstancesPolicy
+	policy.Instances = append([]string{}, s.NodeMixedInstancesPolicy.Instances...)
+
+	return &policy
+}
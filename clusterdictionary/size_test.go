@@ -18,6 +18,7 @@ func TestCheckSize(t *testing.T) {
 	}{
 		{"", false},
 		{"unknown", false},
+		{SizeAlefDevSpot, true},
 		{SizeAlef500, true},
 		{SizeAlef1000, true},
 	}
@@ -53,6 +54,20 @@ func TestApplyToCreateClusterRequest(t *testing.T) {
 				NodeMaxCount:       2,
 			},
 			false,
+		}, {
+			SizeAlefDevSpot,
+			&model.CreateClusterRequest{
+				MasterInstanceType: "t3.medium",
+				MasterCount:        1,
+				NodeInstanceType:   "t3.medium",
+				NodeMinCount:       2,
+				NodeMaxCount:       2,
+				NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{
+					Instances:      []string{"t3.medium", "t3a.medium", "t2.medium"},
+					SpotPercentage: 100,
+				},
+			},
+			false,
 		}, {
 			SizeAlef500,
 			&model.CreateClusterRequest{
@@ -126,42 +141,59 @@ func TestApplyToPatchClusterSizeRequest(t *testing.T) {
 			true,
 		}, {
 			SizeAlefDev,
+			&model.PatchClusterSizeRequest{
+				NodeInstanceType:         stringToPointer("t3.medium"),
+				NodeMinCount:             int64ToPointer(2),
+				NodeMaxCount:             int64ToPointer(2),
+				NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{},
+			},
+			false,
+		}, {
+			SizeAlefDevSpot,
 			&model.PatchClusterSizeRequest{
 				NodeInstanceType: stringToPointer("t3.medium"),
 				NodeMinCount:     int64ToPointer(2),
 				NodeMaxCount:     int64ToPointer(2),
+				NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{
+					Instances:      []string{"t3.medium", "t3a.medium", "t2.medium"},
+					SpotPercentage: 100,
+				},
 			},
 			false,
 		}, {
 			SizeAlef500,
 			&model.PatchClusterSizeRequest{
-				NodeInstanceType: stringToPointer("m5.large"),
-				NodeMinCount:     int64ToPointer(2),
-				NodeMaxCount:     int64ToPointer(2),
+				NodeInstanceType:         stringToPointer("m5.large"),
+				NodeMinCount:             int64ToPointer(2),
+				NodeMaxCount:             int64ToPointer(2),
+				NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{},
 			},
 			false,
 		}, {
 			SizeAlef1000,
 			&model.PatchClusterSizeRequest{
-				NodeInstanceType: stringToPointer("m5.large"),
-				NodeMinCount:     int64ToPointer(4),
-				NodeMaxCount:     int64ToPointer(4),
+				NodeInstanceType:         stringToPointer("m5.large"),
+				NodeMinCount:             int64ToPointer(4),
+				NodeMaxCount:             int64ToPointer(4),
+				NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{},
 			},
 			false,
 		}, {
 			SizeAlef5000,
 			&model.PatchClusterSizeRequest{
-				NodeInstanceType: stringToPointer("m5.large"),
-				NodeMinCount:     int64ToPointer(6),
-				NodeMaxCount:     int64ToPointer(6),
+				NodeInstanceType:         stringToPointer("m5.large"),
+				NodeMinCount:             int64ToPointer(6),
+				NodeMaxCount:             int64ToPointer(6),
+				NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{},
 			},
 			false,
 		}, {
 			SizeAlef10000,
 			&model.PatchClusterSizeRequest{
-				NodeInstanceType: stringToPointer("m5.large"),
-				NodeMinCount:     int64ToPointer(10),
-				NodeMaxCount:     int64ToPointer(10),
+				NodeInstanceType:         stringToPointer("m5.large"),
+				NodeMinCount:             int64ToPointer(10),
+				NodeMaxCount:             int64ToPointer(10),
+				NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{},
 			},
 			false,
 		},
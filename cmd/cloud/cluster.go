@@ -7,6 +7,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -30,21 +31,54 @@ func init() {
 	clusterCreateCmd.Flags().Int64("size-master-count", 0, "The number of k8s master nodes. Overwrites value from 'size'.")
 	clusterCreateCmd.Flags().String("size-node-instance-type", "", "The instance type describing the k8s worker nodes. Overwrites value from 'size'.")
 	clusterCreateCmd.Flags().Int64("size-node-count", 0, "The number of k8s worker nodes. Overwrites value from 'size'.")
-	clusterCreateCmd.Flags().String("zones", "us-east-1a", "The zones where the cluster will be deployed. Use commas to separate multiple zones.")
+	clusterCreateCmd.Flags().Bool("autoscaling", false, "Scale the k8s worker nodes with the cluster-autoscaler between the node count and the max node count.")
+	clusterCreateCmd.Flags().Int64("size-node-max-count", 0, "The max number of k8s worker nodes when autoscaling. Requires autoscaling.")
+	clusterCreateCmd.Flags().StringArray("node-group", []string{}, "Additional k8s worker node groups. Accepts format: NAME=INSTANCE_TYPE:MIN_COUNT[:MAX_COUNT]. Use the flag multiple times to add multiple node groups.")
+	clusterCreateCmd.Flags().StringSlice("node-spot-instance-types", []string{}, "Run the k8s worker nodes with a mixed instances policy of these instance types. Use commas to separate multiple instance types. Overwrites value from 'size'.")
+	clusterCreateCmd.Flags().Int64("node-spot-percentage", 100, "The percentage of k8s worker nodes above the on-demand base that are spot instances. Requires node-spot-instance-types.")
+	clusterCreateCmd.Flags().Int64("node-on-demand-base", 0, "The number of k8s worker nodes that are always on-demand instances. Requires node-spot-instance-types.")
+	clusterCreateCmd.Flags().String("zones", "us-east-1a", "The zones where the cluster will be deployed. Use commas to separate multiple zones. EKS clusters require at least two zones and default to 'us-east-1a,us-east-1b'.")
+	clusterCreateCmd.Flags().String("region", "", "The AWS region where the cluster will be deployed. Defaults to the region of the provisioning server. The zones default to the first zones of the region.")
+	clusterCreateCmd.Flags().String("assume-role-arn", "", "The ARN of an IAM role to assume to deploy the cluster in another AWS account.")
+	clusterCreateCmd.Flags().String("provisioner", model.ProvisionerKops, "The provisioner managing the cluster. Accepts 'kops' or 'eks'.")
 	clusterCreateCmd.Flags().Bool("allow-installations", true, "Whether the cluster will allow for new installations to be scheduled.")
 	clusterCreateCmd.Flags().String("prometheus-operator-version", model.PrometheusOperatorDefaultVersion, "The version of Prometheus Operator to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("thanos-version", model.ThanosDefaultVersion, "The version of Thanos to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("fluentbit-version", model.FluentbitDefaultVersion, "The version of Fluentbit to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("nginx-version", model.NginxDefaultVersion, "The version of Nginx to provision. Use 'stable' to provision the latest stable version published upstream.")
 	clusterCreateCmd.Flags().String("teleport-version", model.TeleportDefaultVersion, "The version of Teleport to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterCreateCmd.Flags().String("velero-version", model.VeleroDefaultVersion, "The version of Velero to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterCreateCmd.Flags().String("cert-manager-version", model.CertManagerDefaultVersion, "The version of cert-manager to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterCreateCmd.Flags().String("cluster-autoscaler-version", model.ClusterAutoscalerDefaultVersion, "The version of cluster-autoscaler to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterCreateCmd.Flags().String("aws-node-termination-handler-version", model.NodeTerminationHandlerDefaultVersion, "The version of aws-node-termination-handler to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterCreateCmd.Flags().StringArray("utility-values", []string{}, "Helm values files overriding the default values of a utility. Accepts format: UTILITY=PATH. Use the flag multiple times to override multiple utilities.")
 	clusterCreateCmd.Flags().StringArray("annotation", []string{}, "Additional annotations for the cluster. Accepts multiple values, for example: '... --annotation abc --annotation def'")
 
+	clusterImportCmd.Flags().String("kops-name", "", "The kops name of an existing kops cluster to import.")
+	clusterImportCmd.Flags().String("kops-state-store", "", "The kops state store of the imported kops cluster. Leave empty for the state store of the provisioning server.")
+	clusterImportCmd.Flags().String("kubeconfig", "", "The path to a kubeconfig file of an existing cluster to import.")
+	clusterImportCmd.Flags().Bool("allow-installations", true, "Whether the cluster will allow for new installations to be scheduled.")
+	clusterImportCmd.Flags().String("prometheus-operator-version", model.PrometheusOperatorDefaultVersion, "The version of Prometheus Operator to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("thanos-version", model.ThanosDefaultVersion, "The version of Thanos to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("fluentbit-version", model.FluentbitDefaultVersion, "The version of Fluentbit to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("nginx-version", model.NginxDefaultVersion, "The version of Nginx to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("teleport-version", model.TeleportDefaultVersion, "The version of Teleport to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("velero-version", model.VeleroDefaultVersion, "The version of Velero to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("cert-manager-version", model.CertManagerDefaultVersion, "The version of cert-manager to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("cluster-autoscaler-version", model.ClusterAutoscalerDefaultVersion, "The version of cluster-autoscaler to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().String("aws-node-termination-handler-version", model.NodeTerminationHandlerDefaultVersion, "The version of aws-node-termination-handler to provision. Use 'stable' to provision the latest stable version published upstream.")
+	clusterImportCmd.Flags().StringArray("annotation", []string{}, "Additional annotations for the cluster. Accepts multiple values, for example: '... --annotation abc --annotation def'")
+
 	clusterProvisionCmd.Flags().String("cluster", "", "The id of the cluster to be provisioned.")
 	clusterProvisionCmd.Flags().String("prometheus-operator-version", "", "The version of Prometheus Operator to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
 	clusterProvisionCmd.Flags().String("thanos-version", "", "The version of Thanos to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
 	clusterProvisionCmd.Flags().String("fluentbit-version", "", "The version of Fluentbit to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
 	clusterProvisionCmd.Flags().String("nginx-version", "", "The version of Nginx to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
 	clusterProvisionCmd.Flags().String("teleport-version", "", "The version of Teleport to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
+	clusterProvisionCmd.Flags().String("velero-version", "", "The version of Velero to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
+	clusterProvisionCmd.Flags().String("cert-manager-version", "", "The version of cert-manager to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
+	clusterProvisionCmd.Flags().String("cluster-autoscaler-version", "", "The version of cluster-autoscaler to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
+	clusterProvisionCmd.Flags().String("aws-node-termination-handler-version", "", "The version of aws-node-termination-handler to provision, no change if omitted. Use \"stable\" as an argument to this command to indicate that you wish to remove the pinned version and return the utility to tracking the latest version.")
 	clusterProvisionCmd.MarkFlagRequired("cluster")
 
 	clusterUpdateCmd.Flags().String("cluster", "", "The id of the cluster to be updated.")
@@ -54,13 +88,31 @@ func init() {
 	clusterUpgradeCmd.Flags().String("cluster", "", "The id of the cluster to be upgraded.")
 	clusterUpgradeCmd.Flags().String("version", "", "The Kubernetes version to target. Use 'latest' or versions such as '1.16.10'.")
 	clusterUpgradeCmd.Flags().String("kops-ami", "", "The AMI to use for the cluster hosts. Use 'latest' for the default kops image.")
+	clusterUpgradeCmd.Flags().Bool("preview", false, "When set to true, prints the kops and terraform changes the upgrade would make without applying them.")
+	clusterUpgradeCmd.Flags().Bool("staged", false, "When set to true, rolls the instance groups in stages starting with a canary node group and pauses the upgrade if the cluster health degrades.")
+	clusterUpgradeCmd.Flags().Int64("max-surge", 0, "The number of extra instances each instance group can launch during a staged upgrade. Requires staged.")
+	clusterUpgradeCmd.Flags().Int64("batch-size", 1, "The number of instance groups rolled in each stage of a staged upgrade. Requires staged.")
+	clusterUpgradeCmd.Flags().Int64("pause-seconds", 0, "The number of seconds to wait between the stages of a staged upgrade. Requires staged.")
 	clusterUpgradeCmd.MarkFlagRequired("cluster")
 
+	clusterUpgradeResumeCmd.Flags().String("cluster", "", "The id of the cluster whose paused upgrade is resumed.")
+	clusterUpgradeResumeCmd.MarkFlagRequired("cluster")
+
+	clusterUpgradeAbortCmd.Flags().String("cluster", "", "The id of the cluster whose paused or failed upgrade is aborted.")
+	clusterUpgradeAbortCmd.MarkFlagRequired("cluster")
+
 	clusterResizeCmd.Flags().String("cluster", "", "The id of the cluster to be resized.")
 	clusterResizeCmd.Flags().String("size", "", "The size constant describing the cluster")
 	clusterResizeCmd.Flags().String("size-node-instance-type", "", "The instance type describing the k8s worker nodes. Overwrites value from 'size'.")
 	clusterResizeCmd.Flags().Int64("size-node-min-count", 0, "The minimum number of k8s worker nodes. Overwrites value from 'size'.")
 	clusterResizeCmd.Flags().Int64("size-node-max-count", 0, "The maximum number of k8s worker nodes. Overwrites value from 'size'.")
+	clusterResizeCmd.Flags().StringArray("node-group", []string{}, "The complete list of additional k8s worker node groups; node groups not listed are deleted. Accepts format: NAME=INSTANCE_TYPE:MIN_COUNT[:MAX_COUNT]. Use the flag multiple times to set multiple node groups.")
+	clusterResizeCmd.Flags().Bool("node-group-clear", false, "When set to true, deletes all additional k8s worker node groups.")
+	clusterResizeCmd.Flags().StringSlice("node-spot-instance-types", []string{}, "Run the k8s worker nodes with a mixed instances policy of these instance types. Use commas to separate multiple instance types. Overwrites value from 'size'.")
+	clusterResizeCmd.Flags().Int64("node-spot-percentage", 100, "The percentage of k8s worker nodes above the on-demand base that are spot instances. Requires node-spot-instance-types.")
+	clusterResizeCmd.Flags().Int64("node-on-demand-base", 0, "The number of k8s worker nodes that are always on-demand instances. Requires node-spot-instance-types.")
+	clusterResizeCmd.Flags().Bool("node-spot-clear", false, "When set to true, removes the mixed instances policy so that all k8s worker nodes are on-demand instances.")
+	clusterResizeCmd.Flags().Bool("preview", false, "When set to true, prints the kops and terraform changes the resize would make without applying them.")
 	clusterResizeCmd.MarkFlagRequired("cluster")
 
 	clusterDeleteCmd.Flags().String("cluster", "", "The id of the cluster to be deleted.")
@@ -77,7 +129,11 @@ func init() {
 	clusterUtilitiesCmd.Flags().String("cluster", "", "The id of the cluster whose utilities are to be fetched.")
 	clusterUtilitiesCmd.MarkFlagRequired("cluster")
 
+	clusterUpgradeCmd.AddCommand(clusterUpgradeResumeCmd)
+	clusterUpgradeCmd.AddCommand(clusterUpgradeAbortCmd)
+
 	clusterCmd.AddCommand(clusterCreateCmd)
+	clusterCmd.AddCommand(clusterImportCmd)
 	clusterCmd.AddCommand(clusterProvisionCmd)
 	clusterCmd.AddCommand(clusterUpdateCmd)
 	clusterCmd.AddCommand(clusterUpgradeCmd)
@@ -90,6 +146,7 @@ func init() {
 	clusterCmd.AddCommand(clusterUtilitiesCmd)
 	clusterCmd.AddCommand(clusterShowSizeDictionary)
 	clusterCmd.AddCommand(clusterAnnotationCmd)
+	clusterCmd.AddCommand(clusterBackupCmd)
 }
 
 var clusterCmd = &cobra.Command{
@@ -103,6 +160,24 @@ func printJSON(data interface{}) error {
 	return encoder.Encode(data)
 }
 
+// printClusterChangePreview prints the requested changes followed by the kops
+// and terraform output describing them.
+func printClusterChangePreview(preview *model.ClusterChangePreview) error {
+	if !preview.HasChanges() {
+		fmt.Printf("The request makes no changes to cluster %s\n", preview.ClusterID)
+		return nil
+	}
+
+	err := printJSON(preview.ChangeRequest)
+	if err != nil {
+		return errors.Wrap(err, "failed to print change request")
+	}
+	fmt.Printf("\nkops update cluster preview:\n%s\n", preview.KopsUpdate)
+	fmt.Printf("\nterraform plan:\n%s\n", preview.TerraformPlan)
+
+	return nil
+}
+
 var clusterCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a cluster.",
@@ -118,6 +193,9 @@ var clusterCreateCmd = &cobra.Command{
 		zones, _ := command.Flags().GetString("zones")
 		allowInstallations, _ := command.Flags().GetBool("allow-installations")
 		annotations, _ := command.Flags().GetStringArray("annotation")
+		clusterProvisioner, _ := command.Flags().GetString("provisioner")
+		region, _ := command.Flags().GetString("region")
+		assumeRoleARN, _ := command.Flags().GetString("assume-role-arn")
 
 		request := &model.CreateClusterRequest{
 			Provider:               provider,
@@ -127,6 +205,13 @@ var clusterCreateCmd = &cobra.Command{
 			AllowInstallations:     allowInstallations,
 			DesiredUtilityVersions: processUtilityFlags(command),
 			Annotations:            annotations,
+			Provisioner:            clusterProvisioner,
+			Region:                 region,
+			AssumeRoleARN:          assumeRoleARN,
+		}
+		if (clusterProvisioner == model.ProvisionerEKS || len(region) != 0) && !command.Flags().Changed("zones") {
+			// Let the server pick the default zones of the provisioner and region.
+			request.Zones = nil
 		}
 
 		size, _ := command.Flags().GetString("size")
@@ -148,11 +233,37 @@ var clusterCreateCmd = &cobra.Command{
 		}
 		nodeCount, _ := command.Flags().GetInt64("size-node-count")
 		if nodeCount != 0 {
-			// Setting different min and max counts in currently not supported
-			// with the kops create cluster flag.
+			// Different min and max counts are only supported with
+			// autoscaling, which sets the max count separately.
 			request.NodeMinCount = nodeCount
 			request.NodeMaxCount = nodeCount
 		}
+		request.Autoscaling, _ = command.Flags().GetBool("autoscaling")
+		nodeMaxCount, _ := command.Flags().GetInt64("size-node-max-count")
+		if nodeMaxCount != 0 {
+			if !request.Autoscaling {
+				return errors.New("size-node-max-count requires autoscaling")
+			}
+			request.NodeMaxCount = nodeMaxCount
+		}
+		nodeGroupInput, _ := command.Flags().GetStringArray("node-group")
+		request.NodeGroups, err = parseNodeGroupInput(nodeGroupInput, false)
+		if err != nil {
+			return err
+		}
+		request.NodeMixedInstancesPolicy, err = processMixedInstancesPolicyFlags(command, request.NodeMixedInstancesPolicy, false)
+		if err != nil {
+			return err
+		}
+		if request.NodeMixedInstancesPolicy != nil && len(request.NodeMixedInstancesPolicy.Instances) != 0 &&
+			!command.Flags().Changed("size-node-instance-type") {
+			// The first instance type of the policy is the node machine type.
+			request.NodeInstanceType = request.NodeMixedInstancesPolicy.Instances[0]
+		}
+		request.UtilityValuesOverrides, err = processUtilityValuesFlags(command)
+		if err != nil {
+			return err
+		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
 		if dryRun {
@@ -178,6 +289,61 @@ var clusterCreateCmd = &cobra.Command{
 	},
 }
 
+var clusterImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import an existing cluster that was created outside of the provisioner.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		kopsName, _ := command.Flags().GetString("kops-name")
+		kopsStateStore, _ := command.Flags().GetString("kops-state-store")
+		kubeconfigPath, _ := command.Flags().GetString("kubeconfig")
+		allowInstallations, _ := command.Flags().GetBool("allow-installations")
+		annotations, _ := command.Flags().GetStringArray("annotation")
+
+		request := &model.ImportClusterRequest{
+			KopsName:               kopsName,
+			KopsStateStore:         kopsStateStore,
+			AllowInstallations:     allowInstallations,
+			DesiredUtilityVersions: processUtilityFlags(command),
+			Annotations:            annotations,
+		}
+
+		if len(kubeconfigPath) != 0 {
+			kubeconfig, err := ioutil.ReadFile(kubeconfigPath)
+			if err != nil {
+				return errors.Wrap(err, "failed to read kubeconfig")
+			}
+			request.Kubeconfig = string(kubeconfig)
+		}
+
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		if dryRun {
+			err := printJSON(request)
+			if err != nil {
+				return errors.Wrap(err, "failed to print API request")
+			}
+
+			return nil
+		}
+
+		cluster, err := client.ImportCluster(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to import cluster")
+		}
+
+		err = printJSON(cluster)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster response")
+		}
+
+		return nil
+	},
+}
+
 var clusterProvisionCmd = &cobra.Command{
 	Use:   "provision",
 	Short: "Provision/Reprovision a cluster's k8s resources.",
@@ -275,6 +441,18 @@ var clusterUpgradeCmd = &cobra.Command{
 			KopsAMI: getStringFlagPointer(command, "kops-ami"),
 		}
 
+		staged, _ := command.Flags().GetBool("staged")
+		if staged {
+			maxSurge, _ := command.Flags().GetInt64("max-surge")
+			batchSize, _ := command.Flags().GetInt64("batch-size")
+			pauseSeconds, _ := command.Flags().GetInt64("pause-seconds")
+			request.Strategy = &model.UpgradeStrategyRequest{
+				MaxSurge:     maxSurge,
+				BatchSize:    batchSize,
+				PauseSeconds: pauseSeconds,
+			}
+		}
+
 		dryRun, _ := command.Flags().GetBool("dry-run")
 		if dryRun {
 			err := printJSON(request)
@@ -285,6 +463,16 @@ var clusterUpgradeCmd = &cobra.Command{
 			return nil
 		}
 
+		preview, _ := command.Flags().GetBool("preview")
+		if preview {
+			clusterChangePreview, err := client.PreviewUpgradeCluster(clusterID, request)
+			if err != nil {
+				return errors.Wrap(err, "failed to preview cluster upgrade")
+			}
+
+			return printClusterChangePreview(clusterChangePreview)
+		}
+
 		cluster, err := client.UpgradeCluster(clusterID, request)
 		if err != nil {
 			return errors.Wrap(err, "failed to upgrade cluster")
@@ -299,6 +487,56 @@ var clusterUpgradeCmd = &cobra.Command{
 	},
 }
 
+var clusterUpgradeResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume a paused staged upgrade of a cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+
+		cluster, err := client.ResumeClusterUpgrade(clusterID)
+		if err != nil {
+			return errors.Wrap(err, "failed to resume cluster upgrade")
+		}
+
+		err = printJSON(cluster)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster response")
+		}
+
+		return nil
+	},
+}
+
+var clusterUpgradeAbortCmd = &cobra.Command{
+	Use:   "abort",
+	Short: "Abort a paused or failed staged upgrade of a cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+
+		cluster, err := client.AbortClusterUpgrade(clusterID)
+		if err != nil {
+			return errors.Wrap(err, "failed to abort cluster upgrade")
+		}
+
+		err = printJSON(cluster)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster response")
+		}
+
+		return nil
+	},
+}
+
 var clusterResizeCmd = &cobra.Command{
 	Use:   "resize",
 	Short: "Resize a k8s cluster",
@@ -329,6 +567,22 @@ var clusterResizeCmd = &cobra.Command{
 		if nodeMaxCount != 0 {
 			request.NodeMaxCount = &nodeMaxCount
 		}
+		nodeGroupInput, _ := command.Flags().GetStringArray("node-group")
+		nodeGroupClear, _ := command.Flags().GetBool("node-group-clear")
+		request.NodeGroups, err = parseNodeGroupInput(nodeGroupInput, nodeGroupClear)
+		if err != nil {
+			return err
+		}
+		nodeSpotClear, _ := command.Flags().GetBool("node-spot-clear")
+		request.NodeMixedInstancesPolicy, err = processMixedInstancesPolicyFlags(command, request.NodeMixedInstancesPolicy, nodeSpotClear)
+		if err != nil {
+			return err
+		}
+		if request.NodeInstanceType != nil && request.NodeMixedInstancesPolicy != nil &&
+			len(request.NodeMixedInstancesPolicy.Instances) != 0 && !command.Flags().Changed("size-node-instance-type") {
+			// The first instance type of the policy is the node machine type.
+			request.NodeInstanceType = &request.NodeMixedInstancesPolicy.Instances[0]
+		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
 		if dryRun {
@@ -340,6 +594,16 @@ var clusterResizeCmd = &cobra.Command{
 			return nil
 		}
 
+		preview, _ := command.Flags().GetBool("preview")
+		if preview {
+			clusterChangePreview, err := client.PreviewResizeCluster(clusterID, request)
+			if err != nil {
+				return errors.Wrap(err, "failed to preview cluster resize")
+			}
+
+			return printClusterChangePreview(clusterChangePreview)
+		}
+
 		cluster, err := client.ResizeCluster(clusterID, request)
 		if err != nil {
 			return errors.Wrap(err, "failed to resize cluster")
@@ -429,6 +693,26 @@ var clusterListCmd = &cobra.Command{
 			table.SetHeader([]string{"ID", "STATE", "VERSION", "MASTER NODES", "WORKER NODES"})
 
 			for _, cluster := range clusters {
+				if cluster.ProvisionerMetadataEKS != nil {
+					table.Append([]string{
+						cluster.ID,
+						cluster.State,
+						cluster.ProvisionerMetadataEKS.Version,
+						"managed",
+						fmt.Sprintf("%d x %s (max %d)", cluster.ProvisionerMetadataEKS.NodeMinCount, cluster.ProvisionerMetadataEKS.NodeInstanceType, cluster.ProvisionerMetadataEKS.NodeMaxCount),
+					})
+					continue
+				}
+				if cluster.ProvisionerMetadataExternal != nil {
+					table.Append([]string{
+						cluster.ID,
+						cluster.State,
+						cluster.ProvisionerMetadataExternal.Version,
+						"external",
+						"external",
+					})
+					continue
+				}
 				table.Append([]string{
 					cluster.ID,
 					cluster.State,
@@ -517,6 +801,10 @@ func processUtilityFlags(command *cobra.Command) map[string]string {
 	fluentbitVersion, _ := command.Flags().GetString("fluentbit-version")
 	nginxVersion, _ := command.Flags().GetString("nginx-version")
 	teleportVersion, _ := command.Flags().GetString("teleport-version")
+	veleroVersion, _ := command.Flags().GetString("velero-version")
+	certManagerVersion, _ := command.Flags().GetString("cert-manager-version")
+	clusterAutoscalerVersion, _ := command.Flags().GetString("cluster-autoscaler-version")
+	nodeTerminationHandlerVersion, _ := command.Flags().GetString("aws-node-termination-handler-version")
 
 	utilityVersions := make(map[string]string)
 
@@ -540,5 +828,59 @@ func processUtilityFlags(command *cobra.Command) map[string]string {
 		utilityVersions[model.TeleportCanonicalName] = teleportVersion
 	}
 
+	if veleroVersion != "" {
+		utilityVersions[model.VeleroCanonicalName] = veleroVersion
+	}
+
+	if certManagerVersion != "" {
+		utilityVersions[model.CertManagerCanonicalName] = certManagerVersion
+	}
+
+	if clusterAutoscalerVersion != "" {
+		utilityVersions[model.ClusterAutoscalerCanonicalName] = clusterAutoscalerVersion
+	}
+
+	if nodeTerminationHandlerVersion != "" {
+		utilityVersions[model.NodeTerminationHandlerCanonicalName] = nodeTerminationHandlerVersion
+	}
+
 	return utilityVersions
 }
+
+// processMixedInstancesPolicyFlags applies the mixed instances policy flags to
+// the policy from the cluster size. A clear request returns a policy without
+// instance types, which removes the existing policy.
+func processMixedInstancesPolicyFlags(command *cobra.Command, policy *model.MixedInstancesPolicyRequest, clear bool) (*model.MixedInstancesPolicyRequest, error) {
+	instancesSet := command.Flags().Changed("node-spot-instance-types")
+	spotPercentageSet := command.Flags().Changed("node-spot-percentage")
+	onDemandBaseSet := command.Flags().Changed("node-on-demand-base")
+
+	if clear {
+		if instancesSet || spotPercentageSet || onDemandBaseSet {
+			return nil, errors.New("both node-spot-clear and mixed instances policy flags were set; use one or the other")
+		}
+		return &model.MixedInstancesPolicyRequest{}, nil
+	}
+	if !instancesSet && !spotPercentageSet && !onDemandBaseSet {
+		return policy, nil
+	}
+
+	if policy == nil || len(policy.Instances) == 0 {
+		if !instancesSet {
+			return nil, errors.New("node-spot-instance-types is required to set a mixed instances policy")
+		}
+		policy = &model.MixedInstancesPolicyRequest{}
+		policy.SpotPercentage, _ = command.Flags().GetInt64("node-spot-percentage")
+	}
+	if instancesSet {
+		policy.Instances, _ = command.Flags().GetStringSlice("node-spot-instance-types")
+	}
+	if spotPercentageSet {
+		policy.SpotPercentage, _ = command.Flags().GetInt64("node-spot-percentage")
+	}
+	if onDemandBaseSet {
+		policy.OnDemandBase, _ = command.Flags().GetInt64("node-on-demand-base")
+	}
+
+	return policy, nil
+}
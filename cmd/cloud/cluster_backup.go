@@ -0,0 +1,88 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	clusterBackupCreateCmd.Flags().String("cluster", "", "The id of the cluster to be backed up.")
+	clusterBackupCreateCmd.Flags().StringArray("namespace", []string{}, "The namespaces to back up. Backs up all namespaces if omitted. Accepts multiple values, for example: '... --namespace abc --namespace def'")
+	clusterBackupCreateCmd.MarkFlagRequired("cluster")
+
+	clusterBackupListCmd.Flags().String("cluster", "", "The id of the cluster whose backups should be listed.")
+	clusterBackupListCmd.MarkFlagRequired("cluster")
+
+	clusterBackupCmd.AddCommand(clusterBackupCreateCmd)
+	clusterBackupCmd.AddCommand(clusterBackupListCmd)
+}
+
+var clusterBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manipulate Velero backups of clusters managed by the provisioning server.",
+}
+
+var clusterBackupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Triggers a backup of the Kubernetes state of the cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+		namespaces, _ := command.Flags().GetStringArray("namespace")
+
+		request := &model.CreateClusterBackupRequest{
+			Namespaces: namespaces,
+		}
+
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		if dryRun {
+			return runDryRun(request)
+		}
+
+		backup, err := client.CreateClusterBackup(clusterID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to create cluster backup")
+		}
+
+		err = printJSON(backup)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster backup response")
+		}
+
+		return nil
+	},
+}
+
+var clusterBackupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the backups of the cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+
+		backups, err := client.GetClusterBackups(clusterID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get cluster backups")
+		}
+
+		err = printJSON(backups)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster backups")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	clusterUtilitiesUpdateCmd.Flags().String("cluster", "", "The id of the cluster whose utilities are to be updated.")
+	clusterUtilitiesUpdateCmd.Flags().StringArray("utility-values", []string{}, "Helm values files overriding the default values of a utility. Accepts format: UTILITY=PATH. Leave the path empty to remove the override of a utility. Use the flag multiple times to update multiple utilities.")
+	clusterUtilitiesUpdateCmd.MarkFlagRequired("cluster")
+	clusterUtilitiesUpdateCmd.MarkFlagRequired("utility-values")
+
+	clusterUtilitiesUpgradeCmd.Flags().String("cluster", "", "The id of the cluster whose utility is to be upgraded.")
+	clusterUtilitiesUpgradeCmd.Flags().String("utility", "", "The name of the utility to upgrade or roll back.")
+	clusterUtilitiesUpgradeCmd.Flags().String("version", "", "The version of the utility chart to deploy. Use 'stable' to track the default version.")
+	clusterUtilitiesUpgradeCmd.MarkFlagRequired("cluster")
+	clusterUtilitiesUpgradeCmd.MarkFlagRequired("utility")
+	clusterUtilitiesUpgradeCmd.MarkFlagRequired("version")
+
+	clusterUtilitiesCmd.AddCommand(clusterUtilitiesUpdateCmd)
+	clusterUtilitiesCmd.AddCommand(clusterUtilitiesUpgradeCmd)
+	clusterUtilitiesCmd.AddCommand(clusterUtilitiesDriftCmd)
+}
+
+var clusterUtilitiesUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the Helm values of utilities running in a cluster and reprovision the cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+
+		valuesOverrides, err := processUtilityValuesFlags(command)
+		if err != nil {
+			return err
+		}
+
+		request := &model.UpdateClusterUtilitiesRequest{
+			ValuesOverrides: valuesOverrides,
+		}
+
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		if dryRun {
+			return runDryRun(request)
+		}
+
+		cluster, err := client.UpdateClusterUtilities(clusterID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to update cluster utilities")
+		}
+
+		err = printJSON(cluster)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster utilities response")
+		}
+
+		return nil
+	},
+}
+
+var clusterUtilitiesUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade or roll back a single utility running in a cluster without reprovisioning the cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		clusterID, _ := command.Flags().GetString("cluster")
+		utility, _ := command.Flags().GetString("utility")
+		version, _ := command.Flags().GetString("version")
+
+		request := &model.UpgradeClusterUtilityRequest{
+			Version: version,
+		}
+
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		if dryRun {
+			return runDryRun(request)
+		}
+
+		cluster, err := client.UpgradeClusterUtility(clusterID, utility, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to upgrade cluster utility")
+		}
+
+		err = printJSON(cluster)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster utility upgrade response")
+		}
+
+		return nil
+	},
+}
+
+var clusterUtilitiesDriftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "List the clusters whose utilities drifted from the versions and values deployed by the provisioner.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		report, err := client.GetClusterUtilityDrift()
+		if err != nil {
+			return errors.Wrap(err, "failed to get cluster utility drift")
+		}
+
+		err = printJSON(report)
+		if err != nil {
+			return errors.Wrap(err, "failed to print cluster utility drift")
+		}
+
+		return nil
+	},
+}
+
+// processUtilityValuesFlags reads the Helm values files of the utility-values
+// flags, keyed by utility name. An empty path maps to empty values.
+func processUtilityValuesFlags(command *cobra.Command) (map[string]string, error) {
+	utilityValues, _ := command.Flags().GetStringArray("utility-values")
+	if len(utilityValues) == 0 {
+		return nil, nil
+	}
+
+	valuesOverrides := make(map[string]string)
+	for _, input := range utilityValues {
+		parts := strings.SplitN(input, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, errors.Errorf("utility values %q must have format UTILITY=PATH", input)
+		}
+
+		utility, path := parts[0], parts[1]
+		if len(path) == 0 {
+			valuesOverrides[utility] = ""
+			continue
+		}
+
+		values, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read values of utility %s", utility)
+		}
+		valuesOverrides[utility] = string(values)
+	}
+
+	return valuesOverrides, nil
+}
@@ -18,7 +18,10 @@ func init() {
 	databaseListCmd.Flags().Int("page", 0, "The page of databases to fetch, starting at 0.")
 	databaseListCmd.Flags().Int("per-page", 100, "The number of databases to fetch per page.")
 
+	databaseReconcileCmd.Flags().Bool("repair", false, "Whether to repair the inconsistencies found instead of only reporting them. Resources of installations unknown to this server are never repaired.")
+
 	databaseCmd.AddCommand(databaseListCmd)
+	databaseCmd.AddCommand(databaseReconcileCmd)
 }
 
 var databaseCmd = &cobra.Command{
@@ -57,3 +60,29 @@ var databaseListCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var databaseReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile multitenant databases against their AWS resources.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		repair, _ := command.Flags().GetBool("repair")
+		report, err := client.ReconcileMultitenantDatabases(&model.ReconcileMultitenantDatabasesRequest{
+			Repair: repair,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to reconcile databases")
+		}
+
+		err = printJSON(report)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
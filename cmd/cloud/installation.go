@@ -5,7 +5,9 @@
 package main
 
 import (
+	"encoding/csv"
 	"os"
+	"strconv"
 
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/olekukonko/tablewriter"
@@ -24,14 +26,26 @@ func init() {
 	installationCreateCmd.Flags().String("dns", "", "The URL at which the Mattermost server will be available.")
 	installationCreateCmd.Flags().String("size", model.InstallationDefaultSize, "The size of the installation. Accepts 100users, 1000users, 5000users, 10000users, 25000users, miniSingleton, or miniHA. Defaults to 100users.")
 	installationCreateCmd.Flags().String("affinity", model.InstallationAffinityIsolated, "How other installations may be co-located in the same cluster.")
+	installationCreateCmd.Flags().String("node-group", "", "The name of the cluster node group the installation will be scheduled on. Uses the default worker nodes if empty.")
 	installationCreateCmd.Flags().String("license", "", "The Mattermost License to use in the server.")
-	installationCreateCmd.Flags().String("database", model.InstallationDatabaseMysqlOperator, "The Mattermost server database type. Accepts mysql-operator, aws-rds, aws-rds-postgres, or aws-multitenant-rds")
-	installationCreateCmd.Flags().String("filestore", model.InstallationFilestoreMinioOperator, "The Mattermost server filestore type. Accepts minio-operator or aws-s3")
+	installationCreateCmd.Flags().String("database", model.InstallationDatabaseMysqlOperator, "The Mattermost server database type. Accepts mysql-operator, aws-rds, aws-rds-postgres, aws-multitenant-rds, or external")
+	installationCreateCmd.Flags().String("filestore", model.InstallationFilestoreMinioOperator, "The Mattermost server filestore type. Accepts minio-operator, aws-s3, or external")
 	installationCreateCmd.Flags().StringArray("mattermost-env", []string{}, "Env vars to add to the Mattermost App. Accepts format: KEY_NAME=VALUE. Use the flag multiple times to set multiple env vars.")
 	installationCreateCmd.Flags().StringArray("annotation", []string{}, "Additional annotations for the installation. Accepts multiple values, for example: '... --annotation abc --annotation def'")
 	installationCreateCmd.Flags().String("rds-primary-instance", "", "The machine instance type used for primary replica of database cluster. Works only with single tenant RDS databases.")
 	installationCreateCmd.Flags().String("rds-replica-instance", "", "The machine instance type used for reader replicas of database cluster. Works only with single tenant RDS databases.")
 	installationCreateCmd.Flags().Int("rds-replicas-count", 0, "The number of reader replicas of database cluster. Min: 0, Max: 15. Works only with single tenant RDS databases.")
+	installationCreateCmd.Flags().String("external-database-connection-string", "", "The connection string of the customer managed database, starting with mysql:// or postgres://. Works only with external databases.")
+	installationCreateCmd.Flags().String("external-filestore-bucket", "", "The name of the customer managed bucket. Works only with external filestores.")
+	installationCreateCmd.Flags().String("external-filestore-endpoint", "", "The S3 compatible endpoint of the customer managed bucket. Defaults to AWS S3. Works only with external filestores.")
+	installationCreateCmd.Flags().String("external-filestore-region", "", "The region of the customer managed bucket. Works only with external filestores.")
+	installationCreateCmd.Flags().String("external-filestore-access-key-id", "", "The access key ID used to access the customer managed bucket. Works only with external filestores.")
+	installationCreateCmd.Flags().String("external-filestore-secret-access-key", "", "The secret access key used to access the customer managed bucket. Works only with external filestores.")
+	installationCreateCmd.Flags().String("network-isolation", "", "The network isolation profile of the installation. Accepts default or restricted. Uses the default profile if empty.")
+	installationCreateCmd.Flags().StringArray("egress-allow", []string{}, "Outbound traffic to allow with the restricted network isolation profile. Accepts format: CIDR[=[PROTOCOL:]PORT,...]. Use the flag multiple times to allow multiple destinations.")
+	installationCreateCmd.Flags().String("quota-cpu", "", "The total CPU requests allowed in the installation namespace, e.g. 4. Derived from the installation size if empty.")
+	installationCreateCmd.Flags().String("quota-memory", "", "The total memory requests allowed in the installation namespace, e.g. 8Gi. Derived from the installation size if empty.")
+	installationCreateCmd.Flags().StringArray("custom-size", []string{}, "Overrides the replicas and resources of the size for a component. Accepts format: COMPONENT=KEY:VALUE,... with components app, database or filestore and keys replicas, cpu-request, memory-request, cpu-limit, memory-limit or storage-size. Use the flag once per component.")
 	installationCreateCmd.MarkFlagRequired("owner")
 	installationCreateCmd.MarkFlagRequired("dns")
 
@@ -42,6 +56,8 @@ func init() {
 	installationUpdateCmd.Flags().String("license", "", "The Mattermost License to use in the server.")
 	installationUpdateCmd.Flags().StringArray("mattermost-env", []string{}, "Env vars to add to the Mattermost App. Accepts format: KEY_NAME=VALUE. Use the flag multiple times to set multiple env vars.")
 	installationUpdateCmd.Flags().Bool("mattermost-env-clear", false, "Clears all env var data.")
+	installationUpdateCmd.Flags().StringArray("custom-size", []string{}, "Overrides the replicas and resources of the size for the app. Accepts format: app=KEY:VALUE,... with keys replicas, cpu-request, memory-request, cpu-limit or memory-limit. The custom app size replaces the current one; database and filestore sizes can only be set on creation.")
+	installationUpdateCmd.Flags().Bool("custom-size-clear", false, "Removes the custom app size, reverting to the size preset.")
 	installationUpdateCmd.MarkFlagRequired("installation")
 
 	installationGetCmd.Flags().String("installation", "", "The id of the installation to be fetched.")
@@ -64,6 +80,22 @@ func init() {
 	installationWakeupCmd.Flags().String("installation", "", "The id of the installation to wake up from hibernation.")
 	installationWakeupCmd.MarkFlagRequired("installation")
 
+	installationMigrateFilestoreCmd.Flags().String("installation", "", "The id of the installation to move to a new filestore.")
+	installationMigrateFilestoreCmd.Flags().String("filestore", "", "The filestore type to move the installation files to. Accepts aws-s3, aws-multitenant-s3, or bifrost.")
+	installationMigrateFilestoreCmd.MarkFlagRequired("installation")
+	installationMigrateFilestoreCmd.MarkFlagRequired("filestore")
+
+	installationRotateCredentialsCmd.Flags().String("installation", "", "The id of the installation whose credentials will be rotated.")
+	installationRotateCredentialsCmd.MarkFlagRequired("installation")
+
+	installationUsageCmd.Flags().String("installation", "", "The id of the installation whose storage usage will be fetched.")
+	installationUsageCmd.Flags().Int("page", 0, "The page of usage records to fetch, starting at 0.")
+	installationUsageCmd.Flags().Int("per-page", 100, "The number of usage records to fetch per page.")
+	installationUsageCmd.MarkFlagRequired("installation")
+
+	installationCertificateCmd.Flags().String("installation", "", "The id of the installation whose certificate status will be fetched.")
+	installationCertificateCmd.MarkFlagRequired("installation")
+
 	installationDeleteCmd.Flags().String("installation", "", "The id of the installation to be deleted.")
 	installationDeleteCmd.MarkFlagRequired("installation")
 
@@ -72,10 +104,15 @@ func init() {
 	installationCmd.AddCommand(installationDeleteCmd)
 	installationCmd.AddCommand(installationHibernateCmd)
 	installationCmd.AddCommand(installationWakeupCmd)
+	installationCmd.AddCommand(installationMigrateFilestoreCmd)
+	installationCmd.AddCommand(installationRotateCredentialsCmd)
+	installationCmd.AddCommand(installationUsageCmd)
+	installationCmd.AddCommand(installationCertificateCmd)
 	installationCmd.AddCommand(installationGetCmd)
 	installationCmd.AddCommand(installationListCmd)
 	installationCmd.AddCommand(installationShowStateReport)
 	installationCmd.AddCommand(installationAnnotationCmd)
+	installationCmd.AddCommand(installationDomainCmd)
 }
 
 var installationCmd = &cobra.Command{
@@ -99,6 +136,7 @@ var installationCreateCmd = &cobra.Command{
 		size, _ := command.Flags().GetString("size")
 		dns, _ := command.Flags().GetString("dns")
 		affinity, _ := command.Flags().GetString("affinity")
+		nodeGroup, _ := command.Flags().GetString("node-group")
 		license, _ := command.Flags().GetString("license")
 		database, _ := command.Flags().GetString("database")
 		filestore, _ := command.Flags().GetString("filestore")
@@ -119,6 +157,7 @@ var installationCreateCmd = &cobra.Command{
 			DNS:           dns,
 			License:       license,
 			Affinity:      affinity,
+			NodeGroup:     nodeGroup,
 			Database:      database,
 			Filestore:     filestore,
 			MattermostEnv: envVarMap,
@@ -139,6 +178,55 @@ var installationCreateCmd = &cobra.Command{
 			request.SingleTenantDatabaseConfig = dbConfig
 		}
 
+		if database == model.InstallationDatabaseExternal {
+			connectionString, _ := command.Flags().GetString("external-database-connection-string")
+
+			request.ExternalDatabaseConfig = &model.ExternalDatabaseRequest{
+				ConnectionString: connectionString,
+			}
+		}
+
+		if filestore == model.InstallationFilestoreExternal {
+			bucket, _ := command.Flags().GetString("external-filestore-bucket")
+			endpoint, _ := command.Flags().GetString("external-filestore-endpoint")
+			region, _ := command.Flags().GetString("external-filestore-region")
+			accessKeyID, _ := command.Flags().GetString("external-filestore-access-key-id")
+			secretAccessKey, _ := command.Flags().GetString("external-filestore-secret-access-key")
+
+			request.ExternalFilestoreConfig = &model.ExternalFilestoreRequest{
+				Bucket:          bucket,
+				Endpoint:        endpoint,
+				Region:          region,
+				AccessKeyID:     accessKeyID,
+				SecretAccessKey: secretAccessKey,
+			}
+		}
+
+		networkIsolation, _ := command.Flags().GetString("network-isolation")
+		egressAllowInput, _ := command.Flags().GetStringArray("egress-allow")
+		if len(networkIsolation) != 0 || len(egressAllowInput) != 0 {
+			request.NetworkIsolation = &model.NetworkIsolation{Profile: networkIsolation}
+			request.NetworkIsolation.EgressAllowList, err = parseEgressAllowInput(egressAllowInput)
+			if err != nil {
+				return err
+			}
+		}
+
+		quotaCPU, _ := command.Flags().GetString("quota-cpu")
+		quotaMemory, _ := command.Flags().GetString("quota-memory")
+		if len(quotaCPU) != 0 || len(quotaMemory) != 0 {
+			request.ResourceQuota = &model.InstallationResourceQuota{
+				CPU:    quotaCPU,
+				Memory: quotaMemory,
+			}
+		}
+
+		customSizeInput, _ := command.Flags().GetStringArray("custom-size")
+		request.CustomSize, err = parseCustomSizeInput(customSizeInput, false)
+		if err != nil {
+			return err
+		}
+
 		dryRun, _ := command.Flags().GetBool("dry-run")
 		if dryRun {
 			err = printJSON(request)
@@ -176,12 +264,20 @@ var installationUpdateCmd = &cobra.Command{
 			return err
 		}
 
+		customSizeInput, _ := command.Flags().GetStringArray("custom-size")
+		customSizeClear, _ := command.Flags().GetBool("custom-size-clear")
+		customSize, err := parseCustomSizeInput(customSizeInput, customSizeClear)
+		if err != nil {
+			return err
+		}
+
 		request := &model.PatchInstallationRequest{
 			Version:       getStringFlagPointer(command, "version"),
 			Image:         getStringFlagPointer(command, "image"),
 			Size:          getStringFlagPointer(command, "size"),
 			License:       getStringFlagPointer(command, "license"),
 			MattermostEnv: envVarMap,
+			CustomSize:    customSize,
 		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
@@ -273,6 +369,122 @@ var installationWakeupCmd = &cobra.Command{
 	},
 }
 
+var installationMigrateFilestoreCmd = &cobra.Command{
+	Use:   "migrate-filestore",
+	Short: "Move the files of an installation to a new filestore.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+		filestore, _ := command.Flags().GetString("filestore")
+
+		installation, err := client.MigrateInstallationFilestore(installationID, &model.MigrateInstallationFilestoreRequest{
+			Filestore: filestore,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to migrate installation filestore")
+		}
+
+		err = printJSON(installation)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var installationRotateCredentialsCmd = &cobra.Command{
+	Use:   "rotate-credentials",
+	Short: "Rotate the database and filestore credentials of an installation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+
+		installation, err := client.RotateInstallationCredentials(installationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to rotate installation credentials")
+		}
+
+		err = printJSON(installation)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var installationUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Export the storage usage history of an installation as CSV.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+
+		usages, err := client.GetInstallationUsage(installationID, &model.GetInstallationUsageRequest{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query installation usage")
+		}
+
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{"CreateAt", "InstallationID", "DatabaseBytes", "FilestoreBytes", "FilestoreObjectCount"})
+		for _, usage := range usages {
+			writer.Write([]string{
+				strconv.FormatInt(usage.CreateAt, 10),
+				usage.InstallationID,
+				strconv.FormatInt(usage.DatabaseBytes, 10),
+				strconv.FormatInt(usage.FilestoreBytes, 10),
+				strconv.FormatInt(usage.FilestoreObjectCount, 10),
+			})
+		}
+		writer.Flush()
+
+		return writer.Error()
+	},
+}
+
+var installationCertificateCmd = &cobra.Command{
+	Use:   "certificate",
+	Short: "Get the status of the TLS certificate of an installation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+
+		certificate, err := client.GetInstallationCertificate(installationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query installation certificate")
+		}
+
+		err = printJSON(certificate)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
 var installationGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get a particular installation.",
@@ -0,0 +1,114 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	installationDomainAddCmd.Flags().String("installation", "", "The id of the installation to attach the domain to.")
+	installationDomainAddCmd.Flags().String("domain", "", "The custom domain to attach to the installation.")
+	installationDomainAddCmd.MarkFlagRequired("installation")
+	installationDomainAddCmd.MarkFlagRequired("domain")
+
+	installationDomainListCmd.Flags().String("installation", "", "The id of the installation whose custom domains will be listed.")
+	installationDomainListCmd.MarkFlagRequired("installation")
+
+	installationDomainDeleteCmd.Flags().String("installation", "", "The id of the installation from which the domain should be removed.")
+	installationDomainDeleteCmd.Flags().String("domain", "", "The id of the custom domain to be removed.")
+	installationDomainDeleteCmd.MarkFlagRequired("installation")
+	installationDomainDeleteCmd.MarkFlagRequired("domain")
+
+	installationDomainCmd.AddCommand(installationDomainAddCmd)
+	installationDomainCmd.AddCommand(installationDomainListCmd)
+	installationDomainCmd.AddCommand(installationDomainDeleteCmd)
+}
+
+var installationDomainCmd = &cobra.Command{
+	Use:   "domain",
+	Short: "Manipulate custom domains of installations managed by the provisioning server.",
+}
+
+var installationDomainAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Attaches a custom domain to the installation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+		domainName, _ := command.Flags().GetString("domain")
+
+		request := &model.CreateInstallationDomainRequest{Domain: domainName}
+
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		if dryRun {
+			return runDryRun(request)
+		}
+
+		domain, err := client.AddInstallationDomain(installationID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to add installation domain")
+		}
+
+		err = printJSON(domain)
+		if err != nil {
+			return errors.Wrap(err, "failed to print installation domain response")
+		}
+
+		return nil
+	},
+}
+
+var installationDomainListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the custom domains of the installation and the DNS records they require.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+
+		domains, err := client.GetInstallationDomains(installationID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get installation domains")
+		}
+
+		err = printJSON(domains)
+		if err != nil {
+			return errors.Wrap(err, "failed to print installation domains")
+		}
+
+		return nil
+	},
+}
+
+var installationDomainDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Removes a custom domain from the installation.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		installationID, _ := command.Flags().GetString("installation")
+		domainID, _ := command.Flags().GetString("domain")
+
+		err := client.DeleteInstallationDomain(installationID, domainID)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete installation domain")
+		}
+
+		return nil
+	},
+}
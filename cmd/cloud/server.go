@@ -8,6 +8,7 @@ import (
 	"context"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -24,6 +25,8 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/internal/supervisor"
 	toolsAWS "github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/internal/tools/dns"
+	"github.com/mattermost/mattermost-cloud/internal/tools/terraform"
 	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
@@ -47,13 +50,24 @@ func init() {
 	serverCmd.PersistentFlags().Bool("group-supervisor", false, "Whether this server will run an installation group supervisor or not.")
 	serverCmd.PersistentFlags().Bool("installation-supervisor", true, "Whether this server will run an installation supervisor or not.")
 	serverCmd.PersistentFlags().Bool("cluster-installation-supervisor", true, "Whether this server will run a cluster installation supervisor or not.")
+	serverCmd.PersistentFlags().Bool("usage-supervisor", false, "Whether this server will run an installation usage supervisor or not.")
+	serverCmd.PersistentFlags().Bool("utility-drift-supervisor", false, "Whether this server will run a cluster utility drift supervisor or not.")
+	serverCmd.PersistentFlags().Bool("installation-domain-supervisor", false, "Whether this server will run an installation custom domain supervisor or not.")
 	serverCmd.PersistentFlags().String("state-store", "dev.cloud.mattermost.com", "The S3 bucket used to store cluster state.")
 	serverCmd.PersistentFlags().StringSlice("allow-list-cidr-range", []string{"0.0.0.0/0"}, "The list of CIDRs to allow communication with the private ingress.")
 
 	serverCmd.PersistentFlags().Int("poll", 30, "The interval in seconds to poll for background work.")
+	serverCmd.PersistentFlags().Int("usage-metering-interval", 3600, "The interval in seconds between storage usage measurements of an installation.")
+	serverCmd.PersistentFlags().Int("utility-drift-interval", 3600, "The interval in seconds between utility drift checks of a cluster.")
+	serverCmd.PersistentFlags().Int("installation-domain-interval", 60, "The interval in seconds between DNS checks of the active custom domains of an installation.")
+	serverCmd.PersistentFlags().Int("credential-rotation-interval", 0, "The age in days after which installation database and filestore credentials are automatically rotated. Set to 0 to disable automatic rotation.")
 	serverCmd.PersistentFlags().Int("cluster-resource-threshold", 80, "The percent threshold where new installations won't be scheduled on a multi-tenant cluster.")
 	serverCmd.PersistentFlags().Int("cluster-resource-threshold-scale-value", 0, "The number of worker nodes to scale up by when the threshold is passed. Set to 0 for no scaling. Scaling will never exceed the cluster max worker configuration value.")
 	serverCmd.PersistentFlags().Bool("use-existing-aws-resources", true, "Whether to use existing AWS resources (VPCs, subnets, etc.) or not.")
+	serverCmd.PersistentFlags().Bool("create-vpcs", false, "Whether to create a tagged VPC for each new kops cluster with terraform instead of claiming one from the pool of pre-tagged VPCs. Requires use-existing-aws-resources.")
+	serverCmd.PersistentFlags().String("vpc-cidr", terraform.DefaultVPCCIDR, "The CIDR block of the VPCs created for kops clusters with create-vpcs.")
+	serverCmd.PersistentFlags().String("dns-provider", dns.ProviderRoute53, "The provider of the public DNS records of installations. Accepts route53, cloudflare (authenticated with the CLOUDFLARE_API_TOKEN environment variable) or memory (local development only).")
+	serverCmd.PersistentFlags().Bool("cert-manager-tls", false, "Whether to issue a Let's Encrypt certificate with cert-manager for each installation ingress instead of terminating TLS with the ACM certificate on the cluster load balancers.")
 	serverCmd.PersistentFlags().Bool("keep-database-data", true, "Whether to preserve database data after installation deletion or not.")
 	serverCmd.PersistentFlags().Bool("keep-filestore-data", true, "Whether to preserve filestore data after installation deletion or not.")
 	serverCmd.PersistentFlags().Bool("debug", false, "Whether to output debug logs.")
@@ -122,7 +136,26 @@ var serverCmd = &cobra.Command{
 		groupSupervisor, _ := command.Flags().GetBool("group-supervisor")
 		installationSupervisor, _ := command.Flags().GetBool("installation-supervisor")
 		clusterInstallationSupervisor, _ := command.Flags().GetBool("cluster-installation-supervisor")
-		if !clusterSupervisor && !installationSupervisor && !clusterInstallationSupervisor && !groupSupervisor {
+		usageSupervisor, _ := command.Flags().GetBool("usage-supervisor")
+		usageMeteringInterval, _ := command.Flags().GetInt("usage-metering-interval")
+		if usageMeteringInterval <= 0 {
+			return errors.Errorf("usage-metering-interval (%d) must be greater than 0", usageMeteringInterval)
+		}
+		utilityDriftSupervisor, _ := command.Flags().GetBool("utility-drift-supervisor")
+		utilityDriftInterval, _ := command.Flags().GetInt("utility-drift-interval")
+		if utilityDriftInterval <= 0 {
+			return errors.Errorf("utility-drift-interval (%d) must be greater than 0", utilityDriftInterval)
+		}
+		installationDomainSupervisor, _ := command.Flags().GetBool("installation-domain-supervisor")
+		installationDomainInterval, _ := command.Flags().GetInt("installation-domain-interval")
+		if installationDomainInterval <= 0 {
+			return errors.Errorf("installation-domain-interval (%d) must be greater than 0", installationDomainInterval)
+		}
+		credentialRotationInterval, _ := command.Flags().GetInt("credential-rotation-interval")
+		if credentialRotationInterval < 0 {
+			return errors.Errorf("credential-rotation-interval (%d) must not be negative", credentialRotationInterval)
+		}
+		if !clusterSupervisor && !installationSupervisor && !clusterInstallationSupervisor && !groupSupervisor && !usageSupervisor && !utilityDriftSupervisor {
 			logger.Warn("Server will be running with no supervisors. Only API functionality will work.")
 		}
 
@@ -130,6 +163,24 @@ var serverCmd = &cobra.Command{
 		keepDatabaseData, _ := command.Flags().GetBool("keep-database-data")
 		keepFilestoreData, _ := command.Flags().GetBool("keep-filestore-data")
 		useExistingResources, _ := command.Flags().GetBool("use-existing-aws-resources")
+		createVPCs, _ := command.Flags().GetBool("create-vpcs")
+		if createVPCs && !useExistingResources {
+			return errors.New("create-vpcs requires use-existing-aws-resources")
+		}
+		vpcCIDR, _ := command.Flags().GetString("vpc-cidr")
+		_, _, err = net.ParseCIDR(vpcCIDR)
+		if err != nil {
+			return errors.Wrap(err, "invalid vpc-cidr")
+		}
+		certManagerTLS, _ := command.Flags().GetBool("cert-manager-tls")
+		dnsProviderName, _ := command.Flags().GetString("dns-provider")
+		if !dns.IsSupportedProvider(dnsProviderName) {
+			return errors.Errorf("dns-provider %s is not supported", dnsProviderName)
+		}
+		cloudflareAPIToken := os.Getenv("CLOUDFLARE_API_TOKEN")
+		if dnsProviderName == dns.ProviderCloudflare && cloudflareAPIToken == "" {
+			return errors.New("the CLOUDFLARE_API_TOKEN environment variable must be set to use the cloudflare dns-provider")
+		}
 
 		wd, err := os.Getwd()
 		if err != nil {
@@ -152,12 +203,23 @@ var serverCmd = &cobra.Command{
 			"group-supervisor":                       groupSupervisor,
 			"installation-supervisor":                installationSupervisor,
 			"cluster-installation-supervisor":        clusterInstallationSupervisor,
+			"usage-supervisor":                       usageSupervisor,
+			"usage-metering-interval":                usageMeteringInterval,
+			"utility-drift-supervisor":               utilityDriftSupervisor,
+			"utility-drift-interval":                 utilityDriftInterval,
+			"installation-domain-supervisor":         installationDomainSupervisor,
+			"installation-domain-interval":           installationDomainInterval,
+			"credential-rotation-interval":           credentialRotationInterval,
 			"store-version":                          currentVersion,
 			"state-store":                            s3StateStore,
 			"working-directory":                      wd,
 			"cluster-resource-threshold":             clusterResourceThreshold,
 			"cluster-resource-threshold-scale-value": clusterResourceThresholdScaleValue,
 			"use-existing-aws-resources":             useExistingResources,
+			"create-vpcs":                            createVPCs,
+			"vpc-cidr":                               vpcCIDR,
+			"cert-manager-tls":                       certManagerTLS,
+			"dns-provider":                           dnsProviderName,
 			"keep-database-data":                     keepDatabaseData,
 			"keep-filestore-data":                    keepFilestoreData,
 			"debug":                                  debugMode,
@@ -170,6 +232,9 @@ var serverCmd = &cobra.Command{
 		if !useExistingResources {
 			logger.Warn("[DEV] Server is configured to not use cluster VPC claim functionality")
 		}
+		if dnsProviderName == dns.ProviderMemory {
+			logger.Warn("[DEV] Server is configured to only keep installation DNS records in memory")
+		}
 
 		// best-effort attempt to tag the VPC with a human's identity for dev purposes
 		owner := getHumanReadableID()
@@ -193,29 +258,63 @@ var serverCmd = &cobra.Command{
 
 		resourceUtil := utils.NewResourceUtil(instanceID, awsClient)
 
+		var dnsProvider supervisor.DNSProvider
+		switch dnsProviderName {
+		case dns.ProviderCloudflare:
+			dnsProvider = dns.NewCloudflareProvider(cloudflareAPIToken)
+		case dns.ProviderMemory:
+			dnsProvider = dns.NewMemoryProvider()
+		default:
+			dnsProvider = dns.NewRoute53Provider(awsClient)
+		}
+
 		// Setup the provisioner for actually effecting changes to clusters.
 		kopsProvisioner := provisioner.NewKopsProvisioner(
 			s3StateStore,
 			owner,
 			useExistingResources,
+			createVPCs,
+			vpcCIDR,
+			certManagerTLS,
 			allowListCIDRRange,
 			resourceUtil,
 			logger,
 			sqlStore,
 		)
+		eksProvisioner := provisioner.NewEKSProvisioner(kopsProvisioner, logger)
+		externalProvisioner := provisioner.NewExternalProvisioner(kopsProvisioner, awsClient, logger)
+
+		// Route cluster lifecycle operations to the provisioner of each cluster.
+		clusterProvisioner := provisioner.NewRouter(kopsProvisioner, map[string]provisioner.ClusterProvisioner{
+			model.ProvisionerKops:     kopsProvisioner,
+			model.ProvisionerEKS:      eksProvisioner,
+			model.ProvisionerExternal: externalProvisioner,
+		})
 
 		var multiDoer supervisor.MultiDoer
 		if clusterSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewClusterSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger))
+			multiDoer = append(multiDoer, supervisor.NewClusterSupervisor(sqlStore, clusterProvisioner, awsClient, instanceID, logger))
 		}
 		if groupSupervisor {
 			multiDoer = append(multiDoer, supervisor.NewGroupSupervisor(sqlStore, instanceID, logger))
 		}
 		if installationSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, clusterResourceThreshold, clusterResourceThresholdScaleValue, keepDatabaseData, keepFilestoreData, resourceUtil, logger))
+			multiDoer = append(multiDoer, supervisor.NewInstallationSupervisor(sqlStore, clusterProvisioner, awsClient, dnsProvider, instanceID, clusterResourceThreshold, clusterResourceThresholdScaleValue, keepDatabaseData, keepFilestoreData, resourceUtil, logger))
 		}
 		if clusterInstallationSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewClusterInstallationSupervisor(sqlStore, kopsProvisioner, awsClient, instanceID, logger))
+			multiDoer = append(multiDoer, supervisor.NewClusterInstallationSupervisor(sqlStore, clusterProvisioner, awsClient, instanceID, logger))
+		}
+		if usageSupervisor {
+			multiDoer = append(multiDoer, supervisor.NewInstallationUsageSupervisor(sqlStore, clusterProvisioner, resourceUtil, instanceID, time.Duration(usageMeteringInterval)*time.Second, logger))
+		}
+		if utilityDriftSupervisor {
+			multiDoer = append(multiDoer, supervisor.NewUtilityDriftSupervisor(sqlStore, clusterProvisioner, instanceID, time.Duration(utilityDriftInterval)*time.Second, logger))
+		}
+		if installationDomainSupervisor {
+			multiDoer = append(multiDoer, supervisor.NewInstallationDomainSupervisor(sqlStore, clusterProvisioner, net.DefaultResolver, instanceID, time.Duration(installationDomainInterval)*time.Second, logger))
+		}
+		if installationSupervisor && credentialRotationInterval > 0 {
+			multiDoer = append(multiDoer, supervisor.NewCredentialRotationSupervisor(sqlStore, instanceID, time.Duration(credentialRotationInterval)*24*time.Hour, logger))
 		}
 
 		// Setup the supervisor to effect any requested changes. It is wrapped in a
@@ -234,8 +333,11 @@ var serverCmd = &cobra.Command{
 		api.Register(router, &api.Context{
 			Store:       sqlStore,
 			Supervisor:  supervisor,
-			Provisioner: kopsProvisioner,
+			Provisioner: clusterProvisioner,
+			AwsClient:   awsClient,
 			Logger:      logger,
+
+			CertManagerTLS: certManagerTLS,
 		})
 
 		listen, _ := command.Flags().GetString("listen")
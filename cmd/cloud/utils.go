@@ -5,6 +5,7 @@
 package main
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/model"
@@ -42,3 +43,138 @@ func parseEnvVarInput(rawInput []string, clear bool) (model.EnvVarMap, error) {
 
 	return envVarMap, nil
 }
+
+func parseNodeGroupInput(rawInput []string, clear bool) ([]*model.NodeGroupRequest, error) {
+	if len(rawInput) != 0 && clear {
+		return nil, errors.New("both node-group and node-group-clear were set; use one or the other")
+	}
+	if clear {
+		// An empty non-nil list is what the API expects to delete all node groups.
+		return []*model.NodeGroupRequest{}, nil
+	}
+	if len(rawInput) == 0 {
+		return nil, nil
+	}
+
+	var nodeGroups []*model.NodeGroupRequest
+	for _, input := range rawInput {
+		nameAndSize := strings.SplitN(input, "=", 2)
+		if len(nameAndSize) != 2 || len(nameAndSize[0]) == 0 {
+			return nil, errors.Errorf("%s is not in a valid node group format; expecting NAME=INSTANCE_TYPE:MIN_COUNT[:MAX_COUNT]", input)
+		}
+		size := strings.Split(nameAndSize[1], ":")
+		if len(size) < 2 || len(size) > 3 {
+			return nil, errors.Errorf("%s is not in a valid node group format; expecting NAME=INSTANCE_TYPE:MIN_COUNT[:MAX_COUNT]", input)
+		}
+
+		nodeGroup := &model.NodeGroupRequest{
+			Name:         nameAndSize[0],
+			InstanceType: size[0],
+		}
+		var err error
+		nodeGroup.MinCount, err = strconv.ParseInt(size[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid min count for node group %s", nodeGroup.Name)
+		}
+		if len(size) == 3 {
+			nodeGroup.MaxCount, err = strconv.ParseInt(size[2], 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid max count for node group %s", nodeGroup.Name)
+			}
+		}
+		nodeGroups = append(nodeGroups, nodeGroup)
+	}
+
+	return nodeGroups, nil
+}
+
+func parseEgressAllowInput(rawInput []string) ([]model.NetworkEgressRule, error) {
+	var rules []model.NetworkEgressRule
+	for _, input := range rawInput {
+		cidrAndPorts := strings.SplitN(input, "=", 2)
+		if len(cidrAndPorts[0]) == 0 {
+			return nil, errors.Errorf("%s is not in a valid egress rule format; expecting CIDR[=[PROTOCOL:]PORT,...]", input)
+		}
+
+		rule := model.NetworkEgressRule{CIDR: cidrAndPorts[0]}
+		if len(cidrAndPorts) == 2 {
+			ports := cidrAndPorts[1]
+			if protocolAndPorts := strings.SplitN(ports, ":", 2); len(protocolAndPorts) == 2 {
+				rule.Protocol = protocolAndPorts[0]
+				ports = protocolAndPorts[1]
+			}
+			for _, port := range strings.Split(ports, ",") {
+				value, err := strconv.ParseInt(port, 10, 32)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid port for egress rule %s", rule.CIDR)
+				}
+				rule.Ports = append(rule.Ports, int32(value))
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func parseCustomSizeInput(rawInput []string, clear bool) (*model.InstallationCustomSize, error) {
+	if len(rawInput) != 0 && clear {
+		return nil, errors.New("both custom-size and custom-size-clear were set; use one or the other")
+	}
+	if clear {
+		// An empty custom size is what the API expects to remove it.
+		return &model.InstallationCustomSize{}, nil
+	}
+	if len(rawInput) == 0 {
+		return nil, nil
+	}
+
+	customSize := &model.InstallationCustomSize{}
+	for _, input := range rawInput {
+		componentAndValues := strings.SplitN(input, "=", 2)
+		if len(componentAndValues) != 2 || len(componentAndValues[1]) == 0 {
+			return nil, errors.Errorf("%s is not in a valid custom size format; expecting COMPONENT=KEY:VALUE,...", input)
+		}
+
+		component := &model.InstallationComponentSize{}
+		switch componentAndValues[0] {
+		case "app":
+			customSize.App = component
+		case "database":
+			customSize.Database = component
+		case "filestore":
+			customSize.Filestore = component
+		default:
+			return nil, errors.Errorf("unknown custom size component %s; expecting app, database or filestore", componentAndValues[0])
+		}
+
+		for _, value := range strings.Split(componentAndValues[1], ",") {
+			kv := strings.SplitN(value, ":", 2)
+			if len(kv) != 2 {
+				return nil, errors.Errorf("%s is not in a valid custom size format; expecting KEY:VALUE", value)
+			}
+			switch kv[0] {
+			case "replicas":
+				replicas, err := strconv.ParseInt(kv[1], 10, 32)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid replicas for custom size component %s", componentAndValues[0])
+				}
+				component.Replicas = int32(replicas)
+			case "cpu-request":
+				component.CPURequest = kv[1]
+			case "memory-request":
+				component.MemoryRequest = kv[1]
+			case "cpu-limit":
+				component.CPULimit = kv[1]
+			case "memory-limit":
+				component.MemoryLimit = kv[1]
+			case "storage-size":
+				component.StorageSize = kv[1]
+			default:
+				return nil, errors.Errorf("unknown custom size key %s", kv[0])
+			}
+		}
+	}
+
+	return customSize, nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	vpcCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The provisioning server whose API will be queried.")
+
+	vpcCmd.AddCommand(vpcListCmd)
+}
+
+var vpcCmd = &cobra.Command{
+	Use:   "vpc",
+	Short: "View information on the pool of VPCs available to clusters",
+}
+
+var vpcListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List VPCs tagged for use by the provisioner along with their claim status and health.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := model.NewClient(serverAddress)
+
+		vpcs, err := client.GetVPCs()
+		if err != nil {
+			return errors.Wrap(err, "failed to query VPCs")
+		}
+
+		err = printJSON(vpcs)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
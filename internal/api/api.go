@@ -16,5 +16,6 @@ func Register(rootRouter *mux.Router, context *Context) {
 	initGroup(apiRouter, context)
 	initWebhook(apiRouter, context)
 	initDatabases(apiRouter, context)
+	initVPCs(apiRouter, context)
 	initSecurity(apiRouter, context)
 }
@@ -25,6 +25,8 @@ func initCluster(apiRouter *mux.Router, context *Context) {
 	clustersRouter := apiRouter.PathPrefix("/clusters").Subrouter()
 	clustersRouter.Handle("", addContext(handleGetClusters)).Methods("GET")
 	clustersRouter.Handle("", addContext(handleCreateCluster)).Methods("POST")
+	clustersRouter.Handle("/import", addContext(handleImportCluster)).Methods("POST")
+	clustersRouter.Handle("/utilities/drift", addContext(handleGetClusterUtilityDrift)).Methods("GET")
 
 	clusterRouter := apiRouter.PathPrefix("/cluster/{cluster:[A-Za-z0-9]{26}}").Subrouter()
 	clusterRouter.Handle("", addContext(handleGetCluster)).Methods("GET")
@@ -32,10 +34,16 @@ func initCluster(apiRouter *mux.Router, context *Context) {
 	clusterRouter.Handle("", addContext(handleUpdateClusterConfiguration)).Methods("PUT")
 	clusterRouter.Handle("/provision", addContext(handleProvisionCluster)).Methods("POST")
 	clusterRouter.Handle("/kubernetes", addContext(handleUpgradeKubernetes)).Methods("PUT")
+	clusterRouter.Handle("/upgrade/resume", addContext(handleResumeClusterUpgrade)).Methods("POST")
+	clusterRouter.Handle("/upgrade/abort", addContext(handleAbortClusterUpgrade)).Methods("POST")
 	clusterRouter.Handle("/size", addContext(handleResizeCluster)).Methods("PUT")
 	clusterRouter.Handle("/utilities", addContext(handleGetAllUtilityMetadata)).Methods("GET")
+	clusterRouter.Handle("/utilities", addContext(handleUpdateClusterUtilities)).Methods("PUT")
+	clusterRouter.Handle("/utilities/{utility}", addContext(handleUpgradeClusterUtility)).Methods("PUT")
 	clusterRouter.Handle("/annotations", addContext(handleAddClusterAnnotations)).Methods("POST")
 	clusterRouter.Handle("/annotation/{annotation-name}", addContext(handleDeleteClusterAnnotation)).Methods("DELETE")
+	clusterRouter.Handle("/backups", addContext(handleGetClusterBackups)).Methods("GET")
+	clusterRouter.Handle("/backups", addContext(handleCreateClusterBackup)).Methods("POST")
 
 	clusterRouter.Handle("", addContext(handleDeleteCluster)).Methods("DELETE")
 }
@@ -92,6 +100,25 @@ func handleGetClusters(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, clusters)
 }
 
+// handleGetClusterUtilityDrift responds to GET /api/clusters/utilities/drift,
+// returning the clusters whose utilities drifted from their deployed state.
+func handleGetClusterUtilityDrift(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.Logger = c.Logger.WithField("action", "get-utility-drift")
+
+	clusters, err := c.Store.GetClusters(&model.ClusterFilter{
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query clusters")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, model.NewClusterUtilityDriftReport(clusters))
+}
+
 // handleCreateCluster responds to POST /api/clusters, beginning the process of creating a new
 // cluster.
 // sample body:
@@ -114,24 +141,40 @@ func handleCreateCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 	cluster := model.Cluster{
 		Provider: createClusterRequest.Provider,
 		ProviderMetadataAWS: &model.AWSMetadata{
-			Zones: createClusterRequest.Zones,
+			Zones:         createClusterRequest.Zones,
+			Region:        createClusterRequest.Region,
+			AssumeRoleARN: createClusterRequest.AssumeRoleARN,
 		},
-		Provisioner: "kops",
+		Provisioner: createClusterRequest.Provisioner,
 		ProvisionerMetadataKops: &model.KopsMetadata{
 			ChangeRequest: &model.KopsMetadataRequestedState{
-				Version:            createClusterRequest.Version,
-				AMI:                createClusterRequest.KopsAMI,
-				MasterInstanceType: createClusterRequest.MasterInstanceType,
-				MasterCount:        createClusterRequest.MasterCount,
-				NodeInstanceType:   createClusterRequest.NodeInstanceType,
-				NodeMinCount:       createClusterRequest.NodeMinCount,
-				NodeMaxCount:       createClusterRequest.NodeMaxCount,
+				Version:                  createClusterRequest.Version,
+				AMI:                      createClusterRequest.KopsAMI,
+				MasterInstanceType:       createClusterRequest.MasterInstanceType,
+				MasterCount:              createClusterRequest.MasterCount,
+				NodeInstanceType:         createClusterRequest.NodeInstanceType,
+				NodeMinCount:             createClusterRequest.NodeMinCount,
+				NodeMaxCount:             createClusterRequest.NodeMaxCount,
+				NodeGroups:               createClusterRequest.KopsNodeGroups(),
+				NodeMixedInstancesPolicy: createClusterRequest.KopsNodeMixedInstancesPolicy(),
+				Autoscaling:              createClusterRequest.Autoscaling,
 			},
 		},
 		AllowInstallations: createClusterRequest.AllowInstallations,
 		APISecurityLock:    createClusterRequest.APISecurityLock,
 		State:              model.ClusterStateCreationRequested,
 	}
+	if cluster.Provisioner == model.ProvisionerEKS {
+		cluster.ProvisionerMetadataKops = nil
+		cluster.ProvisionerMetadataEKS = &model.EKSMetadata{
+			ChangeRequest: &model.EKSMetadataRequestedState{
+				Version:          createClusterRequest.Version,
+				NodeInstanceType: createClusterRequest.NodeInstanceType,
+				NodeMinCount:     createClusterRequest.NodeMinCount,
+				NodeMaxCount:     createClusterRequest.NodeMaxCount,
+			},
+		}
+	}
 
 	err = cluster.SetUtilityDesiredVersions(createClusterRequest.DesiredUtilityVersions)
 	if err != nil {
@@ -139,6 +182,7 @@ func handleCreateCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	cluster.SetUtilityValuesOverrides(createClusterRequest.UtilityValuesOverrides)
 
 	annotations, err := model.AnnotationsFromStringSlice(createClusterRequest.Annotations)
 	if err != nil {
@@ -173,6 +217,121 @@ func handleCreateCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, cluster.ToDTO(annotations))
 }
 
+// handleImportCluster responds to POST /api/clusters/import, registering an
+// existing cluster that was created outside of the provisioner and beginning
+// the process of provisioning it.
+// sample body:
+// {
+//		"kops-name": "cluster.k8s.local",
+//		"kops-state-store": "other-state-store",
+//		"allow-installations": true
+// }
+func handleImportCluster(c *Context, w http.ResponseWriter, r *http.Request) {
+	importClusterRequest, err := model.NewImportClusterRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cluster := model.Cluster{
+		Provider:            importClusterRequest.Provider,
+		ProviderMetadataAWS: &model.AWSMetadata{},
+		Provisioner:         importClusterRequest.Provisioner(),
+		AllowInstallations:  importClusterRequest.AllowInstallations,
+		APISecurityLock:     importClusterRequest.APISecurityLock,
+		State:               model.ClusterStateProvisioningRequested,
+	}
+	if cluster.Provisioner == model.ProvisionerKops {
+		cluster.ProvisionerMetadataKops = &model.KopsMetadata{
+			Name:       importClusterRequest.KopsName,
+			StateStore: importClusterRequest.KopsStateStore,
+			Imported:   true,
+		}
+	} else {
+		cluster.ProvisionerMetadataExternal = &model.ExternalMetadata{
+			Kubeconfig: importClusterRequest.Kubeconfig,
+		}
+	}
+
+	err = cluster.SetUtilityDesiredVersions(importClusterRequest.DesiredUtilityVersions)
+	if err != nil {
+		c.Logger.WithError(err).Error("provided utility metadata could not be applied without error")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	annotations, err := model.AnnotationsFromStringSlice(importClusterRequest.Annotations)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to validate extra annotations")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = c.Provisioner.ImportCluster(&cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to connect to imported cluster")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if cluster.ProvisionerMetadataExternal != nil {
+		err = storeKubeconfigSecret(c, &cluster)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to store kubeconfig secret")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err = c.Store.CreateCluster(&cluster, annotations)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to create cluster")
+		if cluster.ProvisionerMetadataExternal != nil {
+			err = c.AwsClient.EnsureKubeconfigSecretDeleted(cluster.ProvisionerMetadataExternal.KubeconfigSecret, c.Logger)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to delete kubeconfig secret of cluster that was not created")
+			}
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeCluster,
+		ID:        cluster.ID,
+		NewState:  model.ClusterStateProvisioningRequested,
+		OldState:  "n/a",
+		Timestamp: time.Now().UnixNano(),
+	}
+	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, cluster.ToDTO(annotations))
+}
+
+// storeKubeconfigSecret keeps the kubeconfig of an imported cluster in AWS so
+// that it is never persisted in the provisioner database.
+func storeKubeconfigSecret(c *Context, cluster *model.Cluster) error {
+	if c.AwsClient == nil {
+		return errors.New("importing clusters with a kubeconfig is not supported by this server")
+	}
+
+	secretName, err := c.AwsClient.CreateKubeconfigSecret(cluster.ProvisionerMetadataExternal.Kubeconfig, c.Logger)
+	if err != nil {
+		return err
+	}
+	cluster.ProvisionerMetadataExternal.KubeconfigSecret = secretName
+
+	return nil
+}
+
 // handleRetryCreateCluster responds to POST /api/cluster/{cluster}, retrying a previously
 // failed creation.
 //
@@ -347,13 +506,40 @@ func handleUpdateClusterConfiguration(c *Context, w http.ResponseWriter, r *http
 	outputJSON(c, w, clusterDTO)
 }
 
+// outputClusterChangePreview responds with the changes the pending cluster
+// change request would make without applying them.
+func outputClusterChangePreview(c *Context, w http.ResponseWriter, cluster *model.Cluster, changed bool) {
+	preview := &model.ClusterChangePreview{ClusterID: cluster.ID}
+	if changed {
+		var err error
+		preview, err = c.Provisioner.PreviewClusterChanges(cluster)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to preview cluster changes")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, preview)
+}
+
 // handleUpgradeKubernetes responds to PUT /api/cluster/{cluster}/kubernetes,
-// upgrading the cluster to the given Kubernetes version and AMI image.
+// upgrading the cluster to the given Kubernetes version and AMI image. With
+// dry-run=true, the changes are previewed without being applied.
 func handleUpgradeKubernetes(c *Context, w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	clusterID := vars["cluster"]
 	c.Logger = c.Logger.WithField("cluster", clusterID)
 
+	dryRun, err := parseBool(r.URL, "dry-run", false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse request parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
 	upgradeClusterRequest, err := model.NewUpgradeClusterRequestFromReader(r.Body)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to decode request")
@@ -374,6 +560,27 @@ func handleUpgradeKubernetes(c *Context, w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if clusterDTO.IsImported() {
+		c.Logger.Errorf("unable to upgrade imported cluster")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	isEKS := clusterDTO.Provisioner == model.ProvisionerEKS
+	if isEKS {
+		err = upgradeClusterRequest.ValidateEKS()
+		if err != nil {
+			c.Logger.WithError(err).Error("invalid upgrade request for eks cluster")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if dryRun {
+			c.Logger.Error("dry-run is not supported for eks clusters")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
 	oldState := clusterDTO.State
 	newState := model.ClusterStateUpgradeRequested
 
@@ -383,7 +590,20 @@ func handleUpgradeKubernetes(c *Context, w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if upgradeClusterRequest.Apply(clusterDTO.ProvisionerMetadataKops) {
+	if dryRun {
+		changed := upgradeClusterRequest.Apply(clusterDTO.ProvisionerMetadataKops)
+		outputClusterChangePreview(c, w, clusterDTO.Cluster, changed)
+		return
+	}
+
+	var changed bool
+	if isEKS {
+		changed = upgradeClusterRequest.ApplyEKS(clusterDTO.ProvisionerMetadataEKS)
+	} else {
+		changed = upgradeClusterRequest.Apply(clusterDTO.ProvisionerMetadataKops)
+	}
+
+	if changed {
 		clusterDTO.State = newState
 		err := c.Store.UpdateCluster(clusterDTO.Cluster)
 		if err != nil {
@@ -416,13 +636,94 @@ func handleUpgradeKubernetes(c *Context, w http.ResponseWriter, r *http.Request)
 	outputJSON(c, w, clusterDTO)
 }
 
+// handleResumeClusterUpgrade responds to POST /api/cluster/{cluster}/upgrade/resume,
+// resuming a staged cluster upgrade that was paused.
+func handleResumeClusterUpgrade(c *Context, w http.ResponseWriter, r *http.Request) {
+	handlePausedClusterUpgrade(c, w, r, model.ClusterStateUpgradeRequested)
+}
+
+// handleAbortClusterUpgrade responds to POST /api/cluster/{cluster}/upgrade/abort,
+// aborting a staged cluster upgrade that was paused or failed. The previous version and
+// AMI are restored and the instance groups that were already rolled are
+// rolled back to them.
+func handleAbortClusterUpgrade(c *Context, w http.ResponseWriter, r *http.Request) {
+	handlePausedClusterUpgrade(c, w, r, model.ClusterStateUpgradeAbortRequested)
+}
+
+// handlePausedClusterUpgrade moves a cluster with a paused upgrade to the new
+// state. Staged upgrades that failed can only be aborted.
+func handlePausedClusterUpgrade(c *Context, w http.ResponseWriter, r *http.Request, newState string) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID)
+
+	clusterDTO, status, unlockOnce := lockCluster(c, clusterID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if clusterDTO.APISecurityLock {
+		logSecurityLockConflict("cluster", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	oldState := clusterDTO.State
+	validState := oldState == model.ClusterStateUpgradePaused
+	if newState == model.ClusterStateUpgradeAbortRequested {
+		validState = clusterDTO.ValidTransitionState(newState)
+	}
+	if !validState {
+		c.Logger.Warnf("unable to move cluster upgrade to state %s while in state %s", newState, oldState)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clusterDTO.State = newState
+	err := c.Store.UpdateCluster(clusterDTO.Cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeCluster,
+		ID:        clusterDTO.ID,
+		NewState:  newState,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+	}
+	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, clusterDTO)
+}
+
 // handleResizeCluster responds to PUT /api/cluster/{cluster}/size,
-// resizing the cluster.
+// resizing the cluster. With dry-run=true, the changes are previewed without
+// being applied.
 func handleResizeCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	clusterID := vars["cluster"]
 	c.Logger = c.Logger.WithField("cluster", clusterID)
 
+	dryRun, err := parseBool(r.URL, "dry-run", false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse request parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
 	resizeClusterRequest, err := model.NewResizeClusterRequestFromReader(r.Body)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to decode request")
@@ -443,14 +744,50 @@ func handleResizeCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if clusterDTO.IsImported() {
+		c.Logger.Errorf("unable to resize imported cluster")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	isEKS := clusterDTO.Provisioner == model.ProvisionerEKS
+	if isEKS {
+		err = resizeClusterRequest.ValidateEKS()
+		if err != nil {
+			c.Logger.WithError(err).Error("invalid resize request for eks cluster")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if dryRun {
+			c.Logger.Error("dry-run is not supported for eks clusters")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	var nodeMinCount int64
+	if isEKS {
+		nodeMinCount = clusterDTO.ProvisionerMetadataEKS.NodeMinCount
+	} else {
+		nodeMinCount = clusterDTO.ProvisionerMetadataKops.NodeMinCount
+	}
+
 	// One more check that can't be done without both the request and the cluster.
 	if resizeClusterRequest.NodeMinCount == nil &&
 		resizeClusterRequest.NodeMaxCount != nil &&
-		*resizeClusterRequest.NodeMaxCount < clusterDTO.ProvisionerMetadataKops.NodeMinCount {
+		*resizeClusterRequest.NodeMaxCount < nodeMinCount {
 		c.Logger.Error("resize patch would set max node count lower than min node count")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if !isEKS {
+		err = resizeClusterRequest.ValidateNodeInstanceType(clusterDTO.ProvisionerMetadataKops.NodeMixedInstancesPolicy)
+		if err != nil {
+			c.Logger.WithError(err).Error("resize patch conflicts with the node mixed instances policy")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
 
 	oldState := clusterDTO.State
 	newState := model.ClusterStateResizeRequested
@@ -461,7 +798,20 @@ func handleResizeCluster(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if resizeClusterRequest.Apply(clusterDTO.ProvisionerMetadataKops) {
+	if dryRun {
+		changed := resizeClusterRequest.Apply(clusterDTO.ProvisionerMetadataKops)
+		outputClusterChangePreview(c, w, clusterDTO.Cluster, changed)
+		return
+	}
+
+	var changed bool
+	if isEKS {
+		changed = resizeClusterRequest.ApplyEKS(clusterDTO.ProvisionerMetadataEKS)
+	} else {
+		changed = resizeClusterRequest.Apply(clusterDTO.ProvisionerMetadataKops)
+	}
+
+	if changed {
 		clusterDTO.State = newState
 		err = c.Store.UpdateCluster(clusterDTO.Cluster)
 		if err != nil {
@@ -583,6 +933,152 @@ func handleGetAllUtilityMetadata(c *Context, w http.ResponseWriter, r *http.Requ
 	outputJSON(c, w, cluster.UtilityMetadata)
 }
 
+// handleUpdateClusterUtilities responds to PUT /api/cluster/{cluster}/utilities,
+// updating the Helm values overrides of the cluster utilities and
+// reprovisioning the cluster to apply them.
+func handleUpdateClusterUtilities(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID).WithField("action", "update-utilities")
+
+	updateClusterUtilitiesRequest, err := model.NewUpdateClusterUtilitiesRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clusterDTO, status, unlockOnce := lockCluster(c, clusterID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if clusterDTO.APISecurityLock {
+		logSecurityLockConflict("cluster", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	newState := model.ClusterStateProvisioningRequested
+
+	if !clusterDTO.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to update cluster utilities while in state %s", clusterDTO.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clusterDTO.SetUtilityValuesOverrides(updateClusterUtilitiesRequest.ValuesOverrides)
+
+	oldState := clusterDTO.State
+	clusterDTO.State = newState
+
+	err = c.Store.UpdateCluster(clusterDTO.Cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update cluster utilities")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if oldState != newState {
+		webhookPayload := &model.WebhookPayload{
+			Type:      model.TypeCluster,
+			ID:        clusterDTO.ID,
+			NewState:  newState,
+			OldState:  oldState,
+			Timestamp: time.Now().UnixNano(),
+		}
+		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		if err != nil {
+			c.Logger.WithError(err).Error("Unable to process and send webhooks")
+		}
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, clusterDTO)
+}
+
+// handleUpgradeClusterUtility responds to PUT
+// /api/cluster/{cluster}/utilities/{utility}, upgrading or rolling back a
+// single utility of the cluster without reprovisioning the whole cluster.
+func handleUpgradeClusterUtility(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	utility := vars["utility"]
+	c.Logger = c.Logger.WithField("cluster", clusterID).WithField("utility", utility).WithField("action", "upgrade-utility")
+
+	upgradeClusterUtilityRequest, err := model.NewUpgradeClusterUtilityRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clusterDTO, status, unlockOnce := lockCluster(c, clusterID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if clusterDTO.APISecurityLock {
+		logSecurityLockConflict("cluster", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	newState := model.ClusterStateUtilityUpgradeRequested
+
+	if !clusterDTO.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to upgrade cluster utility while in state %s", clusterDTO.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = clusterDTO.SetUtilityUpgrade(utility, upgradeClusterUtilityRequest.Version)
+	if err != nil {
+		c.Logger.WithError(err).Error("invalid utility upgrade")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	oldState := clusterDTO.State
+	clusterDTO.State = newState
+
+	err = c.Store.UpdateCluster(clusterDTO.Cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update cluster utility")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if oldState != newState {
+		webhookPayload := &model.WebhookPayload{
+			Type:      model.TypeCluster,
+			ID:        clusterDTO.ID,
+			NewState:  newState,
+			OldState:  oldState,
+			Timestamp: time.Now().UnixNano(),
+		}
+		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		if err != nil {
+			c.Logger.WithError(err).Error("Unable to process and send webhooks")
+		}
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, clusterDTO)
+}
+
 // handleAddClusterAnnotations responds to POST /api/cluster/{cluster}/annotations,
 // adds the set of annotations to the Cluster.
 func handleAddClusterAnnotations(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -663,6 +1159,85 @@ func handleDeleteClusterAnnotation(c *Context, w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleGetClusterBackups responds to GET /api/cluster/{cluster}/backups,
+// returning the Velero backups of the cluster.
+func handleGetClusterBackups(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID).WithField("action", "get-cluster-backups")
+
+	cluster, err := c.Store.GetCluster(clusterID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if cluster == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	backups, err := c.Provisioner.GetClusterBackups(cluster)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get cluster backups")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, backups)
+}
+
+// handleCreateClusterBackup responds to POST /api/cluster/{cluster}/backups,
+// triggering a Velero backup of the requested namespaces of the cluster.
+func handleCreateClusterBackup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID).WithField("action", "create-cluster-backup")
+
+	createClusterBackupRequest, err := model.NewCreateClusterBackupRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cluster, err := c.Store.GetCluster(clusterID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if cluster == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if cluster.APISecurityLock {
+		logSecurityLockConflict("cluster", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if cluster.State != model.ClusterStateStable {
+		c.Logger.Warnf("unable to back up cluster while in state %s", cluster.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	backup, err := c.Provisioner.CreateClusterBackup(cluster, createClusterBackupRequest.Namespaces)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to create cluster backup")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, backup)
+}
+
 func annotationsFromRequest(req *http.Request) ([]*model.Annotation, error) {
 	annotationsRequest, err := model.NewAddAnnotationsRequestFromReader(req.Body)
 	if err != nil {
@@ -17,6 +17,7 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/internal/testlib"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -328,6 +329,28 @@ func TestCreateCluster(t *testing.T) {
 		// TODO: more fields...
 	})
 
+	t.Run("region and assume role", func(t *testing.T) {
+		cluster, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:      model.ProviderAWS,
+			Region:        "eu-central-1",
+			AssumeRoleARN: "arn:aws:iam::123456789012:role/provisioner",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, cluster.ProviderMetadataAWS)
+		assert.Equal(t, "eu-central-1", cluster.AWSRegion())
+		assert.Equal(t, "arn:aws:iam::123456789012:role/provisioner", cluster.AWSAssumeRoleARN())
+		assert.Equal(t, []string{"eu-central-1a"}, cluster.ProviderMetadataAWS.Zones)
+	})
+
+	t.Run("zone outside of region", func(t *testing.T) {
+		_, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider: model.ProviderAWS,
+			Region:   "eu-central-1",
+			Zones:    []string{"us-east-1a"},
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
 	t.Run("handle annotations", func(t *testing.T) {
 		annotations := []*model.Annotation{
 			{ID: "", Name: "multi-tenant"},
@@ -362,6 +385,114 @@ func TestCreateCluster(t *testing.T) {
 	})
 }
 
+func TestImportCluster(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	provisioner := &mockProvisioner{}
+	awsClient := &mockAwsClient{}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:       sqlStore,
+		Supervisor:  &mockSupervisor{},
+		Provisioner: provisioner,
+		AwsClient:   awsClient,
+		Logger:      logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid payload", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/api/clusters/import", ts.URL), "application/json", bytes.NewReader([]byte("invalid")))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("missing kops name and kubeconfig", func(t *testing.T) {
+		_, err := client.ImportCluster(&model.ImportClusterRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("unreachable cluster", func(t *testing.T) {
+		provisioner.CommandError = errors.New("connection refused")
+		defer func() { provisioner.CommandError = nil }()
+
+		_, err := client.ImportCluster(&model.ImportClusterRequest{KopsName: "cluster.k8s.local"})
+		require.EqualError(t, err, "failed with status code 400")
+
+		clusters, err := client.GetClusters(&model.GetClustersRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		assert.Empty(t, clusters)
+	})
+
+	t.Run("kops cluster", func(t *testing.T) {
+		cluster, err := client.ImportCluster(&model.ImportClusterRequest{
+			KopsName:           "cluster.k8s.local",
+			KopsStateStore:     "other-state-store",
+			AllowInstallations: true,
+			Annotations:        []string{"my-annotation"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateProvisioningRequested, cluster.State)
+		assert.Equal(t, model.ProvisionerKops, cluster.Provisioner)
+		assert.True(t, cluster.AllowInstallations)
+		assert.True(t, cluster.IsImported())
+		require.NotNil(t, cluster.ProvisionerMetadataKops)
+		assert.Equal(t, "cluster.k8s.local", cluster.ProvisionerMetadataKops.Name)
+		assert.Equal(t, "other-state-store", cluster.ProvisionerMetadataKops.StateStore)
+		assert.Nil(t, cluster.ProvisionerMetadataKops.ChangeRequest)
+		nginxVersion, err := cluster.DesiredUtilityVersion(model.NginxCanonicalName)
+		require.NoError(t, err)
+		assert.Equal(t, model.NginxDefaultVersion, nginxVersion)
+		assert.Len(t, cluster.Annotations, 1)
+
+		t.Run("upgrade is rejected", func(t *testing.T) {
+			cluster.State = model.ClusterStateStable
+			err = sqlStore.UpdateCluster(cluster.Cluster)
+			require.NoError(t, err)
+
+			_, err := client.UpgradeCluster(cluster.ID, &model.PatchUpgradeClusterRequest{Version: sToP("latest")})
+			require.EqualError(t, err, "failed with status code 400")
+		})
+
+		t.Run("resize is rejected", func(t *testing.T) {
+			_, err := client.ResizeCluster(cluster.ID, &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.xlarge")})
+			require.EqualError(t, err, "failed with status code 400")
+		})
+	})
+
+	t.Run("kubeconfig", func(t *testing.T) {
+		cluster, err := client.ImportCluster(&model.ImportClusterRequest{Kubeconfig: "apiVersion: v1"})
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateProvisioningRequested, cluster.State)
+		assert.Equal(t, model.ProvisionerExternal, cluster.Provisioner)
+		assert.Nil(t, cluster.ProvisionerMetadataKops)
+		require.NotNil(t, cluster.ProvisionerMetadataExternal)
+		assert.Equal(t, "imported-cluster", cluster.ProvisionerMetadataExternal.Name)
+		assert.Empty(t, cluster.ProvisionerMetadataExternal.Kubeconfig)
+
+		storedCluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		assert.Empty(t, storedCluster.ProvisionerMetadataExternal.Kubeconfig)
+		secretName := storedCluster.ProvisionerMetadataExternal.KubeconfigSecret
+		require.NotEmpty(t, secretName)
+		assert.Equal(t, "apiVersion: v1", awsClient.KubeconfigSecrets[secretName])
+	})
+
+	t.Run("kubeconfig secret failure", func(t *testing.T) {
+		awsClient.Err = errors.New("secrets manager unavailable")
+		defer func() { awsClient.Err = nil }()
+
+		_, err := client.ImportCluster(&model.ImportClusterRequest{Kubeconfig: "apiVersion: v1"})
+		require.EqualError(t, err, "failed with status code 500")
+	})
+
+	assert.Equal(t, 4, provisioner.ImportCalls)
+}
+
 func TestRetryCreateCluster(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -765,6 +896,130 @@ func TestUpgradeCluster(t *testing.T) {
 	})
 }
 
+func TestResumeAndAbortClusterUpgrade(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	cluster1, err := client.CreateCluster(&model.CreateClusterRequest{
+		Provider: model.ProviderAWS,
+		Zones:    []string{"zone"},
+	})
+	require.NoError(t, err)
+
+	t.Run("unknown cluster", func(t *testing.T) {
+		clusterResp, err := client.ResumeClusterUpgrade(model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, clusterResp)
+
+		clusterResp, err = client.AbortClusterUpgrade(model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, clusterResp)
+	})
+
+	t.Run("while not paused", func(t *testing.T) {
+		cluster1.State = model.ClusterStateUpgradeRequested
+		err = sqlStore.UpdateCluster(cluster1.Cluster)
+		require.NoError(t, err)
+
+		clusterResp, err := client.ResumeClusterUpgrade(cluster1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, clusterResp)
+
+		clusterResp, err = client.AbortClusterUpgrade(cluster1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, clusterResp)
+	})
+
+	t.Run("while api-security-locked", func(t *testing.T) {
+		cluster1.State = model.ClusterStateUpgradePaused
+		err = sqlStore.UpdateCluster(cluster1.Cluster)
+		require.NoError(t, err)
+
+		err = sqlStore.LockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+
+		clusterResp, err := client.ResumeClusterUpgrade(cluster1.ID)
+		require.EqualError(t, err, "failed with status code 403")
+		assert.Nil(t, clusterResp)
+
+		err = sqlStore.UnlockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		cluster1.State = model.ClusterStateUpgradePaused
+		err = sqlStore.UpdateCluster(cluster1.Cluster)
+		require.NoError(t, err)
+
+		clusterResp, err := client.ResumeClusterUpgrade(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUpgradeRequested, clusterResp.State)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUpgradeRequested, cluster1.State)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		cluster1.State = model.ClusterStateUpgradePaused
+		err = sqlStore.UpdateCluster(cluster1.Cluster)
+		require.NoError(t, err)
+
+		clusterResp, err := client.AbortClusterUpgrade(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUpgradeAbortRequested, clusterResp.State)
+
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUpgradeAbortRequested, cluster1.State)
+	})
+
+	t.Run("while upgrade failed", func(t *testing.T) {
+		cluster1.State = model.ClusterStateUpgradeFailed
+		err = sqlStore.UpdateCluster(cluster1.Cluster)
+		require.NoError(t, err)
+
+		clusterResp, err := client.ResumeClusterUpgrade(cluster1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, clusterResp)
+
+		clusterResp, err = client.AbortClusterUpgrade(cluster1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, clusterResp)
+	})
+
+	t.Run("abort failed staged upgrade", func(t *testing.T) {
+		cluster1.State = model.ClusterStateUpgradeFailed
+		cluster1.ProvisionerMetadataKops.ChangeRequest = &model.KopsMetadataRequestedState{
+			Version:         "1.19.4",
+			UpgradeStrategy: &model.KopsUpgradeStrategy{BatchSize: 1},
+			UpgradeProgress: &model.KopsUpgradeProgress{SpecApplied: true},
+		}
+		err = sqlStore.UpdateCluster(cluster1.Cluster)
+		require.NoError(t, err)
+
+		clusterResp, err := client.ResumeClusterUpgrade(cluster1.ID)
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, clusterResp)
+
+		clusterResp, err = client.AbortClusterUpgrade(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUpgradeAbortRequested, clusterResp.State)
+	})
+}
+
 func TestUpdateClusterConfiguration(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -1005,6 +1260,209 @@ func TestResizeCluster(t *testing.T) {
 	})
 }
 
+func TestEKSCluster(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid provisioner", func(t *testing.T) {
+		_, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:    model.ProviderAWS,
+			Provisioner: "unknown",
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("kops options", func(t *testing.T) {
+		_, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:    model.ProviderAWS,
+			Provisioner: model.ProvisionerEKS,
+			KopsAMI:     "ami-123",
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	cluster1, err := client.CreateCluster(&model.CreateClusterRequest{
+		Provider:    model.ProviderAWS,
+		Provisioner: model.ProvisionerEKS,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.ProvisionerEKS, cluster1.Provisioner)
+	assert.Equal(t, []string{"us-east-1a", "us-east-1b"}, cluster1.ProviderMetadataAWS.Zones)
+	assert.Nil(t, cluster1.ProvisionerMetadataKops)
+	require.NotNil(t, cluster1.ProvisionerMetadataEKS)
+	require.NotNil(t, cluster1.ProvisionerMetadataEKS.ChangeRequest)
+	assert.Equal(t, "m5.large", cluster1.ProvisionerMetadataEKS.ChangeRequest.NodeInstanceType)
+
+	t.Run("allow installations", func(t *testing.T) {
+		clusterResp, err := client.UpdateCluster(cluster1.ID, &model.UpdateClusterRequest{AllowInstallations: true})
+		require.NoError(t, err)
+		assert.True(t, clusterResp.AllowInstallations)
+	})
+
+	cluster1.State = model.ClusterStateStable
+	cluster1.ProvisionerMetadataEKS = &model.EKSMetadata{
+		Name:             "eks-cluster",
+		Version:          "1.16",
+		NodeGroupName:    "nodes-m5-large",
+		NodeInstanceType: "m5.large",
+		NodeMinCount:     2,
+		NodeMaxCount:     2,
+	}
+	err = sqlStore.UpdateCluster(cluster1.Cluster)
+	require.NoError(t, err)
+
+	t.Run("upgrade with kops ami", func(t *testing.T) {
+		_, err := client.UpgradeCluster(cluster1.ID, &model.PatchUpgradeClusterRequest{KopsAMI: sToP("ami-123")})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("upgrade dry-run", func(t *testing.T) {
+		_, err := client.PreviewUpgradeCluster(cluster1.ID, &model.PatchUpgradeClusterRequest{Version: sToP("1.17.9")})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		clusterResp, err := client.UpgradeCluster(cluster1.ID, &model.PatchUpgradeClusterRequest{Version: sToP("1.17.9")})
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUpgradeRequested, clusterResp.State)
+		require.NotNil(t, clusterResp.ProvisionerMetadataEKS.ChangeRequest)
+		assert.Equal(t, "1.17.9", clusterResp.ProvisionerMetadataEKS.ChangeRequest.Version)
+	})
+
+	cluster1.State = model.ClusterStateStable
+	err = sqlStore.UpdateCluster(cluster1.Cluster)
+	require.NoError(t, err)
+
+	t.Run("resize with max lower than min", func(t *testing.T) {
+		maxCount := int64(1)
+		_, err := client.ResizeCluster(cluster1.ID, &model.PatchClusterSizeRequest{NodeMaxCount: &maxCount})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("resize", func(t *testing.T) {
+		clusterResp, err := client.ResizeCluster(cluster1.ID, &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.xlarge")})
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateResizeRequested, clusterResp.State)
+		require.NotNil(t, clusterResp.ProvisionerMetadataEKS.ChangeRequest)
+		assert.Equal(t, "m5.xlarge", clusterResp.ProvisionerMetadataEKS.ChangeRequest.NodeInstanceType)
+	})
+}
+
+func TestPreviewClusterChanges(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	provisioner := &mockProvisioner{}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:       sqlStore,
+		Supervisor:  &mockSupervisor{},
+		Provisioner: provisioner,
+		Logger:      logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	cluster1, err := client.CreateCluster(&model.CreateClusterRequest{
+		Provider: model.ProviderAWS,
+		Zones:    []string{"zone"},
+	})
+	require.NoError(t, err)
+
+	cluster1.State = model.ClusterStateStable
+	err = sqlStore.UpdateCluster(cluster1.Cluster)
+	require.NoError(t, err)
+
+	t.Run("unknown cluster", func(t *testing.T) {
+		preview, err := client.PreviewUpgradeCluster(model.NewID(), &model.PatchUpgradeClusterRequest{Version: sToP("latest")})
+		require.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, preview)
+	})
+
+	t.Run("invalid dry-run parameter", func(t *testing.T) {
+		httpRequest, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/cluster/%s/size?dry-run=maybe", ts.URL, cluster1.ID), bytes.NewReader([]byte(`{}`)))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(httpRequest)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("preview upgrade", func(t *testing.T) {
+		preview, err := client.PreviewUpgradeCluster(cluster1.ID, &model.PatchUpgradeClusterRequest{Version: sToP("1.16.9")})
+		require.NoError(t, err)
+		assert.Equal(t, cluster1.ID, preview.ClusterID)
+		assert.True(t, preview.HasChanges())
+		assert.Equal(t, "1.16.9", preview.ChangeRequest.Version)
+		assert.Equal(t, "kops update preview", preview.KopsUpdate)
+		assert.Equal(t, "terraform plan preview", preview.TerraformPlan)
+		assert.Equal(t, 1, provisioner.PreviewCalls)
+
+		cluster, err := client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateStable, cluster.State)
+		assert.Equal(t, cluster1.ProvisionerMetadataKops.ChangeRequest, cluster.ProvisionerMetadataKops.ChangeRequest)
+	})
+
+	t.Run("preview resize", func(t *testing.T) {
+		preview, err := client.PreviewResizeCluster(cluster1.ID, &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.xlarge")})
+		require.NoError(t, err)
+		assert.True(t, preview.HasChanges())
+		assert.Equal(t, "m5.xlarge", preview.ChangeRequest.NodeInstanceType)
+		assert.Equal(t, 2, provisioner.PreviewCalls)
+
+		cluster, err := client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateStable, cluster.State)
+	})
+
+	t.Run("preview without changes", func(t *testing.T) {
+		preview, err := client.PreviewResizeCluster(cluster1.ID, &model.PatchClusterSizeRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, cluster1.ID, preview.ClusterID)
+		assert.False(t, preview.HasChanges())
+		assert.Equal(t, 2, provisioner.PreviewCalls)
+	})
+
+	t.Run("preview failure", func(t *testing.T) {
+		provisioner.CommandError = errors.New("kops failure")
+		defer func() { provisioner.CommandError = nil }()
+
+		preview, err := client.PreviewUpgradeCluster(cluster1.ID, &model.PatchUpgradeClusterRequest{Version: sToP("1.16.9")})
+		require.EqualError(t, err, "failed with status code 500")
+		assert.Nil(t, preview)
+
+		cluster, err := client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateStable, cluster.State)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		cluster1.State = model.ClusterStateDeletionRequested
+		err = sqlStore.UpdateCluster(cluster1.Cluster)
+		require.NoError(t, err)
+
+		preview, err := client.PreviewUpgradeCluster(cluster1.ID, &model.PatchUpgradeClusterRequest{Version: sToP("1.16.9")})
+		require.EqualError(t, err, "failed with status code 400")
+		assert.Nil(t, preview)
+	})
+}
+
 func TestDeleteCluster(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -1088,6 +1546,8 @@ func TestDeleteCluster(t *testing.T) {
 		model.ClusterStateProvisioningFailed,
 		model.ClusterStateUpgradeRequested,
 		model.ClusterStateUpgradeFailed,
+		model.ClusterStateUpgradePaused,
+		model.ClusterStateUtilityUpgradeFailed,
 		model.ClusterStateDeletionRequested,
 		model.ClusterStateDeletionFailed,
 	}
@@ -1149,10 +1609,15 @@ func TestGetAllUtilityMetadata(t *testing.T) {
 				"prometheus-operator": "9.4.4",
 				"nginx":               "stable",
 			},
+			UtilityValuesOverrides: map[string]string{
+				"nginx": "controller:\n  replicaCount: 3\n",
+			},
 		})
 
 	require.NoError(t, err)
 	utilityMetadata, err := client.GetClusterUtilities(c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"nginx": "controller:\n  replicaCount: 3\n"}, utilityMetadata.ValuesOverrides)
 
 	assert.Equal(t, "", utilityMetadata.ActualVersions.Nginx)
 	assert.Equal(t, "", utilityMetadata.ActualVersions.Fluentbit)
@@ -1162,6 +1627,261 @@ func TestGetAllUtilityMetadata(t *testing.T) {
 	assert.Equal(t, model.FluentbitDefaultVersion, utilityMetadata.DesiredVersions.Fluentbit)
 }
 
+func TestUpdateClusterUtilities(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	cluster1, err := client.CreateCluster(&model.CreateClusterRequest{
+		Provider: model.ProviderAWS,
+		Zones:    []string{"zone"},
+	})
+	require.NoError(t, err)
+
+	request := &model.UpdateClusterUtilitiesRequest{
+		ValuesOverrides: map[string]string{"nginx": "controller:\n  replicaCount: 3\n"},
+	}
+
+	t.Run("unknown cluster", func(t *testing.T) {
+		_, err := client.UpdateClusterUtilities(model.NewID(), request)
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		httpRequest, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/cluster/%s/utilities", ts.URL, cluster1.ID), bytes.NewReader([]byte("invalid")))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(httpRequest)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("unknown utility", func(t *testing.T) {
+		_, err := client.UpdateClusterUtilities(cluster1.ID, &model.UpdateClusterUtilitiesRequest{
+			ValuesOverrides: map[string]string{"traefik": "replicas: 3"},
+		})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("while creating", func(t *testing.T) {
+		_, err := client.UpdateClusterUtilities(cluster1.ID, request)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	cluster1.State = model.ClusterStateStable
+	err = sqlStore.UpdateCluster(cluster1.Cluster)
+	require.NoError(t, err)
+
+	t.Run("while api-security-locked", func(t *testing.T) {
+		err = sqlStore.LockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+
+		_, err := client.UpdateClusterUtilities(cluster1.ID, request)
+		require.EqualError(t, err, "failed with status code 403")
+
+		err = sqlStore.UnlockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("update values", func(t *testing.T) {
+		clusterResp, err := client.UpdateClusterUtilities(cluster1.ID, request)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateProvisioningRequested, clusterResp.State)
+
+		utilityMetadata, err := client.GetClusterUtilities(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, request.ValuesOverrides, utilityMetadata.ValuesOverrides)
+	})
+
+	t.Run("remove values", func(t *testing.T) {
+		_, err := client.UpdateClusterUtilities(cluster1.ID, &model.UpdateClusterUtilitiesRequest{
+			ValuesOverrides: map[string]string{"nginx": ""},
+		})
+		require.NoError(t, err)
+
+		utilityMetadata, err := client.GetClusterUtilities(cluster1.ID)
+		require.NoError(t, err)
+		assert.Empty(t, utilityMetadata.ValuesOverrides)
+	})
+}
+
+func TestUpgradeClusterUtility(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	cluster1, err := client.CreateCluster(&model.CreateClusterRequest{
+		Provider:               model.ProviderAWS,
+		Zones:                  []string{"zone"},
+		DesiredUtilityVersions: map[string]string{model.NginxCanonicalName: "2.15.0"},
+	})
+	require.NoError(t, err)
+
+	request := &model.UpgradeClusterUtilityRequest{Version: "2.16.0"}
+
+	t.Run("unknown cluster", func(t *testing.T) {
+		_, err := client.UpgradeClusterUtility(model.NewID(), model.NginxCanonicalName, request)
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		httpRequest, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/cluster/%s/utilities/%s", ts.URL, cluster1.ID, model.NginxCanonicalName), bytes.NewReader([]byte("invalid")))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(httpRequest)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		_, err := client.UpgradeClusterUtility(cluster1.ID, model.NginxCanonicalName, &model.UpgradeClusterUtilityRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("while creating", func(t *testing.T) {
+		_, err := client.UpgradeClusterUtility(cluster1.ID, model.NginxCanonicalName, request)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	cluster1.State = model.ClusterStateStable
+	err = sqlStore.UpdateCluster(cluster1.Cluster)
+	require.NoError(t, err)
+
+	t.Run("unknown utility", func(t *testing.T) {
+		_, err := client.UpgradeClusterUtility(cluster1.ID, "traefik", request)
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	t.Run("while api-security-locked", func(t *testing.T) {
+		err = sqlStore.LockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+
+		_, err := client.UpgradeClusterUtility(cluster1.ID, model.NginxCanonicalName, request)
+		require.EqualError(t, err, "failed with status code 403")
+
+		err = sqlStore.UnlockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("upgrade utility", func(t *testing.T) {
+		clusterResp, err := client.UpgradeClusterUtility(cluster1.ID, model.NginxCanonicalName, request)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUtilityUpgradeRequested, clusterResp.State)
+
+		utilityMetadata, err := client.GetClusterUtilities(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "2.16.0", utilityMetadata.DesiredVersions.Nginx)
+		assert.Equal(t, &model.UtilityUpgrade{Name: model.NginxCanonicalName, Version: "2.16.0", PreviousVersion: "2.15.0"}, utilityMetadata.PendingUpgrade)
+	})
+
+	t.Run("roll back after failure", func(t *testing.T) {
+		cluster1, err = client.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		cluster1.State = model.ClusterStateUtilityUpgradeFailed
+		err = sqlStore.UpdateCluster(cluster1.Cluster)
+		require.NoError(t, err)
+
+		clusterResp, err := client.UpgradeClusterUtility(cluster1.ID, model.NginxCanonicalName, &model.UpgradeClusterUtilityRequest{Version: "2.15.0"})
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUtilityUpgradeRequested, clusterResp.State)
+	})
+
+	t.Run("eks cluster", func(t *testing.T) {
+		cluster2, err := client.CreateCluster(&model.CreateClusterRequest{
+			Provider:    model.ProviderAWS,
+			Provisioner: model.ProvisionerEKS,
+		})
+		require.NoError(t, err)
+		cluster2.State = model.ClusterStateStable
+		err = sqlStore.UpdateCluster(cluster2.Cluster)
+		require.NoError(t, err)
+
+		clusterResp, err := client.UpgradeClusterUtility(cluster2.ID, model.NginxCanonicalName, request)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClusterStateUtilityUpgradeRequested, clusterResp.State)
+	})
+}
+
+func TestGetClusterUtilityDrift(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("no clusters", func(t *testing.T) {
+		report, err := client.GetClusterUtilityDrift()
+		require.NoError(t, err)
+		require.Empty(t, report)
+	})
+
+	drifted := &model.Cluster{State: model.ClusterStateStable, ProvisionerMetadataKops: &model.KopsMetadata{}}
+	drifted.SetUtilityDriftCheck(&model.UtilityDriftCheck{
+		CheckedAt: 10,
+		Releases: []model.UtilityRelease{
+			{Name: model.NginxCanonicalName, Version: "2.16.0", ExpectedVersion: "2.15.0"},
+			{Name: model.VeleroCanonicalName, Version: "2.13.6", ExpectedVersion: "2.13.6"},
+		},
+	})
+	err := sqlStore.CreateCluster(drifted, nil)
+	require.NoError(t, err)
+
+	inSync := &model.Cluster{State: model.ClusterStateStable, ProvisionerMetadataKops: &model.KopsMetadata{}}
+	inSync.SetUtilityDriftCheck(&model.UtilityDriftCheck{
+		CheckedAt: 10,
+		Releases: []model.UtilityRelease{
+			{Name: model.NginxCanonicalName, Version: "2.15.0", ExpectedVersion: "2.15.0"},
+		},
+	})
+	err = sqlStore.CreateCluster(inSync, nil)
+	require.NoError(t, err)
+
+	err = sqlStore.CreateCluster(&model.Cluster{State: model.ClusterStateStable}, nil)
+	require.NoError(t, err)
+
+	t.Run("drifted clusters", func(t *testing.T) {
+		report, err := client.GetClusterUtilityDrift()
+		require.NoError(t, err)
+		require.Len(t, report, 1)
+		assert.Equal(t, drifted.ID, report[0].ClusterID)
+		assert.Equal(t, int64(10), report[0].CheckedAt)
+		require.Len(t, report[0].Releases, 1)
+		assert.Equal(t, model.NginxCanonicalName, report[0].Releases[0].Name)
+		assert.True(t, report[0].Releases[0].Drifted)
+	})
+}
+
 func TestClusterAnnotations(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -1284,3 +2004,87 @@ func containsAnnotation(name string, annotations []*model.Annotation) bool {
 	}
 	return false
 }
+
+func TestClusterBackups(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	provisioner := &mockProvisioner{}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:       sqlStore,
+		Supervisor:  &mockSupervisor{},
+		Provisioner: provisioner,
+		Logger:      logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	cluster1, err := client.CreateCluster(&model.CreateClusterRequest{
+		Provider: model.ProviderAWS,
+		Zones:    []string{"zone"},
+	})
+	require.NoError(t, err)
+
+	t.Run("unknown cluster", func(t *testing.T) {
+		_, err := client.GetClusterBackups(model.NewID())
+		require.EqualError(t, err, "failed with status code 404")
+
+		_, err = client.CreateClusterBackup(model.NewID(), &model.CreateClusterBackupRequest{})
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		resp, err := http.Post(fmt.Sprintf("%s/api/cluster/%s/backups", ts.URL, cluster1.ID), "application/json", bytes.NewReader([]byte("invalid")))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("while creating", func(t *testing.T) {
+		_, err := client.CreateClusterBackup(cluster1.ID, &model.CreateClusterBackupRequest{})
+		require.EqualError(t, err, "failed with status code 400")
+	})
+
+	cluster1.State = model.ClusterStateStable
+	err = sqlStore.UpdateCluster(cluster1.Cluster)
+	require.NoError(t, err)
+
+	t.Run("while api-security-locked", func(t *testing.T) {
+		err = sqlStore.LockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+
+		_, err := client.CreateClusterBackup(cluster1.ID, &model.CreateClusterBackupRequest{})
+		require.EqualError(t, err, "failed with status code 403")
+
+		err = sqlStore.UnlockClusterAPI(cluster1.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("provisioner error", func(t *testing.T) {
+		provisioner.CommandError = errors.New("velero is not installed")
+		defer func() { provisioner.CommandError = nil }()
+
+		_, err := client.CreateClusterBackup(cluster1.ID, &model.CreateClusterBackupRequest{})
+		require.EqualError(t, err, "failed with status code 500")
+
+		_, err = client.GetClusterBackups(cluster1.ID)
+		require.EqualError(t, err, "failed with status code 500")
+	})
+
+	t.Run("create and list backups", func(t *testing.T) {
+		backup1, err := client.CreateClusterBackup(cluster1.ID, &model.CreateClusterBackupRequest{Namespaces: []string{"ns1", "ns2"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ns1", "ns2"}, backup1.Namespaces)
+
+		backup2, err := client.CreateClusterBackup(cluster1.ID, &model.CreateClusterBackupRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"*"}, backup2.Namespaces)
+
+		backups, err := client.GetClusterBackups(cluster1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.ClusterBackup{backup1, backup2}, backups)
+	})
+}
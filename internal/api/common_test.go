@@ -7,6 +7,7 @@ package api_test
 import (
 	"github.com/mattermost/mattermost-cloud/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/sirupsen/logrus"
 )
 
 type mockSupervisor struct {
@@ -19,6 +20,9 @@ func (s *mockSupervisor) Do() error {
 type mockProvisioner struct {
 	Output       []byte
 	CommandError error
+	PreviewCalls int
+	ImportCalls  int
+	Backups      []*model.ClusterBackup
 }
 
 func (s *mockProvisioner) ExecClusterInstallationCLI(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation, args ...string) ([]byte, error) {
@@ -41,6 +45,169 @@ func (s *mockProvisioner) GetClusterResources(*model.Cluster, bool) (*k8s.Cluste
 	return nil, nil
 }
 
+func (s *mockProvisioner) PreviewClusterChanges(cluster *model.Cluster) (*model.ClusterChangePreview, error) {
+	s.PreviewCalls++
+	if s.CommandError != nil {
+		return nil, s.CommandError
+	}
+
+	return &model.ClusterChangePreview{
+		ClusterID:     cluster.ID,
+		ChangeRequest: cluster.ProvisionerMetadataKops.ChangeRequest,
+		KopsUpdate:    "kops update preview",
+		TerraformPlan: "terraform plan preview",
+	}, nil
+}
+
+func (s *mockProvisioner) ImportCluster(cluster *model.Cluster) error {
+	s.ImportCalls++
+	if s.CommandError != nil {
+		return s.CommandError
+	}
+	if cluster.ProvisionerMetadataExternal != nil {
+		cluster.ProvisionerMetadataExternal.Name = "imported-cluster"
+	}
+
+	return nil
+}
+
+func (s *mockProvisioner) CreateClusterBackup(cluster *model.Cluster, namespaces []string) (*model.ClusterBackup, error) {
+	if s.CommandError != nil {
+		return nil, s.CommandError
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{"*"}
+	}
+
+	backup := &model.ClusterBackup{Name: model.NewID(), Namespaces: namespaces}
+	s.Backups = append(s.Backups, backup)
+
+	return backup, nil
+}
+
+func (s *mockProvisioner) GetClusterBackups(cluster *model.Cluster) ([]*model.ClusterBackup, error) {
+	if s.CommandError != nil {
+		return nil, s.CommandError
+	}
+
+	return s.Backups, nil
+}
+
+func (s *mockProvisioner) GetClusterInstallationCertificate(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (*model.InstallationCertificate, error) {
+	if s.CommandError != nil {
+		return nil, s.CommandError
+	}
+
+	return &model.InstallationCertificate{
+		InstallationID: installation.ID,
+		ClusterID:      cluster.ID,
+		Status:         model.CertificateStatusReady,
+		DNSNames:       []string{installation.DNS},
+	}, nil
+}
+
+type mockAwsClient struct {
+	DatabaseSecrets   map[string]*model.ExternalDatabaseRequest
+	FilestoreSecrets  map[string]*model.ExternalFilestoreRequest
+	KubeconfigSecrets map[string]string
+	ReconcileRepair   bool
+	VpcPools          map[string][]*model.VPC
+	Err               error
+	FilestoreErr      error
+}
+
+func (a *mockAwsClient) GetRegionalVpcPool(region, assumeRoleARN string, logger logrus.FieldLogger) ([]*model.VPC, error) {
+	if a.Err != nil {
+		return nil, a.Err
+	}
+
+	return a.VpcPools[region], nil
+}
+
+func (a *mockAwsClient) EnsureExternalDatabaseSecret(installationID string, request *model.ExternalDatabaseRequest, logger logrus.FieldLogger) error {
+	if a.Err != nil {
+		return a.Err
+	}
+	if a.DatabaseSecrets == nil {
+		a.DatabaseSecrets = make(map[string]*model.ExternalDatabaseRequest)
+	}
+	a.DatabaseSecrets[installationID] = request
+
+	return nil
+}
+
+func (a *mockAwsClient) EnsureExternalFilestoreSecret(installationID string, request *model.ExternalFilestoreRequest, logger logrus.FieldLogger) error {
+	if a.Err != nil {
+		return a.Err
+	}
+	if a.FilestoreErr != nil {
+		return a.FilestoreErr
+	}
+	if a.FilestoreSecrets == nil {
+		a.FilestoreSecrets = make(map[string]*model.ExternalFilestoreRequest)
+	}
+	a.FilestoreSecrets[installationID] = request
+
+	return nil
+}
+
+func (a *mockAwsClient) EnsureExternalDatabaseSecretDeleted(installationID string, logger logrus.FieldLogger) error {
+	delete(a.DatabaseSecrets, installationID)
+
+	return nil
+}
+
+func (a *mockAwsClient) EnsureExternalFilestoreSecretDeleted(installationID string, logger logrus.FieldLogger) error {
+	delete(a.FilestoreSecrets, installationID)
+
+	return nil
+}
+
+func (a *mockAwsClient) CreateKubeconfigSecret(kubeconfig string, logger logrus.FieldLogger) (string, error) {
+	if a.Err != nil {
+		return "", a.Err
+	}
+	if a.KubeconfigSecrets == nil {
+		a.KubeconfigSecrets = make(map[string]string)
+	}
+	secretName := "cloud-" + model.NewID() + "-kubeconfig"
+	a.KubeconfigSecrets[secretName] = kubeconfig
+
+	return secretName, nil
+}
+
+func (a *mockAwsClient) EnsureKubeconfigSecretDeleted(secretName string, logger logrus.FieldLogger) error {
+	delete(a.KubeconfigSecrets, secretName)
+
+	return nil
+}
+
+func (a *mockAwsClient) ReconcileMultitenantDatabases(store model.MultitenantDatabaseReconcileStoreInterface, lockerID string, repair bool, logger logrus.FieldLogger) (*model.MultitenantDatabaseReconcileReport, error) {
+	if a.Err != nil {
+		return nil, a.Err
+	}
+	a.ReconcileRepair = repair
+
+	databases, err := store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.MultitenantDatabaseReconcileReport{Repair: repair}
+	for _, database := range databases {
+		report.Databases = append(report.Databases, &model.MultitenantDatabaseReconcileResult{
+			ID:                  database.ID,
+			StoredInstallations: database.Installations.Count(),
+			CounterTag:          database.Installations.Count(),
+		})
+	}
+
+	return report, nil
+}
+
 func sToP(s string) *string {
 	return &s
 }
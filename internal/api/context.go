@@ -41,6 +41,7 @@ type Store interface {
 	LockInstallationAPI(installationID string) error
 	UnlockInstallationAPI(installationID string) error
 	DeleteInstallation(installationID string) error
+	GetInstallationUsages(filter *model.InstallationUsageFilter) ([]*model.InstallationUsage, error)
 
 	GetClusterInstallation(clusterInstallationID string) (*model.ClusterInstallation, error)
 	GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
@@ -63,7 +64,11 @@ type Store interface {
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
 	DeleteWebhook(webhookID string) error
 
+	GetMultitenantDatabase(multitenantdatabaseID string) (*model.MultitenantDatabase, error)
 	GetMultitenantDatabases(filter *model.MultitenantDatabaseFilter) ([]*model.MultitenantDatabase, error)
+	UpdateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase) error
+	LockMultitenantDatabase(multitenantdatabaseID, lockerID string) (bool, error)
+	UnlockMultitenantDatabase(multitenantdatabaseID, lockerID string, force bool) (bool, error)
 
 	GetOrCreateAnnotations(annotations []*model.Annotation) ([]*model.Annotation, error)
 
@@ -72,6 +77,11 @@ type Store interface {
 
 	CreateInstallationAnnotations(installationID string, annotations []*model.Annotation) ([]*model.Annotation, error)
 	DeleteInstallationAnnotation(installationID string, annotationName string) error
+
+	CreateInstallationDomain(domain *model.InstallationDomain) error
+	GetInstallationDomain(id string) (*model.InstallationDomain, error)
+	GetInstallationDomains(filter *model.InstallationDomainFilter) ([]*model.InstallationDomain, error)
+	UpdateInstallationDomain(domain *model.InstallationDomain) error
 }
 
 // Provisioner describes the interface required to communicate with the Kubernetes cluster.
@@ -79,6 +89,24 @@ type Provisioner interface {
 	ExecClusterInstallationCLI(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation, args ...string) ([]byte, error)
 	ExecMattermostCLI(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation, args ...string) ([]byte, error)
 	GetClusterResources(*model.Cluster, bool) (*k8s.ClusterResources, error)
+	PreviewClusterChanges(cluster *model.Cluster) (*model.ClusterChangePreview, error)
+	ImportCluster(cluster *model.Cluster) error
+	CreateClusterBackup(cluster *model.Cluster, namespaces []string) (*model.ClusterBackup, error)
+	GetClusterBackups(cluster *model.Cluster) ([]*model.ClusterBackup, error)
+	GetClusterInstallationCertificate(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (*model.InstallationCertificate, error)
+}
+
+// AwsClient describes the interface required to store installation and
+// cluster secrets in AWS and to reconcile AWS resources.
+type AwsClient interface {
+	EnsureExternalDatabaseSecret(installationID string, request *model.ExternalDatabaseRequest, logger logrus.FieldLogger) error
+	EnsureExternalFilestoreSecret(installationID string, request *model.ExternalFilestoreRequest, logger logrus.FieldLogger) error
+	EnsureExternalDatabaseSecretDeleted(installationID string, logger logrus.FieldLogger) error
+	EnsureExternalFilestoreSecretDeleted(installationID string, logger logrus.FieldLogger) error
+	CreateKubeconfigSecret(kubeconfig string, logger logrus.FieldLogger) (string, error)
+	EnsureKubeconfigSecretDeleted(secretName string, logger logrus.FieldLogger) error
+	ReconcileMultitenantDatabases(store model.MultitenantDatabaseReconcileStoreInterface, lockerID string, repair bool, logger logrus.FieldLogger) (*model.MultitenantDatabaseReconcileReport, error)
+	GetRegionalVpcPool(region, assumeRoleARN string, logger logrus.FieldLogger) ([]*model.VPC, error)
 }
 
 // Context provides the API with all necessary data and interfaces for responding to requests.
@@ -88,8 +116,13 @@ type Context struct {
 	Store       Store
 	Supervisor  Supervisor
 	Provisioner Provisioner
+	AwsClient   AwsClient
 	RequestID   string
 	Logger      logrus.FieldLogger
+
+	// CertManagerTLS indicates that installation ingresses are issued
+	// certificates with cert-manager, which custom domains require.
+	CertManagerTLS bool
 }
 
 // Clone creates a shallow copy of context, allowing clones to apply per-request changes.
@@ -98,6 +131,9 @@ func (c *Context) Clone() *Context {
 		Store:       c.Store,
 		Supervisor:  c.Supervisor,
 		Provisioner: c.Provisioner,
+		AwsClient:   c.AwsClient,
 		Logger:      c.Logger,
+
+		CertManagerTLS: c.CertManagerTLS,
 	}
 }
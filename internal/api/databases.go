@@ -19,6 +19,7 @@ func initDatabases(apiRouter *mux.Router, context *Context) {
 
 	databaseRouter := apiRouter.PathPrefix("/databases").Subrouter()
 	databaseRouter.Handle("", addContext(handleGetDatabases)).Methods("GET")
+	databaseRouter.Handle("/reconcile", addContext(handleReconcileDatabases)).Methods("POST")
 }
 
 // handleGetDatabases responds to GET /api/databases, returning a list of
@@ -56,3 +57,26 @@ func handleGetDatabases(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	outputJSON(c, w, databases)
 }
+
+// handleReconcileDatabases responds to POST /api/databases/reconcile,
+// comparing every multitenant database against its AWS resources and
+// returning a report of the inconsistencies found.
+func handleReconcileDatabases(c *Context, w http.ResponseWriter, r *http.Request) {
+	reconcileRequest, err := model.NewReconcileMultitenantDatabasesRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	report, err := c.AwsClient.ReconcileMultitenantDatabases(c.Store, c.RequestID, reconcileRequest.Repair, c.Logger)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to reconcile multitenant databases")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, report)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileMultitenantDatabases(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	awsClient := &mockAwsClient{}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		AwsClient:  awsClient,
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	database := &model.MultitenantDatabase{
+		ID:            "rds-cluster-multitenant-1",
+		VpcID:         "vpc-1",
+		DatabaseType:  model.DatabaseEngineTypeMySQL,
+		Installations: model.MultitenantDatabaseInstallations{"installation1", "installation2"},
+	}
+	err := sqlStore.CreateMultitenantDatabase(database)
+	require.NoError(t, err)
+
+	t.Run("report", func(t *testing.T) {
+		report, err := client.ReconcileMultitenantDatabases(&model.ReconcileMultitenantDatabasesRequest{})
+		require.NoError(t, err)
+		require.False(t, report.Repair)
+		require.False(t, awsClient.ReconcileRepair)
+		require.Len(t, report.Databases, 1)
+		require.Equal(t, database.ID, report.Databases[0].ID)
+		require.Equal(t, 2, report.Databases[0].StoredInstallations)
+		require.True(t, report.Databases[0].IsConsistent())
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		report, err := client.ReconcileMultitenantDatabases(&model.ReconcileMultitenantDatabasesRequest{Repair: true})
+		require.NoError(t, err)
+		require.True(t, report.Repair)
+		require.True(t, awsClient.ReconcileRepair)
+	})
+
+	t.Run("aws error", func(t *testing.T) {
+		awsClient.Err = errors.New("aws failure")
+		defer func() { awsClient.Err = nil }()
+
+		_, err := client.ReconcileMultitenantDatabases(&model.ReconcileMultitenantDatabasesRequest{})
+		require.EqualError(t, err, "failed with status code 500")
+	})
+}
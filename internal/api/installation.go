@@ -5,7 +5,6 @@
 package api
 
 import (
-	"errors"
 	"net/http"
 	"time"
 
@@ -14,6 +13,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-cloud/internal/webhook"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
 )
 
 // initInstallation registers installation endpoints on the given router.
@@ -35,9 +35,16 @@ func initInstallation(apiRouter *mux.Router, context *Context) {
 	installationRouter.Handle("/group", addContext(handleLeaveGroup)).Methods("DELETE")
 	installationRouter.Handle("/hibernate", addContext(handleHibernateInstallation)).Methods("POST")
 	installationRouter.Handle("/wakeup", addContext(handleWakeupInstallation)).Methods("POST")
+	installationRouter.Handle("/filestore/migrate", addContext(handleMigrateInstallationFilestore)).Methods("POST")
+	installationRouter.Handle("/usage", addContext(handleGetInstallationUsage)).Methods("GET")
+	installationRouter.Handle("/certificate", addContext(handleGetInstallationCertificate)).Methods("GET")
+	installationRouter.Handle("/rotate-credentials", addContext(handleRotateInstallationCredentials)).Methods("POST")
 	installationRouter.Handle("", addContext(handleDeleteInstallation)).Methods("DELETE")
 	installationRouter.Handle("/annotations", addContext(handleAddInstallationAnnotations)).Methods("POST")
 	installationRouter.Handle("/annotation/{annotation-name}", addContext(handleDeleteInstallationAnnotation)).Methods("DELETE")
+	installationRouter.Handle("/domains", addContext(handleGetInstallationDomains)).Methods("GET")
+	installationRouter.Handle("/domains", addContext(handleCreateInstallationDomain)).Methods("POST")
+	installationRouter.Handle("/domain/{domain:[A-Za-z0-9]{26}}", addContext(handleDeleteInstallationDomain)).Methods("DELETE")
 }
 
 // handleGetInstallation responds to GET /api/installation/{installation}, returning the installation in question.
@@ -172,12 +179,23 @@ func handleCreateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 		License:                    createInstallationRequest.License,
 		Size:                       createInstallationRequest.Size,
 		Affinity:                   createInstallationRequest.Affinity,
+		NodeGroup:                  createInstallationRequest.NodeGroup,
 		APISecurityLock:            createInstallationRequest.APISecurityLock,
 		MattermostEnv:              createInstallationRequest.MattermostEnv,
 		SingleTenantDatabaseConfig: createInstallationRequest.SingleTenantDatabaseConfig.ToDBConfig(createInstallationRequest.Database),
+		NetworkIsolation:           createInstallationRequest.NetworkIsolation,
+		ResourceQuota:              createInstallationRequest.ResourceQuota,
+		CustomSize:                 createInstallationRequest.CustomSize,
 		State:                      model.InstallationStateCreationRequested,
 	}
 
+	// The supervisor must not pick up the installation before the secrets of
+	// its external resources are stored.
+	externalResources := usesExternalResources(&installation)
+	if externalResources {
+		installation.State = model.InstallationStateCreationPending
+	}
+
 	annotations, err := model.AnnotationsFromStringSlice(createInstallationRequest.Annotations)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to validate extra annotations")
@@ -192,6 +210,30 @@ func handleCreateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if externalResources {
+		err = storeExternalResourceSecrets(c, &installation, createInstallationRequest)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to store external resource secrets")
+			deleteExternalResourceSecrets(c, &installation)
+			err = c.Store.DeleteInstallation(installation.ID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to delete installation without external resource secrets")
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// The secrets are stored at this point, so an installation left
+		// pending can be moved on by retrying its creation.
+		installation.State = model.InstallationStateCreationRequested
+		err = c.Store.UpdateInstallation(&installation)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to request creation of installation with external resources; retry the installation creation to continue")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
 	webhookPayload := &model.WebhookPayload{
 		Type:      model.TypeInstallation,
 		ID:        installation.ID,
@@ -213,6 +255,59 @@ func handleCreateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 	outputJSON(c, w, installation.ToDTO(annotations))
 }
 
+// storeExternalResourceSecrets keeps the connection details of any external
+// database or filestore requested for the installation in AWS so that they are
+// never persisted in the provisioner database.
+func storeExternalResourceSecrets(c *Context, installation *model.Installation, request *model.CreateInstallationRequest) error {
+	if c.AwsClient == nil {
+		return errors.New("external resources are not supported by this server")
+	}
+
+	if installation.Database == model.InstallationDatabaseExternal {
+		err := c.AwsClient.EnsureExternalDatabaseSecret(installation.ID, request.ExternalDatabaseConfig, c.Logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to store external database secret")
+		}
+	}
+	if installation.Filestore == model.InstallationFilestoreExternal {
+		err := c.AwsClient.EnsureExternalFilestoreSecret(installation.ID, request.ExternalFilestoreConfig, c.Logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to store external filestore secret")
+		}
+	}
+
+	return nil
+}
+
+// deleteExternalResourceSecrets removes any secrets stored for the external
+// resources of an installation whose creation is abandoned. Failures are only
+// logged as the secrets are not usable without the installation.
+func deleteExternalResourceSecrets(c *Context, installation *model.Installation) {
+	if c.AwsClient == nil {
+		return
+	}
+
+	if installation.Database == model.InstallationDatabaseExternal {
+		err := c.AwsClient.EnsureExternalDatabaseSecretDeleted(installation.ID, c.Logger)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to delete external database secret")
+		}
+	}
+	if installation.Filestore == model.InstallationFilestoreExternal {
+		err := c.AwsClient.EnsureExternalFilestoreSecretDeleted(installation.ID, c.Logger)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to delete external filestore secret")
+		}
+	}
+}
+
+// usesExternalResources returns true if the installation uses an external
+// database or filestore.
+func usesExternalResources(installation *model.Installation) bool {
+	return installation.Database == model.InstallationDatabaseExternal ||
+		installation.Filestore == model.InstallationFilestoreExternal
+}
+
 // handleRetryCreateInstallation responds to POST /api/installation/{installation}, retrying a
 // previously failed creation.
 //
@@ -308,6 +403,19 @@ func handleUpdateInstallation(c *Context, w http.ResponseWriter, r *http.Request
 	}
 
 	if patchInstallationRequest.Apply(installationDTO.Installation) {
+		err = installationDTO.Installation.ValidateCustomSize()
+		if err != nil {
+			c.Logger.WithError(err).Error("updated installation size is invalid")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		err = installationDTO.Installation.ValidateResourceQuota()
+		if err != nil {
+			c.Logger.WithError(err).Error("updated installation size exceeds its resource quota")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
 		installationDTO.State = newState
 
 		err = c.Store.UpdateInstallation(installationDTO.Installation)
@@ -581,6 +689,251 @@ func handleWakeupInstallation(c *Context, w http.ResponseWriter, r *http.Request
 	outputJSON(c, w, installationDTO)
 }
 
+// handleMigrateInstallationFilestore responds to POST /api/installation/{installation}/filestore/migrate,
+// moving the installation files to the filestore embedded in the request.
+func handleMigrateInstallationFilestore(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	migrateRequest, err := model.NewMigrateInstallationFilestoreRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installationDTO, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if installationDTO.APISecurityLock {
+		logSecurityLockConflict("installation", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	oldState := installationDTO.State
+	newState := model.InstallationStateFilestoreMigrationRequested
+
+	if !installationDTO.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to migrate installation filestore while in state %s", installationDTO.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = model.ValidateFilestoreMigration(installationDTO.Filestore, migrateRequest.Filestore)
+	if err != nil {
+		c.Logger.WithError(err).Error("invalid filestore migration")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	err = installationDTO.NetworkIsolation.ValidateFilestore(migrateRequest.Filestore)
+	if err != nil {
+		c.Logger.WithError(err).Error("invalid filestore migration")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installationDTO.State = newState
+	installationDTO.FilestoreMigration = &model.FilestoreMigration{
+		SourceFilestore: installationDTO.Filestore,
+		TargetFilestore: migrateRequest.Filestore,
+		StartAt:         store.GetMillis(),
+	}
+
+	err = c.Store.UpdateInstallation(installationDTO.Installation)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installationDTO.ID,
+		NewState:  newState,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"DNS": installationDTO.DNS},
+	}
+	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installationDTO)
+}
+
+// handleRotateInstallationCredentials responds to POST /api/installation/{installation}/rotate-credentials,
+// replacing the database and filestore credentials of the installation.
+func handleRotateInstallationCredentials(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	installationDTO, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if installationDTO.APISecurityLock {
+		logSecurityLockConflict("installation", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	oldState := installationDTO.State
+	newState := model.InstallationStateCredentialRotationRequested
+
+	if !installationDTO.ValidTransitionState(newState) {
+		c.Logger.Warnf("unable to rotate installation credentials while in state %s", installationDTO.State)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installationDTO.State = newState
+
+	err := c.Store.UpdateInstallation(installationDTO.Installation)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installationDTO.ID,
+		NewState:  newState,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"DNS": installationDTO.DNS},
+	}
+	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installationDTO)
+}
+
+// handleGetInstallationUsage responds to GET /api/installation/{installation}/usage,
+// returning the specified page of storage usage records of the installation.
+func handleGetInstallationUsage(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installation, err := c.Store.GetInstallation(installationID, false, false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	usages, err := c.Store.GetInstallationUsages(&model.InstallationUsageFilter{
+		InstallationID: installationID,
+		Page:           page,
+		PerPage:        perPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation usages")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if usages == nil {
+		usages = []*model.InstallationUsage{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, usages)
+}
+
+// handleGetInstallationCertificate responds to GET /api/installation/{installation}/certificate,
+// returning the status of the TLS certificate of the installation ingress.
+func handleGetInstallationCertificate(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	installation, err := c.Store.GetInstallation(installationID, false, false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	clusterInstallations, err := c.Store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		InstallationID: installationID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster installations")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if len(clusterInstallations) == 0 {
+		c.Logger.Warn("installation has no cluster installation")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	clusterInstallation := clusterInstallations[0]
+
+	cluster, err := c.Store.GetCluster(clusterInstallation.ClusterID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query cluster")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if cluster == nil {
+		c.Logger.Errorf("cluster %s of the installation not found", clusterInstallation.ClusterID)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	certificate, err := c.Provisioner.GetClusterInstallationCertificate(cluster, installation, clusterInstallation)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get installation certificate")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, certificate)
+}
+
 // handleDeleteInstallation responds to DELETE /api/installation/{installation}, beginning the process of
 // deleting the installation.
 func handleDeleteInstallation(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -718,3 +1071,208 @@ func handleDeleteInstallationAnnotation(c *Context, w http.ResponseWriter, r *ht
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// handleGetInstallationDomains responds to GET /api/installation/{installation}/domains,
+// returning the custom domains of the installation along with their DNS records.
+func handleGetInstallationDomains(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID)
+
+	installation, err := c.Store.GetInstallation(installationID, false, false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	domains, err := c.Store.GetInstallationDomains(&model.InstallationDomainFilter{
+		InstallationID: installationID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation domains")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	domainDTOs := []*model.InstallationDomainDTO{}
+	for _, domain := range domains {
+		domainDTOs = append(domainDTOs, model.NewInstallationDomainDTO(domain, installation.DNS))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, domainDTOs)
+}
+
+// handleCreateInstallationDomain responds to POST /api/installation/{installation}/domains,
+// attaching a custom domain to the installation. The domain is served once the
+// customer publishes the returned DNS records.
+func handleCreateInstallationDomain(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	c.Logger = c.Logger.WithField("installation", installationID).WithField("action", "create-installation-domain")
+
+	createDomainRequest, err := model.NewCreateInstallationDomainRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !c.CertManagerTLS {
+		c.Logger.Error("custom domains require cert-manager TLS to be enabled on the server")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installationDTO, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if installationDTO.APISecurityLock {
+		logSecurityLockConflict("installation", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if installationDTO.DeleteAt != 0 {
+		c.Logger.Error("cannot add a domain to a deleted installation")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	installations, err := c.Store.GetInstallations(&model.InstallationFilter{
+		DNS:     createDomainRequest.Domain,
+		PerPage: model.AllPerPage,
+	}, false, false)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installations")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if len(installations) != 0 {
+		c.Logger.Errorf("domain %s is already the DNS name of an installation", createDomainRequest.Domain)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	domains, err := c.Store.GetInstallationDomains(&model.InstallationDomainFilter{
+		Domain:  createDomainRequest.Domain,
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation domains")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if len(domains) != 0 {
+		c.Logger.Errorf("domain %s is already attached to installation %s", createDomainRequest.Domain, domains[0].InstallationID)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	domain := &model.InstallationDomain{
+		InstallationID:    installationID,
+		Domain:            createDomainRequest.Domain,
+		VerificationToken: model.NewID(),
+		State:             model.InstallationDomainStatePendingVerification,
+	}
+	err = c.Store.CreateInstallationDomain(domain)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to create installation domain")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallationDomain,
+		ID:        domain.ID,
+		NewState:  domain.State,
+		OldState:  "n/a",
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Domain": domain.Domain, "InstallationID": domain.InstallationID},
+	}
+	err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, model.NewInstallationDomainDTO(domain, installationDTO.DNS))
+}
+
+// handleDeleteInstallationDomain responds to DELETE /api/installation/{installation}/domain/{domain},
+// requesting the removal of the custom domain from the installation.
+func handleDeleteInstallationDomain(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationID := vars["installation"]
+	domainID := vars["domain"]
+	c.Logger = c.Logger.
+		WithField("installation", installationID).
+		WithField("action", "delete-installation-domain").
+		WithField("domain", domainID)
+
+	installationDTO, status, unlockOnce := lockInstallation(c, installationID)
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	defer unlockOnce()
+
+	if installationDTO.APISecurityLock {
+		logSecurityLockConflict("installation", c.Logger)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	domain, err := c.Store.GetInstallationDomain(domainID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation domain")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if domain == nil || domain.InstallationID != installationID || domain.IsDeleted() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if domain.State != model.InstallationDomainStateDeletionRequested {
+		oldState := domain.State
+		domain.State = model.InstallationDomainStateDeletionRequested
+		err = c.Store.UpdateInstallationDomain(domain)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to mark installation domain for deletion")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		webhookPayload := &model.WebhookPayload{
+			Type:      model.TypeInstallationDomain,
+			ID:        domain.ID,
+			NewState:  domain.State,
+			OldState:  oldState,
+			Timestamp: time.Now().UnixNano(),
+			ExtraData: map[string]string{"Domain": domain.Domain, "InstallationID": domain.InstallationID},
+		}
+		err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState))
+		if err != nil {
+			c.Logger.WithError(err).Error("Unable to process and send webhooks")
+		}
+	}
+
+	unlockOnce()
+	c.Supervisor.Do()
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -24,7 +24,8 @@ func TestNewCreateClusterRequestFromReader(t *testing.T) {
 			NodeMinCount:           2,
 			NodeMaxCount:           2,
 			Zones:                  []string{"us-east-1a"},
-			DesiredUtilityVersions: map[string]string{"fluentbit": "2.8.7", "nginx": "2.15.0", "prometheus-operator": "9.4.4", "thanos": "2.4.3", "teleport": "0.3.0"},
+			DesiredUtilityVersions: map[string]string{"fluentbit": "2.8.7", "nginx": "2.15.0", "prometheus-operator": "9.4.4", "thanos": "2.4.3", "teleport": "0.3.0", "velero": "2.13.6", "cert-manager": "v1.0.4", "cluster-autoscaler": "9.1.0", "aws-node-termination-handler": "0.13.2"},
+			Provisioner:            model.ProvisionerKops,
 		}
 	}
 
@@ -78,11 +79,16 @@ func TestNewCreateClusterRequestFromReader(t *testing.T) {
 			NodeMaxCount:       2,
 			Zones:              []string{"zone1", "zone2"},
 			DesiredUtilityVersions: map[string]string{
-				"fluentbit":           "2.8.7",
-				"nginx":               "2.15.0",
-				"prometheus-operator": "9.4.4",
-				"thanos":              "2.4.3",
-				"teleport":            "0.3.0"},
+				"fluentbit":                    "2.8.7",
+				"nginx":                        "2.15.0",
+				"prometheus-operator":          "9.4.4",
+				"thanos":                       "2.4.3",
+				"teleport":                     "0.3.0",
+				"velero":                       "2.13.6",
+				"cert-manager":                 "v1.0.4",
+				"cluster-autoscaler":           "9.1.0",
+				"aws-node-termination-handler": "0.13.2"},
+			Provisioner: model.ProvisionerKops,
 		}, clusterRequest)
 	})
 }
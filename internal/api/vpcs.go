@@ -0,0 +1,75 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/model"
+)
+
+// initVPCs registers VPC endpoints on the given router.
+func initVPCs(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	vpcRouter := apiRouter.PathPrefix("/vpcs").Subrouter()
+	vpcRouter.Handle("", addContext(handleGetVPCs)).Methods("GET")
+}
+
+// vpcPlacement is an AWS region and account holding a VPC pool.
+type vpcPlacement struct {
+	region        string
+	assumeRoleARN string
+}
+
+// handleGetVPCs responds to GET /api/vpcs, returning the pool of VPCs tagged
+// for use by the provisioner along with their claim status and health. The
+// pools of the region of the server and of every region and account that
+// clusters are placed in are listed.
+func handleGetVPCs(c *Context, w http.ResponseWriter, r *http.Request) {
+	clusters, err := c.Store.GetClusters(&model.ClusterFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query clusters")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	placements := []vpcPlacement{{}}
+	seenPlacements := map[vpcPlacement]bool{{}: true}
+	for _, cluster := range clusters {
+		placement := vpcPlacement{region: cluster.AWSRegion(), assumeRoleARN: cluster.AWSAssumeRoleARN()}
+		if !seenPlacements[placement] {
+			seenPlacements[placement] = true
+			placements = append(placements, placement)
+		}
+	}
+
+	vpcs := []*model.VPC{}
+	seenVPCs := make(map[model.VPC]bool)
+	for _, placement := range placements {
+		pool, err := c.AwsClient.GetRegionalVpcPool(placement.region, placement.assumeRoleARN, c.Logger)
+		if err != nil {
+			c.Logger.WithError(err).Errorf("failed to query VPC pool of region %s", placement.region)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for _, vpc := range pool {
+			// The region of the server may also be set explicitly on clusters.
+			key := model.VPC{ID: vpc.ID, Region: vpc.Region, AssumeRoleARN: vpc.AssumeRoleARN}
+			if seenVPCs[key] {
+				continue
+			}
+			seenVPCs[key] = true
+			vpcs = append(vpcs, vpc)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, vpcs)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-cloud/internal/api"
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVPCs(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	awsClient := &mockAwsClient{}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		AwsClient:  awsClient,
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("empty pool", func(t *testing.T) {
+		vpcs, err := client.GetVPCs()
+		require.NoError(t, err)
+		require.Empty(t, vpcs)
+	})
+
+	t.Run("pool", func(t *testing.T) {
+		awsClient.VpcPools = map[string][]*model.VPC{
+			"": {
+				{ID: "vpc-1", Region: "us-east-1", Available: true, Valid: true},
+				{ID: "vpc-2", Region: "us-east-1", ClusterID: "cluster1", Owner: "owner", Valid: true},
+				{ID: "vpc-3", Region: "us-east-1", Available: true, Error: "missing subnets"},
+			},
+		}
+
+		vpcs, err := client.GetVPCs()
+		require.NoError(t, err)
+		require.Equal(t, awsClient.VpcPools[""], vpcs)
+		require.Equal(t, 1, model.CountClaimableVPCs(vpcs))
+	})
+
+	t.Run("pools of cluster regions", func(t *testing.T) {
+		awsClient.VpcPools = map[string][]*model.VPC{
+			"": {
+				{ID: "vpc-1", Region: "us-east-1", Available: true, Valid: true},
+			},
+			"us-east-1": {
+				{ID: "vpc-1", Region: "us-east-1", Available: true, Valid: true},
+			},
+			"eu-central-1": {
+				{ID: "vpc-4", Region: "eu-central-1", Available: true, Valid: true},
+			},
+		}
+		for _, region := range []string{"us-east-1", "eu-central-1", "eu-central-1"} {
+			err := sqlStore.CreateCluster(&model.Cluster{
+				Provider:            model.ProviderAWS,
+				ProviderMetadataAWS: &model.AWSMetadata{Region: region},
+			}, nil)
+			require.NoError(t, err)
+		}
+
+		vpcs, err := client.GetVPCs()
+		require.NoError(t, err)
+		require.Equal(t, []*model.VPC{awsClient.VpcPools[""][0], awsClient.VpcPools["eu-central-1"][0]}, vpcs)
+	})
+
+	t.Run("aws failure", func(t *testing.T) {
+		awsClient.Err = errors.New("failure")
+		defer func() { awsClient.Err = nil }()
+
+		_, err := client.GetVPCs()
+		require.EqualError(t, err, "failed with status code 500")
+	})
+}
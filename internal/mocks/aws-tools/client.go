@@ -12,6 +12,7 @@ import (
 	iam "github.com/aws/aws-sdk-go/service/iam"
 	gomock "github.com/golang/mock/gomock"
 	aws "github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	model "github.com/mattermost/mattermost-cloud/model"
 	logrus "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	reflect "reflect"
@@ -142,6 +143,34 @@ func (mr *MockAWSMockRecorder) DetachPolicyFromRole(roleName, policyName, logger
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachPolicyFromRole", reflect.TypeOf((*MockAWS)(nil).DetachPolicyFromRole), roleName, policyName, logger)
 }
 
+// EnsureS3BucketPolicyCreated mocks base method
+func (m *MockAWS) EnsureS3BucketPolicyCreated(policyName, bucketName string, logger logrus.FieldLogger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureS3BucketPolicyCreated", policyName, bucketName, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureS3BucketPolicyCreated indicates an expected call of EnsureS3BucketPolicyCreated
+func (mr *MockAWSMockRecorder) EnsureS3BucketPolicyCreated(policyName, bucketName, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureS3BucketPolicyCreated", reflect.TypeOf((*MockAWS)(nil).EnsureS3BucketPolicyCreated), policyName, bucketName, logger)
+}
+
+// EnsureS3BucketPolicyDeleted mocks base method
+func (m *MockAWS) EnsureS3BucketPolicyDeleted(policyName string, logger logrus.FieldLogger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureS3BucketPolicyDeleted", policyName, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureS3BucketPolicyDeleted indicates an expected call of EnsureS3BucketPolicyDeleted
+func (mr *MockAWSMockRecorder) EnsureS3BucketPolicyDeleted(policyName, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureS3BucketPolicyDeleted", reflect.TypeOf((*MockAWS)(nil).EnsureS3BucketPolicyDeleted), policyName, logger)
+}
+
 // GetPrivateZoneDomainName mocks base method
 func (m *MockAWS) GetPrivateZoneDomainName(logger logrus.FieldLogger) (string, error) {
 	m.ctrl.T.Helper()
@@ -314,6 +343,20 @@ func (mr *MockAWSMockRecorder) DynamoDBEnsureTableDeleted(tableName, logger inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DynamoDBEnsureTableDeleted", reflect.TypeOf((*MockAWS)(nil).DynamoDBEnsureTableDeleted), tableName, logger)
 }
 
+// S3EnsureBucketCreated mocks base method
+func (m *MockAWS) S3EnsureBucketCreated(bucketName string, logger logrus.FieldLogger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "S3EnsureBucketCreated", bucketName, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// S3EnsureBucketCreated indicates an expected call of S3EnsureBucketCreated
+func (mr *MockAWSMockRecorder) S3EnsureBucketCreated(bucketName, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "S3EnsureBucketCreated", reflect.TypeOf((*MockAWS)(nil).S3EnsureBucketCreated), bucketName, logger)
+}
+
 // S3EnsureBucketDeleted mocks base method
 func (m *MockAWS) S3EnsureBucketDeleted(bucketName string, logger logrus.FieldLogger) error {
 	m.ctrl.T.Helper()
@@ -342,3 +385,77 @@ func (mr *MockAWSMockRecorder) GenerateBifrostUtilitySecret(clusterID, logger in
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateBifrostUtilitySecret", reflect.TypeOf((*MockAWS)(nil).GenerateBifrostUtilitySecret), clusterID, logger)
 }
+
+// GetKubeconfigSecret mocks base method
+func (m *MockAWS) GetKubeconfigSecret(secretName string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKubeconfigSecret", secretName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKubeconfigSecret indicates an expected call of GetKubeconfigSecret
+func (mr *MockAWSMockRecorder) GetKubeconfigSecret(secretName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKubeconfigSecret", reflect.TypeOf((*MockAWS)(nil).GetKubeconfigSecret), secretName)
+}
+
+// EnsureKubeconfigSecretDeleted mocks base method
+func (m *MockAWS) EnsureKubeconfigSecretDeleted(secretName string, logger logrus.FieldLogger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureKubeconfigSecretDeleted", secretName, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureKubeconfigSecretDeleted indicates an expected call of EnsureKubeconfigSecretDeleted
+func (mr *MockAWSMockRecorder) EnsureKubeconfigSecretDeleted(secretName, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureKubeconfigSecretDeleted", reflect.TypeOf((*MockAWS)(nil).EnsureKubeconfigSecretDeleted), secretName, logger)
+}
+
+// ClientForCluster mocks base method
+func (m *MockAWS) ClientForCluster(cluster *model.Cluster) (aws.AWS, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientForCluster", cluster)
+	ret0, _ := ret[0].(aws.AWS)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClientForCluster indicates an expected call of ClientForCluster
+func (mr *MockAWSMockRecorder) ClientForCluster(cluster interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientForCluster", reflect.TypeOf((*MockAWS)(nil).ClientForCluster), cluster)
+}
+
+// GetVpcPool mocks base method
+func (m *MockAWS) GetVpcPool(logger logrus.FieldLogger) ([]*model.VPC, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVpcPool", logger)
+	ret0, _ := ret[0].([]*model.VPC)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVpcPool indicates an expected call of GetVpcPool
+func (mr *MockAWSMockRecorder) GetVpcPool(logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVpcPool", reflect.TypeOf((*MockAWS)(nil).GetVpcPool), logger)
+}
+
+// GetVpcDBClusterIDs mocks base method
+func (m *MockAWS) GetVpcDBClusterIDs(clusterID string, logger logrus.FieldLogger) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVpcDBClusterIDs", clusterID, logger)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVpcDBClusterIDs indicates an expected call of GetVpcDBClusterIDs
+func (mr *MockAWSMockRecorder) GetVpcDBClusterIDs(clusterID, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVpcDBClusterIDs", reflect.TypeOf((*MockAWS)(nil).GetVpcDBClusterIDs), clusterID, logger)
+}
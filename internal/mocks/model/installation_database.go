@@ -97,6 +97,49 @@ func (mr *MockDatabaseMockRecorder) GenerateDatabaseSpecAndSecret(store, logger
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateDatabaseSpecAndSecret", reflect.TypeOf((*MockDatabase)(nil).GenerateDatabaseSpecAndSecret), store, logger)
 }
 
+// Usage mocks base method
+func (m *MockDatabase) Usage(store model.InstallationDatabaseStoreInterface, logger logrus.FieldLogger) (*model.StorageUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", store, logger)
+	ret0, _ := ret[0].(*model.StorageUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage
+func (mr *MockDatabaseMockRecorder) Usage(store, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockDatabase)(nil).Usage), store, logger)
+}
+
+// RotateCredentials mocks base method
+func (m *MockDatabase) RotateCredentials(store model.InstallationDatabaseStoreInterface, logger logrus.FieldLogger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateCredentials", store, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateCredentials indicates an expected call of RotateCredentials
+func (mr *MockDatabaseMockRecorder) RotateCredentials(store, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateCredentials", reflect.TypeOf((*MockDatabase)(nil).RotateCredentials), store, logger)
+}
+
+// RevokeStaleCredentials mocks base method
+func (m *MockDatabase) RevokeStaleCredentials(store model.InstallationDatabaseStoreInterface, logger logrus.FieldLogger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeStaleCredentials", store, logger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeStaleCredentials indicates an expected call of RevokeStaleCredentials
+func (mr *MockDatabaseMockRecorder) RevokeStaleCredentials(store, logger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeStaleCredentials", reflect.TypeOf((*MockDatabase)(nil).RevokeStaleCredentials), store, logger)
+}
+
 // MockInstallationDatabaseStoreInterface is a mock of InstallationDatabaseStoreInterface interface
 type MockInstallationDatabaseStoreInterface struct {
 	ctrl     *gomock.Controller
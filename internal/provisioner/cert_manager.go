@@ -0,0 +1,155 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// certManagerNamespace is the namespace cert-manager is deployed to.
+	certManagerNamespace = "cert-manager"
+	// letsEncryptClusterIssuer is the name of the ClusterIssuer that issues
+	// the installation certificates with Let's Encrypt.
+	letsEncryptClusterIssuer = "letsencrypt"
+	// letsEncryptServer is the ACME directory of Let's Encrypt.
+	letsEncryptServer = "https://acme-v02.api.letsencrypt.org/directory"
+)
+
+type certManager struct {
+	provisioner    *KopsProvisioner
+	kubeconfig     kubeconfigProvider
+	cluster        *model.Cluster
+	logger         log.FieldLogger
+	desiredVersion string
+	actualVersion  string
+}
+
+func newCertManagerHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*certManager, error) {
+	if logger == nil {
+		return nil, errors.New("cannot instantiate cert-manager handle with nil logger")
+	}
+
+	if provisioner == nil {
+		return nil, errors.New("cannot create a connection to cert-manager if the provisioner provided is nil")
+	}
+
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to cert-manager if the kubeconfig provider is nil")
+	}
+
+	return &certManager{
+		provisioner:    provisioner,
+		kubeconfig:     kubeconfig,
+		cluster:        cluster,
+		logger:         logger.WithField("cluster-utility", model.CertManagerCanonicalName),
+		desiredVersion: desiredVersion,
+	}, nil
+}
+
+func (c *certManager) updateVersion(h *helmDeployment) error {
+	actualVersion, err := h.Version()
+	if err != nil {
+		return err
+	}
+
+	c.actualVersion = actualVersion
+	return nil
+}
+
+func (c *certManager) CreateOrUpgrade() error {
+	h := c.NewHelmDeployment()
+
+	err := h.TryMigrate()
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate cert-manager release")
+	}
+
+	err = h.Update()
+	if err != nil {
+		return err
+	}
+
+	err = c.ensureClusterIssuer()
+	if err != nil {
+		return errors.Wrap(err, "failed to create Let's Encrypt cluster issuer")
+	}
+
+	err = c.updateVersion(h)
+	return err
+}
+
+// ensureClusterIssuer creates the ClusterIssuer referenced by the ingresses
+// of the installations. The HTTP-01 challenges are solved through the NGINX
+// ingress controller.
+func (c *certManager) ensureClusterIssuer() error {
+	k8sClient, err := k8s.NewFromFile(c.kubeconfig.GetKubeConfigPath(), c.logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to construct k8s client")
+	}
+
+	_, err = k8sClient.CreateOrUpdateClusterIssuer(letsEncryptClusterIssuer, newLetsEncryptIssuerSpec())
+
+	return err
+}
+
+func newLetsEncryptIssuerSpec() map[string]interface{} {
+	return map[string]interface{}{
+		"acme": map[string]interface{}{
+			"server": letsEncryptServer,
+			"privateKeySecretRef": map[string]interface{}{
+				"name": letsEncryptClusterIssuer + "-account-key",
+			},
+			"solvers": []interface{}{
+				map[string]interface{}{
+					"http01": map[string]interface{}{
+						"ingress": map[string]interface{}{
+							"class": "nginx-controller",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *certManager) DesiredVersion() string {
+	return c.desiredVersion
+}
+
+func (c *certManager) ActualVersion() string {
+	return strings.TrimPrefix(c.actualVersion, "cert-manager-")
+}
+
+func (c *certManager) Destroy() error {
+	return nil
+}
+
+func (c *certManager) Migrate() error {
+	return nil
+}
+
+func (c *certManager) NewHelmDeployment() *helmDeployment {
+	return &helmDeployment{
+		chartDeploymentName: "cert-manager",
+		chartName:           "jetstack/cert-manager",
+		namespace:           certManagerNamespace,
+		valuesPath:          "helm-charts/cert-manager_values.yaml",
+		valuesOverride:      c.cluster.UtilityValuesOverride(model.CertManagerCanonicalName),
+		kopsProvisioner:     c.provisioner,
+		kubeconfig:          c.kubeconfig,
+		logger:              c.logger,
+		desiredVersion:      c.desiredVersion,
+	}
+}
+
+func (c *certManager) Name() string {
+	return model.CertManagerCanonicalName
+}
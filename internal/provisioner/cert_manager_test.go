@@ -0,0 +1,43 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCertManagerHelmDeployment(t *testing.T) {
+	logger := log.New()
+
+	cluster := &model.Cluster{ID: "cluster1"}
+	cluster.SetUtilityValuesOverrides(map[string]string{model.CertManagerCanonicalName: "replicaCount: 2\n"})
+	certManager, err := newCertManagerHandle(cluster, "v1.0.4", &KopsProvisioner{}, &kops.Cmd{}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, model.CertManagerCanonicalName, certManager.Name())
+	assert.Equal(t, "v1.0.4", certManager.DesiredVersion())
+
+	helmDeployment := certManager.NewHelmDeployment()
+	require.NotNil(t, helmDeployment)
+	assert.Equal(t, "jetstack/cert-manager", helmDeployment.chartName)
+	assert.Equal(t, "cert-manager", helmDeployment.namespace)
+	assert.Equal(t, "replicaCount: 2\n", helmDeployment.valuesOverride)
+
+	certManager.actualVersion = "cert-manager-v1.0.4"
+	assert.Equal(t, "v1.0.4", certManager.ActualVersion())
+}
+
+func TestNewCertManagerHandleErrors(t *testing.T) {
+	_, err := newCertManagerHandle(&model.Cluster{}, "", nil, &kops.Cmd{}, log.New())
+	require.Error(t, err)
+
+	_, err = newCertManagerHandle(&model.Cluster{}, "", &KopsProvisioner{}, nil, log.New())
+	require.Error(t, err)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type clusterAutoscaler struct {
+	provisioner    *KopsProvisioner
+	kubeconfig     kubeconfigProvider
+	cluster        *model.Cluster
+	logger         log.FieldLogger
+	desiredVersion string
+	actualVersion  string
+}
+
+func newClusterAutoscalerHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*clusterAutoscaler, error) {
+	if logger == nil {
+		return nil, errors.New("cannot instantiate cluster-autoscaler handle with nil logger")
+	}
+
+	if provisioner == nil {
+		return nil, errors.New("cannot create a connection to cluster-autoscaler if the provisioner provided is nil")
+	}
+
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to cluster-autoscaler if the kubeconfig provider is nil")
+	}
+
+	return &clusterAutoscaler{
+		provisioner:    provisioner,
+		kubeconfig:     kubeconfig,
+		cluster:        cluster,
+		logger:         logger.WithField("cluster-utility", model.ClusterAutoscalerCanonicalName),
+		desiredVersion: desiredVersion,
+	}, nil
+}
+
+// clusterAutoscalingEnabled returns true if the default node instance group
+// of a kops cluster is scaled by the cluster-autoscaler or is requested to be
+// when the cluster is created.
+func clusterAutoscalingEnabled(cluster *model.Cluster) bool {
+	kopsMetadata := cluster.ProvisionerMetadataKops
+	if kopsMetadata == nil {
+		return false
+	}
+	if kopsMetadata.ChangeRequest != nil && kopsMetadata.ChangeRequest.Autoscaling {
+		return true
+	}
+
+	return kopsMetadata.IsAutoscaling()
+}
+
+func (c *clusterAutoscaler) updateVersion(h *helmDeployment) error {
+	actualVersion, err := h.Version()
+	if err != nil {
+		return err
+	}
+
+	c.actualVersion = actualVersion
+	return nil
+}
+
+func (c *clusterAutoscaler) CreateOrUpgrade() error {
+	h := c.NewHelmDeployment()
+
+	err := h.TryMigrate()
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate cluster-autoscaler release")
+	}
+
+	err = h.Update()
+	if err != nil {
+		return err
+	}
+
+	err = c.updateVersion(h)
+	return err
+}
+
+func (c *clusterAutoscaler) DesiredVersion() string {
+	return c.desiredVersion
+}
+
+func (c *clusterAutoscaler) ActualVersion() string {
+	return strings.TrimPrefix(c.actualVersion, "cluster-autoscaler-")
+}
+
+func (c *clusterAutoscaler) Destroy() error {
+	return nil
+}
+
+func (c *clusterAutoscaler) Migrate() error {
+	return nil
+}
+
+// NewHelmDeployment returns the cluster-autoscaler deployment. The
+// autoscaler is deployed to every cluster so that its version is tracked
+// like the other utilities, but it only runs on clusters with autoscaling
+// enabled.
+func (c *clusterAutoscaler) NewHelmDeployment() *helmDeployment {
+	replicas := 0
+	if clusterAutoscalingEnabled(c.cluster) {
+		replicas = 1
+	}
+
+	return &helmDeployment{
+		chartDeploymentName: "cluster-autoscaler",
+		chartName:           "autoscaler/cluster-autoscaler",
+		namespace:           "kube-system",
+		setArgument:         fmt.Sprintf("autoDiscovery.clusterName=%s,awsRegion=%s,replicaCount=%d", getClusterName(c.cluster), clusterAWSRegion(c.cluster), replicas),
+		valuesPath:          "helm-charts/cluster-autoscaler_values.yaml",
+		valuesOverride:      c.cluster.UtilityValuesOverride(model.ClusterAutoscalerCanonicalName),
+		kopsProvisioner:     c.provisioner,
+		kubeconfig:          c.kubeconfig,
+		logger:              c.logger,
+		desiredVersion:      c.desiredVersion,
+	}
+}
+
+func (c *clusterAutoscaler) Name() string {
+	return model.ClusterAutoscalerCanonicalName
+}
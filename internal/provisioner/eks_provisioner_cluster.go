@@ -0,0 +1,495 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/internal/tools/eksctl"
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// eksDefaultVersion is the kubernetes version of EKS clusters created or
+// upgraded to the latest version.
+const eksDefaultVersion = "1.17"
+
+// EKSProvisioner provisions clusters as managed EKS clusters using eksctl.
+type EKSProvisioner struct {
+	// kopsProvisioner holds the utility settings shared with kops clusters.
+	kopsProvisioner *KopsProvisioner
+	logger          log.FieldLogger
+}
+
+// NewEKSProvisioner creates a new EKSProvisioner.
+func NewEKSProvisioner(kopsProvisioner *KopsProvisioner, logger log.FieldLogger) *EKSProvisioner {
+	logger = logger.WithField("provisioner", "eks")
+
+	return &EKSProvisioner{
+		kopsProvisioner: kopsProvisioner,
+		logger:          logger,
+	}
+}
+
+// ImportCluster is not supported by the EKS provisioner.
+func (provisioner *EKSProvisioner) ImportCluster(cluster *model.Cluster) error {
+	return errors.New("importing clusters is not supported by the eks provisioner")
+}
+
+// PrepareCluster ensures a cluster object is ready for provisioning.
+func (provisioner *EKSProvisioner) PrepareCluster(cluster *model.Cluster) bool {
+	// Don't regenerate the name if already set.
+	if cluster.ProvisionerMetadataEKS.Name != "" {
+		return false
+	}
+
+	// Generate the EKS name using the cluster id.
+	cluster.ProvisionerMetadataEKS.Name = fmt.Sprintf("%s-eks", cluster.ID)
+	cluster.ProvisionerMetadataEKS.Region = cluster.AWSRegion()
+	if len(cluster.ProvisionerMetadataEKS.Region) == 0 {
+		cluster.ProvisionerMetadataEKS.Region = regionFromZones(cluster.ProviderMetadataAWS.Zones)
+	}
+
+	return true
+}
+
+// CreateCluster creates an EKS cluster with a managed node group.
+func (provisioner *EKSProvisioner) CreateCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	if eksMetadata.ChangeRequest == nil {
+		return errors.New("the EKS metadata change request is nil")
+	}
+
+	logger.WithField("name", eksMetadata.Name).Info("Creating cluster")
+
+	nodeGroup := newEKSNodeGroup(
+		eksMetadata.ChangeRequest.NodeInstanceType,
+		eksMetadata.ChangeRequest.NodeMinCount,
+		eksMetadata.ChangeRequest.NodeMaxCount,
+	)
+	config := eksctl.NewClusterConfig(
+		eksMetadata.Name,
+		eksMetadata.Region,
+		eksVersion(eksMetadata.ChangeRequest.Version),
+		cluster.ProviderMetadataAWS.Zones,
+		nodeGroup,
+	)
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	err = eksctl.CreateCluster(config)
+	if err != nil {
+		return err
+	}
+	eksMetadata.NodeGroupName = nodeGroup.Name
+
+	return nil
+}
+
+// ProvisionCluster installs the operators, the bifrost service and the
+// cluster utilities on an EKS cluster.
+func (provisioner *EKSProvisioner) ProvisionCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Provisioning cluster")
+
+	bifrostSecret, err := awsClient.GenerateBifrostUtilitySecret(cluster.ID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate bifrost secret")
+	}
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	nodeGroups, err := eksctl.GetNodeGroups(eksMetadata.Name, eksMetadata.Region)
+	if err != nil {
+		return errors.Wrap(err, "failed to get node groups")
+	}
+	for _, nodeGroup := range nodeGroups {
+		roleName := roleNameFromARN(nodeGroup.NodeInstanceRoleARN)
+		if len(roleName) == 0 {
+			continue
+		}
+		err = awsClient.AttachPolicyToRole(roleName, aws.CustomNodePolicyName, logger)
+		if err != nil {
+			return errors.Wrapf(err, "unable to attach custom node policy to node group %s", nodeGroup.Name)
+		}
+	}
+
+	err = eksctl.WriteKubeconfig(eksMetadata.Name, eksMetadata.Region)
+	if err != nil {
+		return errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	k8sClient, err := k8s.NewFromFile(eksctl.GetKubeConfigPath(), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	// EKS clusters use the AWS VPC CNI, so the calico manifests applied to
+	// kops clusters are not needed.
+	err = provisionClusterOperators(k8sClient, bifrostSecret, logger, k8s.ManifestFile{
+		Path:            "manifests/metric-server/metric-server.yaml",
+		DeployNamespace: "kube-system",
+	})
+	if err != nil {
+		return err
+	}
+
+	ugh, err := newUtilityGroupHandle(eksctl, provisioner.kopsProvisioner, cluster, awsClient, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new cluster utility group handle")
+	}
+
+	err = ugh.ProvisionUtilityGroup()
+	if err != nil {
+		return errors.Wrap(err, "failed to upgrade all services in utility group")
+	}
+
+	logger.WithField("name", eksMetadata.Name).Info("Successfully provisioned cluster")
+
+	return nil
+}
+
+// UpgradeCluster upgrades the control plane and the managed node group of an
+// EKS cluster to the requested kubernetes version.
+func (provisioner *EKSProvisioner) UpgradeCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	if eksMetadata.ChangeRequest == nil || len(eksMetadata.ChangeRequest.Version) == 0 {
+		logger.Info("Skipping cluster upgrade without a requested version")
+		return nil
+	}
+	version := eksVersion(eksMetadata.ChangeRequest.Version)
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	logger.Infof("Upgrading cluster control plane to %s", version)
+	err = eksctl.UpgradeCluster(eksMetadata.Name, eksMetadata.Region, version)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Upgrading node group %s to %s", eksMetadata.NodeGroupName, version)
+	err = eksctl.UpgradeNodeGroup(eksMetadata.Name, eksMetadata.Region, eksMetadata.NodeGroupName, version)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Successfully upgraded cluster")
+
+	return nil
+}
+
+// ResizeCluster resizes the managed node group of an EKS cluster. The
+// instance type of a managed node group can't be changed, so a new node group
+// replaces the existing one when the instance type changes.
+func (provisioner *EKSProvisioner) ResizeCluster(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	if eksMetadata.ChangeRequest == nil {
+		return errors.New("the EKS metadata change request is nil")
+	}
+
+	instanceType := eksMetadata.NodeInstanceType
+	if len(eksMetadata.ChangeRequest.NodeInstanceType) != 0 {
+		instanceType = eksMetadata.ChangeRequest.NodeInstanceType
+	}
+	minCount := eksMetadata.NodeMinCount
+	if eksMetadata.ChangeRequest.NodeMinCount != 0 {
+		minCount = eksMetadata.ChangeRequest.NodeMinCount
+	}
+	maxCount := eksMetadata.NodeMaxCount
+	if eksMetadata.ChangeRequest.NodeMaxCount != 0 {
+		maxCount = eksMetadata.ChangeRequest.NodeMaxCount
+	}
+	if maxCount < minCount {
+		maxCount = minCount
+	}
+
+	nodeGroup := newEKSNodeGroup(instanceType, minCount, maxCount)
+	config := eksctl.NewClusterConfig(eksMetadata.Name, eksMetadata.Region, "", nil, nodeGroup)
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	if instanceType == eksMetadata.NodeInstanceType {
+		logger.Infof("Scaling node group %s to %d nodes (max=%d)", eksMetadata.NodeGroupName, minCount, maxCount)
+		return eksctl.ScaleNodeGroup(eksMetadata.Name, eksMetadata.Region, eksMetadata.NodeGroupName, minCount, maxCount)
+	}
+
+	logger.Infof("Replacing node group %s with %s", eksMetadata.NodeGroupName, nodeGroup.Name)
+	err = eksctl.CreateNodeGroups(config)
+	if err != nil {
+		return err
+	}
+
+	err = eksctl.DeleteNodeGroup(eksMetadata.Name, eksMetadata.Region, eksMetadata.NodeGroupName)
+	if err != nil {
+		return err
+	}
+	eksMetadata.NodeGroupName = nodeGroup.Name
+
+	logger.Info("Successfully resized cluster")
+
+	return nil
+}
+
+// DeleteCluster deletes an EKS cluster and the resources eksctl created for it.
+func (provisioner *EKSProvisioner) DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	if eksMetadata == nil || len(eksMetadata.Name) == 0 {
+		logger.Info("Cluster has no name; assuming it was never created")
+		return nil
+	}
+
+	logger.WithField("name", eksMetadata.Name).Info("Deleting cluster")
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	err = eksctl.WriteKubeconfig(eksMetadata.Name, eksMetadata.Region)
+	if err != nil {
+		return errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	ugh, err := newUtilityGroupHandle(eksctl, provisioner.kopsProvisioner, cluster, awsClient, logger)
+	if err != nil {
+		return errors.Wrap(err, "couldn't create new utility group handle while deleting the cluster")
+	}
+
+	err = ugh.DestroyUtilityGroup()
+	if err != nil {
+		return errors.Wrap(err, "failed to destroy all services in the utility group")
+	}
+
+	err = eksctl.DeleteCluster(eksMetadata.Name, eksMetadata.Region)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Successfully deleted cluster")
+
+	return nil
+}
+
+// RefreshClusterMetadata updates the EKS metadata of a cluster with the
+// current values of the running cluster.
+func (provisioner *EKSProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Refreshing EKS metadata")
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	eksMetadata := cluster.ProvisionerMetadataEKS
+	summary, err := eksctl.GetCluster(eksMetadata.Name, eksMetadata.Region)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster")
+	}
+	eksMetadata.Version = summary.Version
+
+	nodeGroups, err := eksctl.GetNodeGroups(eksMetadata.Name, eksMetadata.Region)
+	if err != nil {
+		return errors.Wrap(err, "failed to get node groups")
+	}
+	if len(eksMetadata.NodeGroupName) == 0 && len(nodeGroups) == 1 {
+		eksMetadata.NodeGroupName = nodeGroups[0].Name
+	}
+	for _, nodeGroup := range nodeGroups {
+		if nodeGroup.Name == eksMetadata.NodeGroupName {
+			eksMetadata.NodeInstanceType = nodeGroup.InstanceType
+			eksMetadata.NodeMinCount = nodeGroup.MinSize
+			eksMetadata.NodeMaxCount = nodeGroup.MaxSize
+			return nil
+		}
+	}
+
+	return errors.Errorf("failed to find node group %s", eksMetadata.NodeGroupName)
+}
+
+// UpgradeClusterUtility upgrades or rolls back the single utility of the
+// pending utility upgrade of an EKS cluster.
+func (provisioner *EKSProvisioner) UpgradeClusterUtility(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	err = eksctl.WriteKubeconfig(cluster.ProvisionerMetadataEKS.Name, cluster.ProvisionerMetadataEKS.Region)
+	if err != nil {
+		return errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	err = upgradeClusterUtility(eksctl, provisioner.kopsProvisioner, cluster, awsClient, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Successfully upgraded cluster utility")
+
+	return nil
+}
+
+// CheckClusterUtilityDrift compares the Helm releases of the utilities
+// running in an EKS cluster with the versions and values last deployed.
+func (provisioner *EKSProvisioner) CheckClusterUtilityDrift(cluster *model.Cluster) (*model.UtilityDriftCheck, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	err = eksctl.WriteKubeconfig(cluster.ProvisionerMetadataEKS.Name, cluster.ProvisionerMetadataEKS.Region)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	return checkUtilityDrift(eksctl, cluster, logger)
+}
+
+// PreviewClusterChanges is not supported by the EKS provisioner.
+func (provisioner *EKSProvisioner) PreviewClusterChanges(cluster *model.Cluster) (*model.ClusterChangePreview, error) {
+	return nil, errors.New("previewing cluster changes is not supported by the eks provisioner")
+}
+
+// GetClusterInstanceGroups returns the managed node groups of an EKS cluster.
+// The control plane of EKS clusters is managed by AWS, so there are no master
+// instance groups.
+func (provisioner *EKSProvisioner) GetClusterInstanceGroups(cluster *model.Cluster) ([]string, []string, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	nodeGroups, err := eksctl.GetNodeGroups(cluster.ProvisionerMetadataEKS.Name, cluster.ProvisionerMetadataEKS.Region)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get node groups")
+	}
+
+	var nodeGroupNames []string
+	for _, nodeGroup := range nodeGroups {
+		nodeGroupNames = append(nodeGroupNames, nodeGroup.Name)
+	}
+
+	return nil, nodeGroupNames, nil
+}
+
+// RollingUpdateClusterInstanceGroups is not supported by the EKS provisioner.
+func (provisioner *EKSProvisioner) RollingUpdateClusterInstanceGroups(cluster *model.Cluster, igNames []string) error {
+	return errors.New("staged upgrades are not supported by the eks provisioner")
+}
+
+// RevertClusterUpgrade is not supported by the EKS provisioner.
+func (provisioner *EKSProvisioner) RevertClusterUpgrade(cluster *model.Cluster) error {
+	return errors.New("staged upgrades are not supported by the eks provisioner")
+}
+
+// getKubeClient returns a kubernetes client for an EKS cluster.
+func (provisioner *EKSProvisioner) getKubeClient(cluster *model.Cluster, logger log.FieldLogger) (*k8s.KubeClient, error) {
+	eksctl, err := eksctl.New(logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create eksctl wrapper")
+	}
+	defer eksctl.Close()
+
+	err = eksctl.WriteKubeconfig(cluster.ProvisionerMetadataEKS.Name, cluster.ProvisionerMetadataEKS.Region)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	k8sClient, err := k8s.NewFromFile(eksctl.GetKubeConfigPath(), logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	return k8sClient, nil
+}
+
+// newEKSNodeGroup returns the managed node group running the worker nodes.
+// The name includes the instance type since it can't be changed.
+func newEKSNodeGroup(instanceType string, minCount, maxCount int64) eksctl.ManagedNodeGroup {
+	return eksctl.ManagedNodeGroup{
+		Name:            fmt.Sprintf("nodes-%s", strings.ReplaceAll(instanceType, ".", "-")),
+		InstanceType:    instanceType,
+		MinSize:         minCount,
+		MaxSize:         maxCount,
+		DesiredCapacity: minCount,
+	}
+}
+
+// eksVersion converts a cluster version to the major.minor version EKS
+// expects.
+func eksVersion(version string) string {
+	if len(version) == 0 || version == "latest" {
+		return eksDefaultVersion
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// regionFromZones returns the AWS region of the given availability zones.
+func regionFromZones(zones []string) string {
+	if len(zones) == 0 {
+		return aws.DefaultAWSRegion
+	}
+
+	return strings.TrimRight(zones[0], "abcdefghijklmnopqrstuvwxyz")
+}
+
+// roleNameFromARN returns the name of an IAM role given its ARN.
+func roleNameFromARN(arn string) string {
+	index := strings.LastIndex(arn, "/")
+	if index == -1 {
+		return ""
+	}
+
+	return arn[index+1:]
+}
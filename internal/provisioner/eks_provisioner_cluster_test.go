@@ -0,0 +1,89 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEKSVersion(t *testing.T) {
+	assert.Equal(t, eksDefaultVersion, eksVersion(""))
+	assert.Equal(t, eksDefaultVersion, eksVersion("latest"))
+	assert.Equal(t, "1.16", eksVersion("1.16"))
+	assert.Equal(t, "1.16", eksVersion("1.16.10"))
+}
+
+func TestRegionFromZones(t *testing.T) {
+	assert.Equal(t, "us-east-1", regionFromZones(nil))
+	assert.Equal(t, "eu-west-2", regionFromZones([]string{"eu-west-2b", "eu-west-2c"}))
+}
+
+func TestRoleNameFromARN(t *testing.T) {
+	assert.Equal(t, "eksctl-node-role", roleNameFromARN("arn:aws:iam::123456789012:role/eksctl-node-role"))
+	assert.Empty(t, roleNameFromARN(""))
+}
+
+func TestNewEKSNodeGroup(t *testing.T) {
+	nodeGroup := newEKSNodeGroup("m5.large", 2, 4)
+	assert.Equal(t, "nodes-m5-large", nodeGroup.Name)
+	assert.Equal(t, "m5.large", nodeGroup.InstanceType)
+	assert.Equal(t, int64(2), nodeGroup.MinSize)
+	assert.Equal(t, int64(4), nodeGroup.MaxSize)
+	assert.Equal(t, int64(2), nodeGroup.DesiredCapacity)
+}
+
+func TestEKSPrepareCluster(t *testing.T) {
+	provisioner := NewEKSProvisioner(&KopsProvisioner{}, testlib.MakeLogger(t))
+
+	cluster := &model.Cluster{
+		ID:                     model.NewID(),
+		Provisioner:            model.ProvisionerEKS,
+		ProviderMetadataAWS:    &model.AWSMetadata{Zones: []string{"us-west-2a", "us-west-2b"}},
+		ProvisionerMetadataEKS: &model.EKSMetadata{},
+	}
+	require.True(t, provisioner.PrepareCluster(cluster))
+	assert.Equal(t, cluster.ID+"-eks", cluster.ProvisionerMetadataEKS.Name)
+	assert.Equal(t, "us-west-2", cluster.ProvisionerMetadataEKS.Region)
+
+	require.False(t, provisioner.PrepareCluster(cluster))
+}
+
+type mockClusterProvisioner struct {
+	ClusterProvisioner
+	prepared []string
+}
+
+func (p *mockClusterProvisioner) PrepareCluster(cluster *model.Cluster) bool {
+	p.prepared = append(p.prepared, cluster.ID)
+	return true
+}
+
+func TestRouter(t *testing.T) {
+	kopsProvisioner := &mockClusterProvisioner{}
+	eksProvisioner := &mockClusterProvisioner{}
+	router := NewRouter(
+		&KopsProvisioner{logger: testlib.MakeLogger(t)},
+		map[string]ClusterProvisioner{
+			model.ProvisionerKops: kopsProvisioner,
+			model.ProvisionerEKS:  eksProvisioner,
+		},
+	)
+
+	assert.True(t, router.PrepareCluster(&model.Cluster{ID: "legacy"}))
+	assert.True(t, router.PrepareCluster(&model.Cluster{ID: "kops", Provisioner: model.ProvisionerKops}))
+	assert.True(t, router.PrepareCluster(&model.Cluster{ID: "eks", Provisioner: model.ProvisionerEKS}))
+	assert.False(t, router.PrepareCluster(&model.Cluster{ID: "unknown", Provisioner: "unknown"}))
+
+	assert.Equal(t, []string{"legacy", "kops"}, kopsProvisioner.prepared)
+	assert.Equal(t, []string{"eks"}, eksProvisioner.prepared)
+
+	err := router.ResizeCluster(&model.Cluster{ID: "unknown", Provisioner: "unknown"})
+	require.EqualError(t, err, "unsupported provisioner unknown")
+}
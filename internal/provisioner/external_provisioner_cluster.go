@@ -0,0 +1,315 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ExternalProvisioner manages clusters that were imported with a kubeconfig.
+// It only deploys the operators and utilities to the cluster and never
+// changes the infrastructure of the cluster.
+type ExternalProvisioner struct {
+	// kopsProvisioner holds the utility settings shared with kops clusters.
+	kopsProvisioner *KopsProvisioner
+	// awsClient reads the kubeconfig of the clusters from AWS Secrets
+	// Manager.
+	awsClient aws.AWS
+	logger    log.FieldLogger
+}
+
+// NewExternalProvisioner creates a new ExternalProvisioner.
+func NewExternalProvisioner(kopsProvisioner *KopsProvisioner, awsClient aws.AWS, logger log.FieldLogger) *ExternalProvisioner {
+	logger = logger.WithField("provisioner", "external")
+
+	return &ExternalProvisioner{
+		kopsProvisioner: kopsProvisioner,
+		awsClient:       awsClient,
+		logger:          logger,
+	}
+}
+
+// ImportCluster verifies that the cluster can be reached with its kubeconfig.
+func (provisioner *ExternalProvisioner) ImportCluster(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	externalMetadata := cluster.ProvisionerMetadataExternal
+	config, err := clientcmd.Load([]byte(externalMetadata.Kubeconfig))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse kubeconfig")
+	}
+	currentContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return errors.Errorf("kubeconfig current context %q not found", config.CurrentContext)
+	}
+	if len(externalMetadata.Name) == 0 {
+		externalMetadata.Name = currentContext.Cluster
+	}
+
+	k8sClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+
+	versionInfo, err := k8sClient.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to cluster")
+	}
+	externalMetadata.Version = strings.TrimLeft(versionInfo.GitVersion, "v")
+
+	logger.WithField("name", externalMetadata.Name).Info("Successfully connected to imported cluster")
+
+	return nil
+}
+
+// PrepareCluster is a no-op for external clusters which are named after the
+// cluster in their kubeconfig when imported.
+func (provisioner *ExternalProvisioner) PrepareCluster(cluster *model.Cluster) bool {
+	return false
+}
+
+// CreateCluster is not supported for external clusters which must be
+// imported instead.
+func (provisioner *ExternalProvisioner) CreateCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	return errors.New("external clusters can't be created and must be imported")
+}
+
+// ProvisionCluster installs the operators, the bifrost service and the
+// cluster utilities on an external cluster.
+func (provisioner *ExternalProvisioner) ProvisionCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Provisioning cluster")
+
+	bifrostSecret, err := awsClient.GenerateBifrostUtilitySecret(cluster.ID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate bifrost secret")
+	}
+
+	kubeconfig, err := provisioner.writeKubeconfig(cluster)
+	if err != nil {
+		return err
+	}
+	defer kubeconfig.Close()
+
+	k8sClient, err := k8s.NewFromFile(kubeconfig.GetKubeConfigPath(), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	err = provisionClusterOperators(k8sClient, bifrostSecret, logger)
+	if err != nil {
+		return err
+	}
+
+	ugh, err := newUtilityGroupHandle(kubeconfig, provisioner.kopsProvisioner, cluster, awsClient, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new cluster utility group handle")
+	}
+
+	err = ugh.ProvisionUtilityGroup()
+	if err != nil {
+		return errors.Wrap(err, "failed to upgrade all services in utility group")
+	}
+
+	logger.Info("Successfully provisioned cluster")
+
+	return nil
+}
+
+// UpgradeCluster is not supported for external clusters.
+func (provisioner *ExternalProvisioner) UpgradeCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	return errors.New("upgrading external clusters is not supported")
+}
+
+// UpgradeClusterUtility upgrades or rolls back the single utility of the
+// pending utility upgrade of an external cluster.
+func (provisioner *ExternalProvisioner) UpgradeClusterUtility(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kubeconfig, err := provisioner.writeKubeconfig(cluster)
+	if err != nil {
+		return err
+	}
+	defer kubeconfig.Close()
+
+	err = upgradeClusterUtility(kubeconfig, provisioner.kopsProvisioner, cluster, awsClient, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Successfully upgraded cluster utility")
+
+	return nil
+}
+
+// CheckClusterUtilityDrift compares the Helm releases of the utilities
+// running in an external cluster with the versions and values last deployed.
+func (provisioner *ExternalProvisioner) CheckClusterUtilityDrift(cluster *model.Cluster) (*model.UtilityDriftCheck, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kubeconfig, err := provisioner.writeKubeconfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+	defer kubeconfig.Close()
+
+	return checkUtilityDrift(kubeconfig, cluster, logger)
+}
+
+// ResizeCluster is not supported for external clusters.
+func (provisioner *ExternalProvisioner) ResizeCluster(cluster *model.Cluster) error {
+	return errors.New("resizing external clusters is not supported")
+}
+
+// DeleteCluster removes the cluster utilities from an external cluster. The
+// cluster itself is left running.
+func (provisioner *ExternalProvisioner) DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Deleting cluster")
+
+	kubeconfig, err := provisioner.writeKubeconfig(cluster)
+	if err != nil {
+		return err
+	}
+	defer kubeconfig.Close()
+
+	ugh, err := newUtilityGroupHandle(kubeconfig, provisioner.kopsProvisioner, cluster, awsClient, logger)
+	if err != nil {
+		return errors.Wrap(err, "couldn't create new utility group handle while deleting the cluster")
+	}
+
+	err = ugh.DestroyUtilityGroup()
+	if err != nil {
+		return errors.Wrap(err, "failed to destroy all services in the utility group")
+	}
+
+	err = provisioner.awsClient.EnsureKubeconfigSecretDeleted(cluster.ProvisionerMetadataExternal.KubeconfigSecret, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete kubeconfig secret")
+	}
+
+	logger.Info("Successfully deleted cluster; the external cluster itself was left running")
+
+	return nil
+}
+
+// RefreshClusterMetadata updates the external metadata of a cluster with the
+// current values of the running cluster.
+func (provisioner *ExternalProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	logger.Info("Refreshing external metadata")
+
+	k8sClient, err := provisioner.getKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+
+	versionInfo, err := k8sClient.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version")
+	}
+	cluster.ProvisionerMetadataExternal.Version = strings.TrimLeft(versionInfo.GitVersion, "v")
+
+	return nil
+}
+
+// PreviewClusterChanges is not supported for external clusters.
+func (provisioner *ExternalProvisioner) PreviewClusterChanges(cluster *model.Cluster) (*model.ClusterChangePreview, error) {
+	return nil, errors.New("previewing cluster changes is not supported for external clusters")
+}
+
+// GetClusterInstanceGroups is not supported for external clusters.
+func (provisioner *ExternalProvisioner) GetClusterInstanceGroups(cluster *model.Cluster) ([]string, []string, error) {
+	return nil, nil, errors.New("instance groups of external clusters are not managed by the provisioner")
+}
+
+// RollingUpdateClusterInstanceGroups is not supported for external clusters.
+func (provisioner *ExternalProvisioner) RollingUpdateClusterInstanceGroups(cluster *model.Cluster, igNames []string) error {
+	return errors.New("instance groups of external clusters are not managed by the provisioner")
+}
+
+// RevertClusterUpgrade is not supported for external clusters.
+func (provisioner *ExternalProvisioner) RevertClusterUpgrade(cluster *model.Cluster) error {
+	return errors.New("upgrading external clusters is not supported")
+}
+
+// getKubeClient returns a kubernetes client for an external cluster.
+func (provisioner *ExternalProvisioner) getKubeClient(cluster *model.Cluster, logger log.FieldLogger) (*k8s.KubeClient, error) {
+	kubeconfig, err := provisioner.writeKubeconfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+	defer kubeconfig.Close()
+
+	k8sClient, err := k8s.NewFromFile(kubeconfig.GetKubeConfigPath(), logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	return k8sClient, nil
+}
+
+// writeKubeconfig writes the kubeconfig of an external cluster to a temporary
+// directory. The kubeconfig is only held by the cluster while it is imported
+// and is read from its secret afterwards.
+func (provisioner *ExternalProvisioner) writeKubeconfig(cluster *model.Cluster) (*kubeconfigFile, error) {
+	externalMetadata := cluster.ProvisionerMetadataExternal
+	kubeconfig := externalMetadata.Kubeconfig
+	if len(kubeconfig) == 0 {
+		var err error
+		kubeconfig, err = provisioner.awsClient.GetKubeconfigSecret(externalMetadata.KubeconfigSecret)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get kubeconfig secret")
+		}
+	}
+
+	return newKubeconfigFile(kubeconfig)
+}
+
+// kubeconfigFile is a kubeconfig written to a temporary directory.
+type kubeconfigFile struct {
+	tempDir string
+}
+
+// newKubeconfigFile writes the given kubeconfig to a temporary directory.
+// Close must be called to remove the file.
+func newKubeconfigFile(kubeconfig string) (*kubeconfigFile, error) {
+	tempDir, err := ioutil.TempDir("", "kubeconfig-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temporary kubeconfig directory")
+	}
+
+	file := &kubeconfigFile{tempDir: tempDir}
+	err = ioutil.WriteFile(file.GetKubeConfigPath(), []byte(kubeconfig), 0600)
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	return file, nil
+}
+
+// GetKubeConfigPath returns the path of the kubeconfig file.
+func (f *kubeconfigFile) GetKubeConfigPath() string {
+	return path.Join(f.tempDir, "kubeconfig")
+}
+
+// Close removes the kubeconfig file.
+func (f *kubeconfigFile) Close() error {
+	return os.RemoveAll(f.tempDir)
+}
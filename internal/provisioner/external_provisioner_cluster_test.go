@@ -0,0 +1,100 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestKubeconfig(server string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: imported-cluster
+  cluster:
+    server: %s
+contexts:
+- name: imported-context
+  context:
+    cluster: imported-cluster
+    user: imported-user
+current-context: imported-context
+users:
+- name: imported-user
+  user:
+    token: token
+`, server)
+}
+
+func TestKubeconfigFile(t *testing.T) {
+	kubeconfig, err := newKubeconfigFile("kubeconfig-content")
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(kubeconfig.GetKubeConfigPath())
+	require.NoError(t, err)
+	assert.Equal(t, "kubeconfig-content", string(data))
+
+	require.NoError(t, kubeconfig.Close())
+	_, err = os.Stat(kubeconfig.GetKubeConfigPath())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExternalImportCluster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major": "1", "minor": "17", "gitVersion": "v1.17.3"}`))
+	}))
+	defer server.Close()
+
+	provisioner := NewExternalProvisioner(&KopsProvisioner{}, nil, testlib.MakeLogger(t))
+
+	t.Run("invalid kubeconfig", func(t *testing.T) {
+		cluster := &model.Cluster{
+			Provisioner:                 model.ProvisionerExternal,
+			ProvisionerMetadataExternal: &model.ExternalMetadata{Kubeconfig: "{"},
+		}
+		require.Error(t, provisioner.ImportCluster(cluster))
+	})
+
+	t.Run("missing current context", func(t *testing.T) {
+		cluster := &model.Cluster{
+			Provisioner:                 model.ProvisionerExternal,
+			ProvisionerMetadataExternal: &model.ExternalMetadata{Kubeconfig: "apiVersion: v1\nkind: Config\n"},
+		}
+		require.EqualError(t, provisioner.ImportCluster(cluster), `kubeconfig current context "" not found`)
+	})
+
+	t.Run("unreachable cluster", func(t *testing.T) {
+		cluster := &model.Cluster{
+			Provisioner:                 model.ProvisionerExternal,
+			ProvisionerMetadataExternal: &model.ExternalMetadata{Kubeconfig: makeTestKubeconfig("http://127.0.0.1:1")},
+		}
+		require.Error(t, provisioner.ImportCluster(cluster))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cluster := &model.Cluster{
+			Provisioner:                 model.ProvisionerExternal,
+			ProvisionerMetadataExternal: &model.ExternalMetadata{Kubeconfig: makeTestKubeconfig(server.URL)},
+		}
+		require.NoError(t, provisioner.ImportCluster(cluster))
+		assert.Equal(t, "imported-cluster", cluster.ProvisionerMetadataExternal.Name)
+		assert.Equal(t, "1.17.3", cluster.ProvisionerMetadataExternal.Version)
+	})
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -18,17 +17,22 @@ import (
 type fluentbit struct {
 	provisioner    *KopsProvisioner
 	awsClient      aws.AWS
-	kops           *kops.Cmd
+	kubeconfig     kubeconfigProvider
+	cluster        *model.Cluster
 	logger         log.FieldLogger
 	desiredVersion string
 	actualVersion  string
 }
 
-func newFluentbitHandle(version string, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*fluentbit, error) {
+func newFluentbitHandle(cluster *model.Cluster, version string, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*fluentbit, error) {
 	if logger == nil {
 		return nil, errors.New("cannot instantiate Fluentbit handle with nil logger")
 	}
 
+	if cluster == nil {
+		return nil, errors.New("cannot create a connection to Fluentbit if the cluster provided is nil")
+	}
+
 	if provisioner == nil {
 		return nil, errors.New("cannot create a connection to Fluentbit if the provisioner provided is nil")
 	}
@@ -37,14 +41,15 @@ func newFluentbitHandle(version string, provisioner *KopsProvisioner, awsClient
 		return nil, errors.New("cannot create a connection to Fluentbit if the awsClient provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Fluentbit if the Kops command provided is nil")
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to Fluentbit if the kubeconfig provider is nil")
 	}
 
 	return &fluentbit{
 		provisioner:    provisioner,
 		awsClient:      awsClient,
-		kops:           kops,
+		kubeconfig:     kubeconfig,
+		cluster:        cluster,
 		logger:         logger.WithField("cluster-utility", model.FluentbitCanonicalName),
 		desiredVersion: version,
 	}, nil
@@ -136,8 +141,9 @@ func (f *fluentbit) NewHelmDeployment(logger log.FieldLogger) *helmDeployment {
 %s
 `, elasticSearchDNS, auditLogsConf),
 		valuesPath:      "helm-charts/fluent-bit_values.yaml",
+		valuesOverride:  f.cluster.UtilityValuesOverride(model.FluentbitCanonicalName),
 		kopsProvisioner: f.provisioner,
-		kops:            f.kops,
+		kubeconfig:      f.kubeconfig,
 		logger:          f.logger,
 		desiredVersion:  f.desiredVersion,
 	}
@@ -11,6 +11,7 @@ import (
 	mocks "github.com/mattermost/mattermost-cloud/internal/mocks/aws-tools"
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
+	"github.com/mattermost/mattermost-cloud/model"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
@@ -40,7 +41,7 @@ func TestNewHelmDeploymentWithAuditLogsConfiguration(t *testing.T) {
 		AnyTimes()
 
 	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle(&model.Cluster{}, "1.2.3", provisioner, awsClient, kops, logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -71,7 +72,7 @@ func TestNewHelmDeploymentWithDefaultConfiguration(t *testing.T) {
 		AnyTimes()
 
 	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle(&model.Cluster{}, "1.2.3", provisioner, awsClient, kops, logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -99,7 +100,7 @@ func TestNewHelmDeploymentWithZoneIDError(t *testing.T) {
 		AnyTimes()
 
 	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle(&model.Cluster{}, "1.2.3", provisioner, awsClient, kops, logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -131,7 +132,7 @@ func TestNewHelmDeploymentWithoutFindingAuditTag(t *testing.T) {
 		AnyTimes()
 
 	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle(&model.Cluster{}, "1.2.3", provisioner, awsClient, kops, logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -162,7 +163,7 @@ func TestNewHelmDeploymentWithNillTag(t *testing.T) {
 		AnyTimes()
 
 	kops := &kops.Cmd{}
-	fluentbit, err := newFluentbitHandle("1.2.3", provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle(&model.Cluster{}, "1.2.3", provisioner, awsClient, kops, logger)
 	require.NoError(t, err, "should not error when creating new fluentbit handler")
 	require.NotNil(t, fluentbit, "fluentbit should not be nil")
 
@@ -55,7 +55,7 @@ func (l Helm2ListOutput) asListOutput() *HelmListOutput {
 func (d *helmDeployment) ListV2() (*HelmListOutput, error) {
 	arguments := []string{
 		"list",
-		"--kubeconfig", d.kops.GetKubeConfigPath(),
+		"--kubeconfig", d.kubeconfig.GetKubeConfigPath(),
 		"--output", "json",
 	}
 
@@ -7,14 +7,17 @@ package provisioner
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/helm"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -27,11 +30,12 @@ type helmDeployment struct {
 	namespace           string
 	setArgument         string
 	valuesPath          string
+	valuesOverride      string
 	desiredVersion      string
 
 	cluster         *model.Cluster
 	kopsProvisioner *KopsProvisioner
-	kops            *kops.Cmd
+	kubeconfig      kubeconfigProvider
 	logger          log.FieldLogger
 }
 
@@ -39,7 +43,7 @@ func (d *helmDeployment) Update() error {
 	logger := d.logger.WithField("helm-update", d.chartName)
 
 	logger.Infof("Refreshing helm chart %s -- may trigger service upgrade", d.chartName)
-	err := upgradeHelmChart(*d, d.kops.GetKubeConfigPath(), logger)
+	err := upgradeHelmChart(*d, d.kubeconfig.GetKubeConfigPath(), logger)
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("got an error trying to upgrade the helm chart %s", d.chartName))
 	}
@@ -50,7 +54,7 @@ func (d *helmDeployment) Delete() error {
 	logger := d.logger.WithField("helm-delete", d.chartDeploymentName)
 
 	logger.Infof("Deleting helm chart %s", d.chartDeploymentName)
-	err := deleteHelmChart(*d, d.kops.GetKubeConfigPath(), logger)
+	err := deleteHelmChart(*d, d.kubeconfig.GetKubeConfigPath(), logger)
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("got an error trying to delete the helm chart %s", d.chartDeploymentName))
 	}
@@ -122,6 +126,41 @@ func helmRepoUpdate(logger log.FieldLogger) error {
 
 // upgradeHelmChart is used to upgrade Helm deployments.
 func upgradeHelmChart(chart helmDeployment, configPath string, logger log.FieldLogger) error {
+	var valuesOverridePath string
+	if chart.valuesOverride != "" {
+		valuesOverrideFile, err := ioutil.TempFile("", fmt.Sprintf("%s-values-*.yaml", chart.chartDeploymentName))
+		if err != nil {
+			return errors.Wrap(err, "failed to create values override file")
+		}
+		defer os.Remove(valuesOverrideFile.Name())
+
+		_, err = valuesOverrideFile.WriteString(chart.valuesOverride)
+		valuesOverrideFile.Close()
+		if err != nil {
+			return errors.Wrap(err, "failed to write values override file")
+		}
+		valuesOverridePath = valuesOverrideFile.Name()
+	}
+
+	helmClient, err := helm.NewV3(logger)
+	if err != nil {
+		return errors.Wrap(err, "unable to create helm wrapper")
+	}
+	defer helmClient.Close()
+
+	err = helmClient.RunGenericCommand(upgradeHelmChartArguments(chart, configPath, valuesOverridePath)...)
+	if err != nil {
+		return errors.Wrapf(err, "unable to upgrade helm chart %s", chart.chartName)
+	}
+
+	return nil
+}
+
+// upgradeHelmChartArguments returns the helm upgrade arguments of a chart.
+// The values override file is passed after the values file of the chart so
+// that it takes precedence. The --set values of the chart still override
+// both, which is why overrides may not set them.
+func upgradeHelmChartArguments(chart helmDeployment, configPath, valuesOverridePath string) []string {
 	arguments := []string{
 		"--debug",
 		"upgrade",
@@ -135,6 +174,9 @@ func upgradeHelmChart(chart helmDeployment, configPath string, logger log.FieldL
 		"--wait",
 		"--timeout", "20m",
 	}
+	if valuesOverridePath != "" {
+		arguments = append(arguments, "-f", valuesOverridePath)
+	}
 	if chart.setArgument != "" {
 		arguments = append(arguments, "--set", chart.setArgument)
 	}
@@ -142,18 +184,7 @@ func upgradeHelmChart(chart helmDeployment, configPath string, logger log.FieldL
 		arguments = append(arguments, "--version", chart.desiredVersion)
 	}
 
-	helmClient, err := helm.NewV3(logger)
-	if err != nil {
-		return errors.Wrap(err, "unable to create helm wrapper")
-	}
-	defer helmClient.Close()
-
-	err = helmClient.RunGenericCommand(arguments...)
-	if err != nil {
-		return errors.Wrapf(err, "unable to upgrade helm chart %s", chart.chartName)
-	}
-
-	return nil
+	return arguments
 }
 
 // deleteHelmChart is used to delete Helm charts.
@@ -213,7 +244,7 @@ func (l HelmListOutput) asListOutput() *HelmListOutput {
 func (d *helmDeployment) List() (*HelmListOutput, error) {
 	arguments := []string{
 		"list",
-		"--kubeconfig", d.kops.GetKubeConfigPath(),
+		"--kubeconfig", d.kubeconfig.GetKubeConfigPath(),
 		"--output", "json",
 		"--all-namespaces",
 	}
@@ -261,11 +292,64 @@ func (d *helmDeployment) Version() (string, error) {
 	return "", errors.Errorf("unable to get version for chart %s", d.chartDeploymentName)
 }
 
+// ValuesHash returns a hash of the user-supplied values of the deployed
+// release.
+func (d *helmDeployment) ValuesHash() (string, error) {
+	arguments := []string{
+		"get",
+		"values",
+		d.chartDeploymentName,
+		"--kubeconfig", d.kubeconfig.GetKubeConfigPath(),
+		"--namespace", d.namespace,
+		"--output", "json",
+	}
+
+	logger := d.logger.WithFields(log.Fields{
+		"cmd": "helm3",
+	})
+
+	helmClient, err := helm.NewV3(logger)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create helm wrapper")
+	}
+	defer helmClient.Close()
+
+	rawOutput, err := helmClient.RunCommandRaw(arguments...)
+	if err != nil {
+		if len(rawOutput) > 0 {
+			logger.Debugf("Helm output was:\n%s\n", string(rawOutput))
+		}
+		return "", errors.Wrapf(err, "while getting values of Helm release %s", d.chartDeploymentName)
+	}
+
+	return hashHelmValues(rawOutput)
+}
+
+// hashHelmValues returns a hash of JSON encoded Helm values that does not
+// depend on the order of the keys.
+func hashHelmValues(rawValues []byte) (string, error) {
+	var values map[string]interface{}
+	err := json.Unmarshal(rawValues, &values)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to unmarshal JSON output from helm get values")
+	}
+
+	// Maps are marshaled with sorted keys, so equal values produce equal
+	// hashes.
+	normalized, err := json.Marshal(values)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to marshal Helm values")
+	}
+	sum := sha256.Sum256(normalized)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // TryMigrate migrates Helm release from version 2 to 3 if it exists.
 func (d *helmDeployment) TryMigrate() error {
 	logger := d.logger.WithField("operation", "migrate")
 
-	hasTiller, err := tillerExists(logger, d.kops.GetKubeConfigPath())
+	hasTiller, err := tillerExists(logger, d.kubeconfig.GetKubeConfigPath())
 	if err != nil {
 		return errors.Wrap(err, "failed to check if Tiller exists on cluster")
 	}
@@ -287,7 +371,7 @@ func (d *helmDeployment) TryMigrate() error {
 	release := d.chartDeploymentName
 	if listV2.containsRelease(release) && !listV3.containsRelease(release) {
 		logger.Debugf("Release '%s' found with Helm 2 and not found with Helm 3. Starting the migration...", release)
-		return MigrateRelease(logger, d.kops.GetKubeConfigPath(), release)
+		return MigrateRelease(logger, d.kubeconfig.GetKubeConfigPath(), release)
 	}
 
 	logger.Debugf("Skipping migration of release '%s'", release)
@@ -0,0 +1,65 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeHelmChartArguments(t *testing.T) {
+	chart := helmDeployment{
+		chartDeploymentName: "nginx",
+		chartName:           "ingress-nginx/ingress-nginx",
+		namespace:           "nginx",
+		valuesPath:          "helm-charts/nginx_values.yaml",
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"--debug", "upgrade", "nginx", "ingress-nginx/ingress-nginx",
+			"--kubeconfig", "kubeconfig",
+			"-f", "helm-charts/nginx_values.yaml",
+			"--namespace", "nginx",
+			"--install", "--create-namespace", "--wait", "--timeout", "20m",
+		}, upgradeHelmChartArguments(chart, "kubeconfig", ""))
+	})
+
+	t.Run("values override, set argument and version", func(t *testing.T) {
+		chart := chart
+		chart.setArgument = "a=b"
+		chart.desiredVersion = "2.15.0"
+
+		assert.Equal(t, []string{
+			"--debug", "upgrade", "nginx", "ingress-nginx/ingress-nginx",
+			"--kubeconfig", "kubeconfig",
+			"-f", "helm-charts/nginx_values.yaml",
+			"--namespace", "nginx",
+			"--install", "--create-namespace", "--wait", "--timeout", "20m",
+			"-f", "override.yaml",
+			"--set", "a=b",
+			"--version", "2.15.0",
+		}, upgradeHelmChartArguments(chart, "kubeconfig", "override.yaml"))
+	})
+}
+
+func TestHashHelmValues(t *testing.T) {
+	hash, err := hashHelmValues([]byte(`{"controller":{"replicaCount":3,"kind":"Deployment"}}`))
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	reordered, err := hashHelmValues([]byte(`{"controller":{"kind":"Deployment","replicaCount":3}}`))
+	require.NoError(t, err)
+	assert.Equal(t, hash, reordered)
+
+	changed, err := hashHelmValues([]byte(`{"controller":{"kind":"Deployment","replicaCount":2}}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changed)
+
+	_, err = hashHelmValues([]byte(`invalid`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// certManagerIssuerAnnotation is the ingress annotation cert-manager uses to
+// issue a certificate for the TLS hosts of the ingress.
+const certManagerIssuerAnnotation = "cert-manager.io/cluster-issuer"
+
+// configureIngressTLS requests a Let's Encrypt certificate for the ingress of
+// a cluster installation when the provisioner issues a certificate per
+// installation. Otherwise TLS is terminated by the cluster load balancers.
+func (provisioner *KopsProvisioner) configureIngressTLS(spec *mmv1alpha1.ClusterInstallationSpec) {
+	spec.UseIngressTLS = provisioner.certManagerTLS
+
+	if !provisioner.certManagerTLS {
+		delete(spec.IngressAnnotations, certManagerIssuerAnnotation)
+		return
+	}
+
+	if spec.IngressAnnotations == nil {
+		spec.IngressAnnotations = map[string]string{}
+	}
+	spec.IngressAnnotations[certManagerIssuerAnnotation] = letsEncryptClusterIssuer
+}
+
+// ingressTLSSecretName returns the name of the TLS secret the Mattermost
+// operator configures on the ingress of the given host. cert-manager names
+// the certificate it issues for the ingress the same way.
+func ingressTLSSecretName(host string) string {
+	return strings.ReplaceAll(host, ".", "-") + "-tls-cert"
+}
+
+// GetClusterInstallationCertificate returns the status of the certificate
+// issued for the ingress of a cluster installation.
+func (provisioner *KopsProvisioner) GetClusterInstallationCertificate(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (*model.InstallationCertificate, error) {
+	result := &model.InstallationCertificate{
+		InstallationID: installation.ID,
+		ClusterID:      cluster.ID,
+		Status:         model.CertificateStatusDisabled,
+	}
+	if !provisioner.certManagerTLS {
+		return result, nil
+	}
+
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	result.SecretName = ingressTLSSecretName(installation.DNS)
+	certificate, err := k8sClient.GetCertificate(clusterInstallation.Namespace, result.SecretName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get certificate %s", result.SecretName)
+	}
+
+	setCertificateStatus(result, certificate)
+
+	return result, nil
+}
+
+// setCertificateStatus sets the status of an installation certificate from
+// the cert-manager Certificate, which is nil until cert-manager processes the
+// ingress.
+func setCertificateStatus(result *model.InstallationCertificate, certificate *unstructured.Unstructured) {
+	result.Status = model.CertificateStatusPending
+	if certificate == nil {
+		return
+	}
+
+	result.DNSNames, _, _ = unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	result.NotAfter = certificateTimeMillis(certificate, "notAfter")
+	result.RenewalTime = certificateTimeMillis(certificate, "renewalTime")
+
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+
+		if condition["status"] == "True" {
+			result.Status = model.CertificateStatusReady
+		} else {
+			result.Status = model.CertificateStatusNotReady
+		}
+		result.Message, _, _ = unstructured.NestedString(condition, "message")
+	}
+}
+
+// certificateTimeMillis returns the given time of the Certificate status in
+// milliseconds, or 0 if it is not set.
+func certificateTimeMillis(certificate *unstructured.Unstructured, field string) int64 {
+	value, _, _ := unstructured.NestedString(certificate.Object, "status", field)
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0
+	}
+
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConfigureIngressTLS(t *testing.T) {
+	t.Run("load balancer TLS", func(t *testing.T) {
+		provisioner := &KopsProvisioner{}
+		spec := &mmv1alpha1.ClusterInstallationSpec{
+			UseIngressTLS:      true,
+			IngressAnnotations: map[string]string{certManagerIssuerAnnotation: letsEncryptClusterIssuer, "other": "value"},
+		}
+
+		provisioner.configureIngressTLS(spec)
+		assert.False(t, spec.UseIngressTLS)
+		assert.Equal(t, map[string]string{"other": "value"}, spec.IngressAnnotations)
+	})
+
+	t.Run("cert-manager TLS", func(t *testing.T) {
+		provisioner := &KopsProvisioner{certManagerTLS: true}
+		spec := &mmv1alpha1.ClusterInstallationSpec{}
+
+		provisioner.configureIngressTLS(spec)
+		assert.True(t, spec.UseIngressTLS)
+		assert.Equal(t, map[string]string{certManagerIssuerAnnotation: letsEncryptClusterIssuer}, spec.IngressAnnotations)
+	})
+}
+
+func TestIngressTLSSecretName(t *testing.T) {
+	assert.Equal(t, "test-example-com-tls-cert", ingressTLSSecretName("test.example.com"))
+}
+
+func TestGetClusterInstallationCertificateDisabled(t *testing.T) {
+	provisioner := &KopsProvisioner{}
+
+	certificate, err := provisioner.GetClusterInstallationCertificate(&model.Cluster{ID: "cluster1"}, &model.Installation{ID: "installation1"}, &model.ClusterInstallation{})
+	require.NoError(t, err)
+	assert.Equal(t, &model.InstallationCertificate{
+		InstallationID: "installation1",
+		ClusterID:      "cluster1",
+		Status:         model.CertificateStatusDisabled,
+	}, certificate)
+}
+
+func TestSetCertificateStatus(t *testing.T) {
+	t.Run("not created yet", func(t *testing.T) {
+		result := &model.InstallationCertificate{}
+		setCertificateStatus(result, nil)
+		assert.Equal(t, model.CertificateStatusPending, result.Status)
+	})
+
+	t.Run("no conditions", func(t *testing.T) {
+		result := &model.InstallationCertificate{}
+		setCertificateStatus(result, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"dnsNames": []interface{}{"test.example.com"},
+				},
+			},
+		})
+		assert.Equal(t, &model.InstallationCertificate{
+			Status:   model.CertificateStatusPending,
+			DNSNames: []string{"test.example.com"},
+		}, result)
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		result := &model.InstallationCertificate{}
+		setCertificateStatus(result, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False", "message": "Issuing certificate"},
+					},
+				},
+			},
+		})
+		assert.Equal(t, model.CertificateStatusNotReady, result.Status)
+		assert.Equal(t, "Issuing certificate", result.Message)
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		result := &model.InstallationCertificate{}
+		setCertificateStatus(result, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Issuing", "status": "False"},
+						map[string]interface{}{"type": "Ready", "status": "True", "message": "Certificate is up to date and has not expired"},
+					},
+					"notAfter":    "2020-10-01T10:00:00Z",
+					"renewalTime": "2020-09-01T10:00:00Z",
+				},
+			},
+		})
+		assert.True(t, result.IsReady())
+		assert.Equal(t, int64(1601546400000), result.NotAfter)
+		assert.Equal(t, int64(1598954400000), result.RenewalTime)
+	})
+}
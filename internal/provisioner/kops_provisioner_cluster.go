@@ -5,10 +5,13 @@
 package provisioner
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -30,6 +33,10 @@ import (
 // version value.
 const DefaultKubernetesVersion = "0.0.0"
 
+// previewStateStoreDirectory is the directory of a kops state store that holds
+// the temporary copies of cluster state used to preview cluster changes.
+const previewStateStoreDirectory = "previews"
+
 // KopsProvisioner provisions clusters using kops+terraform.
 type KopsProvisioner struct {
 	s3StateStore            string
@@ -38,14 +45,20 @@ type KopsProvisioner struct {
 	allowCIDRRangeList      []string
 	owner                   string
 	useExistingAWSResources bool
+	createVPCs              bool
+	vpcCIDR                 string
+	certManagerTLS          bool
 	resourceUtil            *utils.ResourceUtil
 	logger                  log.FieldLogger
 	store                   model.InstallationDatabaseStoreInterface
+	// kubeClientProviders create the kubernetes clients of the clusters
+	// managed by other provisioners, keyed by provisioner name.
+	kubeClientProviders map[string]kubeClientProvider
 }
 
 // NewKopsProvisioner creates a new KopsProvisioner.
 // TODO(gsagula): Consider replacing all these paramaters with a struct for readability.
-func NewKopsProvisioner(s3StateStore, owner string, useExistingAWSResources bool, allowCIDRRangeList []string,
+func NewKopsProvisioner(s3StateStore, owner string, useExistingAWSResources, createVPCs bool, vpcCIDR string, certManagerTLS bool, allowCIDRRangeList []string,
 	resourceUtil *utils.ResourceUtil, logger log.FieldLogger, store model.InstallationDatabaseStoreInterface) *KopsProvisioner {
 
 	logger = logger.WithField("provisioner", "kops")
@@ -53,6 +66,9 @@ func NewKopsProvisioner(s3StateStore, owner string, useExistingAWSResources bool
 	return &KopsProvisioner{
 		s3StateStore:            s3StateStore,
 		useExistingAWSResources: useExistingAWSResources,
+		createVPCs:              createVPCs,
+		vpcCIDR:                 vpcCIDR,
+		certManagerTLS:          certManagerTLS,
 		allowCIDRRangeList:      allowCIDRRangeList,
 		logger:                  logger,
 		resourceUtil:            resourceUtil,
@@ -61,6 +77,83 @@ func NewKopsProvisioner(s3StateStore, owner string, useExistingAWSResources bool
 	}
 }
 
+// getClusterKubeClient returns a kubernetes client for the cluster from the
+// provisioner managing it. Operations on cluster installations are shared by
+// all provisioners and only differ in how the cluster is reached.
+func (provisioner *KopsProvisioner) getClusterKubeClient(cluster *model.Cluster, logger log.FieldLogger) (*k8s.KubeClient, error) {
+	if len(cluster.Provisioner) == 0 || cluster.Provisioner == model.ProvisionerKops {
+		return provisioner.getKubeClient(cluster, logger)
+	}
+
+	kubeClientProvider, ok := provisioner.kubeClientProviders[cluster.Provisioner]
+	if !ok {
+		return nil, errors.Errorf("unsupported provisioner %s", cluster.Provisioner)
+	}
+
+	return kubeClientProvider.getKubeClient(cluster, logger)
+}
+
+// getKubeClient returns a kubernetes client for a kops cluster.
+func (provisioner *KopsProvisioner) getKubeClient(cluster *model.Cluster, logger log.FieldLogger) (*k8s.KubeClient, error) {
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to export kubecfg")
+	}
+
+	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	return k8sClient, nil
+}
+
+// getStateStore returns the kops state store of a cluster.
+func (provisioner *KopsProvisioner) getStateStore(cluster *model.Cluster) string {
+	if cluster.ProvisionerMetadataKops != nil && len(cluster.ProvisionerMetadataKops.StateStore) != 0 {
+		return cluster.ProvisionerMetadataKops.StateStore
+	}
+
+	return provisioner.s3StateStore
+}
+
+// ImportCluster verifies that an existing kops cluster created outside of the
+// provisioner can be reached with its kops state.
+func (provisioner *KopsProvisioner) ImportCluster(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to export kubecfg")
+	}
+
+	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to construct k8s client")
+	}
+
+	_, err = k8sClient.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to cluster")
+	}
+
+	logger.WithField("name", cluster.ProvisionerMetadataKops.Name).Info("Successfully connected to imported cluster")
+
+	return nil
+}
+
 // PrepareCluster ensures a cluster object is ready for provisioning.
 func (provisioner *KopsProvisioner) PrepareCluster(cluster *model.Cluster) bool {
 	// Don't regenerate the name if already set.
@@ -70,6 +163,10 @@ func (provisioner *KopsProvisioner) PrepareCluster(cluster *model.Cluster) bool
 
 	// Generate the kops name using the cluster id.
 	cluster.ProvisionerMetadataKops.Name = fmt.Sprintf("%s-kops.k8s.local", cluster.ID)
+	cluster.ProvisionerMetadataKops.ManagedVPC = provisioner.useExistingAWSResources && provisioner.createVPCs
+	if cluster.ProvisionerMetadataKops.ManagedVPC {
+		cluster.ProvisionerMetadataKops.ManagedVPCCIDR = provisioner.vpcCIDR
+	}
 
 	return true
 }
@@ -89,18 +186,25 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 	kopsMetadata := cluster.ProvisionerMetadataKops
 
 	logger.WithField("name", kopsMetadata.Name).Info("Creating cluster")
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
 	if err != nil {
 		return err
 	}
 	defer kops.Close()
 
 	var clusterResources aws.ClusterResources
-	if provisioner.useExistingAWSResources {
+	if kopsMetadata.ManagedVPC {
+		err = provisioner.createClusterVPC(cluster, logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to create cluster VPC")
+		}
+	}
+	if provisioner.useExistingAWSResources || kopsMetadata.ManagedVPC {
 		clusterResources, err = awsClient.GetAndClaimVpcResources(cluster.ID, provisioner.owner, logger)
 		if err != nil {
 			return err
 		}
+		kopsMetadata.VPCCIDRs = clusterResources.VpcCIDRs
 	}
 
 	err = kops.CreateCluster(
@@ -114,9 +218,13 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 		clusterResources.WorkerSecurityGroupIDs,
 	)
 	if err != nil {
-		releaseErr := awsClient.ReleaseVpc(cluster.ID, logger)
-		if releaseErr != nil {
-			logger.WithError(releaseErr).Error("Unable to release VPC")
+		// A managed VPC is kept so that it can be reused when the creation
+		// is retried and destroyed when the cluster is deleted.
+		if !kopsMetadata.ManagedVPC {
+			releaseErr := awsClient.ReleaseVpc(cluster.ID, logger)
+			if releaseErr != nil {
+				logger.WithError(releaseErr).Error("Unable to release VPC")
+			}
 		}
 
 		return errors.Wrap(err, "unable to create kops cluster")
@@ -156,6 +264,27 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 		return err
 	}
 
+	if kopsMetadata.ChangeRequest.NodeMixedInstancesPolicy != nil {
+		logger.Info("Setting node instance group mixed instances policy")
+		err = kops.SetInstanceGroupMixedInstancesPolicy(kopsMetadata.Name, defaultNodeInstanceGroupName, kopsMetadata.ChangeRequest.NodeMixedInstancesPolicy)
+		if err != nil {
+			return errors.Wrap(err, "failed to set node mixed instances policy")
+		}
+	}
+
+	if kopsMetadata.ChangeRequest.Autoscaling {
+		logger.Info("Enabling node instance group autoscaling")
+		err = kops.SetInstanceGroupAutoscaling(kopsMetadata.Name, defaultNodeInstanceGroupName, kopsMetadata.ChangeRequest.NodeMaxCount)
+		if err != nil {
+			return errors.Wrap(err, "failed to enable node autoscaling")
+		}
+	}
+
+	err = updateKopsNodeGroups(kops, kopsMetadata.Name, kopsMetadata.ChangeRequest, kopsMetadata.ChangeRequest.Autoscaling, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create node groups")
+	}
+
 	err = kops.UpdateCluster(kopsMetadata.Name, kops.GetOutputDirectory())
 	if err != nil {
 		return err
@@ -196,6 +325,12 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 	if err != nil {
 		return errors.Wrap(err, "unable to attach custom node policy")
 	}
+	if clusterAutoscalingEnabled(cluster) {
+		err = awsClient.AttachPolicyToRole(iamRole, aws.ClusterAutoscalerPolicyName, logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to attach cluster autoscaler policy")
+		}
+	}
 
 	ugh, err := newUtilityGroupHandle(kops, provisioner, cluster, awsClient, logger)
 	if err != nil {
@@ -211,7 +346,7 @@ func (provisioner *KopsProvisioner) CreateCluster(cluster *model.Cluster, awsCli
 func (provisioner *KopsProvisioner) ProvisionCluster(cluster *model.Cluster, awsClient aws.AWS) error {
 	logger := provisioner.logger.WithField("cluster", cluster.ID)
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to create kops wrapper")
 	}
@@ -445,6 +580,12 @@ func (provisioner *KopsProvisioner) ProvisionCluster(cluster *model.Cluster, aws
 	if err != nil {
 		return errors.Wrap(err, "unable to attach custom node policy")
 	}
+	if clusterAutoscalingEnabled(cluster) {
+		err = awsClient.AttachPolicyToRole(iamRole, aws.ClusterAutoscalerPolicyName, logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to attach cluster autoscaler policy")
+		}
+	}
 
 	ugh, err := newUtilityGroupHandle(kops, provisioner, cluster, awsClient, logger)
 	if err != nil {
@@ -461,7 +602,55 @@ func (provisioner *KopsProvisioner) ProvisionCluster(cluster *model.Cluster, aws
 	return nil
 }
 
+// UpgradeClusterUtility upgrades or rolls back the single utility of the
+// pending utility upgrade of the cluster without reprovisioning the rest of
+// the cluster.
+func (provisioner *KopsProvisioner) UpgradeClusterUtility(cluster *model.Cluster, awsClient aws.AWS) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to export kubecfg")
+	}
+
+	err = upgradeClusterUtility(kops, provisioner, cluster, awsClient, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Successfully upgraded cluster utility")
+
+	return nil
+}
+
+// CheckClusterUtilityDrift compares the Helm releases of the utilities
+// running in the cluster with the versions and values last deployed.
+func (provisioner *KopsProvisioner) CheckClusterUtilityDrift(cluster *model.Cluster) (*model.UtilityDriftCheck, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to export kubecfg")
+	}
+
+	return checkUtilityDrift(kops, cluster, logger)
+}
+
 // UpgradeCluster upgrades a cluster to the latest recommended production ready k8s version.
+// For staged upgrades, only the upgraded spec is applied and the instance
+// groups are left to be rolled in stages.
 func (provisioner *KopsProvisioner) UpgradeCluster(cluster *model.Cluster, awsClient aws.AWS) error {
 	logger := provisioner.logger.WithField("cluster", cluster.ID)
 
@@ -475,28 +664,15 @@ func (provisioner *KopsProvisioner) UpgradeCluster(cluster *model.Cluster, awsCl
 
 	kopsMetadata := cluster.ProvisionerMetadataKops
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to create kops wrapper")
 	}
 	defer kops.Close()
 
-	switch kopsMetadata.ChangeRequest.Version {
-	case "":
-		logger.Info("Skipping kubernetes cluster version update")
-	case "latest":
-		logger.Info("Updating kubernetes to latest stable version")
-		err = kops.UpgradeCluster(kopsMetadata.Name)
-		if err != nil {
-			return err
-		}
-	default:
-		logger.Infof("Updating kubernetes to version %s", kopsMetadata.ChangeRequest.Version)
-		setValue := fmt.Sprintf("spec.kubernetesVersion=%s", kopsMetadata.ChangeRequest.Version)
-		err = kops.SetCluster(kopsMetadata.Name, setValue)
-		if err != nil {
-			return err
-		}
+	err = updateKopsKubernetesVersion(kops, kopsMetadata, logger)
+	if err != nil {
+		return err
 	}
 
 	err = updateKopsInstanceGroupAMIs(kops, kopsMetadata, logger)
@@ -518,6 +694,13 @@ func (provisioner *KopsProvisioner) UpgradeCluster(cluster *model.Cluster, awsCl
 		return err
 	}
 
+	if kopsMetadata.ChangeRequest.IsStagedUpgrade() && kopsMetadata.ChangeRequest.UpgradeStrategy.MaxSurge != 0 {
+		err = setKopsNodeInstanceGroupsMaxSurge(kops, kopsMetadata.Name, kopsMetadata.ChangeRequest.UpgradeStrategy.MaxSurge, logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to set instance group max surge")
+		}
+	}
+
 	err = kops.UpdateCluster(kopsMetadata.Name, kops.GetOutputDirectory())
 	if err != nil {
 		return err
@@ -550,6 +733,12 @@ func (provisioner *KopsProvisioner) UpgradeCluster(cluster *model.Cluster, awsCl
 		return err
 	}
 
+	if kopsMetadata.ChangeRequest.IsStagedUpgrade() {
+		// The instance groups are rolled in stages by the cluster supervisor.
+		logger.Info("Applied cluster upgrade; skipping rolling update for staged upgrade")
+		return nil
+	}
+
 	err = kops.RollingUpdateCluster(kopsMetadata.Name)
 	if err != nil {
 		return err
@@ -579,7 +768,7 @@ func (provisioner *KopsProvisioner) ResizeCluster(cluster *model.Cluster) error
 
 	kopsMetadata := cluster.ProvisionerMetadataKops
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to create kops wrapper")
 	}
@@ -608,27 +797,7 @@ func (provisioner *KopsProvisioner) ResizeCluster(cluster *model.Cluster) error
 
 	logger.Info("Resizing cluster")
 
-	igManifest, err := kops.GetInstanceGroupYAML(kopsMetadata.Name, "nodes")
-	if err != nil {
-		return err
-	}
-
-	igManifest, err = grossKopsReplaceSize(
-		igManifest,
-		kopsMetadata.ChangeRequest.NodeInstanceType,
-		fmt.Sprintf("%d", kopsMetadata.ChangeRequest.NodeMinCount),
-		fmt.Sprintf("%d", kopsMetadata.ChangeRequest.NodeMaxCount),
-	)
-	if err != nil {
-		return err
-	}
-
-	igFilename := "ig-nodes.yaml"
-	err = ioutil.WriteFile(path.Join(kops.GetTempDir(), igFilename), []byte(igManifest), 0600)
-	if err != nil {
-		return err
-	}
-	_, err = kops.Replace(igFilename)
+	err = resizeKopsNodes(kops, kopsMetadata.Name, kopsMetadata.ChangeRequest, kopsMetadata.IsAutoscaling(), logger)
 	if err != nil {
 		return err
 	}
@@ -670,13 +839,169 @@ func (provisioner *KopsProvisioner) ResizeCluster(cluster *model.Cluster) error
 	return nil
 }
 
+// PreviewClusterChanges returns the kops and terraform changes that applying
+// the cluster change request would make without applying them. The kops state
+// of the cluster is copied to a temporary state store where the change request
+// is rendered, so the live kops state store is never written to. The rendered
+// terraform configuration is planned against the live terraform state.
+func (provisioner *KopsProvisioner) PreviewClusterChanges(cluster *model.Cluster) (*model.ClusterChangePreview, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kopsMetadata := cluster.ProvisionerMetadataKops
+
+	awsClient := provisioner.resourceUtil.GetAWSClient()
+	if awsClient == nil {
+		return nil, errors.New("previewing cluster changes requires an AWS client")
+	}
+
+	stateStore := provisioner.getStateStore(cluster)
+	previewStateStore := path.Join(stateStore, previewStateStoreDirectory, model.NewID())
+	bucket, stateDirectory := splitStateStore(stateStore)
+	_, previewDirectory := splitStateStore(previewStateStore)
+
+	logger.Info("Previewing cluster changes")
+
+	defer func() {
+		err := awsClient.S3EnsureBucketDirectoryDeleted(bucket, previewDirectory+"/", logger)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to delete temporary kops state store")
+		}
+	}()
+	err := awsClient.S3CopyBucketDirectory(bucket, path.Join(stateDirectory, kopsMetadata.Name)+"/", path.Join(previewDirectory, kopsMetadata.Name)+"/", logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy kops state to temporary state store")
+	}
+
+	kops, err := kops.New(previewStateStore, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	// The cluster spec points kops at the state store it writes the rendered
+	// configuration to, which must be the temporary one.
+	err = kops.SetCluster(kopsMetadata.Name, fmt.Sprintf("spec.configBase=s3://%s/%s", previewStateStore, kopsMetadata.Name))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to point cluster spec at temporary state store")
+	}
+
+	preview, err := previewKopsChangeRequest(kops, kopsMetadata, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// The instances of the cluster read their configuration from the live
+	// state store, so the rendered terraform configuration must reference it
+	// to only show the requested changes.
+	err = replaceInDirectory(kops.GetOutputDirectory(), fmt.Sprintf("s3://%s", previewStateStore), fmt.Sprintf("s3://%s", stateStore))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to point terraform configuration at live state store")
+	}
+
+	terraformClient, err := terraform.New(kops.GetOutputDirectory(), provisioner.s3StateStore, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer terraformClient.Close()
+
+	err = terraformClient.Init(kopsMetadata.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	preview.TerraformPlan, err = terraformClient.PlanOutput()
+	if err != nil {
+		return nil, err
+	}
+
+	preview.ClusterID = cluster.ID
+	preview.ChangeRequest = kopsMetadata.ChangeRequest
+
+	return preview, nil
+}
+
+// previewKopsChangeRequest applies the change request to the kops state store
+// and renders the resulting changes into the kops output directory.
+func previewKopsChangeRequest(kopsClient *kops.Cmd, kopsMetadata *model.KopsMetadata, logger log.FieldLogger) (*model.ClusterChangePreview, error) {
+	err := updateKopsKubernetesVersion(kopsClient, kopsMetadata, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateKopsInstanceGroupAMIs(kopsClient, kopsMetadata, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update kops instance group AMIs")
+	}
+
+	err = resizeKopsNodes(kopsClient, kopsMetadata.Name, kopsMetadata.ChangeRequest, kopsMetadata.IsAutoscaling(), logger)
+	if err != nil {
+		return nil, err
+	}
+
+	kopsUpdate, err := kopsClient.PreviewUpdateCluster(kopsMetadata.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = kopsClient.UpdateCluster(kopsMetadata.Name, kopsClient.GetOutputDirectory())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ClusterChangePreview{
+		KopsUpdate: kopsUpdate,
+	}, nil
+}
+
+// splitStateStore returns the bucket and the directory inside of it of a kops
+// state store.
+func splitStateStore(stateStore string) (string, string) {
+	parts := strings.SplitN(stateStore, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// replaceInDirectory replaces every occurrence of old with replacement in the
+// files of the given directory.
+func replaceInDirectory(dir, old, replacement string) error {
+	return filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(content, []byte(old)) {
+			return nil
+		}
+
+		return ioutil.WriteFile(filePath, bytes.ReplaceAll(content, []byte(old), []byte(replacement)), info.Mode())
+	})
+}
+
 // DeleteCluster deletes a previously created cluster using kops and terraform.
 func (provisioner *KopsProvisioner) DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error {
 	logger := provisioner.logger.WithField("cluster", cluster.ID)
 
 	kopsMetadata := cluster.ProvisionerMetadataKops
+	if kopsMetadata.Imported {
+		logger.Info("Skipping deletion of utilities, IAM policies, kops and terraform resources of imported cluster")
+		return nil
+	}
+
+	if kopsMetadata.ManagedVPC {
+		err := provisioner.ensureClusterVPCUnused(cluster, awsClient, logger)
+		if err != nil {
+			return err
+		}
+	}
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to create kops wrapper")
 	}
@@ -702,6 +1027,12 @@ func (provisioner *KopsProvisioner) DeleteCluster(cluster *model.Cluster, awsCli
 	if err != nil {
 		return errors.Wrap(err, "unable to detach custom node policy")
 	}
+	if clusterAutoscalingEnabled(cluster) {
+		err = awsClient.DetachPolicyFromRole(iamRole, aws.ClusterAutoscalerPolicyName, logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to detach cluster autoscaler policy")
+		}
+	}
 
 	_, err = kops.GetCluster(kopsMetadata.Name)
 	if err != nil {
@@ -748,9 +1079,16 @@ func (provisioner *KopsProvisioner) DeleteCluster(cluster *model.Cluster, awsCli
 		}
 	}
 
-	err = awsClient.ReleaseVpc(cluster.ID, logger)
-	if err != nil {
-		return errors.Wrap(err, "unable to release VPC")
+	if kopsMetadata.ManagedVPC {
+		err = provisioner.destroyClusterVPC(cluster, logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to destroy cluster VPC")
+		}
+	} else {
+		err = awsClient.ReleaseVpc(cluster.ID, logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to release VPC")
+		}
 	}
 
 	logger.Info("Successfully deleted cluster")
@@ -762,20 +1100,9 @@ func (provisioner *KopsProvisioner) DeleteCluster(cluster *model.Cluster, awsCli
 func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error) {
 	logger := provisioner.logger.WithField("cluster", cluster.ID)
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to construct k8s client")
+		return nil, err
 	}
 
 	ctx := context.TODO()
@@ -783,9 +1110,15 @@ func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster,
 	if err != nil {
 		return nil, err
 	}
-	usedCPU, usedMemory := k8s.CalculateTotalPodMilliResourceRequests(allPods)
+	resourceQuotas, err := k8sClient.Clientset.CoreV1().ResourceQuotas("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	usedCPU, usedMemory := k8s.CalculateTotalMilliResourceReservations(allPods, resourceQuotas)
 
-	var totalCPU, totalMemory int64
+	var totalCPU, totalMemory, nodeCount int64
+	nodeGroups := map[string]*k8s.ClusterResources{}
+	nodeGroupNodeNames := map[string]map[string]bool{}
 	nodes, err := k8sClient.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
@@ -815,25 +1148,48 @@ func (provisioner *KopsProvisioner) GetClusterResources(cluster *model.Cluster,
 		if !skipNode {
 			totalCPU += node.Status.Allocatable.Cpu().MilliValue()
 			totalMemory += node.Status.Allocatable.Memory().MilliValue()
+			nodeCount++
+
+			// Track the resources of each instance group separately so that
+			// installations pinned to a node group are sized against it.
+			groupName := node.GetLabels()[model.KopsInstanceGroupLabel]
+			if len(groupName) != 0 {
+				group, ok := nodeGroups[groupName]
+				if !ok {
+					group = &k8s.ClusterResources{}
+					nodeGroups[groupName] = group
+					nodeGroupNodeNames[groupName] = map[string]bool{}
+				}
+				group.MilliTotalCPU += node.Status.Allocatable.Cpu().MilliValue()
+				group.MilliTotalMemory += node.Status.Allocatable.Memory().MilliValue()
+				group.NodeCount++
+				nodeGroupNodeNames[groupName][node.GetName()] = true
+			}
 		}
 	}
 
+	for groupName, group := range nodeGroups {
+		group.MilliUsedCPU, group.MilliUsedMemory = k8s.CalculateNodesMilliResourceReservations(allPods, resourceQuotas, nodeGroupNodeNames[groupName])
+	}
+
 	return &k8s.ClusterResources{
 		MilliTotalCPU:    totalCPU,
 		MilliUsedCPU:     usedCPU,
 		MilliTotalMemory: totalMemory,
 		MilliUsedMemory:  usedMemory,
+		NodeCount:        nodeCount,
+		NodeGroups:       nodeGroups,
 	}, nil
 }
 
-// RefreshKopsMetadata updates the kops metadata of a cluster with the current
+// RefreshClusterMetadata updates the kops metadata of a cluster with the current
 // values of the running cluster.
-func (provisioner *KopsProvisioner) RefreshKopsMetadata(cluster *model.Cluster) error {
+func (provisioner *KopsProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
 	logger := provisioner.logger.WithField("cluster", cluster.ID)
 
 	logger.Info("Refreshing kops metadata")
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to create kops wrapper")
 	}
@@ -0,0 +1,80 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CreateClusterBackup triggers a Velero backup of the given namespaces of a
+// cluster. All namespaces are backed up when none are given.
+func (provisioner *KopsProvisioner) CreateClusterBackup(cluster *model.Cluster, namespaces []string) (*model.ClusterBackup, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create k8s client")
+	}
+
+	backup, err := k8sClient.CreateVeleroBackup(veleroNamespace, model.NewID(), namespaces)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero backup")
+	}
+
+	logger.WithField("backup", backup.GetName()).Info("Created cluster backup")
+
+	return veleroBackupToClusterBackup(backup), nil
+}
+
+// GetClusterBackups returns the Velero backups of a cluster.
+func (provisioner *KopsProvisioner) GetClusterBackups(cluster *model.Cluster) ([]*model.ClusterBackup, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create k8s client")
+	}
+
+	veleroBackups, err := k8sClient.ListVeleroBackups(veleroNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list velero backups")
+	}
+
+	backups := []*model.ClusterBackup{}
+	for i := range veleroBackups {
+		backups = append(backups, veleroBackupToClusterBackup(&veleroBackups[i]))
+	}
+
+	return backups, nil
+}
+
+func veleroBackupToClusterBackup(veleroBackup *unstructured.Unstructured) *model.ClusterBackup {
+	namespaces, _, _ := unstructured.NestedStringSlice(veleroBackup.Object, "spec", "includedNamespaces")
+	phase, _, _ := unstructured.NestedString(veleroBackup.Object, "status", "phase")
+
+	return &model.ClusterBackup{
+		Name:                veleroBackup.GetName(),
+		Namespaces:          namespaces,
+		Phase:               phase,
+		StartTimestamp:      veleroTimestampToMillis(veleroBackup, "startTimestamp"),
+		CompletionTimestamp: veleroTimestampToMillis(veleroBackup, "completionTimestamp"),
+	}
+}
+
+// veleroTimestampToMillis returns the given RFC 3339 status timestamp of a
+// Velero backup in milliseconds, or 0 if it is not set.
+func veleroTimestampToMillis(veleroBackup *unstructured.Unstructured, field string) int64 {
+	value, _, _ := unstructured.NestedString(veleroBackup.Object, "status", field)
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0
+	}
+
+	return timestamp.UnixNano() / int64(time.Millisecond)
+}
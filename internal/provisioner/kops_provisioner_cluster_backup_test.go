@@ -0,0 +1,71 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestVeleroBackupToClusterBackup(t *testing.T) {
+	t.Run("in progress", func(t *testing.T) {
+		veleroBackup := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "backup1"},
+				"spec": map[string]interface{}{
+					"includedNamespaces": []interface{}{"ns1", "ns2"},
+				},
+				"status": map[string]interface{}{
+					"phase":          "InProgress",
+					"startTimestamp": "2020-10-01T10:00:00Z",
+				},
+			},
+		}
+
+		require.Equal(t, &model.ClusterBackup{
+			Name:           "backup1",
+			Namespaces:     []string{"ns1", "ns2"},
+			Phase:          "InProgress",
+			StartTimestamp: 1601546400000,
+		}, veleroBackupToClusterBackup(veleroBackup))
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		veleroBackup := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "backup2"},
+				"spec": map[string]interface{}{
+					"includedNamespaces": []interface{}{"*"},
+				},
+				"status": map[string]interface{}{
+					"phase":               "Completed",
+					"startTimestamp":      "2020-10-01T10:00:00Z",
+					"completionTimestamp": "2020-10-01T10:01:00Z",
+				},
+			},
+		}
+
+		require.Equal(t, &model.ClusterBackup{
+			Name:                "backup2",
+			Namespaces:          []string{"*"},
+			Phase:               "Completed",
+			StartTimestamp:      1601546400000,
+			CompletionTimestamp: 1601546460000,
+		}, veleroBackupToClusterBackup(veleroBackup))
+	})
+
+	t.Run("new", func(t *testing.T) {
+		veleroBackup := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "backup3"},
+			},
+		}
+
+		require.Equal(t, &model.ClusterBackup{Name: "backup3"}, veleroBackupToClusterBackup(veleroBackup))
+	})
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
 	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
@@ -31,18 +30,7 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 	})
 	logger.Info("Creating cluster installation")
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
 	if err != nil {
 		return err
 	}
@@ -63,6 +51,20 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 		return errors.Wrapf(err, "failed to create network policy %s", clusterInstallation.Namespace)
 	}
 
+	s3CIDRs, err := getS3CIDRs(provisioner.resourceUtil, cluster, installation.NetworkIsolation, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply network isolation")
+	}
+	err = ensureNetworkIsolation(k8sClient, cluster, clusterInstallation.Namespace, installation.NetworkIsolation, s3CIDRs)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply network isolation")
+	}
+
+	err = ensureResourceQuota(k8sClient, clusterInstallation.Namespace, installation)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply resource quota")
+	}
+
 	mattermostEnv := getMattermostEnvWithOverrides(installation)
 
 	mattermostInstallation := &mmv1alpha1.ClusterInstallation{
@@ -76,34 +78,16 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 			Labels:    generateClusterInstallationResourceLabels(installation, clusterInstallation),
 		},
 		Spec: mmv1alpha1.ClusterInstallationSpec{
-			Size:          installation.Size,
-			Version:       translateMattermostVersion(installation.Version),
-			Image:         installation.Image,
-			IngressName:   installation.DNS,
-			MattermostEnv: mattermostEnv.ToEnvList(),
-			UseIngressTLS: false,
-			IngressAnnotations: map[string]string{
-				"kubernetes.io/ingress.class":                          "nginx-controller",
-				"kubernetes.io/tls-acme":                               "true",
-				"nginx.ingress.kubernetes.io/proxy-buffering":          "on",
-				"nginx.ingress.kubernetes.io/proxy-body-size":          "100m",
-				"nginx.ingress.kubernetes.io/proxy-send-timeout":       "600",
-				"nginx.ingress.kubernetes.io/proxy-read-timeout":       "600",
-				"nginx.ingress.kubernetes.io/proxy-max-temp-file-size": "0",
-				"nginx.ingress.kubernetes.io/ssl-redirect":             "true",
-				"nginx.ingress.kubernetes.io/configuration-snippet": `
-				  proxy_force_ranges on;
-				  add_header Strict-Transport-Security "max-age=31536000; includeSubDomains" always;
-				  proxy_cache mattermost_cache;
-				  proxy_cache_revalidate on;
-				  proxy_cache_min_uses 2;
-				  proxy_cache_use_stale timeout;
-				  proxy_cache_lock on;
-				  proxy_cache_key "$host$request_uri$cookie_user";`,
-				"nginx.org/server-snippets": "gzip on;",
-			},
+			Version:            translateMattermostVersion(installation.Version),
+			Image:              installation.Image,
+			IngressName:        installation.DNS,
+			MattermostEnv:      mattermostEnv.ToEnvList(),
+			NodeSelector:       installation.NodeSelector(),
+			UseIngressTLS:      false,
+			IngressAnnotations: installationIngressAnnotations(),
 		},
 	}
+	provisioner.configureIngressTLS(&mattermostInstallation.Spec)
 
 	if installation.License != "" {
 		licenseSecretName := fmt.Sprintf("%s-license", makeClusterInstallationName(clusterInstallation))
@@ -123,7 +107,12 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 		logger.Debug("Cluster installation configured with a Mattermost license")
 	}
 
-	databaseSpec, databaseSecret, err := provisioner.resourceUtil.GetDatabase(installation).GenerateDatabaseSpecAndSecret(provisioner.store, logger)
+	resourceUtil, err := provisioner.resourceUtil.ForCluster(cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to get installation resource util")
+	}
+
+	databaseSpec, databaseSecret, err := resourceUtil.GetDatabase(installation).GenerateDatabaseSpecAndSecret(provisioner.store, logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate database configuration")
 	}
@@ -135,7 +124,7 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 		mattermostInstallation.Spec.Database = *databaseSpec
 	}
 
-	filestoreSpec, filestoreSecret, err := provisioner.resourceUtil.GetFilestore(installation).GenerateFilestoreSpecAndSecret(provisioner.store, logger)
+	filestoreSpec, filestoreSecret, err := resourceUtil.GetFilestore(installation).GenerateFilestoreSpecAndSecret(provisioner.store, logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate filestore configuration")
 	}
@@ -147,6 +136,11 @@ func (provisioner *KopsProvisioner) CreateClusterInstallation(cluster *model.Clu
 		mattermostInstallation.Spec.Minio = *filestoreSpec
 	}
 
+	err = configureInstallationSize(&mattermostInstallation.Spec, installation)
+	if err != nil {
+		return errors.Wrap(err, "failed to configure installation size")
+	}
+
 	ctx := context.TODO()
 	_, err = k8sClient.MattermostClientset.MattermostV1alpha1().ClusterInstallations(clusterInstallation.Namespace).Create(ctx, mattermostInstallation, metav1.CreateOptions{})
 	if err != nil {
@@ -166,20 +160,9 @@ func (provisioner *KopsProvisioner) HibernateClusterInstallation(cluster *model.
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
 	if err != nil {
-		return errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kubernetes client")
+		return err
 	}
 
 	ctx := context.TODO()
@@ -214,20 +197,9 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
 	if err != nil {
-		return errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kubernetes client")
+		return err
 	}
 
 	name := makeClusterInstallationName(clusterInstallation)
@@ -241,6 +213,20 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 		return errors.Wrapf(err, "failed to create network policy %s", clusterInstallation.Namespace)
 	}
 
+	s3CIDRs, err := getS3CIDRs(provisioner.resourceUtil, cluster, installation.NetworkIsolation, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply network isolation")
+	}
+	err = ensureNetworkIsolation(k8sClient, cluster, clusterInstallation.Namespace, installation.NetworkIsolation, s3CIDRs)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply network isolation")
+	}
+
+	err = ensureResourceQuota(k8sClient, clusterInstallation.Namespace, installation)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply resource quota")
+	}
+
 	ctx := context.TODO()
 	cr, err := k8sClient.MattermostClientset.MattermostV1alpha1().ClusterInstallations(clusterInstallation.Namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
@@ -266,26 +252,38 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 		cr.Spec.Image = installation.Image
 	}
 
+	// Always ensure the node selector matches the installation node group.
+	cr.Spec.NodeSelector = installation.NodeSelector()
+
+	// Always ensure the ingress TLS matches the provisioner configuration.
+	provisioner.configureIngressTLS(&cr.Spec)
+
 	// A few notes on installation sizing changes:
 	//  - Resource and replica changes are currently targeting the Mattermost
 	//    app pod only. Other pods such as those managed by the MinIO and
 	//    MySQL operator won't be updated with the current Mattermost
 	//    Operator logic. This is intentional as those apps can have replica
 	//    scaling issues.
+	//  - Custom sizes leave the size preset of the custom resource empty so
+	//    that the operator doesn't override the replicas and resources.
 	//  - Resizing currently ignores the installation scheduling algorithm.
 	//    There is no good interface to determine if the new installation
 	//    size will safely fit on the cluster. This could, in theory, be done
 	//    when the size request change comes in on the API, but would require
 	//    new scheduling logic. For now, take care when resizing.
 	//    TODO: address these issue.
-	if cr.Spec.Size == installation.Size {
+	desiredSize := installation.Size
+	if installation.CustomSize != nil {
+		desiredSize = ""
+	}
+	if cr.Spec.Size == desiredSize {
 		logger.Debugf("Cluster installation already on size %s", installation.Size)
 	} else {
 		logger.Debugf("Cluster installation size updated from %s to %s", cr.Spec.Size, installation.Size)
-		cr.Spec.Size = installation.Size
+		cr.Spec.Size = desiredSize
 	}
 
-	sizeTemplate, err := mmv1alpha1.GetClusterSize(installation.Size)
+	sizeTemplate, err := installation.GetSize()
 	if err != nil {
 		return errors.Wrap(err, "failed to get size requirements")
 	}
@@ -322,7 +320,12 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 		}
 	}
 
-	databaseSpec, databaseSecret, err := provisioner.resourceUtil.GetDatabase(installation).GenerateDatabaseSpecAndSecret(provisioner.store, logger)
+	resourceUtil, err := provisioner.resourceUtil.ForCluster(cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to get installation resource util")
+	}
+
+	databaseSpec, databaseSecret, err := resourceUtil.GetDatabase(installation).GenerateDatabaseSpecAndSecret(provisioner.store, logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate database configuration")
 	}
@@ -334,7 +337,7 @@ func (provisioner *KopsProvisioner) UpdateClusterInstallation(cluster *model.Clu
 		cr.Spec.Database = *databaseSpec
 	}
 
-	filestoreSpec, filestoreSecret, err := provisioner.resourceUtil.GetFilestore(installation).GenerateFilestoreSpecAndSecret(provisioner.store, logger)
+	filestoreSpec, filestoreSecret, err := resourceUtil.GetFilestore(installation).GenerateFilestoreSpecAndSecret(provisioner.store, logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate filestore configuration")
 	}
@@ -419,23 +422,12 @@ func (provisioner *KopsProvisioner) DeleteClusterInstallation(cluster *model.Clu
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	if cluster.ProvisionerMetadataKops.Name == "" {
+	if getClusterName(cluster) == "" {
 		logger.Infof("Cluster %s has no name, assuming cluster installation never existed.", cluster.ID)
 		return nil
 	}
 
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
 	if err != nil {
 		return err
 	}
@@ -480,23 +472,12 @@ func (provisioner *KopsProvisioner) GetClusterInstallationResource(cluster *mode
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	if cluster.ProvisionerMetadataKops.Name == "" {
+	if getClusterName(cluster) == "" {
 		logger.Infof("Cluster %s has no name, assuming cluster installation never existed.", cluster.ID)
 		return nil, nil
 	}
 
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -526,20 +507,9 @@ func (provisioner *KopsProvisioner) ExecClusterInstallationCLI(cluster *model.Cl
 		"installation": clusterInstallation.InstallationID,
 	})
 
-	kops, err := kops.New(provisioner.s3StateStore, logger)
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to construct k8s client")
+		return nil, err
 	}
 
 	ctx := context.TODO()
@@ -588,6 +558,41 @@ func (provisioner *KopsProvisioner) ExecClusterInstallationCLI(cluster *model.Cl
 	return output, err
 }
 
+// configureInstallationSize sets the size of a new cluster installation. The
+// operator derives the replicas and resources from the size preset, so those
+// of a custom size are set explicitly and the preset is left empty.
+func configureInstallationSize(spec *mmv1alpha1.ClusterInstallationSpec, installation *model.Installation) error {
+	if installation.CustomSize == nil {
+		spec.Size = installation.Size
+		return nil
+	}
+
+	size, err := installation.GetSize()
+	if err != nil {
+		return errors.Wrap(err, "failed to get size requirements")
+	}
+
+	spec.Size = ""
+	spec.Replicas = size.App.Replicas
+	spec.Resources = size.App.Resources
+	if installation.InternalDatabase() {
+		spec.Database.Replicas = size.Database.Replicas
+		spec.Database.Resources = size.Database.Resources
+		if installation.CustomSize.Database != nil {
+			spec.Database.StorageSize = installation.CustomSize.Database.StorageSize
+		}
+	}
+	if installation.InternalFilestore() {
+		spec.Minio.Replicas = size.Minio.Replicas
+		spec.Minio.Resources = size.Minio.Resources
+		if installation.CustomSize.Filestore != nil {
+			spec.Minio.StorageSize = installation.CustomSize.Filestore.StorageSize
+		}
+	}
+
+	return nil
+}
+
 // generateClusterInstallationResourceLabels generates standard resource labels
 // for ClusterInstallation resources.
 func generateClusterInstallationResourceLabels(installation *model.Installation, clusterInstallation *model.ClusterInstallation) map[string]string {
@@ -619,8 +624,9 @@ func getMattermostEnvWithOverrides(installation *model.Installation) model.EnvVa
 	mattermostEnv["MM_CLOUD_INSTALLATION_ID"] = model.EnvVar{Value: installation.ID}
 	mattermostEnv["MM_SERVICESETTINGS_ENABLELOCALMODE"] = model.EnvVar{Value: "true"}
 
-	// Filestore overrides.
-	if !installation.InternalFilestore() {
+	// Filestore overrides. Encryption of external buckets is managed by their
+	// owners.
+	if !installation.InternalFilestore() && installation.Filestore != model.InstallationFilestoreExternal {
 		mattermostEnv["MM_FILESETTINGS_AMAZONS3SSE"] = model.EnvVar{Value: "true"}
 	}
 	if installation.Filestore == model.InstallationFilestoreMultiTenantAwsS3 ||
@@ -637,3 +643,28 @@ func getMattermostEnvWithOverrides(installation *model.Installation) model.EnvVa
 
 	return mattermostEnv
 }
+
+// installationIngressAnnotations returns the annotations of the ingresses
+// serving an installation.
+func installationIngressAnnotations() map[string]string {
+	return map[string]string{
+		"kubernetes.io/ingress.class":                          "nginx-controller",
+		"kubernetes.io/tls-acme":                               "true",
+		"nginx.ingress.kubernetes.io/proxy-buffering":          "on",
+		"nginx.ingress.kubernetes.io/proxy-body-size":          "100m",
+		"nginx.ingress.kubernetes.io/proxy-send-timeout":       "600",
+		"nginx.ingress.kubernetes.io/proxy-read-timeout":       "600",
+		"nginx.ingress.kubernetes.io/proxy-max-temp-file-size": "0",
+		"nginx.ingress.kubernetes.io/ssl-redirect":             "true",
+		"nginx.ingress.kubernetes.io/configuration-snippet": `
+				  proxy_force_ranges on;
+				  add_header Strict-Transport-Security "max-age=31536000; includeSubDomains" always;
+				  proxy_cache mattermost_cache;
+				  proxy_cache_revalidate on;
+				  proxy_cache_min_uses 2;
+				  proxy_cache_use_stale timeout;
+				  proxy_cache_lock on;
+				  proxy_cache_key "$host$request_uri$cookie_user";`,
+		"nginx.org/server-snippets": "gzip on;",
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureInstallationSize(t *testing.T) {
+	t.Run("preset", func(t *testing.T) {
+		spec := &mmv1alpha1.ClusterInstallationSpec{}
+		err := configureInstallationSize(spec, &model.Installation{Size: mmv1alpha1.Size1000String})
+		require.NoError(t, err)
+		assert.Equal(t, mmv1alpha1.Size1000String, spec.Size)
+		assert.Zero(t, spec.Replicas)
+	})
+
+	t.Run("custom size, internal database and filestore", func(t *testing.T) {
+		spec := &mmv1alpha1.ClusterInstallationSpec{}
+		err := configureInstallationSize(spec, &model.Installation{
+			Size:      mmv1alpha1.Size100String,
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			CustomSize: &model.InstallationCustomSize{
+				App:       &model.InstallationComponentSize{Replicas: 3, MemoryLimit: "8Gi"},
+				Database:  &model.InstallationComponentSize{StorageSize: "100Gi"},
+				Filestore: &model.InstallationComponentSize{Replicas: 4},
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, spec.Size)
+		assert.Equal(t, int32(3), spec.Replicas)
+		assert.Equal(t, "8Gi", spec.Resources.Limits.Memory().String())
+		assert.Equal(t, int32(1), spec.Database.Replicas)
+		assert.Equal(t, "100Gi", spec.Database.StorageSize)
+		assert.Equal(t, int32(4), spec.Minio.Replicas)
+		assert.Empty(t, spec.Minio.StorageSize)
+	})
+
+	t.Run("custom size, external database and filestore", func(t *testing.T) {
+		spec := &mmv1alpha1.ClusterInstallationSpec{}
+		err := configureInstallationSize(spec, &model.Installation{
+			Size:      mmv1alpha1.Size100String,
+			Database:  model.InstallationDatabaseMultiTenantRDSPostgres,
+			Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+			CustomSize: &model.InstallationCustomSize{
+				App:      &model.InstallationComponentSize{Replicas: 2},
+				Database: &model.InstallationComponentSize{StorageSize: "100Gi"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), spec.Replicas)
+		assert.Zero(t, spec.Database.Replicas)
+		assert.Empty(t, spec.Database.StorageSize)
+		assert.Zero(t, spec.Minio.Replicas)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStateStore(t *testing.T) {
+	bucket, directory := splitStateStore("state-store")
+	assert.Equal(t, "state-store", bucket)
+	assert.Empty(t, directory)
+
+	bucket, directory = splitStateStore("state-store/previews/id")
+	assert.Equal(t, "state-store", bucket)
+	assert.Equal(t, "previews/id", directory)
+}
+
+func TestReplaceInDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preview-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.Mkdir(path.Join(dir, "data"), 0700))
+	userData := path.Join(dir, "data", "user_data")
+	require.NoError(t, ioutil.WriteFile(userData, []byte("ConfigBase: s3://state-store/previews/id/cluster\n"), 0600))
+	kubernetes := path.Join(dir, "kubernetes.tf")
+	require.NoError(t, ioutil.WriteFile(kubernetes, []byte("locals {}\n"), 0600))
+
+	err = replaceInDirectory(dir, "s3://state-store/previews/id", "s3://state-store")
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(userData)
+	require.NoError(t, err)
+	assert.Equal(t, "ConfigBase: s3://state-store/cluster\n", string(content))
+
+	content, err = ioutil.ReadFile(kubernetes)
+	require.NoError(t, err)
+	assert.Equal(t, "locals {}\n", string(content))
+}
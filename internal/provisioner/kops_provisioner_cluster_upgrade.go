@@ -0,0 +1,269 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
+	"github.com/mattermost/mattermost-cloud/internal/tools/terraform"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetClusterInstanceGroups returns the names of the master and worker node
+// instance groups of a cluster.
+func (provisioner *KopsProvisioner) GetClusterInstanceGroups(cluster *model.Cluster) ([]string, []string, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	instanceGroups, err := kops.GetInstanceGroupsJSON(cluster.ProvisionerMetadataKops.Name)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get instance groups")
+	}
+
+	var masterIGs, nodeIGs []string
+	for _, ig := range instanceGroups {
+		switch ig.Spec.Role {
+		case "Master":
+			masterIGs = append(masterIGs, ig.Metadata.Name)
+		case "Node":
+			nodeIGs = append(nodeIGs, ig.Metadata.Name)
+		}
+	}
+
+	return masterIGs, nodeIGs, nil
+}
+
+// RollingUpdateClusterInstanceGroups replaces the instances of the given
+// instance groups of a cluster.
+func (provisioner *KopsProvisioner) RollingUpdateClusterInstanceGroups(cluster *model.Cluster, igNames []string) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	logger.Infof("Rolling instance groups %v", igNames)
+
+	err = kops.RollingUpdateInstanceGroups(cluster.ProvisionerMetadataKops.Name, igNames)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevertClusterUpgrade restores the Kubernetes version and AMI a cluster ran
+// before an aborted staged upgrade and rolls the instance groups that were
+// already upgraded back to them. The kops metadata still holds the previous
+// version and AMI, as it is only refreshed once an upgrade is finished. The
+// max surge set for the upgrade is removed from the worker node instance
+// groups.
+func (provisioner *KopsProvisioner) RevertClusterUpgrade(cluster *model.Cluster) error {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	kopsMetadata := cluster.ProvisionerMetadataKops
+	changeRequest := kopsMetadata.ChangeRequest
+	if changeRequest == nil {
+		return errors.New("cluster has no upgrade to revert")
+	}
+
+	revert := &model.KopsMetadata{
+		Name:          kopsMetadata.Name,
+		ChangeRequest: &model.KopsMetadataRequestedState{},
+	}
+	if len(changeRequest.Version) != 0 {
+		if len(kopsMetadata.Version) == 0 {
+			return errors.New("previous kubernetes version of the cluster is unknown")
+		}
+		revert.ChangeRequest.Version = kopsMetadata.Version
+	}
+	if len(changeRequest.AMI) != 0 {
+		revert.ChangeRequest.AMI = kopsMetadata.AMI
+		if len(kopsMetadata.AMI) == 0 {
+			revert.ChangeRequest.AMI = "latest"
+		}
+	}
+
+	kops, err := kops.New(provisioner.getStateStore(cluster), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kops wrapper")
+	}
+	defer kops.Close()
+
+	logger.Info("Reverting cluster upgrade")
+
+	err = updateKopsKubernetesVersion(kops, revert, logger)
+	if err != nil {
+		return err
+	}
+
+	err = updateKopsInstanceGroupAMIs(kops, revert, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to revert kops instance group AMIs")
+	}
+
+	if changeRequest.IsStagedUpgrade() && changeRequest.UpgradeStrategy.MaxSurge != 0 {
+		err = setKopsNodeInstanceGroupsMaxSurge(kops, kopsMetadata.Name, 0, logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to reset instance group max surge")
+		}
+	}
+
+	err = kops.UpdateCluster(kopsMetadata.Name, kops.GetOutputDirectory())
+	if err != nil {
+		return err
+	}
+
+	terraformClient, err := terraform.New(kops.GetOutputDirectory(), provisioner.s3StateStore, logger)
+	if err != nil {
+		return err
+	}
+	defer terraformClient.Close()
+
+	err = terraformClient.Init(kopsMetadata.Name)
+	if err != nil {
+		return err
+	}
+
+	err = terraformClient.Plan()
+	if err != nil {
+		return err
+	}
+	err = terraformClient.Apply()
+	if err != nil {
+		return err
+	}
+
+	if changeRequest.UpgradeProgress == nil || len(changeRequest.UpgradeProgress.UpgradedInstanceGroups) == 0 {
+		return nil
+	}
+
+	igNames := changeRequest.UpgradeProgress.UpgradedInstanceGroups
+	logger.Infof("Rolling instance groups %v back", igNames)
+
+	err = kops.RollingUpdateInstanceGroups(kopsMetadata.Name, igNames)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CheckClusterHealth checks the readiness of the cluster nodes and the
+// availability of the given cluster installations. A description of every
+// problem found is returned; an empty list means the cluster is healthy.
+func (provisioner *KopsProvisioner) CheckClusterHealth(cluster *model.Cluster, clusterInstallations []*model.ClusterInstallation) ([]string, error) {
+	logger := provisioner.logger.WithField("cluster", cluster.ID)
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.TODO()
+	nodes, err := k8sClient.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+
+	issues := nodeHealthIssues(nodes.Items)
+
+	for _, clusterInstallation := range clusterInstallations {
+		if clusterInstallation.IsDeleted() {
+			continue
+		}
+
+		name := makeClusterInstallationName(clusterInstallation)
+		deployment, err := k8sClient.Clientset.AppsV1().Deployments(clusterInstallation.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			issues = append(issues, fmt.Sprintf("Cluster installation %s has no deployment", clusterInstallation.ID))
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get deployment %s", name)
+		}
+
+		issue := deploymentHealthIssue(deployment)
+		if len(issue) != 0 {
+			issues = append(issues, fmt.Sprintf("Cluster installation %s %s", clusterInstallation.ID, issue))
+		}
+	}
+
+	logger.WithField("issues", len(issues)).Debug("Checked cluster health")
+
+	return issues, nil
+}
+
+// nodeHealthIssues returns a description of every node that isn't ready.
+func nodeHealthIssues(nodes []corev1.Node) []string {
+	var issues []string
+	for _, node := range nodes {
+		ready := false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				ready = true
+				break
+			}
+		}
+		if !ready {
+			issues = append(issues, fmt.Sprintf("Node %s is not ready", node.Name))
+		}
+	}
+
+	return issues
+}
+
+// deploymentHealthIssue returns a description of why the deployment is
+// unhealthy or an empty string if all of its replicas are available.
+func deploymentHealthIssue(deployment *appsv1.Deployment) string {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	if deployment.Status.AvailableReplicas < replicas {
+		return fmt.Sprintf("has %d of %d replicas available", deployment.Status.AvailableReplicas, replicas)
+	}
+
+	return ""
+}
+
+// setKopsNodeInstanceGroupsMaxSurge sets the max surge of every worker node
+// instance group. A max surge of 0 removes it.
+func setKopsNodeInstanceGroupsMaxSurge(kopsClient *kops.Cmd, clusterName string, maxSurge int64, logger log.FieldLogger) error {
+	instanceGroups, err := kopsClient.GetInstanceGroupsJSON(clusterName)
+	if err != nil {
+		return errors.Wrap(err, "failed to get instance groups")
+	}
+
+	for _, ig := range instanceGroups {
+		if ig.Spec.Role != "Node" {
+			continue
+		}
+
+		logger.Infof("Setting instance group %s max surge to %d", ig.Metadata.Name, maxSurge)
+		err = kopsClient.SetInstanceGroupMaxSurge(clusterName, ig.Metadata.Name, maxSurge)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeHealthIssues(t *testing.T) {
+	makeNode := func(name string, conditions ...corev1.NodeCondition) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{Conditions: conditions},
+		}
+	}
+
+	nodes := []corev1.Node{
+		makeNode("ready", corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}),
+		makeNode("not-ready", corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionFalse}),
+		makeNode("unknown"),
+		makeNode("pressure",
+			corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+			corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		),
+	}
+
+	assert.Equal(t, []string{"Node not-ready is not ready", "Node unknown is not ready"}, nodeHealthIssues(nodes))
+	assert.Empty(t, nodeHealthIssues(nodes[:1]))
+}
+
+func TestDeploymentHealthIssue(t *testing.T) {
+	replicas := int32(2)
+
+	var testCases = []struct {
+		testName      string
+		deployment    *appsv1.Deployment
+		expectedIssue string
+	}{
+		{
+			"available",
+			&appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{AvailableReplicas: 2},
+			},
+			"",
+		},
+		{
+			"unavailable replicas",
+			&appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{AvailableReplicas: 1},
+			},
+			"has 1 of 2 replicas available",
+		},
+		{
+			"default replicas",
+			&appsv1.Deployment{},
+			"has 0 of 1 replicas available",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			assert.Equal(t, tc.expectedIssue, deploymentHealthIssue(tc.deployment))
+		})
+	}
+}
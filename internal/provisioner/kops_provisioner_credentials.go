@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// restartedAtAnnotation is the pod template annotation used by kubectl to
+// trigger a rolling restart of a deployment.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// RestartClusterInstallation performs a rolling restart of the Mattermost pods
+// of a cluster installation so that they pick up updated secrets.
+func (provisioner *KopsProvisioner) RestartClusterInstallation(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) error {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+
+	name := makeClusterInstallationName(clusterInstallation)
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"%s":"%s"}}}}}`, restartedAtAnnotation, time.Now().Format(time.RFC3339))
+
+	ctx := context.TODO()
+	_, err = k8sClient.Clientset.AppsV1().Deployments(clusterInstallation.Namespace).Patch(ctx, name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to restart deployment %s", name)
+	}
+
+	logger.Info("Cluster installation restart started")
+
+	return nil
+}
+
+// IsClusterInstallationRestartComplete returns true when every Mattermost pod
+// of a cluster installation has been replaced and is available.
+func (provisioner *KopsProvisioner) IsClusterInstallationRestartComplete(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (bool, error) {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return false, err
+	}
+
+	name := makeClusterInstallationName(clusterInstallation)
+	deployment, err := k8sClient.Clientset.AppsV1().Deployments(clusterInstallation.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get deployment %s", name)
+	}
+
+	return isDeploymentRolledOut(deployment), nil
+}
+
+func isDeploymentRolledOut(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	return deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.Replicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsDeploymentRolledOut(t *testing.T) {
+	replicas := int32(2)
+	newDeployment := func(generation, observedGeneration int64, updated, total, available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: observedGeneration,
+				UpdatedReplicas:    updated,
+				Replicas:           total,
+				AvailableReplicas:  available,
+			},
+		}
+	}
+
+	assert.True(t, isDeploymentRolledOut(newDeployment(2, 2, 2, 2, 2)))
+	assert.False(t, isDeploymentRolledOut(newDeployment(3, 2, 2, 2, 2)), "generation not observed")
+	assert.False(t, isDeploymentRolledOut(newDeployment(2, 2, 1, 3, 2)), "old pods remaining")
+	assert.False(t, isDeploymentRolledOut(newDeployment(2, 2, 2, 2, 1)), "pods not available")
+}
@@ -0,0 +1,330 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	filestoreMigrationImage        = "rclone/rclone:1.53.1"
+	filestoreMigrationBackoffLimit = int32(2)
+	filestoreMigrationSourceSize   = "source-size: "
+	filestoreMigrationTargetSize   = "target-size: "
+)
+
+// filestoreMigrationScript copies the installation files and then prints the
+// object counts of both filestores so that the copy can be verified.
+var filestoreMigrationScript = strings.Join([]string{
+	"set -e",
+	`rclone copy --checksum "source:${SOURCE_PATH}" "target:${TARGET_PATH}"`,
+	fmt.Sprintf(`echo "%s$(rclone size --json "source:${SOURCE_PATH}")"`, filestoreMigrationSourceSize),
+	fmt.Sprintf(`echo "%s$(rclone size --json "target:${TARGET_PATH}")"`, filestoreMigrationTargetSize),
+}, "\n")
+
+// CreateFilestoreMigrationJob starts a job in the cluster installation
+// namespace that copies the installation files to the target filestore. Any
+// job left over from a previous copy attempt is replaced.
+func (provisioner *KopsProvisioner) CreateFilestoreMigrationJob(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, config *model.FilestoreMigrationConfig) error {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	if config == nil || config.Target == nil {
+		return errors.New("filestore migration target must be provided")
+	}
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+
+	err = deleteFilestoreMigrationJob(k8sClient, clusterInstallation, logger)
+	if err != nil {
+		return err
+	}
+
+	name := makeClusterInstallationName(clusterInstallation)
+	jobName := makeFilestoreMigrationJobName(clusterInstallation)
+
+	secretData := map[string]string{
+		"target-access-key-id":     config.Target.AccessKeyID,
+		"target-secret-access-key": config.Target.SecretAccessKey,
+	}
+	if config.Source != nil {
+		secretData["source-access-key-id"] = config.Source.AccessKeyID
+		secretData["source-secret-access-key"] = config.Source.SecretAccessKey
+	}
+
+	_, err = k8sClient.CreateOrUpdateSecret(clusterInstallation.Namespace, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: clusterInstallation.Namespace,
+		},
+		StringData: secretData,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create filestore migration secret %s/%s", clusterInstallation.Namespace, jobName)
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "TARGET_PATH", Value: filestorePath(config.Target)},
+	}
+	env = append(env, rcloneS3RemoteEnv("TARGET", config.Target, jobName)...)
+
+	if config.Source != nil {
+		env = append(env, corev1.EnvVar{Name: "SOURCE_PATH", Value: filestorePath(config.Source)})
+		env = append(env, rcloneS3RemoteEnv("SOURCE", config.Source, jobName)...)
+	} else {
+		// The source files are stored in the MinIO instance managed by the
+		// Mattermost operator for this cluster installation.
+		minioSecret := fmt.Sprintf("%s-minio", name)
+		env = append(env,
+			corev1.EnvVar{Name: "SOURCE_PATH", Value: name},
+			corev1.EnvVar{Name: "RCLONE_CONFIG_SOURCE_TYPE", Value: "s3"},
+			corev1.EnvVar{Name: "RCLONE_CONFIG_SOURCE_PROVIDER", Value: "Minio"},
+			corev1.EnvVar{Name: "RCLONE_CONFIG_SOURCE_ENDPOINT", Value: fmt.Sprintf("http://%s-minio-hl-svc.%s:9000", name, clusterInstallation.Namespace)},
+			corev1.EnvVar{Name: "RCLONE_CONFIG_SOURCE_ACCESS_KEY_ID", ValueFrom: secretKeyRef(minioSecret, "accesskey")},
+			corev1.EnvVar{Name: "RCLONE_CONFIG_SOURCE_SECRET_ACCESS_KEY", ValueFrom: secretKeyRef(minioSecret, "secretkey")},
+		)
+	}
+
+	backoffLimit := filestoreMigrationBackoffLimit
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: clusterInstallation.Namespace,
+			Labels:    generateClusterInstallationResourceLabels(installation, clusterInstallation),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": "filestore-migration"},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "filestore-migration",
+							Image:   filestoreMigrationImage,
+							Command: []string{"/bin/sh", "-c", filestoreMigrationScript},
+							Env:     env,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := context.TODO()
+	_, err = k8sClient.Clientset.BatchV1().Jobs(clusterInstallation.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create filestore migration job %s/%s", clusterInstallation.Namespace, jobName)
+	}
+
+	logger.Infof("Created filestore migration job %s", jobName)
+
+	return nil
+}
+
+// GetFilestoreMigrationJobStatus returns the status of the filestore migration
+// job of a cluster installation.
+func (provisioner *KopsProvisioner) GetFilestoreMigrationJobStatus(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (*model.FilestoreMigrationJobStatus, error) {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	jobName := makeFilestoreMigrationJobName(clusterInstallation)
+
+	ctx := context.TODO()
+	job, err := k8sClient.Clientset.BatchV1().Jobs(clusterInstallation.Namespace).Get(ctx, jobName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get filestore migration job %s/%s", clusterInstallation.Namespace, jobName)
+	}
+
+	status := &model.FilestoreMigrationJobStatus{}
+	if job.Status.Failed > filestoreMigrationBackoffLimit {
+		status.Failed = true
+		return status, nil
+	}
+	if job.Status.Succeeded == 0 {
+		return status, nil
+	}
+
+	pods, err := k8sClient.Clientset.CoreV1().Pods(clusterInstallation.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list filestore migration pods")
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+
+		logs, err := k8sClient.Clientset.CoreV1().Pods(clusterInstallation.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{}).Do(ctx).Raw()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get logs of filestore migration pod %s", pod.Name)
+		}
+
+		status.SourceObjectCount, status.TargetObjectCount, err = parseFilestoreMigrationObjectCounts(logs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse logs of filestore migration pod %s", pod.Name)
+		}
+		status.Complete = true
+
+		return status, nil
+	}
+
+	return nil, errors.Errorf("failed to find a succeeded pod for filestore migration job %s", jobName)
+}
+
+// DeleteFilestoreMigrationJob removes the filestore migration job of a cluster
+// installation along with its secret.
+func (provisioner *KopsProvisioner) DeleteFilestoreMigrationJob(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) error {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return err
+	}
+
+	err = deleteFilestoreMigrationJob(k8sClient, clusterInstallation, logger)
+	if err != nil {
+		return err
+	}
+
+	jobName := makeFilestoreMigrationJobName(clusterInstallation)
+	err = k8sClient.Clientset.CoreV1().Secrets(clusterInstallation.Namespace).Delete(context.TODO(), jobName, metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete filestore migration secret %s/%s", clusterInstallation.Namespace, jobName)
+	}
+
+	return nil
+}
+
+func deleteFilestoreMigrationJob(k8sClient *k8s.KubeClient, clusterInstallation *model.ClusterInstallation, logger log.FieldLogger) error {
+	jobName := makeFilestoreMigrationJobName(clusterInstallation)
+	propagation := metav1.DeletePropagationBackground
+
+	err := k8sClient.Clientset.BatchV1().Jobs(clusterInstallation.Namespace).Delete(context.TODO(), jobName, metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	})
+	if k8sErrors.IsNotFound(err) {
+		logger.Debugf("Filestore migration job %s not found; assuming already deleted", jobName)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete filestore migration job %s/%s", clusterInstallation.Namespace, jobName)
+	}
+
+	return nil
+}
+
+func makeFilestoreMigrationJobName(clusterInstallation *model.ClusterInstallation) string {
+	return fmt.Sprintf("%s-filestore-migration", makeClusterInstallationName(clusterInstallation))
+}
+
+func filestorePath(location *model.FilestoreLocation) string {
+	if location.Prefix == "" {
+		return location.Bucket
+	}
+
+	return fmt.Sprintf("%s/%s", location.Bucket, location.Prefix)
+}
+
+// rcloneS3RemoteEnv returns the environment variables that configure an rclone
+// remote for an AWS S3 filestore location.
+func rcloneS3RemoteEnv(remote string, location *model.FilestoreLocation, secretName string) []corev1.EnvVar {
+	prefix := fmt.Sprintf("RCLONE_CONFIG_%s_", remote)
+	secretPrefix := strings.ToLower(remote)
+
+	return []corev1.EnvVar{
+		{Name: prefix + "TYPE", Value: "s3"},
+		{Name: prefix + "PROVIDER", Value: "AWS"},
+		{Name: prefix + "REGION", Value: location.Region},
+		{Name: prefix + "ENDPOINT", Value: fmt.Sprintf("https://%s", location.Endpoint)},
+		{Name: prefix + "SERVER_SIDE_ENCRYPTION", Value: "AES256"},
+		{Name: prefix + "ACCESS_KEY_ID", ValueFrom: secretKeyRef(secretName, secretPrefix+"-access-key-id")},
+		{Name: prefix + "SECRET_ACCESS_KEY", ValueFrom: secretKeyRef(secretName, secretPrefix+"-secret-access-key")},
+	}
+}
+
+func secretKeyRef(name, key string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  key,
+		},
+	}
+}
+
+// parseFilestoreMigrationObjectCounts reads the source and target object
+// counts from the output of the filestore migration script.
+func parseFilestoreMigrationObjectCounts(logs []byte) (int64, int64, error) {
+	type rcloneSize struct {
+		Count int64 `json:"count"`
+	}
+
+	var source, target *rcloneSize
+	scanner := bufio.NewScanner(bytes.NewReader(logs))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		var size *rcloneSize
+		switch {
+		case strings.HasPrefix(line, filestoreMigrationSourceSize):
+			line = strings.TrimPrefix(line, filestoreMigrationSourceSize)
+			source = &rcloneSize{}
+			size = source
+		case strings.HasPrefix(line, filestoreMigrationTargetSize):
+			line = strings.TrimPrefix(line, filestoreMigrationTargetSize)
+			target = &rcloneSize{}
+			size = target
+		default:
+			continue
+		}
+
+		err := json.Unmarshal([]byte(line), size)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "failed to unmarshal object count")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	if source == nil || target == nil {
+		return 0, 0, errors.New("object counts were not found")
+	}
+
+	return source.Count, target.Count, nil
+}
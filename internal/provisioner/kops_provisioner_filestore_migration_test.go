@@ -0,0 +1,35 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilestoreMigrationObjectCounts(t *testing.T) {
+	t.Run("valid output", func(t *testing.T) {
+		logs := []byte(`2020/10/01 12:00:00 INFO  : file1: Copied (new)
+source-size: {"count":12,"bytes":2048}
+target-size: {"count":11,"bytes":1024}
+`)
+		source, target, err := parseFilestoreMigrationObjectCounts(logs)
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), source)
+		assert.Equal(t, int64(11), target)
+	})
+
+	t.Run("missing target count", func(t *testing.T) {
+		_, _, err := parseFilestoreMigrationObjectCounts([]byte(`source-size: {"count":12,"bytes":2048}`))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid count", func(t *testing.T) {
+		_, _, err := parseFilestoreMigrationObjectCounts([]byte("source-size: oops\ntarget-size: {}"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// mattermostServicePort is the port of the service the Mattermost operator
+// creates for a cluster installation.
+const mattermostServicePort = 8065
+
+// customDomainsIngressName returns the name of the ingress serving the custom
+// domains of a cluster installation.
+func customDomainsIngressName(clusterInstallation *model.ClusterInstallation) string {
+	return fmt.Sprintf("%s-custom-domains", makeClusterInstallationName(clusterInstallation))
+}
+
+// UpdateInstallationDomains configures the ingress serving the given custom
+// domains of a cluster installation and returns the status of the certificate
+// issued for each of them. The ingress is removed when no domains are given.
+func (provisioner *KopsProvisioner) UpdateInstallationDomains(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, domains []string) (map[string]*model.InstallationCertificate, error) {
+	if len(domains) > 0 && !provisioner.certManagerTLS {
+		return nil, errors.New("custom domains require the provisioner to issue certificates with cert-manager")
+	}
+
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	ingressName := customDomainsIngressName(clusterInstallation)
+	if len(domains) == 0 {
+		err = k8sClient.DeleteIngress(clusterInstallation.Namespace, ingressName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to delete ingress %s", ingressName)
+		}
+		logger.Debugf("Removed custom domains ingress %s", ingressName)
+
+		return map[string]*model.InstallationCertificate{}, nil
+	}
+
+	ingress := newCustomDomainsIngress(ingressName, makeClusterInstallationName(clusterInstallation), domains)
+	_, err = k8sClient.CreateOrUpdateIngress(clusterInstallation.Namespace, ingress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create or update ingress %s", ingressName)
+	}
+	logger.Debugf("Configured custom domains ingress %s with %d domains", ingressName, len(domains))
+
+	certificates := map[string]*model.InstallationCertificate{}
+	for _, domain := range domains {
+		result := &model.InstallationCertificate{
+			InstallationID: installation.ID,
+			ClusterID:      cluster.ID,
+			SecretName:     ingressTLSSecretName(domain),
+		}
+		certificate, err := k8sClient.GetCertificate(clusterInstallation.Namespace, result.SecretName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get certificate %s", result.SecretName)
+		}
+		setCertificateStatus(result, certificate)
+		certificates[domain] = result
+	}
+
+	return certificates, nil
+}
+
+// newCustomDomainsIngress returns an ingress routing the given domains to the
+// Mattermost service of a cluster installation, with a certificate issued by
+// cert-manager for each domain.
+func newCustomDomainsIngress(name, serviceName string, domains []string) *networkingv1beta1.Ingress {
+	annotations := installationIngressAnnotations()
+	annotations[certManagerIssuerAnnotation] = letsEncryptClusterIssuer
+
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: annotations,
+		},
+	}
+
+	for _, domain := range domains {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1beta1.IngressRule{
+			Host: domain,
+			IngressRuleValue: networkingv1beta1.IngressRuleValue{
+				HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+					Paths: []networkingv1beta1.HTTPIngressPath{
+						{
+							Path: "/",
+							Backend: networkingv1beta1.IngressBackend{
+								ServiceName: serviceName,
+								ServicePort: intstr.FromInt(mattermostServicePort),
+							},
+						},
+					},
+				},
+			},
+		})
+		ingress.Spec.TLS = append(ingress.Spec.TLS, networkingv1beta1.IngressTLS{
+			Hosts:      []string{domain},
+			SecretName: ingressTLSSecretName(domain),
+		})
+	}
+
+	return ingress
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomDomainsIngressName(t *testing.T) {
+	assert.Equal(t, "mm-abcd-custom-domains", customDomainsIngressName(&model.ClusterInstallation{Namespace: "abcdefgh"}))
+}
+
+func TestNewCustomDomainsIngress(t *testing.T) {
+	ingress := newCustomDomainsIngress("mm-abcd-custom-domains", "mm-abcd", []string{"chat.example.com", "chat.example.org"})
+
+	assert.Equal(t, "mm-abcd-custom-domains", ingress.GetName())
+	assert.Equal(t, letsEncryptClusterIssuer, ingress.Annotations[certManagerIssuerAnnotation])
+	assert.Equal(t, "nginx-controller", ingress.Annotations["kubernetes.io/ingress.class"])
+
+	require.Len(t, ingress.Spec.Rules, 2)
+	assert.Equal(t, "chat.example.org", ingress.Spec.Rules[1].Host)
+	assert.Equal(t, "mm-abcd", ingress.Spec.Rules[1].HTTP.Paths[0].Backend.ServiceName)
+	assert.Equal(t, int32(mattermostServicePort), ingress.Spec.Rules[1].HTTP.Paths[0].Backend.ServicePort.IntVal)
+
+	require.Len(t, ingress.Spec.TLS, 2)
+	assert.Equal(t, []string{"chat.example.com"}, ingress.Spec.TLS[0].Hosts)
+	assert.Equal(t, "chat-example-com-tls-cert", ingress.Spec.TLS[0].SecretName)
+}
+
+func TestUpdateInstallationDomainsWithoutCertManager(t *testing.T) {
+	provisioner := &KopsProvisioner{}
+
+	_, err := provisioner.UpdateInstallationDomains(&model.Cluster{}, &model.Installation{}, &model.ClusterInstallation{}, []string{"chat.example.com"})
+	require.Error(t, err)
+}
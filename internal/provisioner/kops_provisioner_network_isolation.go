@@ -0,0 +1,155 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// restrictedEgressNetworkPolicyName is the name of the network policy
+// restricting the outbound traffic of an installation.
+const restrictedEgressNetworkPolicyName = "restricted-egress"
+
+// privateNetworkCIDRs are the private address ranges allowed to reach the
+// databases when the VPC of the cluster is not known, as for clusters that
+// did not claim their VPC.
+var privateNetworkCIDRs = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+// privateNetworkPorts are the ports of the MySQL and Postgres databases.
+var privateNetworkPorts = []int32{3306, 5432}
+
+// s3Ports are the ports of the regional S3 endpoints.
+var s3Ports = []int32{443}
+
+// ensureNetworkIsolation applies the network isolation profile of an
+// installation to its namespace. The policies shared by all installations
+// are created from the installation network policy manifest. Restricted
+// installations are refused on clusters not enforcing network policies and
+// reach their filestore through the regional S3 endpoints in s3CIDRs.
+func ensureNetworkIsolation(k8sClient *k8s.KubeClient, cluster *model.Cluster, namespace string, isolation *model.NetworkIsolation, s3CIDRs []string) error {
+	if !isolation.IsRestricted() {
+		err := k8sClient.DeleteNetworkPolicy(namespace, restrictedEgressNetworkPolicyName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete network policy %s", restrictedEgressNetworkPolicyName)
+		}
+		return nil
+	}
+
+	if cluster.Provisioner == model.ProvisionerEKS {
+		return errors.New("the network policies of a restricted installation are not enforced on EKS clusters")
+	}
+
+	_, err := k8sClient.CreateOrUpdateNetworkPolicy(namespace, newRestrictedEgressNetworkPolicy(clusterNetworkCIDRs(cluster), s3CIDRs, isolation))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create network policy %s", restrictedEgressNetworkPolicyName)
+	}
+
+	return nil
+}
+
+// newRestrictedEgressNetworkPolicy returns the network policy allowing the
+// pods of an installation to only reach DNS, the pods of the installation
+// namespace, the databases in the given VPC CIDRs, the given S3 endpoints and
+// the egress allow-list of the installation.
+func newRestrictedEgressNetworkPolicy(vpcCIDRs, s3CIDRs []string, isolation *model.NetworkIsolation) *networkingv1.NetworkPolicy {
+	egress := []networkingv1.NetworkPolicyEgressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				newNetworkPolicyPort(corev1.ProtocolUDP, 53),
+				newNetworkPolicyPort(corev1.ProtocolTCP, 53),
+			},
+		},
+		{
+			To: []networkingv1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{}},
+			},
+		},
+		newEgressRule(vpcCIDRs, corev1.ProtocolTCP, privateNetworkPorts),
+		newEgressRule(s3CIDRs, corev1.ProtocolTCP, s3Ports),
+	}
+	for _, rule := range isolation.EgressAllowList {
+		egress = append(egress, newEgressRule([]string{rule.CIDR}, corev1.Protocol(rule.Protocol), rule.Ports))
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: restrictedEgressNetworkPolicyName,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+}
+
+// getS3CIDRs returns the CIDR blocks of the regional S3 endpoints of a
+// cluster when the installation is restricted to them.
+func getS3CIDRs(resourceUtil *utils.ResourceUtil, cluster *model.Cluster, isolation *model.NetworkIsolation, logger log.FieldLogger) ([]string, error) {
+	if !isolation.IsRestricted() {
+		return nil, nil
+	}
+
+	clusterResourceUtil, err := resourceUtil.ForCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+	awsClient := clusterResourceUtil.GetAWSClient()
+	if awsClient == nil {
+		return nil, errors.New("restricting installation egress requires an AWS client")
+	}
+
+	cidrs, err := awsClient.GetS3PrefixListCIDRs(logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get S3 endpoint CIDRs")
+	}
+
+	return cidrs, nil
+}
+
+// clusterNetworkCIDRs returns the CIDR blocks of the VPC of a cluster, or
+// the private address ranges when they are unknown.
+func clusterNetworkCIDRs(cluster *model.Cluster) []string {
+	if cluster.ProvisionerMetadataKops != nil && len(cluster.ProvisionerMetadataKops.VPCCIDRs) > 0 {
+		return cluster.ProvisionerMetadataKops.VPCCIDRs
+	}
+	if cluster.ProvisionerMetadataKops != nil && cluster.ProvisionerMetadataKops.ManagedVPC {
+		return []string{clusterVPCCIDR(cluster)}
+	}
+
+	return privateNetworkCIDRs
+}
+
+// newEgressRule returns an egress rule to the given CIDRs on the given ports
+// or on all ports if none are given.
+func newEgressRule(cidrs []string, protocol corev1.Protocol, ports []int32) networkingv1.NetworkPolicyEgressRule {
+	rule := networkingv1.NetworkPolicyEgressRule{}
+	for _, cidr := range cidrs {
+		rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+		})
+	}
+	for _, port := range ports {
+		rule.Ports = append(rule.Ports, newNetworkPolicyPort(protocol, port))
+	}
+
+	return rule
+}
+
+func newNetworkPolicyPort(protocol corev1.Protocol, port int32) networkingv1.NetworkPolicyPort {
+	portValue := intstr.FromInt(int(port))
+	return networkingv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &portValue,
+	}
+}
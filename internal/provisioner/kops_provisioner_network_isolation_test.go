@@ -0,0 +1,98 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func TestNewRestrictedEgressNetworkPolicy(t *testing.T) {
+	networkPolicy := newRestrictedEgressNetworkPolicy([]string{"10.10.0.0/16"}, []string{"3.5.0.0/19", "52.216.0.0/15"}, &model.NetworkIsolation{
+		Profile: model.NetworkIsolationProfileRestricted,
+		EgressAllowList: []model.NetworkEgressRule{
+			{CIDR: "203.0.113.0/24", Protocol: "TCP", Ports: []int32{443, 8443}},
+			{CIDR: "198.51.100.10/32", Protocol: "UDP"},
+		},
+	})
+
+	assert.Equal(t, restrictedEgressNetworkPolicyName, networkPolicy.GetName())
+	assert.Empty(t, networkPolicy.Spec.PodSelector.MatchLabels)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}, networkPolicy.Spec.PolicyTypes)
+	require.Len(t, networkPolicy.Spec.Egress, 6)
+
+	t.Run("dns", func(t *testing.T) {
+		rule := networkPolicy.Spec.Egress[0]
+		assert.Empty(t, rule.To)
+		require.Len(t, rule.Ports, 2)
+		assert.Equal(t, corev1.ProtocolUDP, *rule.Ports[0].Protocol)
+		assert.Equal(t, 53, rule.Ports[0].Port.IntValue())
+	})
+
+	t.Run("installation namespace", func(t *testing.T) {
+		rule := networkPolicy.Spec.Egress[1]
+		require.Len(t, rule.To, 1)
+		assert.NotNil(t, rule.To[0].PodSelector)
+		assert.Empty(t, rule.Ports)
+	})
+
+	t.Run("vpc", func(t *testing.T) {
+		rule := networkPolicy.Spec.Egress[2]
+		require.Len(t, rule.To, 1)
+		assert.Equal(t, "10.10.0.0/16", rule.To[0].IPBlock.CIDR)
+		require.Len(t, rule.Ports, len(privateNetworkPorts))
+		assert.Equal(t, 3306, rule.Ports[0].Port.IntValue())
+	})
+
+	t.Run("s3", func(t *testing.T) {
+		rule := networkPolicy.Spec.Egress[3]
+		require.Len(t, rule.To, 2)
+		assert.Equal(t, "3.5.0.0/19", rule.To[0].IPBlock.CIDR)
+		assert.Equal(t, "52.216.0.0/15", rule.To[1].IPBlock.CIDR)
+		require.Len(t, rule.Ports, 1)
+		assert.Equal(t, 443, rule.Ports[0].Port.IntValue())
+	})
+
+	t.Run("allow-list", func(t *testing.T) {
+		rule := networkPolicy.Spec.Egress[4]
+		require.Len(t, rule.To, 1)
+		assert.Equal(t, "203.0.113.0/24", rule.To[0].IPBlock.CIDR)
+		require.Len(t, rule.Ports, 2)
+		assert.Equal(t, corev1.ProtocolTCP, *rule.Ports[1].Protocol)
+		assert.Equal(t, 8443, rule.Ports[1].Port.IntValue())
+
+		rule = networkPolicy.Spec.Egress[5]
+		assert.Equal(t, "198.51.100.10/32", rule.To[0].IPBlock.CIDR)
+		assert.Empty(t, rule.Ports)
+	})
+}
+
+func TestClusterNetworkCIDRs(t *testing.T) {
+	t.Run("claimed vpc", func(t *testing.T) {
+		cluster := &model.Cluster{ProvisionerMetadataKops: &model.KopsMetadata{
+			VPCCIDRs: []string{"10.10.0.0/16", "100.64.0.0/16"},
+		}}
+		assert.Equal(t, []string{"10.10.0.0/16", "100.64.0.0/16"}, clusterNetworkCIDRs(cluster))
+	})
+
+	t.Run("managed vpc", func(t *testing.T) {
+		cluster := &model.Cluster{ProvisionerMetadataKops: &model.KopsMetadata{
+			ManagedVPC:     true,
+			ManagedVPCCIDR: "172.20.0.0/16",
+		}}
+		assert.Equal(t, []string{"172.20.0.0/16"}, clusterNetworkCIDRs(cluster))
+	})
+
+	t.Run("unknown vpc", func(t *testing.T) {
+		cluster := &model.Cluster{ProvisionerMetadataKops: &model.KopsMetadata{}}
+		assert.Equal(t, privateNetworkCIDRs, clusterNetworkCIDRs(cluster))
+		assert.Equal(t, privateNetworkCIDRs, clusterNetworkCIDRs(&model.Cluster{}))
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	installationResourceQuotaName = "installation-quota"
+	installationLimitRangeName    = "installation-limits"
+)
+
+// limitRangeDefaultRequests are the requests of containers of an installation
+// that don't set their own, so that they are admitted by the resource quota.
+// No default limits are set: the app, database and filestore take their
+// limits from their own size, and a limit shared by every container would
+// fall below the requests of the larger components.
+var limitRangeDefaultRequests = corev1.ResourceList{
+	corev1.ResourceCPU:    resource.MustParse("50m"),
+	corev1.ResourceMemory: resource.MustParse("64Mi"),
+}
+
+// ensureResourceQuota creates or updates the resource quota and limit range
+// of an installation namespace to match the installation size and quota
+// overrides.
+func ensureResourceQuota(k8sClient *k8s.KubeClient, namespace string, installation *model.Installation) error {
+	resourceQuota, limitRange, err := newInstallationResourceQuota(installation)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate resource quota")
+	}
+
+	_, err = k8sClient.CreateOrUpdateResourceQuota(namespace, resourceQuota)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create resource quota %s", installationResourceQuotaName)
+	}
+	_, err = k8sClient.CreateOrUpdateLimitRange(namespace, limitRange)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create limit range %s", installationLimitRangeName)
+	}
+
+	return nil
+}
+
+// newInstallationResourceQuota returns the resource quota limiting the total
+// requests of an installation namespace and the limit range setting the
+// default requests of its containers.
+func newInstallationResourceQuota(installation *model.Installation) (*corev1.ResourceQuota, *corev1.LimitRange, error) {
+	cpu, memory, err := installation.CalculateResourceQuotaMilliRequests()
+	if err != nil {
+		return nil, nil, err
+	}
+	resourceQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: installationResourceQuotaName,
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{
+				corev1.ResourceRequestsCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+				corev1.ResourceRequestsMemory: *resource.NewQuantity(memory/1000, resource.BinarySI),
+			},
+		},
+	}
+
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: installationLimitRangeName,
+		},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type:           corev1.LimitTypeContainer,
+					DefaultRequest: limitRangeDefaultRequests,
+				},
+			},
+		},
+	}
+
+	return resourceQuota, limitRange, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewInstallationResourceQuota(t *testing.T) {
+	installation := &model.Installation{
+		Size:          mmv1alpha1.Size100String,
+		Database:      model.InstallationDatabaseMultiTenantRDSPostgres,
+		Filestore:     model.InstallationFilestoreMultiTenantAwsS3,
+		ResourceQuota: &model.InstallationResourceQuota{Memory: "2Gi"},
+	}
+
+	resourceQuota, limitRange, err := newInstallationResourceQuota(installation)
+	require.NoError(t, err)
+
+	t.Run("resource quota", func(t *testing.T) {
+		assert.Equal(t, installationResourceQuotaName, resourceQuota.GetName())
+		cpu := resourceQuota.Spec.Hard[corev1.ResourceRequestsCPU]
+		assert.Equal(t, "240m", cpu.String())
+		memory := resourceQuota.Spec.Hard[corev1.ResourceRequestsMemory]
+		assert.Equal(t, "2Gi", memory.String())
+	})
+
+	t.Run("limit range", func(t *testing.T) {
+		assert.Equal(t, installationLimitRangeName, limitRange.GetName())
+		require.Len(t, limitRange.Spec.Limits, 1)
+		limits := limitRange.Spec.Limits[0]
+		assert.Equal(t, corev1.LimitTypeContainer, limits.Type)
+		assert.Empty(t, limits.Default)
+		assert.Equal(t, "50m", limits.DefaultRequest.Cpu().String())
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		_, _, err := newInstallationResourceQuota(&model.Installation{Size: "invalid"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// kubeletStatsSummary is the subset of the kubelet stats summary response
+// that is needed to measure persistent volume usage.
+type kubeletStatsSummary struct {
+	Pods []struct {
+		Volumes []struct {
+			UsedBytes *int64 `json:"usedBytes"`
+			PVCRef    *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// GetClusterInstallationVolumeUsage returns the storage used by the persistent
+// volumes of the in-cluster database and filestore of a cluster installation.
+func (provisioner *KopsProvisioner) GetClusterInstallationVolumeUsage(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (*model.ClusterInstallationVolumeUsage, error) {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"cluster":      clusterInstallation.ClusterID,
+		"installation": clusterInstallation.InstallationID,
+	})
+
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.TODO()
+	pods, err := k8sClient.Clientset.CoreV1().Pods(clusterInstallation.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list cluster installation pods")
+	}
+
+	nodes := map[string]bool{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			nodes[pod.Spec.NodeName] = true
+		}
+	}
+
+	usage := &model.ClusterInstallationVolumeUsage{}
+	for node := range nodes {
+		summary, err := k8sClient.Clientset.CoreV1().RESTClient().Get().
+			Resource("nodes").
+			Name(node).
+			SubResource("proxy").
+			Suffix("stats/summary").
+			DoRaw(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get stats summary of node %s", node)
+		}
+
+		err = addVolumeUsageFromStatsSummary(usage, summary, clusterInstallation.Namespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse stats summary of node %s", node)
+		}
+	}
+
+	return usage, nil
+}
+
+// addVolumeUsageFromStatsSummary adds the usage of the persistent volumes in
+// the given namespace to the cluster installation usage. Volume claims are
+// attributed to the database or filestore based on the operator naming.
+func addVolumeUsageFromStatsSummary(usage *model.ClusterInstallationVolumeUsage, summary []byte, namespace string) error {
+	var stats kubeletStatsSummary
+	err := json.Unmarshal(summary, &stats)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range stats.Pods {
+		for _, volume := range pod.Volumes {
+			if volume.PVCRef == nil || volume.UsedBytes == nil || volume.PVCRef.Namespace != namespace {
+				continue
+			}
+
+			switch {
+			case strings.Contains(volume.PVCRef.Name, "mysql"):
+				usage.DatabaseBytes += *volume.UsedBytes
+			case strings.Contains(volume.PVCRef.Name, "minio"):
+				usage.FilestoreBytes += *volume.UsedBytes
+			}
+		}
+	}
+
+	return nil
+}
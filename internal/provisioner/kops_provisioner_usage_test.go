@@ -0,0 +1,45 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddVolumeUsageFromStatsSummary(t *testing.T) {
+	summary := []byte(`{
+	"pods": [
+		{"volume": [
+			{"usedBytes": 100, "pvcRef": {"name": "data-db-mysql-0", "namespace": "ns1"}},
+			{"usedBytes": 7, "name": "default-token"}
+		]},
+		{"volume": [
+			{"usedBytes": 200, "pvcRef": {"name": "export-minio-0", "namespace": "ns1"}},
+			{"usedBytes": 300, "pvcRef": {"name": "export-minio-0", "namespace": "ns2"}}
+		]}
+	]
+}`)
+
+	t.Run("valid summary", func(t *testing.T) {
+		usage := &model.ClusterInstallationVolumeUsage{}
+		err := addVolumeUsageFromStatsSummary(usage, summary, "ns1")
+		require.NoError(t, err)
+		assert.Equal(t, &model.ClusterInstallationVolumeUsage{DatabaseBytes: 100, FilestoreBytes: 200}, usage)
+
+		err = addVolumeUsageFromStatsSummary(usage, summary, "ns1")
+		require.NoError(t, err)
+		assert.Equal(t, &model.ClusterInstallationVolumeUsage{DatabaseBytes: 200, FilestoreBytes: 400}, usage)
+	})
+
+	t.Run("invalid summary", func(t *testing.T) {
+		usage := &model.ClusterInstallationVolumeUsage{}
+		err := addVolumeUsageFromStatsSummary(usage, []byte("{oops"), "ns1")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/internal/tools/terraform"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// newVPCModule returns the terraform module of the managed VPC of a cluster.
+func (provisioner *KopsProvisioner) newVPCModule(cluster *model.Cluster) *terraform.VPCModule {
+	return &terraform.VPCModule{
+		ClusterID:     cluster.ID,
+		KopsName:      cluster.ProvisionerMetadataKops.Name,
+		Owner:         provisioner.owner,
+		Region:        clusterAWSRegion(cluster),
+		AssumeRoleARN: cluster.AWSAssumeRoleARN(),
+		CIDR:          clusterVPCCIDR(cluster),
+		Zones:         cluster.ProviderMetadataAWS.Zones,
+	}
+}
+
+// clusterVPCCIDR returns the CIDR block of the managed VPC of a cluster.
+// Clusters created before the CIDR was configurable use the default one.
+func clusterVPCCIDR(cluster *model.Cluster) string {
+	if len(cluster.ProvisionerMetadataKops.ManagedVPCCIDR) == 0 {
+		return terraform.DefaultVPCCIDR
+	}
+
+	return cluster.ProvisionerMetadataKops.ManagedVPCCIDR
+}
+
+// withVPCTerraform runs the given function with a terraform client whose
+// working directory holds the initialized VPC module of the cluster.
+func (provisioner *KopsProvisioner) withVPCTerraform(cluster *model.Cluster, logger log.FieldLogger, run func(terraformClient *terraform.Cmd) error) error {
+	dir, err := ioutil.TempDir("", "vpc-"+cluster.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to create VPC module directory")
+	}
+	defer os.RemoveAll(dir)
+
+	terraformClient, err := terraform.New(dir, provisioner.s3StateStore, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create terraform wrapper")
+	}
+	defer terraformClient.Close()
+
+	module := provisioner.newVPCModule(cluster)
+	err = terraformClient.WriteVPCModule(module)
+	if err != nil {
+		return err
+	}
+
+	err = terraformClient.Init(module.RemoteKey())
+	if err != nil {
+		return errors.Wrap(err, "failed to init terraform")
+	}
+
+	return run(terraformClient)
+}
+
+// createClusterVPC creates the managed VPC of a cluster. The VPC is tagged as
+// claimed by the cluster so that its resources are returned when claiming.
+func (provisioner *KopsProvisioner) createClusterVPC(cluster *model.Cluster, logger log.FieldLogger) error {
+	logger.Info("Creating cluster VPC")
+
+	return provisioner.withVPCTerraform(cluster, logger, func(terraformClient *terraform.Cmd) error {
+		err := terraformClient.Apply()
+		if err != nil {
+			return err
+		}
+
+		vpcID, ok, err := terraformClient.Output("vpc_id")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("terraform output is missing the VPC ID")
+		}
+
+		logger.WithField("vpc", vpcID).Info("Created cluster VPC")
+
+		return nil
+	})
+}
+
+// ensureClusterVPCUnused returns an error if RDS DB clusters, such as the
+// multitenant databases, still run in the managed VPC of a cluster. They use
+// the DB subnet group and security groups of the VPC, which would otherwise
+// block its destruction after the cluster is already gone.
+func (provisioner *KopsProvisioner) ensureClusterVPCUnused(cluster *model.Cluster, awsClient aws.AWS, logger log.FieldLogger) error {
+	dbClusterIDs, err := awsClient.GetVpcDBClusterIDs(cluster.ID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to look up RDS DB clusters in cluster VPC")
+	}
+	if len(dbClusterIDs) != 0 {
+		return errors.Errorf("cluster VPC still hosts RDS DB clusters %s, which must be deleted first", strings.Join(dbClusterIDs, ", "))
+	}
+
+	return nil
+}
+
+// destroyClusterVPC destroys the managed VPC of a cluster.
+func (provisioner *KopsProvisioner) destroyClusterVPC(cluster *model.Cluster, logger log.FieldLogger) error {
+	logger.Info("Destroying cluster VPC")
+
+	return provisioner.withVPCTerraform(cluster, logger, func(terraformClient *terraform.Cmd) error {
+		return terraformClient.Destroy()
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/mattermost/mattermost-cloud/internal/mocks/aws-tools"
+	"github.com/mattermost/mattermost-cloud/internal/tools/terraform"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterVPCCIDR(t *testing.T) {
+	cluster := &model.Cluster{ProvisionerMetadataKops: &model.KopsMetadata{ManagedVPC: true}}
+	assert.Equal(t, terraform.DefaultVPCCIDR, clusterVPCCIDR(cluster))
+
+	cluster.ProvisionerMetadataKops.ManagedVPCCIDR = "172.20.0.0/16"
+	assert.Equal(t, "172.20.0.0/16", clusterVPCCIDR(cluster))
+}
+
+func TestEnsureClusterVPCUnused(t *testing.T) {
+	logger := log.New()
+	provisioner := &KopsProvisioner{}
+	cluster := &model.Cluster{ID: "cluster1"}
+
+	t.Run("unused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		awsClient := mocks.NewMockAWS(ctrl)
+		awsClient.EXPECT().GetVpcDBClusterIDs("cluster1", gomock.Any()).Return([]string{}, nil)
+
+		require.NoError(t, provisioner.ensureClusterVPCUnused(cluster, awsClient, logger))
+	})
+
+	t.Run("hosts databases", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		awsClient := mocks.NewMockAWS(ctrl)
+		awsClient.EXPECT().GetVpcDBClusterIDs("cluster1", gomock.Any()).Return([]string{"rds-cluster-multitenant-1"}, nil)
+
+		err := provisioner.ensureClusterVPCUnused(cluster, awsClient, logger)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rds-cluster-multitenant-1")
+	})
+}
@@ -128,18 +128,7 @@ func (provisioner *KopsProvisioner) GetPublicLoadBalancerEndpoint(cluster *model
 		"cluster":         cluster.ID,
 		"nginx-namespace": namespace,
 	})
-	kops, err := kops.New(provisioner.s3StateStore, logger)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create kops wrapper")
-	}
-	defer kops.Close()
-
-	err = kops.ExportKubecfg(cluster.ProvisionerMetadataKops.Name)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to export kubecfg")
-	}
-
-	k8sClient, err := k8s.NewFromFile(kops.GetKubeConfigPath(), logger)
+	k8sClient, err := provisioner.getClusterKubeClient(cluster, logger)
 	if err != nil {
 		return "", err
 	}
@@ -164,6 +153,30 @@ func (provisioner *KopsProvisioner) GetPublicLoadBalancerEndpoint(cluster *model
 	return "", errors.New("failed to get NGINX load balancer endpoint")
 }
 
+// updateKopsKubernetesVersion sets the kubernetes version of the change
+// request in the kops cluster spec.
+func updateKopsKubernetesVersion(kopsClient *kops.Cmd, kopsMetadata *model.KopsMetadata, logger log.FieldLogger) error {
+	switch kopsMetadata.ChangeRequest.Version {
+	case "":
+		logger.Info("Skipping kubernetes cluster version update")
+	case "latest":
+		logger.Info("Updating kubernetes to latest stable version")
+		err := kopsClient.UpgradeCluster(kopsMetadata.Name)
+		if err != nil {
+			return err
+		}
+	default:
+		logger.Infof("Updating kubernetes to version %s", kopsMetadata.ChangeRequest.Version)
+		setValue := fmt.Sprintf("spec.kubernetesVersion=%s", kopsMetadata.ChangeRequest.Version)
+		err := kopsClient.SetCluster(kopsMetadata.Name, setValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func updateKopsInstanceGroupAMIs(kops *kops.Cmd, kopsMetadata *model.KopsMetadata, logger log.FieldLogger) error {
 	if len(kopsMetadata.ChangeRequest.AMI) == 0 {
 		logger.Info("Skipping cluster AMI update")
@@ -212,6 +225,126 @@ func updateKopsInstanceGroupAMIs(kops *kops.Cmd, kopsMetadata *model.KopsMetadat
 	return nil
 }
 
+// defaultNodeInstanceGroupName is the name of the default worker instance
+// group of a kops cluster.
+const defaultNodeInstanceGroupName = kops.DefaultNodeInstanceGroupName
+
+// resizeKopsInstanceGroup updates the machine type and size of an existing
+// kops instance group.
+func resizeKopsInstanceGroup(kopsClient *kops.Cmd, clusterName, igName, machineType string, min, max int64) error {
+	igManifest, err := kopsClient.GetInstanceGroupYAML(clusterName, igName)
+	if err != nil {
+		return err
+	}
+
+	igManifest, err = grossKopsReplaceSize(
+		igManifest,
+		machineType,
+		fmt.Sprintf("%d", min),
+		fmt.Sprintf("%d", max),
+	)
+	if err != nil {
+		return err
+	}
+
+	igFilename := fmt.Sprintf("ig-%s.yaml", igName)
+	err = ioutil.WriteFile(path.Join(kopsClient.GetTempDir(), igFilename), []byte(igManifest), 0600)
+	if err != nil {
+		return err
+	}
+	_, err = kopsClient.Replace(igFilename)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resizeKopsNodes applies the worker node changes of the change request to
+// the default node instance group and the additional node groups.
+func resizeKopsNodes(kopsClient *kops.Cmd, clusterName string, changeRequest *model.KopsMetadataRequestedState, autoscaling bool, logger log.FieldLogger) error {
+	if len(changeRequest.NodeInstanceType) != 0 || changeRequest.NodeMinCount != 0 || changeRequest.NodeMaxCount != 0 {
+		err := resizeKopsInstanceGroup(kopsClient, clusterName, defaultNodeInstanceGroupName,
+			changeRequest.NodeInstanceType, changeRequest.NodeMinCount, changeRequest.NodeMaxCount)
+		if err != nil {
+			return err
+		}
+	}
+
+	if changeRequest.NodeMixedInstancesPolicy != nil {
+		logger.Info("Updating node instance group mixed instances policy")
+		err := kopsClient.SetInstanceGroupMixedInstancesPolicy(clusterName, defaultNodeInstanceGroupName, changeRequest.NodeMixedInstancesPolicy)
+		if err != nil {
+			return errors.Wrap(err, "failed to update node mixed instances policy")
+		}
+	}
+
+	err := updateKopsNodeGroups(kopsClient, clusterName, changeRequest, autoscaling, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to update node groups")
+	}
+
+	return nil
+}
+
+// updateKopsNodeGroups creates, resizes and deletes the additional node groups
+// of a kops cluster as described by the change request. On autoscaling
+// clusters, node groups with a max count above their min count are scaled by
+// the cluster-autoscaler.
+func updateKopsNodeGroups(kopsClient *kops.Cmd, clusterName string, changeRequest *model.KopsMetadataRequestedState, autoscaling bool, logger log.FieldLogger) error {
+	if changeRequest == nil || (len(changeRequest.NodeGroups) == 0 && len(changeRequest.DeleteNodeGroups) == 0) {
+		return nil
+	}
+
+	instanceGroups, err := kopsClient.GetInstanceGroupsJSON(clusterName)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, ig := range instanceGroups {
+		existing[ig.Metadata.Name] = true
+	}
+
+	for _, nodeGroup := range changeRequest.NodeGroups {
+		if existing[nodeGroup.Name] {
+			logger.Infof("Resizing node group %s", nodeGroup.Name)
+			err = resizeKopsInstanceGroup(kopsClient, clusterName, nodeGroup.Name, nodeGroup.InstanceType, nodeGroup.MinCount, nodeGroup.MaxCount)
+			if err != nil {
+				return errors.Wrapf(err, "failed to resize node group %s", nodeGroup.Name)
+			}
+		} else {
+			logger.Infof("Creating node group %s", nodeGroup.Name)
+			err = kopsClient.CreateNodeInstanceGroup(clusterName, defaultNodeInstanceGroupName, nodeGroup)
+			if err != nil {
+				return errors.Wrapf(err, "failed to create node group %s", nodeGroup.Name)
+			}
+		}
+
+		if autoscaling && nodeGroup.CanScale() {
+			logger.Infof("Enabling node group %s autoscaling", nodeGroup.Name)
+			err = kopsClient.SetInstanceGroupAutoscaling(clusterName, nodeGroup.Name, nodeGroup.MaxCount)
+			if err != nil {
+				return errors.Wrapf(err, "failed to enable node group %s autoscaling", nodeGroup.Name)
+			}
+		}
+	}
+
+	for _, name := range changeRequest.DeleteNodeGroups {
+		if !existing[name] {
+			logger.Debugf("Node group %s was already deleted", name)
+			continue
+		}
+
+		logger.Infof("Deleting node group %s", name)
+		err = kopsClient.DeleteInstanceGroup(clusterName, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete node group %s", name)
+		}
+	}
+
+	return nil
+}
+
 // grossKopsReplaceSize is a manual find-and-replace flow for updating a raw
 // kops instance group YAML manifest with new sizing values.
 // TODO: remove once new `kops set instancegroup` functionality is available.
@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -18,17 +17,22 @@ import (
 type nginx struct {
 	awsClient      aws.AWS
 	provisioner    *KopsProvisioner
-	kops           *kops.Cmd
+	kubeconfig     kubeconfigProvider
+	cluster        *model.Cluster
 	logger         log.FieldLogger
 	desiredVersion string
 	actualVersion  string
 }
 
-func newNginxHandle(desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*nginx, error) {
+func newNginxHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*nginx, error) {
 	if logger == nil {
 		return nil, errors.New("cannot instantiate NGINX handle with nil logger")
 	}
 
+	if cluster == nil {
+		return nil, errors.New("cannot create a connection to Nginx if the cluster provided is nil")
+	}
+
 	if provisioner == nil {
 		return nil, errors.New("cannot create a connection to Nginx if the provisioner provided is nil")
 	}
@@ -37,14 +41,15 @@ func newNginxHandle(desiredVersion string, provisioner *KopsProvisioner, awsClie
 		return nil, errors.New("cannot create a connection to Nginx if the awsClient provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Nginx if the Kops command provided is nil")
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to Nginx if the kubeconfig provider is nil")
 	}
 
 	return &nginx{
 		awsClient:      awsClient,
 		provisioner:    provisioner,
-		kops:           kops,
+		kubeconfig:     kubeconfig,
+		cluster:        cluster,
 		logger:         logger.WithField("cluster-utility", model.NginxCanonicalName),
 		desiredVersion: desiredVersion,
 	}, nil
@@ -98,6 +103,12 @@ func (n *nginx) Migrate() error {
 }
 
 func (n *nginx) NewHelmDeployment() (*helmDeployment, error) {
+	if n.provisioner.certManagerTLS {
+		// The load balancers pass TLS through so that NGINX serves the
+		// certificates issued for each installation ingress.
+		return n.newHelmDeployment("controller.service.targetPorts.https=https"), nil
+	}
+
 	awsACMCert, err := n.awsClient.GetCertificateSummaryByTag(aws.DefaultInstallCertificatesTagKey, aws.DefaultInstallCertificatesTagValue, n.logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to retrive the AWS ACM")
@@ -108,17 +119,22 @@ func (n *nginx) NewHelmDeployment() (*helmDeployment, error) {
 		return nil, errors.Wrap(err, "failed to retrive the AWS Private ACM")
 	}
 
+	return n.newHelmDeployment(fmt.Sprintf("controller.service.annotations.service\\.beta\\.kubernetes\\.io/aws-load-balancer-ssl-cert=%s,controller.service.internal.annotations.service\\.beta\\.kubernetes\\.io/aws-load-balancer-ssl-cert=%s", *awsACMCert.CertificateArn, *awsACMPrivateCert.CertificateArn)), nil
+}
+
+func (n *nginx) newHelmDeployment(setArgument string) *helmDeployment {
 	return &helmDeployment{
 		chartDeploymentName: "nginx",
 		chartName:           "ingress-nginx/ingress-nginx",
 		namespace:           "nginx",
-		setArgument:         fmt.Sprintf("controller.service.annotations.service\\.beta\\.kubernetes\\.io/aws-load-balancer-ssl-cert=%s,controller.service.internal.annotations.service\\.beta\\.kubernetes\\.io/aws-load-balancer-ssl-cert=%s", *awsACMCert.CertificateArn, *awsACMPrivateCert.CertificateArn),
+		setArgument:         setArgument,
 		valuesPath:          "helm-charts/nginx_values.yaml",
+		valuesOverride:      n.cluster.UtilityValuesOverride(model.NginxCanonicalName),
 		kopsProvisioner:     n.provisioner,
-		kops:                n.kops,
+		kubeconfig:          n.kubeconfig,
 		logger:              n.logger,
 		desiredVersion:      n.desiredVersion,
-	}, nil
+	}
 }
 
 func (n *nginx) Name() string {
@@ -0,0 +1,109 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type nodeTerminationHandler struct {
+	provisioner    *KopsProvisioner
+	kubeconfig     kubeconfigProvider
+	cluster        *model.Cluster
+	logger         log.FieldLogger
+	desiredVersion string
+	actualVersion  string
+}
+
+func newNodeTerminationHandlerHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*nodeTerminationHandler, error) {
+	if logger == nil {
+		return nil, errors.New("cannot instantiate aws-node-termination-handler handle with nil logger")
+	}
+
+	if provisioner == nil {
+		return nil, errors.New("cannot create a connection to aws-node-termination-handler if the provisioner provided is nil")
+	}
+
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to aws-node-termination-handler if the kubeconfig provider is nil")
+	}
+
+	return &nodeTerminationHandler{
+		provisioner:    provisioner,
+		kubeconfig:     kubeconfig,
+		cluster:        cluster,
+		logger:         logger.WithField("cluster-utility", model.NodeTerminationHandlerCanonicalName),
+		desiredVersion: desiredVersion,
+	}, nil
+}
+
+func (n *nodeTerminationHandler) updateVersion(h *helmDeployment) error {
+	actualVersion, err := h.Version()
+	if err != nil {
+		return err
+	}
+
+	n.actualVersion = actualVersion
+	return nil
+}
+
+func (n *nodeTerminationHandler) CreateOrUpgrade() error {
+	h := n.NewHelmDeployment()
+
+	err := h.TryMigrate()
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate aws-node-termination-handler release")
+	}
+
+	err = h.Update()
+	if err != nil {
+		return err
+	}
+
+	err = n.updateVersion(h)
+	return err
+}
+
+func (n *nodeTerminationHandler) DesiredVersion() string {
+	return n.desiredVersion
+}
+
+func (n *nodeTerminationHandler) ActualVersion() string {
+	return strings.TrimPrefix(n.actualVersion, "aws-node-termination-handler-")
+}
+
+func (n *nodeTerminationHandler) Destroy() error {
+	return nil
+}
+
+func (n *nodeTerminationHandler) Migrate() error {
+	return nil
+}
+
+// NewHelmDeployment returns the aws-node-termination-handler deployment. The
+// handler runs on every node and cordons and drains a node as soon as AWS
+// announces a spot interruption or a scheduled event for its instance.
+func (n *nodeTerminationHandler) NewHelmDeployment() *helmDeployment {
+	return &helmDeployment{
+		chartDeploymentName: "aws-node-termination-handler",
+		chartName:           "eks/aws-node-termination-handler",
+		namespace:           "kube-system",
+		setArgument:         "enableSpotInterruptionDraining=true,enableScheduledEventDraining=true",
+		valuesPath:          "helm-charts/aws-node-termination-handler_values.yaml",
+		valuesOverride:      n.cluster.UtilityValuesOverride(model.NodeTerminationHandlerCanonicalName),
+		kopsProvisioner:     n.provisioner,
+		kubeconfig:          n.kubeconfig,
+		logger:              n.logger,
+		desiredVersion:      n.desiredVersion,
+	}
+}
+
+func (n *nodeTerminationHandler) Name() string {
+	return model.NodeTerminationHandlerCanonicalName
+}
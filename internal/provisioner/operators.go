@@ -0,0 +1,123 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// provisionClusterOperators deploys the operators and the bifrost service to
+// a cluster that isn't managed by kops, along with any extra manifests, and
+// waits for them to start.
+func provisionClusterOperators(k8sClient *k8s.KubeClient, bifrostSecret *corev1.Secret, logger log.FieldLogger, extraFiles ...k8s.ManifestFile) error {
+	mysqlOperatorNamespace := "mysql-operator"
+	minioOperatorNamespace := "minio-operator"
+	mattermostOperatorNamespace := "mattermost-operator"
+	bifrostNamespace := "bifrost"
+
+	logger.Info("Creating utility namespaces")
+	_, err := k8sClient.CreateOrUpdateNamespaces([]string{
+		mysqlOperatorNamespace,
+		minioOperatorNamespace,
+		mattermostOperatorNamespace,
+		bifrostNamespace,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create utility namespaces")
+	}
+
+	logger.Info("Creating or updating bifrost secret")
+	_, err = k8sClient.CreateOrUpdateSecret(bifrostNamespace, bifrostSecret)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bifrost secret")
+	}
+
+	files := []k8s.ManifestFile{
+		{
+			Path:            "manifests/operator-manifests/mysql/mysql-operator.yaml",
+			DeployNamespace: mysqlOperatorNamespace,
+		}, {
+			Path:            "manifests/operator-manifests/minio/minio-operator.yaml",
+			DeployNamespace: minioOperatorNamespace,
+		}, {
+			Path:            "manifests/operator-manifests/mattermost/crds/mm_clusterinstallation_crd.yaml",
+			DeployNamespace: mattermostOperatorNamespace,
+		}, {
+			Path:            "manifests/operator-manifests/mattermost/crds/mm_mattermostrestoredb_crd.yaml",
+			DeployNamespace: mattermostOperatorNamespace,
+		}, {
+			Path:            "manifests/operator-manifests/mattermost/service_account.yaml",
+			DeployNamespace: mattermostOperatorNamespace,
+		}, {
+			Path:            "manifests/operator-manifests/mattermost/role.yaml",
+			DeployNamespace: mattermostOperatorNamespace,
+		}, {
+			Path:            "manifests/operator-manifests/mattermost/role_binding.yaml",
+			DeployNamespace: mattermostOperatorNamespace,
+		}, {
+			Path:            "manifests/operator-manifests/mattermost/operator.yaml",
+			DeployNamespace: mattermostOperatorNamespace,
+		}, {
+			Path:            "manifests/bifrost/bifrost.yaml",
+			DeployNamespace: bifrostNamespace,
+		},
+	}
+	files = append(files, extraFiles...)
+	err = k8sClient.CreateFromFiles(files)
+	if err != nil {
+		return err
+	}
+
+	wait := 240
+	appsWithDeployment := map[string]string{
+		"minio-operator":      minioOperatorNamespace,
+		"mattermost-operator": mattermostOperatorNamespace,
+		"bifrost":             bifrostNamespace,
+	}
+	for deployment, namespace := range appsWithDeployment {
+		pods, err := k8sClient.GetPodsFromDeployment(namespace, deployment)
+		if err != nil {
+			return err
+		}
+		if len(pods.Items) == 0 {
+			return fmt.Errorf("no pods found from %q deployment", deployment)
+		}
+
+		for _, pod := range pods.Items {
+			logger.Infof("Waiting up to %d seconds for %q pod %q to start...", wait, deployment, pod.GetName())
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(wait)*time.Second)
+			defer cancel()
+			_, err := k8sClient.WaitForPodRunning(ctx, namespace, pod.GetName())
+			if err != nil {
+				return err
+			}
+			logger.Infof("Successfully deployed service pod %q", pod.GetName())
+		}
+	}
+
+	pods, err := k8sClient.GetPodsFromStatefulset(mysqlOperatorNamespace, "mysql-operator")
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		logger.Infof("Waiting up to %d seconds for %q pod %q to start...", wait, "mysql-operator", pod.GetName())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(wait)*time.Second)
+		defer cancel()
+		_, err := k8sClient.WaitForPodRunning(ctx, mysqlOperatorNamespace, pod.GetName())
+		if err != nil {
+			return err
+		}
+		logger.Infof("Successfully deployed service pod %q", pod.GetName())
+	}
+
+	return nil
+}
@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
@@ -25,14 +24,14 @@ import (
 type prometheusOperator struct {
 	awsClient      aws.AWS
 	cluster        *model.Cluster
-	kops           *kops.Cmd
+	kubeconfig     kubeconfigProvider
 	logger         log.FieldLogger
 	provisioner    *KopsProvisioner
 	desiredVersion string
 	actualVersion  string
 }
 
-func newPrometheusOperatorHandle(cluster *model.Cluster, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*prometheusOperator, error) {
+func newPrometheusOperatorHandle(cluster *model.Cluster, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*prometheusOperator, error) {
 	if logger == nil {
 		return nil, fmt.Errorf("cannot instantiate Prometheus Operator handle with nil logger")
 	}
@@ -49,8 +48,8 @@ func newPrometheusOperatorHandle(cluster *model.Cluster, provisioner *KopsProvis
 		return nil, errors.New("cannot create a connection to Prometheus Operator if the awsClient provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Prometheus Operator if the Kops command provided is nil")
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to Prometheus Operator if the kubeconfig provider is nil")
 	}
 
 	version, err := cluster.DesiredUtilityVersion(model.PrometheusOperatorCanonicalName)
@@ -61,7 +60,7 @@ func newPrometheusOperatorHandle(cluster *model.Cluster, provisioner *KopsProvis
 	return &prometheusOperator{
 		awsClient:      awsClient,
 		cluster:        cluster,
-		kops:           kops,
+		kubeconfig:     kubeconfig,
 		logger:         logger.WithField("cluster-utility", model.PrometheusOperatorCanonicalName),
 		provisioner:    provisioner,
 		desiredVersion: version,
@@ -107,7 +106,7 @@ func (p *prometheusOperator) CreateOrUpgrade() error {
 		},
 	}
 
-	k8sClient, err := k8s.NewFromFile(p.kops.GetKubeConfigPath(), logger)
+	k8sClient, err := k8s.NewFromFile(p.kubeconfig.GetKubeConfigPath(), logger)
 	if err != nil {
 		return errors.Wrap(err, "failed to set up the k8s client")
 	}
@@ -155,7 +154,7 @@ func (p *prometheusOperator) CreateOrUpgrade() error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(120)*time.Second)
 	defer cancel()
 
-	endpoint, err := getPrivateLoadBalancerEndpoint(ctx, "nginx", logger.WithField("prometheus-action", "create"), p.kops.GetKubeConfigPath())
+	endpoint, err := getPrivateLoadBalancerEndpoint(ctx, "nginx", logger.WithField("prometheus-action", "create"), p.kubeconfig.GetKubeConfigPath())
 	if err != nil {
 		return errors.Wrap(err, "couldn't get the load balancer endpoint (nginx) for Prometheus")
 	}
@@ -199,12 +198,13 @@ func (p *prometheusOperator) NewHelmDeployment(prometheusDNS string) *helmDeploy
 	return &helmDeployment{
 		chartDeploymentName: "prometheus-operator",
 		chartName:           "prometheus-community/kube-prometheus-stack",
-		kops:                p.kops,
+		kubeconfig:          p.kubeconfig,
 		kopsProvisioner:     p.provisioner,
 		logger:              p.logger,
 		namespace:           "prometheus",
 		setArgument:         helmValueArguments,
 		valuesPath:          "helm-charts/prometheus_operator_values.yaml",
+		valuesOverride:      p.cluster.UtilityValuesOverride(model.PrometheusOperatorCanonicalName),
 		desiredVersion:      p.desiredVersion,
 	}
 }
@@ -0,0 +1,290 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"os"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/k8s"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ClusterProvisioner manages the lifecycle of the clusters created by a
+// single provisioner such as kops or EKS.
+type ClusterProvisioner interface {
+	ImportCluster(cluster *model.Cluster) error
+	PrepareCluster(cluster *model.Cluster) bool
+	CreateCluster(cluster *model.Cluster, awsClient aws.AWS) error
+	ProvisionCluster(cluster *model.Cluster, awsClient aws.AWS) error
+	UpgradeCluster(cluster *model.Cluster, awsClient aws.AWS) error
+	UpgradeClusterUtility(cluster *model.Cluster, awsClient aws.AWS) error
+	CheckClusterUtilityDrift(cluster *model.Cluster) (*model.UtilityDriftCheck, error)
+	ResizeCluster(cluster *model.Cluster) error
+	DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error
+	RefreshClusterMetadata(cluster *model.Cluster) error
+	PreviewClusterChanges(cluster *model.Cluster) (*model.ClusterChangePreview, error)
+	GetClusterInstanceGroups(cluster *model.Cluster) ([]string, []string, error)
+	RollingUpdateClusterInstanceGroups(cluster *model.Cluster, igNames []string) error
+	RevertClusterUpgrade(cluster *model.Cluster) error
+	kubeClientProvider
+}
+
+// kubeClientProvider creates kubernetes clients for the clusters managed by a
+// provisioner.
+type kubeClientProvider interface {
+	getKubeClient(cluster *model.Cluster, logger log.FieldLogger) (*k8s.KubeClient, error)
+}
+
+// Router routes the cluster lifecycle operations to the provisioner of each
+// cluster. Operations on cluster installations only need a kubernetes client
+// for the cluster and are shared by all provisioners; they are run by the kops
+// provisioner with the client of the provisioner managing the cluster.
+type Router struct {
+	*KopsProvisioner
+	clusterProvisioners map[string]ClusterProvisioner
+}
+
+// NewRouter creates a new Router for the given cluster provisioners keyed by
+// provisioner name.
+func NewRouter(kopsProvisioner *KopsProvisioner, clusterProvisioners map[string]ClusterProvisioner) *Router {
+	kopsProvisioner.kubeClientProviders = make(map[string]kubeClientProvider, len(clusterProvisioners))
+	for name, clusterProvisioner := range clusterProvisioners {
+		kopsProvisioner.kubeClientProviders[name] = clusterProvisioner
+	}
+
+	return &Router{
+		KopsProvisioner:     kopsProvisioner,
+		clusterProvisioners: clusterProvisioners,
+	}
+}
+
+func (r *Router) getClusterProvisioner(cluster *model.Cluster) (ClusterProvisioner, error) {
+	provisioner := cluster.Provisioner
+	if len(provisioner) == 0 {
+		provisioner = model.ProvisionerKops
+	}
+
+	clusterProvisioner, ok := r.clusterProvisioners[provisioner]
+	if !ok {
+		return nil, errors.Errorf("unsupported provisioner %s", provisioner)
+	}
+
+	return clusterProvisioner, nil
+}
+
+// clusterAWSClient returns the AWS client for the region and account the
+// cluster is placed in.
+func clusterAWSClient(cluster *model.Cluster, awsClient aws.AWS) (aws.AWS, error) {
+	if awsClient == nil {
+		return nil, nil
+	}
+
+	return awsClient.ClientForCluster(cluster)
+}
+
+// clusterAWSRegion returns the AWS region the cluster is placed in, falling
+// back to the region of the provisioning server.
+func clusterAWSRegion(cluster *model.Cluster) string {
+	if region := cluster.AWSRegion(); len(region) != 0 {
+		return region
+	}
+	if region := os.Getenv("AWS_REGION"); len(region) != 0 {
+		return region
+	}
+
+	return aws.DefaultAWSRegion
+}
+
+// ImportCluster verifies that an existing cluster can be managed by its
+// provisioner.
+func (r *Router) ImportCluster(cluster *model.Cluster) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.ImportCluster(cluster)
+}
+
+// PrepareCluster ensures a cluster object is ready for provisioning.
+func (r *Router) PrepareCluster(cluster *model.Cluster) bool {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		r.logger.WithField("cluster", cluster.ID).WithError(err).Error("Failed to prepare cluster")
+		return false
+	}
+
+	return clusterProvisioner.PrepareCluster(cluster)
+}
+
+// CreateCluster creates a cluster with its provisioner.
+func (r *Router) CreateCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+	awsClient, err = clusterAWSClient(cluster, awsClient)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.CreateCluster(cluster, awsClient)
+}
+
+// ProvisionCluster installs the cluster services with its provisioner.
+func (r *Router) ProvisionCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+	awsClient, err = clusterAWSClient(cluster, awsClient)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.ProvisionCluster(cluster, awsClient)
+}
+
+// UpgradeCluster upgrades a cluster with its provisioner.
+func (r *Router) UpgradeCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+	awsClient, err = clusterAWSClient(cluster, awsClient)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.UpgradeCluster(cluster, awsClient)
+}
+
+// UpgradeClusterUtility applies the pending single utility upgrade of a
+// cluster with its provisioner.
+func (r *Router) UpgradeClusterUtility(cluster *model.Cluster, awsClient aws.AWS) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+	awsClient, err = clusterAWSClient(cluster, awsClient)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.UpgradeClusterUtility(cluster, awsClient)
+}
+
+// CheckClusterUtilityDrift compares the utilities running in a cluster with
+// what its provisioner last deployed.
+func (r *Router) CheckClusterUtilityDrift(cluster *model.Cluster) (*model.UtilityDriftCheck, error) {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterProvisioner.CheckClusterUtilityDrift(cluster)
+}
+
+// ResizeCluster resizes a cluster with its provisioner.
+func (r *Router) ResizeCluster(cluster *model.Cluster) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.ResizeCluster(cluster)
+}
+
+// DeleteCluster deletes a cluster with its provisioner.
+func (r *Router) DeleteCluster(cluster *model.Cluster, awsClient aws.AWS) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+	awsClient, err = clusterAWSClient(cluster, awsClient)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.DeleteCluster(cluster, awsClient)
+}
+
+// RefreshClusterMetadata refreshes the provisioner metadata of a cluster.
+func (r *Router) RefreshClusterMetadata(cluster *model.Cluster) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.RefreshClusterMetadata(cluster)
+}
+
+// PreviewClusterChanges previews the pending changes of a cluster with its
+// provisioner.
+func (r *Router) PreviewClusterChanges(cluster *model.Cluster) (*model.ClusterChangePreview, error) {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterProvisioner.PreviewClusterChanges(cluster)
+}
+
+// GetClusterInstanceGroups returns the master and worker node groups of a
+// cluster.
+func (r *Router) GetClusterInstanceGroups(cluster *model.Cluster) ([]string, []string, error) {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return clusterProvisioner.GetClusterInstanceGroups(cluster)
+}
+
+// RollingUpdateClusterInstanceGroups replaces the nodes of the given node
+// groups of a cluster.
+func (r *Router) RollingUpdateClusterInstanceGroups(cluster *model.Cluster, igNames []string) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.RollingUpdateClusterInstanceGroups(cluster, igNames)
+}
+
+// RevertClusterUpgrade restores the version of a cluster with a paused
+// staged upgrade.
+func (r *Router) RevertClusterUpgrade(cluster *model.Cluster) error {
+	clusterProvisioner, err := r.getClusterProvisioner(cluster)
+	if err != nil {
+		return err
+	}
+
+	return clusterProvisioner.RevertClusterUpgrade(cluster)
+}
+
+// getClusterName returns the name the provisioner of the cluster knows it by.
+func getClusterName(cluster *model.Cluster) string {
+	switch cluster.Provisioner {
+	case model.ProvisionerEKS:
+		if cluster.ProvisionerMetadataEKS == nil {
+			return ""
+		}
+		return cluster.ProvisionerMetadataEKS.Name
+	case model.ProvisionerExternal:
+		if cluster.ProvisionerMetadataExternal == nil {
+			return ""
+		}
+		return cluster.ProvisionerMetadataExternal.Name
+	}
+	if cluster.ProvisionerMetadataKops == nil {
+		return ""
+	}
+
+	return cluster.ProvisionerMetadataKops.Name
+}
@@ -6,11 +6,9 @@ package provisioner
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -20,14 +18,14 @@ type teleport struct {
 	awsClient      aws.AWS
 	environment    string
 	provisioner    *KopsProvisioner
-	kops           *kops.Cmd
+	kubeconfig     kubeconfigProvider
 	cluster        *model.Cluster
 	logger         log.FieldLogger
 	desiredVersion string
 	actualVersion  string
 }
 
-func newTeleportHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*teleport, error) {
+func newTeleportHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*teleport, error) {
 	if logger == nil {
 		return nil, errors.New("cannot instantiate Teleport handle with nil logger")
 	}
@@ -36,8 +34,8 @@ func newTeleportHandle(cluster *model.Cluster, desiredVersion string, provisione
 		return nil, errors.New("cannot create a connection to Teleport if the provisioner provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Teleport if the Kops command provided is nil")
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to Teleport if the kubeconfig provider is nil")
 	}
 
 	environment, err := awsClient.GetCloudEnvironmentName()
@@ -53,7 +51,7 @@ func newTeleportHandle(cluster *model.Cluster, desiredVersion string, provisione
 		awsClient:      awsClient,
 		environment:    environment,
 		provisioner:    provisioner,
-		kops:           kops,
+		kubeconfig:     kubeconfig,
 		cluster:        cluster,
 		logger:         logger.WithField("cluster-utility", model.TeleportCanonicalName),
 		desiredVersion: desiredVersion,
@@ -120,10 +118,7 @@ func (n *teleport) Migrate() error {
 }
 
 func (n *teleport) NewHelmDeployment() *helmDeployment {
-	awsRegion := os.Getenv("AWS_REGION")
-	if awsRegion == "" {
-		awsRegion = aws.DefaultAWSRegion
-	}
+	awsRegion := clusterAWSRegion(n.cluster)
 	teleportClusterName := fmt.Sprintf("cloud-%s-%s", n.environment, n.cluster.ID)
 	return &helmDeployment{
 		chartDeploymentName: "teleport",
@@ -131,8 +126,9 @@ func (n *teleport) NewHelmDeployment() *helmDeployment {
 		namespace:           "teleport",
 		setArgument:         fmt.Sprintf("config.auth_service.cluster_name=%[1]s,config.teleport.storage.region=%[2]s,config.teleport.storage.table_name=%[1]s,config.teleport.storage.audit_events_uri=dynamodb://%[1]s-events,config.teleport.storage.audit_sessions_uri=s3://%[1]s/records?region=%[2]s", teleportClusterName, awsRegion),
 		valuesPath:          "helm-charts/teleport_values.yaml",
+		valuesOverride:      n.cluster.UtilityValuesOverride(model.TeleportCanonicalName),
 		kopsProvisioner:     n.provisioner,
-		kops:                n.kops,
+		kubeconfig:          n.kubeconfig,
 		logger:              n.logger,
 		desiredVersion:      n.desiredVersion,
 	}
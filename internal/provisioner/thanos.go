@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -21,14 +20,14 @@ import (
 type thanos struct {
 	awsClient      aws.AWS
 	cluster        *model.Cluster
-	kops           *kops.Cmd
+	kubeconfig     kubeconfigProvider
 	logger         log.FieldLogger
 	provisioner    *KopsProvisioner
 	desiredVersion string
 	actualVersion  string
 }
 
-func newThanosHandle(cluster *model.Cluster, provisioner *KopsProvisioner, awsClient aws.AWS, kops *kops.Cmd, logger log.FieldLogger) (*thanos, error) {
+func newThanosHandle(cluster *model.Cluster, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*thanos, error) {
 	if logger == nil {
 		return nil, fmt.Errorf("cannot instantiate Thanos handle with nil logger")
 	}
@@ -45,8 +44,8 @@ func newThanosHandle(cluster *model.Cluster, provisioner *KopsProvisioner, awsCl
 		return nil, errors.New("cannot create a connection to Thanos if the awsClient provided is nil")
 	}
 
-	if kops == nil {
-		return nil, errors.New("cannot create a connection to Thanos if the Kops command provided is nil")
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to Thanos if the kubeconfig provider is nil")
 	}
 
 	version, err := cluster.DesiredUtilityVersion(model.ThanosCanonicalName)
@@ -57,7 +56,7 @@ func newThanosHandle(cluster *model.Cluster, provisioner *KopsProvisioner, awsCl
 	return &thanos{
 		awsClient:      awsClient,
 		cluster:        cluster,
-		kops:           kops,
+		kubeconfig:     kubeconfig,
 		logger:         logger.WithField("cluster-utility", model.ThanosCanonicalName),
 		provisioner:    provisioner,
 		desiredVersion: version,
@@ -113,7 +112,7 @@ func (t *thanos) CreateOrUpgrade() error {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(120)*time.Second)
 		defer cancel()
 
-		endpoint, err := getPrivateLoadBalancerEndpoint(ctx, "nginx", logger.WithField("thanos-action", "create"), t.kops.GetKubeConfigPath())
+		endpoint, err := getPrivateLoadBalancerEndpoint(ctx, "nginx", logger.WithField("thanos-action", "create"), t.kubeconfig.GetKubeConfigPath())
 		if err != nil {
 			return errors.Wrap(err, "couldn't get the load balancer endpoint (nginx) for Thanos")
 		}
@@ -132,7 +131,7 @@ func (t *thanos) CreateOrUpgrade() error {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(120)*time.Second)
 		defer cancel()
 
-		endpoint, err := getPrivateLoadBalancerEndpoint(ctx, "prometheus", logger.WithField("thanos-action", "create"), t.kops.GetKubeConfigPath())
+		endpoint, err := getPrivateLoadBalancerEndpoint(ctx, "prometheus", logger.WithField("thanos-action", "create"), t.kubeconfig.GetKubeConfigPath())
 		if err != nil {
 			return errors.Wrap(err, "couldn't get the load balancer endpoint for Thanos")
 		}
@@ -183,12 +182,13 @@ func (t *thanos) NewHelmDeployment(thanosDNS, thanosDNSGRPC string) *helmDeploym
 	return &helmDeployment{
 		chartDeploymentName: "thanos",
 		chartName:           "bitnami/thanos",
-		kops:                t.kops,
+		kubeconfig:          t.kubeconfig,
 		kopsProvisioner:     t.provisioner,
 		logger:              t.logger,
 		namespace:           "prometheus",
 		setArgument:         helmValueArguments,
 		valuesPath:          "helm-charts/thanos_values.yaml",
+		valuesOverride:      t.cluster.UtilityValuesOverride(model.ThanosCanonicalName),
 		desiredVersion:      t.desiredVersion,
 	}
 }
@@ -5,8 +5,9 @@
 package provisioner
 
 import (
+	"strings"
+
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
-	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -43,13 +44,19 @@ type Utility interface {
 	Name() string
 }
 
+// kubeconfigProvider provides the kubeconfig of the cluster the utilities
+// are deployed to.
+type kubeconfigProvider interface {
+	GetKubeConfigPath() string
+}
+
 // utilityGroup  holds  the  metadata  needed  to  manage  a  specific
 // utilityGroup,  and therefore  uniquely identifies  one, and  can be
 // thought  of as  a handle  to the  real group  of utilities  running
 // inside of the cluster
 type utilityGroup struct {
 	utilities   []Utility
-	kops        *kops.Cmd
+	kubeconfig  kubeconfigProvider
 	provisioner *KopsProvisioner
 	cluster     *model.Cluster
 }
@@ -61,9 +68,36 @@ var helmRepos = map[string]string{
 	"ingress-nginx":        "https://kubernetes.github.io/ingress-nginx",
 	"prometheus-community": "https://prometheus-community.github.io/helm-charts",
 	"bitnami":              "https://charts.bitnami.com/bitnami",
+	"vmware-tanzu":         "https://vmware-tanzu.github.io/helm-charts",
+	"jetstack":             "https://charts.jetstack.io",
+	"autoscaler":           "https://kubernetes.github.io/autoscaler",
+	"eks":                  "https://aws.github.io/eks-charts",
+}
+
+// utilityRelease is the Helm release a utility is deployed as. The chart
+// prefix is trimmed from the chart of the release to get the version
+// reported by the utility.
+type utilityRelease struct {
+	utility     string
+	name        string
+	namespace   string
+	chartPrefix string
+}
+
+// List of the Helm releases of the utilities in deployment order
+var utilityReleases = []utilityRelease{
+	{model.NginxCanonicalName, "nginx", "nginx", "ingress-nginx-"},
+	{model.CertManagerCanonicalName, "cert-manager", certManagerNamespace, "cert-manager-"},
+	{model.PrometheusOperatorCanonicalName, "prometheus-operator", "prometheus", "kube-prometheus-stack-"},
+	{model.ThanosCanonicalName, "thanos", "prometheus", "thanos-"},
+	{model.FluentbitCanonicalName, "fluent-bit", "fluent-bit", "fluent-bit-"},
+	{model.TeleportCanonicalName, "teleport", "teleport", "teleport-"},
+	{model.VeleroCanonicalName, "velero", veleroNamespace, "velero-"},
+	{model.ClusterAutoscalerCanonicalName, "cluster-autoscaler", "kube-system", "cluster-autoscaler-"},
+	{model.NodeTerminationHandlerCanonicalName, "aws-node-termination-handler", "kube-system", "aws-node-termination-handler-"},
 }
 
-func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster *model.Cluster, awsClient aws.AWS, parentLogger log.FieldLogger) (*utilityGroup, error) {
+func newUtilityGroupHandle(kubeconfig kubeconfigProvider, provisioner *KopsProvisioner, cluster *model.Cluster, awsClient aws.AWS, parentLogger log.FieldLogger) (*utilityGroup, error) {
 	logger := parentLogger.WithField("utility-group", "create-handle")
 
 	desiredVersion, err := cluster.DesiredUtilityVersion(model.NginxCanonicalName)
@@ -71,17 +105,27 @@ func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster
 		return nil, err
 	}
 
-	nginx, err := newNginxHandle(desiredVersion, provisioner, awsClient, kops, logger)
+	nginx, err := newNginxHandle(cluster, desiredVersion, provisioner, awsClient, kubeconfig, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for NGINX")
 	}
 
-	prometheusOperator, err := newPrometheusOperatorHandle(cluster, provisioner, awsClient, kops, logger)
+	desiredVersion, err = cluster.DesiredUtilityVersion(model.CertManagerCanonicalName)
+	if err != nil {
+		return nil, err
+	}
+
+	certManager, err := newCertManagerHandle(cluster, desiredVersion, provisioner, kubeconfig, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get handle for cert-manager")
+	}
+
+	prometheusOperator, err := newPrometheusOperatorHandle(cluster, provisioner, awsClient, kubeconfig, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for Prometheus Operator")
 	}
 
-	thanos, err := newThanosHandle(cluster, provisioner, awsClient, kops, logger)
+	thanos, err := newThanosHandle(cluster, provisioner, awsClient, kubeconfig, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for Thanos")
 	}
@@ -91,7 +135,7 @@ func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster
 		return nil, err
 	}
 
-	fluentbit, err := newFluentbitHandle(desiredVersion, provisioner, awsClient, kops, logger)
+	fluentbit, err := newFluentbitHandle(cluster, desiredVersion, provisioner, awsClient, kubeconfig, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for Fluentbit")
 	}
@@ -101,16 +145,46 @@ func newUtilityGroupHandle(kops *kops.Cmd, provisioner *KopsProvisioner, cluster
 		return nil, err
 	}
 
-	teleport, err := newTeleportHandle(cluster, desiredVersion, provisioner, awsClient, kops, logger)
+	teleport, err := newTeleportHandle(cluster, desiredVersion, provisioner, awsClient, kubeconfig, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get handle for Teleport")
 	}
 
+	desiredVersion, err = cluster.DesiredUtilityVersion(model.VeleroCanonicalName)
+	if err != nil {
+		return nil, err
+	}
+
+	velero, err := newVeleroHandle(cluster, desiredVersion, provisioner, awsClient, kubeconfig, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get handle for Velero")
+	}
+
+	desiredVersion, err = cluster.DesiredUtilityVersion(model.ClusterAutoscalerCanonicalName)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterAutoscaler, err := newClusterAutoscalerHandle(cluster, desiredVersion, provisioner, kubeconfig, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get handle for cluster-autoscaler")
+	}
+
+	desiredVersion, err = cluster.DesiredUtilityVersion(model.NodeTerminationHandlerCanonicalName)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeTerminationHandler, err := newNodeTerminationHandlerHandle(cluster, desiredVersion, provisioner, kubeconfig, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get handle for aws-node-termination-handler")
+	}
+
 	// the order of utilities here matters; the utilities are deployed
 	// in order to resolve dependencies between them
 	return &utilityGroup{
-		utilities:   []Utility{nginx, prometheusOperator, thanos, fluentbit, teleport},
-		kops:        kops,
+		utilities:   []Utility{nginx, certManager, prometheusOperator, thanos, fluentbit, teleport, velero, clusterAutoscaler, nodeTerminationHandler},
+		kubeconfig:  kubeconfig,
 		provisioner: provisioner,
 		cluster:     cluster,
 	}, nil
@@ -163,12 +237,166 @@ func (group utilityGroup) ProvisionUtilityGroup() error {
 		if err != nil {
 			return err
 		}
+		group.recordValuesHash(utility.Name(), logger)
 	}
 
-	err := helm2Cleanup(logger, group.kops.GetKubeConfigPath())
+	err := helm2Cleanup(logger, group.kubeconfig.GetKubeConfigPath())
 	if err != nil {
 		return errors.Wrap(err, "failed to cleanup Helm 2")
 	}
 
 	return nil
 }
+
+// UpgradeUtility reapplies the chart of a single utility of the
+// UtilityGroup, leaving the other utilities untouched.
+func (group utilityGroup) UpgradeUtility(name string) error {
+	logger := group.provisioner.logger.WithField("utility-group", "UpgradeUtility")
+
+	var utility Utility
+	for _, u := range group.utilities {
+		if u.Name() == name {
+			utility = u
+			break
+		}
+	}
+	if utility == nil {
+		return errors.Errorf("unknown utility %s", name)
+	}
+
+	logger.Info("Adding new Helm repos.")
+	for repoName, repoURL := range helmRepos {
+		err := helmRepoAdd(repoName, repoURL, logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to add helm repos")
+		}
+	}
+
+	err := utility.CreateOrUpgrade()
+	if err != nil {
+		return errors.Wrapf(err, "failed to upgrade utility %s", name)
+	}
+
+	err = group.cluster.SetUtilityActualVersion(utility.Name(), utility.ActualVersion())
+	if err != nil {
+		return err
+	}
+	group.recordValuesHash(utility.Name(), logger)
+
+	return nil
+}
+
+// recordValuesHash stores the hash of the Helm values of a freshly deployed
+// utility as the baseline for drift detection. A failure only disables the
+// values comparison for the utility.
+func (group utilityGroup) recordValuesHash(name string, logger log.FieldLogger) {
+	for _, release := range utilityReleases {
+		if release.utility != name {
+			continue
+		}
+
+		hash, err := newUtilityReleaseDeployment(release, group.kubeconfig, logger).ValuesHash()
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to record values hash of utility %s", name)
+		}
+		group.cluster.SetUtilityValuesHash(name, hash)
+		return
+	}
+}
+
+// checkUtilityDrift compares the Helm releases of the utilities running in
+// the cluster with the versions and values last deployed by the provisioner.
+func checkUtilityDrift(kubeconfig kubeconfigProvider, cluster *model.Cluster, logger log.FieldLogger) (*model.UtilityDriftCheck, error) {
+	lister := &helmDeployment{kubeconfig: kubeconfig, logger: logger}
+	releases, err := lister.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list Helm releases")
+	}
+
+	check := &model.UtilityDriftCheck{}
+	for _, utilityRelease := range utilityReleases {
+		expectedVersion, err := cluster.ActualUtilityVersion(utilityRelease.utility)
+		if err != nil {
+			return nil, err
+		}
+
+		release := model.UtilityRelease{
+			Name:               utilityRelease.utility,
+			ExpectedVersion:    expectedVersion,
+			ExpectedValuesHash: cluster.UtilityValuesHash(utilityRelease.utility),
+		}
+		for _, deployed := range releases.asSlice() {
+			if deployed.Name == utilityRelease.name && deployed.Namespace == utilityRelease.namespace {
+				release.Version = strings.TrimPrefix(deployed.Chart, utilityRelease.chartPrefix)
+				break
+			}
+		}
+
+		if release.Version != "" {
+			release.ValuesHash, err = newUtilityReleaseDeployment(utilityRelease, kubeconfig, logger).ValuesHash()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get values hash of utility %s", utilityRelease.utility)
+			}
+		}
+
+		check.Releases = append(check.Releases, release)
+	}
+
+	return check, nil
+}
+
+// newUtilityReleaseDeployment returns a helmDeployment that can be used to
+// query the Helm release of a utility.
+func newUtilityReleaseDeployment(release utilityRelease, kubeconfig kubeconfigProvider, logger log.FieldLogger) *helmDeployment {
+	return &helmDeployment{
+		chartDeploymentName: release.name,
+		namespace:           release.namespace,
+		kubeconfig:          kubeconfig,
+		logger:              logger,
+	}
+}
+
+// upgradeClusterUtility applies the pending single utility upgrade of the
+// cluster. If the upgrade fails, the utility is rolled back to its previous
+// desired version and the upgrade error is returned.
+func upgradeClusterUtility(kubeconfig kubeconfigProvider, provisioner *KopsProvisioner, cluster *model.Cluster, awsClient aws.AWS, logger log.FieldLogger) error {
+	upgrade := cluster.UtilityUpgrade()
+	if upgrade == nil {
+		return errors.New("cluster has no pending utility upgrade")
+	}
+	logger = logger.WithField("utility", upgrade.Name)
+
+	ugh, err := newUtilityGroupHandle(kubeconfig, provisioner, cluster, awsClient, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new cluster utility group handle")
+	}
+
+	// The utility is being deployed from here on, so the pending upgrade is
+	// consumed whatever the outcome.
+	cluster.ClearUtilityUpgrade()
+
+	upgradeErr := ugh.UpgradeUtility(upgrade.Name)
+	if upgradeErr == nil {
+		return nil
+	}
+
+	logger.WithError(upgradeErr).Warnf("Utility upgrade failed; rolling back to version %q", upgrade.PreviousVersion)
+
+	err = cluster.SetUtilityDesiredVersions(map[string]string{upgrade.Name: upgrade.PreviousVersion})
+	if err != nil {
+		return errors.Wrap(err, "failed to restore previous utility version")
+	}
+
+	ugh, err = newUtilityGroupHandle(kubeconfig, provisioner, cluster, awsClient, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new cluster utility group handle for rollback")
+	}
+
+	err = ugh.UpgradeUtility(upgrade.Name)
+	if err != nil {
+		logger.WithError(err).Error("Failed to roll back utility")
+		return errors.Wrapf(upgradeErr, "utility upgrade failed and rollback also failed (%s)", err.Error())
+	}
+
+	return errors.Wrap(upgradeErr, "utility upgrade failed and was rolled back")
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// veleroNamespace is the namespace Velero and its backups live in.
+const veleroNamespace = "velero"
+
+type velero struct {
+	awsClient      aws.AWS
+	environment    string
+	provisioner    *KopsProvisioner
+	kubeconfig     kubeconfigProvider
+	cluster        *model.Cluster
+	logger         log.FieldLogger
+	desiredVersion string
+	actualVersion  string
+}
+
+func newVeleroHandle(cluster *model.Cluster, desiredVersion string, provisioner *KopsProvisioner, awsClient aws.AWS, kubeconfig kubeconfigProvider, logger log.FieldLogger) (*velero, error) {
+	if logger == nil {
+		return nil, errors.New("cannot instantiate Velero handle with nil logger")
+	}
+
+	if provisioner == nil {
+		return nil, errors.New("cannot create a connection to Velero if the provisioner provided is nil")
+	}
+
+	if kubeconfig == nil {
+		return nil, errors.New("cannot create a connection to Velero if the kubeconfig provider is nil")
+	}
+
+	environment, err := awsClient.GetCloudEnvironmentName()
+	if err != nil {
+		return nil, err
+	}
+
+	if environment == "" {
+		return nil, errors.New("cannot create a connection to Velero if the environment is empty")
+	}
+
+	return &velero{
+		awsClient:      awsClient,
+		environment:    environment,
+		provisioner:    provisioner,
+		kubeconfig:     kubeconfig,
+		cluster:        cluster,
+		logger:         logger.WithField("cluster-utility", model.VeleroCanonicalName),
+		desiredVersion: desiredVersion,
+	}, nil
+}
+
+func (v *velero) updateVersion(h *helmDeployment) error {
+	actualVersion, err := h.Version()
+	if err != nil {
+		return err
+	}
+
+	v.actualVersion = actualVersion
+	return nil
+}
+
+// bucketName returns the name of the S3 bucket the backups of the cluster
+// are stored in.
+func (v *velero) bucketName() string {
+	return fmt.Sprintf("cloud-%s-%s-velero", v.environment, v.cluster.ID)
+}
+
+// policyName returns the name of the IAM policy granting the cluster nodes
+// access to the backup bucket.
+func (v *velero) policyName() string {
+	return fmt.Sprintf("%s-bucket", v.bucketName())
+}
+
+// nodeRoleName returns the IAM role of the cluster nodes Velero runs on, or
+// an empty string if the role is not known to the provisioner.
+func (v *velero) nodeRoleName() string {
+	if v.cluster.ProvisionerMetadataKops == nil || len(v.cluster.ProvisionerMetadataKops.Name) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("nodes.%s", v.cluster.ProvisionerMetadataKops.Name)
+}
+
+func (v *velero) CreateOrUpgrade() error {
+	err := v.awsClient.S3EnsureBucketCreated(v.bucketName(), v.logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Velero backup bucket")
+	}
+
+	roleName := v.nodeRoleName()
+	if len(roleName) != 0 {
+		err = v.awsClient.EnsureS3BucketPolicyCreated(v.policyName(), v.bucketName(), v.logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to create Velero backup bucket policy")
+		}
+		err = v.awsClient.AttachPolicyToRole(roleName, v.policyName(), v.logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to attach Velero backup bucket policy")
+		}
+	} else {
+		v.logger.Warnf("The cluster nodes must be granted access to the Velero backup bucket %s", v.bucketName())
+	}
+
+	h := v.NewHelmDeployment()
+
+	err = h.TryMigrate()
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate velero release")
+	}
+
+	err = h.Update()
+	if err != nil {
+		return err
+	}
+
+	err = v.updateVersion(h)
+	return err
+}
+
+func (v *velero) DesiredVersion() string {
+	return v.desiredVersion
+}
+
+func (v *velero) ActualVersion() string {
+	return strings.TrimPrefix(v.actualVersion, "velero-")
+}
+
+// Destroy revokes the access of the cluster nodes to the backup bucket. The
+// bucket itself is kept so that the backups can still be restored after the
+// cluster is deleted.
+func (v *velero) Destroy() error {
+	roleName := v.nodeRoleName()
+	if len(roleName) != 0 {
+		err := v.awsClient.DetachPolicyFromRole(roleName, v.policyName(), v.logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to detach Velero backup bucket policy")
+		}
+		err = v.awsClient.EnsureS3BucketPolicyDeleted(v.policyName(), v.logger)
+		if err != nil {
+			return errors.Wrap(err, "unable to delete Velero backup bucket policy")
+		}
+	}
+
+	v.logger.Infof("Keeping Velero backup bucket %s", v.bucketName())
+
+	return nil
+}
+
+func (v *velero) Migrate() error {
+	return nil
+}
+
+func (v *velero) NewHelmDeployment() *helmDeployment {
+	awsRegion := clusterAWSRegion(v.cluster)
+	return &helmDeployment{
+		chartDeploymentName: "velero",
+		chartName:           "vmware-tanzu/velero",
+		namespace:           veleroNamespace,
+		setArgument:         fmt.Sprintf("configuration.backupStorageLocation.bucket=%[1]s,configuration.backupStorageLocation.config.region=%[2]s", v.bucketName(), awsRegion),
+		valuesPath:          "helm-charts/velero_values.yaml",
+		valuesOverride:      v.cluster.UtilityValuesOverride(model.VeleroCanonicalName),
+		kopsProvisioner:     v.provisioner,
+		kubeconfig:          v.kubeconfig,
+		logger:              v.logger,
+		desiredVersion:      v.desiredVersion,
+	}
+}
+
+func (v *velero) Name() string {
+	return model.VeleroCanonicalName
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package provisioner
+
+import (
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/mattermost/mattermost-cloud/internal/mocks/aws-tools"
+	"github.com/mattermost/mattermost-cloud/internal/tools/kops"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVeleroHelmDeployment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	os.Setenv("AWS_REGION", "us-west-1")
+	defer os.Unsetenv("AWS_REGION")
+
+	logger := log.New()
+	awsClient := mocks.NewMockAWS(ctrl)
+	awsClient.EXPECT().GetCloudEnvironmentName().Return("test", nil)
+
+	cluster := &model.Cluster{ID: "cluster1"}
+	cluster.SetUtilityValuesOverrides(map[string]string{model.VeleroCanonicalName: "deployRestic: true\n"})
+	velero, err := newVeleroHandle(cluster, "2.13.6", &KopsProvisioner{}, awsClient, &kops.Cmd{}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, model.VeleroCanonicalName, velero.Name())
+	assert.Equal(t, "2.13.6", velero.DesiredVersion())
+
+	helmDeployment := velero.NewHelmDeployment()
+	require.NotNil(t, helmDeployment)
+	assert.Equal(t, "vmware-tanzu/velero", helmDeployment.chartName)
+	assert.Equal(t, "velero", helmDeployment.namespace)
+	assert.Equal(t, "deployRestic: true\n", helmDeployment.valuesOverride)
+	assert.Equal(t, "configuration.backupStorageLocation.bucket=cloud-test-cluster1-velero,configuration.backupStorageLocation.config.region=us-west-1", helmDeployment.setArgument)
+
+	velero.actualVersion = "velero-2.13.6"
+	assert.Equal(t, "2.13.6", velero.ActualVersion())
+}
+
+func TestVeleroDestroy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := log.New()
+	awsClient := mocks.NewMockAWS(ctrl)
+	awsClient.EXPECT().GetCloudEnvironmentName().Return("test", nil)
+	awsClient.EXPECT().DetachPolicyFromRole("nodes.cluster1-kops.k8s.local", "cloud-test-cluster1-velero-bucket", gomock.Any()).Return(nil)
+	awsClient.EXPECT().EnsureS3BucketPolicyDeleted("cloud-test-cluster1-velero-bucket", gomock.Any()).Return(nil)
+
+	cluster := &model.Cluster{
+		ID:                      "cluster1",
+		ProvisionerMetadataKops: &model.KopsMetadata{Name: "cluster1-kops.k8s.local"},
+	}
+	velero, err := newVeleroHandle(cluster, "2.13.6", &KopsProvisioner{}, awsClient, &kops.Cmd{}, logger)
+	require.NoError(t, err)
+	require.NoError(t, velero.Destroy())
+}
@@ -42,9 +42,23 @@ func buildRawMetadata(cluster *model.Cluster) (*RawClusterMetadata, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to marshal ProviderMetadataAWS")
 	}
-	provisionerMetadataJSON, err := json.Marshal(cluster.ProvisionerMetadataKops)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataKops")
+	var provisionerMetadataJSON []byte
+	switch cluster.Provisioner {
+	case model.ProvisionerEKS:
+		provisionerMetadataJSON, err = json.Marshal(cluster.ProvisionerMetadataEKS)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataEKS")
+		}
+	case model.ProvisionerExternal:
+		provisionerMetadataJSON, err = json.Marshal(cluster.ProvisionerMetadataExternal)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataExternal")
+		}
+	default:
+		provisionerMetadataJSON, err = json.Marshal(cluster.ProvisionerMetadataKops)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal ProvisionerMetadataKops")
+		}
 	}
 	utilityMetadataJSON, err := json.Marshal(cluster.UtilityMetadata)
 	if err != nil {
@@ -64,9 +78,22 @@ func (r *rawCluster) toCluster() (*model.Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
-	r.Cluster.ProvisionerMetadataKops, err = model.NewKopsMetadata(r.ProvisionerMetadataRaw)
-	if err != nil {
-		return nil, err
+	switch r.Cluster.Provisioner {
+	case model.ProvisionerEKS:
+		r.Cluster.ProvisionerMetadataEKS, err = model.NewEKSMetadata(r.ProvisionerMetadataRaw)
+		if err != nil {
+			return nil, err
+		}
+	case model.ProvisionerExternal:
+		r.Cluster.ProvisionerMetadataExternal, err = model.NewExternalMetadata(r.ProvisionerMetadataRaw)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		r.Cluster.ProvisionerMetadataKops, err = model.NewKopsMetadata(r.ProvisionerMetadataRaw)
+		if err != nil {
+			return nil, err
+		}
 	}
 	r.Cluster.UtilityMetadata, err = model.NewUtilityMetadata(r.UtilityMetadataRaw)
 	if err != nil {
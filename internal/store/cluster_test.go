@@ -142,6 +142,60 @@ func TestClusters(t *testing.T) {
 		require.Equal(t, cluster2, actualCluster2)
 	})
 
+	t.Run("eks cluster metadata", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		cluster1 := &model.Cluster{
+			Provider:               model.ProviderAWS,
+			Provisioner:            model.ProvisionerEKS,
+			ProviderMetadataAWS:    &model.AWSMetadata{Zones: []string{"zone1", "zone2"}},
+			ProvisionerMetadataEKS: &model.EKSMetadata{Name: "name1", Region: "region1", Version: "1.17"},
+			UtilityMetadata:        &model.UtilityMetadata{},
+			State:                  model.ClusterStateCreationRequested,
+		}
+
+		err := sqlStore.CreateCluster(cluster1, nil)
+		require.NoError(t, err)
+
+		actualCluster1, err := sqlStore.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, cluster1, actualCluster1)
+		require.Nil(t, actualCluster1.ProvisionerMetadataKops)
+
+		cluster1.ProvisionerMetadataEKS.Version = "1.18"
+		err = sqlStore.UpdateCluster(cluster1)
+		require.NoError(t, err)
+
+		actualCluster1, err = sqlStore.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, "1.18", actualCluster1.ProvisionerMetadataEKS.Version)
+	})
+
+	t.Run("external cluster metadata", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		cluster1 := &model.Cluster{
+			Provider:                    model.ProviderAWS,
+			Provisioner:                 model.ProvisionerExternal,
+			ProviderMetadataAWS:         &model.AWSMetadata{},
+			ProvisionerMetadataExternal: &model.ExternalMetadata{Name: "name1", KubeconfigSecret: "cloud-id-kubeconfig"},
+			UtilityMetadata:             &model.UtilityMetadata{},
+			State:                       model.ClusterStateProvisioningRequested,
+		}
+
+		err := sqlStore.CreateCluster(cluster1, nil)
+		require.NoError(t, err)
+
+		actualCluster1, err := sqlStore.GetCluster(cluster1.ID)
+		require.NoError(t, err)
+		require.Equal(t, cluster1, actualCluster1)
+		require.Nil(t, actualCluster1.ProvisionerMetadataKops)
+	})
+
 	t.Run("delete cluster", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := MakeTestSQLStore(t, logger)
@@ -252,6 +306,8 @@ func TestGetUnlockedClustersPendingWork(t *testing.T) {
 		model.ClusterStateDeletionFailed,
 		model.ClusterStateDeleted,
 		model.ClusterStateUpgradeFailed,
+		model.ClusterStateUpgradePaused,
+		model.ClusterStateUtilityUpgradeFailed,
 		model.ClusterStateStable,
 	}
 	for _, otherState := range otherStates {
@@ -20,8 +20,10 @@ func init() {
 	installationSelect = sq.
 		Select(
 			"ID", "OwnerID", "Version", "Image", "DNS", "Database", "Filestore", "Size",
-			"Affinity", "GroupID", "GroupSequence", "State", "License",
-			"MattermostEnvRaw", "SingleTenantDatabaseConfigRaw", "CreateAt", "DeleteAt",
+			"Affinity", "NodeGroup", "GroupID", "GroupSequence", "State", "License",
+			"MattermostEnvRaw", "SingleTenantDatabaseConfigRaw", "FilestoreMigrationRaw",
+			"NetworkIsolationRaw", "ResourceQuotaRaw", "CustomSizeRaw",
+			"CreateAt", "DeleteAt", "CredentialsRotatedAt",
 			"APISecurityLock", "LockAcquiredBy", "LockAcquiredAt",
 		).
 		From("Installation")
@@ -31,6 +33,10 @@ type rawInstallation struct {
 	*model.Installation
 	MattermostEnvRaw              []byte
 	SingleTenantDatabaseConfigRaw []byte
+	FilestoreMigrationRaw         []byte
+	NetworkIsolationRaw           []byte
+	ResourceQuotaRaw              []byte
+	CustomSizeRaw                 []byte
 }
 
 type rawInstallations []*rawInstallation
@@ -56,6 +62,42 @@ func (r *rawInstallation) toInstallation() (*model.Installation, error) {
 		r.Installation.SingleTenantDatabaseConfig = singleTenantDBConfig
 	}
 
+	if r.FilestoreMigrationRaw != nil {
+		filestoreMigration := &model.FilestoreMigration{}
+		err = json.Unmarshal(r.FilestoreMigrationRaw, filestoreMigration)
+		if err != nil {
+			return nil, err
+		}
+		r.Installation.FilestoreMigration = filestoreMigration
+	}
+
+	if r.NetworkIsolationRaw != nil {
+		networkIsolation := &model.NetworkIsolation{}
+		err = json.Unmarshal(r.NetworkIsolationRaw, networkIsolation)
+		if err != nil {
+			return nil, err
+		}
+		r.Installation.NetworkIsolation = networkIsolation
+	}
+
+	if r.ResourceQuotaRaw != nil {
+		resourceQuota := &model.InstallationResourceQuota{}
+		err = json.Unmarshal(r.ResourceQuotaRaw, resourceQuota)
+		if err != nil {
+			return nil, err
+		}
+		r.Installation.ResourceQuota = resourceQuota
+	}
+
+	if r.CustomSizeRaw != nil {
+		customSize := &model.InstallationCustomSize{}
+		err = json.Unmarshal(r.CustomSizeRaw, customSize)
+		if err != nil {
+			return nil, err
+		}
+		r.Installation.CustomSize = customSize
+	}
+
 	return r.Installation, nil
 }
 
@@ -286,25 +328,27 @@ func (sqlStore *SQLStore) createInstallation(db execer, installation *model.Inst
 	}
 
 	insertsMap := map[string]interface{}{
-		"ID":               installation.ID,
-		"OwnerID":          installation.OwnerID,
-		"GroupID":          installation.GroupID,
-		"GroupSequence":    nil,
-		"Version":          installation.Version,
-		"Image":            installation.Image,
-		"DNS":              installation.DNS,
-		"Database":         installation.Database,
-		"Filestore":        installation.Filestore,
-		"Size":             installation.Size,
-		"Affinity":         installation.Affinity,
-		"State":            installation.State,
-		"License":          installation.License,
-		"MattermostEnvRaw": []byte(envJSON),
-		"CreateAt":         installation.CreateAt,
-		"DeleteAt":         0,
-		"APISecurityLock":  installation.APISecurityLock,
-		"LockAcquiredBy":   nil,
-		"LockAcquiredAt":   0,
+		"ID":                   installation.ID,
+		"OwnerID":              installation.OwnerID,
+		"GroupID":              installation.GroupID,
+		"GroupSequence":        nil,
+		"Version":              installation.Version,
+		"Image":                installation.Image,
+		"DNS":                  installation.DNS,
+		"Database":             installation.Database,
+		"Filestore":            installation.Filestore,
+		"Size":                 installation.Size,
+		"Affinity":             installation.Affinity,
+		"NodeGroup":            installation.NodeGroup,
+		"State":                installation.State,
+		"License":              installation.License,
+		"MattermostEnvRaw":     []byte(envJSON),
+		"CreateAt":             installation.CreateAt,
+		"DeleteAt":             0,
+		"CredentialsRotatedAt": installation.CredentialsRotatedAt,
+		"APISecurityLock":      installation.APISecurityLock,
+		"LockAcquiredBy":       nil,
+		"LockAcquiredAt":       0,
 	}
 
 	singleTenantDBConfJSON, err := installation.SingleTenantDatabaseConfig.ToJSON()
@@ -317,6 +361,38 @@ func (sqlStore *SQLStore) createInstallation(db execer, installation *model.Inst
 		insertsMap["SingleTenantDatabaseConfigRaw"] = singleTenantDBConfJSON
 	}
 
+	filestoreMigrationJSON, err := installation.FilestoreMigration.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal FilestoreMigration")
+	}
+	if filestoreMigrationJSON != nil {
+		insertsMap["FilestoreMigrationRaw"] = filestoreMigrationJSON
+	}
+
+	networkIsolationJSON, err := installation.NetworkIsolation.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal NetworkIsolation")
+	}
+	if networkIsolationJSON != nil {
+		insertsMap["NetworkIsolationRaw"] = networkIsolationJSON
+	}
+
+	resourceQuotaJSON, err := installation.ResourceQuota.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal ResourceQuota")
+	}
+	if resourceQuotaJSON != nil {
+		insertsMap["ResourceQuotaRaw"] = resourceQuotaJSON
+	}
+
+	customSizeJSON, err := customSizeColumnValue(installation.CustomSize)
+	if err != nil {
+		return err
+	}
+	if customSizeJSON != nil {
+		insertsMap["CustomSizeRaw"] = customSizeJSON
+	}
+
 	_, err = sqlStore.execBuilder(db, sq.
 		Insert("Installation").
 		SetMap(insertsMap),
@@ -337,23 +413,35 @@ func (sqlStore *SQLStore) UpdateInstallation(installation *model.Installation) e
 	if err != nil {
 		return errors.Wrap(err, "unable to marshal MattermostEnv")
 	}
+	filestoreMigrationJSON, err := filestoreMigrationColumnValue(installation.FilestoreMigration)
+	if err != nil {
+		return err
+	}
+	customSizeJSON, err := customSizeColumnValue(installation.CustomSize)
+	if err != nil {
+		return err
+	}
 
 	_, err = sqlStore.execBuilder(sqlStore.db, sq.
 		Update("Installation").
 		SetMap(map[string]interface{}{
-			"OwnerID":          installation.OwnerID,
-			"GroupID":          installation.GroupID,
-			"GroupSequence":    installation.GroupSequence,
-			"Version":          installation.Version,
-			"Image":            installation.Image,
-			"DNS":              installation.DNS,
-			"Database":         installation.Database,
-			"Filestore":        installation.Filestore,
-			"Size":             installation.Size,
-			"Affinity":         installation.Affinity,
-			"License":          installation.License,
-			"MattermostEnvRaw": []byte(envJSON),
-			"State":            installation.State,
+			"OwnerID":               installation.OwnerID,
+			"GroupID":               installation.GroupID,
+			"GroupSequence":         installation.GroupSequence,
+			"Version":               installation.Version,
+			"Image":                 installation.Image,
+			"DNS":                   installation.DNS,
+			"Database":              installation.Database,
+			"Filestore":             installation.Filestore,
+			"Size":                  installation.Size,
+			"Affinity":              installation.Affinity,
+			"NodeGroup":             installation.NodeGroup,
+			"License":               installation.License,
+			"MattermostEnvRaw":      []byte(envJSON),
+			"FilestoreMigrationRaw": filestoreMigrationJSON,
+			"CustomSizeRaw":         customSizeJSON,
+			"CredentialsRotatedAt":  installation.CredentialsRotatedAt,
+			"State":                 installation.State,
 		}).
 		Where("ID = ?", installation.ID),
 	)
@@ -386,6 +474,75 @@ func (sqlStore *SQLStore) UpdateInstallationGroupSequence(installation *model.In
 	return nil
 }
 
+// UpdateInstallationFilestoreMigration updates the filestore and filestore
+// migration progress of the given installation. The installation may have
+// been merged with group config.
+func (sqlStore *SQLStore) UpdateInstallationFilestoreMigration(installation *model.Installation) error {
+	filestoreMigrationJSON, err := filestoreMigrationColumnValue(installation.FilestoreMigration)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Installation").
+		SetMap(map[string]interface{}{
+			"Filestore":             installation.Filestore,
+			"FilestoreMigrationRaw": filestoreMigrationJSON,
+		}).
+		Where("ID = ?", installation.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation filestore migration")
+	}
+
+	return nil
+}
+
+// UpdateInstallationCredentialsRotatedAt updates the time the credentials of
+// the given installation were last rotated. The installation may have been
+// merged with group config.
+func (sqlStore *SQLStore) UpdateInstallationCredentialsRotatedAt(installation *model.Installation) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Installation").
+		SetMap(map[string]interface{}{
+			"CredentialsRotatedAt": installation.CredentialsRotatedAt,
+		}).
+		Where("ID = ?", installation.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation credentials rotated at")
+	}
+
+	return nil
+}
+
+// filestoreMigrationColumnValue returns the value to store in the
+// FilestoreMigrationRaw column. For Postgres we cannot set typed nil as it is
+// not mapped to NULL value.
+func filestoreMigrationColumnValue(migration *model.FilestoreMigration) (interface{}, error) {
+	filestoreMigrationJSON, err := migration.ToJSON()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal FilestoreMigration")
+	}
+	if filestoreMigrationJSON == nil {
+		return nil, nil
+	}
+
+	return filestoreMigrationJSON, nil
+}
+
+func customSizeColumnValue(customSize *model.InstallationCustomSize) (interface{}, error) {
+	customSizeJSON, err := customSize.ToJSON()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal CustomSize")
+	}
+	if customSizeJSON == nil {
+		return nil, nil
+	}
+
+	return customSizeJSON, nil
+}
+
 // UpdateInstallationState updates the given installation to a new state.
 func (sqlStore *SQLStore) UpdateInstallationState(installation *model.Installation) error {
 	_, err := sqlStore.execBuilder(sqlStore.db, sq.
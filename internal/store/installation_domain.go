@@ -0,0 +1,133 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var installationDomainSelect sq.SelectBuilder
+
+func init() {
+	installationDomainSelect = sq.
+		Select("ID", "InstallationID", "Domain", "VerificationToken", "State",
+			"Message", "CheckedAt", "CreateAt", "DeleteAt").
+		From("InstallationDomain")
+}
+
+// GetInstallationDomain fetches the given installation domain by id.
+func (sqlStore *SQLStore) GetInstallationDomain(id string) (*model.InstallationDomain, error) {
+	var domain model.InstallationDomain
+	err := sqlStore.getBuilder(sqlStore.db, &domain,
+		installationDomainSelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get installation domain by id")
+	}
+
+	return &domain, nil
+}
+
+// GetInstallationDomains fetches the given page of installation domains. The
+// first page is 0.
+func (sqlStore *SQLStore) GetInstallationDomains(filter *model.InstallationDomainFilter) ([]*model.InstallationDomain, error) {
+	builder := installationDomainSelect.
+		OrderBy("CreateAt ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.InstallationID != "" {
+		builder = builder.Where("InstallationID = ?", filter.InstallationID)
+	}
+	if filter.Domain != "" {
+		builder = builder.Where("Domain = ?", filter.Domain)
+	}
+	if !filter.IncludeDeleted {
+		builder = builder.Where("DeleteAt = 0")
+	}
+
+	var domains []*model.InstallationDomain
+	err := sqlStore.selectBuilder(sqlStore.db, &domains, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for installation domains")
+	}
+
+	return domains, nil
+}
+
+// CreateInstallationDomain records the given installation domain to the
+// database, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateInstallationDomain(domain *model.InstallationDomain) error {
+	domain.ID = model.NewID()
+	domain.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("InstallationDomain").
+		SetMap(map[string]interface{}{
+			"ID":                domain.ID,
+			"InstallationID":    domain.InstallationID,
+			"Domain":            domain.Domain,
+			"VerificationToken": domain.VerificationToken,
+			"State":             domain.State,
+			"Message":           domain.Message,
+			"CheckedAt":         domain.CheckedAt,
+			"CreateAt":          domain.CreateAt,
+			"DeleteAt":          0,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create installation domain")
+	}
+
+	return nil
+}
+
+// UpdateInstallationDomain updates the status of the given installation
+// domain in the database.
+func (sqlStore *SQLStore) UpdateInstallationDomain(domain *model.InstallationDomain) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationDomain").
+		SetMap(map[string]interface{}{
+			"State":     domain.State,
+			"Message":   domain.Message,
+			"CheckedAt": domain.CheckedAt,
+		}).
+		Where("ID = ?", domain.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update installation domain")
+	}
+
+	return nil
+}
+
+// DeleteInstallationDomain marks the given installation domain as deleted,
+// but does not remove the record from the database.
+func (sqlStore *SQLStore) DeleteInstallationDomain(id string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationDomain").
+		SetMap(map[string]interface{}{
+			"State":    model.InstallationDomainStateDeleted,
+			"DeleteAt": GetMillis(),
+		}).
+		Where("ID = ?", id).
+		Where("DeleteAt = 0"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark installation domain as deleted")
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationDomains(t *testing.T) {
+	t.Run("get unknown installation domain", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		domain, err := sqlStore.GetInstallationDomain("unknown")
+		require.NoError(t, err)
+		require.Nil(t, domain)
+	})
+
+	t.Run("installation domains", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		installationID := model.NewID()
+
+		domain1 := &model.InstallationDomain{
+			InstallationID:    installationID,
+			Domain:            "chat.example.com",
+			VerificationToken: "token1",
+			State:             model.InstallationDomainStatePendingVerification,
+		}
+		domain2 := &model.InstallationDomain{
+			InstallationID:    installationID,
+			Domain:            "chat.example.org",
+			VerificationToken: "token2",
+			State:             model.InstallationDomainStatePendingVerification,
+		}
+		domain3 := &model.InstallationDomain{
+			InstallationID:    model.NewID(),
+			Domain:            "chat.example.net",
+			VerificationToken: "token3",
+			State:             model.InstallationDomainStatePendingVerification,
+		}
+
+		err := sqlStore.CreateInstallationDomain(domain1)
+		require.NoError(t, err)
+		time.Sleep(1 * time.Millisecond)
+		err = sqlStore.CreateInstallationDomain(domain2)
+		require.NoError(t, err)
+		time.Sleep(1 * time.Millisecond)
+		err = sqlStore.CreateInstallationDomain(domain3)
+		require.NoError(t, err)
+
+		actualDomain, err := sqlStore.GetInstallationDomain(domain1.ID)
+		require.NoError(t, err)
+		require.Equal(t, domain1, actualDomain)
+
+		domains, err := sqlStore.GetInstallationDomains(&model.InstallationDomainFilter{
+			InstallationID: installationID,
+			PerPage:        model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationDomain{domain1, domain2}, domains)
+
+		domains, err = sqlStore.GetInstallationDomains(&model.InstallationDomainFilter{
+			Domain:  "chat.example.net",
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationDomain{domain3}, domains)
+
+		domains, err = sqlStore.GetInstallationDomains(&model.InstallationDomainFilter{
+			Page:    1,
+			PerPage: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationDomain{domain3}, domains)
+
+		domain1.State = model.InstallationDomainStateActive
+		domain1.Message = "message"
+		domain1.CheckedAt = 10
+		err = sqlStore.UpdateInstallationDomain(domain1)
+		require.NoError(t, err)
+
+		actualDomain, err = sqlStore.GetInstallationDomain(domain1.ID)
+		require.NoError(t, err)
+		require.Equal(t, domain1, actualDomain)
+
+		err = sqlStore.DeleteInstallationDomain(domain2.ID)
+		require.NoError(t, err)
+
+		domains, err = sqlStore.GetInstallationDomains(&model.InstallationDomainFilter{
+			InstallationID: installationID,
+			PerPage:        model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationDomain{domain1}, domains)
+
+		domains, err = sqlStore.GetInstallationDomains(&model.InstallationDomainFilter{
+			InstallationID: installationID,
+			IncludeDeleted: true,
+			PerPage:        model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Len(t, domains, 2)
+		require.True(t, domains[1].IsDeleted())
+		require.Equal(t, model.InstallationDomainStateDeleted, domains[1].State)
+	})
+}
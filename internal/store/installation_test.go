@@ -49,6 +49,7 @@ func TestInstallations(t *testing.T) {
 		Filestore: model.InstallationFilestoreMinioOperator,
 		Size:      mmv1alpha1.Size100String,
 		Affinity:  model.InstallationAffinityIsolated,
+		NodeGroup: "memory",
 		GroupID:   &groupID1,
 		State:     model.InstallationStateCreationRequested,
 	}
@@ -77,6 +78,17 @@ func TestInstallations(t *testing.T) {
 		Affinity:  model.InstallationAffinityIsolated,
 		GroupID:   &groupID2,
 		State:     model.InstallationStateStable,
+		NetworkIsolation: &model.NetworkIsolation{
+			Profile: model.NetworkIsolationProfileRestricted,
+			EgressAllowList: []model.NetworkEgressRule{
+				{CIDR: "203.0.113.0/24", Protocol: "TCP", Ports: []int32{443}},
+			},
+		},
+		ResourceQuota: &model.InstallationResourceQuota{CPU: "4", Memory: "8Gi"},
+		CustomSize: &model.InstallationCustomSize{
+			App:      &model.InstallationComponentSize{Replicas: 3, MemoryRequest: "2Gi"},
+			Database: &model.InstallationComponentSize{StorageSize: "100Gi"},
+		},
 	}
 
 	err = sqlStore.CreateInstallation(installation2, nil)
@@ -636,6 +648,9 @@ func TestUpdateInstallation(t *testing.T) {
 		Affinity:  model.InstallationAffinityIsolated,
 		GroupID:   &groupID2,
 		State:     model.InstallationStateStable,
+		CustomSize: &model.InstallationCustomSize{
+			App: &model.InstallationComponentSize{Replicas: 3, MemoryRequest: "2Gi"},
+		},
 	}
 
 	err = sqlStore.CreateInstallation(installation2, nil)
@@ -649,10 +664,17 @@ func TestUpdateInstallation(t *testing.T) {
 	installation1.Affinity = model.InstallationAffinityIsolated
 	installation1.GroupID = &groupID2
 	installation1.State = model.InstallationStateDeletionRequested
+	installation1.CustomSize = &model.InstallationCustomSize{
+		App: &model.InstallationComponentSize{Replicas: 4},
+	}
 
 	err = sqlStore.UpdateInstallation(installation1)
 	require.NoError(t, err)
 
+	installation2.CustomSize = nil
+	err = sqlStore.UpdateInstallation(installation2)
+	require.NoError(t, err)
+
 	installation1.GroupID = &groupID1
 	err = sqlStore.UpdateInstallation(installation1)
 	require.NoError(t, err)
@@ -772,6 +794,90 @@ func TestUpdateInstallationState(t *testing.T) {
 	assert.NotEqual(t, storedInstallation.Version, installation1.Version)
 }
 
+func TestUpdateInstallationFilestoreMigration(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	installation1 := &model.Installation{
+		OwnerID:   model.NewID(),
+		Version:   "version",
+		DNS:       "dns3.example.com",
+		Database:  model.InstallationDatabaseMysqlOperator,
+		Filestore: model.InstallationFilestoreMinioOperator,
+		Size:      mmv1alpha1.Size100String,
+		Affinity:  model.InstallationAffinityIsolated,
+		State:     model.InstallationStateStable,
+	}
+
+	err := sqlStore.CreateInstallation(installation1, nil)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, storedInstallation.FilestoreMigration)
+
+	installation1.Filestore = model.InstallationFilestoreAwsS3
+	installation1.Version = "new-version-that-should-not-be-saved"
+	installation1.FilestoreMigration = &model.FilestoreMigration{
+		SourceFilestore:   model.InstallationFilestoreMinioOperator,
+		TargetFilestore:   model.InstallationFilestoreAwsS3,
+		SourceObjectCount: 10,
+		TargetObjectCount: 10,
+		CopyAttempts:      1,
+		StartAt:           1,
+	}
+
+	err = sqlStore.UpdateInstallationFilestoreMigration(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err = sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, model.InstallationFilestoreAwsS3, storedInstallation.Filestore)
+	assert.Equal(t, installation1.FilestoreMigration, storedInstallation.FilestoreMigration)
+	assert.NotEqual(t, installation1.Version, storedInstallation.Version)
+
+	installation1.FilestoreMigration = nil
+	err = sqlStore.UpdateInstallationFilestoreMigration(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err = sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, storedInstallation.FilestoreMigration)
+}
+
+func TestUpdateInstallationCredentialsRotatedAt(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	installation1 := &model.Installation{
+		OwnerID:  model.NewID(),
+		Version:  "version",
+		DNS:      "dns4.example.com",
+		Size:     mmv1alpha1.Size100String,
+		Affinity: model.InstallationAffinityIsolated,
+		State:    model.InstallationStateStable,
+	}
+
+	err := sqlStore.CreateInstallation(installation1, nil)
+	require.NoError(t, err)
+
+	storedInstallation, err := sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Zero(t, storedInstallation.CredentialsRotatedAt)
+
+	installation1.CredentialsRotatedAt = GetMillis()
+	installation1.Version = "new-version-that-should-not-be-saved"
+	err = sqlStore.UpdateInstallationCredentialsRotatedAt(installation1)
+	require.NoError(t, err)
+
+	storedInstallation, err = sqlStore.GetInstallation(installation1.ID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, installation1.CredentialsRotatedAt, storedInstallation.CredentialsRotatedAt)
+	assert.NotEqual(t, installation1.Version, storedInstallation.Version)
+}
+
 func TestDeleteInstallation(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := MakeTestSQLStore(t, logger)
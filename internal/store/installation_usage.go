@@ -0,0 +1,89 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+var installationUsageSelect sq.SelectBuilder
+
+func init() {
+	installationUsageSelect = sq.
+		Select("ID", "InstallationID", "FilestoreBytes", "FilestoreObjectCount", "DatabaseBytes", "CreateAt").
+		From("InstallationUsage")
+}
+
+// GetInstallationUsages fetches the given page of usage records. The records
+// are ordered from newest to oldest. The first page is 0.
+func (sqlStore *SQLStore) GetInstallationUsages(filter *model.InstallationUsageFilter) ([]*model.InstallationUsage, error) {
+	builder := installationUsageSelect.
+		OrderBy("CreateAt DESC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.InstallationID != "" {
+		builder = builder.Where("InstallationID = ?", filter.InstallationID)
+	}
+
+	var usages []*model.InstallationUsage
+	err := sqlStore.selectBuilder(sqlStore.db, &usages, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for installation usages")
+	}
+
+	return usages, nil
+}
+
+// GetLatestInstallationUsage fetches the most recent usage record of the given
+// installation.
+func (sqlStore *SQLStore) GetLatestInstallationUsage(installationID string) (*model.InstallationUsage, error) {
+	var usage model.InstallationUsage
+	err := sqlStore.getBuilder(sqlStore.db, &usage,
+		installationUsageSelect.
+			Where("InstallationID = ?", installationID).
+			OrderBy("CreateAt DESC").
+			Limit(1),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest installation usage")
+	}
+
+	return &usage, nil
+}
+
+// CreateInstallationUsage records the given usage to the database, assigning
+// it a unique ID.
+func (sqlStore *SQLStore) CreateInstallationUsage(usage *model.InstallationUsage) error {
+	usage.ID = model.NewID()
+	usage.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("InstallationUsage").
+		SetMap(map[string]interface{}{
+			"ID":                   usage.ID,
+			"InstallationID":       usage.InstallationID,
+			"FilestoreBytes":       usage.FilestoreBytes,
+			"FilestoreObjectCount": usage.FilestoreObjectCount,
+			"DatabaseBytes":        usage.DatabaseBytes,
+			"CreateAt":             usage.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create installation usage")
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationUsage(t *testing.T) {
+	t.Run("get unknown installation usage", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		usage, err := sqlStore.GetLatestInstallationUsage("unknown")
+		require.NoError(t, err)
+		require.Nil(t, usage)
+	})
+
+	t.Run("get installation usages", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		installationID := model.NewID()
+
+		usage1 := &model.InstallationUsage{
+			InstallationID:       installationID,
+			FilestoreBytes:       1024,
+			FilestoreObjectCount: 10,
+			DatabaseBytes:        2048,
+		}
+		usage2 := &model.InstallationUsage{
+			InstallationID:       installationID,
+			FilestoreBytes:       4096,
+			FilestoreObjectCount: 20,
+			DatabaseBytes:        8192,
+		}
+		usage3 := &model.InstallationUsage{
+			InstallationID: model.NewID(),
+			DatabaseBytes:  1,
+		}
+
+		err := sqlStore.CreateInstallationUsage(usage1)
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		err = sqlStore.CreateInstallationUsage(usage2)
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		err = sqlStore.CreateInstallationUsage(usage3)
+		require.NoError(t, err)
+
+		latest, err := sqlStore.GetLatestInstallationUsage(installationID)
+		require.NoError(t, err)
+		require.Equal(t, usage2, latest)
+
+		usages, err := sqlStore.GetInstallationUsages(&model.InstallationUsageFilter{
+			InstallationID: installationID,
+			PerPage:        model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationUsage{usage2, usage1}, usages)
+
+		usages, err = sqlStore.GetInstallationUsages(&model.InstallationUsageFilter{
+			InstallationID: installationID,
+			Page:           1,
+			PerPage:        1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationUsage{usage1}, usages)
+
+		usages, err = sqlStore.GetInstallationUsages(&model.InstallationUsageFilter{
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Len(t, usages, 3)
+	})
+}
@@ -1180,6 +1180,125 @@ var migrations = []migration{
 			return err
 		}
 
+		return nil
+	}},
+	{semver.MustParse("0.23.0"), semver.MustParse("0.24.0"), func(e execer) error {
+		// Add FilestoreMigrationRaw column for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN FilestoreMigrationRaw BYTEA NULL;
+				`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.24.0"), semver.MustParse("0.25.0"), func(e execer) error {
+		// Add InstallationUsage table.
+		_, err := e.Exec(`
+			CREATE TABLE InstallationUsage (
+				ID TEXT PRIMARY KEY,
+				InstallationID TEXT NOT NULL,
+				FilestoreBytes BIGINT NOT NULL,
+				FilestoreObjectCount BIGINT NOT NULL,
+				DatabaseBytes BIGINT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+			CREATE INDEX InstallationUsage_InstallationID_CreateAt ON InstallationUsage (InstallationID, CreateAt);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.25.0"), semver.MustParse("0.26.0"), func(e execer) error {
+		// Add CredentialsRotatedAt column to Installation.
+		_, err := e.Exec(`ALTER TABLE Installation ADD COLUMN CredentialsRotatedAt BIGINT NOT NULL DEFAULT 0;`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.26.0"), semver.MustParse("0.27.0"), func(e execer) error {
+		// Add NodeGroup column to Installation.
+		_, err := e.Exec(`ALTER TABLE Installation ADD COLUMN NodeGroup TEXT NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.27.0"), semver.MustParse("0.28.0"), func(e execer) error {
+		// Add InstallationDomain table.
+		_, err := e.Exec(`
+			CREATE TABLE InstallationDomain (
+				ID TEXT PRIMARY KEY,
+				InstallationID TEXT NOT NULL,
+				Domain TEXT NOT NULL,
+				VerificationToken TEXT NOT NULL,
+				State TEXT NOT NULL,
+				Message TEXT NOT NULL,
+				CheckedAt BIGINT NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				DeleteAt BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+			CREATE INDEX InstallationDomain_InstallationID ON InstallationDomain (InstallationID);
+		`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.28.0"), semver.MustParse("0.29.0"), func(e execer) error {
+		// Add NetworkIsolationRaw column for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN NetworkIsolationRaw BYTEA NULL;
+				`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.29.0"), semver.MustParse("0.30.0"), func(e execer) error {
+		// Add ResourceQuotaRaw column for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN ResourceQuotaRaw BYTEA NULL;
+				`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.30.0"), semver.MustParse("0.31.0"), func(e execer) error {
+		// Add CustomSizeRaw column for installations.
+		_, err := e.Exec(`
+				ALTER TABLE Installation
+				ADD COLUMN CustomSizeRaw BYTEA NULL;
+				`)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}},
 }
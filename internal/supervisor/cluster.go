@@ -5,14 +5,21 @@
 package supervisor
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/internal/webhook"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+// vpcPoolLowCapacity is the number of claimable VPCs below which a low
+// capacity webhook is sent after a cluster claims a VPC.
+const vpcPoolLowCapacity = 2
+
 // clusterStore abstracts the database operations required to query clusters.
 type clusterStore interface {
 	GetCluster(clusterID string) (*model.Cluster, error)
@@ -23,6 +30,8 @@ type clusterStore interface {
 	UnlockCluster(clusterID string, lockerID string, force bool) (bool, error)
 	DeleteCluster(clusterID string) error
 
+	GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
 }
 
@@ -32,9 +41,14 @@ type clusterProvisioner interface {
 	CreateCluster(cluster *model.Cluster, aws aws.AWS) error
 	ProvisionCluster(cluster *model.Cluster, aws aws.AWS) error
 	UpgradeCluster(cluster *model.Cluster, aws aws.AWS) error
+	UpgradeClusterUtility(cluster *model.Cluster, aws aws.AWS) error
 	ResizeCluster(cluster *model.Cluster) error
 	DeleteCluster(cluster *model.Cluster, aws aws.AWS) error
-	RefreshKopsMetadata(cluster *model.Cluster) error
+	RefreshClusterMetadata(cluster *model.Cluster) error
+	GetClusterInstanceGroups(cluster *model.Cluster) ([]string, []string, error)
+	RollingUpdateClusterInstanceGroups(cluster *model.Cluster, igNames []string) error
+	RevertClusterUpgrade(cluster *model.Cluster) error
+	CheckClusterHealth(cluster *model.Cluster, clusterInstallations []*model.ClusterInstallation) ([]string, error)
 }
 
 // ClusterSupervisor finds clusters pending work and effects the required changes.
@@ -153,6 +167,10 @@ func (s *ClusterSupervisor) transitionCluster(cluster *model.Cluster, logger log
 		return s.provisionCluster(cluster, logger)
 	case model.ClusterStateUpgradeRequested:
 		return s.upgradeCluster(cluster, logger)
+	case model.ClusterStateUpgradeAbortRequested:
+		return s.abortClusterUpgrade(cluster, logger)
+	case model.ClusterStateUtilityUpgradeRequested:
+		return s.upgradeClusterUtility(cluster, logger)
 	case model.ClusterStateResizeRequested:
 		return s.resizeCluster(cluster, logger)
 	case model.ClusterStateRefreshMetadata:
@@ -182,10 +200,67 @@ func (s *ClusterSupervisor) createCluster(cluster *model.Cluster, logger log.Fie
 		return model.ClusterStateCreationFailed
 	}
 
+	err = s.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record created cluster")
+		return model.ClusterStateCreationFailed
+	}
+
 	logger.Info("Finished creating cluster")
+	s.checkVpcPoolCapacity(cluster, logger)
+
 	return s.provisionCluster(cluster, logger)
 }
 
+// checkVpcPoolCapacity warns when a cluster claimed one of the last VPCs of
+// the pre-tagged VPC pool of its region and account.
+func (s *ClusterSupervisor) checkVpcPoolCapacity(cluster *model.Cluster, logger log.FieldLogger) {
+	if len(cluster.Provisioner) != 0 && cluster.Provisioner != model.ProvisionerKops {
+		return
+	}
+	if cluster.ProvisionerMetadataKops != nil && cluster.ProvisionerMetadataKops.ManagedVPC {
+		return
+	}
+
+	awsClient, err := s.aws.ClientForCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get AWS client to check VPC pool capacity")
+		return
+	}
+	pool, err := awsClient.GetVpcPool(logger)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to check VPC pool capacity")
+		return
+	}
+	// An empty pool means that clusters don't claim pre-tagged VPCs.
+	if len(pool) == 0 {
+		return
+	}
+
+	claimable := model.CountClaimableVPCs(pool)
+	if claimable >= vpcPoolLowCapacity {
+		return
+	}
+
+	logger.Warnf("Only %d of %d VPCs are left to be claimed by new clusters", claimable, len(pool))
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeVPCPool,
+		ID:        cluster.ID,
+		NewState:  model.VPCPoolStateLowCapacity,
+		OldState:  "n/a",
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{
+			"claimable": strconv.Itoa(claimable),
+			"total":     strconv.Itoa(len(pool)),
+		},
+	}
+	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
+
 func (s *ClusterSupervisor) provisionCluster(cluster *model.Cluster, logger log.FieldLogger) string {
 	err := s.provisioner.ProvisionCluster(cluster, s.aws)
 	if err != nil {
@@ -198,6 +273,10 @@ func (s *ClusterSupervisor) provisionCluster(cluster *model.Cluster, logger log.
 }
 
 func (s *ClusterSupervisor) upgradeCluster(cluster *model.Cluster, logger log.FieldLogger) string {
+	if cluster.ProvisionerMetadataKops != nil && cluster.ProvisionerMetadataKops.ChangeRequest.IsStagedUpgrade() {
+		return s.upgradeClusterInStages(cluster, logger)
+	}
+
 	err := s.provisioner.UpgradeCluster(cluster, s.aws)
 	if err != nil {
 		logger.WithError(err).Error("Failed to upgrade cluster")
@@ -208,6 +287,165 @@ func (s *ClusterSupervisor) upgradeCluster(cluster *model.Cluster, logger log.Fi
 	return s.refreshClusterMetadata(cluster, logger)
 }
 
+// upgradeClusterInStages applies the upgraded cluster spec and then rolls one
+// stage of instance groups per supervisor cycle. The cluster health is checked
+// after every stage and before every later stage, so that a resumed upgrade
+// is paused again if the cluster didn't recover.
+func (s *ClusterSupervisor) upgradeClusterInStages(cluster *model.Cluster, logger log.FieldLogger) string {
+	changeRequest := cluster.ProvisionerMetadataKops.ChangeRequest
+	if changeRequest.UpgradeProgress == nil {
+		changeRequest.UpgradeProgress = &model.KopsUpgradeProgress{}
+	}
+	strategy := changeRequest.UpgradeStrategy
+	progress := changeRequest.UpgradeProgress
+
+	if !progress.SpecApplied {
+		err := s.provisioner.UpgradeCluster(cluster, s.aws)
+		if err != nil {
+			logger.WithError(err).Error("Failed to upgrade cluster")
+			return model.ClusterStateUpgradeFailed
+		}
+
+		progress.SpecApplied = true
+		err = s.store.UpdateCluster(cluster)
+		if err != nil {
+			logger.WithError(err).Error("Failed to record cluster upgrade progress")
+			return model.ClusterStateUpgradeFailed
+		}
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	if now < strategy.NextStageAt(progress) {
+		logger.Debug("Waiting before starting the next upgrade stage")
+		return model.ClusterStateUpgradeRequested
+	}
+
+	masterIGs, nodeIGs, err := s.provisioner.GetClusterInstanceGroups(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster instance groups")
+		return model.ClusterStateUpgradeFailed
+	}
+
+	stage := strategy.NextStage(masterIGs, nodeIGs, progress)
+	if len(stage) == 0 {
+		logger.Info("Finished upgrading cluster")
+		return s.refreshClusterMetadata(cluster, logger)
+	}
+
+	if len(progress.UpgradedInstanceGroups) != 0 {
+		issues := s.clusterHealthIssues(cluster, logger)
+		if len(issues) != 0 {
+			for _, issue := range issues {
+				cluster.ProvisionerMetadataKops.AddWarning(issue)
+			}
+			logger.WithField("issues", issues).Warnf("Cluster health is degraded before rolling instance groups %v; pausing upgrade", stage)
+
+			err = s.store.UpdateCluster(cluster)
+			if err != nil {
+				logger.WithError(err).Error("Failed to record cluster upgrade warnings")
+				return model.ClusterStateUpgradeFailed
+			}
+
+			return model.ClusterStateUpgradePaused
+		}
+	}
+
+	err = s.provisioner.RollingUpdateClusterInstanceGroups(cluster, stage)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to roll instance groups %v", stage)
+		return model.ClusterStateUpgradeFailed
+	}
+	progress.UpgradedInstanceGroups = append(progress.UpgradedInstanceGroups, stage...)
+	progress.LastStageCompletedAt = time.Now().UnixNano() / int64(time.Millisecond)
+
+	newState := model.ClusterStateUpgradeRequested
+	issues := s.clusterHealthIssues(cluster, logger)
+	if len(issues) != 0 {
+		for _, issue := range issues {
+			cluster.ProvisionerMetadataKops.AddWarning(issue)
+		}
+		logger.WithField("issues", issues).Warnf("Cluster health degraded after rolling instance groups %v; pausing upgrade", stage)
+		newState = model.ClusterStateUpgradePaused
+	}
+
+	err = s.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record cluster upgrade progress")
+		return model.ClusterStateUpgradeFailed
+	}
+
+	logger.Infof("Finished upgrade stage for instance groups %v", stage)
+	return newState
+}
+
+// clusterHealthIssues returns the health issues of the cluster. A failed
+// health check is reported as an issue as well.
+func (s *ClusterSupervisor) clusterHealthIssues(cluster *model.Cluster, logger log.FieldLogger) []string {
+	issues, err := s.checkClusterHealth(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to check cluster health")
+		issues = append(issues, fmt.Sprintf("Failed to check cluster health: %s", err))
+	}
+
+	return issues
+}
+
+// abortClusterUpgrade reverts a paused or failed staged upgrade. The change
+// request and warnings are kept when the revert fails so that the upgrade can
+// be inspected and the abort requested again.
+func (s *ClusterSupervisor) abortClusterUpgrade(cluster *model.Cluster, logger log.FieldLogger) string {
+	err := s.provisioner.RevertClusterUpgrade(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to revert cluster upgrade")
+		return model.ClusterStateUpgradeFailed
+	}
+
+	logger.Info("Finished reverting cluster upgrade")
+	return s.refreshClusterMetadata(cluster, logger)
+}
+
+func (s *ClusterSupervisor) checkClusterHealth(cluster *model.Cluster) ([]string, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		ClusterID: cluster.ID,
+		PerPage:   model.AllPerPage,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster installations")
+	}
+
+	return s.provisioner.CheckClusterHealth(cluster, clusterInstallations)
+}
+
+// upgradeClusterUtility applies the pending single utility upgrade of the
+// cluster. The provisioner rolls the utility back on failure, so the cluster
+// is saved in both cases to record the resulting utility versions.
+func (s *ClusterSupervisor) upgradeClusterUtility(cluster *model.Cluster, logger log.FieldLogger) string {
+	upgradeErr := s.provisioner.UpgradeClusterUtility(cluster, s.aws)
+	if cluster.UtilityUpgrade() != nil {
+		// The upgrade failed before the utility was deployed, so the desired
+		// version is restored to match the cluster.
+		err := cluster.RevertUtilityUpgrade()
+		if err != nil {
+			logger.WithError(err).Error("Failed to restore previous cluster utility version")
+			return model.ClusterStateUtilityUpgradeFailed
+		}
+	}
+
+	err := s.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record updated cluster utility versions")
+		return model.ClusterStateUtilityUpgradeFailed
+	}
+
+	if upgradeErr != nil {
+		logger.WithError(upgradeErr).Error("Failed to upgrade cluster utility")
+		return model.ClusterStateUtilityUpgradeFailed
+	}
+
+	logger.Info("Finished upgrading cluster utility")
+	return model.ClusterStateStable
+}
+
 func (s *ClusterSupervisor) resizeCluster(cluster *model.Cluster, logger log.FieldLogger) string {
 	err := s.provisioner.ResizeCluster(cluster)
 	if err != nil {
@@ -224,8 +462,12 @@ func (s *ClusterSupervisor) refreshClusterMetadata(cluster *model.Cluster, logge
 		cluster.ProvisionerMetadataKops.ClearChangeRequest()
 		cluster.ProvisionerMetadataKops.ClearWarnings()
 	}
+	if cluster.ProvisionerMetadataEKS != nil {
+		cluster.ProvisionerMetadataEKS.ClearChangeRequest()
+		cluster.ProvisionerMetadataEKS.ClearWarnings()
+	}
 
-	err := s.provisioner.RefreshKopsMetadata(cluster)
+	err := s.provisioner.RefreshClusterMetadata(cluster)
 	if err != nil {
 		logger.WithError(err).Error("Failed to refresh cluster")
 		return model.ClusterStateRefreshMetadata
@@ -5,13 +5,18 @@
 package supervisor_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-cloud/internal/store"
 	"github.com/mattermost/mattermost-cloud/internal/supervisor"
 	"github.com/mattermost/mattermost-cloud/internal/testlib"
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -56,11 +61,24 @@ func (s *mockClusterStore) DeleteCluster(clusterID string) error {
 	return nil
 }
 
+func (s *mockClusterStore) GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error) {
+	return nil, nil
+}
+
 func (s *mockClusterStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error) {
 	return nil, nil
 }
 
-type mockClusterProvisioner struct{}
+type mockClusterProvisioner struct {
+	MasterInstanceGroups []string
+	NodeInstanceGroups   []string
+	RolledInstanceGroups [][]string
+	HealthIssues         []string
+	UtilityUpgradeError  error
+	UtilitySetupError    error
+	RevertCalls          int
+	RevertError          error
+}
 
 func (p *mockClusterProvisioner) PrepareCluster(cluster *model.Cluster) bool {
 	return true
@@ -78,6 +96,23 @@ func (p *mockClusterProvisioner) UpgradeCluster(cluster *model.Cluster, aws aws.
 	return nil
 }
 
+func (p *mockClusterProvisioner) UpgradeClusterUtility(cluster *model.Cluster, aws aws.AWS) error {
+	if p.UtilitySetupError != nil {
+		return p.UtilitySetupError
+	}
+
+	upgrade := cluster.UtilityUpgrade()
+	cluster.ClearUtilityUpgrade()
+	if p.UtilityUpgradeError != nil {
+		cluster.SetUtilityDesiredVersions(map[string]string{upgrade.Name: upgrade.PreviousVersion})
+		cluster.SetUtilityActualVersion(upgrade.Name, upgrade.PreviousVersion)
+		return p.UtilityUpgradeError
+	}
+
+	cluster.SetUtilityActualVersion(upgrade.Name, upgrade.Version)
+	return nil
+}
+
 func (p *mockClusterProvisioner) ResizeCluster(cluster *model.Cluster) error {
 	return nil
 }
@@ -86,10 +121,28 @@ func (p *mockClusterProvisioner) DeleteCluster(cluster *model.Cluster, aws aws.A
 	return nil
 }
 
-func (p *mockClusterProvisioner) RefreshKopsMetadata(cluster *model.Cluster) error {
+func (p *mockClusterProvisioner) RefreshClusterMetadata(cluster *model.Cluster) error {
 	return nil
 }
 
+func (p *mockClusterProvisioner) GetClusterInstanceGroups(cluster *model.Cluster) ([]string, []string, error) {
+	return p.MasterInstanceGroups, p.NodeInstanceGroups, nil
+}
+
+func (p *mockClusterProvisioner) RollingUpdateClusterInstanceGroups(cluster *model.Cluster, igNames []string) error {
+	p.RolledInstanceGroups = append(p.RolledInstanceGroups, igNames)
+	return nil
+}
+
+func (p *mockClusterProvisioner) RevertClusterUpgrade(cluster *model.Cluster) error {
+	p.RevertCalls++
+	return p.RevertError
+}
+
+func (p *mockClusterProvisioner) CheckClusterHealth(cluster *model.Cluster, clusterInstallations []*model.ClusterInstallation) ([]string, error) {
+	return p.HealthIssues, nil
+}
+
 func TestClusterSupervisorDo(t *testing.T) {
 	t.Run("no clusters pending work", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
@@ -118,7 +171,7 @@ func TestClusterSupervisorDo(t *testing.T) {
 		require.NoError(t, err)
 
 		<-mockStore.UnlockChan
-		require.Equal(t, 3, mockStore.UpdateClusterCalls)
+		require.Equal(t, 4, mockStore.UpdateClusterCalls)
 	})
 }
 
@@ -159,6 +212,55 @@ func TestClusterSupervisorSupervise(t *testing.T) {
 		})
 	}
 
+	t.Run("creation with low VPC pool capacity", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+
+		payloads := make(chan *model.WebhookPayload, 10)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			payload, err := model.WebhookPayloadFromReader(r.Body)
+			require.NoError(t, err)
+			payloads <- payload
+		}))
+		defer ts.Close()
+		err := sqlStore.CreateWebhook(&model.Webhook{OwnerID: "owner", URL: ts.URL})
+		require.NoError(t, err)
+
+		awsClient := &mockAWS{
+			VpcPool: []*model.VPC{
+				{ID: "vpc1", ClusterID: "cluster1", Valid: true},
+				{ID: "vpc2", Available: true, Valid: true},
+				{ID: "vpc3", Available: true, Error: "private subnet list is empty"},
+			},
+		}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, &mockClusterProvisioner{}, awsClient, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{},
+			State:                   model.ClusterStateCreationRequested,
+		}
+		err = sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		for {
+			select {
+			case payload := <-payloads:
+				if payload.Type != model.TypeVPCPool {
+					continue
+				}
+				assert.Equal(t, cluster.ID, payload.ID)
+				assert.Equal(t, model.VPCPoolStateLowCapacity, payload.NewState)
+				assert.Equal(t, map[string]string{"claimable": "1", "total": "3"}, payload.ExtraData)
+				return
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "expected a low VPC pool capacity webhook")
+			}
+		}
+	})
+
 	t.Run("state has changed since cluster was selected to be worked on", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
@@ -182,4 +284,306 @@ func TestClusterSupervisorSupervise(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, model.ClusterStateDeletionRequested, cluster.State)
 	})
+
+	t.Run("staged upgrade", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockClusterProvisioner{
+			MasterInstanceGroups: []string{"master-a"},
+			NodeInstanceGroups:   []string{"nodes-a", "nodes-b", "nodes-c"},
+		}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider: model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{
+				ChangeRequest: &model.KopsMetadataRequestedState{
+					UpgradeStrategy: &model.KopsUpgradeStrategy{BatchSize: 2},
+					UpgradeProgress: &model.KopsUpgradeProgress{},
+				},
+			},
+			State: model.ClusterStateUpgradeRequested,
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		expectedStages := [][]string{{"master-a"}, {"nodes-a"}, {"nodes-b", "nodes-c"}}
+		for i := range expectedStages {
+			supervisor.Supervise(cluster)
+
+			cluster, err = sqlStore.GetCluster(cluster.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.ClusterStateUpgradeRequested, cluster.State)
+			require.Equal(t, expectedStages[:i+1], provisioner.RolledInstanceGroups)
+			require.True(t, cluster.ProvisionerMetadataKops.ChangeRequest.UpgradeProgress.SpecApplied)
+		}
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Equal(t, expectedStages, provisioner.RolledInstanceGroups)
+	})
+
+	t.Run("staged upgrade waits between stages", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockClusterProvisioner{
+			NodeInstanceGroups: []string{"nodes-a", "nodes-b"},
+		}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider: model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{
+				ChangeRequest: &model.KopsMetadataRequestedState{
+					UpgradeStrategy: &model.KopsUpgradeStrategy{BatchSize: 1, PauseSeconds: 3600},
+					UpgradeProgress: &model.KopsUpgradeProgress{},
+				},
+			},
+			State: model.ClusterStateUpgradeRequested,
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateUpgradeRequested, cluster.State)
+		require.Equal(t, [][]string{{"nodes-a"}}, provisioner.RolledInstanceGroups)
+	})
+
+	t.Run("staged upgrade pauses when health degrades", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockClusterProvisioner{
+			NodeInstanceGroups: []string{"nodes-a", "nodes-b"},
+			HealthIssues:       []string{"node ip-10-0-0-1 is not ready"},
+		}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider: model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{
+				ChangeRequest: &model.KopsMetadataRequestedState{
+					UpgradeStrategy: &model.KopsUpgradeStrategy{BatchSize: 1},
+					UpgradeProgress: &model.KopsUpgradeProgress{},
+				},
+			},
+			State: model.ClusterStateUpgradeRequested,
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateUpgradePaused, cluster.State)
+		require.Equal(t, [][]string{{"nodes-a"}}, provisioner.RolledInstanceGroups)
+		require.Contains(t, cluster.ProvisionerMetadataKops.Warnings, "node ip-10-0-0-1 is not ready")
+		require.Equal(t, []string{"nodes-a"}, cluster.ProvisionerMetadataKops.ChangeRequest.UpgradeProgress.UpgradedInstanceGroups)
+	})
+
+	t.Run("resumed staged upgrade pauses again while health is degraded", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockClusterProvisioner{
+			NodeInstanceGroups: []string{"nodes-a", "nodes-b"},
+			HealthIssues:       []string{"node ip-10-0-0-1 is not ready"},
+		}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider: model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{
+				ChangeRequest: &model.KopsMetadataRequestedState{
+					UpgradeStrategy: &model.KopsUpgradeStrategy{BatchSize: 1},
+					UpgradeProgress: &model.KopsUpgradeProgress{
+						SpecApplied:            true,
+						UpgradedInstanceGroups: []string{"nodes-a"},
+					},
+				},
+			},
+			State: model.ClusterStateUpgradeRequested,
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateUpgradePaused, cluster.State)
+		require.Empty(t, provisioner.RolledInstanceGroups)
+		require.Contains(t, cluster.ProvisionerMetadataKops.Warnings, "node ip-10-0-0-1 is not ready")
+	})
+
+	t.Run("staged upgrade aborted", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockClusterProvisioner{}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider: model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{
+				Version: "1.18.10",
+				ChangeRequest: &model.KopsMetadataRequestedState{
+					Version:         "1.19.4",
+					UpgradeStrategy: &model.KopsUpgradeStrategy{BatchSize: 1},
+					UpgradeProgress: &model.KopsUpgradeProgress{
+						SpecApplied:            true,
+						UpgradedInstanceGroups: []string{"nodes-a"},
+					},
+				},
+				Warnings: []string{"node ip-10-0-0-1 is not ready"},
+			},
+			State: model.ClusterStateUpgradeAbortRequested,
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Equal(t, 1, provisioner.RevertCalls)
+		require.Nil(t, cluster.ProvisionerMetadataKops.ChangeRequest)
+	})
+
+	t.Run("staged upgrade abort fails", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockClusterProvisioner{
+			RevertError: errors.New("kops update failed"),
+		}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider: model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{
+				Version: "1.18.10",
+				ChangeRequest: &model.KopsMetadataRequestedState{
+					Version:         "1.19.4",
+					UpgradeStrategy: &model.KopsUpgradeStrategy{BatchSize: 1},
+					UpgradeProgress: &model.KopsUpgradeProgress{SpecApplied: true},
+				},
+				Warnings: []string{"node ip-10-0-0-1 is not ready"},
+			},
+			State: model.ClusterStateUpgradeAbortRequested,
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateUpgradeFailed, cluster.State)
+		require.NotNil(t, cluster.ProvisionerMetadataKops.ChangeRequest)
+		require.Equal(t, []string{"node ip-10-0-0-1 is not ready"}, cluster.ProvisionerMetadataKops.Warnings)
+	})
+
+	t.Run("utility upgrade", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, &mockClusterProvisioner{}, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{},
+			State:                   model.ClusterStateUtilityUpgradeRequested,
+		}
+		err := cluster.SetUtilityDesiredVersions(map[string]string{model.NginxCanonicalName: "2.15.0"})
+		require.NoError(t, err)
+		err = cluster.SetUtilityUpgrade(model.NginxCanonicalName, "2.16.0")
+		require.NoError(t, err)
+		err = sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Nil(t, cluster.UtilityUpgrade())
+		version, err := cluster.DesiredUtilityVersion(model.NginxCanonicalName)
+		require.NoError(t, err)
+		require.Equal(t, "2.16.0", version)
+		version, err = cluster.ActualUtilityVersion(model.NginxCanonicalName)
+		require.NoError(t, err)
+		require.Equal(t, "2.16.0", version)
+	})
+
+	t.Run("utility upgrade rolled back", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockClusterProvisioner{
+			UtilityUpgradeError: errors.New("helm upgrade failed"),
+		}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{},
+			State:                   model.ClusterStateUtilityUpgradeRequested,
+		}
+		err := cluster.SetUtilityDesiredVersions(map[string]string{model.NginxCanonicalName: "2.15.0"})
+		require.NoError(t, err)
+		err = cluster.SetUtilityUpgrade(model.NginxCanonicalName, "2.16.0")
+		require.NoError(t, err)
+		err = sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateUtilityUpgradeFailed, cluster.State)
+		require.Nil(t, cluster.UtilityUpgrade())
+		version, err := cluster.DesiredUtilityVersion(model.NginxCanonicalName)
+		require.NoError(t, err)
+		require.Equal(t, "2.15.0", version)
+		version, err = cluster.ActualUtilityVersion(model.NginxCanonicalName)
+		require.NoError(t, err)
+		require.Equal(t, "2.15.0", version)
+	})
+
+	t.Run("utility upgrade fails before deploying", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockClusterProvisioner{
+			UtilitySetupError: errors.New("failed to export kubecfg"),
+		}
+		supervisor := supervisor.NewClusterSupervisor(sqlStore, provisioner, &mockAWS{}, "instanceID", logger)
+
+		cluster := &model.Cluster{
+			Provider:                model.ProviderAWS,
+			ProvisionerMetadataKops: &model.KopsMetadata{},
+			State:                   model.ClusterStateUtilityUpgradeRequested,
+		}
+		err := cluster.SetUtilityDesiredVersions(map[string]string{model.NginxCanonicalName: "2.15.0"})
+		require.NoError(t, err)
+		err = cluster.SetUtilityUpgrade(model.NginxCanonicalName, "2.16.0")
+		require.NoError(t, err)
+		err = sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateUtilityUpgradeFailed, cluster.State)
+		require.Nil(t, cluster.UtilityUpgrade())
+		version, err := cluster.DesiredUtilityVersion(model.NginxCanonicalName)
+		require.NoError(t, err)
+		require.Equal(t, "2.15.0", version)
+	})
 }
@@ -0,0 +1,133 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// credentialRotationStore abstracts the database operations required by the
+// credential rotation supervisor.
+type credentialRotationStore interface {
+	GetInstallations(filter *model.InstallationFilter, includeGroupConfig, includeGroupConfigOverrides bool) ([]*model.Installation, error)
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+	UpdateInstallationState(*model.Installation) error
+	LockInstallation(installationID, lockerID string) (bool, error)
+	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+}
+
+// CredentialRotationSupervisor finds stable installations whose database and
+// filestore credentials are older than the rotation interval and requests a
+// credential rotation for them.
+type CredentialRotationSupervisor struct {
+	store      credentialRotationStore
+	instanceID string
+	interval   time.Duration
+	logger     log.FieldLogger
+}
+
+// NewCredentialRotationSupervisor creates a new CredentialRotationSupervisor.
+func NewCredentialRotationSupervisor(store credentialRotationStore, instanceID string, interval time.Duration, logger log.FieldLogger) *CredentialRotationSupervisor {
+	return &CredentialRotationSupervisor{
+		store:      store,
+		instanceID: instanceID,
+		interval:   interval,
+		logger:     logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the credential rotation
+// supervisor.
+func (s *CredentialRotationSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down credential rotation supervisor")
+}
+
+// Do looks for stable installations with expired credentials and requests a
+// credential rotation for each of them.
+func (s *CredentialRotationSupervisor) Do() error {
+	installations, err := s.store.GetInstallations(&model.InstallationFilter{
+		PerPage: model.AllPerPage,
+	}, false, false)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for installations")
+		return nil
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	for _, installation := range installations {
+		if installation.State != model.InstallationStateStable {
+			continue
+		}
+		if !s.credentialsExpired(installation, now) {
+			continue
+		}
+		s.Supervise(installation)
+	}
+
+	return nil
+}
+
+// Supervise moves the given installation to the credential rotation
+// requested state.
+func (s *CredentialRotationSupervisor) Supervise(installation *model.Installation) {
+	logger := s.logger.WithFields(log.Fields{
+		"installation": installation.ID,
+	})
+
+	lock := newInstallationLock(installation.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	// Fetch the installation again now that we hold the lock.
+	installation, err := s.store.GetInstallation(installation.ID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation to request credential rotation")
+		return
+	}
+	if installation == nil || installation.State != model.InstallationStateStable {
+		return
+	}
+
+	oldState := installation.State
+	installation.State = model.InstallationStateCredentialRotationRequested
+	err = s.store.UpdateInstallationState(installation)
+	if err != nil {
+		logger.WithError(err).Error("Unable to set new installation state")
+		return
+	}
+
+	logger.Info("Requested installation credential rotation")
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallation,
+		ID:        installation.ID,
+		NewState:  installation.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+	}
+	err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
+
+// credentialsExpired returns true if the credentials of the installation were
+// created or last rotated longer than the rotation interval ago.
+func (s *CredentialRotationSupervisor) credentialsExpired(installation *model.Installation, now int64) bool {
+	issuedAt := installation.CreateAt
+	if installation.CredentialsRotatedAt > issuedAt {
+		issuedAt = installation.CredentialsRotatedAt
+	}
+
+	return now-issuedAt >= s.interval.Milliseconds()
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialRotationSupervisor(t *testing.T) {
+	createInstallation := func(t *testing.T, sqlStore *store.SQLStore, state string) *model.Installation {
+		t.Helper()
+
+		installation := &model.Installation{
+			OwnerID:  model.NewID(),
+			Version:  "version",
+			DNS:      model.NewID() + ".example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			State:    state,
+		}
+		err := sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		return installation
+	}
+
+	expectInstallationState := func(t *testing.T, sqlStore *store.SQLStore, installation *model.Installation, expectedState string) {
+		t.Helper()
+
+		installation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Equal(t, expectedState, installation.State)
+	}
+
+	t.Run("expired credentials", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		stable := createInstallation(t, sqlStore, model.InstallationStateStable)
+		updating := createInstallation(t, sqlStore, model.InstallationStateUpdateRequested)
+
+		// A zero interval expires every installation immediately.
+		supervisor := supervisor.NewCredentialRotationSupervisor(sqlStore, "instanceID", 0, logger)
+		err := supervisor.Do()
+		require.NoError(t, err)
+
+		expectInstallationState(t, sqlStore, stable, model.InstallationStateCredentialRotationRequested)
+		expectInstallationState(t, sqlStore, updating, model.InstallationStateUpdateRequested)
+	})
+
+	t.Run("credentials within interval", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		installation := createInstallation(t, sqlStore, model.InstallationStateStable)
+
+		supervisor := supervisor.NewCredentialRotationSupervisor(sqlStore, "instanceID", time.Hour, logger)
+		err := supervisor.Do()
+		require.NoError(t, err)
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+	})
+
+	t.Run("rotated credentials within interval", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		installation := createInstallation(t, sqlStore, model.InstallationStateStable)
+		installation.CredentialsRotatedAt = store.GetMillis()
+		err := sqlStore.UpdateInstallationCredentialsRotatedAt(installation)
+		require.NoError(t, err)
+
+		supervisor := supervisor.NewCredentialRotationSupervisor(sqlStore, "instanceID", time.Hour, logger)
+		err = supervisor.Do()
+		require.NoError(t, err)
+
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+	})
+}
@@ -31,6 +31,8 @@ type installationStore interface {
 	UpdateInstallation(installation *model.Installation) error
 	UpdateInstallationGroupSequence(installation *model.Installation) error
 	UpdateInstallationState(*model.Installation) error
+	UpdateInstallationFilestoreMigration(installation *model.Installation) error
+	UpdateInstallationCredentialsRotatedAt(installation *model.Installation) error
 	LockInstallation(installationID, lockerID string) (bool, error)
 	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
 	DeleteInstallation(installationID string) error
@@ -70,8 +72,24 @@ type installationProvisioner interface {
 	GetClusterInstallationResource(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation) (*mmv1alpha1.ClusterInstallation, error)
 	GetClusterResources(cluster *model.Cluster, onlySchedulable bool) (*k8s.ClusterResources, error)
 	GetPublicLoadBalancerEndpoint(cluster *model.Cluster, namespace string) (string, error)
+	CreateFilestoreMigrationJob(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, config *model.FilestoreMigrationConfig) error
+	GetFilestoreMigrationJobStatus(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (*model.FilestoreMigrationJobStatus, error)
+	DeleteFilestoreMigrationJob(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) error
+	RestartClusterInstallation(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) error
+	IsClusterInstallationRestartComplete(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (bool, error)
 }
 
+// DNSProvider abstracts the management of the public DNS records of
+// installations. Implementations are provided by the tools/dns package.
+type DNSProvider interface {
+	CreatePublicCNAME(dnsName string, dnsEndpoints []string, logger log.FieldLogger) error
+	DeletePublicCNAME(dnsName string, logger log.FieldLogger) error
+}
+
+// maxFilestoreMigrationCopyAttempts is the number of times the installation
+// files are copied before a filestore migration with missing objects fails.
+const maxFilestoreMigrationCopyAttempts = 5
+
 // InstallationSupervisor finds installations pending work and effects the required changes.
 //
 // The degree of parallelism is controlled by a weighted semaphore, intended to be shared with
@@ -80,6 +98,7 @@ type InstallationSupervisor struct {
 	store                              installationStore
 	provisioner                        installationProvisioner
 	aws                                aws.AWS
+	dnsProvider                        DNSProvider
 	instanceID                         string
 	clusterResourceThreshold           int
 	clusterResourceThresholdScaleValue int
@@ -90,11 +109,12 @@ type InstallationSupervisor struct {
 }
 
 // NewInstallationSupervisor creates a new InstallationSupervisor.
-func NewInstallationSupervisor(store installationStore, installationProvisioner installationProvisioner, aws aws.AWS, instanceID string, threshold, thresholdScaleValue int, keepDatabaseData, keepFilestoreData bool, resourceUtil *utils.ResourceUtil, logger log.FieldLogger) *InstallationSupervisor {
+func NewInstallationSupervisor(store installationStore, installationProvisioner installationProvisioner, aws aws.AWS, dnsProvider DNSProvider, instanceID string, threshold, thresholdScaleValue int, keepDatabaseData, keepFilestoreData bool, resourceUtil *utils.ResourceUtil, logger log.FieldLogger) *InstallationSupervisor {
 	return &InstallationSupervisor{
 		store:                              store,
 		provisioner:                        installationProvisioner,
 		aws:                                aws,
+		dnsProvider:                        dnsProvider,
 		instanceID:                         instanceID,
 		clusterResourceThreshold:           threshold,
 		clusterResourceThresholdScaleValue: thresholdScaleValue,
@@ -243,6 +263,24 @@ func (s *InstallationSupervisor) transitionInstallation(installation *model.Inst
 	case model.InstallationStateHibernationInProgress:
 		return s.waitForHibernationStable(installation, instanceID, logger)
 
+	case model.InstallationStateFilestoreMigrationRequested:
+		return s.startFilestoreMigration(installation, logger)
+
+	case model.InstallationStateFilestoreMigrationInProgress:
+		return s.checkFilestoreMigration(installation, instanceID, logger)
+
+	case model.InstallationStateFilestoreMigrationFinalizing:
+		return s.finalizeFilestoreMigration(installation, logger)
+
+	case model.InstallationStateCredentialRotationRequested:
+		return s.rotateInstallationCredentials(installation, logger)
+
+	case model.InstallationStateCredentialRotationInProgress:
+		return s.restartInstallationWithNewCredentials(installation, instanceID, logger)
+
+	case model.InstallationStateCredentialRotationFinalizing:
+		return s.finalizeCredentialRotation(installation, logger)
+
 	case model.InstallationStateDeletionRequested,
 		model.InstallationStateDeletionInProgress:
 		return s.deleteInstallation(installation, instanceID, logger)
@@ -339,6 +377,11 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 		logger.Debugf("Cluster %s is set to not allow for new installation scheduling", cluster.ID)
 		return nil
 	}
+	if len(installation.NodeGroup) != 0 &&
+		(cluster.ProvisionerMetadataKops == nil || cluster.ProvisionerMetadataKops.GetNodeGroup(installation.NodeGroup) == nil) {
+		logger.Debugf("Cluster %s has no node group %s", cluster.ID, installation.NodeGroup)
+		return nil
+	}
 
 	existingClusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
 		PerPage:   model.AllPerPage,
@@ -376,7 +419,9 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 
 	// Begin final resource check.
 
-	size, err := mmv1alpha1.GetClusterSize(installation.Size)
+	// The resource quota of the installation namespace is reserved on the
+	// cluster, as the installation may use it at any time.
+	installationCPURequirement, installationMemRequirement, err := installation.CalculateResourceQuotaMilliRequests()
 	if err != nil {
 		logger.WithError(err).Error("Invalid cluster installation size")
 		return nil
@@ -387,21 +432,32 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 		return nil
 	}
 
-	installationCPURequirement := size.CalculateCPUMilliRequirement(
-		installation.InternalDatabase(),
-		installation.InternalFilestore(),
-	)
-	installationMemRequirement := size.CalculateMemoryMilliRequirement(
-		installation.InternalDatabase(),
-		installation.InternalFilestore(),
-	)
+	// Installations pinned to a node group are scheduled onto its nodes only,
+	// so they are sized against the resources of that group.
+	var nodeGroup *model.KopsNodeGroup
+	if len(installation.NodeGroup) != 0 {
+		nodeGroup = cluster.ProvisionerMetadataKops.GetNodeGroup(installation.NodeGroup)
+		clusterResources = clusterResources.NodeGroups[installation.NodeGroup]
+		if clusterResources == nil {
+			clusterResources = &k8s.ClusterResources{}
+		}
+		if nodeGroup != nil && nodeGroup.CanScale() && cluster.ProvisionerMetadataKops.IsAutoscaling() {
+			// Node groups are scaled by the cluster-autoscaler up to their
+			// max count on autoscaling clusters.
+			clusterResources = clusterResources.WithNodeHeadroom(nodeGroup.MaxCount - clusterResources.NodeCount)
+		}
+	} else if cluster.ProvisionerMetadataKops != nil && cluster.ProvisionerMetadataKops.IsAutoscaling() {
+		// The cluster-autoscaler adds nodes for pending pods up to the node
+		// max count. Nodes of additional node groups are counted against the
+		// max count, which keeps the headroom estimate conservative.
+		clusterResources = clusterResources.WithNodeHeadroom(cluster.ProvisionerMetadataKops.NodeMaxCount - clusterResources.NodeCount)
+	}
+
 	cpuPercent := clusterResources.CalculateCPUPercentUsed(installationCPURequirement)
 	memoryPercent := clusterResources.CalculateMemoryPercentUsed(installationMemRequirement)
 
 	if cpuPercent > s.clusterResourceThreshold || memoryPercent > s.clusterResourceThreshold {
-		if s.clusterResourceThresholdScaleValue == 0 ||
-			cluster.ProvisionerMetadataKops.NodeMinCount == cluster.ProvisionerMetadataKops.NodeMaxCount ||
-			cluster.State != model.ClusterStateStable {
+		if !s.canScaleClusterForInstallation(cluster, nodeGroup) {
 			logger.Debugf("Cluster %s would exceed the cluster load threshold (%d%%): CPU=%d%% (+%dm), Memory=%d%% (+%dMi)",
 				cluster.ID,
 				s.clusterResourceThreshold,
@@ -416,21 +472,42 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 		// updating the cluster. We should try to reuse some of the API flow
 		// that already does this.
 
-		newWorkerCount := cluster.ProvisionerMetadataKops.NodeMinCount + int64(s.clusterResourceThresholdScaleValue)
-		if newWorkerCount > cluster.ProvisionerMetadataKops.NodeMaxCount {
-			newWorkerCount = cluster.ProvisionerMetadataKops.NodeMaxCount
-		}
-
 		cluster.State = model.ClusterStateResizeRequested
-		cluster.ProvisionerMetadataKops.ChangeRequest = &model.KopsMetadataRequestedState{
-			NodeMinCount: newWorkerCount,
-		}
+		if nodeGroup != nil {
+			newNodeCount := nodeGroup.MinCount + int64(s.clusterResourceThresholdScaleValue)
+			if newNodeCount > nodeGroup.MaxCount {
+				newNodeCount = nodeGroup.MaxCount
+			}
+			cluster.ProvisionerMetadataKops.ChangeRequest = &model.KopsMetadataRequestedState{
+				NodeGroups: []*model.KopsNodeGroup{{
+					Name:         nodeGroup.Name,
+					InstanceType: nodeGroup.InstanceType,
+					MinCount:     newNodeCount,
+					MaxCount:     nodeGroup.MaxCount,
+				}},
+			}
 
-		logger.WithField("cluster", cluster.ID).Infof("Scaling cluster worker nodes from %d to %d (max=%d)",
-			cluster.ProvisionerMetadataKops.NodeMinCount,
-			cluster.ProvisionerMetadataKops.ChangeRequest.NodeMinCount,
-			cluster.ProvisionerMetadataKops.NodeMaxCount,
-		)
+			logger.WithField("cluster", cluster.ID).Infof("Scaling node group %s from %d to %d (max=%d)",
+				nodeGroup.Name,
+				nodeGroup.MinCount,
+				newNodeCount,
+				nodeGroup.MaxCount,
+			)
+		} else {
+			newWorkerCount := cluster.ProvisionerMetadataKops.NodeMinCount + int64(s.clusterResourceThresholdScaleValue)
+			if newWorkerCount > cluster.ProvisionerMetadataKops.NodeMaxCount {
+				newWorkerCount = cluster.ProvisionerMetadataKops.NodeMaxCount
+			}
+			cluster.ProvisionerMetadataKops.ChangeRequest = &model.KopsMetadataRequestedState{
+				NodeMinCount: newWorkerCount,
+			}
+
+			logger.WithField("cluster", cluster.ID).Infof("Scaling cluster worker nodes from %d to %d (max=%d)",
+				cluster.ProvisionerMetadataKops.NodeMinCount,
+				cluster.ProvisionerMetadataKops.ChangeRequest.NodeMinCount,
+				cluster.ProvisionerMetadataKops.NodeMaxCount,
+			)
+		}
 		err = s.store.UpdateCluster(cluster)
 		if err != nil {
 			logger.WithError(err).Error("Failed to update cluster")
@@ -483,14 +560,38 @@ func (s *InstallationSupervisor) createClusterInstallation(cluster *model.Cluste
 	return clusterInstallation
 }
 
+// canScaleClusterForInstallation returns whether the supervisor may resize
+// the cluster, or the given node group of it, to make room for a new
+// installation.
+func (s *InstallationSupervisor) canScaleClusterForInstallation(cluster *model.Cluster, nodeGroup *model.KopsNodeGroup) bool {
+	if s.clusterResourceThresholdScaleValue == 0 ||
+		cluster.ProvisionerMetadataKops == nil ||
+		cluster.IsImported() ||
+		cluster.State != model.ClusterStateStable {
+		return false
+	}
+	if nodeGroup != nil {
+		return !cluster.ProvisionerMetadataKops.IsAutoscaling() && nodeGroup.CanScale()
+	}
+
+	return !cluster.ProvisionerMetadataKops.IsAutoscaling() &&
+		cluster.ProvisionerMetadataKops.NodeMinCount != cluster.ProvisionerMetadataKops.NodeMaxCount
+}
+
 func (s *InstallationSupervisor) preProvisionInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
-	err := s.resourceUtil.GetDatabase(installation).Provision(s.store, logger)
+	resourceUtil, err := getInstallationResourceUtil(s.store, s.resourceUtil, installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation resource util")
+		return installation.State
+	}
+
+	err = resourceUtil.GetDatabase(installation).Provision(s.store, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to provision installation database")
 		return model.InstallationStateCreationPreProvisioning
 	}
 
-	err = s.resourceUtil.GetFilestore(installation).Provision(s.store, logger)
+	err = resourceUtil.GetFilestore(installation).Provision(s.store, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to provision installation filestore")
 		return model.InstallationStateCreationPreProvisioning
@@ -549,7 +650,7 @@ func (s *InstallationSupervisor) configureInstallationDNS(installation *model.In
 		endpoints = append(endpoints, endpoint)
 	}
 
-	err = s.aws.CreatePublicCNAME(installation.DNS, endpoints, logger)
+	err = s.dnsProvider.CreatePublicCNAME(installation.DNS, endpoints, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to create DNS CNAME record")
 		return model.InstallationStateCreationDNS
@@ -805,6 +906,527 @@ func (s *InstallationSupervisor) waitForHibernationStable(installation *model.In
 	return model.InstallationStateHibernating
 }
 
+func (s *InstallationSupervisor) startFilestoreMigration(installation *model.Installation, logger log.FieldLogger) string {
+	if installation.FilestoreMigration == nil {
+		logger.Error("Installation has no filestore migration to start")
+		return model.InstallationStateFilestoreMigrationFailed
+	}
+
+	cluster, clusterInstallation, err := s.getFilestoreMigrationClusterInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster installation for filestore migration")
+		return model.InstallationStateFilestoreMigrationFailed
+	}
+
+	// The final copy only starts once the app was scaled down so that no
+	// file is written to the source filestore after it.
+	if installation.FilestoreMigration.FinalSync {
+		stable, err := s.checkIfClusterInstallationsAreStable(installation, logger)
+		if err != nil {
+			logger.WithError(err).Error("Cluster installation failed while freezing writes")
+			return s.failFilestoreMigration(cluster, installation, clusterInstallation, logger)
+		}
+		if !stable {
+			logger.Debug("Waiting for the app to scale down before the final copy")
+			return installation.State
+		}
+	}
+
+	resourceUtil, err := s.resourceUtil.ForCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation resource util")
+		return installation.State
+	}
+
+	config, err := resourceUtil.GetFilestoreMigrator(installation).Setup(s.store, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to set up filestore migration")
+		return installation.State
+	}
+
+	err = s.provisioner.CreateFilestoreMigrationJob(cluster, installation, clusterInstallation, config)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create filestore migration job")
+		return installation.State
+	}
+
+	installation.FilestoreMigration.CopyAttempts++
+	err = s.store.UpdateInstallationFilestoreMigration(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to store filestore migration progress")
+		return installation.State
+	}
+
+	logger.Infof("Started filestore migration copy attempt %d", installation.FilestoreMigration.CopyAttempts)
+
+	return model.InstallationStateFilestoreMigrationInProgress
+}
+
+func (s *InstallationSupervisor) checkFilestoreMigration(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	if installation.FilestoreMigration == nil {
+		logger.Error("Installation has no filestore migration in progress")
+		return model.InstallationStateFilestoreMigrationFailed
+	}
+
+	cluster, clusterInstallation, err := s.getFilestoreMigrationClusterInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster installation for filestore migration")
+		return model.InstallationStateFilestoreMigrationFailed
+	}
+
+	// The cutover may already have been done by a previous pass that failed
+	// to update the cluster installation.
+	if installation.Filestore != installation.FilestoreMigration.TargetFilestore {
+		status, err := s.provisioner.GetFilestoreMigrationJobStatus(cluster, clusterInstallation)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get filestore migration job status")
+			return installation.State
+		}
+		if status.Failed {
+			// The job is left in place so that its logs can be inspected.
+			logger.Error("Filestore migration job failed")
+			return s.failFilestoreMigration(cluster, installation, clusterInstallation, logger)
+		}
+		if !status.Complete {
+			logger.Debug("Filestore migration job is still running")
+			return installation.State
+		}
+
+		migration := installation.FilestoreMigration
+		migration.SourceObjectCount = status.SourceObjectCount
+		migration.TargetObjectCount = status.TargetObjectCount
+
+		if migration.TargetObjectCount < migration.SourceObjectCount {
+			logger.Warnf("Filestore migration copied %d of %d objects", migration.TargetObjectCount, migration.SourceObjectCount)
+
+			err = s.store.UpdateInstallationFilestoreMigration(installation)
+			if err != nil {
+				logger.WithError(err).Error("Failed to store filestore migration progress")
+				return installation.State
+			}
+
+			if migration.CopyAttempts >= maxFilestoreMigrationCopyAttempts {
+				logger.Errorf("Filestore migration failed to copy all objects after %d attempts", migration.CopyAttempts)
+				return s.failFilestoreMigration(cluster, installation, clusterInstallation, logger)
+			}
+
+			return model.InstallationStateFilestoreMigrationRequested
+		}
+
+		// Files keep being written to the source filestore while it is copied.
+		// The app is scaled down to freeze writes, then one more copy, which
+		// only transfers what changed, picks them up before the cutover. The
+		// app stays down until the cluster installation is updated to use
+		// the target filestore.
+		if !migration.FinalSync {
+			logger.Infof("Filestore migration copied %d objects; freezing writes for the final copy", migration.TargetObjectCount)
+
+			err = s.freezeFilestoreWrites(cluster, installation, clusterInstallation, instanceID, logger)
+			if err != nil {
+				logger.WithError(err).Error("Failed to freeze filestore writes")
+				return installation.State
+			}
+
+			migration.FinalSync = true
+			err = s.store.UpdateInstallationFilestoreMigration(installation)
+			if err != nil {
+				logger.WithError(err).Error("Failed to store filestore migration progress")
+				return installation.State
+			}
+
+			return model.InstallationStateFilestoreMigrationRequested
+		}
+
+		logger.Infof("Filestore migration copied %d objects; switching to filestore %s", migration.TargetObjectCount, migration.TargetFilestore)
+
+		resourceUtil, err := s.resourceUtil.ForCluster(cluster)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get installation resource util")
+			return installation.State
+		}
+
+		err = resourceUtil.GetFilestoreMigrator(installation).Cutover(s.store, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to cut over to the new filestore")
+			return installation.State
+		}
+
+		installation.Filestore = migration.TargetFilestore
+		err = s.store.UpdateInstallationFilestoreMigration(installation)
+		if err != nil {
+			logger.WithError(err).Error("Failed to store new installation filestore")
+			return installation.State
+		}
+	}
+
+	clusterInstallationLocks := newClusterInstallationLocks([]string{clusterInstallation.ID}, instanceID, s.store, logger)
+	if !clusterInstallationLocks.TryLock() {
+		logger.Debug("Failed to lock cluster installation")
+		return installation.State
+	}
+	defer clusterInstallationLocks.Unlock()
+
+	err = s.provisioner.UpdateClusterInstallation(cluster, installation, clusterInstallation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update cluster installation")
+		return installation.State
+	}
+
+	if clusterInstallation.State != model.ClusterInstallationStateReconciling {
+		oldState := clusterInstallation.State
+		clusterInstallation.State = model.ClusterInstallationStateReconciling
+		err = s.store.UpdateClusterInstallation(clusterInstallation)
+		if err != nil {
+			logger.WithError(err).Errorf("Failed to change cluster installation state to %s", model.ClusterInstallationStateReconciling)
+			return installation.State
+		}
+
+		webhookPayload := &model.WebhookPayload{
+			Type:      model.TypeClusterInstallation,
+			ID:        clusterInstallation.ID,
+			NewState:  clusterInstallation.State,
+			OldState:  oldState,
+			Timestamp: time.Now().UnixNano(),
+		}
+		err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+		if err != nil {
+			logger.WithError(err).Error("Unable to process and send webhooks")
+		}
+	}
+
+	return model.InstallationStateFilestoreMigrationFinalizing
+}
+
+// freezeFilestoreWrites scales the app of an installation down so that no
+// file is written to its filestore.
+func (s *InstallationSupervisor) freezeFilestoreWrites(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, instanceID string, logger log.FieldLogger) error {
+	clusterInstallationLocks := newClusterInstallationLocks([]string{clusterInstallation.ID}, instanceID, s.store, logger)
+	if !clusterInstallationLocks.TryLock() {
+		return errors.New("failed to lock cluster installation")
+	}
+	defer clusterInstallationLocks.Unlock()
+
+	err := s.provisioner.HibernateClusterInstallation(cluster, installation, clusterInstallation)
+	if err != nil {
+		return errors.Wrap(err, "failed to scale down cluster installation")
+	}
+
+	clusterInstallation.State = model.ClusterInstallationStateReconciling
+	err = s.store.UpdateClusterInstallation(clusterInstallation)
+	if err != nil {
+		return errors.Wrapf(err, "failed to change cluster installation state to %s", model.ClusterInstallationStateReconciling)
+	}
+
+	return nil
+}
+
+// failFilestoreMigration scales the app of an installation back up on its
+// source filestore when writes were frozen for the final copy, so that a
+// failed migration doesn't leave the installation down.
+func (s *InstallationSupervisor) failFilestoreMigration(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, logger log.FieldLogger) string {
+	if !installation.FilestoreMigration.FinalSync {
+		return model.InstallationStateFilestoreMigrationFailed
+	}
+
+	err := s.provisioner.UpdateClusterInstallation(cluster, installation, clusterInstallation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to scale the app back up after the failed filestore migration")
+		return installation.State
+	}
+
+	// A retried migration freezes writes again before its final copy.
+	installation.FilestoreMigration.FinalSync = false
+	err = s.store.UpdateInstallationFilestoreMigration(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to store filestore migration progress")
+		return installation.State
+	}
+
+	return model.InstallationStateFilestoreMigrationFailed
+}
+
+func (s *InstallationSupervisor) finalizeFilestoreMigration(installation *model.Installation, logger log.FieldLogger) string {
+	if installation.FilestoreMigration == nil {
+		logger.Error("Installation has no filestore migration to finalize")
+		return model.InstallationStateFilestoreMigrationFailed
+	}
+
+	stable, err := s.checkIfClusterInstallationsAreStable(installation, logger)
+	if err != nil {
+		logger.WithError(err).Error("Cluster installation failed after filestore migration")
+		return model.InstallationStateFilestoreMigrationFailed
+	}
+	if !stable {
+		return installation.State
+	}
+
+	cluster, clusterInstallation, err := s.getFilestoreMigrationClusterInstallation(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get cluster installation for filestore migration")
+		return model.InstallationStateFilestoreMigrationFailed
+	}
+
+	err = s.provisioner.DeleteFilestoreMigrationJob(cluster, clusterInstallation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to delete filestore migration job")
+		return installation.State
+	}
+
+	resourceUtil, err := s.resourceUtil.ForCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation resource util")
+		return installation.State
+	}
+
+	err = resourceUtil.GetFilestoreMigrator(installation).Teardown(s.keepFilestoreData, s.store, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to tear down filestore migration resources")
+		return installation.State
+	}
+
+	installation.FilestoreMigration.CompleteAt = time.Now().UnixNano() / int64(time.Millisecond)
+	err = s.store.UpdateInstallationFilestoreMigration(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to store filestore migration completion")
+		return installation.State
+	}
+
+	logger.Info("Finished filestore migration")
+
+	return model.InstallationStateStable
+}
+
+// installationClusterStore abstracts the database operations required to find
+// the cluster of an installation.
+type installationClusterStore interface {
+	GetCluster(id string) (*model.Cluster, error)
+	GetClusterInstallations(*model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+}
+
+// getInstallationResourceUtil returns the resource util for the AWS region
+// and account of the cluster the installation is scheduled on.
+func getInstallationResourceUtil(store installationClusterStore, resourceUtil *utils.ResourceUtil, installation *model.Installation) (*utils.ResourceUtil, error) {
+	clusterInstallations, err := store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find cluster installations")
+	}
+	if len(clusterInstallations) == 0 {
+		return resourceUtil, nil
+	}
+
+	cluster, err := store.GetCluster(clusterInstallations[0].ClusterID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query cluster %s", clusterInstallations[0].ClusterID)
+	}
+	if cluster == nil {
+		return resourceUtil, nil
+	}
+
+	return resourceUtil.ForCluster(cluster)
+}
+
+// getFilestoreMigrationClusterInstallation returns the only cluster
+// installation of an installation along with its cluster. Filestore migrations
+// are only supported for installations running on a single cluster.
+func (s *InstallationSupervisor) getFilestoreMigrationClusterInstallation(installation *model.Installation) (*model.Cluster, *model.ClusterInstallation, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to find cluster installations")
+	}
+	if len(clusterInstallations) != 1 {
+		return nil, nil, errors.Errorf("expected exactly one cluster installation, but found %d", len(clusterInstallations))
+	}
+	clusterInstallation := clusterInstallations[0]
+
+	cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to query cluster %s", clusterInstallation.ClusterID)
+	}
+	if cluster == nil {
+		return nil, nil, errors.Errorf("failed to find cluster %s", clusterInstallation.ClusterID)
+	}
+
+	return cluster, clusterInstallation, nil
+}
+
+func (s *InstallationSupervisor) rotateInstallationCredentials(installation *model.Installation, logger log.FieldLogger) string {
+	resourceUtil, err := getInstallationResourceUtil(s.store, s.resourceUtil, installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation resource util")
+		return installation.State
+	}
+
+	err = resourceUtil.GetDatabase(installation).RotateCredentials(s.store, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to rotate database credentials")
+		return model.InstallationStateCredentialRotationFailed
+	}
+
+	err = resourceUtil.GetFilestore(installation).RotateCredentials(s.store, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to rotate filestore credentials")
+		return model.InstallationStateCredentialRotationFailed
+	}
+
+	logger.Info("Installation credentials rotated; restarting cluster installations")
+
+	return model.InstallationStateCredentialRotationInProgress
+}
+
+func (s *InstallationSupervisor) restartInstallationWithNewCredentials(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return installation.State
+	}
+
+	var clusterInstallationIDs []string
+	for _, clusterInstallation := range clusterInstallations {
+		clusterInstallationIDs = append(clusterInstallationIDs, clusterInstallation.ID)
+	}
+
+	if len(clusterInstallationIDs) > 0 {
+		clusterInstallationLocks := newClusterInstallationLocks(clusterInstallationIDs, instanceID, s.store, logger)
+		if !clusterInstallationLocks.TryLock() {
+			logger.Debugf("Failed to lock %d cluster installations", len(clusterInstallations))
+			return installation.State
+		}
+		defer clusterInstallationLocks.Unlock()
+	}
+
+	for _, clusterInstallation := range clusterInstallations {
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to query cluster %s", clusterInstallation.ClusterID)
+			return installation.State
+		}
+		if cluster == nil {
+			logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
+			return model.InstallationStateCredentialRotationFailed
+		}
+
+		err = s.provisioner.UpdateClusterInstallation(cluster, installation, clusterInstallation)
+		if err != nil {
+			logger.WithError(err).Error("Failed to update cluster installation with new credentials")
+			return installation.State
+		}
+
+		err = s.provisioner.RestartClusterInstallation(cluster, clusterInstallation)
+		if err != nil {
+			logger.WithError(err).Error("Failed to restart cluster installation")
+			return installation.State
+		}
+
+		if clusterInstallation.State != model.ClusterInstallationStateReconciling {
+			oldState := clusterInstallation.State
+			clusterInstallation.State = model.ClusterInstallationStateReconciling
+			err = s.store.UpdateClusterInstallation(clusterInstallation)
+			if err != nil {
+				logger.Errorf("Failed to change cluster installation state to %s", model.ClusterInstallationStateReconciling)
+				return installation.State
+			}
+
+			webhookPayload := &model.WebhookPayload{
+				Type:      model.TypeClusterInstallation,
+				ID:        clusterInstallation.ID,
+				NewState:  clusterInstallation.State,
+				OldState:  oldState,
+				Timestamp: time.Now().UnixNano(),
+			}
+			err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+			if err != nil {
+				logger.WithError(err).Error("Unable to process and send webhooks")
+			}
+		}
+	}
+
+	logger.Info("Finished restarting cluster installations with new credentials")
+
+	return model.InstallationStateCredentialRotationFinalizing
+}
+
+func (s *InstallationSupervisor) finalizeCredentialRotation(installation *model.Installation, logger log.FieldLogger) string {
+	stable, err := s.checkIfClusterInstallationsAreStable(installation, logger)
+	if err != nil {
+		logger.WithError(err).Error("Cluster installation failed after credential rotation")
+		return model.InstallationStateCredentialRotationFailed
+	}
+	if !stable {
+		return installation.State
+	}
+
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to find cluster installations")
+		return installation.State
+	}
+
+	for _, clusterInstallation := range clusterInstallations {
+		cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to query cluster %s", clusterInstallation.ClusterID)
+			return installation.State
+		}
+		if cluster == nil {
+			logger.Errorf("Failed to find cluster %s", clusterInstallation.ClusterID)
+			return model.InstallationStateCredentialRotationFailed
+		}
+
+		complete, err := s.provisioner.IsClusterInstallationRestartComplete(cluster, clusterInstallation)
+		if err != nil {
+			logger.WithError(err).Error("Failed to check cluster installation restart")
+			return installation.State
+		}
+		if !complete {
+			logger.Debugf("Cluster installation %s is still restarting", clusterInstallation.ID)
+			return installation.State
+		}
+	}
+
+	resourceUtil, err := getInstallationResourceUtil(s.store, s.resourceUtil, installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation resource util")
+		return installation.State
+	}
+
+	// All pods are now using the new credentials, so the old ones can go.
+	err = resourceUtil.GetDatabase(installation).RevokeStaleCredentials(s.store, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to revoke stale database credentials")
+		return installation.State
+	}
+
+	err = resourceUtil.GetFilestore(installation).RevokeStaleCredentials(s.store, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to revoke stale filestore credentials")
+		return installation.State
+	}
+
+	installation.CredentialsRotatedAt = time.Now().UnixNano() / int64(time.Millisecond)
+	err = s.store.UpdateInstallationCredentialsRotatedAt(installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to store credential rotation time")
+		return installation.State
+	}
+
+	logger.Info("Finished rotating installation credentials")
+
+	return model.InstallationStateStable
+}
+
 func (s *InstallationSupervisor) deleteInstallation(installation *model.Installation, instanceID string, logger log.FieldLogger) string {
 	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
 		PerPage:        model.AllPerPage,
@@ -911,24 +1533,38 @@ func (s *InstallationSupervisor) deleteInstallation(installation *model.Installa
 }
 
 func (s *InstallationSupervisor) finalDeletionCleanup(installation *model.Installation, logger log.FieldLogger) string {
-	err := s.aws.DeletePublicCNAME(installation.DNS, logger)
+	err := s.dnsProvider.DeletePublicCNAME(installation.DNS, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to delete installation DNS")
 		return model.InstallationStateDeletionFinalCleanup
 	}
 
-	err = s.resourceUtil.GetDatabase(installation).Teardown(s.store, s.keepDatabaseData, logger)
+	resourceUtil, err := getInstallationResourceUtil(s.store, s.resourceUtil, installation)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation resource util")
+		return installation.State
+	}
+
+	err = resourceUtil.GetDatabase(installation).Teardown(s.store, s.keepDatabaseData, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to delete database")
 		return model.InstallationStateDeletionFinalCleanup
 	}
 
-	err = s.resourceUtil.GetFilestore(installation).Teardown(s.keepFilestoreData, s.store, logger)
+	err = resourceUtil.GetFilestore(installation).Teardown(s.keepFilestoreData, s.store, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to delete filestore")
 		return model.InstallationStateDeletionFinalCleanup
 	}
 
+	if installation.FilestoreMigration != nil && !installation.FilestoreMigration.IsComplete() {
+		err = resourceUtil.GetFilestoreMigrator(installation).Teardown(s.keepFilestoreData, s.store, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to delete filestore migration resources")
+			return model.InstallationStateDeletionFinalCleanup
+		}
+	}
+
 	err = s.store.DeleteInstallation(installation.ID)
 	if err != nil {
 		logger.WithError(err).Warn("Failed to mark installation as deleted")
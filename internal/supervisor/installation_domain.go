@@ -0,0 +1,325 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/webhook"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// installationDomainStore abstracts the database operations required by the
+// installation domain supervisor.
+type installationDomainStore interface {
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error)
+	LockInstallation(installationID, lockerID string) (bool, error)
+	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
+
+	GetCluster(id string) (*model.Cluster, error)
+	GetClusterInstallations(*model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+
+	GetInstallationDomains(filter *model.InstallationDomainFilter) ([]*model.InstallationDomain, error)
+	UpdateInstallationDomain(domain *model.InstallationDomain) error
+	DeleteInstallationDomain(id string) error
+
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+}
+
+// installationDomainProvisioner abstracts the provisioning operations
+// required by the installation domain supervisor.
+type installationDomainProvisioner interface {
+	UpdateInstallationDomains(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, domains []string) (map[string]*model.InstallationCertificate, error)
+}
+
+// domainResolver abstracts the DNS lookups required by the installation
+// domain supervisor. It is satisfied by net.Resolver.
+type domainResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// InstallationDomainSupervisor verifies the custom domains of installations,
+// detects when they point at their installation and serves them through the
+// installation ingress with a certificate of their own.
+type InstallationDomainSupervisor struct {
+	store       installationDomainStore
+	provisioner installationDomainProvisioner
+	resolver    domainResolver
+	instanceID  string
+	interval    time.Duration
+	logger      log.FieldLogger
+}
+
+// NewInstallationDomainSupervisor creates a new InstallationDomainSupervisor.
+func NewInstallationDomainSupervisor(store installationDomainStore, provisioner installationDomainProvisioner, resolver domainResolver, instanceID string, interval time.Duration, logger log.FieldLogger) *InstallationDomainSupervisor {
+	return &InstallationDomainSupervisor{
+		store:       store,
+		provisioner: provisioner,
+		resolver:    resolver,
+		instanceID:  instanceID,
+		interval:    interval,
+		logger:      logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the installation domain
+// supervisor.
+func (s *InstallationDomainSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down installation domain supervisor")
+}
+
+// Do looks for installations with custom domains that are not active yet or
+// have not been checked within the check interval and checks their domains.
+func (s *InstallationDomainSupervisor) Do() error {
+	domains, err := s.store.GetInstallationDomains(&model.InstallationDomainFilter{
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for installation domains")
+		return nil
+	}
+
+	var installationIDs []string
+	installationDomains := map[string][]*model.InstallationDomain{}
+	for _, domain := range domains {
+		if _, ok := installationDomains[domain.InstallationID]; !ok {
+			installationIDs = append(installationIDs, domain.InstallationID)
+		}
+		installationDomains[domain.InstallationID] = append(installationDomains[domain.InstallationID], domain)
+	}
+
+	for _, installationID := range installationIDs {
+		s.Supervise(installationID, installationDomains[installationID])
+	}
+
+	return nil
+}
+
+// Supervise checks the given custom domains of an installation if any of them
+// is not active yet or if the check interval has passed since their last
+// check.
+func (s *InstallationDomainSupervisor) Supervise(installationID string, domains []*model.InstallationDomain) {
+	logger := s.logger.WithFields(log.Fields{
+		"installation": installationID,
+	})
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	if !s.checkDue(domains, now) {
+		return
+	}
+
+	lock := newInstallationLock(installationID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	domains, err := s.store.GetInstallationDomains(&model.InstallationDomainFilter{
+		InstallationID: installationID,
+		PerPage:        model.AllPerPage,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed installation domains")
+		return
+	}
+
+	installation, err := s.store.GetInstallation(installationID, false, false)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation")
+		return
+	}
+	if installation == nil || installation.DeleteAt != 0 || installation.State == model.InstallationStateDeleted {
+		for _, domain := range domains {
+			s.deleteDomain(domain, logger)
+		}
+		return
+	}
+	if installation.State != model.InstallationStateStable {
+		return
+	}
+
+	logger.Debugf("Checking %d installation domains", len(domains))
+
+	oldStates := map[string]string{}
+	var servedDomains []string
+	// The ingress is only updated when it serves or served domains.
+	updateIngress := false
+	for _, domain := range domains {
+		oldStates[domain.ID] = domain.State
+		domain.CheckedAt = now
+		if domain.IsServed() || domain.State == model.InstallationDomainStateDeletionRequested {
+			updateIngress = true
+		}
+		if domain.State == model.InstallationDomainStateDeletionRequested {
+			continue
+		}
+
+		s.checkDomain(domain, installation)
+		if domain.IsServed() {
+			servedDomains = append(servedDomains, domain.Domain)
+			updateIngress = true
+		}
+	}
+
+	certificates := map[string]*model.InstallationCertificate{}
+	if updateIngress {
+		certificates, err = s.updateIngress(installation, servedDomains)
+		if err != nil {
+			logger.WithError(err).Error("Failed to update installation domains ingress")
+			return
+		}
+	}
+
+	for _, domain := range domains {
+		if domain.State == model.InstallationDomainStateDeletionRequested {
+			s.deleteDomain(domain, logger)
+			continue
+		}
+
+		certificate, ok := certificates[domain.Domain]
+		if domain.IsServed() && ok {
+			if certificate.IsReady() {
+				domain.State = model.InstallationDomainStateActive
+				domain.Message = ""
+			} else {
+				domain.State = model.InstallationDomainStateIssuingCertificate
+				domain.Message = certificate.Message
+			}
+		}
+
+		err = s.store.UpdateInstallationDomain(domain)
+		if err != nil {
+			logger.WithError(err).Errorf("Failed to update installation domain %s", domain.Domain)
+			continue
+		}
+
+		if domain.State != oldStates[domain.ID] {
+			s.sendWebhook(domain, oldStates[domain.ID], logger)
+		}
+	}
+}
+
+// checkDue returns true if any of the given domains is not active yet or was
+// not checked within the check interval.
+func (s *InstallationDomainSupervisor) checkDue(domains []*model.InstallationDomain, now int64) bool {
+	for _, domain := range domains {
+		if domain.State != model.InstallationDomainStateActive {
+			return true
+		}
+		if now-domain.CheckedAt >= s.interval.Milliseconds() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkDomain moves a domain to the state matching its DNS records. A domain
+// is served once it is verified and points at the installation.
+func (s *InstallationDomainSupervisor) checkDomain(domain *model.InstallationDomain, installation *model.Installation) {
+	ctx := context.Background()
+
+	if domain.State == model.InstallationDomainStatePendingVerification {
+		record := domain.VerificationRecord()
+		values, err := s.resolver.LookupTXT(ctx, record.Name)
+		if err != nil || !containsString(values, record.Value) {
+			domain.Message = fmt.Sprintf("TXT record %s not found", record.Name)
+			return
+		}
+		domain.State = model.InstallationDomainStatePendingCNAME
+		domain.Message = ""
+	}
+
+	expected, err := s.resolver.LookupCNAME(ctx, installation.DNS)
+	if err != nil {
+		domain.Message = fmt.Sprintf("failed to resolve %s", installation.DNS)
+		return
+	}
+	actual, err := s.resolver.LookupCNAME(ctx, domain.Domain)
+	if err != nil || !sameHost(actual, expected) {
+		domain.State = model.InstallationDomainStatePendingCNAME
+		domain.Message = fmt.Sprintf("%s does not point at %s", domain.Domain, installation.DNS)
+		return
+	}
+
+	if domain.State == model.InstallationDomainStatePendingCNAME {
+		domain.State = model.InstallationDomainStateIssuingCertificate
+		domain.Message = ""
+	}
+}
+
+// updateIngress serves the given domains through the ingress of the cluster
+// installation of an installation.
+func (s *InstallationDomainSupervisor) updateIngress(installation *model.Installation, domains []string) (map[string]*model.InstallationCertificate, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find cluster installations")
+	}
+	if len(clusterInstallations) != 1 {
+		return nil, errors.Errorf("expected exactly one cluster installation, but found %d", len(clusterInstallations))
+	}
+	clusterInstallation := clusterInstallations[0]
+
+	cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query cluster %s", clusterInstallation.ClusterID)
+	}
+	if cluster == nil {
+		return nil, errors.Errorf("failed to find cluster %s", clusterInstallation.ClusterID)
+	}
+
+	return s.provisioner.UpdateInstallationDomains(cluster, installation, clusterInstallation, domains)
+}
+
+func (s *InstallationDomainSupervisor) deleteDomain(domain *model.InstallationDomain, logger log.FieldLogger) {
+	err := s.store.DeleteInstallationDomain(domain.ID)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to delete installation domain %s", domain.Domain)
+		return
+	}
+
+	oldState := domain.State
+	domain.State = model.InstallationDomainStateDeleted
+	s.sendWebhook(domain, oldState, logger)
+}
+
+func (s *InstallationDomainSupervisor) sendWebhook(domain *model.InstallationDomain, oldState string, logger log.FieldLogger) {
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallationDomain,
+		ID:        domain.ID,
+		NewState:  domain.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Domain": domain.Domain, "InstallationID": domain.InstallationID},
+	}
+	err := webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState))
+	if err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
+
+// sameHost returns true if the given DNS names are equal.
+func sameHost(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInstallationDomainProvisioner struct {
+	CertificateStatus string
+	Domains           []string
+	Calls             int
+}
+
+func (p *mockInstallationDomainProvisioner) UpdateInstallationDomains(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, domains []string) (map[string]*model.InstallationCertificate, error) {
+	p.Calls++
+	p.Domains = domains
+
+	certificates := map[string]*model.InstallationCertificate{}
+	for _, domain := range domains {
+		certificates[domain] = &model.InstallationCertificate{Status: p.CertificateStatus, Message: "certificate message"}
+	}
+
+	return certificates, nil
+}
+
+type mockDomainResolver struct {
+	TXT   map[string][]string
+	CNAME map[string]string
+}
+
+func (r *mockDomainResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	values, ok := r.TXT[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+
+	return values, nil
+}
+
+func (r *mockDomainResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	value, ok := r.CNAME[host]
+	if !ok {
+		return "", errors.New("no such host")
+	}
+
+	return value, nil
+}
+
+func TestInstallationDomainSupervisor(t *testing.T) {
+	setup := func(t *testing.T, sqlStore *store.SQLStore, state string) (*model.Installation, *model.InstallationDomain) {
+		t.Helper()
+
+		cluster := &model.Cluster{State: model.ClusterStateStable}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     state,
+		}
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		err = sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      installation.ID,
+			State:          model.ClusterInstallationStateStable,
+		})
+		require.NoError(t, err)
+
+		domain := &model.InstallationDomain{
+			InstallationID:    installation.ID,
+			Domain:            "chat.customer.com",
+			VerificationToken: "token",
+			State:             model.InstallationDomainStatePendingVerification,
+		}
+		err = sqlStore.CreateInstallationDomain(domain)
+		require.NoError(t, err)
+
+		return installation, domain
+	}
+
+	expectState := func(t *testing.T, sqlStore *store.SQLStore, domain *model.InstallationDomain, expectedState string) *model.InstallationDomain {
+		t.Helper()
+
+		domain, err := sqlStore.GetInstallationDomain(domain.ID)
+		require.NoError(t, err)
+		require.Equal(t, expectedState, domain.State)
+
+		return domain
+	}
+
+	t.Run("domain lifecycle", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationDomainProvisioner{CertificateStatus: model.CertificateStatusNotReady}
+		resolver := &mockDomainResolver{
+			TXT:   map[string][]string{},
+			CNAME: map[string]string{"dns.example.com": "lb.amazonaws.com."},
+		}
+		supervisor := supervisor.NewInstallationDomainSupervisor(sqlStore, provisioner, resolver, "instanceID", time.Hour, logger)
+
+		installation, domain := setup(t, sqlStore, model.InstallationStateStable)
+
+		err := supervisor.Do()
+		require.NoError(t, err)
+		actual := expectState(t, sqlStore, domain, model.InstallationDomainStatePendingVerification)
+		require.NotEmpty(t, actual.Message)
+		require.NotZero(t, actual.CheckedAt)
+		require.Equal(t, 0, provisioner.Calls)
+
+		resolver.TXT["_mattermost-cloud-verification.chat.customer.com"] = []string{"token"}
+		err = supervisor.Do()
+		require.NoError(t, err)
+		expectState(t, sqlStore, domain, model.InstallationDomainStatePendingCNAME)
+
+		resolver.CNAME["chat.customer.com"] = "LB.amazonaws.com"
+		err = supervisor.Do()
+		require.NoError(t, err)
+		actual = expectState(t, sqlStore, domain, model.InstallationDomainStateIssuingCertificate)
+		require.Equal(t, "certificate message", actual.Message)
+		require.Equal(t, []string{"chat.customer.com"}, provisioner.Domains)
+
+		provisioner.CertificateStatus = model.CertificateStatusReady
+		err = supervisor.Do()
+		require.NoError(t, err)
+		actual = expectState(t, sqlStore, domain, model.InstallationDomainStateActive)
+		require.Empty(t, actual.Message)
+
+		t.Run("active domain not checked again within interval", func(t *testing.T) {
+			calls := provisioner.Calls
+			err = supervisor.Do()
+			require.NoError(t, err)
+			require.Equal(t, calls, provisioner.Calls)
+		})
+
+		t.Run("deletion", func(t *testing.T) {
+			actual.State = model.InstallationDomainStateDeletionRequested
+			err = sqlStore.UpdateInstallationDomain(actual)
+			require.NoError(t, err)
+
+			err = supervisor.Do()
+			require.NoError(t, err)
+			actual = expectState(t, sqlStore, domain, model.InstallationDomainStateDeleted)
+			require.True(t, actual.IsDeleted())
+			require.Empty(t, provisioner.Domains)
+		})
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Nil(t, installation.LockAcquiredBy)
+	})
+
+	t.Run("domain no longer pointing at installation", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationDomainProvisioner{CertificateStatus: model.CertificateStatusReady}
+		resolver := &mockDomainResolver{
+			CNAME: map[string]string{"dns.example.com": "lb.amazonaws.com.", "chat.customer.com": "other.example.com."},
+		}
+		supervisor := supervisor.NewInstallationDomainSupervisor(sqlStore, provisioner, resolver, "instanceID", time.Millisecond, logger)
+
+		_, domain := setup(t, sqlStore, model.InstallationStateStable)
+		domain.State = model.InstallationDomainStateActive
+		err := sqlStore.UpdateInstallationDomain(domain)
+		require.NoError(t, err)
+
+		err = supervisor.Do()
+		require.NoError(t, err)
+		expectState(t, sqlStore, domain, model.InstallationDomainStatePendingCNAME)
+		require.Equal(t, 1, provisioner.Calls)
+		require.Empty(t, provisioner.Domains)
+	})
+
+	t.Run("unstable installation is skipped", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationDomainProvisioner{}
+		resolver := &mockDomainResolver{}
+		supervisor := supervisor.NewInstallationDomainSupervisor(sqlStore, provisioner, resolver, "instanceID", time.Hour, logger)
+
+		_, domain := setup(t, sqlStore, model.InstallationStateUpdateInProgress)
+
+		err := supervisor.Do()
+		require.NoError(t, err)
+		actual := expectState(t, sqlStore, domain, model.InstallationDomainStatePendingVerification)
+		require.Zero(t, actual.CheckedAt)
+	})
+
+	t.Run("domains of deleted installation are deleted", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationDomainProvisioner{}
+		resolver := &mockDomainResolver{}
+		supervisor := supervisor.NewInstallationDomainSupervisor(sqlStore, provisioner, resolver, "instanceID", time.Hour, logger)
+
+		installation, domain := setup(t, sqlStore, model.InstallationStateStable)
+		err := sqlStore.DeleteInstallation(installation.ID)
+		require.NoError(t, err)
+
+		err = supervisor.Do()
+		require.NoError(t, err)
+		expectState(t, sqlStore, domain, model.InstallationDomainStateDeleted)
+		require.Equal(t, 0, provisioner.Calls)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/mattermost/mattermost-cloud/internal/supervisor"
 	"github.com/mattermost/mattermost-cloud/internal/testlib"
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
+	"github.com/mattermost/mattermost-cloud/internal/tools/dns"
 	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
 	"github.com/mattermost/mattermost-cloud/k8s"
 	"github.com/mattermost/mattermost-cloud/model"
@@ -76,6 +77,16 @@ func (s *mockInstallationStore) UpdateInstallationState(installation *model.Inst
 	return nil
 }
 
+func (s *mockInstallationStore) UpdateInstallationFilestoreMigration(installation *model.Installation) error {
+	s.UpdateInstallationCalls++
+	return nil
+}
+
+func (s *mockInstallationStore) UpdateInstallationCredentialsRotatedAt(installation *model.Installation) error {
+	s.UpdateInstallationCalls++
+	return nil
+}
+
 func (s *mockInstallationStore) LockInstallation(installationID, lockerID string) (bool, error) {
 	return true, nil
 }
@@ -171,8 +182,10 @@ func (s *mockInstallationStore) GetAnnotationsForInstallation(installationID str
 }
 
 type mockInstallationProvisioner struct {
-	UseCustomClusterResources bool
-	CustomClusterResources    *k8s.ClusterResources
+	UseCustomClusterResources   bool
+	CustomClusterResources      *k8s.ClusterResources
+	FilestoreMigrationJobStatus *model.FilestoreMigrationJobStatus
+	RestartIncomplete           bool
 }
 
 func (p *mockInstallationProvisioner) CreateClusterInstallation(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, awsClient aws.AWS) error {
@@ -216,6 +229,14 @@ func (p *mockInstallationProvisioner) GetClusterResources(cluster *model.Cluster
 			MilliUsedCPU:     100,
 			MilliTotalMemory: 100000000000000,
 			MilliUsedMemory:  100,
+			NodeGroups: map[string]*k8s.ClusterResources{
+				"memory": {
+					MilliTotalCPU:    100000,
+					MilliUsedCPU:     100,
+					MilliTotalMemory: 100000000000000,
+					MilliUsedMemory:  100,
+				},
+			},
 		},
 		nil
 }
@@ -224,9 +245,35 @@ func (p *mockInstallationProvisioner) GetPublicLoadBalancerEndpoint(cluster *mod
 	return "example.elb.us-east-1.amazonaws.com", nil
 }
 
+func (p *mockInstallationProvisioner) CreateFilestoreMigrationJob(cluster *model.Cluster, installation *model.Installation, clusterInstallation *model.ClusterInstallation, config *model.FilestoreMigrationConfig) error {
+	return nil
+}
+
+func (p *mockInstallationProvisioner) GetFilestoreMigrationJobStatus(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (*model.FilestoreMigrationJobStatus, error) {
+	if p.FilestoreMigrationJobStatus != nil {
+		return p.FilestoreMigrationJobStatus, nil
+	}
+
+	return &model.FilestoreMigrationJobStatus{}, nil
+}
+
+func (p *mockInstallationProvisioner) DeleteFilestoreMigrationJob(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) error {
+	return nil
+}
+
+func (p *mockInstallationProvisioner) RestartClusterInstallation(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) error {
+	return nil
+}
+
+func (p *mockInstallationProvisioner) IsClusterInstallationRestartComplete(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (bool, error) {
+	return !p.RestartIncomplete, nil
+}
+
 // TODO(gsagula): this can be replaced with /internal/mocks/aws-tools/AWS.go so that inputs and other variants
 // can be tested.
-type mockAWS struct{}
+type mockAWS struct {
+	VpcPool []*model.VPC
+}
 
 func (a *mockAWS) GetCertificateSummaryByTag(key, value string, logger log.FieldLogger) (*acm.CertificateSummary, error) {
 	return nil, nil
@@ -244,6 +291,10 @@ func (a *mockAWS) DynamoDBEnsureTableDeleted(tableName string, logger log.FieldL
 	return nil
 }
 
+func (a *mockAWS) S3EnsureBucketCreated(bucketName string, logger log.FieldLogger) error {
+	return nil
+}
+
 func (a *mockAWS) S3EnsureBucketDeleted(bucketName string, logger log.FieldLogger) error {
 	return nil
 }
@@ -326,12 +377,40 @@ func (a *mockAWS) GenerateBifrostUtilitySecret(clusterID string, logger log.Fiel
 	return nil, nil
 }
 
+func (a *mockAWS) GetVpcPool(logger log.FieldLogger) ([]*model.VPC, error) {
+	return a.VpcPool, nil
+}
+
+func (a *mockAWS) GetVpcDBClusterIDs(clusterID string, logger log.FieldLogger) ([]string, error) {
+	return []string{}, nil
+}
+
+func (a *mockAWS) EnsureS3BucketPolicyCreated(policyName, bucketName string, logger log.FieldLogger) error {
+	return nil
+}
+
+func (a *mockAWS) EnsureS3BucketPolicyDeleted(policyName string, logger log.FieldLogger) error {
+	return nil
+}
+
+func (a *mockAWS) GetKubeconfigSecret(secretName string) (string, error) {
+	return "", nil
+}
+
+func (a *mockAWS) EnsureKubeconfigSecretDeleted(secretName string, logger log.FieldLogger) error {
+	return nil
+}
+
+func (a *mockAWS) ClientForCluster(cluster *model.Cluster) (aws.AWS, error) {
+	return a, nil
+}
+
 func TestInstallationSupervisorDo(t *testing.T) {
 	t.Run("no installations pending work", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		mockStore := &mockInstallationStore{}
 
-		supervisor := supervisor.NewInstallationSupervisor(mockStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(mockStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 		err := supervisor.Do()
 		require.NoError(t, err)
 
@@ -349,7 +428,7 @@ func TestInstallationSupervisorDo(t *testing.T) {
 		mockStore.Installation = mockStore.UnlockedInstallationsPendingWork[0]
 		mockStore.UnlockChan = make(chan interface{})
 
-		supervisor := supervisor.NewInstallationSupervisor(mockStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(mockStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 		err := supervisor.Do()
 		require.NoError(t, err)
 
@@ -405,7 +484,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("unexpected state", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -443,7 +522,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("state has changed since installation was selected to be worked on", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -476,7 +555,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations not yet created, no clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		owner := model.NewID()
 		groupID := model.NewID()
@@ -501,7 +580,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations not yet created, cluster doesn't allow scheduling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		cluster.AllowInstallations = false
@@ -528,10 +607,105 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectClusterInstallations(t, sqlStore, installation, 0, "")
 	})
 
+	t.Run("creation requested, cluster installations not yet created, eks cluster", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		cluster.Provisioner = model.ProvisionerEKS
+		cluster.ProvisionerMetadataKops = nil
+		cluster.ProvisionerMetadataEKS = &model.EKSMetadata{Name: "eks-cluster"}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+	})
+
+	t.Run("creation requested, cluster installations not yet created, cluster missing node group", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:   owner,
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			NodeGroup: "memory",
+			GroupID:   &groupID,
+			State:     model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationNoCompatibleClusters)
+		expectClusterInstallations(t, sqlStore, installation, 0, "")
+	})
+
+	t.Run("creation requested, cluster installations not yet created, cluster with node group", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		cluster.ProvisionerMetadataKops.NodeGroups = []*model.KopsNodeGroup{
+			{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 2},
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:   owner,
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			NodeGroup: "memory",
+			GroupID:   &groupID,
+			State:     model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+	})
+
 	t.Run("creation requested, cluster installations not yet created, no empty clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -569,7 +743,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -607,7 +781,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -645,7 +819,8 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation DNS, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		dnsProvider := dns.NewMemoryProvider()
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dnsProvider, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -678,12 +853,13 @@ func TestInstallationSupervisor(t *testing.T) {
 		supervisor.Supervise(installation)
 		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+		assert.Equal(t, []string{"example.elb.us-east-1.amazonaws.com"}, dnsProvider.GetCNAME("dns.example.com"))
 	})
 
 	t.Run("creation requested, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -721,7 +897,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations stable, in group with different sequence", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -772,7 +948,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("pre provisioning requested, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -810,7 +986,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation requested, cluster installations failed", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -848,7 +1024,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation in progress, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -886,7 +1062,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation in progress, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -924,7 +1100,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation in progress, cluster installations stable, in group with same sequence", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -983,7 +1159,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation in progress, cluster installations failed", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1021,7 +1197,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("creation final tasks, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1059,7 +1235,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("no compatible clusters, cluster installations not yet created, no clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		owner := model.NewID()
 		groupID := model.NewID()
@@ -1084,7 +1260,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("no compatible clusters, cluster installations not yet created, no available clusters", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1122,7 +1298,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("no compatible clusters, cluster installations not yet created, available cluster", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1151,7 +1327,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("update requested, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1189,7 +1365,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("update requested, cluster installations stable, in group with different sequence", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1240,7 +1416,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("update in progress, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1278,7 +1454,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("update requested, cluster installations reconciling, in group with different sequence", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1329,7 +1505,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("update in progress, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1367,7 +1543,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("update requested, cluster installations stable, in group with same sequence", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1428,7 +1604,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("hibernation requested, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1466,7 +1642,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("hibernation in progress, cluster installations reconciling", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1504,7 +1680,7 @@ func TestInstallationSupervisor(t *testing.T) {
 	t.Run("hibernation in progress, cluster installations stable", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
@@ -1539,25 +1715,31 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
 	})
 
-	t.Run("deletion requested, cluster installations stable", func(t *testing.T) {
+	t.Run("filestore migration in progress, job running", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		provisioner := &mockInstallationProvisioner{
+			FilestoreMigrationJobStatus: &model.FilestoreMigrationJobStatus{},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
 		require.NoError(t, err)
 
-		owner := model.NewID()
-		groupID := model.NewID()
 		installation := &model.Installation{
-			OwnerID:  owner,
-			Version:  "version",
-			DNS:      "dns.example.com",
-			Size:     mmv1alpha1.Size100String,
-			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionRequested,
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateFilestoreMigrationInProgress,
+			FilestoreMigration: &model.FilestoreMigration{
+				SourceFilestore: model.InstallationFilestoreMinioOperator,
+				TargetFilestore: model.InstallationFilestoreAwsS3,
+				CopyAttempts:    1,
+			},
 		}
 
 		err = sqlStore.CreateInstallation(installation, nil)
@@ -1573,29 +1755,35 @@ func TestInstallationSupervisor(t *testing.T) {
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateFilestoreMigrationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
 	})
 
-	t.Run("deletion requested, cluster installations deleting", func(t *testing.T) {
+	t.Run("filestore migration in progress, job failed", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		provisioner := &mockInstallationProvisioner{
+			FilestoreMigrationJobStatus: &model.FilestoreMigrationJobStatus{Failed: true},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
 		require.NoError(t, err)
 
-		owner := model.NewID()
-		groupID := model.NewID()
 		installation := &model.Installation{
-			OwnerID:  owner,
-			Version:  "version",
-			DNS:      "dns.example.com",
-			Size:     mmv1alpha1.Size100String,
-			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionRequested,
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateFilestoreMigrationInProgress,
+			FilestoreMigration: &model.FilestoreMigration{
+				SourceFilestore: model.InstallationFilestoreMinioOperator,
+				TargetFilestore: model.InstallationFilestoreAwsS3,
+				CopyAttempts:    1,
+			},
 		}
 
 		err = sqlStore.CreateInstallation(installation, nil)
@@ -1605,35 +1793,41 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateDeletionRequested,
+			State:          model.ClusterInstallationStateStable,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateFilestoreMigrationFailed)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
 	})
 
-	t.Run("deletion in progress, cluster installations failed", func(t *testing.T) {
+	t.Run("filestore migration in progress, objects missing, retry copy", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		provisioner := &mockInstallationProvisioner{
+			FilestoreMigrationJobStatus: &model.FilestoreMigrationJobStatus{Complete: true, SourceObjectCount: 10, TargetObjectCount: 8},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
 		require.NoError(t, err)
 
-		owner := model.NewID()
-		groupID := model.NewID()
 		installation := &model.Installation{
-			OwnerID:  owner,
-			Version:  "version",
-			DNS:      "dns.example.com",
-			Size:     mmv1alpha1.Size100String,
-			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionInProgress,
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateFilestoreMigrationInProgress,
+			FilestoreMigration: &model.FilestoreMigration{
+				SourceFilestore: model.InstallationFilestoreMinioOperator,
+				TargetFilestore: model.InstallationFilestoreAwsS3,
+				CopyAttempts:    1,
+			},
 		}
 
 		err = sqlStore.CreateInstallation(installation, nil)
@@ -1643,35 +1837,48 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateDeletionFailed,
+			State:          model.ClusterInstallationStateStable,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionFailed)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionFailed)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateFilestoreMigrationRequested)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.NotNil(t, installation.FilestoreMigration)
+		assert.Equal(t, int64(10), installation.FilestoreMigration.SourceObjectCount)
+		assert.Equal(t, int64(8), installation.FilestoreMigration.TargetObjectCount)
+		assert.Equal(t, model.InstallationFilestoreMinioOperator, installation.Filestore)
 	})
 
-	t.Run("deletion requested, cluster installations failed, so retry", func(t *testing.T) {
+	t.Run("filestore migration in progress, objects missing, max copy attempts", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		provisioner := &mockInstallationProvisioner{
+			FilestoreMigrationJobStatus: &model.FilestoreMigrationJobStatus{Complete: true, SourceObjectCount: 10, TargetObjectCount: 8},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
 		require.NoError(t, err)
 
-		owner := model.NewID()
-		groupID := model.NewID()
 		installation := &model.Installation{
-			OwnerID:  owner,
-			Version:  "version",
-			DNS:      "dns.example.com",
-			Size:     mmv1alpha1.Size100String,
-			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionRequested,
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateFilestoreMigrationInProgress,
+			FilestoreMigration: &model.FilestoreMigration{
+				SourceFilestore: model.InstallationFilestoreMinioOperator,
+				TargetFilestore: model.InstallationFilestoreAwsS3,
+				CopyAttempts:    5,
+			},
 		}
 
 		err = sqlStore.CreateInstallation(installation, nil)
@@ -1681,35 +1888,41 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateDeletionFailed,
+			State:          model.ClusterInstallationStateStable,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateFilestoreMigrationFailed)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
 	})
 
-	t.Run("creation requested, cluster installations deleted", func(t *testing.T) {
+	t.Run("filestore migration in progress, objects copied, final copy", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		provisioner := &mockInstallationProvisioner{
+			FilestoreMigrationJobStatus: &model.FilestoreMigrationJobStatus{Complete: true, SourceObjectCount: 10, TargetObjectCount: 10},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
 		err := sqlStore.CreateCluster(cluster, nil)
 		require.NoError(t, err)
 
-		owner := model.NewID()
-		groupID := model.NewID()
 		installation := &model.Installation{
-			OwnerID:  owner,
-			Version:  "version",
-			DNS:      "dns.example.com",
-			Size:     mmv1alpha1.Size100String,
-			Affinity: model.InstallationAffinityIsolated,
-			GroupID:  &groupID,
-			State:    model.InstallationStateDeletionRequested,
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateFilestoreMigrationInProgress,
+			FilestoreMigration: &model.FilestoreMigration{
+				SourceFilestore: model.InstallationFilestoreMinioOperator,
+				TargetFilestore: model.InstallationFilestoreAwsS3,
+				CopyAttempts:    1,
+			},
 		}
 
 		err = sqlStore.CreateInstallation(installation, nil)
@@ -1719,57 +1932,591 @@ func TestInstallationSupervisor(t *testing.T) {
 			ClusterID:      cluster.ID,
 			InstallationID: installation.ID,
 			Namespace:      "namespace",
-			State:          model.ClusterInstallationStateDeleted,
+			State:          model.ClusterInstallationStateStable,
 		}
 		err = sqlStore.CreateClusterInstallation(clusterInstallation)
 		require.NoError(t, err)
 
 		supervisor.Supervise(installation)
-		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeleted)
-		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeleted)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateFilestoreMigrationRequested)
+		// The app is scaled down to freeze writes before the final copy.
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.NotNil(t, installation.FilestoreMigration)
+		assert.True(t, installation.FilestoreMigration.FinalSync)
+		assert.Equal(t, model.InstallationFilestoreMinioOperator, installation.Filestore)
 	})
 
-	t.Run("multitenant", func(t *testing.T) {
-		t.Run("creation requested, cluster installations not yet created, available cluster", func(t *testing.T) {
-			logger := testlib.MakeLogger(t)
-			sqlStore := store.MakeTestSQLStore(t, logger)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+	t.Run("filestore migration requested, final copy waits for writes to freeze", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
-			cluster := standardStableTestCluster()
-			err := sqlStore.CreateCluster(cluster, nil)
-			require.NoError(t, err)
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
 
-			owner := model.NewID()
-			groupID := model.NewID()
-			installation := &model.Installation{
-				OwnerID:  owner,
-				Version:  "version",
-				DNS:      "dns.example.com",
-				Size:     mmv1alpha1.Size100String,
-				Affinity: model.InstallationAffinityMultiTenant,
-				GroupID:  &groupID,
-				State:    model.InstallationStateCreationRequested,
-			}
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateFilestoreMigrationRequested,
+			FilestoreMigration: &model.FilestoreMigration{
+				SourceFilestore: model.InstallationFilestoreMinioOperator,
+				TargetFilestore: model.InstallationFilestoreAwsS3,
+				CopyAttempts:    1,
+				FinalSync:       true,
+			},
+		}
 
-			err = sqlStore.CreateInstallation(installation, nil)
-			require.NoError(t, err)
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
 
-			supervisor.Supervise(installation)
-			expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
-			expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
-			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
-		})
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateReconciling,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
 
-		t.Run("creation requested, cluster installations not yet created, 3 installations, available cluster", func(t *testing.T) {
-			logger := testlib.MakeLogger(t)
-			sqlStore := store.MakeTestSQLStore(t, logger)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateFilestoreMigrationRequested)
 
-			cluster := standardStableTestCluster()
-			err := sqlStore.CreateCluster(cluster, nil)
-			require.NoError(t, err)
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, installation.FilestoreMigration.CopyAttempts)
+	})
 
-			for i := 1; i < 3; i++ {
+	t.Run("filestore migration in progress, final copy failed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationProvisioner{
+			FilestoreMigrationJobStatus: &model.FilestoreMigrationJobStatus{Failed: true},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateFilestoreMigrationInProgress,
+			FilestoreMigration: &model.FilestoreMigration{
+				SourceFilestore: model.InstallationFilestoreMinioOperator,
+				TargetFilestore: model.InstallationFilestoreAwsS3,
+				CopyAttempts:    2,
+				FinalSync:       true,
+			},
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateFilestoreMigrationFailed)
+
+		// The app was scaled back up, so a retry freezes writes again.
+		installation, err = sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		assert.False(t, installation.FilestoreMigration.FinalSync)
+	})
+
+	t.Run("filestore migration in progress, cutover already done", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationProvisioner{
+			// The job is not checked again once the cutover is done.
+			FilestoreMigrationJobStatus: &model.FilestoreMigrationJobStatus{Failed: true},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, provisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Filestore: model.InstallationFilestoreAwsS3,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateFilestoreMigrationInProgress,
+			FilestoreMigration: &model.FilestoreMigration{
+				SourceFilestore: model.InstallationFilestoreMinioOperator,
+				TargetFilestore: model.InstallationFilestoreAwsS3,
+				CopyAttempts:    2,
+				FinalSync:       true,
+			},
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateFilestoreMigrationFinalizing)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+	})
+
+	t.Run("credential rotation requested, cluster installations stable", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateCredentialRotationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCredentialRotationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+	})
+
+	t.Run("credential rotation in progress, cluster installations stable", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateCredentialRotationInProgress,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCredentialRotationFinalizing)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+	})
+
+	t.Run("credential rotation finalizing, cluster installations reconciling", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateCredentialRotationFinalizing,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateReconciling,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCredentialRotationFinalizing)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateReconciling)
+
+		storedInstallation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		assert.Zero(t, storedInstallation.CredentialsRotatedAt)
+	})
+
+	t.Run("credential rotation finalizing, cluster installations still restarting", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{RestartIncomplete: true}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateCredentialRotationFinalizing,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCredentialRotationFinalizing)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+
+		storedInstallation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		assert.Zero(t, storedInstallation.CredentialsRotatedAt)
+	})
+
+	t.Run("credential rotation finalizing, cluster installations restarted", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     model.InstallationStateCredentialRotationFinalizing,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateStable)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateStable)
+
+		storedInstallation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		assert.NotZero(t, storedInstallation.CredentialsRotatedAt)
+	})
+
+	t.Run("deletion requested, cluster installations stable", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateStable,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+	})
+
+	t.Run("deletion requested, cluster installations deleting", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeletionRequested,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+	})
+
+	t.Run("deletion in progress, cluster installations failed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionInProgress,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeletionFailed,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionFailed)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionFailed)
+	})
+
+	t.Run("deletion requested, cluster installations failed, so retry", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeletionFailed,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeletionInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeletionRequested)
+	})
+
+	t.Run("creation requested, cluster installations deleted", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityIsolated,
+			GroupID:  &groupID,
+			State:    model.InstallationStateDeletionRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		clusterInstallation := &model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      "namespace",
+			State:          model.ClusterInstallationStateDeleted,
+		}
+		err = sqlStore.CreateClusterInstallation(clusterInstallation)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateDeleted)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateDeleted)
+	})
+
+	t.Run("multitenant", func(t *testing.T) {
+		t.Run("creation requested, cluster installations not yet created, available cluster", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+			cluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(cluster, nil)
+			require.NoError(t, err)
+
+			owner := model.NewID()
+			groupID := model.NewID()
+			installation := &model.Installation{
+				OwnerID:  owner,
+				Version:  "version",
+				DNS:      "dns.example.com",
+				Size:     mmv1alpha1.Size100String,
+				Affinity: model.InstallationAffinityMultiTenant,
+				GroupID:  &groupID,
+				State:    model.InstallationStateCreationRequested,
+			}
+
+			err = sqlStore.CreateInstallation(installation, nil)
+			require.NoError(t, err)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+			expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+		})
+
+		t.Run("creation requested, cluster installations not yet created, 3 installations, available cluster", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+			cluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(cluster, nil)
+			require.NoError(t, err)
+
+			for i := 1; i < 3; i++ {
 				t.Run(fmt.Sprintf("cluster-%d", i), func(t *testing.T) {
 					owner := model.NewID()
 					groupID := model.NewID()
@@ -1797,7 +2544,7 @@ func TestInstallationSupervisor(t *testing.T) {
 		t.Run("creation requested, cluster installations not yet created, 1 isolated and 1 multitenant, available cluster", func(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster, nil)
@@ -1856,7 +2603,7 @@ func TestInstallationSupervisor(t *testing.T) {
 					MilliUsedMemory:  100,
 				},
 			}
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster, nil)
@@ -1877,6 +2624,69 @@ func TestInstallationSupervisor(t *testing.T) {
 			err = sqlStore.CreateInstallation(installation, nil)
 			require.NoError(t, err)
 
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationNoCompatibleClusters)
+			expectClusterInstallations(t, sqlStore, installation, 0, "")
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 0)
+		})
+		t.Run("creation requested, cluster installations not yet created, resource quota exceeds cluster resources", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			mockInstallationProvisioner := &mockInstallationProvisioner{}
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+			cluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(cluster, nil)
+			require.NoError(t, err)
+
+			owner := model.NewID()
+			groupID := model.NewID()
+			installation := &model.Installation{
+				OwnerID:       owner,
+				Version:       "version",
+				DNS:           "dns.example.com",
+				Size:          mmv1alpha1.Size100String,
+				Affinity:      model.InstallationAffinityMultiTenant,
+				GroupID:       &groupID,
+				State:         model.InstallationStateCreationRequested,
+				ResourceQuota: &model.InstallationResourceQuota{CPU: "100"},
+			}
+
+			err = sqlStore.CreateInstallation(installation, nil)
+			require.NoError(t, err)
+
+			supervisor.Supervise(installation)
+			expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationNoCompatibleClusters)
+			expectClusterInstallations(t, sqlStore, installation, 0, "")
+			expectClusterInstallationsOnCluster(t, sqlStore, cluster, 0)
+		})
+
+		t.Run("creation requested, cluster installations not yet created, custom size exceeds cluster resources", func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			mockInstallationProvisioner := &mockInstallationProvisioner{}
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+
+			cluster := standardStableTestCluster()
+			err := sqlStore.CreateCluster(cluster, nil)
+			require.NoError(t, err)
+
+			owner := model.NewID()
+			groupID := model.NewID()
+			installation := &model.Installation{
+				OwnerID:    owner,
+				Version:    "version",
+				DNS:        "dns.example.com",
+				Size:       mmv1alpha1.Size100String,
+				Affinity:   model.InstallationAffinityMultiTenant,
+				GroupID:    &groupID,
+				State:      model.InstallationStateCreationRequested,
+				CustomSize: &model.InstallationCustomSize{App: &model.InstallationComponentSize{Replicas: 20, CPURequest: "5"}},
+			}
+
+			err = sqlStore.CreateInstallation(installation, nil)
+			require.NoError(t, err)
+
 			supervisor.Supervise(installation)
 			expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationNoCompatibleClusters)
 			expectClusterInstallations(t, sqlStore, installation, 0, "")
@@ -1896,9 +2706,51 @@ func TestInstallationSupervisor(t *testing.T) {
 				MilliUsedMemory:  100,
 			},
 		}
-		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, "instanceID", 80, 2, false, false, &utils.ResourceUtil{}, logger)
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 2, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		owner := model.NewID()
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:  owner,
+			Version:  "version",
+			DNS:      "dns.example.com",
+			Size:     mmv1alpha1.Size100String,
+			Affinity: model.InstallationAffinityMultiTenant,
+			GroupID:  &groupID,
+			State:    model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+		expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+	})
+
+	t.Run("creation requested, cluster installations not yet created, insufficient cluster resources, but autoscaling", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockInstallationProvisioner := &mockInstallationProvisioner{
+			UseCustomClusterResources: true,
+			CustomClusterResources: &k8s.ClusterResources{
+				MilliTotalCPU:    4000,
+				MilliUsedCPU:     3900,
+				MilliTotalMemory: 16000000000000,
+				MilliUsedMemory:  15900000000000,
+				NodeCount:        1,
+			},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 2, false, false, &utils.ResourceUtil{}, logger)
 
 		cluster := standardStableTestCluster()
+		cluster.ProvisionerMetadataKops.NodeMaxCount = 20
+		cluster.ProvisionerMetadataKops.Autoscaling = true
 		err := sqlStore.CreateCluster(cluster, nil)
 		require.NoError(t, err)
 
@@ -1921,6 +2773,167 @@ func TestInstallationSupervisor(t *testing.T) {
 		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
 		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
 		expectClusterInstallationsOnCluster(t, sqlStore, cluster, 1)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Nil(t, cluster.ProvisionerMetadataKops.ChangeRequest)
+	})
+
+	t.Run("creation requested, cluster installations not yet created, insufficient node group resources, but scale", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockInstallationProvisioner := &mockInstallationProvisioner{
+			UseCustomClusterResources: true,
+			CustomClusterResources: &k8s.ClusterResources{
+				MilliTotalCPU:    100000,
+				MilliUsedCPU:     100,
+				MilliTotalMemory: 100000000000000,
+				MilliUsedMemory:  100,
+				NodeGroups: map[string]*k8s.ClusterResources{
+					"memory": {
+						MilliTotalCPU:    200,
+						MilliUsedCPU:     100,
+						MilliTotalMemory: 200,
+						MilliUsedMemory:  100,
+					},
+				},
+			},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 2, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		cluster.ProvisionerMetadataKops.NodeGroups = []*model.KopsNodeGroup{
+			{Name: "memory", InstanceType: "r5.xlarge", MinCount: 1, MaxCount: 2},
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityMultiTenant,
+			NodeGroup: "memory",
+			GroupID:   &groupID,
+			State:     model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateResizeRequested, cluster.State)
+		require.NotNil(t, cluster.ProvisionerMetadataKops.ChangeRequest)
+		assert.Empty(t, cluster.ProvisionerMetadataKops.ChangeRequest.NodeMinCount)
+		assert.Equal(t, []*model.KopsNodeGroup{
+			{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 2},
+		}, cluster.ProvisionerMetadataKops.ChangeRequest.NodeGroups)
+	})
+
+	t.Run("creation requested, cluster installations not yet created, insufficient node group resources, but autoscaling", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockInstallationProvisioner := &mockInstallationProvisioner{
+			UseCustomClusterResources: true,
+			CustomClusterResources: &k8s.ClusterResources{
+				MilliTotalCPU:    100000,
+				MilliUsedCPU:     100,
+				MilliTotalMemory: 100000000000000,
+				MilliUsedMemory:  100,
+				NodeGroups: map[string]*k8s.ClusterResources{
+					"memory": {
+						MilliTotalCPU:    4000,
+						MilliUsedCPU:     3900,
+						MilliTotalMemory: 16000000000000,
+						MilliUsedMemory:  15900000000000,
+						NodeCount:        1,
+					},
+				},
+			},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 2, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		cluster.ProvisionerMetadataKops.NodeMaxCount = 20
+		cluster.ProvisionerMetadataKops.Autoscaling = true
+		cluster.ProvisionerMetadataKops.NodeGroups = []*model.KopsNodeGroup{
+			{Name: "memory", InstanceType: "r5.xlarge", MinCount: 1, MaxCount: 20},
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityMultiTenant,
+			NodeGroup: "memory",
+			GroupID:   &groupID,
+			State:     model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationInProgress)
+		expectClusterInstallations(t, sqlStore, installation, 1, model.ClusterInstallationStateCreationRequested)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ClusterStateStable, cluster.State)
+		require.Nil(t, cluster.ProvisionerMetadataKops.ChangeRequest)
+	})
+
+	t.Run("creation requested, cluster installations not yet created, insufficient node group resources", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		mockInstallationProvisioner := &mockInstallationProvisioner{
+			UseCustomClusterResources: true,
+			CustomClusterResources: &k8s.ClusterResources{
+				MilliTotalCPU:    100000,
+				MilliUsedCPU:     100,
+				MilliTotalMemory: 100000000000000,
+				MilliUsedMemory:  100,
+			},
+		}
+		supervisor := supervisor.NewInstallationSupervisor(sqlStore, mockInstallationProvisioner, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 2, false, false, &utils.ResourceUtil{}, logger)
+
+		cluster := standardStableTestCluster()
+		cluster.ProvisionerMetadataKops.NodeGroups = []*model.KopsNodeGroup{
+			{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 2},
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		groupID := model.NewID()
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityMultiTenant,
+			NodeGroup: "memory",
+			GroupID:   &groupID,
+			State:     model.InstallationStateCreationRequested,
+		}
+
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		supervisor.Supervise(installation)
+		expectInstallationState(t, sqlStore, installation, model.InstallationStateCreationNoCompatibleClusters)
+		expectClusterInstallations(t, sqlStore, installation, 0, "")
 	})
 
 	t.Run("cluster with proper annotations selected", func(t *testing.T) {
@@ -1946,7 +2959,7 @@ func TestInstallationSupervisor(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
 			defer store.CloseConnection(t, sqlStore)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster, annotations)
@@ -1967,7 +2980,7 @@ func TestInstallationSupervisor(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
 			defer store.CloseConnection(t, sqlStore)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster, nil)
@@ -1988,7 +3001,7 @@ func TestInstallationSupervisor(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
 			defer store.CloseConnection(t, sqlStore)
-			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
+			supervisor := supervisor.NewInstallationSupervisor(sqlStore, &mockInstallationProvisioner{}, &mockAWS{}, dns.NewMemoryProvider(), "instanceID", 80, 0, false, false, &utils.ResourceUtil{}, logger)
 
 			cluster := standardStableTestCluster()
 			err := sqlStore.CreateCluster(cluster, annotations)
@@ -0,0 +1,203 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// installationUsageStore abstracts the database operations required by the
+// installation usage supervisor.
+type installationUsageStore interface {
+	GetInstallations(filter *model.InstallationFilter, includeGroupConfig, includeGroupConfigOverrides bool) ([]*model.Installation, error)
+	LockInstallation(installationID, lockerID string) (bool, error)
+	UnlockInstallation(installationID, lockerID string, force bool) (bool, error)
+
+	GetCluster(id string) (*model.Cluster, error)
+	GetClusterInstallations(*model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error)
+
+	GetMultitenantDatabase(multitenantdatabaseID string) (*model.MultitenantDatabase, error)
+	GetMultitenantDatabases(filter *model.MultitenantDatabaseFilter) ([]*model.MultitenantDatabase, error)
+	GetMultitenantDatabaseForInstallationID(installationID string) (*model.MultitenantDatabase, error)
+	CreateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase) error
+	UpdateMultitenantDatabase(multitenantDatabase *model.MultitenantDatabase) error
+	LockMultitenantDatabase(multitenantdatabaseID, lockerID string) (bool, error)
+	UnlockMultitenantDatabase(multitenantdatabaseID, lockerID string, force bool) (bool, error)
+	GetSingleTenantDatabaseConfigForInstallation(installationID string) (*model.SingleTenantDatabaseConfig, error)
+
+	GetLatestInstallationUsage(installationID string) (*model.InstallationUsage, error)
+	CreateInstallationUsage(usage *model.InstallationUsage) error
+}
+
+// installationUsageProvisioner abstracts the provisioning operations required
+// by the installation usage supervisor.
+type installationUsageProvisioner interface {
+	GetClusterInstallationVolumeUsage(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (*model.ClusterInstallationVolumeUsage, error)
+}
+
+// InstallationUsageSupervisor periodically measures the storage used by the
+// database and filestore of each stable installation and records it.
+type InstallationUsageSupervisor struct {
+	store        installationUsageStore
+	provisioner  installationUsageProvisioner
+	resourceUtil *utils.ResourceUtil
+	instanceID   string
+	interval     time.Duration
+	logger       log.FieldLogger
+}
+
+// NewInstallationUsageSupervisor creates a new InstallationUsageSupervisor.
+func NewInstallationUsageSupervisor(store installationUsageStore, provisioner installationUsageProvisioner, resourceUtil *utils.ResourceUtil, instanceID string, interval time.Duration, logger log.FieldLogger) *InstallationUsageSupervisor {
+	return &InstallationUsageSupervisor{
+		store:        store,
+		provisioner:  provisioner,
+		resourceUtil: resourceUtil,
+		instanceID:   instanceID,
+		interval:     interval,
+		logger:       logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the installation usage
+// supervisor.
+func (s *InstallationUsageSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down installation usage supervisor")
+}
+
+// Do looks for stable installations that have not been measured within the
+// metering interval and records their storage usage.
+func (s *InstallationUsageSupervisor) Do() error {
+	installations, err := s.store.GetInstallations(&model.InstallationFilter{
+		PerPage: model.AllPerPage,
+	}, false, false)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for installations")
+		return nil
+	}
+
+	for _, installation := range installations {
+		if installation.State != model.InstallationStateStable {
+			continue
+		}
+		s.Supervise(installation)
+	}
+
+	return nil
+}
+
+// Supervise measures and records the storage usage of the given installation
+// if the metering interval has passed since its last measurement.
+func (s *InstallationUsageSupervisor) Supervise(installation *model.Installation) {
+	logger := s.logger.WithFields(log.Fields{
+		"installation": installation.ID,
+	})
+
+	latest, err := s.store.GetLatestInstallationUsage(installation.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get latest installation usage")
+		return
+	}
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	if latest != nil && now-latest.CreateAt < s.interval.Milliseconds() {
+		return
+	}
+
+	lock := newInstallationLock(installation.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	logger.Debug("Measuring installation usage")
+
+	usage, err := s.measureUsage(installation, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to measure installation usage")
+		return
+	}
+
+	err = s.store.CreateInstallationUsage(usage)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record installation usage")
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"database-bytes":  usage.DatabaseBytes,
+		"filestore-bytes": usage.FilestoreBytes,
+	}).Debug("Recorded installation usage")
+}
+
+func (s *InstallationUsageSupervisor) measureUsage(installation *model.Installation, logger log.FieldLogger) (*model.InstallationUsage, error) {
+	usage := &model.InstallationUsage{
+		InstallationID: installation.ID,
+	}
+
+	if installation.InternalDatabase() || installation.InternalFilestore() {
+		volumeUsage, err := s.getVolumeUsage(installation)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get cluster installation volume usage")
+		}
+		usage.DatabaseBytes = volumeUsage.DatabaseBytes
+		usage.FilestoreBytes = volumeUsage.FilestoreBytes
+	}
+
+	resourceUtil, err := getInstallationResourceUtil(s.store, s.resourceUtil, installation)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get installation resource util")
+	}
+
+	if !installation.InternalDatabase() {
+		databaseUsage, err := resourceUtil.GetDatabase(installation).Usage(s.store, logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get database usage")
+		}
+		if databaseUsage != nil {
+			usage.DatabaseBytes = databaseUsage.Bytes
+		}
+	}
+
+	if !installation.InternalFilestore() {
+		filestoreUsage, err := resourceUtil.GetFilestore(installation).Usage(s.store, logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get filestore usage")
+		}
+		if filestoreUsage != nil {
+			usage.FilestoreBytes = filestoreUsage.Bytes
+			usage.FilestoreObjectCount = filestoreUsage.ObjectCount
+		}
+	}
+
+	return usage, nil
+}
+
+func (s *InstallationUsageSupervisor) getVolumeUsage(installation *model.Installation) (*model.ClusterInstallationVolumeUsage, error) {
+	clusterInstallations, err := s.store.GetClusterInstallations(&model.ClusterInstallationFilter{
+		PerPage:        model.AllPerPage,
+		InstallationID: installation.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find cluster installations")
+	}
+	if len(clusterInstallations) != 1 {
+		return nil, errors.Errorf("expected exactly one cluster installation, but found %d", len(clusterInstallations))
+	}
+	clusterInstallation := clusterInstallations[0]
+
+	cluster, err := s.store.GetCluster(clusterInstallation.ClusterID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query cluster %s", clusterInstallation.ClusterID)
+	}
+	if cluster == nil {
+		return nil, errors.Errorf("failed to find cluster %s", clusterInstallation.ClusterID)
+	}
+
+	return s.provisioner.GetClusterInstallationVolumeUsage(cluster, clusterInstallation)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/internal/tools/utils"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInstallationUsageProvisioner struct {
+	VolumeUsage *model.ClusterInstallationVolumeUsage
+	Err         error
+	Calls       int
+}
+
+func (p *mockInstallationUsageProvisioner) GetClusterInstallationVolumeUsage(cluster *model.Cluster, clusterInstallation *model.ClusterInstallation) (*model.ClusterInstallationVolumeUsage, error) {
+	p.Calls++
+	return p.VolumeUsage, p.Err
+}
+
+func TestInstallationUsageSupervisor(t *testing.T) {
+	setup := func(t *testing.T, sqlStore *store.SQLStore, state string) *model.Installation {
+		t.Helper()
+
+		cluster := &model.Cluster{State: model.ClusterStateStable}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		installation := &model.Installation{
+			OwnerID:   model.NewID(),
+			Version:   "version",
+			DNS:       "dns.example.com",
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+			Size:      mmv1alpha1.Size100String,
+			Affinity:  model.InstallationAffinityIsolated,
+			State:     state,
+		}
+		err = sqlStore.CreateInstallation(installation, nil)
+		require.NoError(t, err)
+
+		err = sqlStore.CreateClusterInstallation(&model.ClusterInstallation{
+			ClusterID:      cluster.ID,
+			InstallationID: installation.ID,
+			Namespace:      installation.ID,
+			State:          model.ClusterInstallationStateStable,
+		})
+		require.NoError(t, err)
+
+		return installation
+	}
+
+	expectUsages := func(t *testing.T, sqlStore *store.SQLStore, installation *model.Installation, expectedCount int) []*model.InstallationUsage {
+		t.Helper()
+
+		usages, err := sqlStore.GetInstallationUsages(&model.InstallationUsageFilter{
+			InstallationID: installation.ID,
+			PerPage:        model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Len(t, usages, expectedCount)
+
+		return usages
+	}
+
+	t.Run("stable installation is measured", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationUsageProvisioner{
+			VolumeUsage: &model.ClusterInstallationVolumeUsage{DatabaseBytes: 100, FilestoreBytes: 200},
+		}
+		supervisor := supervisor.NewInstallationUsageSupervisor(sqlStore, provisioner, &utils.ResourceUtil{}, "instanceID", time.Hour, logger)
+
+		installation := setup(t, sqlStore, model.InstallationStateStable)
+
+		err := supervisor.Do()
+		require.NoError(t, err)
+
+		usages := expectUsages(t, sqlStore, installation, 1)
+		require.Equal(t, int64(100), usages[0].DatabaseBytes)
+		require.Equal(t, int64(200), usages[0].FilestoreBytes)
+
+		t.Run("not measured again within interval", func(t *testing.T) {
+			err = supervisor.Do()
+			require.NoError(t, err)
+
+			expectUsages(t, sqlStore, installation, 1)
+			require.Equal(t, 1, provisioner.Calls)
+		})
+	})
+
+	t.Run("measured again after interval", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationUsageProvisioner{
+			VolumeUsage: &model.ClusterInstallationVolumeUsage{},
+		}
+		supervisor := supervisor.NewInstallationUsageSupervisor(sqlStore, provisioner, &utils.ResourceUtil{}, "instanceID", time.Millisecond, logger)
+
+		installation := setup(t, sqlStore, model.InstallationStateStable)
+
+		supervisor.Supervise(installation)
+		time.Sleep(2 * time.Millisecond)
+		supervisor.Supervise(installation)
+
+		expectUsages(t, sqlStore, installation, 2)
+	})
+
+	t.Run("unstable installation is skipped", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationUsageProvisioner{}
+		supervisor := supervisor.NewInstallationUsageSupervisor(sqlStore, provisioner, &utils.ResourceUtil{}, "instanceID", time.Hour, logger)
+
+		installation := setup(t, sqlStore, model.InstallationStateUpdateInProgress)
+
+		err := supervisor.Do()
+		require.NoError(t, err)
+
+		expectUsages(t, sqlStore, installation, 0)
+		require.Equal(t, 0, provisioner.Calls)
+	})
+
+	t.Run("measurement failure", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockInstallationUsageProvisioner{Err: errors.New("failure")}
+		supervisor := supervisor.NewInstallationUsageSupervisor(sqlStore, provisioner, &utils.ResourceUtil{}, "instanceID", time.Hour, logger)
+
+		installation := setup(t, sqlStore, model.InstallationStateStable)
+
+		supervisor.Supervise(installation)
+
+		expectUsages(t, sqlStore, installation, 0)
+
+		installation, err := sqlStore.GetInstallation(installation.ID, false, false)
+		require.NoError(t, err)
+		require.Nil(t, installation.LockAcquiredBy)
+	})
+}
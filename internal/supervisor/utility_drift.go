@@ -0,0 +1,130 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// utilityDriftStore abstracts the database operations required by the
+// utility drift supervisor.
+type utilityDriftStore interface {
+	GetCluster(clusterID string) (*model.Cluster, error)
+	GetClusters(clusterFilter *model.ClusterFilter) ([]*model.Cluster, error)
+	UpdateCluster(cluster *model.Cluster) error
+	LockCluster(clusterID, lockerID string) (bool, error)
+	UnlockCluster(clusterID string, lockerID string, force bool) (bool, error)
+}
+
+// utilityDriftProvisioner abstracts the provisioning operations required by
+// the utility drift supervisor.
+type utilityDriftProvisioner interface {
+	CheckClusterUtilityDrift(cluster *model.Cluster) (*model.UtilityDriftCheck, error)
+}
+
+// UtilityDriftSupervisor periodically compares the utilities running in each
+// stable cluster with the versions and values the provisioner last deployed
+// and records the drifted utilities.
+type UtilityDriftSupervisor struct {
+	store       utilityDriftStore
+	provisioner utilityDriftProvisioner
+	instanceID  string
+	interval    time.Duration
+	logger      log.FieldLogger
+}
+
+// NewUtilityDriftSupervisor creates a new UtilityDriftSupervisor.
+func NewUtilityDriftSupervisor(store utilityDriftStore, provisioner utilityDriftProvisioner, instanceID string, interval time.Duration, logger log.FieldLogger) *UtilityDriftSupervisor {
+	return &UtilityDriftSupervisor{
+		store:       store,
+		provisioner: provisioner,
+		instanceID:  instanceID,
+		interval:    interval,
+		logger:      logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the utility drift
+// supervisor.
+func (s *UtilityDriftSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down utility drift supervisor")
+}
+
+// Do looks for stable clusters that have not been checked within the drift
+// check interval and checks their utilities.
+func (s *UtilityDriftSupervisor) Do() error {
+	clusters, err := s.store.GetClusters(&model.ClusterFilter{
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to query for clusters")
+		return nil
+	}
+
+	for _, cluster := range clusters {
+		if cluster.State != model.ClusterStateStable {
+			continue
+		}
+		s.Supervise(cluster)
+	}
+
+	return nil
+}
+
+// Supervise checks the utilities of the given cluster for drift if the drift
+// check interval has passed since its last check.
+func (s *UtilityDriftSupervisor) Supervise(cluster *model.Cluster) {
+	logger := s.logger.WithFields(log.Fields{
+		"cluster": cluster.ID,
+	})
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	lastCheck := cluster.UtilityDriftCheck()
+	if lastCheck != nil && now-lastCheck.CheckedAt < s.interval.Milliseconds() {
+		return
+	}
+
+	lock := newClusterLock(cluster.ID, s.instanceID, s.store, logger)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	cluster, err := s.store.GetCluster(cluster.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed cluster")
+		return
+	}
+	if cluster == nil || cluster.State != model.ClusterStateStable {
+		return
+	}
+
+	logger.Debug("Checking cluster utilities for drift")
+
+	check, err := s.provisioner.CheckClusterUtilityDrift(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to check cluster utilities for drift")
+		return
+	}
+	check.CheckedAt = now
+
+	cluster.SetUtilityDriftCheck(check)
+	err = s.store.UpdateCluster(cluster)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record cluster utility drift")
+		return
+	}
+
+	for _, release := range check.DriftedReleases() {
+		logger.WithFields(log.Fields{
+			"utility":          release.Name,
+			"version":          release.Version,
+			"expected-version": release.ExpectedVersion,
+		}).Warn("Cluster utility has drifted from its deployed state")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-cloud/internal/store"
+	"github.com/mattermost/mattermost-cloud/internal/supervisor"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUtilityDriftProvisioner struct {
+	Releases []model.UtilityRelease
+	Err      error
+	Calls    int
+}
+
+func (p *mockUtilityDriftProvisioner) CheckClusterUtilityDrift(cluster *model.Cluster) (*model.UtilityDriftCheck, error) {
+	p.Calls++
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	releases := make([]model.UtilityRelease, len(p.Releases))
+	copy(releases, p.Releases)
+
+	return &model.UtilityDriftCheck{Releases: releases}, nil
+}
+
+func TestUtilityDriftSupervisor(t *testing.T) {
+	setup := func(t *testing.T, sqlStore *store.SQLStore, state string) *model.Cluster {
+		t.Helper()
+
+		cluster := &model.Cluster{
+			State:                   state,
+			ProvisionerMetadataKops: &model.KopsMetadata{},
+		}
+		err := sqlStore.CreateCluster(cluster, nil)
+		require.NoError(t, err)
+
+		return cluster
+	}
+
+	driftedReleases := []model.UtilityRelease{
+		{Name: model.NginxCanonicalName, Version: "2.16.0", ExpectedVersion: "2.15.0"},
+		{Name: model.VeleroCanonicalName, Version: "2.13.6", ExpectedVersion: "2.13.6"},
+	}
+
+	t.Run("stable cluster is checked", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockUtilityDriftProvisioner{Releases: driftedReleases}
+		supervisor := supervisor.NewUtilityDriftSupervisor(sqlStore, provisioner, "instanceID", time.Hour, logger)
+
+		cluster := setup(t, sqlStore, model.ClusterStateStable)
+
+		err := supervisor.Do()
+		require.NoError(t, err)
+
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.NotNil(t, cluster.UtilityDriftCheck())
+		require.NotZero(t, cluster.UtilityDriftCheck().CheckedAt)
+		require.Len(t, cluster.UtilityDriftCheck().DriftedReleases(), 1)
+		require.Len(t, cluster.ProvisionerMetadataKops.Warnings, 1)
+		require.Nil(t, cluster.LockAcquiredBy)
+
+		t.Run("not checked again within interval", func(t *testing.T) {
+			err = supervisor.Do()
+			require.NoError(t, err)
+			require.Equal(t, 1, provisioner.Calls)
+		})
+	})
+
+	t.Run("checked again after interval", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockUtilityDriftProvisioner{Releases: driftedReleases}
+		supervisor := supervisor.NewUtilityDriftSupervisor(sqlStore, provisioner, "instanceID", time.Millisecond, logger)
+
+		cluster := setup(t, sqlStore, model.ClusterStateStable)
+
+		supervisor.Supervise(cluster)
+		time.Sleep(2 * time.Millisecond)
+		cluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		supervisor.Supervise(cluster)
+
+		require.Equal(t, 2, provisioner.Calls)
+		cluster, err = sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Len(t, cluster.ProvisionerMetadataKops.Warnings, 1)
+	})
+
+	t.Run("unstable cluster is skipped", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockUtilityDriftProvisioner{}
+		supervisor := supervisor.NewUtilityDriftSupervisor(sqlStore, provisioner, "instanceID", time.Hour, logger)
+
+		setup(t, sqlStore, model.ClusterStateUpgradeRequested)
+
+		err := supervisor.Do()
+		require.NoError(t, err)
+		require.Equal(t, 0, provisioner.Calls)
+	})
+
+	t.Run("check failure", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockUtilityDriftProvisioner{Err: errors.New("failure")}
+		supervisor := supervisor.NewUtilityDriftSupervisor(sqlStore, provisioner, "instanceID", time.Hour, logger)
+
+		cluster := setup(t, sqlStore, model.ClusterStateStable)
+
+		supervisor.Supervise(cluster)
+
+		cluster, err := sqlStore.GetCluster(cluster.ID)
+		require.NoError(t, err)
+		require.Nil(t, cluster.UtilityDriftCheck())
+		require.Nil(t, cluster.LockAcquiredBy)
+	})
+}
@@ -50,8 +50,12 @@ type AWS interface {
 
 	GetAndClaimVpcResources(clusterID, owner string, logger log.FieldLogger) (ClusterResources, error)
 	ReleaseVpc(clusterID string, logger log.FieldLogger) error
+	GetVpcPool(logger log.FieldLogger) ([]*model.VPC, error)
+	GetVpcDBClusterIDs(clusterID string, logger log.FieldLogger) ([]string, error)
 	AttachPolicyToRole(roleName, policyName string, logger log.FieldLogger) error
 	DetachPolicyFromRole(roleName, policyName string, logger log.FieldLogger) error
+	EnsureS3BucketPolicyCreated(policyName, bucketName string, logger log.FieldLogger) error
+	EnsureS3BucketPolicyDeleted(policyName string, logger log.FieldLogger) error
 
 	GetPrivateZoneDomainName(logger log.FieldLogger) (string, error)
 	GetPrivateZoneIDForDefaultTag(logger log.FieldLogger) (string, error)
@@ -68,17 +72,23 @@ type AWS interface {
 	IsValidAMI(AMIImage string, logger log.FieldLogger) (bool, error)
 
 	DynamoDBEnsureTableDeleted(tableName string, logger log.FieldLogger) error
+	S3EnsureBucketCreated(bucketName string, logger log.FieldLogger) error
 	S3EnsureBucketDeleted(bucketName string, logger log.FieldLogger) error
 
 	GenerateBifrostUtilitySecret(clusterID string, logger log.FieldLogger) (*corev1.Secret, error)
+	GetKubeconfigSecret(secretName string) (string, error)
+	EnsureKubeconfigSecretDeleted(secretName string, logger log.FieldLogger) error
+
+	ClientForCluster(cluster *model.Cluster) (AWS, error)
 }
 
 // NewAWSClientWithConfig returns a new instance of Client with a custom configuration.
 func NewAWSClientWithConfig(config *aws.Config, logger log.FieldLogger) *Client {
 	return &Client{
-		logger: logger,
-		config: config,
-		mux:    &sync.Mutex{},
+		logger:   logger,
+		config:   config,
+		mux:      &sync.Mutex{},
+		regional: newRegionalClients(),
 	}
 }
 
@@ -123,6 +133,8 @@ type Client struct {
 	service *Service
 	config  *aws.Config
 	mux     *sync.Mutex
+
+	regional *regionalClients
 }
 
 // Service contructs an AWS session if not yet successfully done and returns AWS clients.
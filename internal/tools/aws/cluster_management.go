@@ -9,6 +9,8 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -17,6 +19,7 @@ import (
 // a kops cluster.
 type ClusterResources struct {
 	VpcID                  string
+	VpcCIDRs               []string
 	PrivateSubnetIDs       []string
 	PublicSubnetsIDs       []string
 	MasterSecurityGroupIDs []string
@@ -44,6 +47,23 @@ func (cr *ClusterResources) IsValid() error {
 	return nil
 }
 
+// vpcCIDRBlocks returns the primary and associated IPv4 CIDR blocks of a VPC.
+func vpcCIDRBlocks(vpc *ec2.Vpc) []string {
+	cidrs := []string{}
+	for _, association := range vpc.CidrBlockAssociationSet {
+		if association.CidrBlockState != nil &&
+			aws.StringValue(association.CidrBlockState.State) != ec2.VpcCidrBlockStateCodeAssociated {
+			continue
+		}
+		cidrs = append(cidrs, aws.StringValue(association.CidrBlock))
+	}
+	if len(cidrs) == 0 && vpc.CidrBlock != nil {
+		cidrs = append(cidrs, *vpc.CidrBlock)
+	}
+
+	return cidrs
+}
+
 func (a *Client) getClusterResourcesForVPC(vpcID string, logger log.FieldLogger) (ClusterResources, error) {
 	clusterResources := ClusterResources{
 		VpcID: vpcID,
@@ -147,7 +167,9 @@ func (a *Client) GetAndClaimVpcResources(clusterID, owner string, logger log.Fie
 		return ClusterResources{}, fmt.Errorf("multiple VPCs (%d) have been claimed by cluster %s; aborting claim process", len(clusterAlreadyClaimedVpcs), clusterID)
 	}
 	if len(clusterAlreadyClaimedVpcs) == 1 {
-		return a.getClusterResourcesForVPC(*clusterAlreadyClaimedVpcs[0].VpcId, logger)
+		clusterResources, err := a.getClusterResourcesForVPC(*clusterAlreadyClaimedVpcs[0].VpcId, logger)
+		clusterResources.VpcCIDRs = vpcCIDRBlocks(clusterAlreadyClaimedVpcs[0])
+		return clusterResources, err
 	}
 
 	// This cluster has not already claimed a VPC. Continue with claiming process.
@@ -190,6 +212,7 @@ func (a *Client) GetAndClaimVpcResources(clusterID, owner string, logger log.Fie
 			logger.Warn(err)
 			continue
 		}
+		clusterResources.VpcCIDRs = vpcCIDRBlocks(vpc)
 
 		err = a.claimVpc(clusterResources, clusterID, owner, logger)
 		if err != nil {
@@ -202,6 +225,134 @@ func (a *Client) GetAndClaimVpcResources(clusterID, owner string, logger log.Fie
 	return ClusterResources{}, fmt.Errorf("%d VPCs were returned as currently available; none of them were configured correctly", len(vpcs))
 }
 
+// GetVpcPool returns every VPC tagged for use by the provisioner along with
+// its claim status and whether its resources are correctly tagged. The VPCs
+// created by the provisioner for a single cluster are left out.
+func (a *Client) GetVpcPool(logger log.FieldLogger) ([]*model.VPC, error) {
+	vpcs, err := a.GetVpcsWithFilters([]*ec2.Filter{
+		{
+			Name: aws.String(VpcAvailableTagKey),
+			Values: []*string{
+				aws.String(VpcAvailableTagValueTrue),
+				aws.String(VpcAvailableTagValueFalse),
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get VPCs")
+	}
+
+	pool := []*model.VPC{}
+	for _, vpc := range vpcs {
+		if isManagedVpc(vpc) {
+			continue
+		}
+
+		status := &model.VPC{ID: *vpc.VpcId, Region: aws.StringValue(a.config.Region)}
+		for _, tag := range vpc.Tags {
+			switch *tag.Key {
+			case trimTagPrefix(VpcAvailableTagKey):
+				status.Available = *tag.Value == VpcAvailableTagValueTrue
+			case trimTagPrefix(VpcClusterIDTagKey):
+				if *tag.Value != VpcClusterIDTagValueNone {
+					status.ClusterID = *tag.Value
+				}
+			case trimTagPrefix(VpcClusterOwnerKey):
+				if *tag.Value != VpcClusterOwnerValueNone {
+					status.Owner = *tag.Value
+				}
+			}
+		}
+
+		_, err = a.getClusterResourcesForVPC(*vpc.VpcId, logger)
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Valid = true
+		}
+
+		pool = append(pool, status)
+	}
+
+	return pool, nil
+}
+
+// GetRegionalVpcPool returns the VPC pool of the given AWS region and of the
+// account reached by assuming the given IAM role, if any.
+func (a *Client) GetRegionalVpcPool(region, assumeRoleARN string, logger log.FieldLogger) ([]*model.VPC, error) {
+	client, err := a.ForRegion(region, assumeRoleARN)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get AWS client for region %s", region)
+	}
+
+	pool, err := client.GetVpcPool(logger)
+	if err != nil {
+		return nil, err
+	}
+	for _, vpc := range pool {
+		vpc.AssumeRoleARN = assumeRoleARN
+	}
+
+	return pool, nil
+}
+
+// isManagedVpc returns true if the VPC was created by the provisioner for a
+// single cluster.
+func isManagedVpc(vpc *ec2.Vpc) bool {
+	for _, tag := range vpc.Tags {
+		if *tag.Key == trimTagPrefix(VpcManagedTagKey) && *tag.Value == VpcManagedTagValueTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetVpcDBClusterIDs returns the IDs of the RDS DB clusters that use the DB
+// subnet group of the VPC claimed by the given cluster.
+func (a *Client) GetVpcDBClusterIDs(clusterID string, logger log.FieldLogger) ([]string, error) {
+	vpcs, err := a.GetVpcsWithFilters([]*ec2.Filter{
+		{
+			Name:   aws.String(VpcClusterIDTagKey),
+			Values: []*string{aws.String(clusterID)},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster VPC")
+	}
+
+	subnetGroupNames := make(map[string]bool)
+	for _, vpc := range vpcs {
+		subnetGroupNames[DBSubnetGroupName(*vpc.VpcId)] = true
+	}
+
+	dbClusterIDs := []string{}
+	if len(subnetGroupNames) == 0 {
+		return dbClusterIDs, nil
+	}
+
+	input := &rds.DescribeDBClustersInput{}
+	for {
+		output, err := a.Service().rds.DescribeDBClusters(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to describe DB clusters")
+		}
+		for _, dbCluster := range output.DBClusters {
+			if dbCluster.DBSubnetGroup != nil && subnetGroupNames[*dbCluster.DBSubnetGroup] {
+				dbClusterIDs = append(dbClusterIDs, *dbCluster.DBClusterIdentifier)
+			}
+		}
+		if output.Marker == nil {
+			break
+		}
+		input.Marker = output.Marker
+	}
+
+	logger.Debugf("Found %d DB clusters in the VPC of cluster %s", len(dbClusterIDs), clusterID)
+
+	return dbClusterIDs, nil
+}
+
 // ReleaseVpc changes the tags on a VPC to mark it as "available" again.
 func (a *Client) ReleaseVpc(clusterID string, logger log.FieldLogger) error {
 	return a.releaseVpc(clusterID, logger)
@@ -39,6 +39,14 @@ const (
 	// there is no cluster running in the VPC.
 	VpcClusterIDTagValueNone = "none"
 
+	// VpcManagedTagKey is the tag key used to mark the VPCs created by the
+	// provisioner for a single cluster, which aren't part of the VPC pool.
+	VpcManagedTagKey = "tag:CloudManagedVPC"
+
+	// VpcManagedTagValueTrue is the tag value for VpcManagedTagKey on the VPCs
+	// created by the provisioner.
+	VpcManagedTagValueTrue = "true"
+
 	// DefaultDatabaseMySQLVersion is the default version of MySQL used when
 	// creating databases.
 	DefaultDatabaseMySQLVersion = "5.7"
@@ -133,6 +141,11 @@ const (
 	// attached in Kops Instance Profile.
 	CustomNodePolicyName = "cloud-provisioning-node-policy"
 
+	// ClusterAutoscalerPolicyName is the name of the custom IAM policy that
+	// lets the cluster-autoscaler scale the autoscaling groups of a cluster.
+	// It is attached in Kops Instance Profile of clusters with autoscaling.
+	ClusterAutoscalerPolicyName = "cloud-provisioning-cluster-autoscaler-policy"
+
 	// cloudIDPrefix is the prefix value used when creating AWS resource names.
 	// Warning:
 	// changing this value will break the connection to AWS resources for
@@ -151,6 +164,31 @@ const (
 	// existing installations.
 	rdsSuffix = "-rds"
 
+	// rdsMasterSuffix is the suffix value used when referencing the AWS RDS
+	// master secret of an installation that no longer uses the master user.
+	// Warning:
+	// changing this value will break the connection to AWS resources for
+	// existing installations.
+	rdsMasterSuffix = "-rds-master"
+
+	// externalDatabaseSuffix is the suffix value used when referencing an
+	// external database secret.
+	// Warning:
+	// changing this value will break the connection to external resources for
+	// existing installations.
+	externalDatabaseSuffix = "-external-database"
+
+	// externalFilestoreSuffix is the suffix value used when referencing an
+	// external filestore secret.
+	// Warning:
+	// changing this value will break the connection to external resources for
+	// existing installations.
+	externalFilestoreSuffix = "-external-filestore"
+
+	// kubeconfigSuffix is the suffix value used when referencing the
+	// kubeconfig secret of an imported cluster.
+	kubeconfigSuffix = "-kubeconfig"
+
 	// rdsMySQLDefaultSchema is the default schema given to a new RDS MySQL
 	// database. This is used to connect to multitenant RDS clusters to set up
 	// new installation databases as needed.
@@ -217,6 +255,10 @@ const (
 	// AWS to identify an RDS cluster.
 	DefaultResourceTypeClusterRDS = "rds:cluster"
 
+	// DefaultResourceTypeSecretsManagerSecret is the default resource type
+	// used by AWS to identify a Secrets Manager secret.
+	DefaultResourceTypeSecretsManagerSecret = "secretsmanager:secret"
+
 	// DefaultRDSStatusAvailable identify that a RDS cluster is in available
 	// state.
 	DefaultRDSStatusAvailable = "available"
@@ -5,6 +5,8 @@
 package aws
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -64,6 +67,10 @@ func (d *RDSDatabase) Teardown(store model.InstallationDatabaseStoreInterface, k
 	if err != nil {
 		return errors.Wrap(err, "unable to delete RDS secret")
 	}
+	err = d.client.secretsManagerEnsureSecretDeleted(RDSMasterSecretName(awsID), logger)
+	if err != nil {
+		return errors.Wrap(err, "unable to delete RDS master secret")
+	}
 
 	if keepData {
 		logger.Info("AWS RDS DB cluster was left intact due to the keep-data setting of this server")
@@ -205,6 +212,207 @@ func (d *RDSDatabase) GenerateDatabaseSpecAndSecret(store model.InstallationData
 	return databaseSpec, databaseSecret, nil
 }
 
+// Usage returns the storage used by the installation RDS database.
+func (d *RDSDatabase) Usage(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.StorageUsage, error) {
+	awsID := CloudID(d.installationID)
+
+	installationSecret, err := d.client.secretsManagerGetRDSSecret(awsID, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := d.connectRDSCluster(awsID, installationSecret)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	size, err := sqlDatabaseSize(ctx, db, d.databaseType, "mattermost")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get size of RDS database")
+	}
+
+	return &model.StorageUsage{Bytes: size}, nil
+}
+
+// RotateCredentials moves the installation to a new database user of its RDS
+// cluster and stores its credentials in the installation RDS secret. The
+// previous user, which is the master user until the first rotation, keeps
+// working until RevokeStaleCredentials is called. A rotation that was never
+// finalized is resumed instead of replacing its user again.
+func (d *RDSDatabase) RotateCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	awsID := CloudID(d.installationID)
+	logger = logger.WithField("db-cluster-name", awsID)
+
+	installationSecret, err := d.client.secretsManagerGetRDSSecret(awsID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to get RDS secret")
+	}
+
+	newSecret, resumed := rotatedDatabaseSecret(d.installationID, installationSecret)
+	if resumed {
+		logger.WithFields(log.Fields{
+			"database-user":       newSecret.MasterUsername,
+			"stale-database-user": newSecret.StaleUsername,
+		}).Info("Resuming unfinished RDS database credential rotation")
+		return nil
+	}
+
+	masterSecret, err := d.ensureRDSMasterSecret(awsID, installationSecret, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to get RDS master secret")
+	}
+
+	db, err := d.connectRDSCluster(awsID, masterSecret)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	err = createRotatedDatabaseUser(ctx, db, d.databaseType, "mattermost", masterSecret.MasterUsername, installationSecret.MasterUsername, newSecret)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new database user")
+	}
+
+	err = d.client.secretsManagerUpdateRDSSecret(RDSSecretName(awsID), newSecret, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to store new database credentials")
+	}
+
+	logger.WithField("database-user", newSecret.MasterUsername).Info("RDS database credentials rotated")
+
+	return nil
+}
+
+// RevokeStaleCredentials drops the database user that was replaced by a
+// credential rotation. When the replaced user is the master user, the master
+// password is changed instead.
+func (d *RDSDatabase) RevokeStaleCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	awsID := CloudID(d.installationID)
+	logger = logger.WithField("db-cluster-name", awsID)
+
+	installationSecret, err := d.client.secretsManagerGetRDSSecret(awsID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to get RDS secret")
+	}
+	if installationSecret.StaleUsername == "" {
+		logger.Debug("No stale RDS database credentials to revoke")
+		return nil
+	}
+	masterSecret, err := d.ensureRDSMasterSecret(awsID, installationSecret, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to get RDS master secret")
+	}
+
+	db, err := d.connectRDSCluster(awsID, masterSecret)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	staleUsername := installationSecret.StaleUsername
+	logger = logger.WithField("database-user", staleUsername)
+
+	err = revokeDatabaseUser(ctx, db, d.databaseType, masterSecret.MasterUsername, staleUsername, installationSecret.MasterUsername)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke stale database user")
+	}
+
+	if staleUsername == masterSecret.MasterUsername {
+		masterSecret.MasterPassword = newRandomPassword(40)
+		_, err = d.client.Service().rds.ModifyDBCluster(&rds.ModifyDBClusterInput{
+			DBClusterIdentifier: aws.String(awsID),
+			MasterUserPassword:  aws.String(masterSecret.MasterPassword),
+			ApplyImmediately:    aws.Bool(true),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to update RDS cluster master password")
+		}
+
+		err = d.client.secretsManagerEnsureSecretValue(RDSMasterSecretName(awsID), rdsMasterSecretDescription(awsID), masterSecret, logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to store new RDS master password")
+		}
+	}
+
+	installationSecret.StaleUsername = ""
+	err = d.client.secretsManagerUpdateRDSSecret(RDSSecretName(awsID), installationSecret, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to store revoked database credentials")
+	}
+
+	logger.Info("Stale RDS database credentials revoked")
+
+	return nil
+}
+
+// ensureRDSMasterSecret returns the master credentials of the installation RDS
+// cluster. Installations use the master user until their first credential
+// rotation, so the master credentials are copied from the installation secret
+// into their own secret before that happens.
+func (d *RDSDatabase) ensureRDSMasterSecret(awsID string, installationSecret *RDSSecret, logger log.FieldLogger) (*RDSSecret, error) {
+	masterSecret, err := d.client.secretsManagerGetRDSSecretFromSecretName(RDSMasterSecretName(awsID))
+	if err == nil {
+		return masterSecret, nil
+	}
+	if !IsErrorCode(errors.Cause(err), secretsmanager.ErrCodeResourceNotFoundException) {
+		return nil, err
+	}
+	if installationSecret.MasterUsername != DefaultMattermostDatabaseUsername {
+		return nil, errors.Errorf("installation uses database user %s, but the RDS master secret is missing", installationSecret.MasterUsername)
+	}
+
+	err = d.client.secretsManagerEnsureSecretValue(RDSMasterSecretName(awsID), rdsMasterSecretDescription(awsID), installationSecret, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store RDS master secret")
+	}
+
+	return installationSecret, nil
+}
+
+// connectRDSCluster connects to the Mattermost database of the installation
+// RDS cluster with the given credentials.
+func (d *RDSDatabase) connectRDSCluster(awsID string, secret *RDSSecret) (*sql.DB, error) {
+	dbClusters, err := d.client.Service().rds.DescribeDBClusters(&rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(awsID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe RDS cluster")
+	}
+	if len(dbClusters.DBClusters) != 1 {
+		return nil, fmt.Errorf("expected 1 DB cluster, but got %d", len(dbClusters.DBClusters))
+	}
+	endpoint := *dbClusters.DBClusters[0].Endpoint
+
+	var db *sql.DB
+	switch d.databaseType {
+	case model.DatabaseEngineTypeMySQL:
+		db, err = sql.Open("mysql", RDSMySQLConnString("mattermost", endpoint, secret.MasterUsername, secret.MasterPassword))
+	case model.DatabaseEngineTypePostgres:
+		db, err = sql.Open("postgres", RDSPostgresConnString("mattermost", endpoint, secret.MasterUsername, secret.MasterPassword))
+	default:
+		return nil, errors.Errorf("%s is an invalid database engine type", d.databaseType)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to RDS cluster endpoint %s", endpoint)
+	}
+
+	return db, nil
+}
+
+func rdsMasterSecretDescription(awsID string) string {
+	return fmt.Sprintf("Master credentials of the RDS cluster %s", awsID)
+}
+
 func (d *RDSDatabase) rdsDatabaseProvision(installationID string, logger log.FieldLogger) error {
 	awsID := CloudID(installationID)
 
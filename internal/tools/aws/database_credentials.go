@@ -0,0 +1,179 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// createRotatedDatabaseUser creates the database user that replaces the
+// current user of an installation database when its credentials are rotated.
+// The new user can access everything the current user can, so that both work
+// until the current user is revoked. The given connection must be made with
+// the master user to the installation database.
+func createRotatedDatabaseUser(ctx context.Context, db SQLDatabaseManager, databaseType, databaseName, masterUsername, currentUsername string, newSecret *RDSSecret) error {
+	// Drop any user left behind by an earlier rotation attempt so that it is
+	// recreated with the new password.
+	err := dropLeftoverDatabaseUser(ctx, db, databaseType, databaseName, newSecret.MasterUsername)
+	if err != nil {
+		return errors.Wrap(err, "failed to drop leftover database user")
+	}
+
+	switch databaseType {
+	case model.DatabaseEngineTypeMySQL:
+		_, err = db.QueryContext(ctx, "CREATE USER ?@? IDENTIFIED BY ? REQUIRE SSL", newSecret.MasterUsername, "%", newSecret.MasterPassword)
+		if err != nil {
+			return errors.New("failed to run create user SQL command: error suppressed")
+		}
+		// Query placeholders don't seem to work with argument database.
+		// See https://github.com/mattermost/mattermost-cloud/pull/209#discussion_r422533477
+		query := fmt.Sprintf("GRANT ALL PRIVILEGES ON %s.* TO ?@?", databaseName)
+		_, err = db.QueryContext(ctx, query, newSecret.MasterUsername, "%")
+		if err != nil {
+			return errors.Wrap(err, "failed to run privilege grant SQL command")
+		}
+	case model.DatabaseEngineTypePostgres:
+		// See ensureDatabaseUserIsCreated for why the password is not passed
+		// as a query parameter.
+		query := fmt.Sprintf("CREATE USER %s WITH PASSWORD '%s'", newSecret.MasterUsername, newSecret.MasterPassword)
+		_, err = db.QueryContext(ctx, query)
+		if err != nil {
+			return errors.New("failed to run create user SQL command: error suppressed")
+		}
+
+		queries := []string{fmt.Sprintf("GRANT ALL PRIVILEGES ON DATABASE %s TO %s", databaseName, newSecret.MasterUsername)}
+		if currentUsername == masterUsername {
+			// Membership of the master user would grant access to the whole
+			// cluster, so the new user only gets access to the tables until
+			// it takes ownership of them when the master user is revoked.
+			queries = append(queries,
+				fmt.Sprintf("GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO %s", newSecret.MasterUsername),
+				fmt.Sprintf("GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO %s", newSecret.MasterUsername),
+			)
+		} else {
+			queries = append(queries, fmt.Sprintf("GRANT %s TO %s", currentUsername, newSecret.MasterUsername))
+		}
+		for _, query := range queries {
+			_, err = db.QueryContext(ctx, query)
+			if err != nil {
+				return errors.Wrap(err, "failed to run privilege grant SQL command")
+			}
+		}
+	default:
+		return errors.Errorf("%s is an invalid database engine type", databaseType)
+	}
+
+	return nil
+}
+
+// revokeDatabaseUser hands the objects owned by the stale user of an
+// installation database over to the current user and drops the stale user.
+// The master user is never dropped, so the caller must change its password
+// when it is the stale user. The given connection must be made with the
+// master user to the installation database.
+func revokeDatabaseUser(ctx context.Context, db SQLDatabaseManager, databaseType, masterUsername, staleUsername, currentUsername string) error {
+	exists, err := databaseUserExists(ctx, db, databaseType, staleUsername)
+	if err != nil {
+		return errors.Wrap(err, "failed to check for stale database user")
+	}
+	if !exists {
+		return nil
+	}
+
+	switch databaseType {
+	case model.DatabaseEngineTypeMySQL:
+		if staleUsername == masterUsername {
+			return nil
+		}
+		_, err = db.QueryContext(ctx, "DROP USER IF EXISTS ?@?", staleUsername, "%")
+		if err != nil {
+			return errors.Wrap(err, "failed to run drop user SQL command")
+		}
+	case model.DatabaseEngineTypePostgres:
+		// Reassigning ownership requires the privileges of both users.
+		queries := []string{fmt.Sprintf("GRANT %s TO %s", currentUsername, masterUsername)}
+		if staleUsername != masterUsername {
+			queries = append(queries, fmt.Sprintf("GRANT %s TO %s", staleUsername, masterUsername))
+		}
+		queries = append(queries, fmt.Sprintf("REASSIGN OWNED BY %s TO %s", staleUsername, currentUsername))
+		if staleUsername != masterUsername {
+			queries = append(queries,
+				fmt.Sprintf("DROP OWNED BY %s", staleUsername),
+				fmt.Sprintf("DROP USER %s", staleUsername),
+			)
+		}
+		for _, query := range queries {
+			_, err = db.QueryContext(ctx, query)
+			if err != nil {
+				return errors.Wrap(err, "failed to run revoke user SQL command")
+			}
+		}
+	default:
+		return errors.Errorf("%s is an invalid database engine type", databaseType)
+	}
+
+	return nil
+}
+
+// dropLeftoverDatabaseUser drops a database user left behind by an earlier
+// rotation attempt. PostgreSQL refuses to drop users that still own objects,
+// so a user that is in use is never removed along with its data.
+func dropLeftoverDatabaseUser(ctx context.Context, db SQLDatabaseManager, databaseType, databaseName, username string) error {
+	switch databaseType {
+	case model.DatabaseEngineTypeMySQL:
+		_, err := db.QueryContext(ctx, "DROP USER IF EXISTS ?@?", username, "%")
+		if err != nil {
+			return errors.Wrap(err, "failed to run drop user SQL command")
+		}
+	case model.DatabaseEngineTypePostgres:
+		exists, err := databaseUserExists(ctx, db, databaseType, username)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return nil
+		}
+
+		for _, query := range []string{
+			fmt.Sprintf("REVOKE ALL PRIVILEGES ON DATABASE %s FROM %s", databaseName, username),
+			fmt.Sprintf("REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA public FROM %s", username),
+			fmt.Sprintf("REVOKE ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public FROM %s", username),
+			fmt.Sprintf("DROP USER %s", username),
+		} {
+			_, err = db.QueryContext(ctx, query)
+			if err != nil {
+				return errors.Wrap(err, "failed to run drop user SQL command")
+			}
+		}
+	default:
+		return errors.Errorf("%s is an invalid database engine type", databaseType)
+	}
+
+	return nil
+}
+
+func databaseUserExists(ctx context.Context, db SQLDatabaseManager, databaseType, username string) (bool, error) {
+	var query string
+	switch databaseType {
+	case model.DatabaseEngineTypeMySQL:
+		query = "SELECT 1 FROM mysql.user WHERE user = ?"
+	case model.DatabaseEngineTypePostgres:
+		query = "SELECT 1 FROM pg_roles WHERE rolname = $1"
+	default:
+		return false, errors.Errorf("%s is an invalid database engine type", databaseType)
+	}
+
+	rows, err := db.QueryContext(ctx, query, username)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to run user lookup SQL command")
+	}
+	defer rows.Close()
+
+	return rows.Next(), nil
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExternalDatabase is a customer managed database. The connection string is
+// supplied when the installation is created and kept in Secrets Manager. The
+// provisioner never creates, modifies or deletes the database itself.
+type ExternalDatabase struct {
+	installationID string
+	client         *Client
+}
+
+// NewExternalDatabase returns a new ExternalDatabase interface.
+func NewExternalDatabase(installationID string, client *Client) *ExternalDatabase {
+	return &ExternalDatabase{
+		installationID: installationID,
+		client:         client,
+	}
+}
+
+// Provision validates that the external database can be reached with the
+// supplied connection string.
+func (d *ExternalDatabase) Provision(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	logger = logger.WithField("database-type", model.InstallationDatabaseExternal)
+	logger.Info("Validating external database connectivity")
+
+	db, _, err := d.connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to external database")
+	}
+
+	logger.Info("External database is reachable")
+
+	return nil
+}
+
+// Teardown removes the stored external database connection details. The
+// external database and its data are never deleted.
+func (d *ExternalDatabase) Teardown(store model.InstallationDatabaseStoreInterface, keepData bool, logger log.FieldLogger) error {
+	awsID := CloudID(d.installationID)
+	logger = logger.WithField("database-type", model.InstallationDatabaseExternal)
+	logger.Info("Tearing down external database configuration; the database itself is left untouched")
+
+	err := d.client.secretsManagerEnsureSecretDeleted(ExternalDatabaseSecretName(awsID), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete external database secret")
+	}
+
+	return nil
+}
+
+// Snapshot is not supported for external databases.
+func (d *ExternalDatabase) Snapshot(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return errors.New("snapshotting is not supported for external databases")
+}
+
+// GenerateDatabaseSpecAndSecret creates the k8s database spec and secret for
+// accessing the external database.
+func (d *ExternalDatabase) GenerateDatabaseSpecAndSecret(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*mmv1alpha1.Database, *corev1.Secret, error) {
+	secret, err := d.client.secretsManagerGetExternalDatabaseSecret(CloudID(d.installationID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	databaseSecretName := fmt.Sprintf("%s-external-database", d.installationID)
+	databaseSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: databaseSecretName,
+		},
+		StringData: map[string]string{
+			"DB_CONNECTION_STRING": secret.ConnectionString,
+		},
+	}
+
+	databaseSpec := &mmv1alpha1.Database{
+		Secret: databaseSecretName,
+	}
+
+	logger.Debug("External database configuration generated for cluster installation")
+
+	return databaseSpec, databaseSecret, nil
+}
+
+// Usage returns the storage used by the external database.
+func (d *ExternalDatabase) Usage(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.StorageUsage, error) {
+	db, connection, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	size, err := sqlDatabaseSize(ctx, db, connection.databaseType, connection.databaseName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get size of external database")
+	}
+
+	return &model.StorageUsage{Bytes: size}, nil
+}
+
+// RotateCredentials is a no-op as external database credentials are managed
+// by the database owner.
+func (d *ExternalDatabase) RotateCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	logger.Info("External database credentials are managed by the database owner; skipping rotation")
+	return nil
+}
+
+// RevokeStaleCredentials is a no-op as external database credentials are
+// managed by the database owner.
+func (d *ExternalDatabase) RevokeStaleCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return nil
+}
+
+// externalDatabaseConnection describes how the provisioner connects to an
+// external database.
+type externalDatabaseConnection struct {
+	databaseType string
+	databaseName string
+	driverName   string
+	dataSource   string
+}
+
+// parseExternalDatabaseConnectionString converts a Mattermost connection
+// string into the values needed to open it with database/sql.
+func parseExternalDatabaseConnectionString(connectionString string) (*externalDatabaseConnection, error) {
+	databaseType, err := model.ExternalDatabaseEngineType(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	switch databaseType {
+	case model.DatabaseEngineTypeMySQL:
+		dataSource := strings.TrimPrefix(connectionString, "mysql://")
+		config, err := mysql.ParseDSN(dataSource)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse external MySQL connection string")
+		}
+
+		return &externalDatabaseConnection{
+			databaseType: databaseType,
+			databaseName: config.DBName,
+			driverName:   "mysql",
+			dataSource:   dataSource,
+		}, nil
+	default:
+		u, err := url.Parse(connectionString)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse external PostgreSQL connection string")
+		}
+
+		return &externalDatabaseConnection{
+			databaseType: databaseType,
+			databaseName: strings.TrimPrefix(u.Path, "/"),
+			driverName:   "postgres",
+			dataSource:   connectionString,
+		}, nil
+	}
+}
+
+func (d *ExternalDatabase) connect() (*sql.DB, *externalDatabaseConnection, error) {
+	secret, err := d.client.secretsManagerGetExternalDatabaseSecret(CloudID(d.installationID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	connection, err := parseExternalDatabaseConnectionString(secret.ConnectionString)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := sql.Open(connection.driverName, connection.dataSource)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to open external database connection")
+	}
+
+	return db, connection, nil
+}
@@ -252,6 +252,138 @@ func (d *RDSMultitenantDatabase) GenerateDatabaseSpecAndSecret(store model.Insta
 	return databaseSpec, databaseSecret, nil
 }
 
+// Usage returns the storage used by the installation database inside the RDS
+// multitenant cluster.
+func (d *RDSMultitenantDatabase) Usage(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.StorageUsage, error) {
+	err := d.IsValid()
+	if err != nil {
+		return nil, errors.Wrap(err, "multitenant database configuration is invalid")
+	}
+
+	installationDatabaseName := MattermostRDSDatabaseName(d.installationID)
+
+	multitenantDatabase, err := store.GetMultitenantDatabaseForInstallationID(d.installationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for the multitenant database")
+	}
+
+	rdsCluster, err := d.describeRDSCluster(multitenantDatabase.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe RDS cluster")
+	}
+
+	masterSecretValue, err := d.client.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: rdsCluster.DBClusterIdentifier,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the master secret for the multitenant RDS cluster %s", *rdsCluster.DBClusterIdentifier)
+	}
+
+	close, err := d.connectRDSCluster(*rdsCluster.Endpoint, DefaultMattermostDatabaseUsername, *masterSecretValue.SecretString)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to the multitenant RDS cluster %s", *rdsCluster.DBClusterIdentifier)
+	}
+	defer close(logger)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	size, err := sqlDatabaseSize(ctx, d.db, d.databaseType, installationDatabaseName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get size of multitenant RDS database %s", installationDatabaseName)
+	}
+
+	return &model.StorageUsage{Bytes: size}, nil
+}
+
+// RotateCredentials moves the installation database inside the RDS
+// multitenant cluster to a new database user and stores its credentials in the
+// installation secret. The previous user keeps working until
+// RevokeStaleCredentials is called. A rotation that was never finalized is
+// resumed instead of replacing its user again.
+func (d *RDSMultitenantDatabase) RotateCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := d.IsValid()
+	if err != nil {
+		return errors.Wrap(err, "multitenant database configuration is invalid")
+	}
+
+	installationDatabaseName := MattermostRDSDatabaseName(d.installationID)
+	logger = logger.WithField("multitenant-rds-database", installationDatabaseName)
+
+	installationSecret, close, err := d.connectAssignedRDSCluster(store, logger)
+	if err != nil {
+		return err
+	}
+	defer close(logger)
+
+	newSecret, resumed := rotatedDatabaseSecret(d.installationID, installationSecret)
+	if resumed {
+		logger.WithFields(log.Fields{
+			"database-user":       newSecret.MasterUsername,
+			"stale-database-user": newSecret.StaleUsername,
+		}).Info("Resuming unfinished RDS multitenant database credential rotation")
+		return nil
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	err = createRotatedDatabaseUser(ctx, d.db, d.databaseType, installationDatabaseName, DefaultMattermostDatabaseUsername, installationSecret.MasterUsername, newSecret)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new database user")
+	}
+
+	err = d.client.secretsManagerUpdateRDSSecret(RDSMultitenantSecretName(d.installationID), newSecret, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to store new database credentials")
+	}
+
+	logger.WithField("database-user", newSecret.MasterUsername).Info("RDS multitenant database credentials rotated")
+
+	return nil
+}
+
+// RevokeStaleCredentials drops the database user that was replaced by a
+// credential rotation.
+func (d *RDSMultitenantDatabase) RevokeStaleCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := d.IsValid()
+	if err != nil {
+		return errors.Wrap(err, "multitenant database configuration is invalid")
+	}
+
+	logger = logger.WithField("multitenant-rds-database", MattermostRDSDatabaseName(d.installationID))
+
+	installationSecret, close, err := d.connectAssignedRDSCluster(store, logger)
+	if err != nil {
+		return err
+	}
+	defer close(logger)
+
+	if installationSecret.StaleUsername == "" {
+		logger.Debug("No stale RDS multitenant database user to revoke")
+		return nil
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	staleUsername := installationSecret.StaleUsername
+	err = revokeDatabaseUser(ctx, d.db, d.databaseType, DefaultMattermostDatabaseUsername, staleUsername, installationSecret.MasterUsername)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke stale database user")
+	}
+
+	installationSecret.StaleUsername = ""
+	err = d.client.secretsManagerUpdateRDSSecret(RDSMultitenantSecretName(d.installationID), installationSecret, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to store revoked database credentials")
+	}
+
+	logger.WithField("database-user", staleUsername).Info("Stale RDS multitenant database user revoked")
+
+	return nil
+}
+
 // Teardown removes all AWS resources related to a RDS multitenant database.
 func (d *RDSMultitenantDatabase) Teardown(store model.InstallationDatabaseStoreInterface, keepData bool, logger log.FieldLogger) error {
 	logger = logger.WithField("rds-multitenant-database", MattermostRDSDatabaseName(d.installationID))
@@ -798,17 +930,27 @@ func (d *RDSMultitenantDatabase) runProvisionSQLCommands(installationDatabaseNam
 }
 
 func (d *RDSMultitenantDatabase) connectRDSCluster(endpoint, username, password string) (func(logger log.FieldLogger), error) {
+	schema := rdsMySQLDefaultSchema
+	if d.databaseType == model.DatabaseEngineTypePostgres {
+		schema = rdsPostgresDefaultSchema
+	}
+
+	return d.connectRDSClusterDatabase(endpoint, schema, username, password)
+}
+
+// connectRDSClusterDatabase connects to the given database of the RDS cluster.
+func (d *RDSMultitenantDatabase) connectRDSClusterDatabase(endpoint, schema, username, password string) (func(logger log.FieldLogger), error) {
 	if d.db == nil {
 		var db SQLDatabaseManager
 		var err error
 		switch d.databaseType {
 		case model.DatabaseEngineTypeMySQL:
-			db, err = sql.Open("mysql", RDSMySQLConnString(rdsMySQLDefaultSchema, endpoint, username, password))
+			db, err = sql.Open("mysql", RDSMySQLConnString(schema, endpoint, username, password))
 			if err != nil {
 				return nil, errors.Wrapf(err, "failed to connect multitenant RDS cluster endpoint %s", endpoint)
 			}
 		case model.DatabaseEngineTypePostgres:
-			db, err = sql.Open("postgres", RDSPostgresConnString(rdsPostgresDefaultSchema, endpoint, username, password))
+			db, err = sql.Open("postgres", RDSPostgresConnString(schema, endpoint, username, password))
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to connect to postgres database")
 			}
@@ -905,6 +1047,47 @@ func (d *RDSMultitenantDatabase) ensureDatabaseUserHasFullPermissions(ctx contex
 	return nil
 }
 
+// connectAssignedRDSCluster connects to the installation database inside the
+// RDS multitenant cluster with the master credentials and returns the current
+// installation credentials.
+func (d *RDSMultitenantDatabase) connectAssignedRDSCluster(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*RDSSecret, func(logger log.FieldLogger), error) {
+	multitenantDatabase, err := store.GetMultitenantDatabaseForInstallationID(d.installationID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to query for the multitenant database")
+	}
+
+	rdsCluster, err := d.describeRDSCluster(multitenantDatabase.ID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to describe RDS cluster")
+	}
+	rdsID := *rdsCluster.DBClusterIdentifier
+
+	result, err := d.client.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(RDSMultitenantSecretName(d.installationID)),
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get secret value for database")
+	}
+	installationSecret, err := unmarshalSecretPayload(*result.SecretString)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal secret payload")
+	}
+
+	masterSecretValue, err := d.client.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: rdsCluster.DBClusterIdentifier,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to find the master secret for the multitenant RDS cluster %s", rdsID)
+	}
+
+	close, err := d.connectRDSClusterDatabase(*rdsCluster.Endpoint, MattermostRDSDatabaseName(d.installationID), DefaultMattermostDatabaseUsername, *masterSecretValue.SecretString)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to connect to the multitenant RDS cluster %s", rdsID)
+	}
+
+	return installationSecret, close, nil
+}
+
 func (d *RDSMultitenantDatabase) dropDatabaseIfExists(ctx context.Context, databaseName string) error {
 	// Query placeholders don't seem to work with argument database.
 	// See https://github.com/mattermost/mattermost-cloud/pull/209#discussion_r422533477
@@ -917,3 +1100,32 @@ func (d *RDSMultitenantDatabase) dropDatabaseIfExists(ctx context.Context, datab
 
 	return nil
 }
+
+// sqlDatabaseSize returns the size in bytes of the given database.
+func sqlDatabaseSize(ctx context.Context, db SQLDatabaseManager, databaseType, databaseName string) (int64, error) {
+	var query string
+	switch databaseType {
+	case model.DatabaseEngineTypeMySQL:
+		query = "SELECT COALESCE(SUM(data_length + index_length), 0) FROM information_schema.tables WHERE table_schema = ?"
+	case model.DatabaseEngineTypePostgres:
+		query = "SELECT pg_database_size($1)"
+	default:
+		return 0, errors.Errorf("%s is an invalid database engine type", databaseType)
+	}
+
+	rows, err := db.QueryContext(ctx, query, databaseName)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to run database size SQL command")
+	}
+	defer rows.Close()
+
+	var size int64
+	if rows.Next() {
+		err = rows.Scan(&size)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to scan database size")
+		}
+	}
+
+	return size, rows.Err()
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	gt "github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ReconcileMultitenantDatabases compares every multitenant database in the
+// datastore against the RDS counter tag, the logical databases on the RDS
+// cluster and the installation secrets stored in AWS. When repair is true, the
+// inconsistencies that can be safely fixed are repaired.
+func (a *Client) ReconcileMultitenantDatabases(store model.MultitenantDatabaseReconcileStoreInterface, lockerID string, repair bool, logger log.FieldLogger) (*model.MultitenantDatabaseReconcileReport, error) {
+	databases, err := store.GetMultitenantDatabases(&model.MultitenantDatabaseFilter{
+		MaxInstallationsLimit: model.NoInstallationsLimit,
+		PerPage:               model.AllPerPage,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for multitenant databases")
+	}
+
+	report := &model.MultitenantDatabaseReconcileReport{
+		Repair:    repair,
+		Databases: []*model.MultitenantDatabaseReconcileResult{},
+	}
+	for _, database := range databases {
+		databaseLogger := logger.WithField("multitenant-database", database.ID)
+
+		result, err := a.reconcileMultitenantDatabase(database.ID, store, lockerID, repair, databaseLogger)
+		if err != nil {
+			databaseLogger.WithError(err).Error("Failed to reconcile multitenant database")
+			result.Error = err.Error()
+		}
+		report.Databases = append(report.Databases, result)
+	}
+
+	return report, nil
+}
+
+func (a *Client) reconcileMultitenantDatabase(multitenantDatabaseID string, store model.MultitenantDatabaseReconcileStoreInterface, lockerID string, repair bool, logger log.FieldLogger) (*model.MultitenantDatabaseReconcileResult, error) {
+	result := &model.MultitenantDatabaseReconcileResult{ID: multitenantDatabaseID}
+
+	locked, err := store.LockMultitenantDatabase(multitenantDatabaseID, lockerID)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to lock multitenant database")
+	}
+	if !locked {
+		return result, errors.New("failed to acquire lock for multitenant database")
+	}
+	defer func() {
+		unlocked, err := store.UnlockMultitenantDatabase(multitenantDatabaseID, lockerID, true)
+		if err != nil {
+			logger.WithError(err).Error("failed to unlock multitenant database")
+		}
+		if !unlocked {
+			logger.Warn("failed to release lock for multitenant database")
+		}
+	}()
+
+	// Refresh the multitenant database now that it is locked.
+	database, err := store.GetMultitenantDatabase(multitenantDatabaseID)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to refresh multitenant database after lock")
+	}
+	if database == nil {
+		return result, errors.New("multitenant database no longer exists")
+	}
+	result.VpcID = database.VpcID
+	result.DatabaseType = database.DatabaseType
+	result.StoredInstallations = database.Installations.Count()
+
+	client, err := a.clientForMultitenantDatabase(database, store)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to get AWS client for multitenant database")
+	}
+
+	d := NewRDSMultitenantDatabase(database.DatabaseType, lockerID, "", client)
+
+	rdsCluster, err := d.describeRDSCluster(multitenantDatabaseID)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to describe the multitenant RDS cluster")
+	}
+
+	result.CounterTag, err = rdsClusterCounterTagValue(rdsCluster.TagList)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to get the multitenant RDS cluster counter tag")
+	}
+
+	masterSecretValue, err := client.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(multitenantDatabaseID),
+	})
+	if err != nil {
+		return result, errors.Wrapf(err, "failed to get master secret by ID %s", multitenantDatabaseID)
+	}
+
+	close, err := d.connectRDSCluster(*rdsCluster.Endpoint, DefaultMattermostDatabaseUsername, *masterSecretValue.SecretString)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to connect to multitenant RDS cluster")
+	}
+	defer close(logger)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(DefaultMySQLContextTimeSeconds*time.Second))
+	defer cancel()
+
+	databaseInstallationIDs, err := d.listInstallationDatabases(ctx)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to list installation databases")
+	}
+
+	secretARNs, err := client.getMultitenantDatabaseInstallationSecrets(multitenantDatabaseID)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to list installation secrets")
+	}
+	secretInstallationIDs := make([]string, 0, len(secretARNs))
+	for installationID := range secretARNs {
+		secretInstallationIDs = append(secretInstallationIDs, installationID)
+	}
+
+	err = compareMultitenantDatabase(result, database.Installations, databaseInstallationIDs, secretInstallationIDs, store)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to compare multitenant database resources")
+	}
+
+	if !repair || result.IsConsistent() {
+		return result, nil
+	}
+
+	logger.Info("Repairing multitenant database")
+
+	for _, installationID := range result.OrphanedDatabases {
+		databaseName := MattermostRDSDatabaseName(installationID)
+		err = d.dropDatabaseIfExists(ctx, databaseName)
+		if err != nil {
+			return result, errors.Wrapf(err, "failed to drop orphaned database %s", databaseName)
+		}
+		logger.Infof("Dropped orphaned database %s", databaseName)
+	}
+
+	for _, installationID := range result.OrphanedSecrets {
+		_, err = client.Service().secretsManager.DeleteSecret(&secretsmanager.DeleteSecretInput{
+			SecretId: aws.String(secretARNs[installationID]),
+		})
+		if err != nil && !IsErrorCode(err, secretsmanager.ErrCodeResourceNotFoundException) {
+			return result, errors.Wrapf(err, "failed to delete orphaned secret for installation %s", installationID)
+		}
+		logger.Infof("Deleted orphaned secret for installation %s", installationID)
+	}
+
+	if len(result.DeletedInstallations) != 0 {
+		for _, installationID := range result.DeletedInstallations {
+			database.Installations.Remove(installationID)
+		}
+		err = store.UpdateMultitenantDatabase(database)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to remove deleted installations from multitenant database")
+		}
+		logger.Infof("Removed %d deleted installations from multitenant database", len(result.DeletedInstallations))
+	}
+
+	if result.CounterTag != database.Installations.Count() {
+		err = d.updateCounterTag(rdsCluster.DBClusterArn, database.Installations.Count())
+		if err != nil {
+			return result, errors.Wrap(err, "failed to update counter tag")
+		}
+		logger.Infof("Multitenant database counter value updated from %d to %d", result.CounterTag, database.Installations.Count())
+	}
+
+	result.Repaired = true
+
+	return result, nil
+}
+
+// clientForMultitenantDatabase returns a client for the AWS region and account
+// the multitenant database is placed in. The database lives in the VPC of the
+// clusters hosting its installations, so the first installation with a known
+// cluster determines the region. The client itself is returned for databases
+// without any such installation.
+func (a *Client) clientForMultitenantDatabase(database *model.MultitenantDatabase, store model.MultitenantDatabaseReconcileStoreInterface) (*Client, error) {
+	for _, installationID := range database.Installations {
+		clusterInstallations, err := store.GetClusterInstallations(&model.ClusterInstallationFilter{
+			PerPage:        model.AllPerPage,
+			InstallationID: installationID,
+			IncludeDeleted: true,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find cluster installations of installation %s", installationID)
+		}
+		if len(clusterInstallations) == 0 {
+			continue
+		}
+
+		cluster, err := store.GetCluster(clusterInstallations[0].ClusterID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query cluster %s", clusterInstallations[0].ClusterID)
+		}
+		if cluster == nil {
+			continue
+		}
+
+		return a.ForRegion(cluster.AWSRegion(), cluster.AWSAssumeRoleARN())
+	}
+
+	return a, nil
+}
+
+// installationStatus is the state of an installation in the datastore as seen
+// by the multitenant database reconciliation.
+type installationStatus int
+
+const (
+	installationLive installationStatus = iota
+	installationDeleted
+	installationUnknown
+)
+
+// compareMultitenantDatabase fills the result with the inconsistencies found
+// between the installations recorded on a multitenant database and the
+// logical databases and secrets that exist for it.
+//
+// Only installations that are marked as deleted in the datastore are treated
+// as deleted. Installations the datastore doesn't know about may belong to
+// another environment sharing the RDS cluster, so their resources are only
+// reported.
+func compareMultitenantDatabase(result *model.MultitenantDatabaseReconcileResult, installations model.MultitenantDatabaseInstallations, databaseInstallationIDs, secretInstallationIDs []string, store model.MultitenantDatabaseReconcileStoreInterface) error {
+	statuses := make(map[string]installationStatus)
+	getStatus := func(installationID string) (installationStatus, error) {
+		if status, ok := statuses[installationID]; ok {
+			return status, nil
+		}
+		installation, err := store.GetInstallation(installationID, false, false)
+		if err != nil {
+			return installationUnknown, errors.Wrapf(err, "failed to get installation %s", installationID)
+		}
+		switch {
+		case installation == nil:
+			statuses[installationID] = installationUnknown
+		case installation.DeleteAt > 0:
+			statuses[installationID] = installationDeleted
+		default:
+			statuses[installationID] = installationLive
+		}
+		return statuses[installationID], nil
+	}
+
+	hasDatabase := make(map[string]bool)
+	for _, installationID := range databaseInstallationIDs {
+		hasDatabase[installationID] = true
+	}
+
+	for _, installationID := range installations {
+		status, err := getStatus(installationID)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case installationDeleted:
+			result.DeletedInstallations = append(result.DeletedInstallations, installationID)
+		case installationUnknown:
+			result.UnknownInstallations = append(result.UnknownInstallations, installationID)
+		default:
+			if !hasDatabase[installationID] {
+				result.MissingDatabases = append(result.MissingDatabases, installationID)
+			}
+		}
+	}
+
+	for _, installationID := range databaseInstallationIDs {
+		status, err := getStatus(installationID)
+		if err != nil {
+			return err
+		}
+		if status == installationDeleted {
+			result.OrphanedDatabases = append(result.OrphanedDatabases, installationID)
+			continue
+		}
+		if !installations.Contains(installationID) {
+			result.UntrackedDatabases = append(result.UntrackedDatabases, installationID)
+		}
+	}
+
+	for _, installationID := range secretInstallationIDs {
+		status, err := getStatus(installationID)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case installationDeleted:
+			result.OrphanedSecrets = append(result.OrphanedSecrets, installationID)
+		case installationUnknown:
+			result.UntrackedSecrets = append(result.UntrackedSecrets, installationID)
+		}
+	}
+
+	sort.Strings(result.DeletedInstallations)
+	sort.Strings(result.UnknownInstallations)
+	sort.Strings(result.MissingDatabases)
+	sort.Strings(result.OrphanedDatabases)
+	sort.Strings(result.UntrackedDatabases)
+	sort.Strings(result.OrphanedSecrets)
+	sort.Strings(result.UntrackedSecrets)
+
+	return nil
+}
+
+// listInstallationDatabases returns the installation IDs of all Mattermost
+// logical databases on the connected RDS cluster.
+func (d *RDSMultitenantDatabase) listInstallationDatabases(ctx context.Context) ([]string, error) {
+	query := "SELECT SCHEMA_NAME FROM information_schema.SCHEMATA"
+	if d.databaseType == model.DatabaseEngineTypePostgres {
+		query = "SELECT datname FROM pg_database"
+	}
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run list databases SQL command")
+	}
+	defer rows.Close()
+
+	var installationIDs []string
+	for rows.Next() {
+		var databaseName string
+		err = rows.Scan(&databaseName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan database name")
+		}
+		if strings.HasPrefix(databaseName, rdsDatabaseNamePrefix) {
+			installationIDs = append(installationIDs, strings.TrimPrefix(databaseName, rdsDatabaseNamePrefix))
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate over database names")
+	}
+
+	return installationIDs, nil
+}
+
+// getMultitenantDatabaseInstallationSecrets returns the ARNs of the
+// installation secrets tagged with the given multitenant database ID keyed by
+// installation ID.
+func (a *Client) getMultitenantDatabaseInstallationSecrets(multitenantDatabaseID string) (map[string]string, error) {
+	resources, err := a.resourceTaggingGetAllResources(gt.GetResourcesInput{
+		TagFilters: []*gt.TagFilter{
+			{
+				Key:    aws.String(trimTagPrefix(DefaultRDSMultitenantDatabaseIDTagKey)),
+				Values: []*string{aws.String(multitenantDatabaseID)},
+			},
+			{
+				Key: aws.String(trimTagPrefix(DefaultMattermostInstallationIDTagKey)),
+			},
+		},
+		ResourceTypeFilters: []*string{aws.String(DefaultResourceTypeSecretsManagerSecret)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tagged secrets")
+	}
+
+	secrets := make(map[string]string)
+	for _, resource := range resources {
+		for _, tag := range resource.Tags {
+			if *tag.Key == trimTagPrefix(DefaultMattermostInstallationIDTagKey) && tag.Value != nil {
+				secrets[*tag.Value] = *resource.ResourceARN
+			}
+		}
+	}
+
+	return secrets, nil
+}
+
+// rdsClusterCounterTagValue returns the value of the installation counter tag.
+// A missing tag is treated as a counter of zero.
+func rdsClusterCounterTagValue(tags []*rds.Tag) (int, error) {
+	for _, tag := range tags {
+		if *tag.Key == trimTagPrefix(RDSMultitenantInstallationCounterTagKey) && tag.Value != nil {
+			counter, err := strconv.Atoi(*tag.Value)
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to parse string tag:counter to integer")
+			}
+			return counter, nil
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	gt "github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockReconcileStore struct {
+	model.MultitenantDatabaseReconcileStoreInterface
+	installations        map[string]*model.Installation
+	clusterInstallations map[string][]*model.ClusterInstallation
+	clusters             map[string]*model.Cluster
+}
+
+func (s *mockReconcileStore) GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*model.Installation, error) {
+	return s.installations[installationID], nil
+}
+
+func (s *mockReconcileStore) GetClusterInstallations(filter *model.ClusterInstallationFilter) ([]*model.ClusterInstallation, error) {
+	return s.clusterInstallations[filter.InstallationID], nil
+}
+
+func (s *mockReconcileStore) GetCluster(id string) (*model.Cluster, error) {
+	return s.clusters[id], nil
+}
+
+func TestClientForMultitenantDatabase(t *testing.T) {
+	client := NewAWSClientWithConfig(&aws.Config{Region: aws.String(DefaultAWSRegion)}, testlib.MakeLogger(t))
+	store := &mockReconcileStore{
+		clusterInstallations: map[string][]*model.ClusterInstallation{
+			"installation1": {{ClusterID: "deleted-cluster"}},
+			"installation2": {{ClusterID: "cluster1"}},
+		},
+		clusters: map[string]*model.Cluster{
+			"cluster1": {ID: "cluster1", ProviderMetadataAWS: &model.AWSMetadata{Region: "eu-central-1"}},
+		},
+	}
+
+	t.Run("regional", func(t *testing.T) {
+		regional, err := client.clientForMultitenantDatabase(&model.MultitenantDatabase{
+			Installations: model.MultitenantDatabaseInstallations{"unknown", "installation1", "installation2"},
+		}, store)
+		require.NoError(t, err)
+		assert.Equal(t, "eu-central-1", aws.StringValue(regional.config.Region))
+	})
+
+	t.Run("no installations", func(t *testing.T) {
+		regional, err := client.clientForMultitenantDatabase(&model.MultitenantDatabase{}, store)
+		require.NoError(t, err)
+		assert.True(t, client == regional)
+	})
+}
+
+func TestCompareMultitenantDatabase(t *testing.T) {
+	store := &mockReconcileStore{
+		installations: map[string]*model.Installation{
+			"live1":    {ID: "live1"},
+			"live2":    {ID: "live2"},
+			"live3":    {ID: "live3"},
+			"deleted1": {ID: "deleted1", DeleteAt: 1},
+		},
+	}
+
+	result := &model.MultitenantDatabaseReconcileResult{StoredInstallations: 4, CounterTag: 4}
+	err := compareMultitenantDatabase(
+		result,
+		model.MultitenantDatabaseInstallations{"live1", "live2", "deleted1", "unknown1"},
+		[]string{"live1", "live3", "deleted1", "unknown2"},
+		[]string{"live1", "live2", "deleted1", "unknown2"},
+		store,
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"deleted1"}, result.DeletedInstallations)
+	assert.Equal(t, []string{"unknown1"}, result.UnknownInstallations)
+	assert.Equal(t, []string{"live2"}, result.MissingDatabases)
+	// Resources of unknown installations are only reported as untracked.
+	assert.Equal(t, []string{"deleted1"}, result.OrphanedDatabases)
+	assert.Equal(t, []string{"live3", "unknown2"}, result.UntrackedDatabases)
+	assert.Equal(t, []string{"deleted1"}, result.OrphanedSecrets)
+	assert.Equal(t, []string{"unknown2"}, result.UntrackedSecrets)
+	assert.False(t, result.CounterTagDrift())
+	assert.False(t, result.IsConsistent())
+
+	t.Run("consistent", func(t *testing.T) {
+		result := &model.MultitenantDatabaseReconcileResult{StoredInstallations: 1, CounterTag: 1}
+		err := compareMultitenantDatabase(
+			result,
+			model.MultitenantDatabaseInstallations{"live1"},
+			[]string{"live1"},
+			[]string{"live1"},
+			store,
+		)
+		require.NoError(t, err)
+		assert.True(t, result.IsConsistent())
+	})
+}
+
+func TestRDSClusterCounterTagValue(t *testing.T) {
+	t.Run("present", func(t *testing.T) {
+		counter, err := rdsClusterCounterTagValue([]*rds.Tag{
+			{Key: aws.String("VpcID"), Value: aws.String("vpc-1")},
+			{Key: aws.String("Counter"), Value: aws.String("7")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 7, counter)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		counter, err := rdsClusterCounterTagValue(nil)
+		require.NoError(t, err)
+		assert.Equal(t, 0, counter)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := rdsClusterCounterTagValue([]*rds.Tag{
+			{Key: aws.String("Counter"), Value: aws.String("many")},
+		})
+		require.Error(t, err)
+	})
+}
+
+func (a *AWSTestSuite) TestGetMultitenantDatabaseInstallationSecrets() {
+	a.Mocks.API.ResourceGroupsTagging.EXPECT().
+		GetResources(gomock.Any()).
+		Do(func(input *gt.GetResourcesInput) {
+			a.Assert().Equal([]*string{aws.String(DefaultResourceTypeSecretsManagerSecret)}, input.ResourceTypeFilters)
+			a.Assert().Equal(aws.String(a.RDSClusterID), input.TagFilters[0].Values[0])
+		}).
+		Return(&gt.GetResourcesOutput{
+			ResourceTagMappingList: []*gt.ResourceTagMapping{
+				{
+					ResourceARN: aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:rds-multitenant-installation1-AbCdEf"),
+					Tags: []*gt.Tag{
+						{Key: aws.String("MultitenantDatabaseID"), Value: aws.String(a.RDSClusterID)},
+						{Key: aws.String("InstallationId"), Value: aws.String("installation1")},
+					},
+				},
+			},
+		}, nil).
+		Times(1)
+
+	secrets, err := a.Mocks.AWS.getMultitenantDatabaseInstallationSecrets(a.RDSClusterID)
+	a.Require().NoError(err)
+	a.Assert().Equal(map[string]string{
+		"installation1": "arn:aws:secretsmanager:us-east-1:123456789012:secret:rds-multitenant-installation1-AbCdEf",
+	}, secrets)
+}
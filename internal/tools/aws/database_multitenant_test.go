@@ -238,3 +238,44 @@ func (a *AWSTestSuite) TestProvisioningMultitenantDatabase() {
 		"rds-cluster-multitenant-09d44077df9934f96-97670d43: failed to run create database SQL command: dial tcp: "+
 		"lookup aws.rds.com/mattermost: no such host", err.Error())
 }
+
+func (a *AWSTestSuite) TestRotatedDatabaseUsername() {
+	installationID := model.NewID()
+	primary := "user_" + installationID
+	secondary := rotatedDatabaseUsername(installationID, primary)
+
+	a.Assert().Equal("userb_"+installationID, secondary)
+	a.Assert().LessOrEqual(len(secondary), 32)
+	a.Assert().Equal(primary, rotatedDatabaseUsername(installationID, secondary))
+}
+
+func (a *AWSTestSuite) TestRotatedDatabaseSecret() {
+	installationID := model.NewID()
+	masterSecret := &RDSSecret{
+		MasterUsername: DefaultMattermostDatabaseUsername,
+		MasterPassword: newRandomPassword(40),
+	}
+
+	rotated, resumed := rotatedDatabaseSecret(installationID, masterSecret)
+	a.Assert().False(resumed)
+	a.Assert().Equal("user_"+installationID, rotated.MasterUsername)
+	a.Assert().Equal(DefaultMattermostDatabaseUsername, rotated.StaleUsername)
+	a.Assert().NoError(rotated.Validate())
+
+	a.Run("retry of an unfinished rotation", func() {
+		retried, resumed := rotatedDatabaseSecret(installationID, rotated)
+		a.Assert().True(resumed)
+		a.Assert().Equal(rotated, retried)
+	})
+
+	a.Run("rotation after revoke", func() {
+		revoked := *rotated
+		revoked.StaleUsername = ""
+
+		next, resumed := rotatedDatabaseSecret(installationID, &revoked)
+		a.Assert().False(resumed)
+		a.Assert().Equal("userb_"+installationID, next.MasterUsername)
+		a.Assert().Equal("user_"+installationID, next.StaleUsername)
+		a.Assert().NotEqual(rotated.MasterPassword, next.MasterPassword)
+	})
+}
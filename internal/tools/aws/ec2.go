@@ -6,6 +6,7 @@ package aws
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -133,6 +134,32 @@ func (a *Client) GetSecurityGroupsWithFilters(filters []*ec2.Filter) ([]*ec2.Sec
 	return sgOutput.SecurityGroups, nil
 }
 
+// GetS3PrefixListCIDRs returns the CIDR blocks of the S3 prefix list of the
+// region of the client, which cover the public endpoints of the S3 buckets
+// in that region.
+func (a *Client) GetS3PrefixListCIDRs(logger log.FieldLogger) ([]string, error) {
+	prefixListName := fmt.Sprintf("com.amazonaws.%s.s3", aws.StringValue(a.config.Region))
+
+	output, err := a.Service().ec2.DescribePrefixLists(&ec2.DescribePrefixListsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("prefix-list-name"),
+				Values: []*string{aws.String(prefixListName)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to describe prefix list %s", prefixListName)
+	}
+	if len(output.PrefixLists) != 1 {
+		return nil, errors.Errorf("expected 1 prefix list named %s, but found %d", prefixListName, len(output.PrefixLists))
+	}
+
+	logger.Debugf("Found %d CIDR blocks in prefix list %s", len(output.PrefixLists[0].Cidrs), prefixListName)
+
+	return aws.StringValueSlice(output.PrefixLists[0].Cidrs), nil
+}
+
 func prettyCreateTagsResponse(resp *ec2.CreateTagsOutput) string {
 	prettyResp, err := json.Marshal(resp)
 	if err != nil {
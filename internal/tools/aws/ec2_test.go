@@ -9,6 +9,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/golang/mock/gomock"
 	testlib "github.com/mattermost/mattermost-cloud/internal/testlib"
@@ -160,3 +161,63 @@ func TestVPCReal(t *testing.T) {
 	err = client.releaseVpc(clusterID, logger)
 	require.NoError(t, err)
 }
+
+func TestVpcCIDRBlocks(t *testing.T) {
+	vpc := &ec2.Vpc{
+		CidrBlock: aws.String("10.0.0.0/16"),
+		CidrBlockAssociationSet: []*ec2.VpcCidrBlockAssociation{
+			{
+				CidrBlock:      aws.String("10.0.0.0/16"),
+				CidrBlockState: &ec2.VpcCidrBlockState{State: aws.String(ec2.VpcCidrBlockStateCodeAssociated)},
+			},
+			{
+				CidrBlock:      aws.String("100.64.0.0/16"),
+				CidrBlockState: &ec2.VpcCidrBlockState{State: aws.String(ec2.VpcCidrBlockStateCodeAssociated)},
+			},
+			{
+				CidrBlock:      aws.String("100.65.0.0/16"),
+				CidrBlockState: &ec2.VpcCidrBlockState{State: aws.String(ec2.VpcCidrBlockStateCodeDisassociated)},
+			},
+		},
+	}
+	require.Equal(t, []string{"10.0.0.0/16", "100.64.0.0/16"}, vpcCIDRBlocks(vpc))
+
+	require.Equal(t, []string{"10.0.0.0/16"}, vpcCIDRBlocks(&ec2.Vpc{CidrBlock: aws.String("10.0.0.0/16")}))
+}
+
+func (a *AWSTestSuite) TestGetS3PrefixListCIDRs() {
+	a.Mocks.AWS.config.Region = aws.String("us-east-1")
+
+	a.Mocks.API.EC2.EXPECT().
+		DescribePrefixLists(&ec2.DescribePrefixListsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("prefix-list-name"),
+					Values: []*string{aws.String("com.amazonaws.us-east-1.s3")},
+				},
+			},
+		}).
+		Return(&ec2.DescribePrefixListsOutput{
+			PrefixLists: []*ec2.PrefixList{
+				{Cidrs: aws.StringSlice([]string{"3.5.0.0/19", "52.216.0.0/15"})},
+			},
+		}, nil).
+		Times(1)
+	a.Mocks.Log.Logger.EXPECT().
+		Debugf(gomock.Any(), gomock.Any()).
+		Times(1)
+
+	cidrs, err := a.Mocks.AWS.GetS3PrefixListCIDRs(a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+	a.Assert().Equal([]string{"3.5.0.0/19", "52.216.0.0/15"}, cidrs)
+
+	a.Run("missing prefix list", func() {
+		a.Mocks.API.EC2.EXPECT().
+			DescribePrefixLists(gomock.Any()).
+			Return(&ec2.DescribePrefixListsOutput{}, nil).
+			Times(1)
+
+		_, err := a.Mocks.AWS.GetS3PrefixListCIDRs(a.Mocks.Log.Logger)
+		a.Assert().EqualError(err, "expected 1 prefix list named com.amazonaws.us-east-1.s3, but found 0")
+	})
+}
@@ -108,6 +108,38 @@ func (f *S3Filestore) GenerateFilestoreSpecAndSecret(store model.InstallationDat
 	return filestoreSpec, filestoreSecret, nil
 }
 
+// Usage returns the storage used by the installation S3 bucket.
+func (f *S3Filestore) Usage(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.StorageUsage, error) {
+	usage, err := f.awsClient.S3GetBucketUsage(CloudID(f.installationID), "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get AWS S3 filestore usage")
+	}
+
+	return usage, nil
+}
+
+// RotateCredentials creates a new IAM access key for the AWS S3 filestore while
+// leaving the previous key active.
+func (f *S3Filestore) RotateCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := f.awsClient.iamRotateAccessKey(CloudID(f.installationID), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to rotate AWS S3 filestore credentials")
+	}
+
+	return nil
+}
+
+// RevokeStaleCredentials deletes the IAM access keys of the AWS S3 filestore
+// that were replaced by a credential rotation.
+func (f *S3Filestore) RevokeStaleCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := f.awsClient.iamRevokeStaleAccessKeys(CloudID(f.installationID), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke stale AWS S3 filestore credentials")
+	}
+
+	return nil
+}
+
 // s3FilestoreProvision provisions an S3 filestore for an installation.
 func (f *S3Filestore) s3FilestoreProvision(installationID string, logger log.FieldLogger) error {
 	logger.Info("Provisioning AWS S3 filestore")
@@ -139,7 +171,7 @@ func (f *S3Filestore) s3FilestoreProvision(installationID string, logger log.Fie
 		"iam-user-name":   *user.UserName,
 	}).Debug("AWS IAM policy attached to user")
 
-	err = f.awsClient.s3EnsureBucketCreated(awsID, logger)
+	err = f.awsClient.S3EnsureBucketCreated(awsID, logger)
 	if err != nil {
 		return err
 	}
@@ -135,6 +135,34 @@ func (f *BifrostFilestore) GenerateFilestoreSpecAndSecret(store model.Installati
 	return filestoreSpec, filestoreSecret, nil
 }
 
+// Usage returns the storage used by the installation directory of the
+// shared S3 bucket.
+func (f *BifrostFilestore) Usage(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.StorageUsage, error) {
+	bucketName, err := getMultitenantBucketNameForInstallation(f.installationID, store, f.awsClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find multitenant bucket")
+	}
+
+	usage, err := f.awsClient.S3GetBucketUsage(bucketName, f.installationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multitenant filestore usage")
+	}
+
+	return usage, nil
+}
+
+// RotateCredentials is a noop as bifrost filestores are accessed without
+// installation credentials.
+func (f *BifrostFilestore) RotateCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return nil
+}
+
+// RevokeStaleCredentials is a noop as bifrost filestores are accessed without
+// installation credentials.
+func (f *BifrostFilestore) RevokeStaleCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return nil
+}
+
 // s3FilestoreProvision provisions a shared S3 filestore for an installation.
 func (f *BifrostFilestore) s3FilestoreProvision(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
 	awsID := CloudID(f.installationID)
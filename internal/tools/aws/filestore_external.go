@@ -0,0 +1,168 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExternalFilestore is a customer managed S3 compatible bucket. The bucket
+// location and credentials are supplied when the installation is created and
+// kept in Secrets Manager. The provisioner never creates, modifies or deletes
+// the bucket itself.
+type ExternalFilestore struct {
+	installationID string
+	awsClient      *Client
+}
+
+// NewExternalFilestore returns a new ExternalFilestore interface.
+func NewExternalFilestore(installationID string, awsClient *Client) *ExternalFilestore {
+	return &ExternalFilestore{
+		installationID: installationID,
+		awsClient:      awsClient,
+	}
+}
+
+// Provision validates that the external bucket can be reached with the
+// supplied credentials.
+func (f *ExternalFilestore) Provision(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	logger = logger.WithField("filestore-type", model.InstallationFilestoreExternal)
+	logger.Info("Validating external filestore connectivity")
+
+	secret, s3API, err := f.connect()
+	if err != nil {
+		return err
+	}
+
+	_, err = s3API.HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(secret.Bucket),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to access external bucket %s", secret.Bucket)
+	}
+
+	logger.Info("External filestore is reachable")
+
+	return nil
+}
+
+// Teardown removes the stored external filestore credentials. The external
+// bucket and its files are never deleted.
+func (f *ExternalFilestore) Teardown(keepData bool, store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	awsID := CloudID(f.installationID)
+	logger = logger.WithField("filestore-type", model.InstallationFilestoreExternal)
+	logger.Info("Tearing down external filestore configuration; the bucket itself is left untouched")
+
+	err := f.awsClient.secretsManagerEnsureSecretDeleted(ExternalFilestoreSecretName(awsID), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete external filestore secret")
+	}
+
+	return nil
+}
+
+// GenerateFilestoreSpecAndSecret creates the k8s filestore spec and secret for
+// accessing the external bucket.
+func (f *ExternalFilestore) GenerateFilestoreSpecAndSecret(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*mmv1alpha1.Minio, *corev1.Secret, error) {
+	secret, err := f.awsClient.secretsManagerGetExternalFilestoreSecret(CloudID(f.installationID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filestoreSecretName := fmt.Sprintf("%s-external-filestore", f.installationID)
+	filestoreSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: filestoreSecretName,
+		},
+		StringData: map[string]string{
+			"accesskey": secret.AccessKeyID,
+			"secretkey": secret.SecretAccessKey,
+		},
+	}
+
+	filestoreSpec := &mmv1alpha1.Minio{
+		ExternalURL:    externalFilestoreEndpoint(secret),
+		ExternalBucket: secret.Bucket,
+		Secret:         filestoreSecretName,
+	}
+
+	logger.Debug("Cluster installation configured to use an external filestore")
+
+	return filestoreSpec, filestoreSecret, nil
+}
+
+// Usage returns the storage used by the files in the external bucket.
+func (f *ExternalFilestore) Usage(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.StorageUsage, error) {
+	secret, s3API, err := f.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return s3BucketUsage(s3API, secret.Bucket, "")
+}
+
+// RotateCredentials is a no-op as external filestore credentials are managed
+// by the bucket owner.
+func (f *ExternalFilestore) RotateCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	logger.Info("External filestore credentials are managed by the bucket owner; skipping rotation")
+	return nil
+}
+
+// RevokeStaleCredentials is a no-op as external filestore credentials are
+// managed by the bucket owner.
+func (f *ExternalFilestore) RevokeStaleCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return nil
+}
+
+// connect returns an S3 client authenticated with the external filestore
+// credentials instead of the provisioner credentials.
+func (f *ExternalFilestore) connect() (*ExternalFilestoreSecret, s3iface.S3API, error) {
+	secret, err := f.awsClient.secretsManagerGetExternalFilestoreSecret(CloudID(f.installationID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	region := secret.Region
+	if region == "" {
+		region = DefaultAWSRegion
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String(region),
+		Endpoint:         aws.String(externalFilestoreEndpoint(secret)),
+		Credentials:      credentials.NewStaticCredentials(secret.AccessKeyID, secret.SecretAccessKey, ""),
+		S3ForcePathStyle: aws.Bool(secret.Endpoint != ""),
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create external filestore session")
+	}
+
+	return secret, s3.New(sess), nil
+}
+
+// externalFilestoreEndpoint returns the S3 endpoint of the external bucket,
+// defaulting to AWS S3 in the bucket region.
+func externalFilestoreEndpoint(secret *ExternalFilestoreSecret) string {
+	if secret.Endpoint != "" {
+		return secret.Endpoint
+	}
+	if secret.Region != "" && secret.Region != DefaultAWSRegion {
+		return "s3." + secret.Region + ".amazonaws.com"
+	}
+
+	return S3URL
+}
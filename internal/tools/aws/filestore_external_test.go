@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExternalFilestoreEndpoint(t *testing.T) {
+	assert.Equal(t, "minio.example.com", externalFilestoreEndpoint(&ExternalFilestoreSecret{Endpoint: "minio.example.com", Region: "eu-west-1"}))
+	assert.Equal(t, "s3.eu-west-1.amazonaws.com", externalFilestoreEndpoint(&ExternalFilestoreSecret{Region: "eu-west-1"}))
+	assert.Equal(t, S3URL, externalFilestoreEndpoint(&ExternalFilestoreSecret{Region: DefaultAWSRegion}))
+	assert.Equal(t, S3URL, externalFilestoreEndpoint(&ExternalFilestoreSecret{}))
+}
+
+func (a *AWSTestSuite) TestExternalFilestoreGenerateFilestoreSpecAndSecret() {
+	filestore := NewExternalFilestore(a.InstallationA.ID, a.Mocks.AWS)
+
+	a.Mocks.API.SecretsManager.EXPECT().
+		GetSecretValue(&secretsmanager.GetSecretValueInput{
+			SecretId: aws.String(ExternalFilestoreSecretName(CloudID(a.InstallationA.ID))),
+		}).
+		Return(&secretsmanager.GetSecretValueOutput{
+			SecretString: aws.String(`{"Bucket":"customer-bucket","Endpoint":"minio.example.com","AccessKeyID":"key","SecretAccessKey":"secret"}`),
+		}, nil).
+		Times(1)
+
+	filestoreSpec, filestoreSecret, err := filestore.GenerateFilestoreSpecAndSecret(nil, logrus.New())
+	a.Require().NoError(err)
+	a.Assert().Equal("customer-bucket", filestoreSpec.ExternalBucket)
+	a.Assert().Equal("minio.example.com", filestoreSpec.ExternalURL)
+	a.Assert().Equal(filestoreSecret.Name, filestoreSpec.Secret)
+	a.Assert().Equal("key", filestoreSecret.StringData["accesskey"])
+	a.Assert().Equal("secret", filestoreSecret.StringData["secretkey"])
+}
+
+func (a *AWSTestSuite) TestExternalFilestoreTeardown() {
+	filestore := NewExternalFilestore(a.InstallationA.ID, a.Mocks.AWS)
+
+	// Only the stored credentials are removed; the customer bucket is never
+	// touched.
+	a.Mocks.API.SecretsManager.EXPECT().
+		DeleteSecret(&secretsmanager.DeleteSecretInput{
+			SecretId: aws.String(ExternalFilestoreSecretName(CloudID(a.InstallationA.ID))),
+		}).
+		Return(&secretsmanager.DeleteSecretOutput{}, nil).
+		Times(1)
+	a.Mocks.API.S3.EXPECT().DeleteBucket(gomock.Any()).Times(0)
+
+	err := filestore.Teardown(false, nil, logrus.New())
+	a.Assert().NoError(err)
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// S3FilestoreMigration manages the AWS resources used to move the files of an
+// installation between filestores.
+type S3FilestoreMigration struct {
+	installation *model.Installation
+	awsClient    *Client
+}
+
+// NewS3FilestoreMigration returns a new S3FilestoreMigration.
+func NewS3FilestoreMigration(installation *model.Installation, awsClient *Client) *S3FilestoreMigration {
+	return &S3FilestoreMigration{
+		installation: installation,
+		awsClient:    awsClient,
+	}
+}
+
+// Setup ensures that the target filestore location exists and creates a
+// temporary IAM user with access to both the source and target locations.
+func (m *S3FilestoreMigration) Setup(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.FilestoreMigrationConfig, error) {
+	migration := m.installation.FilestoreMigration
+	if migration == nil {
+		return nil, errors.New("installation has no filestore migration")
+	}
+
+	awsID := FilestoreMigrationID(m.installation.ID)
+	logger = logger.WithFields(log.Fields{
+		"iam-user-name":    awsID,
+		"source-filestore": migration.SourceFilestore,
+		"target-filestore": migration.TargetFilestore,
+	})
+	logger.Info("Setting up AWS resources for filestore migration")
+
+	source, err := m.filestoreLocation(migration.SourceFilestore, store)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get source filestore location")
+	}
+	target, err := m.filestoreLocation(migration.TargetFilestore, store)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get target filestore location")
+	}
+	if target == nil {
+		return nil, errors.Errorf("filestore %s is not supported as a migration target", migration.TargetFilestore)
+	}
+
+	if migration.TargetFilestore == model.InstallationFilestoreAwsS3 {
+		err = m.awsClient.S3EnsureBucketCreated(target.Bucket, logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create target S3 bucket")
+		}
+		logger.WithField("s3-bucket-name", target.Bucket).Debug("AWS S3 bucket created")
+	}
+
+	user, err := m.awsClient.iamEnsureUserCreated(awsID, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create migration IAM user")
+	}
+
+	// The IAM policy lookup requires the AWS account ID for the ARN. The user
+	// object contains this ID so we will user that.
+	userARN, err := arn.Parse(*user.Arn)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := policyDocument{
+		Version:   "2012-10-17",
+		Statement: s3PolicyStatements("Target", target.Bucket, permittedDirectory(target)),
+	}
+	if source != nil {
+		policy.Statement = append(policy.Statement, s3PolicyStatements("Source", source.Bucket, permittedDirectory(source))...)
+	}
+
+	policyARN := fmt.Sprintf("arn:aws:iam::%s:policy/%s", userARN.AccountID, awsID)
+	_, err = m.awsClient.iamEnsurePolicyCreated(awsID, policyARN, policy, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create migration IAM policy")
+	}
+	err = m.awsClient.iamEnsurePolicyAttached(awsID, policyARN, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to attach migration IAM policy")
+	}
+
+	ak, err := m.awsClient.iamEnsureAccessKeyCreated(awsID, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create migration IAM access key")
+	}
+
+	for _, location := range []*model.FilestoreLocation{source, target} {
+		if location == nil {
+			continue
+		}
+		location.AccessKeyID = *ak.AccessKeyId
+		location.SecretAccessKey = *ak.SecretAccessKey
+	}
+
+	logger.Info("Filestore migration setup completed")
+
+	return &model.FilestoreMigrationConfig{
+		Source: source,
+		Target: target,
+	}, nil
+}
+
+// Cutover provisions the target filestore so that it is ready to be used by
+// the installation. The credentials of the source filestore are left intact.
+func (m *S3FilestoreMigration) Cutover(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	migration := m.installation.FilestoreMigration
+	if migration == nil {
+		return errors.New("installation has no filestore migration")
+	}
+
+	// The single and multitenant S3 filestores share the same IAM user and
+	// access key secret, so the target is not provisioned again: that would
+	// replace the access key still used by the pods on the source. The policy
+	// of the user is widened to both filestores instead, and the source access
+	// is revoked in the teardown once the pods use the target. The target
+	// bucket was created during the setup.
+	if usesFilestoreIAMUser(migration.SourceFilestore) && usesFilestoreIAMUser(migration.TargetFilestore) {
+		err := m.ensureFilestoreIAMPolicy(store, logger, migration.SourceFilestore, migration.TargetFilestore)
+		if err != nil {
+			return errors.Wrap(err, "failed to grant filestore IAM user access to the target filestore")
+		}
+
+		return nil
+	}
+
+	var target model.Filestore
+	switch migration.TargetFilestore {
+	case model.InstallationFilestoreAwsS3:
+		target = NewS3Filestore(m.installation.ID, m.awsClient)
+	case model.InstallationFilestoreMultiTenantAwsS3:
+		target = NewS3MultitenantFilestore(m.installation.ID, m.awsClient)
+	case model.InstallationFilestoreBifrost:
+		target = NewBifrostFilestore(m.installation.ID, m.awsClient)
+	default:
+		return errors.Errorf("filestore %s is not supported as a migration target", migration.TargetFilestore)
+	}
+
+	err := target.Provision(store, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to provision target filestore")
+	}
+
+	return nil
+}
+
+// Teardown removes the temporary migration resources along with the files of
+// the filestore that is no longer used by the installation.
+func (m *S3FilestoreMigration) Teardown(keepData bool, store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	migration := m.installation.FilestoreMigration
+	if migration == nil {
+		return nil
+	}
+
+	awsID := FilestoreMigrationID(m.installation.ID)
+	logger = logger.WithFields(log.Fields{
+		"iam-user-name":    awsID,
+		"source-filestore": migration.SourceFilestore,
+		"target-filestore": migration.TargetFilestore,
+	})
+	logger.Info("Tearing down AWS resources for filestore migration")
+
+	err := m.awsClient.iamEnsureUserDeleted(awsID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete migration IAM user")
+	}
+
+	// The installation only uses the target filestore once cutover completes.
+	// Before then, the target is the filestore that needs to be cleaned up.
+	unusedFilestore := migration.TargetFilestore
+	if m.installation.Filestore == migration.TargetFilestore {
+		unusedFilestore = migration.SourceFilestore
+
+		if usesFilestoreIAMUser(migration.SourceFilestore) && !usesFilestoreIAMUser(migration.TargetFilestore) {
+			err = m.ensureFilestoreIAMUserDeleted(logger)
+			if err != nil {
+				return err
+			}
+		}
+		if usesFilestoreIAMUser(migration.SourceFilestore) && usesFilestoreIAMUser(migration.TargetFilestore) {
+			err = m.ensureFilestoreIAMPolicy(store, logger, migration.TargetFilestore)
+			if err != nil {
+				return errors.Wrap(err, "failed to revoke filestore IAM user access to the source filestore")
+			}
+		}
+	}
+
+	logger = logger.WithField("unused-filestore", unusedFilestore)
+
+	if keepData {
+		logger.Info("Unused filestore data was left intact due to the keep-data setting of this server")
+		return nil
+	}
+
+	location, err := m.filestoreLocation(unusedFilestore, store)
+	if err != nil {
+		return errors.Wrap(err, "failed to get unused filestore location")
+	}
+	if location == nil {
+		logger.Info("Unused filestore is internal to the cluster; skipping data cleanup")
+		return nil
+	}
+
+	if location.Prefix == "" {
+		err = m.awsClient.S3EnsureBucketDeleted(location.Bucket, logger)
+	} else {
+		err = m.awsClient.S3EnsureBucketDirectoryDeleted(location.Bucket, location.Prefix, logger)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to delete unused filestore data")
+	}
+
+	logger.Info("Filestore migration teardown completed")
+
+	return nil
+}
+
+// filestoreLocation returns the S3 location of the installation files for the
+// given filestore type. A nil location is returned for filestores that are
+// internal to the cluster.
+func (m *S3FilestoreMigration) filestoreLocation(filestore string, store model.InstallationDatabaseStoreInterface) (*model.FilestoreLocation, error) {
+	S3RegionURL := S3URL
+	awsRegion := *m.awsClient.config.Region
+	if awsRegion != "" && awsRegion != "us-east-1" {
+		S3RegionURL = "s3." + awsRegion + ".amazonaws.com"
+	}
+
+	switch filestore {
+	case model.InstallationFilestoreMinioOperator:
+		return nil, nil
+	case model.InstallationFilestoreAwsS3:
+		return &model.FilestoreLocation{
+			Endpoint: S3RegionURL,
+			Region:   awsRegion,
+			Bucket:   CloudID(m.installation.ID),
+		}, nil
+	case model.InstallationFilestoreMultiTenantAwsS3, model.InstallationFilestoreBifrost:
+		bucketName, err := getMultitenantBucketNameForInstallation(m.installation.ID, store, m.awsClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find multitenant bucket")
+		}
+
+		return &model.FilestoreLocation{
+			Endpoint: S3RegionURL,
+			Region:   awsRegion,
+			Bucket:   bucketName,
+			Prefix:   m.installation.ID,
+		}, nil
+	}
+
+	return nil, errors.Errorf("unsupported filestore %s", filestore)
+}
+
+// ensureFilestoreIAMPolicy sets the policy of the installation filestore IAM
+// user to allow access to the given filestores only.
+func (m *S3FilestoreMigration) ensureFilestoreIAMPolicy(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger, filestores ...string) error {
+	accountID, err := m.awsClient.GetAccountID()
+	if err != nil {
+		return errors.Wrap(err, "failed to get AWS account ID")
+	}
+
+	policy := policyDocument{Version: "2012-10-17"}
+	for i, filestore := range filestores {
+		location, err := m.filestoreLocation(filestore, store)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get filestore %s location", filestore)
+		}
+		sidSuffix := ""
+		if i > 0 {
+			sidSuffix = fmt.Sprintf("%d", i)
+		}
+		policy.Statement = append(policy.Statement, s3PolicyStatements(sidSuffix, location.Bucket, permittedDirectory(location))...)
+	}
+
+	awsID := CloudID(m.installation.ID)
+	policyARN := fmt.Sprintf("arn:aws:iam::%s:policy/%s", accountID, awsID)
+
+	return m.awsClient.iamEnsurePolicyDocument(policyARN, policy, logger)
+}
+
+func (m *S3FilestoreMigration) ensureFilestoreIAMUserDeleted(logger log.FieldLogger) error {
+	awsID := CloudID(m.installation.ID)
+
+	err := m.awsClient.iamEnsureUserDeleted(awsID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete filestore IAM user")
+	}
+
+	err = m.awsClient.secretsManagerEnsureIAMAccessKeySecretDeleted(awsID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete filestore IAM access key secret")
+	}
+
+	return nil
+}
+
+func permittedDirectory(location *model.FilestoreLocation) string {
+	if location.Prefix == "" {
+		return "*"
+	}
+
+	return location.Prefix
+}
+
+// usesFilestoreIAMUser returns true if the filestore type accesses its files
+// with the installation IAM user.
+func usesFilestoreIAMUser(filestore string) bool {
+	return filestore == model.InstallationFilestoreAwsS3 ||
+		filestore == model.InstallationFilestoreMultiTenantAwsS3
+}
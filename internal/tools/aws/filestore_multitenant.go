@@ -148,6 +148,44 @@ func (f *S3MultitenantFilestore) GenerateFilestoreSpecAndSecret(store model.Inst
 	return filestoreSpec, filestoreSecret, nil
 }
 
+// Usage returns the storage used by the installation directory of the
+// shared S3 bucket.
+func (f *S3MultitenantFilestore) Usage(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) (*model.StorageUsage, error) {
+	bucketName, err := getMultitenantBucketNameForInstallation(f.installationID, store, f.awsClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find multitenant bucket")
+	}
+
+	usage, err := f.awsClient.S3GetBucketUsage(bucketName, f.installationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multitenant filestore usage")
+	}
+
+	return usage, nil
+}
+
+// RotateCredentials creates a new IAM access key for the multitenant S3 filestore while
+// leaving the previous key active.
+func (f *S3MultitenantFilestore) RotateCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := f.awsClient.iamRotateAccessKey(CloudID(f.installationID), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to rotate multitenant S3 filestore credentials")
+	}
+
+	return nil
+}
+
+// RevokeStaleCredentials deletes the IAM access keys of the multitenant S3 filestore
+// that were replaced by a credential rotation.
+func (f *S3MultitenantFilestore) RevokeStaleCredentials(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	err := f.awsClient.iamRevokeStaleAccessKeys(CloudID(f.installationID), logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke stale multitenant S3 filestore credentials")
+	}
+
+	return nil
+}
+
 // s3FilestoreProvision provisions a shared S3 filestore for an installation.
 func (f *S3MultitenantFilestore) s3FilestoreProvision(store model.InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
 	awsID := CloudID(f.installationID)
@@ -40,6 +40,30 @@ func RDSSecretName(cloudID string) string {
 	return cloudID + rdsSuffix
 }
 
+// RDSMasterSecretName returns the RDS master secret name for a given Cloud
+// ID.
+func RDSMasterSecretName(cloudID string) string {
+	return cloudID + rdsMasterSuffix
+}
+
+// ExternalDatabaseSecretName returns the external database secret name for a
+// given Cloud ID.
+func ExternalDatabaseSecretName(cloudID string) string {
+	return cloudID + externalDatabaseSuffix
+}
+
+// ExternalFilestoreSecretName returns the external filestore secret name for
+// a given Cloud ID.
+func ExternalFilestoreSecretName(cloudID string) string {
+	return cloudID + externalFilestoreSuffix
+}
+
+// KubeconfigSecretName returns the kubeconfig secret name for a given Cloud
+// ID.
+func KubeconfigSecretName(cloudID string) string {
+	return cloudID + kubeconfigSuffix
+}
+
 func trimTagPrefix(tag string) string {
 	return strings.TrimLeft(tag, "tag:")
 }
@@ -88,6 +112,12 @@ func RDSMigrationInstanceID(installationID string) string {
 	return fmt.Sprintf("%s-migration", CloudID(installationID))
 }
 
+// FilestoreMigrationID formats the name used for the temporary AWS resources
+// of filestore migrations.
+func FilestoreMigrationID(installationID string) string {
+	return fmt.Sprintf("%s-migration", CloudID(installationID))
+}
+
 // IsErrorCode asserts that an AWS error has a certain code.
 func IsErrorCode(err error, code string) bool {
 	if err != nil {
@@ -110,6 +140,37 @@ func MattermostMultitenantS3Name(environmentName, vpcID string) string {
 	return fmt.Sprintf("mattermost-cloud-%s-provisioning-%s", environmentName, vpcID)
 }
 
+// rotatedDatabaseUsername returns the database user that replaces the given
+// user when the credentials of an RDS database installation are rotated.
+// Installations alternate between two users so that the previous user can be
+// revoked after the rotation.
+func rotatedDatabaseUsername(installationID, currentUsername string) string {
+	// Names can't be longer than 32 characters for MySQL databases.
+	primary := fmt.Sprintf("user_%s", installationID)
+	if currentUsername == primary {
+		return fmt.Sprintf("userb_%s", installationID)
+	}
+
+	return primary
+}
+
+// rotatedDatabaseSecret returns the credentials that replace the given
+// installation credentials when they are rotated, and whether they resume an
+// earlier rotation. A rotation that was never finalized is resumed instead of
+// creating another user: the pods may still use its stale user, which must be
+// revoked before it can be replaced.
+func rotatedDatabaseSecret(installationID string, installationSecret *RDSSecret) (*RDSSecret, bool) {
+	if installationSecret.StaleUsername != "" {
+		return installationSecret, true
+	}
+
+	return &RDSSecret{
+		MasterUsername: rotatedDatabaseUsername(installationID, installationSecret.MasterUsername),
+		MasterPassword: newRandomPassword(40),
+		StaleUsername:  installationSecret.MasterUsername,
+	}, false
+}
+
 // MattermostRDSDatabaseName formats the name of a Mattermost RDS database schema.
 func MattermostRDSDatabaseName(installationID string) string {
 	return fmt.Sprintf("%s%s", rdsDatabaseNamePrefix, installationID)
@@ -141,21 +141,18 @@ func (a *Client) iamEnsureUserDeleted(awsID string, logger log.FieldLogger) erro
 }
 
 func (a *Client) iamEnsureS3PolicyCreated(awsID, policyARN, bucketName, permittedDirectory string, logger log.FieldLogger) (*iam.Policy, error) {
-	getResult, err := a.Service().iam.GetPolicy(&iam.GetPolicyInput{
-		PolicyArn: aws.String(policyARN),
-	})
-	if err == nil {
-		logger.WithField("iam-policy-name", *getResult.Policy.PolicyName).Debug("AWS IAM policy already created")
-		return getResult.Policy, nil
-	}
-	if aerr, ok := err.(awserr.Error); ok {
-		if aerr.Code() != iam.ErrCodeNoSuchEntityException {
-			return nil, err
-		}
-	} else {
-		return nil, err
+	policy := policyDocument{
+		Version:   "2012-10-17",
+		Statement: s3PolicyStatements("", bucketName, permittedDirectory),
 	}
 
+	return a.iamEnsurePolicyCreated(awsID, policyARN, policy, logger)
+}
+
+// s3PolicyStatements returns the policy statements needed to manage objects in
+// the permitted directory of an S3 bucket. The sidSuffix is appended to the
+// statement IDs so that several locations can be allowed in one policy.
+func s3PolicyStatements(sidSuffix, bucketName, permittedDirectory string) []policyStatementEntry {
 	// The list condition directory needs a bit of logic to set correctly for
 	// the single and multi-tenant S3 filestores.
 	listCondition := policyStatementCondition{}
@@ -168,31 +165,45 @@ func (a *Client) iamEnsureS3PolicyCreated(awsID, policyARN, bucketName, permitte
 		}
 	}
 
-	policy := policyDocument{
-		Version: "2012-10-17",
-		Statement: []policyStatementEntry{
-			{
-				Sid:    "ListObjectsInBucket",
-				Effect: "Allow",
-				Action: []string{
-					"s3:ListBucket",
-				},
-				Resource:  fmt.Sprintf("arn:aws:s3:::%s", bucketName),
-				Condition: listCondition,
-			}, {
-				Sid:    "AllObjectActions",
-				Effect: "Allow",
-				Action: []string{
-					"s3:GetObject",
-					"s3:PutObject",
-					"s3:ListBucket",
-					"s3:PutObjectAcl",
-					"s3:DeleteObject",
-				},
-				Resource: fmt.Sprintf("arn:aws:s3:::%s/%s", bucketName, permittedDirectory),
+	return []policyStatementEntry{
+		{
+			Sid:    "ListObjectsInBucket" + sidSuffix,
+			Effect: "Allow",
+			Action: []string{
+				"s3:ListBucket",
 			},
+			Resource:  fmt.Sprintf("arn:aws:s3:::%s", bucketName),
+			Condition: listCondition,
+		}, {
+			Sid:    "AllObjectActions" + sidSuffix,
+			Effect: "Allow",
+			Action: []string{
+				"s3:GetObject",
+				"s3:PutObject",
+				"s3:ListBucket",
+				"s3:PutObjectAcl",
+				"s3:DeleteObject",
+			},
+			Resource: fmt.Sprintf("arn:aws:s3:::%s/%s", bucketName, permittedDirectory),
 		},
 	}
+}
+
+func (a *Client) iamEnsurePolicyCreated(awsID, policyARN string, policy policyDocument, logger log.FieldLogger) (*iam.Policy, error) {
+	getResult, err := a.Service().iam.GetPolicy(&iam.GetPolicyInput{
+		PolicyArn: aws.String(policyARN),
+	})
+	if err == nil {
+		logger.WithField("iam-policy-name", *getResult.Policy.PolicyName).Debug("AWS IAM policy already created")
+		return getResult.Policy, nil
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		if aerr.Code() != iam.ErrCodeNoSuchEntityException {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
 
 	b, err := json.Marshal(&policy)
 	if err != nil {
@@ -212,6 +223,50 @@ func (a *Client) iamEnsureS3PolicyCreated(awsID, policyARN, bucketName, permitte
 	return createResult.Policy, nil
 }
 
+// iamEnsurePolicyDocument replaces the document of an existing IAM policy with
+// a new default version. The users the policy is attached to keep their
+// access keys.
+func (a *Client) iamEnsurePolicyDocument(policyARN string, policy policyDocument, logger log.FieldLogger) error {
+	b, err := json.Marshal(&policy)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal IAM policy")
+	}
+
+	// A policy keeps at most five versions, so the versions that are no
+	// longer in use are removed first.
+	listResult, err := a.Service().iam.ListPolicyVersions(&iam.ListPolicyVersionsInput{
+		PolicyArn: aws.String(policyARN),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to list IAM policy versions")
+	}
+	for _, version := range listResult.Versions {
+		if aws.BoolValue(version.IsDefaultVersion) {
+			continue
+		}
+		_, err = a.Service().iam.DeletePolicyVersion(&iam.DeletePolicyVersionInput{
+			PolicyArn: aws.String(policyARN),
+			VersionId: version.VersionId,
+		})
+		if err != nil {
+			return errors.Wrap(err, "unable to delete IAM policy version")
+		}
+	}
+
+	_, err = a.Service().iam.CreatePolicyVersion(&iam.CreatePolicyVersionInput{
+		PolicyArn:      aws.String(policyARN),
+		PolicyDocument: aws.String(string(b)),
+		SetAsDefault:   aws.Bool(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to update IAM policy")
+	}
+
+	logger.WithField("iam-policy-arn", policyARN).Debug("AWS IAM policy updated")
+
+	return nil
+}
+
 func (a *Client) iamEnsurePolicyAttached(awsID, policyARN string, logger log.FieldLogger) error {
 	_, err := a.Service().iam.AttachUserPolicy(&iam.AttachUserPolicyInput{
 		PolicyArn: aws.String(policyARN),
@@ -256,6 +311,82 @@ func (a *Client) iamEnsureAccessKeyCreated(awsID string, logger log.FieldLogger)
 	return createResult.AccessKey, nil
 }
 
+// iamRotateAccessKey creates a new access key for the IAM user and stores it
+// in the user's access key secret. The previous access key is left active so
+// that it can keep being used until iamRevokeStaleAccessKeys is called.
+func (a *Client) iamRotateAccessKey(awsID string, logger log.FieldLogger) error {
+	current, err := a.secretsManagerGetIAMAccessKey(awsID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current access key")
+	}
+
+	// IAM users can only have two access keys, so clean up any key left
+	// behind by an earlier rotation attempt before creating a new one.
+	err = a.iamEnsureAccessKeysDeletedExcept(awsID, current.ID, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete stale access keys")
+	}
+
+	createResult, err := a.Service().iam.CreateAccessKey(&iam.CreateAccessKeyInput{
+		UserName: aws.String(awsID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create access key")
+	}
+
+	err = a.secretsManagerUpdateIAMAccessKeySecret(awsID, createResult.AccessKey, logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to store new access key")
+	}
+
+	logger.WithFields(log.Fields{
+		"iam-user-name":     awsID,
+		"iam-access-key-id": *createResult.AccessKey.AccessKeyId,
+	}).Info("AWS IAM user access key rotated")
+
+	return nil
+}
+
+// iamRevokeStaleAccessKeys deletes every access key of the IAM user other than
+// the one stored in the user's access key secret.
+func (a *Client) iamRevokeStaleAccessKeys(awsID string, logger log.FieldLogger) error {
+	current, err := a.secretsManagerGetIAMAccessKey(awsID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current access key")
+	}
+
+	return a.iamEnsureAccessKeysDeletedExcept(awsID, current.ID, logger)
+}
+
+func (a *Client) iamEnsureAccessKeysDeletedExcept(awsID, accessKeyID string, logger log.FieldLogger) error {
+	listResult, err := a.Service().iam.ListAccessKeys(&iam.ListAccessKeysInput{
+		UserName: aws.String(awsID),
+	})
+	if err != nil {
+		return err
+	}
+	for _, ak := range listResult.AccessKeyMetadata {
+		if *ak.AccessKeyId == accessKeyID {
+			continue
+		}
+
+		_, err = a.Service().iam.DeleteAccessKey(&iam.DeleteAccessKeyInput{
+			AccessKeyId: ak.AccessKeyId,
+			UserName:    aws.String(awsID),
+		})
+		if err != nil {
+			return err
+		}
+
+		logger.WithFields(log.Fields{
+			"iam-user-name":     awsID,
+			"iam-access-key-id": *ak.AccessKeyId,
+		}).Info("AWS IAM user access key deleted")
+	}
+
+	return nil
+}
+
 // GetAccountAliases returns the AWS account name aliases.
 func (a *Client) GetAccountAliases() (*iam.ListAccountAliasesOutput, error) {
 	accountAliases, err := a.Service().iam.ListAccountAliases(&iam.ListAccountAliasesInput{})
@@ -265,6 +396,73 @@ func (a *Client) GetAccountAliases() (*iam.ListAccountAliasesOutput, error) {
 	return accountAliases, nil
 }
 
+// EnsureS3BucketPolicyCreated creates the IAM policy granting full access to
+// the objects of an S3 bucket so that it can be attached to an IAM role.
+func (a *Client) EnsureS3BucketPolicyCreated(policyName, bucketName string, logger log.FieldLogger) error {
+	accountID, err := a.GetAccountID()
+	if err != nil {
+		return errors.Wrap(err, "unable to get the current AWS Account ID")
+	}
+	policyARN := fmt.Sprintf("arn:aws:iam::%s:policy/%s", accountID, policyName)
+
+	policy := policyDocument{
+		Version: "2012-10-17",
+		Statement: []policyStatementEntry{
+			{
+				Sid:      "ListBucket",
+				Effect:   "Allow",
+				Action:   []string{"s3:ListBucket"},
+				Resource: fmt.Sprintf("arn:aws:s3:::%s", bucketName),
+			}, {
+				Sid:    "AllObjectActions",
+				Effect: "Allow",
+				Action: []string{
+					"s3:GetObject",
+					"s3:PutObject",
+					"s3:DeleteObject",
+					"s3:AbortMultipartUpload",
+					"s3:ListMultipartUploadParts",
+				},
+				Resource: fmt.Sprintf("arn:aws:s3:::%s/*", bucketName),
+			},
+		},
+	}
+
+	_, err = a.iamEnsurePolicyCreated(policyName, policyARN, policy, logger)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create IAM policy for bucket %s", bucketName)
+	}
+
+	return nil
+}
+
+// EnsureS3BucketPolicyDeleted deletes an IAM policy created with
+// EnsureS3BucketPolicyCreated. The policy must be detached from all roles.
+func (a *Client) EnsureS3BucketPolicyDeleted(policyName string, logger log.FieldLogger) error {
+	accountID, err := a.GetAccountID()
+	if err != nil {
+		return errors.Wrap(err, "unable to get the current AWS Account ID")
+	}
+	policyARN := fmt.Sprintf("arn:aws:iam::%s:policy/%s", accountID, policyName)
+
+	_, err = a.Service().iam.DeletePolicy(&iam.DeletePolicyInput{
+		PolicyArn: aws.String(policyARN),
+	})
+	if aerr, ok := err.(awserr.Error); ok {
+		if aerr.Code() == iam.ErrCodeNoSuchEntityException {
+			logger.WithField("iam-policy", policyARN).Warn("IAM policy could not be found; assuming already deleted")
+			return nil
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to delete IAM policy")
+	}
+
+	logger.WithField("iam-policy", policyARN).Debug("AWS IAM policy deleted")
+
+	return nil
+}
+
 // AttachPolicyToRole attaches a precreated IAM policy to an IAM role.
 func (a *Client) AttachPolicyToRole(roleName, policyName string, logger log.FieldLogger) error {
 	accountID, err := a.GetAccountID()
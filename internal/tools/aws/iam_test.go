@@ -0,0 +1,132 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+)
+
+func (a *AWSTestSuite) TestIAMRotateAccessKey() {
+	awsID := CloudID(a.InstallationA.ID)
+
+	gomock.InOrder(
+		a.Mocks.API.SecretsManager.EXPECT().
+			GetSecretValue(gomock.Any()).
+			Return(&secretsmanager.GetSecretValueOutput{
+				SecretString: aws.String(`{"ID":"current","Secret":"secret"}`),
+			}, nil).
+			Times(1),
+		a.Mocks.API.IAM.EXPECT().
+			ListAccessKeys(gomock.Any()).
+			Return(&iam.ListAccessKeysOutput{
+				AccessKeyMetadata: []*iam.AccessKeyMetadata{
+					{AccessKeyId: aws.String("current")},
+					{AccessKeyId: aws.String("leftover")},
+				},
+			}, nil).
+			Times(1),
+		a.Mocks.API.IAM.EXPECT().
+			DeleteAccessKey(&iam.DeleteAccessKeyInput{
+				AccessKeyId: aws.String("leftover"),
+				UserName:    aws.String(awsID),
+			}).
+			Return(&iam.DeleteAccessKeyOutput{}, nil).
+			Times(1),
+		a.Mocks.API.IAM.EXPECT().
+			CreateAccessKey(gomock.Any()).
+			Return(&iam.CreateAccessKeyOutput{
+				AccessKey: &iam.AccessKey{
+					AccessKeyId:     aws.String("new"),
+					SecretAccessKey: aws.String("newsecret"),
+				},
+			}, nil).
+			Times(1),
+		a.Mocks.API.SecretsManager.EXPECT().
+			PutSecretValue(&secretsmanager.PutSecretValueInput{
+				SecretId:     aws.String(IAMSecretName(awsID)),
+				SecretString: aws.String(`{"ID":"new","Secret":"newsecret"}`),
+			}).
+			Return(&secretsmanager.PutSecretValueOutput{}, nil).
+			Times(1),
+	)
+
+	err := a.Mocks.AWS.iamRotateAccessKey(awsID, logrus.New())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestIAMRevokeStaleAccessKeys() {
+	awsID := CloudID(a.InstallationA.ID)
+
+	gomock.InOrder(
+		a.Mocks.API.SecretsManager.EXPECT().
+			GetSecretValue(gomock.Any()).
+			Return(&secretsmanager.GetSecretValueOutput{
+				SecretString: aws.String(`{"ID":"new","Secret":"newsecret"}`),
+			}, nil).
+			Times(1),
+		a.Mocks.API.IAM.EXPECT().
+			ListAccessKeys(gomock.Any()).
+			Return(&iam.ListAccessKeysOutput{
+				AccessKeyMetadata: []*iam.AccessKeyMetadata{
+					{AccessKeyId: aws.String("old")},
+					{AccessKeyId: aws.String("new")},
+				},
+			}, nil).
+			Times(1),
+		a.Mocks.API.IAM.EXPECT().
+			DeleteAccessKey(&iam.DeleteAccessKeyInput{
+				AccessKeyId: aws.String("old"),
+				UserName:    aws.String(awsID),
+			}).
+			Return(&iam.DeleteAccessKeyOutput{}, nil).
+			Times(1),
+	)
+
+	err := a.Mocks.AWS.iamRevokeStaleAccessKeys(awsID, logrus.New())
+	a.Assert().NoError(err)
+}
+
+func (a *AWSTestSuite) TestIAMEnsurePolicyDocument() {
+	policyARN := "arn:aws:iam::123456789012:policy/" + CloudID(a.InstallationA.ID)
+
+	gomock.InOrder(
+		a.Mocks.API.IAM.EXPECT().
+			ListPolicyVersions(gomock.Any()).
+			Return(&iam.ListPolicyVersionsOutput{
+				Versions: []*iam.PolicyVersion{
+					{VersionId: aws.String("v1"), IsDefaultVersion: aws.Bool(false)},
+					{VersionId: aws.String("v2"), IsDefaultVersion: aws.Bool(true)},
+				},
+			}, nil).
+			Times(1),
+		a.Mocks.API.IAM.EXPECT().
+			DeletePolicyVersion(&iam.DeletePolicyVersionInput{
+				PolicyArn: aws.String(policyARN),
+				VersionId: aws.String("v1"),
+			}).
+			Return(&iam.DeletePolicyVersionOutput{}, nil).
+			Times(1),
+		a.Mocks.API.IAM.EXPECT().
+			CreatePolicyVersion(gomock.Any()).
+			Do(func(input *iam.CreatePolicyVersionInput) {
+				a.Assert().Equal(policyARN, *input.PolicyArn)
+				a.Assert().True(*input.SetAsDefault)
+				a.Assert().Contains(*input.PolicyDocument, "arn:aws:s3:::bucket/prefix/*")
+			}).
+			Return(&iam.CreatePolicyVersionOutput{}, nil).
+			Times(1),
+	)
+
+	policy := policyDocument{
+		Version:   "2012-10-17",
+		Statement: s3PolicyStatements("", "bucket", "prefix"),
+	}
+	err := a.Mocks.AWS.iamEnsurePolicyDocument(policyARN, policy, logrus.New())
+	a.Assert().NoError(err)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// regionalClients caches the clients built for other AWS regions and
+// accounts so that their sessions and credentials are reused.
+type regionalClients struct {
+	mux     sync.Mutex
+	clients map[string]*Client
+}
+
+func newRegionalClients() *regionalClients {
+	return &regionalClients{
+		clients: make(map[string]*Client),
+	}
+}
+
+// ForRegion returns a client for the given AWS region that assumes the given
+// IAM role, if any. The client itself is returned when neither differs from
+// its own configuration.
+func (c *Client) ForRegion(region, assumeRoleARN string) (*Client, error) {
+	if len(region) == 0 {
+		region = aws.StringValue(c.config.Region)
+	}
+	if region == aws.StringValue(c.config.Region) && len(assumeRoleARN) == 0 {
+		return c, nil
+	}
+
+	key := region + "|" + assumeRoleARN
+	if c.regional != nil {
+		c.regional.mux.Lock()
+		defer c.regional.mux.Unlock()
+
+		if client, ok := c.regional.clients[key]; ok {
+			return client, nil
+		}
+	}
+
+	config := c.config.Copy().WithRegion(region)
+	if len(assumeRoleARN) != 0 {
+		sess, err := NewAWSSessionWithLogger(c.config, c.logger.WithField("tools-aws", "client"))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to initialize AWS session to assume role")
+		}
+		config = config.WithCredentials(stscreds.NewCredentials(sess, assumeRoleARN))
+	}
+
+	client := &Client{
+		store:    c.store,
+		logger:   c.logger.WithField("aws-region", region),
+		config:   config,
+		mux:      &sync.Mutex{},
+		regional: c.regional,
+	}
+	if c.regional != nil {
+		c.regional.clients[key] = client
+	}
+
+	return client, nil
+}
+
+// ClientForCluster returns a client for the AWS region and account the given
+// cluster is placed in.
+func (c *Client) ClientForCluster(cluster *model.Cluster) (AWS, error) {
+	client, err := c.ForRegion(cluster.AWSRegion(), cluster.AWSAssumeRoleARN())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get AWS client for cluster %s", cluster.ID)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientForRegion(t *testing.T) {
+	client := NewAWSClientWithConfig(&aws.Config{Region: aws.String(DefaultAWSRegion)}, testlib.MakeLogger(t))
+
+	t.Run("same region", func(t *testing.T) {
+		regional, err := client.ForRegion("", "")
+		require.NoError(t, err)
+		assert.Equal(t, client, regional)
+
+		regional, err = client.ForRegion(DefaultAWSRegion, "")
+		require.NoError(t, err)
+		assert.Equal(t, client, regional)
+	})
+
+	t.Run("other region", func(t *testing.T) {
+		regional, err := client.ForRegion("eu-central-1", "")
+		require.NoError(t, err)
+		assert.NotEqual(t, client, regional)
+		assert.Equal(t, "eu-central-1", aws.StringValue(regional.config.Region))
+		assert.Equal(t, DefaultAWSRegion, aws.StringValue(client.config.Region))
+
+		cached, err := client.ForRegion("eu-central-1", "")
+		require.NoError(t, err)
+		assert.True(t, regional == cached)
+	})
+
+	t.Run("other account", func(t *testing.T) {
+		roleARN := "arn:aws:iam::123456789012:role/provisioner"
+		regional, err := client.ForRegion("", roleARN)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultAWSRegion, aws.StringValue(regional.config.Region))
+		assert.NotEqual(t, client.config.Credentials, regional.config.Credentials)
+
+		other, err := client.ForRegion("eu-central-1", roleARN)
+		require.NoError(t, err)
+		assert.False(t, regional == other)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		regional, err := client.ClientForCluster(&model.Cluster{
+			ProviderMetadataAWS: &model.AWSMetadata{Region: "eu-central-1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "eu-central-1", aws.StringValue(regional.(*Client).config.Region))
+
+		regional, err = client.ClientForCluster(&model.Cluster{})
+		require.NoError(t, err)
+		assert.Equal(t, client, regional)
+	})
+}
@@ -5,20 +5,28 @@
 package aws
 
 import (
+	"net/url"
+	"path"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
-func (a *Client) s3EnsureBucketCreated(bucketName string, logger log.FieldLogger) error {
+// S3EnsureBucketCreated is used to ensure that a private and encrypted S3
+// bucket exists.
+func (a *Client) S3EnsureBucketCreated(bucketName string, logger log.FieldLogger) error {
 	_, err := a.Service().s3.CreateBucket(&s3.CreateBucketInput{
 		Bucket: aws.String(bucketName),
 		ACL:    aws.String("private"),
 	})
-	if err != nil {
+	if err != nil && !IsErrorCode(err, s3.ErrCodeBucketAlreadyOwnedByYou) {
 		return errors.Wrap(err, "unable to create bucket")
 	}
 
@@ -103,3 +111,76 @@ func (a *Client) S3EnsureBucketDirectoryDeleted(bucketName, directory string, lo
 
 	return nil
 }
+
+// S3CopyBucketDirectory copies every object of a bucket directory into
+// another directory of the same bucket.
+func (a *Client) S3CopyBucketDirectory(bucketName, sourceDirectory, destinationDirectory string, logger log.FieldLogger) error {
+	return s3CopyBucketDirectory(a.Service().s3, bucketName, sourceDirectory, destinationDirectory, logger)
+}
+
+func s3CopyBucketDirectory(s3API s3iface.S3API, bucketName, sourceDirectory, destinationDirectory string, logger log.FieldLogger) error {
+	var keys []string
+	err := s3API.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+		Prefix: aws.String(sourceDirectory),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, *object.Key)
+		}
+		return true
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list bucket directory objects")
+	}
+
+	for _, key := range keys {
+		_, err = s3API.CopyObject(&s3.CopyObjectInput{
+			Bucket:     aws.String(bucketName),
+			CopySource: aws.String(url.PathEscape(path.Join(bucketName, key))),
+			Key:        aws.String(destinationDirectory + strings.TrimPrefix(key, sourceDirectory)),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to copy object %s", key)
+		}
+	}
+
+	logger.WithFields(log.Fields{
+		"bucket":    bucketName,
+		"directory": sourceDirectory,
+		"objects":   len(keys),
+	}).Debugf("Copied bucket directory to %s", destinationDirectory)
+
+	return nil
+}
+
+// S3GetBucketUsage returns the total size and number of objects stored in a
+// bucket. If a directory is provided then only objects with that prefix are
+// counted.
+func (a *Client) S3GetBucketUsage(bucketName, directory string) (*model.StorageUsage, error) {
+	return s3BucketUsage(a.Service().s3, bucketName, directory)
+}
+
+func s3BucketUsage(s3API s3iface.S3API, bucketName, directory string) (*model.StorageUsage, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+	}
+	if directory != "" {
+		input.Prefix = aws.String(directory)
+	}
+
+	usage := &model.StorageUsage{}
+	err := s3API.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			usage.ObjectCount++
+			if object.Size != nil {
+				usage.Bytes += *object.Size
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list bucket objects")
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func (a *AWSTestSuite) TestS3GetBucketUsage() {
+	a.Mocks.API.S3.EXPECT().
+		ListObjectsV2Pages(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+			a.Assert().Equal(a.InstallationA.ID, *input.Prefix)
+			fn(&s3.ListObjectsV2Output{
+				Contents: []*s3.Object{
+					{Size: aws.Int64(100)},
+					{Size: aws.Int64(50)},
+				},
+			}, false)
+			fn(&s3.ListObjectsV2Output{
+				Contents: []*s3.Object{
+					{Size: aws.Int64(25)},
+				},
+			}, true)
+			return nil
+		}).
+		Times(1)
+
+	usage, err := a.Mocks.AWS.S3GetBucketUsage("bucket", a.InstallationA.ID)
+	a.Assert().NoError(err)
+	a.Assert().Equal(int64(175), usage.Bytes)
+	a.Assert().Equal(int64(3), usage.ObjectCount)
+}
+
+func (a *AWSTestSuite) TestS3GetBucketUsageError() {
+	a.Mocks.API.S3.EXPECT().
+		ListObjectsV2Pages(gomock.Any(), gomock.Any()).
+		Return(errors.New("list failure")).
+		Times(1)
+
+	usage, err := a.Mocks.AWS.S3GetBucketUsage("bucket", "")
+	a.Assert().Error(err)
+	a.Assert().Nil(usage)
+}
+
+func (a *AWSTestSuite) TestS3CopyBucketDirectory() {
+	a.Mocks.API.S3.EXPECT().
+		ListObjectsV2Pages(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+			a.Assert().Equal("cluster/", *input.Prefix)
+			fn(&s3.ListObjectsV2Output{
+				Contents: []*s3.Object{
+					{Key: aws.String("cluster/config")},
+					{Key: aws.String("cluster/pki/ca.key")},
+				},
+			}, true)
+			return nil
+		}).
+		Times(1)
+
+	a.Mocks.API.S3.EXPECT().
+		CopyObject(&s3.CopyObjectInput{
+			Bucket:     aws.String("bucket"),
+			CopySource: aws.String("bucket%2Fcluster%2Fconfig"),
+			Key:        aws.String("preview/cluster/config"),
+		}).
+		Return(&s3.CopyObjectOutput{}, nil).
+		Times(1)
+	a.Mocks.API.S3.EXPECT().
+		CopyObject(&s3.CopyObjectInput{
+			Bucket:     aws.String("bucket"),
+			CopySource: aws.String("bucket%2Fcluster%2Fpki%2Fca.key"),
+			Key:        aws.String("preview/cluster/pki/ca.key"),
+		}).
+		Return(&s3.CopyObjectOutput{}, nil).
+		Times(1)
+
+	a.Mocks.Log.Logger.EXPECT().
+		WithFields(logrus.Fields{"bucket": "bucket", "directory": "cluster/", "objects": 2}).
+		Return(testlib.NewLoggerEntry()).
+		Times(1)
+
+	err := a.Mocks.AWS.S3CopyBucketDirectory("bucket", "cluster/", "preview/cluster/", a.Mocks.Log.Logger)
+	a.Assert().NoError(err)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -38,6 +39,9 @@ func (s *IAMAccessKey) Validate() error {
 type RDSSecret struct {
 	MasterUsername string
 	MasterPassword string
+	// StaleUsername is the database user replaced by a credential rotation
+	// that hasn't been revoked yet.
+	StaleUsername string `json:",omitempty"`
 }
 
 // Validate performs a basic sanity check on the RDS secret.
@@ -55,6 +59,56 @@ func (s *RDSSecret) Validate() error {
 	return nil
 }
 
+// ExternalDatabaseSecret is the Secret payload for an external database.
+type ExternalDatabaseSecret struct {
+	ConnectionString string
+}
+
+// Validate performs a basic sanity check on the external database secret.
+func (s *ExternalDatabaseSecret) Validate() error {
+	_, err := model.ExternalDatabaseEngineType(s.ConnectionString)
+	return err
+}
+
+// ExternalFilestoreSecret is the Secret payload for an external filestore.
+type ExternalFilestoreSecret struct {
+	Bucket          string
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Validate performs a basic sanity check on the external filestore secret.
+func (s *ExternalFilestoreSecret) Validate() error {
+	if s.Bucket == "" {
+		return errors.New("External filestore bucket value is empty")
+	}
+	if s.AccessKeyID == "" {
+		return errors.New("External filestore access key ID value is empty")
+	}
+	if s.SecretAccessKey == "" {
+		return errors.New("External filestore secret access key value is empty")
+	}
+
+	return nil
+}
+
+// KubeconfigSecret is the Secret payload for the kubeconfig of an imported
+// cluster.
+type KubeconfigSecret struct {
+	Kubeconfig string
+}
+
+// Validate performs a basic sanity check on the kubeconfig secret.
+func (s *KubeconfigSecret) Validate() error {
+	if s.Kubeconfig == "" {
+		return errors.New("Kubeconfig value is empty")
+	}
+
+	return nil
+}
+
 func (a *Client) secretsManagerEnsureIAMAccessKeySecretCreated(awsID string, ak *iam.AccessKey, logger log.FieldLogger) error {
 	accessKeyPayload := &IAMAccessKey{
 		ID:     *ak.AccessKeyId,
@@ -83,6 +137,34 @@ func (a *Client) secretsManagerEnsureIAMAccessKeySecretCreated(awsID string, ak
 	return nil
 }
 
+func (a *Client) secretsManagerUpdateIAMAccessKeySecret(awsID string, ak *iam.AccessKey, logger log.FieldLogger) error {
+	accessKeyPayload := &IAMAccessKey{
+		ID:     *ak.AccessKeyId,
+		Secret: *ak.SecretAccessKey,
+	}
+	err := accessKeyPayload.Validate()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(&accessKeyPayload)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal secrets manager payload")
+	}
+
+	_, err = a.Service().secretsManager.PutSecretValue(&secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(IAMSecretName(awsID)),
+		SecretString: aws.String(string(b)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to update secrets manager secret")
+	}
+
+	logger.WithField("secret-name", IAMSecretName(awsID)).Debug("IAM access key secret updated")
+
+	return nil
+}
+
 func (a *Client) secretsManagerEnsureRDSSecretCreated(awsID string, logger log.FieldLogger) (*RDSSecret, error) {
 	secretName := RDSSecretName(awsID)
 	rdsSecretPayload := &RDSSecret{}
@@ -135,6 +217,30 @@ func (a *Client) secretsManagerEnsureRDSSecretCreated(awsID string, logger log.F
 	return rdsSecretPayload, nil
 }
 
+func (a *Client) secretsManagerUpdateRDSSecret(secretName string, rdsSecret *RDSSecret, logger log.FieldLogger) error {
+	err := rdsSecret.Validate()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(&rdsSecret)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal secrets manager payload")
+	}
+
+	_, err = a.Service().secretsManager.PutSecretValue(&secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(secretName),
+		SecretString: aws.String(string(b)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to update secrets manager secret")
+	}
+
+	logger.WithField("secret-name", secretName).Debug("AWS RDS secret updated")
+
+	return nil
+}
+
 // secretsManagerGetIAMAccessKey returns the AccessKey for an IAM account.
 func (a *Client) secretsManagerGetIAMAccessKey(awsID string) (*IAMAccessKey, error) {
 	return a.secretsManagerGetIAMAccessKeyFromSecretName(IAMSecretName(awsID))
@@ -165,7 +271,10 @@ func (a *Client) secretsManagerGetIAMAccessKeyFromSecretName(secretName string)
 }
 
 func (a *Client) secretsManagerGetRDSSecret(awsID string, logger log.FieldLogger) (*RDSSecret, error) {
-	secretName := RDSSecretName(awsID)
+	return a.secretsManagerGetRDSSecretFromSecretName(RDSSecretName(awsID))
+}
+
+func (a *Client) secretsManagerGetRDSSecretFromSecretName(secretName string) (*RDSSecret, error) {
 	result, err := a.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
 		SecretId: aws.String(secretName),
 	})
@@ -215,6 +324,179 @@ func (a *Client) secretsManagerEnsureSecretDeleted(secretName string, logger log
 	return nil
 }
 
+// EnsureExternalDatabaseSecret stores the connection details of the external
+// database of an installation, replacing any previously stored value.
+func (a *Client) EnsureExternalDatabaseSecret(installationID string, request *model.ExternalDatabaseRequest, logger log.FieldLogger) error {
+	secret := &ExternalDatabaseSecret{
+		ConnectionString: request.ConnectionString,
+	}
+	err := secret.Validate()
+	if err != nil {
+		return err
+	}
+
+	awsID := CloudID(installationID)
+
+	return a.secretsManagerEnsureSecretValue(ExternalDatabaseSecretName(awsID), fmt.Sprintf("External database configuration for %s", awsID), secret, logger)
+}
+
+// EnsureExternalFilestoreSecret stores the location and credentials of the
+// external filestore of an installation, replacing any previously stored
+// value.
+func (a *Client) EnsureExternalFilestoreSecret(installationID string, request *model.ExternalFilestoreRequest, logger log.FieldLogger) error {
+	secret := &ExternalFilestoreSecret{
+		Bucket:          request.Bucket,
+		Endpoint:        request.Endpoint,
+		Region:          request.Region,
+		AccessKeyID:     request.AccessKeyID,
+		SecretAccessKey: request.SecretAccessKey,
+	}
+	err := secret.Validate()
+	if err != nil {
+		return err
+	}
+
+	awsID := CloudID(installationID)
+
+	return a.secretsManagerEnsureSecretValue(ExternalFilestoreSecretName(awsID), fmt.Sprintf("External filestore configuration for %s", awsID), secret, logger)
+}
+
+// EnsureExternalDatabaseSecretDeleted deletes the connection details of the
+// external database of an installation.
+func (a *Client) EnsureExternalDatabaseSecretDeleted(installationID string, logger log.FieldLogger) error {
+	return a.secretsManagerEnsureSecretDeleted(ExternalDatabaseSecretName(CloudID(installationID)), logger)
+}
+
+// EnsureExternalFilestoreSecretDeleted deletes the location and credentials of
+// the external filestore of an installation.
+func (a *Client) EnsureExternalFilestoreSecretDeleted(installationID string, logger log.FieldLogger) error {
+	return a.secretsManagerEnsureSecretDeleted(ExternalFilestoreSecretName(CloudID(installationID)), logger)
+}
+
+// CreateKubeconfigSecret stores the kubeconfig of an imported cluster and
+// returns the name of the secret holding it.
+func (a *Client) CreateKubeconfigSecret(kubeconfig string, logger log.FieldLogger) (string, error) {
+	secret := &KubeconfigSecret{
+		Kubeconfig: kubeconfig,
+	}
+	err := secret.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	secretName := KubeconfigSecretName(CloudID(model.NewID()))
+	err = a.secretsManagerEnsureSecretValue(secretName, "Kubeconfig of an imported cluster", secret, logger)
+	if err != nil {
+		return "", err
+	}
+
+	return secretName, nil
+}
+
+// GetKubeconfigSecret returns the kubeconfig stored in the given secret.
+func (a *Client) GetKubeconfigSecret(secretName string) (string, error) {
+	secret := &KubeconfigSecret{}
+	err := a.secretsManagerGetSecretValue(secretName, secret)
+	if err != nil {
+		return "", err
+	}
+
+	err = secret.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	return secret.Kubeconfig, nil
+}
+
+// EnsureKubeconfigSecretDeleted deletes the secret holding the kubeconfig of
+// an imported cluster.
+func (a *Client) EnsureKubeconfigSecretDeleted(secretName string, logger log.FieldLogger) error {
+	return a.secretsManagerEnsureSecretDeleted(secretName, logger)
+}
+
+// secretsManagerEnsureSecretValue creates a secret with the given payload or
+// updates its value if it already exists.
+func (a *Client) secretsManagerEnsureSecretValue(secretName, description string, payload interface{}, logger log.FieldLogger) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal secrets manager payload")
+	}
+
+	_, err = a.Service().secretsManager.CreateSecret(&secretsmanager.CreateSecretInput{
+		Name:         aws.String(secretName),
+		Description:  aws.String(description),
+		SecretString: aws.String(string(b)),
+	})
+	if err == nil {
+		logger.WithField("secret-name", secretName).Debug("Secret Manager secret created")
+		return nil
+	}
+	if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != secretsmanager.ErrCodeResourceExistsException {
+		return errors.Wrap(err, "unable to create secrets manager secret")
+	}
+
+	_, err = a.Service().secretsManager.PutSecretValue(&secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(secretName),
+		SecretString: aws.String(string(b)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to update secrets manager secret")
+	}
+
+	logger.WithField("secret-name", secretName).Debug("Secret Manager secret updated")
+
+	return nil
+}
+
+// secretsManagerGetSecretValue unmarshals the value of the given secret into
+// payload.
+func (a *Client) secretsManagerGetSecretValue(secretName string, payload interface{}) error {
+	result, err := a.Service().secretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretName),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to get secrets manager secret %s", secretName)
+	}
+
+	err = json.Unmarshal([]byte(*result.SecretString), payload)
+	if err != nil {
+		return errors.Wrap(err, "unable to unmarshal secrets manager payload")
+	}
+
+	return nil
+}
+
+func (a *Client) secretsManagerGetExternalDatabaseSecret(awsID string) (*ExternalDatabaseSecret, error) {
+	secret := &ExternalDatabaseSecret{}
+	err := a.secretsManagerGetSecretValue(ExternalDatabaseSecretName(awsID), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = secret.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+func (a *Client) secretsManagerGetExternalFilestoreSecret(awsID string) (*ExternalFilestoreSecret, error) {
+	secret := &ExternalFilestoreSecret{}
+	err := a.secretsManagerGetSecretValue(ExternalFilestoreSecretName(awsID), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = secret.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
 func unmarshalSecretPayload(payload string) (*RDSSecret, error) {
 	var secret RDSSecret
 	err := json.Unmarshal([]byte(payload), &secret)
@@ -0,0 +1,56 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package aws
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+)
+
+func (a *AWSTestSuite) TestKubeconfigSecret() {
+	kubeconfig := "apiVersion: v1\nkind: Config\n"
+
+	a.Run("secret created", func() {
+		var createdSecretName string
+		a.Mocks.API.SecretsManager.EXPECT().
+			CreateSecret(gomock.Any()).
+			Do(func(input *secretsmanager.CreateSecretInput) {
+				createdSecretName = *input.Name
+				a.Assert().Contains(*input.SecretString, "kind: Config")
+			}).
+			Return(&secretsmanager.CreateSecretOutput{}, nil).
+			Times(1)
+
+		secretName, err := a.Mocks.AWS.CreateKubeconfigSecret(kubeconfig, logrus.New())
+		a.Require().NoError(err)
+		a.Assert().Equal(createdSecretName, secretName)
+		a.Assert().True(strings.HasPrefix(secretName, cloudIDPrefix))
+		a.Assert().True(strings.HasSuffix(secretName, kubeconfigSuffix))
+	})
+
+	a.Run("empty kubeconfig", func() {
+		_, err := a.Mocks.AWS.CreateKubeconfigSecret("", logrus.New())
+		a.Assert().Error(err)
+	})
+
+	a.Run("secret read", func() {
+		a.Mocks.API.SecretsManager.EXPECT().
+			GetSecretValue(&secretsmanager.GetSecretValueInput{
+				SecretId: aws.String("cloud-id-kubeconfig"),
+			}).
+			Return(&secretsmanager.GetSecretValueOutput{
+				SecretString: aws.String(`{"Kubeconfig":"apiVersion: v1\nkind: Config\n"}`),
+			}, nil).
+			Times(1)
+
+		storedKubeconfig, err := a.Mocks.AWS.GetKubeconfigSecret("cloud-id-kubeconfig")
+		a.Require().NoError(err)
+		a.Assert().Equal(kubeconfig, storedKubeconfig)
+	})
+}
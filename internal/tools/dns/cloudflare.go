@@ -0,0 +1,215 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	cloudflareAPIURL = "https://api.cloudflare.com/client/v4"
+	cloudflareTTL    = 60
+)
+
+// CloudflareProvider manages the installation DNS records in the Cloudflare
+// zone of the installation domain.
+type CloudflareProvider struct {
+	apiToken   string
+	apiURL     string
+	httpClient *http.Client
+}
+
+type cloudflareResponse struct {
+	Success bool              `json:"success"`
+	Errors  []cloudflareError `json:"errors"`
+	Result  json.RawMessage   `json:"result"`
+}
+
+type cloudflareError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type cloudflareZone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type cloudflareRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl"`
+	Proxied bool   `json:"proxied"`
+}
+
+// NewCloudflareProvider creates a new CloudflareProvider authenticating with
+// the given API token.
+func NewCloudflareProvider(apiToken string) *CloudflareProvider {
+	return &CloudflareProvider{
+		apiToken:   apiToken,
+		apiURL:     cloudflareAPIURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreatePublicCNAME creates or updates a CNAME record pointing at the given
+// endpoint. Cloudflare supports a single endpoint per CNAME record.
+func (p *CloudflareProvider) CreatePublicCNAME(dnsName string, dnsEndpoints []string, logger log.FieldLogger) error {
+	if len(dnsEndpoints) != 1 {
+		return errors.Errorf("expected exactly one endpoint for CNAME %s, but got %d", dnsName, len(dnsEndpoints))
+	}
+	logger = logger.WithField("dns-provider", ProviderCloudflare)
+
+	zoneID, err := p.getZoneID(dnsName)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create a public CNAME: %s", dnsName)
+	}
+
+	record, err := p.getRecord(zoneID, dnsName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get CNAME %s", dnsName)
+	}
+
+	newRecord := &cloudflareRecord{
+		Type:    "CNAME",
+		Name:    dnsName,
+		Content: dnsEndpoints[0],
+		TTL:     cloudflareTTL,
+	}
+	if record == nil {
+		err = p.do(http.MethodPost, fmt.Sprintf("/zones/%s/dns_records", zoneID), newRecord, nil)
+	} else {
+		err = p.do(http.MethodPut, fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, record.ID), newRecord, nil)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to create CNAME %s", dnsName)
+	}
+
+	logger.WithFields(log.Fields{
+		"cloudflare-dns-value": dnsEndpoints[0],
+		"cloudflare-zone-id":   zoneID,
+	}).Debugf("Cloudflare CNAME %s created", dnsName)
+
+	return nil
+}
+
+// DeletePublicCNAME deletes the CNAME record of the given name. No error is
+// returned if the record doesn't exist.
+func (p *CloudflareProvider) DeletePublicCNAME(dnsName string, logger log.FieldLogger) error {
+	logger = logger.WithField("dns-provider", ProviderCloudflare)
+
+	zoneID, err := p.getZoneID(dnsName)
+	if err != nil {
+		return errors.Wrapf(err, "unable to delete a public CNAME: %s", dnsName)
+	}
+
+	record, err := p.getRecord(zoneID, dnsName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get CNAME %s", dnsName)
+	}
+	if record == nil {
+		logger.Warnf("Unable to find any Cloudflare CNAME %s to delete", dnsName)
+		return nil
+	}
+
+	err = p.do(http.MethodDelete, fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, record.ID), nil, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete CNAME %s", dnsName)
+	}
+
+	logger.WithField("cloudflare-zone-id", zoneID).Debugf("Cloudflare CNAME %s deleted", dnsName)
+
+	return nil
+}
+
+// getZoneID returns the ID of the closest Cloudflare zone enclosing the given
+// DNS name.
+func (p *CloudflareProvider) getZoneID(dnsName string) (string, error) {
+	labels := strings.Split(strings.TrimSuffix(dnsName, "."), ".")
+	for i := 1; i < len(labels)-1; i++ {
+		zoneName := strings.Join(labels[i:], ".")
+
+		var zones []cloudflareZone
+		err := p.do(http.MethodGet, "/zones?name="+url.QueryEscape(zoneName), nil, &zones)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to query zone %s", zoneName)
+		}
+		if len(zones) != 0 {
+			return zones[0].ID, nil
+		}
+	}
+
+	return "", errors.Errorf("no Cloudflare zone found for %s", dnsName)
+}
+
+// getRecord returns the CNAME record of the given name, or nil if it doesn't
+// exist.
+func (p *CloudflareProvider) getRecord(zoneID, dnsName string) (*cloudflareRecord, error) {
+	var records []cloudflareRecord
+	err := p.do(http.MethodGet, fmt.Sprintf("/zones/%s/dns_records?type=CNAME&name=%s", zoneID, url.QueryEscape(dnsName)), nil, &records)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	return &records[0], nil
+}
+
+// do sends a request to the Cloudflare API and decodes the result of the
+// response into result, if not nil.
+func (p *CloudflareProvider) do(method, path string, body, result interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&reqBody).Encode(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode request")
+		}
+	}
+
+	req, err := http.NewRequest(method, p.apiURL+path, &reqBody)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	var response cloudflareResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode response with status code %d", resp.StatusCode)
+	}
+	if !response.Success {
+		var messages []string
+		for _, e := range response.Errors {
+			messages = append(messages, fmt.Sprintf("%d: %s", e.Code, e.Message))
+		}
+		return errors.Errorf("request failed with status code %d: %s", resp.StatusCode, strings.Join(messages, ", "))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(response.Result, result)
+}
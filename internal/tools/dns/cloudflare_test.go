@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCloudflare serves a single zone through the subset of the Cloudflare API
+// used by the provider.
+type fakeCloudflare struct {
+	zone    cloudflareZone
+	records map[string]cloudflareRecord
+	nextID  int
+}
+
+func (f *fakeCloudflare) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(cloudflareResponse{Errors: []cloudflareError{{Code: 10000, Message: "Authentication error"}}})
+		return
+	}
+
+	var result interface{}
+	recordsPath := fmt.Sprintf("/zones/%s/dns_records", f.zone.ID)
+	switch {
+	case r.URL.Path == "/zones":
+		zones := []cloudflareZone{}
+		if r.URL.Query().Get("name") == f.zone.Name {
+			zones = append(zones, f.zone)
+		}
+		result = zones
+	case r.URL.Path == recordsPath && r.Method == http.MethodGet:
+		records := []cloudflareRecord{}
+		for _, record := range f.records {
+			if record.Name == r.URL.Query().Get("name") {
+				records = append(records, record)
+			}
+		}
+		result = records
+	case r.URL.Path == recordsPath && r.Method == http.MethodPost:
+		var record cloudflareRecord
+		json.NewDecoder(r.Body).Decode(&record)
+		f.nextID++
+		record.ID = fmt.Sprintf("record%d", f.nextID)
+		f.records[record.ID] = record
+		result = record
+	case strings.HasPrefix(r.URL.Path, recordsPath+"/") && r.Method == http.MethodPut:
+		var record cloudflareRecord
+		json.NewDecoder(r.Body).Decode(&record)
+		record.ID = strings.TrimPrefix(r.URL.Path, recordsPath+"/")
+		f.records[record.ID] = record
+		result = record
+	case strings.HasPrefix(r.URL.Path, recordsPath+"/") && r.Method == http.MethodDelete:
+		delete(f.records, strings.TrimPrefix(r.URL.Path, recordsPath+"/"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(cloudflareResponse{Errors: []cloudflareError{{Code: 7003, Message: "Could not route"}}})
+		return
+	}
+
+	data, _ := json.Marshal(result)
+	json.NewEncoder(w).Encode(cloudflareResponse{Success: true, Result: data})
+}
+
+func (f *fakeCloudflare) contents() []string {
+	var contents []string
+	for _, record := range f.records {
+		contents = append(contents, record.Name+"="+record.Content)
+	}
+
+	return contents
+}
+
+func TestCloudflareProvider(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	fake := &fakeCloudflare{
+		zone:    cloudflareZone{ID: "zone1", Name: "example.com"},
+		records: map[string]cloudflareRecord{},
+	}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	provider := NewCloudflareProvider("token")
+	provider.apiURL = ts.URL
+
+	t.Run("create record", func(t *testing.T) {
+		err := provider.CreatePublicCNAME("test.cloud.example.com", []string{"lb1.amazonaws.com"}, logger)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"test.cloud.example.com=lb1.amazonaws.com"}, fake.contents())
+	})
+
+	t.Run("update record", func(t *testing.T) {
+		err := provider.CreatePublicCNAME("test.cloud.example.com", []string{"lb2.amazonaws.com"}, logger)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"test.cloud.example.com=lb2.amazonaws.com"}, fake.contents())
+	})
+
+	t.Run("multiple endpoints", func(t *testing.T) {
+		err := provider.CreatePublicCNAME("test.cloud.example.com", []string{"lb1.amazonaws.com", "lb2.amazonaws.com"}, logger)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown zone", func(t *testing.T) {
+		err := provider.CreatePublicCNAME("test.example.org", []string{"lb1.amazonaws.com"}, logger)
+		require.EqualError(t, err, "unable to create a public CNAME: test.example.org: no Cloudflare zone found for test.example.org")
+	})
+
+	t.Run("delete record", func(t *testing.T) {
+		err := provider.DeletePublicCNAME("test.cloud.example.com", logger)
+		require.NoError(t, err)
+		assert.Empty(t, fake.contents())
+	})
+
+	t.Run("delete missing record", func(t *testing.T) {
+		err := provider.DeletePublicCNAME("test.cloud.example.com", logger)
+		require.NoError(t, err)
+	})
+
+	t.Run("authentication error", func(t *testing.T) {
+		provider := NewCloudflareProvider("invalid")
+		provider.apiURL = ts.URL
+
+		err := provider.CreatePublicCNAME("test.cloud.example.com", []string{"lb1.amazonaws.com"}, logger)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "request failed with status code 403: 10000: Authentication error")
+	})
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package dns provides the implementations of the public DNS providers the
+// installation DNS records can be managed with.
+package dns
+
+const (
+	// ProviderRoute53 manages the installation DNS records in the public
+	// Route53 hosted zone.
+	ProviderRoute53 = "route53"
+	// ProviderCloudflare manages the installation DNS records in Cloudflare.
+	ProviderCloudflare = "cloudflare"
+	// ProviderMemory keeps the installation DNS records in memory, which is
+	// intended for local development and tests.
+	ProviderMemory = "memory"
+)
+
+// IsSupportedProvider returns true if the given DNS provider is supported.
+func IsSupportedProvider(provider string) bool {
+	switch provider {
+	case ProviderRoute53, ProviderCloudflare, ProviderMemory:
+		return true
+	}
+
+	return false
+}
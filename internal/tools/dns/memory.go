@@ -0,0 +1,57 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package dns
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MemoryProvider keeps the installation DNS records in memory. It lets local
+// servers and tests run without a real DNS provider.
+type MemoryProvider struct {
+	lock    sync.Mutex
+	records map[string][]string
+}
+
+// NewMemoryProvider creates a new MemoryProvider without records.
+func NewMemoryProvider() *MemoryProvider {
+	return &MemoryProvider{
+		records: map[string][]string{},
+	}
+}
+
+// CreatePublicCNAME creates or updates a CNAME record pointing at the given
+// endpoints.
+func (p *MemoryProvider) CreatePublicCNAME(dnsName string, dnsEndpoints []string, logger log.FieldLogger) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.records[dnsName] = append([]string{}, dnsEndpoints...)
+	logger.WithField("dns-provider", ProviderMemory).Debugf("Created CNAME %s pointing at %v", dnsName, dnsEndpoints)
+
+	return nil
+}
+
+// DeletePublicCNAME deletes the CNAME record of the given name.
+func (p *MemoryProvider) DeletePublicCNAME(dnsName string, logger log.FieldLogger) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.records, dnsName)
+	logger.WithField("dns-provider", ProviderMemory).Debugf("Deleted CNAME %s", dnsName)
+
+	return nil
+}
+
+// GetCNAME returns the endpoints the CNAME record of the given name points
+// at, or nil if there is no such record.
+func (p *MemoryProvider) GetCNAME(dnsName string) []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.records[dnsName]
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package dns
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryProvider(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	provider := NewMemoryProvider()
+
+	assert.Nil(t, provider.GetCNAME("test.example.com"))
+
+	err := provider.CreatePublicCNAME("test.example.com", []string{"lb1.amazonaws.com"}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lb1.amazonaws.com"}, provider.GetCNAME("test.example.com"))
+
+	err = provider.CreatePublicCNAME("test.example.com", []string{"lb2.amazonaws.com"}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lb2.amazonaws.com"}, provider.GetCNAME("test.example.com"))
+
+	err = provider.DeletePublicCNAME("test.example.com", logger)
+	require.NoError(t, err)
+	assert.Nil(t, provider.GetCNAME("test.example.com"))
+}
+
+func TestIsSupportedProvider(t *testing.T) {
+	assert.True(t, IsSupportedProvider(ProviderRoute53))
+	assert.True(t, IsSupportedProvider(ProviderCloudflare))
+	assert.True(t, IsSupportedProvider(ProviderMemory))
+	assert.False(t, IsSupportedProvider("unknown"))
+}
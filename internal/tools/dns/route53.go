@@ -0,0 +1,39 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package dns
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// route53Client abstracts the Route53 operations of the AWS client.
+type route53Client interface {
+	CreatePublicCNAME(dnsName string, dnsEndpoints []string, logger log.FieldLogger) error
+	DeletePublicCNAME(dnsName string, logger log.FieldLogger) error
+}
+
+// Route53Provider manages the installation DNS records in the public Route53
+// hosted zone.
+type Route53Provider struct {
+	client route53Client
+}
+
+// NewRoute53Provider creates a new Route53Provider.
+func NewRoute53Provider(client route53Client) *Route53Provider {
+	return &Route53Provider{
+		client: client,
+	}
+}
+
+// CreatePublicCNAME creates or updates a public CNAME record pointing at the
+// given endpoints.
+func (p *Route53Provider) CreatePublicCNAME(dnsName string, dnsEndpoints []string, logger log.FieldLogger) error {
+	return p.client.CreatePublicCNAME(dnsName, dnsEndpoints, logger.WithField("dns-provider", ProviderRoute53))
+}
+
+// DeletePublicCNAME deletes the public CNAME record of the given name.
+func (p *Route53Provider) DeletePublicCNAME(dnsName string, logger log.FieldLogger) error {
+	return p.client.DeletePublicCNAME(dnsName, logger.WithField("dns-provider", ProviderRoute53))
+}
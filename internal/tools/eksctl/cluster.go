@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package eksctl
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ClusterSummary is the summary of a cluster returned by eksctl get cluster.
+type ClusterSummary struct {
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
+	Status  string `json:"Status"`
+}
+
+// CreateCluster invokes eksctl create cluster with the given configuration.
+// The kubeconfig of the new cluster is written to the kubeconfig path.
+func (c *Cmd) CreateCluster(config *ClusterConfig) error {
+	filename, err := c.writeClusterConfig(config)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.run(
+		"create", "cluster",
+		arg("config-file", filename),
+		arg("kubeconfig", c.GetKubeConfigPath()),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke eksctl create cluster")
+	}
+
+	return nil
+}
+
+// UpgradeCluster invokes eksctl upgrade cluster, upgrading the control plane
+// of the cluster to the given version.
+func (c *Cmd) UpgradeCluster(name, region, version string) error {
+	_, _, err := c.run(
+		"upgrade", "cluster",
+		arg("name", name),
+		arg("region", region),
+		arg("version", version),
+		"--approve",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke eksctl upgrade cluster")
+	}
+
+	return nil
+}
+
+// DeleteCluster invokes eksctl delete cluster and waits for the cluster
+// resources to be deleted.
+func (c *Cmd) DeleteCluster(name, region string) error {
+	_, _, err := c.run(
+		"delete", "cluster",
+		arg("name", name),
+		arg("region", region),
+		"--wait",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke eksctl delete cluster")
+	}
+
+	return nil
+}
+
+// GetCluster invokes eksctl get cluster and returns the cluster summary.
+func (c *Cmd) GetCluster(name, region string) (*ClusterSummary, error) {
+	stdout, _, err := c.run(
+		"get", "cluster",
+		arg("name", name),
+		arg("region", region),
+		arg("output", "json"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to invoke eksctl get cluster")
+	}
+
+	var clusters []ClusterSummary
+	err = json.Unmarshal(stdout, &clusters)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal eksctl get cluster output")
+	}
+	if len(clusters) != 1 {
+		return nil, errors.Errorf("expected one cluster named %s, but found %d", name, len(clusters))
+	}
+
+	return &clusters[0], nil
+}
+
+// WriteKubeconfig invokes eksctl utils write-kubeconfig, writing the
+// kubeconfig of the cluster to the kubeconfig path.
+func (c *Cmd) WriteKubeconfig(name, region string) error {
+	_, _, err := c.run(
+		"utils", "write-kubeconfig",
+		arg("cluster", name),
+		arg("region", region),
+		arg("kubeconfig", c.GetKubeConfigPath()),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke eksctl utils write-kubeconfig")
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package eksctl
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const kubeConfigName = "kubeconfig"
+
+// Cmd is the eksctl command to execute.
+type Cmd struct {
+	eksctlPath string
+	tempDir    string
+	logger     log.FieldLogger
+}
+
+// New creates a new instance of Cmd through which to execute eksctl.
+func New(logger log.FieldLogger) (*Cmd, error) {
+	eksctlPath, err := exec.LookPath("eksctl")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find eksctl installed on your PATH")
+	}
+
+	tempDir, err := ioutil.TempDir("", "eksctl-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temporary eksctl directory")
+	}
+
+	return &Cmd{
+		eksctlPath: eksctlPath,
+		tempDir:    tempDir,
+		logger:     logger,
+	}, nil
+}
+
+// GetTempDir returns the root temporary directory used by eksctl.
+func (c *Cmd) GetTempDir() string {
+	return c.tempDir
+}
+
+// GetKubeConfigPath returns the temporary kubeconfig path used by eksctl.
+func (c *Cmd) GetKubeConfigPath() string {
+	return path.Join(c.tempDir, kubeConfigName)
+}
+
+// Close cleans up the temporary directory used by eksctl.
+func (c *Cmd) Close() error {
+	return os.RemoveAll(c.tempDir)
+}
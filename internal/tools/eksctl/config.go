@@ -0,0 +1,80 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package eksctl
+
+import (
+	"io/ioutil"
+	"path"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	clusterConfigAPIVersion = "eksctl.io/v1alpha5"
+	clusterConfigKind       = "ClusterConfig"
+	clusterConfigFilename   = "cluster.yaml"
+)
+
+// ClusterConfig is the eksctl configuration file describing a cluster and
+// its managed node groups.
+type ClusterConfig struct {
+	APIVersion        string             `yaml:"apiVersion"`
+	Kind              string             `yaml:"kind"`
+	Metadata          ClusterMeta        `yaml:"metadata"`
+	AvailabilityZones []string           `yaml:"availabilityZones,omitempty"`
+	ManagedNodeGroups []ManagedNodeGroup `yaml:"managedNodeGroups,omitempty"`
+}
+
+// ClusterMeta describes the cluster in a ClusterConfig.
+type ClusterMeta struct {
+	Name    string            `yaml:"name"`
+	Region  string            `yaml:"region"`
+	Version string            `yaml:"version,omitempty"`
+	Tags    map[string]string `yaml:"tags,omitempty"`
+}
+
+// ManagedNodeGroup describes an EKS managed node group in a ClusterConfig.
+type ManagedNodeGroup struct {
+	Name            string            `yaml:"name"`
+	InstanceType    string            `yaml:"instanceType"`
+	MinSize         int64             `yaml:"minSize"`
+	MaxSize         int64             `yaml:"maxSize"`
+	DesiredCapacity int64             `yaml:"desiredCapacity"`
+	Labels          map[string]string `yaml:"labels,omitempty"`
+}
+
+// NewClusterConfig returns the configuration of a cluster with the given
+// managed node groups.
+func NewClusterConfig(name, region, version string, zones []string, nodeGroups ...ManagedNodeGroup) *ClusterConfig {
+	return &ClusterConfig{
+		APIVersion: clusterConfigAPIVersion,
+		Kind:       clusterConfigKind,
+		Metadata: ClusterMeta{
+			Name:    name,
+			Region:  region,
+			Version: version,
+		},
+		AvailabilityZones: zones,
+		ManagedNodeGroups: nodeGroups,
+	}
+}
+
+// writeClusterConfig writes the cluster configuration to the temporary
+// directory and returns the path of the file.
+func (c *Cmd) writeClusterConfig(config *ClusterConfig) (string, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal cluster config")
+	}
+
+	filename := path.Join(c.tempDir, clusterConfigFilename)
+	err = ioutil.WriteFile(filename, data, 0600)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write cluster config")
+	}
+
+	return filename, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package eksctl
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteClusterConfig(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "eksctl-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	c := &Cmd{tempDir: tempDir, logger: testlib.MakeLogger(t)}
+
+	config := NewClusterConfig("cluster1", "us-east-1", "1.17", []string{"us-east-1a", "us-east-1b"}, ManagedNodeGroup{
+		Name:            "nodes-m5-large",
+		InstanceType:    "m5.large",
+		MinSize:         2,
+		MaxSize:         3,
+		DesiredCapacity: 2,
+	})
+
+	filename, err := c.writeClusterConfig(config)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: eksctl.io/v1alpha5
+kind: ClusterConfig
+metadata:
+  name: cluster1
+  region: us-east-1
+  version: "1.17"
+availabilityZones:
+- us-east-1a
+- us-east-1b
+managedNodeGroups:
+- name: nodes-m5-large
+  instanceType: m5.large
+  minSize: 2
+  maxSize: 3
+  desiredCapacity: 2
+`, string(data))
+}
+
+func TestArg(t *testing.T) {
+	assert.Equal(t, "--name=cluster1", arg("name", "cluster1"))
+	assert.Equal(t, "--nodes-min=2", arg("--nodes-min", "2"))
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package eksctl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// NodeGroupSummary is the summary of a node group returned by eksctl get
+// nodegroup.
+type NodeGroupSummary struct {
+	Cluster         string `json:"Cluster"`
+	Name            string `json:"Name"`
+	Status          string `json:"Status"`
+	MinSize         int64  `json:"MinSize"`
+	MaxSize         int64  `json:"MaxSize"`
+	DesiredCapacity int64  `json:"DesiredCapacity"`
+	InstanceType    string `json:"InstanceType"`
+	// NodeInstanceRoleARN is the IAM role of the nodes in the node group.
+	NodeInstanceRoleARN string `json:"NodeInstanceRoleARN"`
+}
+
+// CreateNodeGroups invokes eksctl create nodegroup for the managed node
+// groups of the given configuration.
+func (c *Cmd) CreateNodeGroups(config *ClusterConfig) error {
+	filename, err := c.writeClusterConfig(config)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.run(
+		"create", "nodegroup",
+		arg("config-file", filename),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke eksctl create nodegroup")
+	}
+
+	return nil
+}
+
+// DeleteNodeGroup invokes eksctl delete nodegroup, draining the nodes
+// before they are terminated.
+func (c *Cmd) DeleteNodeGroup(clusterName, region, name string) error {
+	_, _, err := c.run(
+		"delete", "nodegroup",
+		arg("cluster", clusterName),
+		arg("region", region),
+		arg("name", name),
+		"--wait",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke eksctl delete nodegroup")
+	}
+
+	return nil
+}
+
+// ScaleNodeGroup invokes eksctl scale nodegroup.
+func (c *Cmd) ScaleNodeGroup(clusterName, region, name string, minCount, maxCount int64) error {
+	_, _, err := c.run(
+		"scale", "nodegroup",
+		arg("cluster", clusterName),
+		arg("region", region),
+		arg("name", name),
+		arg("nodes", fmt.Sprintf("%d", minCount)),
+		arg("nodes-min", fmt.Sprintf("%d", minCount)),
+		arg("nodes-max", fmt.Sprintf("%d", maxCount)),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke eksctl scale nodegroup")
+	}
+
+	return nil
+}
+
+// UpgradeNodeGroup invokes eksctl upgrade nodegroup, replacing the nodes of
+// the managed node group with nodes running the given kubernetes version.
+func (c *Cmd) UpgradeNodeGroup(clusterName, region, name, version string) error {
+	_, _, err := c.run(
+		"upgrade", "nodegroup",
+		arg("cluster", clusterName),
+		arg("region", region),
+		arg("name", name),
+		arg("kubernetes-version", version),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke eksctl upgrade nodegroup")
+	}
+
+	return nil
+}
+
+// GetNodeGroups invokes eksctl get nodegroup and returns the node groups of
+// the cluster.
+func (c *Cmd) GetNodeGroups(clusterName, region string) ([]NodeGroupSummary, error) {
+	stdout, _, err := c.run(
+		"get", "nodegroup",
+		arg("cluster", clusterName),
+		arg("region", region),
+		arg("output", "json"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to invoke eksctl get nodegroup")
+	}
+
+	var nodeGroups []NodeGroupSummary
+	err = json.Unmarshal(stdout, &nodeGroups)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal eksctl get nodegroup output")
+	}
+
+	return nodeGroups, nil
+}
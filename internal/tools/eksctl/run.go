@@ -0,0 +1,52 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package eksctl
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mattermost/mattermost-cloud/internal/tools/exechelper"
+	log "github.com/sirupsen/logrus"
+)
+
+func arg(key string, values ...string) string {
+	value := strings.Join(values, "")
+
+	if strings.HasPrefix(key, "--") {
+		return fmt.Sprintf("%s=%s", key, value)
+	}
+
+	return fmt.Sprintf("--%s=%s", key, value)
+}
+
+func outputLogger(line string, logger log.FieldLogger) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	// eksctl prefixes every line with a symbol describing its level.
+	switch {
+	case strings.HasPrefix(line, "[!]"):
+		logger.Warnf("[eksctl] %s", strings.TrimSpace(strings.TrimPrefix(line, "[!]")))
+	case strings.HasPrefix(line, "[✖]"):
+		logger.Errorf("[eksctl] %s", strings.TrimSpace(strings.TrimPrefix(line, "[✖]")))
+	default:
+		logger.Infof("[eksctl] %s", line)
+	}
+}
+
+func (c *Cmd) run(arg ...string) ([]byte, []byte, error) {
+	cmd := exec.Command(c.eksctlPath, arg...)
+	cmd.Env = append(
+		os.Environ(),
+		fmt.Sprintf("KUBECONFIG=%s", c.GetKubeConfigPath()),
+	)
+
+	return exechelper.Run(cmd, c.logger, outputLogger)
+}
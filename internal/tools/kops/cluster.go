@@ -104,6 +104,24 @@ func (c *Cmd) RollingUpdateCluster(name string) error {
 	return nil
 }
 
+// RollingUpdateInstanceGroups invokes kops rolling-update cluster, using the
+// context of the created Cmd, limited to the given instance groups.
+func (c *Cmd) RollingUpdateInstanceGroups(name string, igNames []string) error {
+	_, _, err := c.run(
+		"rolling-update",
+		"cluster",
+		arg("name", name),
+		arg("state", "s3://", c.s3StateStore),
+		commaArg("instance-group", igNames),
+		"--yes",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops rolling-update cluster")
+	}
+
+	return nil
+}
+
 // UpdateCluster invokes kops update cluster, using the context of the created Cmd.
 func (c *Cmd) UpdateCluster(name, dir string) error {
 	_, _, err := c.run(
@@ -122,6 +140,24 @@ func (c *Cmd) UpdateCluster(name, dir string) error {
 	return nil
 }
 
+// PreviewUpdateCluster invokes kops update cluster without applying any
+// changes, using the context of the created Cmd, and returns the stdout
+// describing the changes that would be made.
+func (c *Cmd) PreviewUpdateCluster(name string) (string, error) {
+	stdout, _, err := c.run(
+		"update",
+		"cluster",
+		arg("name", name),
+		arg("state", "s3://", c.s3StateStore),
+	)
+	trimmed := strings.TrimSuffix(string(stdout), "\n")
+	if err != nil {
+		return trimmed, errors.Wrap(err, "failed to invoke kops update cluster")
+	}
+
+	return trimmed, nil
+}
+
 // UpgradeCluster invokes kops upgrade cluster, using the context of the created Cmd.
 func (c *Cmd) UpgradeCluster(name string) error {
 	_, _, err := c.run(
@@ -193,6 +229,40 @@ func (c *Cmd) GetCluster(name string) (string, error) {
 	return trimmed, nil
 }
 
+// GetClusterYAML invokes kops get cluster, using the context of the created
+// Cmd, and returns the YAML stdout.
+func (c *Cmd) GetClusterYAML(name string) (string, error) {
+	stdout, _, err := c.run(
+		"get",
+		"cluster",
+		arg("name", name),
+		arg("state", "s3://", c.s3StateStore),
+		arg("output", "yaml"),
+	)
+	trimmed := strings.TrimSuffix(string(stdout), "\n")
+	if err != nil {
+		return trimmed, errors.Wrap(err, "failed to invoke kops get cluster")
+	}
+
+	return trimmed, nil
+}
+
+// Create invokes kops create, using the context of the created Cmd. The
+// filename passed in is expected to be in the root temp dir of this kops
+// command.
+func (c *Cmd) Create(name string) error {
+	_, _, err := c.run(
+		"create",
+		arg("filename", path.Join(c.GetTempDir(), name)),
+		arg("state", "s3://", c.s3StateStore),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops create")
+	}
+
+	return nil
+}
+
 // Replace invokes kops replace, using the context of the created Cmd, and
 // returns the stdout. The filename passed in is expected to be in the root temp
 // dir of this kops command.
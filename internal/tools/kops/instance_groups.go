@@ -7,12 +7,34 @@ package kops
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"path"
 	"strings"
 
 	"github.com/mattermost/mattermost-cloud/model"
 	"github.com/pkg/errors"
 )
 
+const (
+	// DefaultNodeInstanceGroupName is the name of the worker instance group
+	// created with every kops cluster.
+	DefaultNodeInstanceGroupName = "nodes"
+
+	// UtilityInstanceGroupName is the name of the instance group dedicated to
+	// cluster utilities.
+	UtilityInstanceGroupName = "nodes-utilities"
+
+	// AutoscalerEnabledCloudLabel is the cloud label the cluster-autoscaler
+	// discovers the autoscaling groups it manages with.
+	AutoscalerEnabledCloudLabel = "k8s.io/cluster-autoscaler/enabled"
+)
+
+// autoscalerClusterCloudLabel returns the cloud label restricting the
+// cluster-autoscaler auto-discovery to the autoscaling groups of a cluster.
+func autoscalerClusterCloudLabel(clusterName string) string {
+	return "k8s.io/cluster-autoscaler/" + clusterName
+}
+
 // InstanceGroup is a kops instance group.
 type InstanceGroup struct {
 	Metadata InstanceGroupMetadata `json:"metadata"`
@@ -31,16 +53,62 @@ type InstanceGroupSpec struct {
 	MachineType string `json:"machineType"`
 	MinSize     int64  `json:"minSize"`
 	MaxSize     int64  `json:"maxSize"`
+
+	CloudLabels          map[string]string         `json:"cloudLabels,omitempty"`
+	MixedInstancesPolicy *MixedInstancesPolicySpec `json:"mixedInstancesPolicy,omitempty"`
+}
+
+// MixedInstancesPolicySpec is the mixed instances policy of a kops instance
+// group.
+type MixedInstancesPolicySpec struct {
+	Instances              []string `json:"instances,omitempty"`
+	OnDemandBase           *int64   `json:"onDemandBase,omitempty"`
+	OnDemandAboveBase      *int64   `json:"onDemandAboveBase,omitempty"`
+	SpotAllocationStrategy string   `json:"spotAllocationStrategy,omitempty"`
+}
+
+// newMixedInstancesPolicySpec converts a model.MixedInstancesPolicy into the
+// kops representation. Kops defines the percentage of on-demand instances
+// above the base instead of the spot percentage.
+func newMixedInstancesPolicySpec(policy *model.MixedInstancesPolicy) *MixedInstancesPolicySpec {
+	onDemandBase := policy.OnDemandBase
+	onDemandAboveBase := 100 - policy.SpotPercentage
+
+	return &MixedInstancesPolicySpec{
+		Instances:              policy.Instances,
+		OnDemandBase:           &onDemandBase,
+		OnDemandAboveBase:      &onDemandAboveBase,
+		SpotAllocationStrategy: model.DefaultSpotAllocationStrategy,
+	}
+}
+
+// ToMixedInstancesPolicy converts the kops mixed instances policy into a
+// model.MixedInstancesPolicy.
+func (s *MixedInstancesPolicySpec) ToMixedInstancesPolicy() *model.MixedInstancesPolicy {
+	policy := &model.MixedInstancesPolicy{
+		Instances:      s.Instances,
+		SpotPercentage: 100,
+	}
+	if s.OnDemandBase != nil {
+		policy.OnDemandBase = *s.OnDemandBase
+	}
+	if s.OnDemandAboveBase != nil {
+		policy.SpotPercentage = 100 - *s.OnDemandAboveBase
+	}
+
+	return policy
 }
 
 // UpdateMetadata updates KopsMetadata with the current values from kops state
 // store. This can be a bit tricky. We are attempting to correlate multiple kops
 // instance groups into a simplified set of metadata information. To do so, we
 // assume and check the following:
-// - There is one worker node instance group.
+// - There is one default worker node instance group named "nodes".
+// - Any other worker node instance group is an additional node group.
 // - There is one or more master instance groups.
 // - All of the cluster hosts are running the same AMI.
 // - All of the master nodes are running the same instance type.
+// - Worker instance groups running spot instances can be interrupted.
 // Note:
 // If any violations are found, we don't return an error as that is beyond the
 // scope of updating the metadata. Instead, warnings for each violation are
@@ -53,6 +121,9 @@ func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
 
 	var masterIGCount, NodeIGCount, nodeMinCount, nodeMaxCount int64
 	var masterMachineType, nodeInstanceType, AMI string
+	var nodeGroups []*model.KopsNodeGroup
+	var nodeMixedInstancesPolicy *model.MixedInstancesPolicy
+	var autoscaling bool
 	for _, ig := range instanceGroups {
 		switch ig.Spec.Role {
 		case "Master":
@@ -74,8 +145,7 @@ func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
 
 			masterIGCount++
 		case "Node":
-			// TODO: temp fix while we dont support multiple igs
-			if ig.Metadata.Name == "nodes-utilities" {
+			if ig.Metadata.Name == UtilityInstanceGroupName {
 				c.logger.Debug("Skipping utility group")
 				continue
 			}
@@ -88,10 +158,29 @@ func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
 				c.logger.WithField("kops-metadata-error", warning).Warn("Encountered a kops metadata validation error")
 			}
 
+			if ig.Metadata.Name != DefaultNodeInstanceGroupName {
+				nodeGroups = append(nodeGroups, &model.KopsNodeGroup{
+					Name:         ig.Metadata.Name,
+					InstanceType: ig.Spec.MachineType,
+					MinCount:     ig.Spec.MinSize,
+					MaxCount:     ig.Spec.MaxSize,
+				})
+				continue
+			}
+
 			NodeIGCount++
 			nodeInstanceType = ig.Spec.MachineType
 			nodeMinCount = ig.Spec.MinSize
 			nodeMaxCount = ig.Spec.MaxSize
+			_, autoscaling = ig.Spec.CloudLabels[AutoscalerEnabledCloudLabel]
+			nodeMixedInstancesPolicy = nil
+			if ig.Spec.MixedInstancesPolicy != nil {
+				nodeMixedInstancesPolicy = ig.Spec.MixedInstancesPolicy.ToMixedInstancesPolicy()
+				for _, warning := range mixedInstancesPolicyWarnings(ig.Metadata.Name, nodeMixedInstancesPolicy) {
+					metadata.AddWarning(warning)
+					c.logger.WithField("kops-metadata-warning", warning).Warn("Instance group is running spot instances")
+				}
+			}
 		default:
 			warning := fmt.Sprintf("Instance group %s has unknown role %s", ig.Metadata.Name, ig.Spec.Role)
 			metadata.AddWarning(warning)
@@ -105,7 +194,7 @@ func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
 		c.logger.WithField("kops-metadata-error", warning).Warn("Encountered a kops metadata validation error")
 	}
 	if NodeIGCount != 1 {
-		warning := fmt.Sprintf("expected exactly 1 node instance group, but found %d", NodeIGCount)
+		warning := fmt.Sprintf("expected exactly 1 %s instance group, but found %d", DefaultNodeInstanceGroupName, NodeIGCount)
 		metadata.AddWarning(warning)
 		c.logger.WithField("kops-metadata-error", warning).Warn("Encountered a kops metadata validation error")
 	}
@@ -116,10 +205,32 @@ func (c *Cmd) UpdateMetadata(metadata *model.KopsMetadata) error {
 	metadata.NodeInstanceType = nodeInstanceType
 	metadata.NodeMinCount = nodeMinCount
 	metadata.NodeMaxCount = nodeMaxCount
+	metadata.NodeMixedInstancesPolicy = nodeMixedInstancesPolicy
+	metadata.NodeGroups = nodeGroups
+	metadata.Autoscaling = autoscaling
 
 	return nil
 }
 
+// mixedInstancesPolicyWarnings returns the warnings for an instance group
+// running spot instances. Spot instances can be reclaimed by AWS with a two
+// minute notice. The aws-node-termination-handler utility drains the nodes
+// on notice, but workloads on the instance group are still interrupted.
+func mixedInstancesPolicyWarnings(igName string, policy *model.MixedInstancesPolicy) []string {
+	if !policy.HasSpot() {
+		return nil
+	}
+
+	warnings := []string{
+		fmt.Sprintf("Instance group %s runs %d%% spot instances above an on-demand base of %d; nodes may be interrupted with a two minute notice and are drained by %s", igName, policy.SpotPercentage, policy.OnDemandBase, model.NodeTerminationHandlerCanonicalName),
+	}
+	if len(policy.Instances) < 2 {
+		warnings = append(warnings, fmt.Sprintf("Instance group %s runs spot instances of a single instance type; spot interruptions are more likely without additional instance types", igName))
+	}
+
+	return warnings
+}
+
 // GetInstanceGroupsJSON invokes kops get instancegroup, using the context of the
 // created Cmd, and returns the unmarshaled response as []InstanceGroup.
 func (c *Cmd) GetInstanceGroupsJSON(clusterName string) ([]InstanceGroup, error) {
@@ -172,3 +283,291 @@ func (c *Cmd) GetInstanceGroupYAML(clusterName, igName string) (string, error) {
 
 	return trimmed, nil
 }
+
+// CreateNodeInstanceGroup invokes kops create, using the context of the
+// created Cmd, to add a worker instance group for the given node group. The
+// new instance group is a copy of the template instance group with the machine
+// type and size of the node group.
+func (c *Cmd) CreateNodeInstanceGroup(clusterName, templateIGName string, nodeGroup *model.KopsNodeGroup) error {
+	stdout, _, err := c.run(
+		"get",
+		"instancegroup",
+		arg("name", clusterName),
+		arg("state", "s3://", c.s3StateStore),
+		templateIGName,
+		arg("output", "json"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops get instancegroup")
+	}
+
+	manifest, err := newNodeInstanceGroupManifest(stdout, clusterName, nodeGroup)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate instance group %s manifest", nodeGroup.Name)
+	}
+
+	filename := fmt.Sprintf("ig-%s.json", nodeGroup.Name)
+	err = ioutil.WriteFile(path.Join(c.GetTempDir(), filename), manifest, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write instance group manifest")
+	}
+
+	err = c.Create(filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create instance group %s", nodeGroup.Name)
+	}
+
+	return nil
+}
+
+// SetInstanceGroupMixedInstancesPolicy invokes kops replace, using the context
+// of the created Cmd, to set the mixed instances policy of an instance group.
+// The machine type of the instance group is set to the first instance type of
+// the policy. A policy without instance types removes the existing policy.
+func (c *Cmd) SetInstanceGroupMixedInstancesPolicy(clusterName, igName string, policy *model.MixedInstancesPolicy) error {
+	stdout, _, err := c.run(
+		"get",
+		"instancegroup",
+		arg("name", clusterName),
+		arg("state", "s3://", c.s3StateStore),
+		igName,
+		arg("output", "json"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops get instancegroup")
+	}
+
+	manifest, err := setMixedInstancesPolicyManifest(stdout, policy)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update instance group %s manifest", igName)
+	}
+
+	filename := fmt.Sprintf("ig-%s.json", igName)
+	err = ioutil.WriteFile(path.Join(c.GetTempDir(), filename), manifest, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write instance group manifest")
+	}
+
+	_, err = c.Replace(filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to replace instance group %s", igName)
+	}
+
+	return nil
+}
+
+// SetInstanceGroupMaxSurge invokes kops replace, using the context of the
+// created Cmd, to set the number of extra instances the instance group can
+// launch during a rolling update.
+func (c *Cmd) SetInstanceGroupMaxSurge(clusterName, igName string, maxSurge int64) error {
+	stdout, _, err := c.run(
+		"get",
+		"instancegroup",
+		arg("name", clusterName),
+		arg("state", "s3://", c.s3StateStore),
+		igName,
+		arg("output", "json"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops get instancegroup")
+	}
+
+	manifest, err := setMaxSurgeManifest(stdout, maxSurge)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update instance group %s manifest", igName)
+	}
+
+	filename := fmt.Sprintf("ig-%s.json", igName)
+	err = ioutil.WriteFile(path.Join(c.GetTempDir(), filename), manifest, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write instance group manifest")
+	}
+
+	_, err = c.Replace(filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to replace instance group %s", igName)
+	}
+
+	return nil
+}
+
+// SetInstanceGroupAutoscaling invokes kops replace, using the context of the
+// created Cmd, to tag an instance group for the cluster-autoscaler
+// auto-discovery and to let it scale up to the given max size.
+func (c *Cmd) SetInstanceGroupAutoscaling(clusterName, igName string, maxSize int64) error {
+	stdout, _, err := c.run(
+		"get",
+		"instancegroup",
+		arg("name", clusterName),
+		arg("state", "s3://", c.s3StateStore),
+		igName,
+		arg("output", "json"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops get instancegroup")
+	}
+
+	manifest, err := setAutoscalingManifest(stdout, clusterName, maxSize)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update instance group %s manifest", igName)
+	}
+
+	filename := fmt.Sprintf("ig-%s.json", igName)
+	err = ioutil.WriteFile(path.Join(c.GetTempDir(), filename), manifest, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write instance group manifest")
+	}
+
+	_, err = c.Replace(filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to replace instance group %s", igName)
+	}
+
+	return nil
+}
+
+// DeleteInstanceGroup invokes kops delete instancegroup, using the context of
+// the created Cmd.
+func (c *Cmd) DeleteInstanceGroup(clusterName, igName string) error {
+	_, _, err := c.run(
+		"delete",
+		"instancegroup",
+		arg("name", clusterName),
+		arg("state", "s3://", c.s3StateStore),
+		igName,
+		"--yes",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to invoke kops delete instancegroup")
+	}
+
+	return nil
+}
+
+// newNodeInstanceGroupManifest builds the JSON manifest of a new instance
+// group from the JSON manifest of an existing instance group. The
+// cluster-autoscaler cloud labels of the template are not copied.
+func newNodeInstanceGroupManifest(templateManifest []byte, clusterName string, nodeGroup *model.KopsNodeGroup) ([]byte, error) {
+	var manifest map[string]interface{}
+	err := json.Unmarshal(templateManifest, &manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal template instance group")
+	}
+
+	metadata, ok := manifest["metadata"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("template instance group has no metadata")
+	}
+	spec, ok := manifest["spec"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("template instance group has no spec")
+	}
+
+	manifest["metadata"] = map[string]interface{}{
+		"name":   nodeGroup.Name,
+		"labels": metadata["labels"],
+	}
+	spec["role"] = "Node"
+	spec["machineType"] = nodeGroup.InstanceType
+	spec["minSize"] = nodeGroup.MinCount
+	spec["maxSize"] = nodeGroup.MaxCount
+	// The instance group label is how installations are scheduled onto the
+	// nodes of the group, so it must not be inherited from the template.
+	spec["nodeLabels"] = map[string]interface{}{
+		model.KopsInstanceGroupLabel: nodeGroup.Name,
+	}
+	delete(spec, "taints")
+	delete(spec, "mixedInstancesPolicy")
+	if cloudLabels, ok := spec["cloudLabels"].(map[string]interface{}); ok {
+		delete(cloudLabels, AutoscalerEnabledCloudLabel)
+		delete(cloudLabels, autoscalerClusterCloudLabel(clusterName))
+	}
+
+	return json.Marshal(manifest)
+}
+
+// setMixedInstancesPolicyManifest sets or removes the mixed instances policy
+// in the JSON manifest of an existing instance group.
+func setMixedInstancesPolicyManifest(igManifest []byte, policy *model.MixedInstancesPolicy) ([]byte, error) {
+	var manifest map[string]interface{}
+	err := json.Unmarshal(igManifest, &manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal instance group")
+	}
+
+	spec, ok := manifest["spec"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("instance group has no spec")
+	}
+
+	if policy == nil || policy.IsRemoval() {
+		delete(spec, "mixedInstancesPolicy")
+		return json.Marshal(manifest)
+	}
+
+	spec["machineType"] = policy.Instances[0]
+	spec["mixedInstancesPolicy"] = newMixedInstancesPolicySpec(policy)
+
+	return json.Marshal(manifest)
+}
+
+// setMaxSurgeManifest sets the rolling update max surge in the JSON manifest
+// of an existing instance group. A max surge of 0 removes the setting.
+func setMaxSurgeManifest(igManifest []byte, maxSurge int64) ([]byte, error) {
+	var manifest map[string]interface{}
+	err := json.Unmarshal(igManifest, &manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal instance group")
+	}
+
+	spec, ok := manifest["spec"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("instance group has no spec")
+	}
+
+	rollingUpdate, ok := spec["rollingUpdate"].(map[string]interface{})
+	if !ok {
+		rollingUpdate = make(map[string]interface{})
+	}
+	if maxSurge == 0 {
+		delete(rollingUpdate, "maxSurge")
+	} else {
+		rollingUpdate["maxSurge"] = maxSurge
+	}
+
+	if len(rollingUpdate) == 0 {
+		delete(spec, "rollingUpdate")
+	} else {
+		spec["rollingUpdate"] = rollingUpdate
+	}
+
+	return json.Marshal(manifest)
+}
+
+// setAutoscalingManifest adds the cluster-autoscaler auto-discovery cloud
+// labels and sets the max size in the JSON manifest of an existing instance
+// group.
+func setAutoscalingManifest(igManifest []byte, clusterName string, maxSize int64) ([]byte, error) {
+	var manifest map[string]interface{}
+	err := json.Unmarshal(igManifest, &manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal instance group")
+	}
+
+	spec, ok := manifest["spec"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("instance group has no spec")
+	}
+
+	cloudLabels, ok := spec["cloudLabels"].(map[string]interface{})
+	if !ok {
+		cloudLabels = make(map[string]interface{})
+	}
+	cloudLabels[AutoscalerEnabledCloudLabel] = "true"
+	cloudLabels[autoscalerClusterCloudLabel(clusterName)] = "owned"
+
+	spec["cloudLabels"] = cloudLabels
+	spec["maxSize"] = maxSize
+
+	return json.Marshal(manifest)
+}
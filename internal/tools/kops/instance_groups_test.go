@@ -0,0 +1,241 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package kops
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNodeInstanceGroupManifest(t *testing.T) {
+	template := []byte(`{
+		"apiVersion": "kops.k8s.io/v1alpha2",
+		"kind": "InstanceGroup",
+		"metadata": {
+			"creationTimestamp": "2020-01-01T00:00:00Z",
+			"labels": {"kops.k8s.io/cluster": "test-kops.k8s.local"},
+			"name": "nodes"
+		},
+		"spec": {
+			"image": "kope.io/k8s-1.15-debian-stretch-amd64-hvm-ebs-2020-01-17",
+			"machineType": "m5.large",
+			"maxSize": 2,
+			"minSize": 2,
+			"nodeLabels": {"kops.k8s.io/instancegroup": "nodes"},
+			"cloudLabels": {
+				"team": "cloud",
+				"k8s.io/cluster-autoscaler/enabled": "true",
+				"k8s.io/cluster-autoscaler/test-kops.k8s.local": "owned"
+			},
+			"role": "Node",
+			"subnets": ["us-east-1a"]
+		}
+	}`)
+
+	manifest, err := newNodeInstanceGroupManifest(template, "test-kops.k8s.local", &model.KopsNodeGroup{
+		Name:         "memory",
+		InstanceType: "r5.xlarge",
+		MinCount:     1,
+		MaxCount:     3,
+	})
+	require.NoError(t, err)
+
+	var instanceGroup struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name              string            `json:"name"`
+			Labels            map[string]string `json:"labels"`
+			CreationTimestamp string            `json:"creationTimestamp"`
+		} `json:"metadata"`
+		Spec struct {
+			InstanceGroupSpec
+			NodeLabels  map[string]string `json:"nodeLabels"`
+			CloudLabels map[string]string `json:"cloudLabels"`
+			Subnets     []string          `json:"subnets"`
+		} `json:"spec"`
+	}
+	err = json.Unmarshal(manifest, &instanceGroup)
+	require.NoError(t, err)
+
+	assert.Equal(t, "kops.k8s.io/v1alpha2", instanceGroup.APIVersion)
+	assert.Equal(t, "InstanceGroup", instanceGroup.Kind)
+	assert.Equal(t, "memory", instanceGroup.Metadata.Name)
+	assert.Empty(t, instanceGroup.Metadata.CreationTimestamp)
+	assert.Equal(t, map[string]string{"kops.k8s.io/cluster": "test-kops.k8s.local"}, instanceGroup.Metadata.Labels)
+	assert.Equal(t, "Node", instanceGroup.Spec.Role)
+	assert.Equal(t, "kope.io/k8s-1.15-debian-stretch-amd64-hvm-ebs-2020-01-17", instanceGroup.Spec.Image)
+	assert.Equal(t, "r5.xlarge", instanceGroup.Spec.MachineType)
+	assert.Equal(t, int64(1), instanceGroup.Spec.MinSize)
+	assert.Equal(t, int64(3), instanceGroup.Spec.MaxSize)
+	assert.Equal(t, map[string]string{"kops.k8s.io/instancegroup": "memory"}, instanceGroup.Spec.NodeLabels)
+	assert.Equal(t, map[string]string{"team": "cloud"}, instanceGroup.Spec.CloudLabels)
+	assert.Equal(t, []string{"us-east-1a"}, instanceGroup.Spec.Subnets)
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := newNodeInstanceGroupManifest([]byte(`{"kind": "InstanceGroup"}`), "test-kops.k8s.local", &model.KopsNodeGroup{Name: "memory"})
+		require.Error(t, err)
+	})
+}
+
+func TestSetMixedInstancesPolicyManifest(t *testing.T) {
+	igManifest := []byte(`{
+		"apiVersion": "kops.k8s.io/v1alpha2",
+		"kind": "InstanceGroup",
+		"metadata": {"name": "nodes"},
+		"spec": {
+			"image": "kope.io/k8s-1.15-debian-stretch-amd64-hvm-ebs-2020-01-17",
+			"machineType": "m5.large",
+			"maxSize": 2,
+			"minSize": 2,
+			"role": "Node",
+			"subnets": ["us-east-1a"]
+		}
+	}`)
+
+	var instanceGroup struct {
+		Spec struct {
+			InstanceGroupSpec
+			Subnets []string `json:"subnets"`
+		} `json:"spec"`
+	}
+
+	policy := &model.MixedInstancesPolicy{
+		Instances:      []string{"t3.large", "t3a.large"},
+		OnDemandBase:   1,
+		SpotPercentage: 75,
+	}
+	manifest, err := setMixedInstancesPolicyManifest(igManifest, policy)
+	require.NoError(t, err)
+	err = json.Unmarshal(manifest, &instanceGroup)
+	require.NoError(t, err)
+
+	assert.Equal(t, "t3.large", instanceGroup.Spec.MachineType)
+	assert.Equal(t, []string{"us-east-1a"}, instanceGroup.Spec.Subnets)
+	require.NotNil(t, instanceGroup.Spec.MixedInstancesPolicy)
+	assert.Equal(t, []string{"t3.large", "t3a.large"}, instanceGroup.Spec.MixedInstancesPolicy.Instances)
+	assert.Equal(t, int64(1), *instanceGroup.Spec.MixedInstancesPolicy.OnDemandBase)
+	assert.Equal(t, int64(25), *instanceGroup.Spec.MixedInstancesPolicy.OnDemandAboveBase)
+	assert.Equal(t, model.DefaultSpotAllocationStrategy, instanceGroup.Spec.MixedInstancesPolicy.SpotAllocationStrategy)
+	assert.True(t, policy.Equal(instanceGroup.Spec.MixedInstancesPolicy.ToMixedInstancesPolicy()))
+
+	t.Run("remove policy", func(t *testing.T) {
+		removed, err := setMixedInstancesPolicyManifest(manifest, &model.MixedInstancesPolicy{})
+		require.NoError(t, err)
+
+		instanceGroup.Spec.MixedInstancesPolicy = nil
+		err = json.Unmarshal(removed, &instanceGroup)
+		require.NoError(t, err)
+		assert.Nil(t, instanceGroup.Spec.MixedInstancesPolicy)
+		assert.Equal(t, "t3.large", instanceGroup.Spec.MachineType)
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		_, err := setMixedInstancesPolicyManifest([]byte(`{"kind": "InstanceGroup"}`), policy)
+		require.Error(t, err)
+	})
+}
+
+func TestMixedInstancesPolicyWarnings(t *testing.T) {
+	t.Run("on-demand only", func(t *testing.T) {
+		warnings := mixedInstancesPolicyWarnings("nodes", &model.MixedInstancesPolicy{
+			Instances: []string{"t3.large", "t3a.large"},
+		})
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("spot", func(t *testing.T) {
+		warnings := mixedInstancesPolicyWarnings("nodes", &model.MixedInstancesPolicy{
+			Instances:      []string{"t3.large", "t3a.large"},
+			SpotPercentage: 100,
+		})
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("spot with single instance type", func(t *testing.T) {
+		warnings := mixedInstancesPolicyWarnings("nodes", &model.MixedInstancesPolicy{
+			Instances:      []string{"t3.large"},
+			SpotPercentage: 50,
+		})
+		assert.Len(t, warnings, 2)
+	})
+}
+
+func TestSetMaxSurgeManifest(t *testing.T) {
+	igManifest := []byte(`{
+		"metadata": {"name": "nodes"},
+		"spec": {
+			"machineType": "m5.large",
+			"rollingUpdate": {"maxUnavailable": 1},
+			"role": "Node"
+		}
+	}`)
+
+	var instanceGroup struct {
+		Spec struct {
+			MachineType   string                 `json:"machineType"`
+			RollingUpdate map[string]interface{} `json:"rollingUpdate"`
+		} `json:"spec"`
+	}
+
+	manifest, err := setMaxSurgeManifest(igManifest, 2)
+	require.NoError(t, err)
+	err = json.Unmarshal(manifest, &instanceGroup)
+	require.NoError(t, err)
+	assert.Equal(t, "m5.large", instanceGroup.Spec.MachineType)
+	assert.Equal(t, map[string]interface{}{"maxSurge": float64(2), "maxUnavailable": float64(1)}, instanceGroup.Spec.RollingUpdate)
+
+	t.Run("remove max surge", func(t *testing.T) {
+		manifest, err = setMaxSurgeManifest(manifest, 0)
+		require.NoError(t, err)
+
+		instanceGroup.Spec.RollingUpdate = nil
+		err = json.Unmarshal(manifest, &instanceGroup)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"maxUnavailable": float64(1)}, instanceGroup.Spec.RollingUpdate)
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		_, err := setMaxSurgeManifest([]byte(`{"kind": "InstanceGroup"}`), 1)
+		require.Error(t, err)
+	})
+}
+
+func TestSetAutoscalingManifest(t *testing.T) {
+	igManifest := []byte(`{
+		"metadata": {"name": "nodes"},
+		"spec": {
+			"cloudLabels": {"team": "cloud"},
+			"machineType": "m5.large",
+			"maxSize": 2,
+			"minSize": 2,
+			"role": "Node"
+		}
+	}`)
+
+	manifest, err := setAutoscalingManifest(igManifest, "test-kops.k8s.local", 5)
+	require.NoError(t, err)
+
+	var instanceGroup InstanceGroup
+	err = json.Unmarshal(manifest, &instanceGroup)
+	require.NoError(t, err)
+	assert.Equal(t, "m5.large", instanceGroup.Spec.MachineType)
+	assert.Equal(t, int64(2), instanceGroup.Spec.MinSize)
+	assert.Equal(t, int64(5), instanceGroup.Spec.MaxSize)
+	assert.Equal(t, map[string]string{
+		"team":                              "cloud",
+		"k8s.io/cluster-autoscaler/enabled": "true",
+		"k8s.io/cluster-autoscaler/test-kops.k8s.local": "owned",
+	}, instanceGroup.Spec.CloudLabels)
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		_, err := setAutoscalingManifest([]byte(`{"kind": "InstanceGroup"}`), "test-kops.k8s.local", 5)
+		require.Error(t, err)
+	})
+}
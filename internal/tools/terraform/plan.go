@@ -61,6 +61,21 @@ func (c *Cmd) Plan() error {
 	return nil
 }
 
+// PlanOutput invokes terraform plan without locking the remote state and
+// returns the stdout describing the changes that would be made.
+func (c *Cmd) PlanOutput() (string, error) {
+	stdout, _, err := c.run(
+		"plan",
+		arg("input", "false"),
+		arg("lock", "false"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to invoke terraform plan")
+	}
+
+	return strings.TrimSuffix(string(stdout), "\n"), nil
+}
+
 // Apply invokes terraform apply.
 func (c *Cmd) Apply() error {
 	_, _, err := c.run(
@@ -0,0 +1,285 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package terraform
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// vpcModuleFilename is the terraform configuration file of the cluster VPC
+	// module.
+	vpcModuleFilename = "vpc.tf"
+	// DefaultVPCCIDR is the CIDR block of the VPCs created for clusters.
+	DefaultVPCCIDR = "10.0.0.0/16"
+)
+
+// VPCModule is the configuration of a VPC created for a single cluster. The
+// resources are tagged the same way as the VPCs of the pre-tagged pool so
+// that they can be found by the AWS tooling.
+type VPCModule struct {
+	ClusterID     string
+	KopsName      string
+	Owner         string
+	Region        string
+	AssumeRoleARN string
+	CIDR          string
+	Zones         []string
+}
+
+// Validate returns an error if the VPC module configuration is incomplete.
+func (m *VPCModule) Validate() error {
+	if len(m.ClusterID) == 0 {
+		return errors.New("cluster ID must not be empty")
+	}
+	if len(m.KopsName) == 0 {
+		return errors.New("kops name must not be empty")
+	}
+	if len(m.Region) == 0 {
+		return errors.New("region must not be empty")
+	}
+	if len(m.CIDR) == 0 {
+		return errors.New("CIDR must not be empty")
+	}
+	if len(m.Zones) == 0 {
+		return errors.New("at least one zone is required")
+	}
+
+	return nil
+}
+
+// RemoteKey returns the key of the terraform remote state of the VPC.
+func (m *VPCModule) RemoteKey() string {
+	return m.ClusterID + "-vpc"
+}
+
+// WriteVPCModule writes the terraform configuration of the given VPC module
+// to the working directory of the terraform command.
+func (c *Cmd) WriteVPCModule(module *VPCModule) error {
+	err := module.Validate()
+	if err != nil {
+		return errors.Wrap(err, "invalid VPC module")
+	}
+
+	var buffer bytes.Buffer
+	err = vpcModuleTemplate.Execute(&buffer, module)
+	if err != nil {
+		return errors.Wrap(err, "failed to render VPC module")
+	}
+
+	err = ioutil.WriteFile(path.Join(c.dir, vpcModuleFilename), buffer.Bytes(), 0644)
+	if err != nil {
+		return errors.Wrap(err, "unable to write VPC module file")
+	}
+
+	return nil
+}
+
+var vpcModuleTemplate = template.Must(template.New("vpc").Parse(`
+provider "aws" {
+  region = "{{.Region}}"
+{{- if .AssumeRoleARN}}
+
+  assume_role {
+    role_arn = "{{.AssumeRoleARN}}"
+  }
+{{- end}}
+}
+
+locals {
+  zones = [{{range $i, $zone := .Zones}}{{if $i}}, {{end}}"{{$zone}}"{{end}}]
+  tags = {
+    Name              = "cloud-{{.ClusterID}}"
+    CloudClusterOwner = "{{.Owner}}"
+  }
+}
+
+resource "aws_vpc" "cluster" {
+  cidr_block           = "{{.CIDR}}"
+  enable_dns_hostnames = true
+  enable_dns_support   = true
+
+  tags = merge(local.tags, {
+    Available       = "false"
+    CloudClusterID  = "{{.ClusterID}}"
+    CloudManagedVPC = "true"
+  })
+}
+
+resource "aws_internet_gateway" "cluster" {
+  vpc_id = aws_vpc.cluster.id
+  tags   = local.tags
+}
+
+resource "aws_subnet" "public" {
+  count                   = length(local.zones)
+  vpc_id                  = aws_vpc.cluster.id
+  availability_zone       = local.zones[count.index]
+  cidr_block              = cidrsubnet(aws_vpc.cluster.cidr_block, 4, count.index)
+  map_public_ip_on_launch = true
+
+  tags = merge(local.tags, {
+    SubnetType                            = "public"
+    "kubernetes.io/cluster/{{.KopsName}}" = "shared"
+    "kubernetes.io/role/elb"              = "1"
+  })
+}
+
+resource "aws_subnet" "private" {
+  count             = length(local.zones)
+  vpc_id            = aws_vpc.cluster.id
+  availability_zone = local.zones[count.index]
+  cidr_block        = cidrsubnet(aws_vpc.cluster.cidr_block, 4, count.index + 8)
+
+  tags = merge(local.tags, {
+    SubnetType                        = "private"
+    "kubernetes.io/role/internal-elb" = "1"
+  })
+}
+
+resource "aws_route_table" "public" {
+  vpc_id = aws_vpc.cluster.id
+  tags   = local.tags
+
+  route {
+    cidr_block = "0.0.0.0/0"
+    gateway_id = aws_internet_gateway.cluster.id
+  }
+}
+
+resource "aws_route_table_association" "public" {
+  count          = length(local.zones)
+  subnet_id      = aws_subnet.public[count.index].id
+  route_table_id = aws_route_table.public.id
+}
+
+resource "aws_eip" "nat" {
+  count = length(local.zones)
+  vpc   = true
+  tags  = local.tags
+}
+
+resource "aws_nat_gateway" "cluster" {
+  count         = length(local.zones)
+  allocation_id = aws_eip.nat[count.index].id
+  subnet_id     = aws_subnet.public[count.index].id
+  tags          = local.tags
+
+  depends_on = [aws_internet_gateway.cluster]
+}
+
+resource "aws_route_table" "private" {
+  count  = length(local.zones)
+  vpc_id = aws_vpc.cluster.id
+  tags   = local.tags
+
+  route {
+    cidr_block     = "0.0.0.0/0"
+    nat_gateway_id = aws_nat_gateway.cluster[count.index].id
+  }
+}
+
+resource "aws_route_table_association" "private" {
+  count          = length(local.zones)
+  subnet_id      = aws_subnet.private[count.index].id
+  route_table_id = aws_route_table.private[count.index].id
+}
+
+resource "aws_security_group" "master" {
+  name   = "cloud-{{.ClusterID}}-master"
+  vpc_id = aws_vpc.cluster.id
+
+  tags = merge(local.tags, {
+    NodeType = "master"
+  })
+
+  ingress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = [aws_vpc.cluster.cidr_block]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_security_group" "worker" {
+  name   = "cloud-{{.ClusterID}}-worker"
+  vpc_id = aws_vpc.cluster.id
+
+  tags = merge(local.tags, {
+    NodeType = "worker"
+  })
+
+  ingress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = [aws_vpc.cluster.cidr_block]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_security_group" "db_mysql" {
+  name   = "cloud-{{.ClusterID}}-db-mysql"
+  vpc_id = aws_vpc.cluster.id
+
+  tags = merge(local.tags, {
+    MattermostCloudInstallationDatabase = "MySQL/Aurora"
+  })
+
+  ingress {
+    from_port   = 3306
+    to_port     = 3306
+    protocol    = "tcp"
+    cidr_blocks = [aws_vpc.cluster.cidr_block]
+  }
+}
+
+resource "aws_security_group" "db_postgres" {
+  name   = "cloud-{{.ClusterID}}-db-postgres"
+  vpc_id = aws_vpc.cluster.id
+
+  tags = merge(local.tags, {
+    MattermostCloudInstallationDatabase = "PostgreSQL/Aurora"
+  })
+
+  ingress {
+    from_port   = 5432
+    to_port     = 5432
+    protocol    = "tcp"
+    cidr_blocks = [aws_vpc.cluster.cidr_block]
+  }
+}
+
+resource "aws_db_subnet_group" "cluster" {
+  name       = "mattermost-provisioner-db-${aws_vpc.cluster.id}"
+  subnet_ids = aws_subnet.private[*].id
+
+  tags = merge(local.tags, {
+    MattermostCloudInstallationDatabase = "MySQL/Aurora"
+  })
+}
+
+output "vpc_id" {
+  value = aws_vpc.cluster.id
+}
+`))
@@ -0,0 +1,62 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/internal/testlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteVPCModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vpc-module")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cmd := &Cmd{dir: dir, logger: testlib.MakeLogger(t)}
+	module := &VPCModule{
+		ClusterID: "cluster1",
+		KopsName:  "cluster1-kops.k8s.local",
+		Owner:     "owner",
+		Region:    "eu-central-1",
+		CIDR:      DefaultVPCCIDR,
+		Zones:     []string{"eu-central-1a", "eu-central-1b"},
+	}
+	assert.Equal(t, "cluster1-vpc", module.RemoteKey())
+
+	t.Run("invalid module", func(t *testing.T) {
+		err := cmd.WriteVPCModule(&VPCModule{ClusterID: "cluster1"})
+		require.Error(t, err)
+	})
+
+	t.Run("module", func(t *testing.T) {
+		err := cmd.WriteVPCModule(module)
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadFile(path.Join(dir, vpcModuleFilename))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `region = "eu-central-1"`)
+		assert.Contains(t, string(content), `zones = ["eu-central-1a", "eu-central-1b"]`)
+		assert.Contains(t, string(content), `CloudClusterID  = "cluster1"`)
+		assert.Contains(t, string(content), `CloudManagedVPC = "true"`)
+		assert.Contains(t, string(content), `"kubernetes.io/cluster/cluster1-kops.k8s.local" = "shared"`)
+		assert.NotContains(t, string(content), "assume_role")
+	})
+
+	t.Run("module with assume role", func(t *testing.T) {
+		module.AssumeRoleARN = "arn:aws:iam::123456789012:role/provisioner"
+		err := cmd.WriteVPCModule(module)
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadFile(path.Join(dir, vpcModuleFilename))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `role_arn = "arn:aws:iam::123456789012:role/provisioner"`)
+	})
+}
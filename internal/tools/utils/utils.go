@@ -13,6 +13,7 @@ import (
 
 	"github.com/mattermost/mattermost-cloud/internal/tools/aws"
 	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
 )
 
 type stop struct {
@@ -95,6 +96,26 @@ func NewResourceUtil(instanceID string, awsClient *aws.Client) *ResourceUtil {
 	}
 }
 
+// ForCluster returns a ResourceUtil that manages the installation resources
+// in the AWS region and account of the given cluster.
+func (r *ResourceUtil) ForCluster(cluster *model.Cluster) (*ResourceUtil, error) {
+	if r.awsClient == nil {
+		return r, nil
+	}
+
+	awsClient, err := r.awsClient.ForRegion(cluster.AWSRegion(), cluster.AWSAssumeRoleARN())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get AWS client for cluster %s", cluster.ID)
+	}
+
+	return NewResourceUtil(r.instanceID, awsClient), nil
+}
+
+// GetAWSClient returns the AWS client used to manage the resources.
+func (r *ResourceUtil) GetAWSClient() *aws.Client {
+	return r.awsClient
+}
+
 // GetFilestore returns the Filestore interface that matches the installation.
 func (r *ResourceUtil) GetFilestore(installation *model.Installation) model.Filestore {
 	switch installation.Filestore {
@@ -106,6 +127,8 @@ func (r *ResourceUtil) GetFilestore(installation *model.Installation) model.File
 		return aws.NewS3MultitenantFilestore(installation.ID, r.awsClient)
 	case model.InstallationFilestoreBifrost:
 		return aws.NewBifrostFilestore(installation.ID, r.awsClient)
+	case model.InstallationFilestoreExternal:
+		return aws.NewExternalFilestore(installation.ID, r.awsClient)
 	}
 
 	// Warning: we should never get here as it would mean that we didn't match
@@ -113,6 +136,12 @@ func (r *ResourceUtil) GetFilestore(installation *model.Installation) model.File
 	return model.NewMinioOperatorFilestore()
 }
 
+// GetFilestoreMigrator returns the FilestoreMigrator interface used to move the
+// installation files between filestores.
+func (r *ResourceUtil) GetFilestoreMigrator(installation *model.Installation) model.FilestoreMigrator {
+	return aws.NewS3FilestoreMigration(installation, r.awsClient)
+}
+
 // GetDatabase returns the Database interface that matches the installation.
 func (r *ResourceUtil) GetDatabase(installation *model.Installation) model.Database {
 	switch installation.Database {
@@ -126,6 +155,8 @@ func (r *ResourceUtil) GetDatabase(installation *model.Installation) model.Datab
 		return aws.NewRDSMultitenantDatabase(model.DatabaseEngineTypeMySQL, r.instanceID, installation.ID, r.awsClient)
 	case model.InstallationDatabaseMultiTenantRDSPostgres:
 		return aws.NewRDSMultitenantDatabase(model.DatabaseEngineTypePostgres, r.instanceID, installation.ID, r.awsClient)
+	case model.InstallationDatabaseExternal:
+		return aws.NewExternalDatabase(installation.ID, r.awsClient)
 	}
 
 	// Warning: we should never get here as it would mean that we didn't match
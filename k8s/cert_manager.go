@@ -0,0 +1,73 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"context"
+
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CertManagerClusterIssuerResource is the resource of the cert-manager
+// ClusterIssuer custom resource.
+var CertManagerClusterIssuerResource = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "clusterissuers",
+}
+
+// CertManagerCertificateResource is the resource of the cert-manager
+// Certificate custom resource.
+var CertManagerCertificateResource = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "certificates",
+}
+
+// CreateOrUpdateClusterIssuer creates or updates the cert-manager
+// ClusterIssuer with the given name and spec.
+func (kc *KubeClient) CreateOrUpdateClusterIssuer(name string, spec map[string]interface{}) (*unstructured.Unstructured, error) {
+	ctx := context.TODO()
+	issuer, err := kc.DynamicClient.Resource(CertManagerClusterIssuerResource).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err != nil && k8sErrors.IsNotFound(err) {
+		issuer = &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "cert-manager.io/v1",
+				"kind":       "ClusterIssuer",
+				"metadata": map[string]interface{}{
+					"name": name,
+				},
+				"spec": spec,
+			},
+		}
+
+		return kc.DynamicClient.Resource(CertManagerClusterIssuerResource).Create(ctx, issuer, metav1.CreateOptions{})
+	}
+
+	issuer.Object["spec"] = spec
+
+	return kc.DynamicClient.Resource(CertManagerClusterIssuerResource).Update(ctx, issuer, metav1.UpdateOptions{})
+}
+
+// GetCertificate returns the cert-manager Certificate with the given name or
+// nil if it doesn't exist.
+func (kc *KubeClient) GetCertificate(namespace, name string) (*unstructured.Unstructured, error) {
+	certificate, err := kc.DynamicClient.Resource(CertManagerCertificateResource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return certificate, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestClusterIssuer(t *testing.T) {
+	testClient := newTestKubeClient()
+
+	t.Run("create issuer", func(t *testing.T) {
+		issuer, err := testClient.CreateOrUpdateClusterIssuer("letsencrypt", map[string]interface{}{
+			"acme": map[string]interface{}{"server": "server1"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "letsencrypt", issuer.GetName())
+	})
+
+	t.Run("update issuer", func(t *testing.T) {
+		_, err := testClient.CreateOrUpdateClusterIssuer("letsencrypt", map[string]interface{}{
+			"acme": map[string]interface{}{"server": "server2"},
+		})
+		require.NoError(t, err)
+
+		issuer, err := testClient.DynamicClient.Resource(CertManagerClusterIssuerResource).Get(context.TODO(), "letsencrypt", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		server, found, err := unstructured.NestedString(issuer.Object, "spec", "acme", "server")
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, "server2", server)
+	})
+}
+
+func TestGetCertificate(t *testing.T) {
+	testClient := newTestKubeClient()
+	namespace := "installation"
+
+	t.Run("missing certificate", func(t *testing.T) {
+		certificate, err := testClient.GetCertificate(namespace, "test-tls-cert")
+		require.NoError(t, err)
+		require.Nil(t, certificate)
+	})
+
+	t.Run("certificate", func(t *testing.T) {
+		_, err := testClient.DynamicClient.Resource(CertManagerCertificateResource).Namespace(namespace).Create(context.TODO(), &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "cert-manager.io/v1",
+				"kind":       "Certificate",
+				"metadata": map[string]interface{}{
+					"name":      "test-tls-cert",
+					"namespace": namespace,
+				},
+			},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		certificate, err := testClient.GetCertificate(namespace, "test-tls-cert")
+		require.NoError(t, err)
+		require.NotNil(t, certificate)
+		require.Equal(t, "test-tls-cert", certificate.GetName())
+	})
+}
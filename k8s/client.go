@@ -9,6 +9,7 @@ import (
 
 	mmclient "github.com/mattermost/mattermost-operator/pkg/client/clientset/versioned"
 	apixclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -22,6 +23,7 @@ type KubeClient struct {
 	ApixClientset       apixclient.Interface
 	MattermostClientset mmclient.Interface
 	KubeagClientSet     kubeagclient.Interface
+	DynamicClient       dynamic.Interface
 	logger              log.FieldLogger
 }
 
@@ -61,12 +63,18 @@ func createKubeClient(config *rest.Config, logger log.FieldLogger) (*KubeClient,
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &KubeClient{
 			config:              config,
 			Clientset:           clientset,
 			MattermostClientset: mattermostClientset,
 			ApixClientset:       apixClientset,
 			KubeagClientSet:     kubeagClientset,
+			DynamicClient:       dynamicClient,
 			logger:              logger,
 		},
 		nil
@@ -9,6 +9,8 @@ import (
 
 	mmfake "github.com/mattermost/mattermost-operator/pkg/client/clientset/versioned/fake"
 	apixfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
 	kubeagfake "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset/fake"
@@ -21,6 +23,7 @@ func newTestKubeClient() *KubeClient {
 		ApixClientset:       apixfake.NewSimpleClientset(),
 		MattermostClientset: mmfake.NewSimpleClientset(),
 		KubeagClientSet:     kubeagfake.NewSimpleClientset(),
+		DynamicClient:       dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
 		logger:              logrus.New(),
 	}
 }
@@ -0,0 +1,39 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"context"
+
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateOrUpdateIngress creates or updates an ingress.
+func (kc *KubeClient) CreateOrUpdateIngress(namespace string, ingress *networkingv1beta1.Ingress) (metav1.Object, error) {
+	ctx := context.TODO()
+	_, err := kc.Clientset.NetworkingV1beta1().Ingresses(namespace).Get(ctx, ingress.GetName(), metav1.GetOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return kc.Clientset.NetworkingV1beta1().Ingresses(namespace).Create(ctx, ingress, metav1.CreateOptions{})
+	}
+
+	return kc.Clientset.NetworkingV1beta1().Ingresses(namespace).Update(ctx, ingress, metav1.UpdateOptions{})
+}
+
+// DeleteIngress deletes an ingress. No error is returned if the ingress
+// doesn't exist.
+func (kc *KubeClient) DeleteIngress(namespace, name string) error {
+	err := kc.Clientset.NetworkingV1beta1().Ingresses(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
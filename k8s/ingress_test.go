@@ -0,0 +1,53 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIngresses(t *testing.T) {
+	testClient := newTestKubeClient()
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ingress"},
+	}
+	namespace := "testing"
+
+	t.Run("create ingress", func(t *testing.T) {
+		result, err := testClient.CreateOrUpdateIngress(namespace, ingress)
+		require.NoError(t, err)
+		require.Equal(t, ingress.GetName(), result.GetName())
+	})
+
+	t.Run("update ingress", func(t *testing.T) {
+		ingress.Spec.Rules = []networkingv1beta1.IngressRule{{Host: "test.example.com"}}
+		result, err := testClient.CreateOrUpdateIngress(namespace, ingress)
+		require.NoError(t, err)
+		require.Equal(t, ingress.GetName(), result.GetName())
+
+		actual, err := testClient.Clientset.NetworkingV1beta1().Ingresses(namespace).Get(context.TODO(), ingress.GetName(), metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "test.example.com", actual.Spec.Rules[0].Host)
+	})
+
+	t.Run("delete ingress", func(t *testing.T) {
+		err := testClient.DeleteIngress(namespace, ingress.GetName())
+		require.NoError(t, err)
+
+		_, err = testClient.Clientset.NetworkingV1beta1().Ingresses(namespace).Get(context.TODO(), ingress.GetName(), metav1.GetOptions{})
+		require.True(t, k8sErrors.IsNotFound(err))
+	})
+
+	t.Run("delete missing ingress", func(t *testing.T) {
+		err := testClient.DeleteIngress(namespace, ingress.GetName())
+		require.NoError(t, err)
+	})
+}
@@ -16,6 +16,22 @@ const (
 	allowMMExternal = "external-mm-allow"
 )
 
+// CreateOrUpdateNetworkPolicy creates or updates a network policy.
+func (kc *KubeClient) CreateOrUpdateNetworkPolicy(namespace string, networkPolicy *networkingv1.NetworkPolicy) (metav1.Object, error) {
+	return kc.createOrUpdateNetworkPolicyV1(namespace, networkPolicy)
+}
+
+// DeleteNetworkPolicy deletes a network policy. No error is returned if the
+// network policy doesn't exist.
+func (kc *KubeClient) DeleteNetworkPolicy(namespace, name string) error {
+	err := kc.Clientset.NetworkingV1().NetworkPolicies(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
 func (kc *KubeClient) createOrUpdateNetworkPolicyV1(namespace string, networkPolicy *networkingv1.NetworkPolicy) (metav1.Object, error) {
 	ctx := context.TODO()
 	_, err := kc.Clientset.NetworkingV1().NetworkPolicies(namespace).Get(ctx, networkPolicy.GetName(), metav1.GetOptions{})
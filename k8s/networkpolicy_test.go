@@ -5,6 +5,7 @@
 package k8s
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -29,4 +30,20 @@ func TestNetworkPolicyV1(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, networkPolicy.GetName(), result.GetName())
 	})
+	t.Run("update exported NetworkPolicy", func(t *testing.T) {
+		result, err := testClient.CreateOrUpdateNetworkPolicy(namespace, networkPolicy)
+		require.NoError(t, err)
+		require.Equal(t, networkPolicy.GetName(), result.GetName())
+	})
+	t.Run("delete NetworkPolicy", func(t *testing.T) {
+		err := testClient.DeleteNetworkPolicy(namespace, networkPolicy.GetName())
+		require.NoError(t, err)
+
+		_, err = testClient.Clientset.NetworkingV1().NetworkPolicies(namespace).Get(context.TODO(), networkPolicy.GetName(), metav1.GetOptions{})
+		require.Error(t, err)
+	})
+	t.Run("delete missing NetworkPolicy", func(t *testing.T) {
+		err := testClient.DeleteNetworkPolicy(namespace, networkPolicy.GetName())
+		require.NoError(t, err)
+	})
 }
@@ -0,0 +1,43 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateOrUpdateResourceQuota creates or updates a resource quota.
+func (kc *KubeClient) CreateOrUpdateResourceQuota(namespace string, resourceQuota *corev1.ResourceQuota) (metav1.Object, error) {
+	ctx := context.TODO()
+	_, err := kc.Clientset.CoreV1().ResourceQuotas(namespace).Get(ctx, resourceQuota.GetName(), metav1.GetOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return kc.Clientset.CoreV1().ResourceQuotas(namespace).Create(ctx, resourceQuota, metav1.CreateOptions{})
+	}
+
+	return kc.Clientset.CoreV1().ResourceQuotas(namespace).Update(ctx, resourceQuota, metav1.UpdateOptions{})
+}
+
+// CreateOrUpdateLimitRange creates or updates a limit range.
+func (kc *KubeClient) CreateOrUpdateLimitRange(namespace string, limitRange *corev1.LimitRange) (metav1.Object, error) {
+	ctx := context.TODO()
+	_, err := kc.Clientset.CoreV1().LimitRanges(namespace).Get(ctx, limitRange.GetName(), metav1.GetOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return kc.Clientset.CoreV1().LimitRanges(namespace).Create(ctx, limitRange, metav1.CreateOptions{})
+	}
+
+	return kc.Clientset.CoreV1().LimitRanges(namespace).Update(ctx, limitRange, metav1.UpdateOptions{})
+}
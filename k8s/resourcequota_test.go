@@ -0,0 +1,58 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResourceQuota(t *testing.T) {
+	testClient := newTestKubeClient()
+	resourceQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-resource-quota"},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1")},
+		},
+	}
+	namespace := "testing"
+
+	t.Run("create resource quota", func(t *testing.T) {
+		result, err := testClient.CreateOrUpdateResourceQuota(namespace, resourceQuota)
+		require.NoError(t, err)
+		require.Equal(t, resourceQuota.GetName(), result.GetName())
+	})
+	t.Run("update resource quota", func(t *testing.T) {
+		resourceQuota.Spec.Hard[corev1.ResourceRequestsCPU] = resource.MustParse("2")
+		result, err := testClient.CreateOrUpdateResourceQuota(namespace, resourceQuota)
+		require.NoError(t, err)
+		require.Equal(t, resourceQuota.GetName(), result.GetName())
+		hard := result.(*corev1.ResourceQuota).Spec.Hard[corev1.ResourceRequestsCPU]
+		require.Equal(t, "2", hard.String())
+	})
+}
+
+func TestLimitRange(t *testing.T) {
+	testClient := newTestKubeClient()
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-limit-range"},
+	}
+	namespace := "testing"
+
+	t.Run("create limit range", func(t *testing.T) {
+		result, err := testClient.CreateOrUpdateLimitRange(namespace, limitRange)
+		require.NoError(t, err)
+		require.Equal(t, limitRange.GetName(), result.GetName())
+	})
+	t.Run("create duplicate limit range", func(t *testing.T) {
+		result, err := testClient.CreateOrUpdateLimitRange(namespace, limitRange)
+		require.NoError(t, err)
+		require.Equal(t, limitRange.GetName(), result.GetName())
+	})
+}
@@ -4,7 +4,11 @@
 
 package k8s
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+)
 
 // ClusterResources is a snapshot of a cluster's total and currently-used
 // resources.
@@ -13,12 +17,36 @@ type ClusterResources struct {
 	MilliUsedCPU     int64
 	MilliTotalMemory int64
 	MilliUsedMemory  int64
+	// NodeCount is the number of nodes included in the total resources.
+	NodeCount int64
+	// NodeGroups are the resources of the nodes of each node group, keyed by
+	// the name of the node group.
+	NodeGroups map[string]*ClusterResources
+}
+
+// WithNodeHeadroom returns the cluster resources with the total resources
+// extended by the given number of nodes the size of the average node of the
+// cluster. This is the capacity the cluster can grow to by adding nodes.
+func (r *ClusterResources) WithNodeHeadroom(nodes int64) *ClusterResources {
+	resources := *r
+	if nodes <= 0 || r.NodeCount == 0 {
+		return &resources
+	}
+
+	resources.MilliTotalCPU += r.MilliTotalCPU / r.NodeCount * nodes
+	resources.MilliTotalMemory += r.MilliTotalMemory / r.NodeCount * nodes
+	resources.NodeCount += nodes
+
+	return &resources
 }
 
 // CalculateCPUPercentUsed calculates the CPU usage percentage of a cluster with
 // an optional additional load. Pass in 0 to calculate the current CPU usage of
 // the cluster.
 func (r *ClusterResources) CalculateCPUPercentUsed(additional int64) int {
+	if r.MilliTotalCPU == 0 {
+		return math.MaxInt32
+	}
 	return int((float64(r.MilliUsedCPU+additional) / float64(r.MilliTotalCPU)) * 100)
 }
 
@@ -26,6 +54,9 @@ func (r *ClusterResources) CalculateCPUPercentUsed(additional int64) int {
 // cluster with an optional additional load. Pass in 0 to calculate the current
 // memory usage of the cluster.
 func (r *ClusterResources) CalculateMemoryPercentUsed(additional int64) int {
+	if r.MilliTotalMemory == 0 {
+		return math.MaxInt32
+	}
 	return int((float64(r.MilliUsedMemory+additional) / float64(r.MilliTotalMemory)) * 100)
 }
 
@@ -42,3 +73,77 @@ func CalculateTotalPodMilliResourceRequests(pods *corev1.PodList) (int64, int64)
 
 	return totalCPU, totalMemory
 }
+
+// CalculateTotalMilliResourceReservations calculates the total CPU and memory
+// milli resources reserved by a list of pods and resource quotas. The
+// resources of a namespace with a resource quota on requests are the larger
+// of its pod requests and its quota, as the quota may be used at any time.
+func CalculateTotalMilliResourceReservations(pods *corev1.PodList, quotas *corev1.ResourceQuotaList) (int64, int64) {
+	namespaceCPU := make(map[string]int64)
+	namespaceMemory := make(map[string]int64)
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			namespaceCPU[pod.Namespace] += container.Resources.Requests.Cpu().MilliValue()
+			namespaceMemory[pod.Namespace] += container.Resources.Requests.Memory().MilliValue()
+		}
+	}
+
+	// The most restrictive quota of a namespace is the one in effect.
+	quotaCPU := make(map[string]int64)
+	quotaMemory := make(map[string]int64)
+	for _, quota := range quotas.Items {
+		if cpu, ok := quota.Spec.Hard[corev1.ResourceRequestsCPU]; ok {
+			if current, found := quotaCPU[quota.Namespace]; !found || cpu.MilliValue() < current {
+				quotaCPU[quota.Namespace] = cpu.MilliValue()
+			}
+		}
+		if memory, ok := quota.Spec.Hard[corev1.ResourceRequestsMemory]; ok {
+			if current, found := quotaMemory[quota.Namespace]; !found || memory.MilliValue() < current {
+				quotaMemory[quota.Namespace] = memory.MilliValue()
+			}
+		}
+	}
+	for namespace, cpu := range quotaCPU {
+		if cpu > namespaceCPU[namespace] {
+			namespaceCPU[namespace] = cpu
+		}
+	}
+	for namespace, memory := range quotaMemory {
+		if memory > namespaceMemory[namespace] {
+			namespaceMemory[namespace] = memory
+		}
+	}
+
+	var totalCPU, totalMemory int64
+	for _, cpu := range namespaceCPU {
+		totalCPU += cpu
+	}
+	for _, memory := range namespaceMemory {
+		totalMemory += memory
+	}
+
+	return totalCPU, totalMemory
+}
+
+// CalculateNodesMilliResourceReservations calculates the total CPU and memory
+// milli resources reserved on the given nodes by the pods scheduled on them
+// and the resource quotas of the namespaces of those pods.
+func CalculateNodesMilliResourceReservations(pods *corev1.PodList, quotas *corev1.ResourceQuotaList, nodeNames map[string]bool) (int64, int64) {
+	nodePods := &corev1.PodList{}
+	namespaces := make(map[string]bool)
+	for _, pod := range pods.Items {
+		if nodeNames[pod.Spec.NodeName] {
+			nodePods.Items = append(nodePods.Items, pod)
+			namespaces[pod.Namespace] = true
+		}
+	}
+
+	nodeQuotas := &corev1.ResourceQuotaList{}
+	for _, quota := range quotas.Items {
+		if namespaces[quota.Namespace] {
+			nodeQuotas.Items = append(nodeQuotas.Items, quota)
+		}
+	}
+
+	return CalculateTotalMilliResourceReservations(nodePods, nodeQuotas)
+}
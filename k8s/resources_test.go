@@ -0,0 +1,128 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWithNodeHeadroom(t *testing.T) {
+	resources := &ClusterResources{
+		MilliTotalCPU:    4000,
+		MilliUsedCPU:     3000,
+		MilliTotalMemory: 8000,
+		MilliUsedMemory:  6000,
+		NodeCount:        2,
+	}
+
+	t.Run("headroom", func(t *testing.T) {
+		assert.Equal(t, &ClusterResources{
+			MilliTotalCPU:    10000,
+			MilliUsedCPU:     3000,
+			MilliTotalMemory: 20000,
+			MilliUsedMemory:  6000,
+			NodeCount:        5,
+		}, resources.WithNodeHeadroom(3))
+		assert.Equal(t, int64(4000), resources.MilliTotalCPU)
+	})
+
+	t.Run("no headroom", func(t *testing.T) {
+		assert.Equal(t, resources, resources.WithNodeHeadroom(0))
+		assert.Equal(t, resources, resources.WithNodeHeadroom(-1))
+	})
+
+	t.Run("no nodes", func(t *testing.T) {
+		empty := &ClusterResources{}
+		assert.Equal(t, empty, empty.WithNodeHeadroom(3))
+	})
+}
+
+func TestCalculateTotalMilliResourceReservations(t *testing.T) {
+	newPod := func(namespace, cpu, memory string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpu),
+							corev1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				}},
+			},
+		}
+	}
+	newQuota := func(namespace, cpu, memory string) corev1.ResourceQuota {
+		return corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard: corev1.ResourceList{
+					corev1.ResourceRequestsCPU:    resource.MustParse(cpu),
+					corev1.ResourceRequestsMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+
+	pods := &corev1.PodList{Items: []corev1.Pod{
+		newPod("kube-system", "100m", "100"),
+		newPod("quota-unused", "100m", "100"),
+		newPod("quota-exceeded", "1", "1000"),
+	}}
+
+	t.Run("no quotas", func(t *testing.T) {
+		cpu, memory := CalculateTotalMilliResourceReservations(pods, &corev1.ResourceQuotaList{})
+		assert.Equal(t, int64(1200), cpu)
+		assert.Equal(t, int64(1200000), memory)
+	})
+
+	t.Run("quotas", func(t *testing.T) {
+		quotas := &corev1.ResourceQuotaList{Items: []corev1.ResourceQuota{
+			newQuota("quota-unused", "500m", "500"),
+			newQuota("quota-unused", "2", "2000"),
+			newQuota("quota-exceeded", "500m", "500"),
+			newQuota("empty", "1", "1000"),
+		}}
+		cpu, memory := CalculateTotalMilliResourceReservations(pods, quotas)
+		assert.Equal(t, int64(2600), cpu)
+		assert.Equal(t, int64(2600000), memory)
+	})
+
+	t.Run("nodes", func(t *testing.T) {
+		nodePods := &corev1.PodList{Items: []corev1.Pod{
+			newPod("kube-system", "100m", "100"),
+			newPod("quota-unused", "100m", "100"),
+			newPod("quota-exceeded", "1", "1000"),
+		}}
+		nodePods.Items[0].Spec.NodeName = "node1"
+		nodePods.Items[1].Spec.NodeName = "node2"
+		nodePods.Items[2].Spec.NodeName = "node3"
+		quotas := &corev1.ResourceQuotaList{Items: []corev1.ResourceQuota{
+			newQuota("quota-unused", "500m", "500"),
+			newQuota("quota-exceeded", "500m", "500"),
+		}}
+
+		cpu, memory := CalculateNodesMilliResourceReservations(nodePods, quotas, map[string]bool{"node1": true, "node2": true})
+		assert.Equal(t, int64(600), cpu)
+		assert.Equal(t, int64(600000), memory)
+	})
+}
+
+func TestCalculatePercentUsed(t *testing.T) {
+	resources := &ClusterResources{MilliTotalCPU: 4000, MilliUsedCPU: 1000, MilliTotalMemory: 8000, MilliUsedMemory: 4000}
+	assert.Equal(t, 50, resources.CalculateCPUPercentUsed(1000))
+	assert.Equal(t, 75, resources.CalculateMemoryPercentUsed(2000))
+
+	empty := &ClusterResources{}
+	assert.Equal(t, math.MaxInt32, empty.CalculateCPUPercentUsed(0))
+	assert.Equal(t, math.MaxInt32, empty.CalculateMemoryPercentUsed(0))
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// VeleroBackupResource is the resource of the Velero Backup custom resource.
+var VeleroBackupResource = schema.GroupVersionResource{
+	Group:    "velero.io",
+	Version:  "v1",
+	Resource: "backups",
+}
+
+// CreateVeleroBackup creates a Velero Backup of the included namespaces. An
+// empty list of included namespaces backs up every namespace of the cluster.
+// Persistent volumes are not snapshotted.
+func (kc *KubeClient) CreateVeleroBackup(namespace, name string, includedNamespaces []string) (*unstructured.Unstructured, error) {
+	if len(includedNamespaces) == 0 {
+		includedNamespaces = []string{"*"}
+	}
+	namespaces := []interface{}{}
+	for _, includedNamespace := range includedNamespaces {
+		namespaces = append(namespaces, includedNamespace)
+	}
+
+	backup := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "velero.io/v1",
+			"kind":       "Backup",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"includedNamespaces": namespaces,
+				"snapshotVolumes":    false,
+			},
+		},
+	}
+
+	return kc.DynamicClient.Resource(VeleroBackupResource).Namespace(namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
+}
+
+// ListVeleroBackups returns the Velero Backups in the given namespace.
+func (kc *KubeClient) ListVeleroBackups(namespace string) ([]unstructured.Unstructured, error) {
+	list, err := kc.DynamicClient.Resource(VeleroBackupResource).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestVeleroBackups(t *testing.T) {
+	testClient := newTestKubeClient()
+	namespace := "velero"
+
+	t.Run("list no backups", func(t *testing.T) {
+		backups, err := testClient.ListVeleroBackups(namespace)
+		require.NoError(t, err)
+		require.Empty(t, backups)
+	})
+
+	t.Run("create backup of namespaces", func(t *testing.T) {
+		backup, err := testClient.CreateVeleroBackup(namespace, "backup1", []string{"ns1", "ns2"})
+		require.NoError(t, err)
+		require.Equal(t, "backup1", backup.GetName())
+
+		included, found, err := unstructured.NestedStringSlice(backup.Object, "spec", "includedNamespaces")
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, []string{"ns1", "ns2"}, included)
+
+		snapshotVolumes, found, err := unstructured.NestedBool(backup.Object, "spec", "snapshotVolumes")
+		require.NoError(t, err)
+		require.True(t, found)
+		require.False(t, snapshotVolumes)
+	})
+
+	t.Run("create backup of all namespaces", func(t *testing.T) {
+		backup, err := testClient.CreateVeleroBackup(namespace, "backup2", nil)
+		require.NoError(t, err)
+
+		included, found, err := unstructured.NestedStringSlice(backup.Object, "spec", "includedNamespaces")
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, []string{"*"}, included)
+	})
+
+	t.Run("list backups", func(t *testing.T) {
+		backups, err := testClient.ListVeleroBackups(namespace)
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+	})
+}
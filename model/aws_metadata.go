@@ -9,6 +9,34 @@ import "encoding/json"
 // AWSMetadata is the provider metadata stored in a model.Cluster.
 type AWSMetadata struct {
 	Zones []string
+
+	// Region is the AWS region of the cluster. Clusters without a region
+	// are placed in the region of the provisioning server.
+	Region string `json:"Region,omitempty"`
+	// AssumeRoleARN is the IAM role assumed to manage the AWS resources of
+	// a cluster placed in another AWS account.
+	AssumeRoleARN string `json:"AssumeRoleARN,omitempty"`
+}
+
+// AWSRegion returns the AWS region of the cluster or an empty string if the
+// cluster uses the region of the provisioning server.
+func (c *Cluster) AWSRegion() string {
+	if c.ProviderMetadataAWS == nil {
+		return ""
+	}
+
+	return c.ProviderMetadataAWS.Region
+}
+
+// AWSAssumeRoleARN returns the IAM role assumed to manage the AWS resources
+// of the cluster or an empty string if the cluster is in the account of the
+// provisioning server.
+func (c *Cluster) AWSAssumeRoleARN() string {
+	if c.ProviderMetadataAWS == nil {
+		return ""
+	}
+
+	return c.ProviderMetadataAWS.AssumeRoleARN
 }
 
 // NewAWSMetadata creates an instance of AWSMetadata given the raw provider metadata.
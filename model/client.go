@@ -129,6 +129,23 @@ func (c *Client) CreateCluster(request *CreateClusterRequest) (*ClusterDTO, erro
 	}
 }
 
+// ImportCluster imports an existing cluster into the configured provisioning server.
+func (c *Client) ImportCluster(request *ImportClusterRequest) (*ClusterDTO, error) {
+	resp, err := c.doPost(c.buildURL("/api/clusters/import"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // RetryCreateCluster retries the creation of a cluster from the configured provisioning server.
 func (c *Client) RetryCreateCluster(clusterID string) error {
 	resp, err := c.doPost(c.buildURL("/api/cluster/%s", clusterID), nil)
@@ -225,6 +242,94 @@ func (c *Client) GetClusterUtilities(clusterID string) (*UtilityMetadata, error)
 	}
 }
 
+// UpdateClusterUtilities updates the Helm values overrides of the utilities
+// of a cluster and reprovisions the cluster to apply them.
+func (c *Client) UpdateClusterUtilities(clusterID string, request *UpdateClusterUtilitiesRequest) (*ClusterDTO, error) {
+	resp, err := c.doPut(c.buildURL("/api/cluster/%s/utilities", clusterID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetClusterUtilityDrift fetches the clusters whose utilities drifted from
+// their deployed state.
+func (c *Client) GetClusterUtilityDrift() ([]*ClusterUtilityDrift, error) {
+	resp, err := c.doGet(c.buildURL("/api/clusters/utilities/drift"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ClusterUtilityDriftReportFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// UpgradeClusterUtility requests the upgrade or rollback of a single
+// utility of a cluster.
+func (c *Client) UpgradeClusterUtility(clusterID, utility string, request *UpgradeClusterUtilityRequest) (*ClusterDTO, error) {
+	resp, err := c.doPut(c.buildURL("/api/cluster/%s/utilities/%s", clusterID, utility), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// CreateClusterBackup triggers a backup of the Kubernetes state of a cluster.
+func (c *Client) CreateClusterBackup(clusterID string, request *CreateClusterBackupRequest) (*ClusterBackup, error) {
+	resp, err := c.doPost(c.buildURL("/api/cluster/%s/backups", clusterID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterBackupFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetClusterBackups returns the backups of a cluster.
+func (c *Client) GetClusterBackups(clusterID string) ([]*ClusterBackup, error) {
+	resp, err := c.doGet(c.buildURL("/api/cluster/%s/backups", clusterID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ClusterBackupsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // UpdateCluster updates a cluster's configuration.
 func (c *Client) UpdateCluster(clusterID string, request *UpdateClusterRequest) (*ClusterDTO, error) {
 	resp, err := c.doPut(c.buildURL("/api/cluster/%s", clusterID), request)
@@ -276,6 +381,76 @@ func (c *Client) ResizeCluster(clusterID string, request *PatchClusterSizeReques
 	}
 }
 
+// PreviewUpgradeCluster returns the changes upgrading a cluster would make
+// without applying them.
+func (c *Client) PreviewUpgradeCluster(clusterID string, request *PatchUpgradeClusterRequest) (*ClusterChangePreview, error) {
+	resp, err := c.doPut(c.buildURL("/api/cluster/%s/kubernetes?dry-run=true", clusterID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ClusterChangePreviewFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// PreviewResizeCluster returns the changes resizing a cluster would make
+// without applying them.
+func (c *Client) PreviewResizeCluster(clusterID string, request *PatchClusterSizeRequest) (*ClusterChangePreview, error) {
+	resp, err := c.doPut(c.buildURL("/api/cluster/%s/size?dry-run=true", clusterID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ClusterChangePreviewFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// ResumeClusterUpgrade resumes a paused staged upgrade of the given cluster.
+func (c *Client) ResumeClusterUpgrade(clusterID string) (*ClusterDTO, error) {
+	resp, err := c.doPost(c.buildURL("/api/cluster/%s/upgrade/resume", clusterID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// AbortClusterUpgrade aborts a paused staged upgrade of the given cluster.
+func (c *Client) AbortClusterUpgrade(clusterID string) (*ClusterDTO, error) {
+	resp, err := c.doPost(c.buildURL("/api/cluster/%s/upgrade/abort", clusterID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ClusterDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // DeleteCluster deletes the given cluster and all resources contained therein.
 func (c *Client) DeleteCluster(clusterID string) error {
 	resp, err := c.doDelete(c.buildURL("/api/cluster/%s", clusterID))
@@ -515,6 +690,85 @@ func (c *Client) WakeupInstallation(installationID string) (*InstallationDTO, er
 	}
 }
 
+// MigrateInstallationFilestore requests the installation files be moved to a
+// new filestore.
+func (c *Client) MigrateInstallationFilestore(installationID string, request *MigrateInstallationFilestoreRequest) (*InstallationDTO, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/filestore/migrate", installationID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// RotateInstallationCredentials requests new database and filestore
+// credentials for an installation.
+func (c *Client) RotateInstallationCredentials(installationID string) (*InstallationDTO, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/rotate-credentials", installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallationUsage fetches the storage usage records of an installation,
+// ordered from newest to oldest.
+func (c *Client) GetInstallationUsage(installationID string, request *GetInstallationUsageRequest) ([]*InstallationUsage, error) {
+	u, err := url.Parse(c.buildURL("/api/installation/%s/usage", installationID))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationUsagesFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallationCertificate fetches the status of the TLS certificate of
+// the given installation.
+func (c *Client) GetInstallationCertificate(installationID string) (*InstallationCertificate, error) {
+	resp, err := c.doGet(c.buildURL("/api/installation/%s/certificate", installationID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationCertificateFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // DeleteInstallation deletes the given installation and all resources contained therein.
 func (c *Client) DeleteInstallation(installationID string) error {
 	resp, err := c.doDelete(c.buildURL("/api/installation/%s", installationID))
@@ -566,6 +820,58 @@ func (c *Client) DeleteInstallationAnnotation(installationID string, annotationN
 	}
 }
 
+// AddInstallationDomain attaches a custom domain to the given installation.
+func (c *Client) AddInstallationDomain(installationID string, request *CreateInstallationDomainRequest) (*InstallationDomainDTO, error) {
+	resp, err := c.doPost(c.buildURL("/api/installation/%s/domains", installationID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationDomainDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallationDomains fetches the custom domains of the given installation.
+func (c *Client) GetInstallationDomains(installationID string) ([]*InstallationDomainDTO, error) {
+	resp, err := c.doGet(c.buildURL("/api/installation/%s/domains", installationID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationDomainDTOsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// DeleteInstallationDomain requests the removal of a custom domain from the
+// given installation.
+func (c *Client) DeleteInstallationDomain(installationID, domainID string) error {
+	resp, err := c.doDelete(c.buildURL("/api/installation/%s/domain/%s", installationID, domainID))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return nil
+
+	default:
+		return errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // GetClusterInstallation fetches the specified cluster installation from the configured provisioning server.
 func (c *Client) GetClusterInstallation(clusterInstallationID string) (*ClusterInstallation, error) {
 	resp, err := c.doGet(c.buildURL("/api/cluster_installation/%s", clusterInstallationID))
@@ -867,6 +1173,43 @@ func (c *Client) GetMultitenantDatabases(request *GetDatabasesRequest) ([]*Multi
 	}
 }
 
+// GetVPCs fetches the pool of VPCs tagged for use by the provisioner from
+// the configured provisioning server.
+func (c *Client) GetVPCs() ([]*VPC, error) {
+	resp, err := c.doGet(c.buildURL("/api/vpcs"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return VPCsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// ReconcileMultitenantDatabases requests a reconciliation of all multitenant
+// databases against their AWS resources from the configured provisioning
+// server.
+func (c *Client) ReconcileMultitenantDatabases(request *ReconcileMultitenantDatabasesRequest) (*MultitenantDatabaseReconcileReport, error) {
+	resp, err := c.doPost(c.buildURL("/api/databases/reconcile"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return MultitenantDatabaseReconcileReportFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
 // CreateWebhook requests the creation of a webhook from the configured provisioning server.
 func (c *Client) CreateWebhook(request *CreateWebhookRequest) (*Webhook, error) {
 	resp, err := c.doPost(c.buildURL("/api/webhooks"), request)
@@ -18,6 +18,7 @@ type Cluster struct {
 	ProviderMetadataAWS     *AWSMetadata
 	Provisioner             string
 	ProvisionerMetadataKops *KopsMetadata
+	ProvisionerMetadataEKS  *EKSMetadata
 	UtilityMetadata         *UtilityMetadata
 	AllowInstallations      bool
 	CreateAt                int64
@@ -25,6 +26,10 @@ type Cluster struct {
 	APISecurityLock         bool
 	LockAcquiredBy          *string
 	LockAcquiredAt          int64
+
+	// ProvisionerMetadataExternal is the metadata of clusters imported with
+	// a kubeconfig.
+	ProvisionerMetadataExternal *ExternalMetadata
 }
 
 // Clone returns a deep copy the cluster.
@@ -36,6 +41,16 @@ func (c *Cluster) Clone() *Cluster {
 	return &clone
 }
 
+// IsImported returns true if the cluster was created outside of the
+// provisioner.
+func (c *Cluster) IsImported() bool {
+	if c.Provisioner == ProvisionerExternal {
+		return true
+	}
+
+	return c.ProvisionerMetadataKops != nil && c.ProvisionerMetadataKops.Imported
+}
+
 // ToDTO expands cluster to ClusterDTO.
 func (c *Cluster) ToDTO(annotations []*Annotation) *ClusterDTO {
 	return &ClusterDTO{
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ClusterBackup is a Velero backup of the Kubernetes state of cluster
+// namespaces.
+type ClusterBackup struct {
+	Name string
+	// Namespaces are the namespaces included in the backup. A backup of all
+	// namespaces is described by "*".
+	Namespaces []string
+	// Phase is the Velero backup phase such as InProgress or Completed.
+	Phase               string `json:"Phase,omitempty"`
+	StartTimestamp      int64  `json:"StartTimestamp,omitempty"`
+	CompletionTimestamp int64  `json:"CompletionTimestamp,omitempty"`
+}
+
+// CreateClusterBackupRequest specifies the parameters for a new cluster
+// backup.
+type CreateClusterBackupRequest struct {
+	// Namespaces are the namespaces to back up. All namespaces of the
+	// cluster are backed up when empty.
+	Namespaces []string
+}
+
+// Validate validates the values of a cluster backup create request.
+func (request *CreateClusterBackupRequest) Validate() error {
+	for _, namespace := range request.Namespaces {
+		if len(namespace) == 0 {
+			return errors.New("backup namespaces must not be empty")
+		}
+	}
+
+	return nil
+}
+
+// NewCreateClusterBackupRequestFromReader will create a
+// CreateClusterBackupRequest from an io.Reader with JSON data.
+func NewCreateClusterBackupRequestFromReader(reader io.Reader) (*CreateClusterBackupRequest, error) {
+	var createClusterBackupRequest CreateClusterBackupRequest
+	err := json.NewDecoder(reader).Decode(&createClusterBackupRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode create cluster backup request")
+	}
+
+	err = createClusterBackupRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "create cluster backup request failed validation")
+	}
+
+	return &createClusterBackupRequest, nil
+}
+
+// ClusterBackupFromReader decodes a json-encoded cluster backup from the
+// given io.Reader.
+func ClusterBackupFromReader(reader io.Reader) (*ClusterBackup, error) {
+	backup := ClusterBackup{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&backup)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &backup, nil
+}
+
+// ClusterBackupsFromReader decodes a json-encoded list of cluster backups
+// from the given io.Reader.
+func ClusterBackupsFromReader(reader io.Reader) ([]*ClusterBackup, error) {
+	backups := []*ClusterBackup{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&backups)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return backups, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCreateClusterBackupRequestFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		request, err := NewCreateClusterBackupRequestFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Empty(t, request.Namespaces)
+	})
+
+	t.Run("namespaces", func(t *testing.T) {
+		request, err := NewCreateClusterBackupRequestFromReader(bytes.NewReader([]byte(`{"Namespaces":["ns1","ns2"]}`)))
+		require.NoError(t, err)
+		require.Equal(t, []string{"ns1", "ns2"}, request.Namespaces)
+	})
+
+	t.Run("empty namespace", func(t *testing.T) {
+		_, err := NewCreateClusterBackupRequestFromReader(bytes.NewReader([]byte(`{"Namespaces":["ns1",""]}`)))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := NewCreateClusterBackupRequestFromReader(bytes.NewReader([]byte(`{test`)))
+		require.Error(t, err)
+	})
+}
+
+func TestClusterBackupsFromReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		backups, err := ClusterBackupsFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Empty(t, backups)
+	})
+
+	t.Run("backups", func(t *testing.T) {
+		backups, err := ClusterBackupsFromReader(bytes.NewReader([]byte(`[{"Name":"backup1","Namespaces":["*"],"Phase":"Completed"}]`)))
+		require.NoError(t, err)
+		require.Equal(t, []*ClusterBackup{{Name: "backup1", Namespaces: []string{"*"}, Phase: "Completed"}}, backups)
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ClusterChangePreview describes the changes a cluster upgrade or resize
+// would make without applying them.
+type ClusterChangePreview struct {
+	ClusterID     string
+	ChangeRequest *KopsMetadataRequestedState `json:"ChangeRequest,omitempty"`
+	// KopsUpdate is the output of kops update cluster without applying the
+	// changes.
+	KopsUpdate string `json:"KopsUpdate,omitempty"`
+	// TerraformPlan is the output of terraform plan for the kops generated
+	// terraform configuration.
+	TerraformPlan string `json:"TerraformPlan,omitempty"`
+}
+
+// HasChanges returns true if the previewed request changes the cluster.
+func (p *ClusterChangePreview) HasChanges() bool {
+	return p.ChangeRequest != nil
+}
+
+// ClusterChangePreviewFromReader decodes a json-encoded cluster change preview
+// from the given io.Reader.
+func ClusterChangePreviewFromReader(reader io.Reader) (*ClusterChangePreview, error) {
+	preview := ClusterChangePreview{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&preview)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &preview, nil
+}
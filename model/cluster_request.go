@@ -8,38 +8,81 @@ import (
 	"encoding/json"
 	"io"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
 // CreateClusterRequest specifies the parameters for a new cluster.
 type CreateClusterRequest struct {
-	Provider               string            `json:"provider,omitempty"`
-	Zones                  []string          `json:"zones,omitempty"`
-	Version                string            `json:"version,omitempty"`
-	KopsAMI                string            `json:"kops-ami,omitempty"`
-	MasterInstanceType     string            `json:"master-instance-type,omitempty"`
-	MasterCount            int64             `json:"master-count,omitempty"`
-	NodeInstanceType       string            `json:"node-instance-type,omitempty"`
-	NodeMinCount           int64             `json:"node-min-count,omitempty"`
-	NodeMaxCount           int64             `json:"node-max-count,omitempty"`
-	AllowInstallations     bool              `json:"allow-installations,omitempty"`
-	APISecurityLock        bool              `json:"api-security-lock,omitempty"`
-	DesiredUtilityVersions map[string]string `json:"utility-versions,omitempty"`
-	Annotations            []string          `json:"annotations,omitempty"`
+	Provider               string              `json:"provider,omitempty"`
+	Zones                  []string            `json:"zones,omitempty"`
+	Version                string              `json:"version,omitempty"`
+	KopsAMI                string              `json:"kops-ami,omitempty"`
+	MasterInstanceType     string              `json:"master-instance-type,omitempty"`
+	MasterCount            int64               `json:"master-count,omitempty"`
+	NodeInstanceType       string              `json:"node-instance-type,omitempty"`
+	NodeMinCount           int64               `json:"node-min-count,omitempty"`
+	NodeMaxCount           int64               `json:"node-max-count,omitempty"`
+	NodeGroups             []*NodeGroupRequest `json:"node-groups,omitempty"`
+	AllowInstallations     bool                `json:"allow-installations,omitempty"`
+	APISecurityLock        bool                `json:"api-security-lock,omitempty"`
+	DesiredUtilityVersions map[string]string   `json:"utility-versions,omitempty"`
+	Annotations            []string            `json:"annotations,omitempty"`
+
+	// NodeMixedInstancesPolicy runs the default node instance group on a mix
+	// of on-demand and spot instances.
+	NodeMixedInstancesPolicy *MixedInstancesPolicyRequest `json:"node-mixed-instances-policy,omitempty"`
+
+	// Autoscaling lets the cluster-autoscaler scale the default node
+	// instance group between the node min and max counts.
+	Autoscaling bool `json:"autoscaling,omitempty"`
+
+	// Provisioner is the provisioner that creates and manages the cluster.
+	Provisioner string `json:"provisioner,omitempty"`
+
+	// UtilityValuesOverrides are Helm values keyed by utility name that
+	// override the default values of the utility charts.
+	UtilityValuesOverrides map[string]string `json:"utility-values,omitempty"`
+
+	// Region is the AWS region to place the cluster in. The region of the
+	// provisioning server is used if no region is specified.
+	Region string `json:"region,omitempty"`
+	// AssumeRoleARN is an IAM role to assume to place the cluster in another
+	// AWS account.
+	AssumeRoleARN string `json:"assume-role-arn,omitempty"`
 }
 
+// awsRegionPattern matches the names of AWS regions such as eu-central-1.
+var awsRegionPattern = regexp.MustCompile(`^[a-z]{2}(-gov)?-[a-z]+-[0-9]$`)
+
+// assumeRoleARNPattern matches the ARNs of IAM roles.
+var assumeRoleARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`)
+
 // SetDefaults sets the default values for a cluster create request.
 func (request *CreateClusterRequest) SetDefaults() {
 	if len(request.Provider) == 0 {
 		request.Provider = ProviderAWS
 	}
+	if len(request.Provisioner) == 0 {
+		request.Provisioner = ProvisionerKops
+	}
+	request.Provisioner = strings.ToLower(request.Provisioner)
 	if len(request.Version) == 0 {
 		request.Version = "latest"
 	}
 	if len(request.Zones) == 0 {
-		request.Zones = []string{"us-east-1a"}
+		region := request.Region
+		if len(region) == 0 {
+			region = "us-east-1"
+		}
+		request.Zones = []string{region + "a"}
+		if request.Provisioner == ProvisionerEKS {
+			// EKS control planes require subnets in at least two zones.
+			request.Zones = append(request.Zones, region+"b")
+		}
 	}
 	if len(request.MasterInstanceType) == 0 {
 		request.MasterInstanceType = "t3.medium"
@@ -49,6 +92,9 @@ func (request *CreateClusterRequest) SetDefaults() {
 	}
 	if len(request.NodeInstanceType) == 0 {
 		request.NodeInstanceType = "m5.large"
+		if request.NodeMixedInstancesPolicy != nil && len(request.NodeMixedInstancesPolicy.Instances) != 0 {
+			request.NodeInstanceType = request.NodeMixedInstancesPolicy.Instances[0]
+		}
 	}
 	if request.NodeMinCount == 0 {
 		request.NodeMinCount = 2
@@ -56,6 +102,11 @@ func (request *CreateClusterRequest) SetDefaults() {
 	if request.NodeMaxCount == 0 {
 		request.NodeMaxCount = request.NodeMinCount
 	}
+	for _, nodeGroup := range request.NodeGroups {
+		if nodeGroup != nil {
+			nodeGroup.SetDefaults()
+		}
+	}
 	if request.DesiredUtilityVersions == nil {
 		request.DesiredUtilityVersions = make(map[string]string)
 	}
@@ -74,6 +125,18 @@ func (request *CreateClusterRequest) SetDefaults() {
 	if _, ok := request.DesiredUtilityVersions[TeleportCanonicalName]; !ok {
 		request.DesiredUtilityVersions[TeleportCanonicalName] = TeleportDefaultVersion
 	}
+	if _, ok := request.DesiredUtilityVersions[VeleroCanonicalName]; !ok {
+		request.DesiredUtilityVersions[VeleroCanonicalName] = VeleroDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[CertManagerCanonicalName]; !ok {
+		request.DesiredUtilityVersions[CertManagerCanonicalName] = CertManagerDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[ClusterAutoscalerCanonicalName]; !ok {
+		request.DesiredUtilityVersions[ClusterAutoscalerCanonicalName] = ClusterAutoscalerDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[NodeTerminationHandlerCanonicalName]; !ok {
+		request.DesiredUtilityVersions[NodeTerminationHandlerCanonicalName] = NodeTerminationHandlerDefaultVersion
+	}
 }
 
 // Validate validates the values of a cluster create request.
@@ -90,14 +153,190 @@ func (request *CreateClusterRequest) Validate() error {
 	if request.NodeMinCount < 1 {
 		return errors.Errorf("node min count (%d) must be 1 or greater", request.NodeMinCount)
 	}
-	if request.NodeMaxCount != request.NodeMinCount {
+	if request.Autoscaling {
+		if request.NodeMaxCount <= request.NodeMinCount {
+			return errors.Errorf("autoscaling requires a node max count (%d) greater than the node min count (%d)", request.NodeMaxCount, request.NodeMinCount)
+		}
+	} else if request.NodeMaxCount != request.NodeMinCount {
 		return errors.Errorf("node min (%d) and max (%d) counts must match", request.NodeMinCount, request.NodeMaxCount)
 	}
+	err := validateNodeGroupRequests(request.NodeGroups)
+	if err != nil {
+		return err
+	}
+	if request.NodeMixedInstancesPolicy != nil {
+		err = request.NodeMixedInstancesPolicy.Validate()
+		if err != nil {
+			return err
+		}
+		if len(request.NodeMixedInstancesPolicy.Instances) == 0 {
+			return errors.New("mixed instances policy requires at least one instance type")
+		}
+		err = request.NodeMixedInstancesPolicy.ValidateNodeInstanceType(request.NodeInstanceType)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = CheckProvisioner(request.Provisioner)
+	if err != nil {
+		return err
+	}
+	err = request.validatePlacement()
+	if err != nil {
+		return err
+	}
+	err = ValidateUtilityValuesOverrides(request.UtilityValuesOverrides)
+	if err != nil {
+		return err
+	}
+	if request.Provisioner == ProvisionerEKS {
+		err = request.validateEKS()
+		if err != nil {
+			return err
+		}
+	}
 	// TODO: check zones and instance types?
 
 	return nil
 }
 
+// validatePlacement validates the AWS region and account of a cluster create
+// request.
+func (request *CreateClusterRequest) validatePlacement() error {
+	if len(request.Region) != 0 {
+		if !awsRegionPattern.MatchString(request.Region) {
+			return errors.Errorf("invalid region %s", request.Region)
+		}
+		for _, zone := range request.Zones {
+			if !strings.HasPrefix(zone, request.Region) {
+				return errors.Errorf("zone %s is not in region %s", zone, request.Region)
+			}
+		}
+	}
+	if len(request.AssumeRoleARN) != 0 && !assumeRoleARNPattern.MatchString(request.AssumeRoleARN) {
+		return errors.Errorf("invalid assume role ARN %s", request.AssumeRoleARN)
+	}
+
+	return nil
+}
+
+// validateEKS validates the values of a cluster create request that are
+// specific to the EKS provisioner.
+func (request *CreateClusterRequest) validateEKS() error {
+	if len(request.Zones) < 2 {
+		return errors.New("eks clusters require at least two zones")
+	}
+	if len(request.KopsAMI) != 0 {
+		return errors.New("kops AMI is not supported by the eks provisioner")
+	}
+	if len(request.NodeGroups) != 0 {
+		return errors.New("additional node groups are not supported by the eks provisioner")
+	}
+	if request.NodeMixedInstancesPolicy != nil {
+		return errors.New("mixed instances policies are not supported by the eks provisioner")
+	}
+	if request.Autoscaling {
+		return errors.New("autoscaling is not supported by the eks provisioner")
+	}
+
+	return nil
+}
+
+// NodeGroupRequest specifies the parameters of an additional node group.
+type NodeGroupRequest struct {
+	Name         string `json:"name"`
+	InstanceType string `json:"instance-type"`
+	MinCount     int64  `json:"min-count"`
+	MaxCount     int64  `json:"max-count,omitempty"`
+}
+
+// nodeGroupNamePattern matches the names kops accepts for instance groups.
+var nodeGroupNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// reservedNodeGroupNames are instance group names managed by the provisioner.
+var reservedNodeGroupNames = map[string]bool{
+	"nodes":           true,
+	"nodes-utilities": true,
+}
+
+// SetDefaults sets the default values for a node group request.
+func (request *NodeGroupRequest) SetDefaults() {
+	if request.MaxCount == 0 {
+		request.MaxCount = request.MinCount
+	}
+}
+
+// Validate validates the values of a node group request.
+func (request *NodeGroupRequest) Validate() error {
+	if !nodeGroupNamePattern.MatchString(request.Name) {
+		return errors.Errorf("node group name %q is invalid", request.Name)
+	}
+	if reservedNodeGroupNames[request.Name] || strings.HasPrefix(request.Name, "master-") {
+		return errors.Errorf("node group name %q is reserved", request.Name)
+	}
+	if len(request.InstanceType) == 0 {
+		return errors.Errorf("node group %s instance type cannot be blank", request.Name)
+	}
+	if request.MinCount < 1 {
+		return errors.Errorf("node group %s min count (%d) must be 1 or greater", request.Name, request.MinCount)
+	}
+	if request.MaxCount < request.MinCount {
+		return errors.Errorf("node group %s max count (%d) can't be less than min count (%d)", request.Name, request.MaxCount, request.MinCount)
+	}
+
+	return nil
+}
+
+// ToKopsNodeGroup converts the request into a KopsNodeGroup.
+func (request *NodeGroupRequest) ToKopsNodeGroup() *KopsNodeGroup {
+	return &KopsNodeGroup{
+		Name:         request.Name,
+		InstanceType: request.InstanceType,
+		MinCount:     request.MinCount,
+		MaxCount:     request.MaxCount,
+	}
+}
+
+func validateNodeGroupRequests(requests []*NodeGroupRequest) error {
+	names := make(map[string]bool)
+	for _, request := range requests {
+		if request == nil {
+			return errors.New("node group cannot be empty")
+		}
+		err := request.Validate()
+		if err != nil {
+			return err
+		}
+		if names[request.Name] {
+			return errors.Errorf("node group %s is defined more than once", request.Name)
+		}
+		names[request.Name] = true
+	}
+
+	return nil
+}
+
+// KopsNodeGroups returns the requested additional node groups converted to
+// KopsNodeGroups.
+func (request *CreateClusterRequest) KopsNodeGroups() []*KopsNodeGroup {
+	var nodeGroups []*KopsNodeGroup
+	for _, nodeGroup := range request.NodeGroups {
+		nodeGroups = append(nodeGroups, nodeGroup.ToKopsNodeGroup())
+	}
+
+	return nodeGroups
+}
+
+// KopsNodeMixedInstancesPolicy returns the requested mixed instances policy of
+// the default node instance group or nil if none was requested.
+func (request *CreateClusterRequest) KopsNodeMixedInstancesPolicy() *MixedInstancesPolicy {
+	if request.NodeMixedInstancesPolicy == nil {
+		return nil
+	}
+
+	return request.NodeMixedInstancesPolicy.ToMixedInstancesPolicy()
+}
+
 // NewCreateClusterRequestFromReader will create a CreateClusterRequest from an
 // io.Reader with JSON data.
 func NewCreateClusterRequestFromReader(reader io.Reader) (*CreateClusterRequest, error) {
@@ -116,6 +355,102 @@ func NewCreateClusterRequestFromReader(reader io.Reader) (*CreateClusterRequest,
 	return &createClusterRequest, nil
 }
 
+// ImportClusterRequest specifies the parameters to import an existing cluster
+// that was created outside of the provisioner. Either a kops cluster name or
+// a kubeconfig must be provided.
+type ImportClusterRequest struct {
+	Provider               string            `json:"provider,omitempty"`
+	KopsName               string            `json:"kops-name,omitempty"`
+	KopsStateStore         string            `json:"kops-state-store,omitempty"`
+	Kubeconfig             string            `json:"kubeconfig,omitempty"`
+	AllowInstallations     bool              `json:"allow-installations,omitempty"`
+	APISecurityLock        bool              `json:"api-security-lock,omitempty"`
+	DesiredUtilityVersions map[string]string `json:"utility-versions,omitempty"`
+	Annotations            []string          `json:"annotations,omitempty"`
+}
+
+// SetDefaults sets the default values for a cluster import request.
+func (request *ImportClusterRequest) SetDefaults() {
+	if len(request.Provider) == 0 {
+		request.Provider = ProviderAWS
+	}
+	if request.DesiredUtilityVersions == nil {
+		request.DesiredUtilityVersions = make(map[string]string)
+	}
+	if _, ok := request.DesiredUtilityVersions[PrometheusOperatorCanonicalName]; !ok {
+		request.DesiredUtilityVersions[PrometheusOperatorCanonicalName] = PrometheusOperatorDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[ThanosCanonicalName]; !ok {
+		request.DesiredUtilityVersions[ThanosCanonicalName] = ThanosDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[NginxCanonicalName]; !ok {
+		request.DesiredUtilityVersions[NginxCanonicalName] = NginxDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[FluentbitCanonicalName]; !ok {
+		request.DesiredUtilityVersions[FluentbitCanonicalName] = FluentbitDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[TeleportCanonicalName]; !ok {
+		request.DesiredUtilityVersions[TeleportCanonicalName] = TeleportDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[VeleroCanonicalName]; !ok {
+		request.DesiredUtilityVersions[VeleroCanonicalName] = VeleroDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[CertManagerCanonicalName]; !ok {
+		request.DesiredUtilityVersions[CertManagerCanonicalName] = CertManagerDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[ClusterAutoscalerCanonicalName]; !ok {
+		request.DesiredUtilityVersions[ClusterAutoscalerCanonicalName] = ClusterAutoscalerDefaultVersion
+	}
+	if _, ok := request.DesiredUtilityVersions[NodeTerminationHandlerCanonicalName]; !ok {
+		request.DesiredUtilityVersions[NodeTerminationHandlerCanonicalName] = NodeTerminationHandlerDefaultVersion
+	}
+}
+
+// Validate validates the values of a cluster import request.
+func (request *ImportClusterRequest) Validate() error {
+	if request.Provider != ProviderAWS {
+		return errors.Errorf("unsupported provider %s", request.Provider)
+	}
+	if len(request.KopsName) == 0 && len(request.Kubeconfig) == 0 {
+		return errors.New("either a kops name or a kubeconfig must be provided")
+	}
+	if len(request.KopsName) != 0 && len(request.Kubeconfig) != 0 {
+		return errors.New("only one of kops name or kubeconfig may be provided")
+	}
+	if len(request.KopsStateStore) != 0 && len(request.KopsName) == 0 {
+		return errors.New("kops state store requires a kops name")
+	}
+
+	return nil
+}
+
+// Provisioner returns the provisioner managing the imported cluster.
+func (request *ImportClusterRequest) Provisioner() string {
+	if len(request.KopsName) != 0 {
+		return ProvisionerKops
+	}
+
+	return ProvisionerExternal
+}
+
+// NewImportClusterRequestFromReader will create an ImportClusterRequest from
+// an io.Reader with JSON data.
+func NewImportClusterRequestFromReader(reader io.Reader) (*ImportClusterRequest, error) {
+	var importClusterRequest ImportClusterRequest
+	err := json.NewDecoder(reader).Decode(&importClusterRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode import cluster request")
+	}
+
+	importClusterRequest.SetDefaults()
+	err = importClusterRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "import cluster request failed validation")
+	}
+
+	return &importClusterRequest, nil
+}
+
 // GetClustersRequest describes the parameters to request a list of clusters.
 type GetClustersRequest struct {
 	Page           int
@@ -153,6 +488,16 @@ func NewUpdateClusterRequestFromReader(reader io.Reader) (*UpdateClusterRequest,
 type PatchUpgradeClusterRequest struct {
 	Version *string `json:"version,omitempty"`
 	KopsAMI *string `json:"kops-ami,omitempty"`
+	// Strategy rolls the instance groups in stages with health checks in
+	// between instead of rolling the whole cluster at once.
+	Strategy *UpgradeStrategyRequest `json:"strategy,omitempty"`
+}
+
+// SetDefaults sets the default values for a cluster upgrade request.
+func (p *PatchUpgradeClusterRequest) SetDefaults() {
+	if p.Strategy != nil {
+		p.Strategy.SetDefaults()
+	}
 }
 
 // Validate validates the values of a cluster upgrade request.
@@ -160,6 +505,12 @@ func (p *PatchUpgradeClusterRequest) Validate() error {
 	if p.Version != nil && !ValidClusterVersion(*p.Version) {
 		return errors.Errorf("unsupported cluster version %s", *p.Version)
 	}
+	if p.Strategy != nil {
+		err := p.Strategy.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid upgrade strategy")
+		}
+	}
 
 	return nil
 }
@@ -179,12 +530,40 @@ func (p *PatchUpgradeClusterRequest) Apply(metadata *KopsMetadata) bool {
 	}
 
 	if applied {
+		if p.Strategy != nil {
+			changes.UpgradeStrategy = p.Strategy.ToKopsUpgradeStrategy()
+			changes.UpgradeProgress = &KopsUpgradeProgress{}
+		}
 		metadata.ChangeRequest = changes
 	}
 
 	return applied
 }
 
+// ValidateEKS validates that the upgrade can be applied to an EKS cluster.
+func (p *PatchUpgradeClusterRequest) ValidateEKS() error {
+	if p.KopsAMI != nil {
+		return errors.New("kops AMI is not supported by the eks provisioner")
+	}
+	if p.Strategy != nil {
+		return errors.New("upgrade strategies are not supported by the eks provisioner")
+	}
+
+	return nil
+}
+
+// ApplyEKS applies the patch to the given cluster's EKS metadata.
+func (p *PatchUpgradeClusterRequest) ApplyEKS(metadata *EKSMetadata) bool {
+	if p.Version != nil && *p.Version != metadata.Version {
+		metadata.ChangeRequest = &EKSMetadataRequestedState{
+			Version: *p.Version,
+		}
+		return true
+	}
+
+	return false
+}
+
 // NewUpgradeClusterRequestFromReader will create an UpgradeClusterRequest from an io.Reader with JSON data.
 func NewUpgradeClusterRequestFromReader(reader io.Reader) (*PatchUpgradeClusterRequest, error) {
 	var upgradeClusterRequest PatchUpgradeClusterRequest
@@ -193,6 +572,8 @@ func NewUpgradeClusterRequestFromReader(reader io.Reader) (*PatchUpgradeClusterR
 		return nil, errors.Wrap(err, "failed to decode upgrade cluster request")
 	}
 
+	upgradeClusterRequest.SetDefaults()
+
 	err = upgradeClusterRequest.Validate()
 	if err != nil {
 		return nil, errors.Wrap(err, "upgrade cluster request failed validation")
@@ -206,6 +587,23 @@ type PatchClusterSizeRequest struct {
 	NodeInstanceType *string `json:"node-instance-type,omitempty"`
 	NodeMinCount     *int64  `json:"node-min-count,omitempty"`
 	NodeMaxCount     *int64  `json:"node-max-count,omitempty"`
+	// NodeGroups is the complete list of desired additional node groups.
+	// Groups not in the list are deleted. A nil list leaves the node groups
+	// unchanged.
+	NodeGroups []*NodeGroupRequest `json:"node-groups"`
+	// NodeMixedInstancesPolicy replaces the mixed instances policy of the
+	// default node instance group. A policy without instance types removes
+	// the existing policy.
+	NodeMixedInstancesPolicy *MixedInstancesPolicyRequest `json:"node-mixed-instances-policy,omitempty"`
+}
+
+// SetDefaults sets the default values for a PatchClusterSizeRequest.
+func (p *PatchClusterSizeRequest) SetDefaults() {
+	for _, nodeGroup := range p.NodeGroups {
+		if nodeGroup != nil {
+			nodeGroup.SetDefaults()
+		}
+	}
 }
 
 // Validate validates the values of a PatchClusterSizeRequest.
@@ -220,6 +618,37 @@ func (p *PatchClusterSizeRequest) Validate() error {
 		*p.NodeMaxCount < *p.NodeMinCount {
 		return errors.Errorf("node max count (%d) can't be less than min count (%d)", *p.NodeMaxCount, *p.NodeMinCount)
 	}
+	err := validateNodeGroupRequests(p.NodeGroups)
+	if err != nil {
+		return err
+	}
+	if p.NodeMixedInstancesPolicy != nil {
+		err = p.NodeMixedInstancesPolicy.Validate()
+		if err != nil {
+			return err
+		}
+		if p.NodeInstanceType != nil {
+			err = p.NodeMixedInstancesPolicy.ValidateNodeInstanceType(*p.NodeInstanceType)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateNodeInstanceType checks that a node instance type change doesn't
+// conflict with the current mixed instances policy of the default node
+// instance group, which determines the machine type of the group.
+func (p *PatchClusterSizeRequest) ValidateNodeInstanceType(current *MixedInstancesPolicy) error {
+	if p.NodeInstanceType == nil || p.NodeMixedInstancesPolicy != nil ||
+		current == nil || len(current.Instances) == 0 {
+		return nil
+	}
+	if *p.NodeInstanceType != current.Instances[0] {
+		return errors.Errorf("node instance type %s conflicts with the mixed instances policy instance types %v; update or remove the policy in the same request", *p.NodeInstanceType, current.Instances)
+	}
 
 	return nil
 }
@@ -228,6 +657,73 @@ func (p *PatchClusterSizeRequest) Validate() error {
 func (p *PatchClusterSizeRequest) Apply(metadata *KopsMetadata) bool {
 	changes := &KopsMetadataRequestedState{}
 
+	var applied bool
+	if p.NodeInstanceType != nil && *p.NodeInstanceType != metadata.NodeInstanceType {
+		applied = true
+		changes.NodeInstanceType = *p.NodeInstanceType
+	}
+	if p.NodeMinCount != nil && *p.NodeMinCount != metadata.NodeMinCount {
+		applied = true
+		changes.NodeMinCount = *p.NodeMinCount
+	}
+	if p.NodeMaxCount != nil && *p.NodeMaxCount != metadata.NodeMaxCount {
+		applied = true
+		changes.NodeMaxCount = *p.NodeMaxCount
+	}
+	if p.NodeGroups != nil {
+		desired := make(map[string]bool)
+		for _, request := range p.NodeGroups {
+			desired[request.Name] = true
+			nodeGroup := request.ToKopsNodeGroup()
+			existing := metadata.GetNodeGroup(request.Name)
+			if existing == nil || *existing != *nodeGroup {
+				applied = true
+				changes.NodeGroups = append(changes.NodeGroups, nodeGroup)
+			}
+		}
+		for _, nodeGroup := range metadata.NodeGroups {
+			if !desired[nodeGroup.Name] {
+				applied = true
+				changes.DeleteNodeGroups = append(changes.DeleteNodeGroups, nodeGroup.Name)
+			}
+		}
+	}
+	if p.NodeMixedInstancesPolicy != nil {
+		policy := p.NodeMixedInstancesPolicy.ToMixedInstancesPolicy()
+		if policy.IsRemoval() {
+			if metadata.NodeMixedInstancesPolicy != nil {
+				applied = true
+				changes.NodeMixedInstancesPolicy = policy
+			}
+		} else if !policy.Equal(metadata.NodeMixedInstancesPolicy) {
+			applied = true
+			changes.NodeMixedInstancesPolicy = policy
+		}
+	}
+
+	if applied {
+		metadata.ChangeRequest = changes
+	}
+
+	return applied
+}
+
+// ValidateEKS validates that the resize can be applied to an EKS cluster.
+func (p *PatchClusterSizeRequest) ValidateEKS() error {
+	if p.NodeGroups != nil {
+		return errors.New("additional node groups are not supported by the eks provisioner")
+	}
+	if p.NodeMixedInstancesPolicy != nil {
+		return errors.New("mixed instances policies are not supported by the eks provisioner")
+	}
+
+	return nil
+}
+
+// ApplyEKS applies the patch to the given cluster's EKS metadata.
+func (p *PatchClusterSizeRequest) ApplyEKS(metadata *EKSMetadata) bool {
+	changes := &EKSMetadataRequestedState{}
+
 	var applied bool
 	if p.NodeInstanceType != nil && *p.NodeInstanceType != metadata.NodeInstanceType {
 		applied = true
@@ -257,6 +753,8 @@ func NewResizeClusterRequestFromReader(reader io.Reader) (*PatchClusterSizeReque
 		return nil, errors.Wrap(err, "failed to decode resize cluster request")
 	}
 
+	patchClusterSizeRequest.SetDefaults()
+
 	err = patchClusterSizeRequest.Validate()
 	if err != nil {
 		return nil, errors.Wrap(err, "resize cluster request failed validation")
@@ -270,6 +768,72 @@ type ProvisionClusterRequest struct {
 	DesiredUtilityVersions map[string]string `json:"utility-versions,omitempty"`
 }
 
+// UpdateClusterUtilitiesRequest contains the utility configuration changes of
+// a cluster.
+type UpdateClusterUtilitiesRequest struct {
+	// ValuesOverrides are Helm values keyed by utility name. An empty value
+	// removes the override of the utility.
+	ValuesOverrides map[string]string `json:"utility-values,omitempty"`
+}
+
+// Validate validates the values of a cluster utilities update request.
+func (request *UpdateClusterUtilitiesRequest) Validate() error {
+	if len(request.ValuesOverrides) == 0 {
+		return errors.New("must specify at least one utility values override")
+	}
+
+	return ValidateUtilityValuesOverrides(request.ValuesOverrides)
+}
+
+// NewUpdateClusterUtilitiesRequestFromReader will create an
+// UpdateClusterUtilitiesRequest from an io.Reader with JSON data.
+func NewUpdateClusterUtilitiesRequestFromReader(reader io.Reader) (*UpdateClusterUtilitiesRequest, error) {
+	var updateClusterUtilitiesRequest UpdateClusterUtilitiesRequest
+	err := json.NewDecoder(reader).Decode(&updateClusterUtilitiesRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode update cluster utilities request")
+	}
+
+	err = updateClusterUtilitiesRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "update cluster utilities request failed validation")
+	}
+
+	return &updateClusterUtilitiesRequest, nil
+}
+
+// UpgradeClusterUtilityRequest specifies the version a single cluster
+// utility should be upgraded or rolled back to.
+type UpgradeClusterUtilityRequest struct {
+	Version string `json:"version"`
+}
+
+// Validate validates the values of a cluster utility upgrade request.
+func (request *UpgradeClusterUtilityRequest) Validate() error {
+	if len(request.Version) == 0 {
+		return errors.New("must specify a utility version")
+	}
+
+	return nil
+}
+
+// NewUpgradeClusterUtilityRequestFromReader will create an
+// UpgradeClusterUtilityRequest from an io.Reader with JSON data.
+func NewUpgradeClusterUtilityRequestFromReader(reader io.Reader) (*UpgradeClusterUtilityRequest, error) {
+	var upgradeClusterUtilityRequest UpgradeClusterUtilityRequest
+	err := json.NewDecoder(reader).Decode(&upgradeClusterUtilityRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode upgrade cluster utility request")
+	}
+
+	err = upgradeClusterUtilityRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "upgrade cluster utility request failed validation")
+	}
+
+	return &upgradeClusterUtilityRequest, nil
+}
+
 // NewProvisionClusterRequestFromReader will create an UpdateClusterRequest from an io.Reader with JSON data.
 func NewProvisionClusterRequestFromReader(reader io.Reader) (*ProvisionClusterRequest, error) {
 	var provisionClusterRequest ProvisionClusterRequest
@@ -23,6 +23,41 @@ func TestCreateClusterRequestValid(t *testing.T) {
 		{"negative node counts", &model.CreateClusterRequest{NodeMinCount: -1, NodeMaxCount: -1}, true},
 		{"negative master count", &model.CreateClusterRequest{MasterCount: -1}, true},
 		{"mismatched node count", &model.CreateClusterRequest{NodeMinCount: 2, NodeMaxCount: 3}, true},
+		{"autoscaling", &model.CreateClusterRequest{NodeMinCount: 2, NodeMaxCount: 6, Autoscaling: true}, false},
+		{"autoscaling without node max count", &model.CreateClusterRequest{NodeMinCount: 2, Autoscaling: true}, true},
+		{"valid node group", &model.CreateClusterRequest{NodeGroups: []*model.NodeGroupRequest{{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2}}}, false},
+		{"valid autoscaling node group", &model.CreateClusterRequest{NodeGroups: []*model.NodeGroupRequest{{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 4}}}, false},
+		{"reserved node group name", &model.CreateClusterRequest{NodeGroups: []*model.NodeGroupRequest{{Name: "nodes", InstanceType: "r5.xlarge", MinCount: 2}}}, true},
+		{"invalid node group name", &model.CreateClusterRequest{NodeGroups: []*model.NodeGroupRequest{{Name: "Memory_Pool", InstanceType: "r5.xlarge", MinCount: 2}}}, true},
+		{"blank node group instance type", &model.CreateClusterRequest{NodeGroups: []*model.NodeGroupRequest{{Name: "memory", MinCount: 2}}}, true},
+		{"zero node group count", &model.CreateClusterRequest{NodeGroups: []*model.NodeGroupRequest{{Name: "memory", InstanceType: "r5.xlarge"}}}, true},
+		{"duplicate node groups", &model.CreateClusterRequest{NodeGroups: []*model.NodeGroupRequest{
+			{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2},
+			{Name: "memory", InstanceType: "r5.large", MinCount: 2},
+		}}, true},
+		{"valid mixed instances policy", &model.CreateClusterRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{"t3.medium", "t3a.medium"}, SpotPercentage: 100}}, false},
+		{"mixed instances policy without instance types", &model.CreateClusterRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{}}, true},
+		{"mixed instances policy invalid spot percentage", &model.CreateClusterRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{"t3.medium"}, SpotPercentage: 101}}, true},
+		{"mixed instances policy negative on-demand base", &model.CreateClusterRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{"t3.medium"}, OnDemandBase: -1}}, true},
+		{"mixed instances policy with matching node instance type", &model.CreateClusterRequest{NodeInstanceType: "t3.medium", NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{"t3.medium", "t3a.medium"}, SpotPercentage: 100}}, false},
+		{"mixed instances policy with conflicting node instance type", &model.CreateClusterRequest{NodeInstanceType: "m5.large", NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{"t3.medium", "t3a.medium"}, SpotPercentage: 100}}, true},
+		{"invalid provisioner", &model.CreateClusterRequest{Provisioner: "gke"}, true},
+		{"eks defaults", &model.CreateClusterRequest{Provisioner: model.ProvisionerEKS}, false},
+		{"eks single zone", &model.CreateClusterRequest{Provisioner: model.ProvisionerEKS, Zones: []string{"us-east-1a"}}, true},
+		{"eks kops ami", &model.CreateClusterRequest{Provisioner: model.ProvisionerEKS, KopsAMI: "ami-1"}, true},
+		{"eks autoscaling", &model.CreateClusterRequest{Provisioner: model.ProvisionerEKS, NodeMinCount: 2, NodeMaxCount: 6, Autoscaling: true}, true},
+		{"eks node groups", &model.CreateClusterRequest{Provisioner: model.ProvisionerEKS, NodeGroups: []*model.NodeGroupRequest{{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2}}}, true},
+		{"region", &model.CreateClusterRequest{Region: "eu-central-1"}, false},
+		{"eks region", &model.CreateClusterRequest{Provisioner: model.ProvisionerEKS, Region: "eu-central-1"}, false},
+		{"region with zones", &model.CreateClusterRequest{Region: "eu-central-1", Zones: []string{"eu-central-1a", "eu-central-1b"}}, false},
+		{"invalid region", &model.CreateClusterRequest{Region: "europe"}, true},
+		{"zone outside of region", &model.CreateClusterRequest{Region: "eu-central-1", Zones: []string{"us-east-1a"}}, true},
+		{"valid assume role", &model.CreateClusterRequest{AssumeRoleARN: "arn:aws:iam::123456789012:role/provisioner"}, false},
+		{"invalid assume role", &model.CreateClusterRequest{AssumeRoleARN: "provisioner"}, true},
+		{"valid utility values", &model.CreateClusterRequest{UtilityValuesOverrides: map[string]string{model.NginxCanonicalName: "controller:\n  replicaCount: 3\n"}}, false},
+		{"unknown utility values", &model.CreateClusterRequest{UtilityValuesOverrides: map[string]string{"traefik": "replicas: 3"}}, true},
+		{"invalid utility values", &model.CreateClusterRequest{UtilityValuesOverrides: map[string]string{model.NginxCanonicalName: "controller: ["}}, true},
+		{"utility values set by the provisioner", &model.CreateClusterRequest{UtilityValuesOverrides: map[string]string{model.VeleroCanonicalName: "configuration:\n  backupStorageLocation:\n    bucket: other\n"}}, true},
 	}
 
 	for _, tc := range testCases {
@@ -38,6 +73,85 @@ func TestCreateClusterRequestValid(t *testing.T) {
 	}
 }
 
+func TestImportClusterRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		request      *model.ImportClusterRequest
+		requireError bool
+	}{
+		{"empty", &model.ImportClusterRequest{}, true},
+		{"kops name", &model.ImportClusterRequest{KopsName: "cluster.k8s.local"}, false},
+		{"kops name and state store", &model.ImportClusterRequest{KopsName: "cluster.k8s.local", KopsStateStore: "other-state-store"}, false},
+		{"kubeconfig", &model.ImportClusterRequest{Kubeconfig: "apiVersion: v1"}, false},
+		{"kops name and kubeconfig", &model.ImportClusterRequest{KopsName: "cluster.k8s.local", Kubeconfig: "apiVersion: v1"}, true},
+		{"state store without kops name", &model.ImportClusterRequest{KopsStateStore: "other-state-store", Kubeconfig: "apiVersion: v1"}, true},
+		{"invalid provider", &model.ImportClusterRequest{Provider: "blah", KopsName: "cluster.k8s.local"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			tc.request.SetDefaults()
+
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+
+	assert.Equal(t, model.ProvisionerKops, (&model.ImportClusterRequest{KopsName: "cluster.k8s.local"}).Provisioner())
+	assert.Equal(t, model.ProvisionerExternal, (&model.ImportClusterRequest{Kubeconfig: "apiVersion: v1"}).Provisioner())
+}
+
+func TestUpdateClusterUtilitiesRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		request      *model.UpdateClusterUtilitiesRequest
+		requireError bool
+	}{
+		{"empty", &model.UpdateClusterUtilitiesRequest{}, true},
+		{"valid values", &model.UpdateClusterUtilitiesRequest{ValuesOverrides: map[string]string{model.NginxCanonicalName: "controller:\n  replicaCount: 3\n"}}, false},
+		{"remove values", &model.UpdateClusterUtilitiesRequest{ValuesOverrides: map[string]string{model.NginxCanonicalName: ""}}, false},
+		{"unknown utility", &model.UpdateClusterUtilitiesRequest{ValuesOverrides: map[string]string{"traefik": "replicas: 3"}}, true},
+		{"not a map", &model.UpdateClusterUtilitiesRequest{ValuesOverrides: map[string]string{model.NginxCanonicalName: "- a\n- b\n"}}, true},
+		{"annotation set by the provisioner", &model.UpdateClusterUtilitiesRequest{ValuesOverrides: map[string]string{model.NginxCanonicalName: "controller:\n  service:\n    annotations:\n      service.beta.kubernetes.io/aws-load-balancer-ssl-cert: arn\n"}}, true},
+		{"sibling of a value set by the provisioner", &model.UpdateClusterUtilitiesRequest{ValuesOverrides: map[string]string{model.NginxCanonicalName: "controller:\n  service:\n    annotations:\n      service.beta.kubernetes.io/aws-load-balancer-type: nlb\n"}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
+
+func TestUpgradeClusterUtilityRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		request      *model.UpgradeClusterUtilityRequest
+		requireError bool
+	}{
+		{"empty", &model.UpgradeClusterUtilityRequest{}, true},
+		{"valid version", &model.UpgradeClusterUtilityRequest{Version: "2.16.0"}, false},
+		{"stable", &model.UpgradeClusterUtilityRequest{Version: "stable"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
+
 func TestUpgradeClusterRequestValid(t *testing.T) {
 	var testCases = []struct {
 		testName     string
@@ -48,6 +162,9 @@ func TestUpgradeClusterRequestValid(t *testing.T) {
 		{"valid version", &model.PatchUpgradeClusterRequest{Version: sToP("1.15.2")}, false},
 		{"invalid version", &model.PatchUpgradeClusterRequest{Version: sToP("invalid")}, true},
 		{"blank version", &model.PatchUpgradeClusterRequest{Version: sToP("")}, true},
+		{"valid strategy", &model.PatchUpgradeClusterRequest{Version: sToP("1.15.2"), Strategy: &model.UpgradeStrategyRequest{MaxSurge: 1, BatchSize: 2, PauseSeconds: 60}}, false},
+		{"invalid strategy batch size", &model.PatchUpgradeClusterRequest{Version: sToP("1.15.2"), Strategy: &model.UpgradeStrategyRequest{BatchSize: 0}}, true},
+		{"invalid strategy max surge", &model.PatchUpgradeClusterRequest{Version: sToP("1.15.2"), Strategy: &model.UpgradeStrategyRequest{MaxSurge: -1, BatchSize: 1}}, true},
 	}
 
 	for _, tc := range testCases {
@@ -129,6 +246,37 @@ func TestUpgradeClusterRequestApply(t *testing.T) {
 				},
 			},
 		},
+		{
+			"version with strategy",
+			true,
+			&model.PatchUpgradeClusterRequest{
+				Version:  sToP("version1"),
+				Strategy: &model.UpgradeStrategyRequest{MaxSurge: 1, BatchSize: 2, PauseSeconds: 60},
+			},
+			&model.KopsMetadata{
+				ChangeRequest: &model.KopsMetadataRequestedState{},
+			},
+			&model.KopsMetadata{
+				ChangeRequest: &model.KopsMetadataRequestedState{
+					Version:         "version1",
+					UpgradeStrategy: &model.KopsUpgradeStrategy{MaxSurge: 1, BatchSize: 2, PauseSeconds: 60},
+					UpgradeProgress: &model.KopsUpgradeProgress{},
+				},
+			},
+		},
+		{
+			"strategy only",
+			false,
+			&model.PatchUpgradeClusterRequest{
+				Strategy: &model.UpgradeStrategyRequest{BatchSize: 1},
+			},
+			&model.KopsMetadata{
+				ChangeRequest: &model.KopsMetadataRequestedState{},
+			},
+			&model.KopsMetadata{
+				ChangeRequest: &model.KopsMetadataRequestedState{},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -140,6 +288,40 @@ func TestUpgradeClusterRequestApply(t *testing.T) {
 	}
 }
 
+func TestUpgradeClusterRequestApplyEKS(t *testing.T) {
+	metadata := &model.EKSMetadata{Version: "1.16"}
+
+	request := &model.PatchUpgradeClusterRequest{Version: sToP("1.16")}
+	assert.NoError(t, request.ValidateEKS())
+	assert.False(t, request.ApplyEKS(metadata))
+	assert.Nil(t, metadata.ChangeRequest)
+
+	request = &model.PatchUpgradeClusterRequest{Version: sToP("1.17")}
+	assert.True(t, request.ApplyEKS(metadata))
+	assert.Equal(t, &model.EKSMetadataRequestedState{Version: "1.17"}, metadata.ChangeRequest)
+
+	request = &model.PatchUpgradeClusterRequest{KopsAMI: sToP("image1")}
+	assert.Error(t, request.ValidateEKS())
+}
+
+func TestResizeClusterRequestApplyEKS(t *testing.T) {
+	metadata := &model.EKSMetadata{NodeInstanceType: "m5.large", NodeMinCount: 2, NodeMaxCount: 2}
+	nodeCount := int64(2)
+	newNodeCount := int64(4)
+
+	request := &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.large"), NodeMinCount: &nodeCount}
+	assert.NoError(t, request.ValidateEKS())
+	assert.False(t, request.ApplyEKS(metadata))
+	assert.Nil(t, metadata.ChangeRequest)
+
+	request = &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.xlarge"), NodeMinCount: &newNodeCount, NodeMaxCount: &newNodeCount}
+	assert.True(t, request.ApplyEKS(metadata))
+	assert.Equal(t, &model.EKSMetadataRequestedState{NodeInstanceType: "m5.xlarge", NodeMinCount: 4, NodeMaxCount: 4}, metadata.ChangeRequest)
+
+	request = &model.PatchClusterSizeRequest{NodeGroups: []*model.NodeGroupRequest{}}
+	assert.Error(t, request.ValidateEKS())
+}
+
 func TestResizeClusterRequestValid(t *testing.T) {
 	var testCases = []struct {
 		testName     string
@@ -151,6 +333,16 @@ func TestResizeClusterRequestValid(t *testing.T) {
 		{"blank node type", &model.PatchClusterSizeRequest{NodeInstanceType: sToP("")}, true},
 		{"zero nodes", &model.PatchClusterSizeRequest{NodeMinCount: i64oP(0), NodeMaxCount: i64oP(0)}, true},
 		{"max lower than min", &model.PatchClusterSizeRequest{NodeMinCount: i64oP(5), NodeMaxCount: i64oP(2)}, true},
+		{"valid node groups", &model.PatchClusterSizeRequest{NodeGroups: []*model.NodeGroupRequest{{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 2}}}, false},
+		{"empty node groups", &model.PatchClusterSizeRequest{NodeGroups: []*model.NodeGroupRequest{}}, false},
+		{"node group max lower than min", &model.PatchClusterSizeRequest{NodeGroups: []*model.NodeGroupRequest{{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 1}}}, true},
+		{"valid mixed instances policy", &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{"t3.medium"}, SpotPercentage: 50}}, false},
+		{"remove mixed instances policy", &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{}}, false},
+		{"mixed instances policy blank instance type", &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{""}}}, true},
+		{"mixed instances policy without instance types", &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{SpotPercentage: 50}}, true},
+		{"mixed instances policy with matching node instance type", &model.PatchClusterSizeRequest{NodeInstanceType: sToP("t3.medium"), NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{"t3.medium"}, SpotPercentage: 50}}, false},
+		{"mixed instances policy with conflicting node instance type", &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.large"), NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{Instances: []string{"t3.medium"}, SpotPercentage: 50}}, true},
+		{"node instance type with mixed instances policy removal", &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.large"), NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{}}, false},
 	}
 
 	for _, tc := range testCases {
@@ -163,3 +355,117 @@ func TestResizeClusterRequestValid(t *testing.T) {
 		})
 	}
 }
+
+func TestResizeClusterRequestApplyNodeGroups(t *testing.T) {
+	memory := &model.KopsNodeGroup{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 2}
+	spot := &model.KopsNodeGroup{Name: "spot", InstanceType: "m5.large", MinCount: 1, MaxCount: 3}
+
+	t.Run("nil node groups are unchanged", func(t *testing.T) {
+		metadata := &model.KopsMetadata{NodeGroups: []*model.KopsNodeGroup{memory}}
+		request := &model.PatchClusterSizeRequest{}
+		assert.False(t, request.Apply(metadata))
+		assert.Nil(t, metadata.ChangeRequest)
+	})
+
+	t.Run("same node groups", func(t *testing.T) {
+		metadata := &model.KopsMetadata{NodeGroups: []*model.KopsNodeGroup{memory}}
+		request := &model.PatchClusterSizeRequest{NodeGroups: []*model.NodeGroupRequest{
+			{Name: "memory", InstanceType: "r5.xlarge", MinCount: 2, MaxCount: 2},
+		}}
+		assert.False(t, request.Apply(metadata))
+	})
+
+	t.Run("create, resize and delete", func(t *testing.T) {
+		metadata := &model.KopsMetadata{NodeGroups: []*model.KopsNodeGroup{memory, spot}}
+		request := &model.PatchClusterSizeRequest{NodeGroups: []*model.NodeGroupRequest{
+			{Name: "memory", InstanceType: "r5.2xlarge", MinCount: 2, MaxCount: 2},
+			{Name: "batch", InstanceType: "c5.large", MinCount: 1, MaxCount: 1},
+		}}
+		assert.True(t, request.Apply(metadata))
+		assert.Equal(t, &model.KopsMetadataRequestedState{
+			NodeGroups: []*model.KopsNodeGroup{
+				{Name: "memory", InstanceType: "r5.2xlarge", MinCount: 2, MaxCount: 2},
+				{Name: "batch", InstanceType: "c5.large", MinCount: 1, MaxCount: 1},
+			},
+			DeleteNodeGroups: []string{"spot"},
+		}, metadata.ChangeRequest)
+	})
+
+	t.Run("delete all", func(t *testing.T) {
+		metadata := &model.KopsMetadata{NodeGroups: []*model.KopsNodeGroup{memory, spot}}
+		request := &model.PatchClusterSizeRequest{NodeGroups: []*model.NodeGroupRequest{}}
+		assert.True(t, request.Apply(metadata))
+		assert.Equal(t, []string{"memory", "spot"}, metadata.ChangeRequest.DeleteNodeGroups)
+	})
+}
+
+func TestResizeClusterRequestApplyMixedInstancesPolicy(t *testing.T) {
+	policy := &model.MixedInstancesPolicy{Instances: []string{"t3.medium", "t3a.medium"}, SpotPercentage: 100}
+
+	t.Run("set policy", func(t *testing.T) {
+		metadata := &model.KopsMetadata{}
+		request := &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{
+			Instances:      []string{"t3.medium", "t3a.medium"},
+			SpotPercentage: 100,
+		}}
+		assert.True(t, request.Apply(metadata))
+		assert.Equal(t, policy, metadata.ChangeRequest.NodeMixedInstancesPolicy)
+	})
+
+	t.Run("same policy", func(t *testing.T) {
+		metadata := &model.KopsMetadata{NodeMixedInstancesPolicy: policy}
+		request := &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{
+			Instances:      []string{"t3.medium", "t3a.medium"},
+			SpotPercentage: 100,
+		}}
+		assert.False(t, request.Apply(metadata))
+	})
+
+	t.Run("change policy", func(t *testing.T) {
+		metadata := &model.KopsMetadata{NodeMixedInstancesPolicy: policy}
+		request := &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{
+			Instances:      []string{"t3.medium", "t3a.medium"},
+			OnDemandBase:   1,
+			SpotPercentage: 100,
+		}}
+		assert.True(t, request.Apply(metadata))
+		assert.Equal(t, int64(1), metadata.ChangeRequest.NodeMixedInstancesPolicy.OnDemandBase)
+	})
+
+	t.Run("remove policy", func(t *testing.T) {
+		metadata := &model.KopsMetadata{NodeMixedInstancesPolicy: policy}
+		request := &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{}}
+		assert.True(t, request.Apply(metadata))
+		assert.True(t, metadata.ChangeRequest.NodeMixedInstancesPolicy.IsRemoval())
+	})
+
+	t.Run("remove missing policy", func(t *testing.T) {
+		metadata := &model.KopsMetadata{}
+		request := &model.PatchClusterSizeRequest{NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{}}
+		assert.False(t, request.Apply(metadata))
+	})
+}
+
+func TestResizeClusterRequestValidateNodeInstanceType(t *testing.T) {
+	policy := &model.MixedInstancesPolicy{Instances: []string{"t3.medium", "t3a.medium"}, SpotPercentage: 100}
+
+	t.Run("no policy", func(t *testing.T) {
+		request := &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.large")}
+		assert.NoError(t, request.ValidateNodeInstanceType(nil))
+	})
+
+	t.Run("instance type of the policy", func(t *testing.T) {
+		request := &model.PatchClusterSizeRequest{NodeInstanceType: sToP("t3.medium")}
+		assert.NoError(t, request.ValidateNodeInstanceType(policy))
+	})
+
+	t.Run("conflicting instance type", func(t *testing.T) {
+		request := &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.large")}
+		assert.Error(t, request.ValidateNodeInstanceType(policy))
+	})
+
+	t.Run("conflicting instance type with new policy", func(t *testing.T) {
+		request := &model.PatchClusterSizeRequest{NodeInstanceType: sToP("m5.large"), NodeMixedInstancesPolicy: &model.MixedInstancesPolicyRequest{}}
+		assert.NoError(t, request.ValidateNodeInstanceType(policy))
+	})
+}
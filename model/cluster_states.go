@@ -22,6 +22,19 @@ const (
 	ClusterStateUpgradeRequested = "upgrade-requested"
 	// ClusterStateUpgradeFailed is a cluster that failed to upgrade.
 	ClusterStateUpgradeFailed = "upgrade-failed"
+	// ClusterStateUpgradePaused is a cluster with a staged upgrade that was
+	// halted because the cluster health degraded. The upgrade can be resumed
+	// or aborted.
+	ClusterStateUpgradePaused = "upgrade-paused"
+	// ClusterStateUpgradeAbortRequested is a cluster with a paused or failed
+	// staged upgrade that is being reverted to its previous version.
+	ClusterStateUpgradeAbortRequested = "upgrade-abort-requested"
+	// ClusterStateUtilityUpgradeRequested is a cluster in the process of
+	// upgrading or rolling back a single utility.
+	ClusterStateUtilityUpgradeRequested = "utility-upgrade-requested"
+	// ClusterStateUtilityUpgradeFailed is a cluster that failed to upgrade a
+	// single utility.
+	ClusterStateUtilityUpgradeFailed = "utility-upgrade-failed"
 	// ClusterStateResizeRequested is a cluster in the process of resizing.
 	ClusterStateResizeRequested = "resize-requested"
 	// ClusterStateResizeFailed is a cluster that failed to resize.
@@ -46,6 +59,10 @@ var AllClusterStates = []string{
 	ClusterStateProvisioningFailed,
 	ClusterStateUpgradeRequested,
 	ClusterStateUpgradeFailed,
+	ClusterStateUpgradePaused,
+	ClusterStateUpgradeAbortRequested,
+	ClusterStateUtilityUpgradeRequested,
+	ClusterStateUtilityUpgradeFailed,
 	ClusterStateResizeRequested,
 	ClusterStateResizeFailed,
 	ClusterStateDeletionRequested,
@@ -63,6 +80,8 @@ var AllClusterStatesPendingWork = []string{
 	ClusterStateProvisioningRequested,
 	ClusterStateRefreshMetadata,
 	ClusterStateUpgradeRequested,
+	ClusterStateUpgradeAbortRequested,
+	ClusterStateUtilityUpgradeRequested,
 	ClusterStateResizeRequested,
 	ClusterStateDeletionRequested,
 }
@@ -76,6 +95,8 @@ var AllClusterRequestStates = []string{
 	ClusterStateCreationRequested,
 	ClusterStateProvisioningRequested,
 	ClusterStateUpgradeRequested,
+	ClusterStateUpgradeAbortRequested,
+	ClusterStateUtilityUpgradeRequested,
 	ClusterStateResizeRequested,
 	ClusterStateDeletionRequested,
 }
@@ -90,6 +111,10 @@ func (c *Cluster) ValidTransitionState(newState string) bool {
 		return validTransitionToClusterStateProvisioningRequested(c.State)
 	case ClusterStateUpgradeRequested:
 		return validTransitionToClusterStateUpgradeRequested(c.State)
+	case ClusterStateUpgradeAbortRequested:
+		return validTransitionToClusterStateUpgradeAbortRequested(c.State, c.hasUpgradeProgress())
+	case ClusterStateUtilityUpgradeRequested:
+		return validTransitionToClusterStateUtilityUpgradeRequested(c.State)
 	case ClusterStateResizeRequested:
 		return validTransitionToClusterStateResizeRequested(c.State)
 	case ClusterStateDeletionRequested:
@@ -113,7 +138,8 @@ func validTransitionToClusterStateProvisioningRequested(currentState string) boo
 	switch currentState {
 	case ClusterStateStable,
 		ClusterStateProvisioningFailed,
-		ClusterStateProvisioningRequested:
+		ClusterStateProvisioningRequested,
+		ClusterStateUtilityUpgradeFailed:
 		return true
 	}
 
@@ -131,6 +157,39 @@ func validTransitionToClusterStateUpgradeRequested(currentState string) bool {
 	return false
 }
 
+func validTransitionToClusterStateUpgradeAbortRequested(currentState string, upgradeStarted bool) bool {
+	switch currentState {
+	case ClusterStateUpgradePaused,
+		ClusterStateUpgradeAbortRequested:
+		return true
+	case ClusterStateUpgradeFailed:
+		// Staged upgrades that failed part way, and reverts that failed, can
+		// be aborted as well.
+		return upgradeStarted
+	}
+
+	return false
+}
+
+// hasUpgradeProgress returns true if the cluster has a staged upgrade in
+// progress.
+func (c *Cluster) hasUpgradeProgress() bool {
+	return c.ProvisionerMetadataKops != nil &&
+		c.ProvisionerMetadataKops.ChangeRequest != nil &&
+		c.ProvisionerMetadataKops.ChangeRequest.UpgradeProgress != nil
+}
+
+func validTransitionToClusterStateUtilityUpgradeRequested(currentState string) bool {
+	switch currentState {
+	case ClusterStateStable,
+		ClusterStateUtilityUpgradeRequested,
+		ClusterStateUtilityUpgradeFailed:
+		return true
+	}
+
+	return false
+}
+
 func validTransitionToClusterStateResizeRequested(currentState string) bool {
 	switch currentState {
 	case ClusterStateStable,
@@ -150,6 +209,8 @@ func validTransitionToClusterStateDeletionRequested(currentState string) bool {
 		ClusterStateProvisioningFailed,
 		ClusterStateUpgradeRequested,
 		ClusterStateUpgradeFailed,
+		ClusterStateUpgradePaused,
+		ClusterStateUtilityUpgradeFailed,
 		ClusterStateDeletionRequested,
 		ClusterStateDeletionFailed:
 		return true
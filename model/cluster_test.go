@@ -85,6 +85,13 @@ func TestClustersFromReader(t *testing.T) {
 	})
 }
 
+func TestClusterIsImported(t *testing.T) {
+	assert.False(t, (&Cluster{Provisioner: ProvisionerKops, ProvisionerMetadataKops: &KopsMetadata{}}).IsImported())
+	assert.True(t, (&Cluster{Provisioner: ProvisionerKops, ProvisionerMetadataKops: &KopsMetadata{Imported: true}}).IsImported())
+	assert.False(t, (&Cluster{Provisioner: ProvisionerEKS}).IsImported())
+	assert.True(t, (&Cluster{Provisioner: ProvisionerExternal}).IsImported())
+}
+
 func TestValidClusterVersion(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -115,3 +122,19 @@ func TestValidClusterVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterValidTransitionToUpgradeAbortRequested(t *testing.T) {
+	cluster := &Cluster{State: ClusterStateUpgradePaused}
+	assert.True(t, cluster.ValidTransitionState(ClusterStateUpgradeAbortRequested))
+
+	cluster.State = ClusterStateUpgradeFailed
+	assert.False(t, cluster.ValidTransitionState(ClusterStateUpgradeAbortRequested))
+
+	cluster.ProvisionerMetadataKops = &KopsMetadata{
+		ChangeRequest: &KopsMetadataRequestedState{UpgradeProgress: &KopsUpgradeProgress{}},
+	}
+	assert.True(t, cluster.ValidTransitionState(ClusterStateUpgradeAbortRequested))
+
+	cluster.State = ClusterStateStable
+	assert.False(t, cluster.ValidTransitionState(ClusterStateUpgradeAbortRequested))
+}
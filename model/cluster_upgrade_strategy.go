@@ -0,0 +1,134 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// KopsUpgradeStrategy describes how the instance groups of a cluster are
+// rolled during an upgrade. Instance groups are rolled in stages: the master
+// instance groups first, then a single canary node instance group and then the
+// remaining node instance groups in batches. Node and cluster installation
+// health is checked after every stage.
+type KopsUpgradeStrategy struct {
+	// MaxSurge is the number of extra instances each instance group can launch
+	// while its instances are being replaced.
+	MaxSurge int64
+	// BatchSize is the number of instance groups rolled in a single stage.
+	BatchSize int64
+	// PauseSeconds is the time to wait between stages.
+	PauseSeconds int64
+}
+
+// KopsUpgradeProgress tracks the progress of a staged cluster upgrade.
+type KopsUpgradeProgress struct {
+	// SpecApplied is true once the upgraded cluster spec has been applied and
+	// only the rolling updates remain.
+	SpecApplied bool
+	// UpgradedInstanceGroups are the instance groups that were rolled.
+	UpgradedInstanceGroups []string `json:"UpgradedInstanceGroups,omitempty"`
+	// LastStageCompletedAt is the time in milliseconds the last stage was
+	// completed at.
+	LastStageCompletedAt int64 `json:"LastStageCompletedAt,omitempty"`
+}
+
+// IsUpgraded returns true if the instance group was already rolled.
+func (p *KopsUpgradeProgress) IsUpgraded(igName string) bool {
+	for _, upgraded := range p.UpgradedInstanceGroups {
+		if upgraded == igName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextStage returns the instance groups to roll in the next stage of the
+// upgrade or nil if every instance group was rolled.
+func (s *KopsUpgradeStrategy) NextStage(masterIGs, nodeIGs []string, progress *KopsUpgradeProgress) []string {
+	remainingMasters := remainingInstanceGroups(masterIGs, progress)
+	if len(remainingMasters) != 0 {
+		return batchInstanceGroups(remainingMasters, s.BatchSize)
+	}
+
+	remainingNodes := remainingInstanceGroups(nodeIGs, progress)
+	if len(remainingNodes) == 0 {
+		return nil
+	}
+	if len(remainingNodes) == len(nodeIGs) {
+		// Nothing was rolled yet, so start with a single canary node group.
+		return remainingNodes[:1]
+	}
+
+	return batchInstanceGroups(remainingNodes, s.BatchSize)
+}
+
+// NextStageAt returns the time in milliseconds the next stage can start at.
+func (s *KopsUpgradeStrategy) NextStageAt(progress *KopsUpgradeProgress) int64 {
+	if progress.LastStageCompletedAt == 0 {
+		return 0
+	}
+
+	return progress.LastStageCompletedAt + s.PauseSeconds*1000
+}
+
+func remainingInstanceGroups(igNames []string, progress *KopsUpgradeProgress) []string {
+	var remaining []string
+	for _, igName := range igNames {
+		if !progress.IsUpgraded(igName) {
+			remaining = append(remaining, igName)
+		}
+	}
+
+	return remaining
+}
+
+func batchInstanceGroups(igNames []string, batchSize int64) []string {
+	if batchSize < 1 || int64(len(igNames)) <= batchSize {
+		return igNames
+	}
+
+	return igNames[:batchSize]
+}
+
+// UpgradeStrategyRequest specifies the parameters of a staged cluster
+// upgrade.
+type UpgradeStrategyRequest struct {
+	MaxSurge     int64 `json:"max-surge,omitempty"`
+	BatchSize    int64 `json:"batch-size,omitempty"`
+	PauseSeconds int64 `json:"pause-seconds,omitempty"`
+}
+
+// SetDefaults sets the default values for an upgrade strategy request.
+func (request *UpgradeStrategyRequest) SetDefaults() {
+	if request.BatchSize == 0 {
+		request.BatchSize = 1
+	}
+}
+
+// Validate validates the values of an upgrade strategy request.
+func (request *UpgradeStrategyRequest) Validate() error {
+	if request.MaxSurge < 0 {
+		return errors.Errorf("max surge (%d) can't be negative", request.MaxSurge)
+	}
+	if request.BatchSize < 1 {
+		return errors.Errorf("batch size (%d) must be 1 or greater", request.BatchSize)
+	}
+	if request.PauseSeconds < 0 {
+		return errors.Errorf("pause seconds (%d) can't be negative", request.PauseSeconds)
+	}
+
+	return nil
+}
+
+// ToKopsUpgradeStrategy converts the request into a KopsUpgradeStrategy.
+func (request *UpgradeStrategyRequest) ToKopsUpgradeStrategy() *KopsUpgradeStrategy {
+	return &KopsUpgradeStrategy{
+		MaxSurge:     request.MaxSurge,
+		BatchSize:    request.BatchSize,
+		PauseSeconds: request.PauseSeconds,
+	}
+}
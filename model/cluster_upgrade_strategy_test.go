@@ -0,0 +1,47 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKopsUpgradeStrategyNextStage(t *testing.T) {
+	masterIGs := []string{"master-a", "master-b", "master-c"}
+	nodeIGs := []string{"nodes-a", "nodes-b", "nodes-c", "nodes-d"}
+
+	var testCases = []struct {
+		testName      string
+		batchSize     int64
+		upgraded      []string
+		expectedStage []string
+	}{
+		{"first stage", 2, nil, []string{"master-a", "master-b"}},
+		{"remaining masters", 2, []string{"master-a", "master-b"}, []string{"master-c"}},
+		{"canary node group", 2, masterIGs, []string{"nodes-a"}},
+		{"node batch", 2, append(masterIGs, "nodes-a"), []string{"nodes-b", "nodes-c"}},
+		{"last node batch", 2, append(masterIGs, "nodes-a", "nodes-b", "nodes-c"), []string{"nodes-d"}},
+		{"large batch", 10, append(masterIGs, "nodes-a"), []string{"nodes-b", "nodes-c", "nodes-d"}},
+		{"done", 2, append(masterIGs, nodeIGs...), nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			strategy := &model.KopsUpgradeStrategy{BatchSize: tc.batchSize}
+			progress := &model.KopsUpgradeProgress{UpgradedInstanceGroups: tc.upgraded}
+			assert.Equal(t, tc.expectedStage, strategy.NextStage(masterIGs, nodeIGs, progress))
+		})
+	}
+}
+
+func TestKopsUpgradeStrategyNextStageAt(t *testing.T) {
+	strategy := &model.KopsUpgradeStrategy{PauseSeconds: 60}
+
+	assert.Equal(t, int64(0), strategy.NextStageAt(&model.KopsUpgradeProgress{}))
+	assert.Equal(t, int64(61000), strategy.NextStageAt(&model.KopsUpgradeProgress{LastStageCompletedAt: 1000}))
+}
@@ -7,6 +7,10 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -20,6 +24,14 @@ const (
 	FluentbitCanonicalName = "fluentbit"
 	// TeleportCanonicalName is the canonical string representation of teleport
 	TeleportCanonicalName = "teleport"
+	// VeleroCanonicalName is the canonical string representation of velero
+	VeleroCanonicalName = "velero"
+	// CertManagerCanonicalName is the canonical string representation of cert-manager
+	CertManagerCanonicalName = "cert-manager"
+	// ClusterAutoscalerCanonicalName is the canonical string representation of cluster-autoscaler
+	ClusterAutoscalerCanonicalName = "cluster-autoscaler"
+	// NodeTerminationHandlerCanonicalName is the canonical string representation of aws-node-termination-handler
+	NodeTerminationHandlerCanonicalName = "aws-node-termination-handler"
 )
 
 const (
@@ -33,6 +45,14 @@ const (
 	FluentbitDefaultVersion = "2.8.7"
 	// TeleportDefaultVersion defines the default version for the Helm chart
 	TeleportDefaultVersion = "0.3.0"
+	// VeleroDefaultVersion defines the default version for the Helm chart
+	VeleroDefaultVersion = "2.13.6"
+	// CertManagerDefaultVersion defines the default version for the Helm chart
+	CertManagerDefaultVersion = "v1.0.4"
+	// ClusterAutoscalerDefaultVersion defines the default version for the Helm chart
+	ClusterAutoscalerDefaultVersion = "9.1.0"
+	// NodeTerminationHandlerDefaultVersion defines the default version for the Helm chart
+	NodeTerminationHandlerDefaultVersion = "0.13.2"
 )
 
 // UtilityMetadata is a container struct for any metadata related to
@@ -40,14 +60,41 @@ const (
 type UtilityMetadata struct {
 	DesiredVersions utilityVersions
 	ActualVersions  utilityVersions
+
+	// ValuesOverrides are Helm values keyed by utility name, which are
+	// merged over the default values of the utility charts.
+	ValuesOverrides map[string]string `json:"ValuesOverrides,omitempty"`
+
+	// PendingUpgrade is the single utility upgrade waiting to be applied
+	// by the supervisor.
+	PendingUpgrade *UtilityUpgrade `json:"PendingUpgrade,omitempty"`
+
+	// ValuesHashes are the hashes of the Helm values keyed by utility name
+	// that the utilities were last deployed with.
+	ValuesHashes map[string]string `json:"ValuesHashes,omitempty"`
+	// DriftCheck is the result of the last utility drift check.
+	DriftCheck *UtilityDriftCheck `json:"DriftCheck,omitempty"`
+}
+
+// UtilityUpgrade is a request to upgrade or roll back a single utility of a
+// cluster. The previous version is kept so that a failed upgrade can be
+// rolled back.
+type UtilityUpgrade struct {
+	Name            string
+	Version         string
+	PreviousVersion string
 }
 
 type utilityVersions struct {
-	PrometheusOperator string
-	Thanos             string
-	Nginx              string
-	Fluentbit          string
-	Teleport           string
+	PrometheusOperator     string
+	Thanos                 string
+	Nginx                  string
+	Fluentbit              string
+	Teleport               string
+	Velero                 string
+	CertManager            string
+	ClusterAutoscaler      string
+	NodeTerminationHandler string
 }
 
 // NewUtilityMetadata creates an instance of UtilityMetadata given the raw
@@ -128,9 +175,231 @@ func (c *Cluster) DesiredUtilityVersion(utility string) (string, error) {
 // ActualUtilityVersion fetches the desired version of a utility from the
 // Cluster object
 func (c *Cluster) ActualUtilityVersion(utility string) (string, error) {
+	if c.UtilityMetadata == nil {
+		return "", nil
+	}
+
 	return getUtilityVersion(&c.UtilityMetadata.ActualVersions, utility), nil
 }
 
+// SetUtilityUpgrade sets the desired version of a single utility and records
+// the upgrade as pending so that it can be rolled back on failure. The
+// upgrade is rolled back to the version deployed in the cluster, or to the
+// previous desired version if the utility hasn't been deployed yet.
+func (c *Cluster) SetUtilityUpgrade(utility, version string) error {
+	if !isUtility(utility) {
+		return errors.Errorf("unknown utility %s", utility)
+	}
+
+	previousVersion, err := c.ActualUtilityVersion(utility)
+	if err != nil {
+		return err
+	}
+	if len(previousVersion) == 0 {
+		previousVersion, err = c.DesiredUtilityVersion(utility)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.SetUtilityDesiredVersions(map[string]string{utility: version})
+	if err != nil {
+		return err
+	}
+
+	desiredVersion, err := c.DesiredUtilityVersion(utility)
+	if err != nil {
+		return err
+	}
+
+	c.UtilityMetadata.PendingUpgrade = &UtilityUpgrade{
+		Name:            utility,
+		Version:         desiredVersion,
+		PreviousVersion: previousVersion,
+	}
+
+	return nil
+}
+
+// UtilityUpgrade returns the pending single utility upgrade of the cluster or
+// nil if there is none.
+func (c *Cluster) UtilityUpgrade() *UtilityUpgrade {
+	if c.UtilityMetadata == nil {
+		return nil
+	}
+
+	return c.UtilityMetadata.PendingUpgrade
+}
+
+// RevertUtilityUpgrade restores the desired version of the utility of the
+// pending single utility upgrade to its previous version and removes the
+// pending upgrade.
+func (c *Cluster) RevertUtilityUpgrade() error {
+	upgrade := c.UtilityUpgrade()
+	if upgrade == nil {
+		return nil
+	}
+
+	err := c.SetUtilityDesiredVersions(map[string]string{upgrade.Name: upgrade.PreviousVersion})
+	if err != nil {
+		return err
+	}
+	c.ClearUtilityUpgrade()
+
+	return nil
+}
+
+// ClearUtilityUpgrade removes the pending single utility upgrade.
+func (c *Cluster) ClearUtilityUpgrade() {
+	if c.UtilityMetadata == nil {
+		return
+	}
+
+	c.UtilityMetadata.PendingUpgrade = nil
+}
+
+// SetUtilityValuesOverrides stores the provided Helm values overrides keyed
+// by utility name. An empty value removes the override of the utility.
+func (c *Cluster) SetUtilityValuesOverrides(overrides map[string]string) {
+	if len(overrides) == 0 {
+		return
+	}
+
+	metadata := &UtilityMetadata{}
+	if c.UtilityMetadata != nil {
+		metadata = c.UtilityMetadata
+	}
+	if metadata.ValuesOverrides == nil {
+		metadata.ValuesOverrides = make(map[string]string)
+	}
+
+	for utility, values := range overrides {
+		if len(values) == 0 {
+			delete(metadata.ValuesOverrides, utility)
+			continue
+		}
+		metadata.ValuesOverrides[utility] = values
+	}
+
+	c.UtilityMetadata = metadata
+}
+
+// UtilityValuesOverride returns the Helm values override of a utility or an
+// empty string if the utility has none.
+func (c *Cluster) UtilityValuesOverride(utility string) string {
+	if c.UtilityMetadata == nil {
+		return ""
+	}
+
+	return c.UtilityMetadata.ValuesOverrides[utility]
+}
+
+// utilityProvisionerValues are the Helm values that the provisioner sets
+// on each utility with --set, which takes precedence over values overrides.
+// Each value is given as its path of keys.
+var utilityProvisionerValues = map[string][][]string{
+	PrometheusOperatorCanonicalName: {
+		{"prometheus", "prometheusSpec", "externalLabels", "clusterID"},
+		{"prometheus", "ingress", "hosts"},
+		{"prometheus", "ingress", "annotations", "nginx.ingress.kubernetes.io/whitelist-source-range"},
+	},
+	ThanosCanonicalName: {
+		{"querier", "ingress", "hostname"},
+		{"querier", "ingress", "grpc", "hostname"},
+		{"querier", "ingress", "annotations", "nginx.ingress.kubernetes.io/whitelist-source-range"},
+	},
+	NginxCanonicalName: {
+		{"controller", "service", "targetPorts", "https"},
+		{"controller", "service", "annotations", "service.beta.kubernetes.io/aws-load-balancer-ssl-cert"},
+		{"controller", "service", "internal", "annotations", "service.beta.kubernetes.io/aws-load-balancer-ssl-cert"},
+	},
+	FluentbitCanonicalName: {
+		{"backend", "es", "host"},
+		{"rawConfig"},
+	},
+	TeleportCanonicalName: {
+		{"config", "auth_service", "cluster_name"},
+		{"config", "teleport", "storage", "region"},
+		{"config", "teleport", "storage", "table_name"},
+		{"config", "teleport", "storage", "audit_events_uri"},
+		{"config", "teleport", "storage", "audit_sessions_uri"},
+	},
+	VeleroCanonicalName: {
+		{"configuration", "backupStorageLocation", "bucket"},
+		{"configuration", "backupStorageLocation", "config", "region"},
+	},
+	ClusterAutoscalerCanonicalName: {
+		{"autoDiscovery", "clusterName"},
+		{"awsRegion"},
+		{"replicaCount"},
+	},
+	NodeTerminationHandlerCanonicalName: {
+		{"enableSpotInterruptionDraining"},
+		{"enableScheduledEventDraining"},
+	},
+}
+
+// ValidateUtilityValuesOverrides checks that Helm values overrides are keyed
+// by known utility names, are valid YAML documents and don't set any of the
+// values that the provisioner sets on the utility.
+func ValidateUtilityValuesOverrides(overrides map[string]string) error {
+	for utility, values := range overrides {
+		if !isUtility(utility) {
+			return errors.Errorf("unknown utility %s", utility)
+		}
+		parsedValues := map[string]interface{}{}
+		err := yaml.Unmarshal([]byte(values), &parsedValues)
+		if err != nil {
+			return errors.Wrapf(err, "invalid values override for utility %s", utility)
+		}
+		for _, path := range utilityProvisionerValues[utility] {
+			if hasValue(parsedValues, path) {
+				return errors.Errorf("values override for utility %s sets %s, which is managed by the provisioner", utility, strings.Join(path, "."))
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasValue returns true if the given path of keys is set in Helm values.
+func hasValue(values map[string]interface{}, path []string) bool {
+	var current interface{} = values
+	for _, key := range path {
+		var ok bool
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current, ok = node[key]
+		case map[interface{}]interface{}:
+			current, ok = node[key]
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isUtility returns true if the given name is the canonical name of a
+// cluster utility.
+func isUtility(name string) bool {
+	switch name {
+	case PrometheusOperatorCanonicalName,
+		ThanosCanonicalName,
+		NginxCanonicalName,
+		FluentbitCanonicalName,
+		TeleportCanonicalName,
+		VeleroCanonicalName,
+		CertManagerCanonicalName,
+		ClusterAutoscalerCanonicalName,
+		NodeTerminationHandlerCanonicalName:
+		return true
+	}
+
+	return false
+}
+
 // UtilityMetadataFromReader produces a UtilityMetadata object from
 // the JSON representation embedded in a io.Reader
 func UtilityMetadataFromReader(reader io.Reader) (*UtilityMetadata, error) {
@@ -158,6 +427,14 @@ func getUtilityVersion(versions *utilityVersions, utility string) string {
 		return versions.Fluentbit
 	case TeleportCanonicalName:
 		return versions.Teleport
+	case VeleroCanonicalName:
+		return versions.Velero
+	case CertManagerCanonicalName:
+		return versions.CertManager
+	case ClusterAutoscalerCanonicalName:
+		return versions.ClusterAutoscaler
+	case NodeTerminationHandlerCanonicalName:
+		return versions.NodeTerminationHandler
 	}
 
 	return ""
@@ -179,5 +456,13 @@ func setUtilityVersion(versions *utilityVersions, utility, desiredVersion string
 		versions.Fluentbit = desiredVersion
 	case TeleportCanonicalName:
 		versions.Teleport = desiredVersion
+	case VeleroCanonicalName:
+		versions.Velero = desiredVersion
+	case CertManagerCanonicalName:
+		versions.CertManager = desiredVersion
+	case ClusterAutoscalerCanonicalName:
+		versions.ClusterAutoscaler = desiredVersion
+	case NodeTerminationHandlerCanonicalName:
+		versions.NodeTerminationHandler = desiredVersion
 	}
 }
@@ -0,0 +1,191 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// utilityDriftWarningPrefix prefixes the cluster warnings of drifted
+// utilities so that they can be replaced on every drift check.
+const utilityDriftWarningPrefix = "utility drift: "
+
+// UtilityRelease is the Helm release of a cluster utility as observed on the
+// cluster, along with the version and values last deployed by the
+// provisioner.
+type UtilityRelease struct {
+	Name               string
+	Version            string
+	ValuesHash         string
+	ExpectedVersion    string
+	ExpectedValuesHash string
+	Drifted            bool
+}
+
+// IsDrifted returns true if the release was changed out of band since it was
+// last deployed by the provisioner. Releases without a recorded values hash
+// are only compared by version.
+func (r *UtilityRelease) IsDrifted() bool {
+	if r.Version != r.ExpectedVersion {
+		return true
+	}
+
+	return r.ExpectedValuesHash != "" && r.ValuesHash != r.ExpectedValuesHash
+}
+
+// UtilityDriftCheck is the result of comparing the Helm releases of the
+// utilities of a cluster with what the provisioner last deployed.
+type UtilityDriftCheck struct {
+	CheckedAt int64
+	Releases  []UtilityRelease
+}
+
+// DriftedReleases returns the releases of the check that have drifted.
+func (c *UtilityDriftCheck) DriftedReleases() []UtilityRelease {
+	drifted := []UtilityRelease{}
+	for _, release := range c.Releases {
+		if release.Drifted {
+			drifted = append(drifted, release)
+		}
+	}
+
+	return drifted
+}
+
+// SetUtilityValuesHash records the hash of the Helm values a utility was
+// deployed with.
+func (c *Cluster) SetUtilityValuesHash(utility, hash string) {
+	metadata := &UtilityMetadata{}
+	if c.UtilityMetadata != nil {
+		metadata = c.UtilityMetadata
+	}
+	if metadata.ValuesHashes == nil {
+		metadata.ValuesHashes = make(map[string]string)
+	}
+
+	if len(hash) == 0 {
+		delete(metadata.ValuesHashes, utility)
+	} else {
+		metadata.ValuesHashes[utility] = hash
+	}
+
+	c.UtilityMetadata = metadata
+}
+
+// UtilityValuesHash returns the hash of the Helm values a utility was last
+// deployed with or an empty string if none was recorded.
+func (c *Cluster) UtilityValuesHash(utility string) string {
+	if c.UtilityMetadata == nil {
+		return ""
+	}
+
+	return c.UtilityMetadata.ValuesHashes[utility]
+}
+
+// SetUtilityDriftCheck stores the result of a utility drift check and
+// replaces the drift warnings of the cluster with the drifted utilities.
+func (c *Cluster) SetUtilityDriftCheck(check *UtilityDriftCheck) {
+	metadata := &UtilityMetadata{}
+	if c.UtilityMetadata != nil {
+		metadata = c.UtilityMetadata
+	}
+
+	var warnings []string
+	for i := range check.Releases {
+		release := &check.Releases[i]
+		release.Drifted = release.IsDrifted()
+		if release.Drifted {
+			warnings = append(warnings, utilityDriftWarning(release))
+		}
+	}
+
+	metadata.DriftCheck = check
+	c.UtilityMetadata = metadata
+
+	switch {
+	case c.ProvisionerMetadataKops != nil:
+		c.ProvisionerMetadataKops.Warnings = replaceUtilityDriftWarnings(c.ProvisionerMetadataKops.Warnings, warnings)
+	case c.ProvisionerMetadataExternal != nil:
+		c.ProvisionerMetadataExternal.Warnings = replaceUtilityDriftWarnings(c.ProvisionerMetadataExternal.Warnings, warnings)
+	}
+}
+
+// UtilityDriftCheck returns the result of the last utility drift check of
+// the cluster or nil if the cluster was never checked.
+func (c *Cluster) UtilityDriftCheck() *UtilityDriftCheck {
+	if c.UtilityMetadata == nil {
+		return nil
+	}
+
+	return c.UtilityMetadata.DriftCheck
+}
+
+func utilityDriftWarning(release *UtilityRelease) string {
+	if release.Version != release.ExpectedVersion {
+		return fmt.Sprintf("%s%s release is %q, but %q was deployed", utilityDriftWarningPrefix, release.Name, release.Version, release.ExpectedVersion)
+	}
+
+	return fmt.Sprintf("%s%s release values were changed since deployment", utilityDriftWarningPrefix, release.Name)
+}
+
+func replaceUtilityDriftWarnings(warnings, driftWarnings []string) []string {
+	replaced := []string{}
+	for _, warning := range warnings {
+		if !strings.HasPrefix(warning, utilityDriftWarningPrefix) {
+			replaced = append(replaced, warning)
+		}
+	}
+
+	return append(replaced, driftWarnings...)
+}
+
+// ClusterUtilityDrift is the entry of a single cluster in the fleet-wide
+// utility drift report.
+type ClusterUtilityDrift struct {
+	ClusterID string
+	CheckedAt int64
+	Releases  []UtilityRelease
+}
+
+// NewClusterUtilityDriftReport builds the utility drift report of the given
+// clusters, containing only the clusters with drifted utilities.
+func NewClusterUtilityDriftReport(clusters []*Cluster) []*ClusterUtilityDrift {
+	report := []*ClusterUtilityDrift{}
+	for _, cluster := range clusters {
+		check := cluster.UtilityDriftCheck()
+		if check == nil {
+			continue
+		}
+
+		drifted := check.DriftedReleases()
+		if len(drifted) == 0 {
+			continue
+		}
+
+		report = append(report, &ClusterUtilityDrift{
+			ClusterID: cluster.ID,
+			CheckedAt: check.CheckedAt,
+			Releases:  drifted,
+		})
+	}
+
+	return report
+}
+
+// ClusterUtilityDriftReportFromReader decodes a json-encoded utility drift
+// report from the given io.Reader.
+func ClusterUtilityDriftReportFromReader(reader io.Reader) ([]*ClusterUtilityDrift, error) {
+	report := []*ClusterUtilityDrift{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&report)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return report, nil
+}
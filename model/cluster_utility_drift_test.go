@@ -0,0 +1,119 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUtilityReleaseIsDrifted(t *testing.T) {
+	var testCases = []struct {
+		testName string
+		release  UtilityRelease
+		expected bool
+	}{
+		{"matching", UtilityRelease{Version: "2.15.0", ExpectedVersion: "2.15.0", ValuesHash: "a", ExpectedValuesHash: "a"}, false},
+		{"version changed", UtilityRelease{Version: "2.16.0", ExpectedVersion: "2.15.0"}, true},
+		{"release removed", UtilityRelease{ExpectedVersion: "2.15.0"}, true},
+		{"values changed", UtilityRelease{Version: "2.15.0", ExpectedVersion: "2.15.0", ValuesHash: "b", ExpectedValuesHash: "a"}, true},
+		{"no recorded values hash", UtilityRelease{Version: "2.15.0", ExpectedVersion: "2.15.0", ValuesHash: "b"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.release.IsDrifted())
+		})
+	}
+}
+
+func TestUtilityValuesHash(t *testing.T) {
+	c := &Cluster{}
+	assert.Equal(t, "", c.UtilityValuesHash(NginxCanonicalName))
+
+	c.SetUtilityValuesHash(NginxCanonicalName, "hash")
+	assert.Equal(t, "hash", c.UtilityValuesHash(NginxCanonicalName))
+
+	c.SetUtilityValuesHash(NginxCanonicalName, "")
+	assert.Equal(t, "", c.UtilityValuesHash(NginxCanonicalName))
+	assert.Empty(t, c.UtilityMetadata.ValuesHashes)
+}
+
+func TestSetUtilityDriftCheck(t *testing.T) {
+	c := &Cluster{
+		ID: NewID(),
+		ProvisionerMetadataKops: &KopsMetadata{
+			Warnings: []string{"node is not ready", utilityDriftWarningPrefix + "old warning"},
+		},
+	}
+	assert.Nil(t, c.UtilityDriftCheck())
+
+	c.SetUtilityDriftCheck(&UtilityDriftCheck{
+		CheckedAt: 10,
+		Releases: []UtilityRelease{
+			{Name: NginxCanonicalName, Version: "2.16.0", ExpectedVersion: "2.15.0"},
+			{Name: VeleroCanonicalName, Version: "2.13.6", ExpectedVersion: "2.13.6", ValuesHash: "b", ExpectedValuesHash: "a"},
+			{Name: TeleportCanonicalName, Version: "0.3.0", ExpectedVersion: "0.3.0"},
+		},
+	})
+
+	check := c.UtilityDriftCheck()
+	require.NotNil(t, check)
+	assert.Equal(t, []string{NginxCanonicalName, VeleroCanonicalName}, releaseNames(check.DriftedReleases()))
+	assert.Equal(t, []string{
+		"node is not ready",
+		`utility drift: nginx release is "2.16.0", but "2.15.0" was deployed`,
+		"utility drift: velero release values were changed since deployment",
+	}, c.ProvisionerMetadataKops.Warnings)
+
+	report := NewClusterUtilityDriftReport([]*Cluster{c, {ID: NewID()}})
+	require.Len(t, report, 1)
+	assert.Equal(t, c.ID, report[0].ClusterID)
+	assert.Equal(t, int64(10), report[0].CheckedAt)
+	assert.Len(t, report[0].Releases, 2)
+
+	c.SetUtilityDriftCheck(&UtilityDriftCheck{
+		Releases: []UtilityRelease{
+			{Name: NginxCanonicalName, Version: "2.16.0", ExpectedVersion: "2.16.0"},
+		},
+	})
+	assert.Empty(t, c.UtilityDriftCheck().DriftedReleases())
+	assert.Equal(t, []string{"node is not ready"}, c.ProvisionerMetadataKops.Warnings)
+	assert.Empty(t, NewClusterUtilityDriftReport([]*Cluster{c}))
+}
+
+func TestClusterUtilityDriftReportFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		report, err := ClusterUtilityDriftReportFromReader(bytes.NewReader([]byte(``)))
+		require.NoError(t, err)
+		require.Equal(t, []*ClusterUtilityDrift{}, report)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		report, err := ClusterUtilityDriftReportFromReader(bytes.NewReader([]byte(`{test`)))
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		report, err := ClusterUtilityDriftReportFromReader(bytes.NewReader([]byte(`[{"ClusterID":"id","CheckedAt":10,"Releases":[{"Name":"nginx","Drifted":true}]}]`)))
+		require.NoError(t, err)
+		require.Equal(t, []*ClusterUtilityDrift{
+			{ClusterID: "id", CheckedAt: 10, Releases: []UtilityRelease{{Name: "nginx", Drifted: true}}},
+		}, report)
+	})
+}
+
+func releaseNames(releases []UtilityRelease) []string {
+	var names []string
+	for _, release := range releases {
+		names = append(names, release.Name)
+	}
+
+	return names
+}
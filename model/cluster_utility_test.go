@@ -85,6 +85,9 @@ func TestGetActualVersion(t *testing.T) {
 				Nginx:              "10.3",
 				Fluentbit:          "1337",
 				Teleport:           "12345",
+				Velero:             "2.13.6",
+				CertManager:        "v1.0.4",
+				ClusterAutoscaler:  "9.1.0",
 			},
 			ActualVersions: utilityVersions{
 				PrometheusOperator: "kube-prometheus-stack-9.4",
@@ -92,6 +95,9 @@ func TestGetActualVersion(t *testing.T) {
 				Nginx:              "nginx-10.2",
 				Fluentbit:          "fluent-bit-0.9",
 				Teleport:           "teleport-0.3.0",
+				Velero:             "velero-2.13.6",
+				CertManager:        "cert-manager-v1.0.4",
+				ClusterAutoscaler:  "cluster-autoscaler-9.1.0",
 			},
 		},
 	}
@@ -116,6 +122,18 @@ func TestGetActualVersion(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "teleport-0.3.0", version)
 
+	version, err = c.ActualUtilityVersion(VeleroCanonicalName)
+	assert.NoError(t, err)
+	assert.Equal(t, "velero-2.13.6", version)
+
+	version, err = c.ActualUtilityVersion(CertManagerCanonicalName)
+	assert.NoError(t, err)
+	assert.Equal(t, "cert-manager-v1.0.4", version)
+
+	version, err = c.ActualUtilityVersion(ClusterAutoscalerCanonicalName)
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster-autoscaler-9.1.0", version)
+
 	version, err = c.ActualUtilityVersion("something else that doesn't exist")
 	assert.NoError(t, err)
 	assert.Equal(t, "", version)
@@ -130,6 +148,9 @@ func TestGetDesiredVersion(t *testing.T) {
 				Nginx:              "10.3",
 				Fluentbit:          "1337",
 				Teleport:           "12345",
+				Velero:             "2.13.6",
+				CertManager:        "v1.0.4",
+				ClusterAutoscaler:  "9.1.0",
 			},
 			ActualVersions: utilityVersions{
 				PrometheusOperator: "kube-prometheus-stack-9.4",
@@ -137,6 +158,9 @@ func TestGetDesiredVersion(t *testing.T) {
 				Nginx:              "nginx-10.2",
 				Fluentbit:          "fluent-bit-0.9",
 				Teleport:           "teleport-0.3.0",
+				Velero:             "velero-2.13.6",
+				CertManager:        "cert-manager-v1.0.4",
+				ClusterAutoscaler:  "cluster-autoscaler-9.1.0",
 			},
 		},
 	}
@@ -161,7 +185,87 @@ func TestGetDesiredVersion(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "12345", version)
 
+	version, err = c.DesiredUtilityVersion(VeleroCanonicalName)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.13.6", version)
+
+	version, err = c.DesiredUtilityVersion(CertManagerCanonicalName)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.4", version)
+
+	version, err = c.DesiredUtilityVersion(ClusterAutoscalerCanonicalName)
+	assert.NoError(t, err)
+	assert.Equal(t, "9.1.0", version)
+
 	version, err = c.DesiredUtilityVersion("something else that doesn't exist")
 	assert.NoError(t, err)
 	assert.Equal(t, "", version)
 }
+
+func TestUtilityValuesOverrides(t *testing.T) {
+	c := &Cluster{}
+	assert.Equal(t, "", c.UtilityValuesOverride(NginxCanonicalName))
+
+	c.SetUtilityValuesOverrides(nil)
+	assert.Nil(t, c.UtilityMetadata)
+
+	c.SetUtilityValuesOverrides(map[string]string{
+		NginxCanonicalName:  "controller:\n  replicaCount: 3\n",
+		VeleroCanonicalName: "schedules: {}\n",
+	})
+	assert.Equal(t, "controller:\n  replicaCount: 3\n", c.UtilityValuesOverride(NginxCanonicalName))
+	assert.Equal(t, "schedules: {}\n", c.UtilityValuesOverride(VeleroCanonicalName))
+	assert.Equal(t, "", c.UtilityValuesOverride(ThanosCanonicalName))
+
+	c.SetUtilityValuesOverrides(map[string]string{VeleroCanonicalName: ""})
+	assert.Equal(t, "controller:\n  replicaCount: 3\n", c.UtilityValuesOverride(NginxCanonicalName))
+	assert.Equal(t, "", c.UtilityValuesOverride(VeleroCanonicalName))
+	assert.Equal(t, map[string]string{NginxCanonicalName: "controller:\n  replicaCount: 3\n"}, c.UtilityMetadata.ValuesOverrides)
+}
+
+func TestUtilityUpgrade(t *testing.T) {
+	c := &Cluster{}
+	assert.Nil(t, c.UtilityUpgrade())
+	c.ClearUtilityUpgrade()
+
+	err := c.SetUtilityUpgrade("traefik", "1.0.0")
+	assert.Error(t, err)
+	assert.Nil(t, c.UtilityUpgrade())
+
+	err = c.SetUtilityDesiredVersions(map[string]string{NginxCanonicalName: "2.15.0"})
+	assert.NoError(t, err)
+
+	err = c.SetUtilityUpgrade(NginxCanonicalName, "2.16.0")
+	assert.NoError(t, err)
+	assert.Equal(t, &UtilityUpgrade{Name: NginxCanonicalName, Version: "2.16.0", PreviousVersion: "2.15.0"}, c.UtilityUpgrade())
+
+	version, err := c.DesiredUtilityVersion(NginxCanonicalName)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.16.0", version)
+
+	err = c.SetUtilityUpgrade(NginxCanonicalName, "stable")
+	assert.NoError(t, err)
+	assert.Equal(t, &UtilityUpgrade{Name: NginxCanonicalName, Version: "", PreviousVersion: "2.16.0"}, c.UtilityUpgrade())
+
+	c.ClearUtilityUpgrade()
+	assert.Nil(t, c.UtilityUpgrade())
+
+	t.Run("previous version is the deployed version", func(t *testing.T) {
+		c := &Cluster{}
+		err := c.SetUtilityActualVersion(NginxCanonicalName, "2.15.0")
+		assert.NoError(t, err)
+		err = c.SetUtilityDesiredVersions(map[string]string{NginxCanonicalName: "2.17.0"})
+		assert.NoError(t, err)
+
+		err = c.SetUtilityUpgrade(NginxCanonicalName, "2.16.0")
+		assert.NoError(t, err)
+		assert.Equal(t, &UtilityUpgrade{Name: NginxCanonicalName, Version: "2.16.0", PreviousVersion: "2.15.0"}, c.UtilityUpgrade())
+
+		err = c.RevertUtilityUpgrade()
+		assert.NoError(t, err)
+		assert.Nil(t, c.UtilityUpgrade())
+		version, err := c.DesiredUtilityVersion(NginxCanonicalName)
+		assert.NoError(t, err)
+		assert.Equal(t, "2.15.0", version)
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+)
+
+// EKSMetadata is the provisioner metadata stored in a model.Cluster that is
+// provisioned as a managed EKS cluster.
+type EKSMetadata struct {
+	Name    string
+	Region  string
+	Version string
+	// NodeGroupName is the name of the managed node group running the
+	// worker nodes.
+	NodeGroupName    string
+	NodeInstanceType string
+	NodeMinCount     int64
+	NodeMaxCount     int64
+	ChangeRequest    *EKSMetadataRequestedState `json:"ChangeRequest,omitempty"`
+	Warnings         []string                   `json:"Warnings,omitempty"`
+}
+
+// EKSMetadataRequestedState is the requested state for EKS metadata.
+type EKSMetadataRequestedState struct {
+	Version          string `json:"Version,omitempty"`
+	NodeInstanceType string `json:"NodeInstanceType,omitempty"`
+	NodeMinCount     int64  `json:"NodeMinCount,omitempty"`
+	NodeMaxCount     int64  `json:"NodeMaxCount,omitempty"`
+}
+
+// ClearChangeRequest clears the EKS metadata change request.
+func (em *EKSMetadata) ClearChangeRequest() {
+	em.ChangeRequest = nil
+}
+
+// ClearWarnings clears the EKS metadata warnings.
+func (em *EKSMetadata) ClearWarnings() {
+	em.Warnings = []string{}
+}
+
+// AddWarning adds a warning the EKS metadata warning list.
+func (em *EKSMetadata) AddWarning(warning string) {
+	em.Warnings = append(em.Warnings, warning)
+}
+
+// NewEKSMetadata creates an instance of EKSMetadata given the raw provisioner metadata.
+func NewEKSMetadata(metadataBytes []byte) (*EKSMetadata, error) {
+	if len(metadataBytes) == 0 || string(metadataBytes) == "null" {
+		return nil, nil
+	}
+
+	eksMetadata := EKSMetadata{}
+	err := json.Unmarshal(metadataBytes, &eksMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eksMetadata, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+)
+
+// ExternalMetadata is the provisioner metadata stored in a model.Cluster that
+// was imported with a kubeconfig.
+type ExternalMetadata struct {
+	Name    string
+	Version string
+	// Kubeconfig is the kubeconfig used to connect to the cluster while it
+	// is imported. It is never stored; the kubeconfig is kept in the AWS
+	// Secrets Manager secret named by KubeconfigSecret.
+	Kubeconfig string `json:"-"`
+	// KubeconfigSecret is the name of the secret holding the kubeconfig.
+	KubeconfigSecret string
+	Warnings         []string `json:"Warnings,omitempty"`
+}
+
+// ClearWarnings clears the external metadata warnings.
+func (em *ExternalMetadata) ClearWarnings() {
+	em.Warnings = []string{}
+}
+
+// AddWarning adds a warning the external metadata warning list.
+func (em *ExternalMetadata) AddWarning(warning string) {
+	em.Warnings = append(em.Warnings, warning)
+}
+
+// NewExternalMetadata creates an instance of ExternalMetadata given the raw
+// provisioner metadata.
+func NewExternalMetadata(metadataBytes []byte) (*ExternalMetadata, error) {
+	if len(metadataBytes) == 0 || string(metadataBytes) == "null" {
+		return nil, nil
+	}
+
+	externalMetadata := ExternalMetadata{}
+	err := json.Unmarshal(metadataBytes, &externalMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalMetadata, nil
+}
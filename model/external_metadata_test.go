@@ -0,0 +1,33 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExternalMetadata(t *testing.T) {
+	t.Run("nil payload", func(t *testing.T) {
+		externalMetadata, err := model.NewExternalMetadata(nil)
+		require.NoError(t, err)
+		require.Nil(t, externalMetadata)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, err := model.NewExternalMetadata([]byte(`{`))
+		require.Error(t, err)
+	})
+
+	t.Run("valid payload", func(t *testing.T) {
+		externalMetadata, err := model.NewExternalMetadata([]byte(`{"Name": "name", "Kubeconfig": "config", "KubeconfigSecret": "cloud-id-kubeconfig"}`))
+		require.NoError(t, err)
+		require.Equal(t, "name", externalMetadata.Name)
+		require.Equal(t, "cloud-id-kubeconfig", externalMetadata.KubeconfigSecret)
+		require.Empty(t, externalMetadata.Kubeconfig)
+	})
+}
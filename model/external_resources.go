@@ -0,0 +1,73 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExternalDatabaseRequest represents the connection details of a customer
+// managed database supplied with a new installation.
+type ExternalDatabaseRequest struct {
+	// ConnectionString is the Mattermost SQL data source, starting with either
+	// mysql:// or postgres://.
+	ConnectionString string
+}
+
+// Validate validates the values of an external database request.
+func (request *ExternalDatabaseRequest) Validate() error {
+	if request == nil {
+		return errors.New("external database connection string must be specified")
+	}
+	_, err := ExternalDatabaseEngineType(request.ConnectionString)
+
+	return err
+}
+
+// ExternalDatabaseEngineType returns the database engine type of the given
+// external database connection string.
+func ExternalDatabaseEngineType(connectionString string) (string, error) {
+	switch {
+	case strings.HasPrefix(connectionString, "mysql://"):
+		return DatabaseEngineTypeMySQL, nil
+	case strings.HasPrefix(connectionString, "postgres://"),
+		strings.HasPrefix(connectionString, "postgresql://"):
+		return DatabaseEngineTypePostgres, nil
+	case connectionString == "":
+		return "", errors.New("external database connection string must be specified")
+	}
+
+	return "", errors.New("external database connection string must start with mysql:// or postgres://")
+}
+
+// ExternalFilestoreRequest represents the location and credentials of a
+// customer managed S3 compatible bucket supplied with a new installation.
+type ExternalFilestoreRequest struct {
+	Bucket          string
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Validate validates the values of an external filestore request.
+func (request *ExternalFilestoreRequest) Validate() error {
+	if request == nil {
+		return errors.New("external filestore bucket and credentials must be specified")
+	}
+	if request.Bucket == "" {
+		return errors.New("external filestore bucket must be specified")
+	}
+	if request.AccessKeyID == "" || request.SecretAccessKey == "" {
+		return errors.New("external filestore access key ID and secret access key must be specified")
+	}
+	if strings.Contains(request.Endpoint, "://") {
+		return errors.New("external filestore endpoint must not include a scheme")
+	}
+
+	return nil
+}
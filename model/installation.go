@@ -25,14 +25,20 @@ type Installation struct {
 	MattermostEnv              EnvVarMap
 	Size                       string
 	Affinity                   string
+	NodeGroup                  string
 	State                      string
 	CreateAt                   int64
 	DeleteAt                   int64
+	CredentialsRotatedAt       int64
 	APISecurityLock            bool
 	LockAcquiredBy             *string
 	LockAcquiredAt             int64
 	GroupOverrides             map[string]string           `json:"GroupOverrides,omitempty"`
 	SingleTenantDatabaseConfig *SingleTenantDatabaseConfig `json:"SingleTenantDatabaseConfig,omitempty"`
+	FilestoreMigration         *FilestoreMigration         `json:"FilestoreMigration,omitempty"`
+	NetworkIsolation           *NetworkIsolation           `json:"NetworkIsolation,omitempty"`
+	ResourceQuota              *InstallationResourceQuota  `json:"ResourceQuota,omitempty"`
+	CustomSize                 *InstallationCustomSize     `json:"CustomSize,omitempty"`
 
 	// configconfigMergedWithGroup is set when the installation configuration
 	// has been overridden with group configuration. This value can then be
@@ -45,6 +51,16 @@ type Installation struct {
 	configMergeGroupSequence int64
 }
 
+// NodeSelector returns the node selector that schedules the installation on
+// its node group or nil if the installation has no node group.
+func (i *Installation) NodeSelector() map[string]string {
+	if len(i.NodeGroup) == 0 {
+		return nil
+	}
+
+	return map[string]string{KopsInstanceGroupLabel: i.NodeGroup}
+}
+
 // InstallationsCount represents the number of installations
 type InstallationsCount struct {
 	Count int
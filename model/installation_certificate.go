@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	// CertificateStatusDisabled is the status of an installation whose
+	// ingress is served with the load balancer certificate instead of a
+	// certificate of its own.
+	CertificateStatusDisabled = "disabled"
+	// CertificateStatusPending is the status of a certificate that has been
+	// requested but not yet processed by cert-manager.
+	CertificateStatusPending = "pending"
+	// CertificateStatusReady is the status of a certificate that has been
+	// issued and is up to date.
+	CertificateStatusReady = "ready"
+	// CertificateStatusNotReady is the status of a certificate that is being
+	// issued or that failed to be issued.
+	CertificateStatusNotReady = "not-ready"
+)
+
+// InstallationCertificate is the status of the TLS certificate issued for
+// the ingress of an installation.
+type InstallationCertificate struct {
+	InstallationID string
+	ClusterID      string
+	Status         string
+	SecretName     string   `json:"SecretName,omitempty"`
+	DNSNames       []string `json:"DNSNames,omitempty"`
+	Message        string   `json:"Message,omitempty"`
+	// NotAfter is the expiry time of the certificate in milliseconds.
+	NotAfter int64 `json:"NotAfter,omitempty"`
+	// RenewalTime is the time the certificate will be renewed at in
+	// milliseconds.
+	RenewalTime int64 `json:"RenewalTime,omitempty"`
+}
+
+// IsReady returns true if the certificate has been issued.
+func (c *InstallationCertificate) IsReady() bool {
+	return c.Status == CertificateStatusReady
+}
+
+// InstallationCertificateFromReader decodes a json-encoded installation
+// certificate from the given io.Reader.
+func InstallationCertificateFromReader(reader io.Reader) (*InstallationCertificate, error) {
+	certificate := InstallationCertificate{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&certificate)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &certificate, nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationCertificateFromReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		certificate, err := InstallationCertificateFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Equal(t, &InstallationCertificate{}, certificate)
+	})
+
+	t.Run("certificate", func(t *testing.T) {
+		certificate, err := InstallationCertificateFromReader(bytes.NewReader([]byte(`{"InstallationID":"id","Status":"ready","DNSNames":["test.example.com"],"NotAfter":10}`)))
+		require.NoError(t, err)
+		require.Equal(t, &InstallationCertificate{
+			InstallationID: "id",
+			Status:         CertificateStatusReady,
+			DNSNames:       []string{"test.example.com"},
+			NotAfter:       10,
+		}, certificate)
+		require.True(t, certificate.IsReady())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := InstallationCertificateFromReader(bytes.NewReader([]byte(`{test`)))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// maxComponentReplicas is the maximum number of replicas of a component of a
+// custom installation size.
+const maxComponentReplicas = 20
+
+// InstallationCustomSize overrides the replicas and resources of the
+// installation size preset. Components and values that are not set keep the
+// values of the preset.
+type InstallationCustomSize struct {
+	App       *InstallationComponentSize `json:"App,omitempty"`
+	Database  *InstallationComponentSize `json:"Database,omitempty"`
+	Filestore *InstallationComponentSize `json:"Filestore,omitempty"`
+}
+
+// InstallationComponentSize is the size of a component of an installation.
+type InstallationComponentSize struct {
+	Replicas      int32  `json:"Replicas,omitempty"`
+	CPURequest    string `json:"CPURequest,omitempty"`
+	MemoryRequest string `json:"MemoryRequest,omitempty"`
+	CPULimit      string `json:"CPULimit,omitempty"`
+	MemoryLimit   string `json:"MemoryLimit,omitempty"`
+	// StorageSize is the size of the volume of the operator managed database
+	// or filestore. It is not supported for the app.
+	StorageSize string `json:"StorageSize,omitempty"`
+}
+
+// ToJSON marshals the custom size to JSON if it is not nil.
+func (s *InstallationCustomSize) ToJSON() ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// IsEmpty returns true if the custom size doesn't override any component.
+func (s *InstallationCustomSize) IsEmpty() bool {
+	return s == nil || (s.App == nil && s.Database == nil && s.Filestore == nil)
+}
+
+// Validate validates a custom installation size.
+func (s *InstallationCustomSize) Validate() error {
+	if s.App != nil {
+		if len(s.App.StorageSize) != 0 {
+			return errors.New("app storage size is not supported")
+		}
+		err := s.App.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid app size")
+		}
+	}
+	if s.Database != nil {
+		err := s.Database.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid database size")
+		}
+	}
+	if s.Filestore != nil {
+		err := s.Filestore.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid filestore size")
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the size of an installation component.
+func (c *InstallationComponentSize) Validate() error {
+	if c.Replicas < 0 || c.Replicas > maxComponentReplicas {
+		return errors.Errorf("replicas must be between 0 and %d", maxComponentReplicas)
+	}
+
+	for name, value := range map[string]string{
+		"CPU request":    c.CPURequest,
+		"memory request": c.MemoryRequest,
+		"CPU limit":      c.CPULimit,
+		"memory limit":   c.MemoryLimit,
+		"storage size":   c.StorageSize,
+	} {
+		if len(value) == 0 {
+			continue
+		}
+		err := validatePositiveQuantity(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateCustomSize returns an error when the custom size of an installation
+// sets requests above the limits of its components. Requests and limits are
+// compared once merged with the size preset, as a custom request may exceed
+// a limit of the preset.
+func (i *Installation) ValidateCustomSize() error {
+	if i.CustomSize == nil {
+		return nil
+	}
+
+	size, err := i.GetSize()
+	if err != nil {
+		return errors.Wrap(err, "invalid installation size")
+	}
+	components := []struct {
+		name string
+		size mmv1alpha1.ComponentSize
+	}{
+		{"app", size.App},
+		{"database", size.Database},
+		{"filestore", size.Minio},
+	}
+	for _, component := range components {
+		err = validateRequestsWithinLimits(component.size.Resources)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s size", component.name)
+		}
+	}
+
+	return nil
+}
+
+func validateRequestsWithinLimits(resources corev1.ResourceRequirements) error {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		request, hasRequest := resources.Requests[name]
+		limit, hasLimit := resources.Limits[name]
+		if hasRequest && hasLimit && request.Cmp(limit) > 0 {
+			return errors.Errorf("%s request %s is greater than limit %s", name, request.String(), limit.String())
+		}
+	}
+
+	return nil
+}
+
+// apply overrides the replicas and resources of the given component size.
+func (c *InstallationComponentSize) apply(size *mmv1alpha1.ComponentSize) {
+	if c == nil {
+		return
+	}
+	if c.Replicas != 0 {
+		size.Replicas = c.Replicas
+	}
+	setResourceQuantity(&size.Resources.Requests, corev1.ResourceCPU, c.CPURequest)
+	setResourceQuantity(&size.Resources.Requests, corev1.ResourceMemory, c.MemoryRequest)
+	setResourceQuantity(&size.Resources.Limits, corev1.ResourceCPU, c.CPULimit)
+	setResourceQuantity(&size.Resources.Limits, corev1.ResourceMemory, c.MemoryLimit)
+}
+
+func setResourceQuantity(resources *corev1.ResourceList, name corev1.ResourceName, value string) {
+	if len(value) == 0 {
+		return
+	}
+	if *resources == nil {
+		*resources = corev1.ResourceList{}
+	}
+	(*resources)[name] = resource.MustParse(value)
+}
+
+// GetSize returns the replicas and resources of the installation, which are
+// those of the installation size preset with the custom size overrides.
+func (i *Installation) GetSize() (mmv1alpha1.ClusterInstallationSize, error) {
+	preset, err := mmv1alpha1.GetClusterSize(i.Size)
+	if err != nil {
+		return mmv1alpha1.ClusterInstallationSize{}, err
+	}
+
+	// The presets share their resource lists, so they must not be modified.
+	size := mmv1alpha1.ClusterInstallationSize{
+		App:      mmv1alpha1.ComponentSize{Replicas: preset.App.Replicas, Resources: *preset.App.Resources.DeepCopy()},
+		Database: mmv1alpha1.ComponentSize{Replicas: preset.Database.Replicas, Resources: *preset.Database.Resources.DeepCopy()},
+		Minio:    mmv1alpha1.ComponentSize{Replicas: preset.Minio.Replicas, Resources: *preset.Minio.Resources.DeepCopy()},
+	}
+	if i.CustomSize != nil {
+		i.CustomSize.App.apply(&size.App)
+		i.CustomSize.Database.apply(&size.Database)
+		i.CustomSize.Filestore.apply(&size.Minio)
+	}
+
+	return size, nil
+}
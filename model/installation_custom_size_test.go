@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationCustomSizeValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		customSize   *model.InstallationCustomSize
+		requireError bool
+	}{
+		{"empty", &model.InstallationCustomSize{}, false},
+		{"app", &model.InstallationCustomSize{App: &model.InstallationComponentSize{Replicas: 3, CPURequest: "500m", MemoryRequest: "2Gi", CPULimit: "2", MemoryLimit: "4Gi"}}, false},
+		{"database and filestore", &model.InstallationCustomSize{Database: &model.InstallationComponentSize{Replicas: 3, StorageSize: "100Gi"}, Filestore: &model.InstallationComponentSize{Replicas: 4, StorageSize: "500Gi"}}, false},
+		{"negative replicas", &model.InstallationCustomSize{App: &model.InstallationComponentSize{Replicas: -1}}, true},
+		{"too many replicas", &model.InstallationCustomSize{App: &model.InstallationComponentSize{Replicas: 21}}, true},
+		{"invalid CPU request", &model.InstallationCustomSize{App: &model.InstallationComponentSize{CPURequest: "half"}}, true},
+		{"zero memory limit", &model.InstallationCustomSize{Database: &model.InstallationComponentSize{MemoryLimit: "0"}}, true},
+		{"app storage size", &model.InstallationCustomSize{App: &model.InstallationComponentSize{StorageSize: "10Gi"}}, true},
+		{"invalid storage size", &model.InstallationCustomSize{Filestore: &model.InstallationComponentSize{StorageSize: "lots"}}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.customSize.Validate())
+			} else {
+				assert.NoError(t, tc.customSize.Validate())
+			}
+		})
+	}
+}
+
+func TestInstallationValidateCustomSize(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		customSize   *model.InstallationCustomSize
+		requireError bool
+	}{
+		{"no custom size", nil, false},
+		{"within preset limits", &model.InstallationCustomSize{App: &model.InstallationComponentSize{CPURequest: "1", MemoryRequest: "2Gi"}}, false},
+		{"raised limits", &model.InstallationCustomSize{App: &model.InstallationComponentSize{MemoryRequest: "8Gi", MemoryLimit: "8Gi"}}, false},
+		{"request greater than limit", &model.InstallationCustomSize{App: &model.InstallationComponentSize{MemoryRequest: "8Gi", MemoryLimit: "4Gi"}}, true},
+		{"request greater than preset limit", &model.InstallationCustomSize{App: &model.InstallationComponentSize{CPURequest: "3"}}, true},
+		{"limit lower than preset request", &model.InstallationCustomSize{Database: &model.InstallationComponentSize{MemoryLimit: "1Mi"}}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			installation := &model.Installation{Size: mmv1alpha1.Size100String, CustomSize: tc.customSize}
+			err := installation.ValidateCustomSize()
+			if tc.requireError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInstallationGetSize(t *testing.T) {
+	preset, err := mmv1alpha1.GetClusterSize(mmv1alpha1.Size100String)
+	require.NoError(t, err)
+
+	t.Run("preset", func(t *testing.T) {
+		installation := &model.Installation{Size: mmv1alpha1.Size100String}
+		size, err := installation.GetSize()
+		require.NoError(t, err)
+		assert.Equal(t, preset, size)
+	})
+
+	t.Run("custom size", func(t *testing.T) {
+		installation := &model.Installation{
+			Size: mmv1alpha1.Size100String,
+			CustomSize: &model.InstallationCustomSize{
+				App:       &model.InstallationComponentSize{Replicas: 3, MemoryRequest: "1Gi"},
+				Filestore: &model.InstallationComponentSize{CPULimit: "1"},
+			},
+		}
+		size, err := installation.GetSize()
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), size.App.Replicas)
+		assert.Equal(t, "1Gi", size.App.Resources.Requests.Memory().String())
+		assert.Equal(t, preset.App.Resources.Requests.Cpu().String(), size.App.Resources.Requests.Cpu().String())
+		assert.Equal(t, "1", size.Minio.Resources.Limits.Cpu().String())
+		assert.Equal(t, preset.Database, size.Database)
+
+		// The preset must not be modified by the custom size.
+		unmodified, err := mmv1alpha1.GetClusterSize(mmv1alpha1.Size100String)
+		require.NoError(t, err)
+		assert.Equal(t, preset, unmodified)
+		assert.Equal(t, "256Mi", unmodified.App.Resources.Requests.Memory().String())
+		assert.Empty(t, unmodified.Minio.Resources.Limits)
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		installation := &model.Installation{Size: "invalid"}
+		_, err := installation.GetSize()
+		assert.Error(t, err)
+	})
+}
@@ -28,6 +28,9 @@ const (
 	// InstallationDatabaseMultiTenantRDSPostgres is a PostgreSQL multitenant
 	// database hosted via Amazon RDS.
 	InstallationDatabaseMultiTenantRDSPostgres = "aws-multitenant-rds-postgres"
+	// InstallationDatabaseExternal is a MySQL or PostgreSQL database managed
+	// outside of the provisioner and supplied with the installation.
+	InstallationDatabaseExternal = "external"
 
 	// DatabaseEngineTypeMySQL is a MySQL database.
 	DatabaseEngineTypeMySQL = "mysql"
@@ -41,6 +44,9 @@ type Database interface {
 	Teardown(store InstallationDatabaseStoreInterface, keepData bool, logger log.FieldLogger) error
 	Snapshot(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
 	GenerateDatabaseSpecAndSecret(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*mmv1alpha1.Database, *corev1.Secret, error)
+	Usage(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*StorageUsage, error)
+	RotateCredentials(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+	RevokeStaleCredentials(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
 }
 
 // InstallationDatabaseStoreInterface is the interface necessary for SQLStore
@@ -97,6 +103,24 @@ func (d *MysqlOperatorDatabase) GenerateDatabaseSpecAndSecret(store Installation
 	return nil, nil, nil
 }
 
+// Usage returns nil as the MySQL operator database usage can only be measured
+// from inside the kubernetes cluster.
+func (d *MysqlOperatorDatabase) Usage(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*StorageUsage, error) {
+	return nil, nil
+}
+
+// RotateCredentials is a noop as the MySQL operator database credentials are
+// managed inside the kubernetes cluster.
+func (d *MysqlOperatorDatabase) RotateCredentials(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return nil
+}
+
+// RevokeStaleCredentials is a noop as the MySQL operator database credentials
+// are managed inside the kubernetes cluster.
+func (d *MysqlOperatorDatabase) RevokeStaleCredentials(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return nil
+}
+
 // InternalDatabase returns true if the installation's database is internal
 // to the kubernetes cluster it is running on.
 func (i *Installation) InternalDatabase() bool {
@@ -111,6 +135,7 @@ func IsSupportedDatabase(database string) bool {
 	case InstallationDatabaseMultiTenantRDSMySQL:
 	case InstallationDatabaseMultiTenantRDSPostgres:
 	case InstallationDatabaseMysqlOperator:
+	case InstallationDatabaseExternal:
 	default:
 		return false
 	}
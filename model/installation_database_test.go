@@ -44,6 +44,7 @@ func TestIsSupportedDatabase(t *testing.T) {
 		{model.InstallationDatabaseMysqlOperator, true},
 		{model.InstallationDatabaseSingleTenantRDSMySQL, true},
 		{model.InstallationDatabaseSingleTenantRDSPostgres, true},
+		{model.InstallationDatabaseExternal, true},
 	}
 
 	for _, tc := range testCases {
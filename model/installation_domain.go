@@ -0,0 +1,172 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// InstallationDomainStatePendingVerification is a domain waiting for the
+	// customer to publish its verification record.
+	InstallationDomainStatePendingVerification = "pending-verification"
+	// InstallationDomainStatePendingCNAME is a verified domain waiting for
+	// the customer to point it at the installation.
+	InstallationDomainStatePendingCNAME = "pending-cname"
+	// InstallationDomainStateIssuingCertificate is a domain served by the
+	// installation ingress whose certificate is not issued yet.
+	InstallationDomainStateIssuingCertificate = "issuing-certificate"
+	// InstallationDomainStateActive is a domain served by the installation
+	// ingress with a valid certificate.
+	InstallationDomainStateActive = "active"
+	// InstallationDomainStateDeletionRequested is a domain to be removed from
+	// the installation ingress.
+	InstallationDomainStateDeletionRequested = "deletion-requested"
+	// InstallationDomainStateDeleted is a domain removed from the
+	// installation.
+	InstallationDomainStateDeleted = "deleted"
+)
+
+// installationDomainVerificationPrefix is the subdomain of a custom domain
+// that holds its TXT verification record.
+const installationDomainVerificationPrefix = "_mattermost-cloud-verification"
+
+var installationDomainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// InstallationDomain is a custom hostname of a customer attached to an
+// installation in addition to its DNS name.
+type InstallationDomain struct {
+	ID                string
+	InstallationID    string
+	Domain            string
+	VerificationToken string
+	State             string
+	Message           string `json:"Message,omitempty"`
+	CheckedAt         int64
+	CreateAt          int64
+	DeleteAt          int64
+}
+
+// InstallationDomainFilter describes the parameters used to constrain a set
+// of installation domains.
+type InstallationDomainFilter struct {
+	InstallationID string
+	Domain         string
+	IncludeDeleted bool
+	Page           int
+	PerPage        int
+}
+
+// DNSRecord is a DNS record the customer must publish for a custom domain.
+type DNSRecord struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// VerificationRecord returns the TXT record proving that the customer owns
+// the domain.
+func (d *InstallationDomain) VerificationRecord() DNSRecord {
+	return DNSRecord{
+		Name:  installationDomainVerificationPrefix + "." + d.Domain,
+		Type:  "TXT",
+		Value: d.VerificationToken,
+	}
+}
+
+// IsServed returns true if the domain is expected to be part of the
+// installation ingress.
+func (d *InstallationDomain) IsServed() bool {
+	return d.State == InstallationDomainStateIssuingCertificate || d.State == InstallationDomainStateActive
+}
+
+// IsDeleted returns true if the domain is marked as deleted.
+func (d *InstallationDomain) IsDeleted() bool {
+	return d.DeleteAt != 0
+}
+
+// InstallationDomainDTO is an installation domain along with the DNS records
+// the customer must publish for it.
+type InstallationDomainDTO struct {
+	*InstallationDomain
+	Records []DNSRecord
+}
+
+// NewInstallationDomainDTO returns the DTO of the given domain, which must be
+// pointed at the given installation DNS name.
+func NewInstallationDomainDTO(domain *InstallationDomain, installationDNS string) *InstallationDomainDTO {
+	return &InstallationDomainDTO{
+		InstallationDomain: domain,
+		Records: []DNSRecord{
+			domain.VerificationRecord(),
+			{Name: domain.Domain, Type: "CNAME", Value: installationDNS},
+		},
+	}
+}
+
+// CreateInstallationDomainRequest specifies the parameters for a new
+// installation domain.
+type CreateInstallationDomainRequest struct {
+	Domain string
+}
+
+// Validate validates the values of an installation domain create request.
+func (request *CreateInstallationDomainRequest) Validate() error {
+	if !installationDomainPattern.MatchString(request.Domain) {
+		return errors.Errorf("invalid domain %q", request.Domain)
+	}
+
+	return nil
+}
+
+// NewCreateInstallationDomainRequestFromReader will create a
+// CreateInstallationDomainRequest from an io.Reader with JSON data.
+func NewCreateInstallationDomainRequestFromReader(reader io.Reader) (*CreateInstallationDomainRequest, error) {
+	var request CreateInstallationDomainRequest
+	err := json.NewDecoder(reader).Decode(&request)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode create installation domain request")
+	}
+
+	request.Domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(request.Domain)), ".")
+
+	err = request.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "create installation domain request failed validation")
+	}
+
+	return &request, nil
+}
+
+// InstallationDomainDTOFromReader decodes a json-encoded installation domain
+// from the given io.Reader.
+func InstallationDomainDTOFromReader(reader io.Reader) (*InstallationDomainDTO, error) {
+	domain := InstallationDomainDTO{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&domain)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &domain, nil
+}
+
+// InstallationDomainDTOsFromReader decodes a json-encoded list of
+// installation domains from the given io.Reader.
+func InstallationDomainDTOsFromReader(reader io.Reader) ([]*InstallationDomainDTO, error) {
+	domains := []*InstallationDomainDTO{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&domains)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return domains, nil
+}
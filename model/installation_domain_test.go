@@ -0,0 +1,80 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCreateInstallationDomainRequestFromReader(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		request, err := NewCreateInstallationDomainRequestFromReader(bytes.NewReader([]byte(`{"Domain":" Chat.Example.com. "}`)))
+		require.NoError(t, err)
+		require.Equal(t, "chat.example.com", request.Domain)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := NewCreateInstallationDomainRequestFromReader(bytes.NewReader([]byte("")))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid domains", func(t *testing.T) {
+		for _, domain := range []string{"example", "-chat.example.com", "chat_1.example.com", "*.example.com", "chat.example.com/path"} {
+			_, err := NewCreateInstallationDomainRequestFromReader(bytes.NewReader([]byte(`{"Domain":"` + domain + `"}`)))
+			require.Error(t, err, domain)
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := NewCreateInstallationDomainRequestFromReader(bytes.NewReader([]byte(`{test`)))
+		require.Error(t, err)
+	})
+}
+
+func TestInstallationDomain(t *testing.T) {
+	domain := &InstallationDomain{
+		Domain:            "chat.example.com",
+		VerificationToken: "token",
+		State:             InstallationDomainStatePendingVerification,
+	}
+	require.False(t, domain.IsServed())
+	require.False(t, domain.IsDeleted())
+
+	dto := NewInstallationDomainDTO(domain, "installation.cloud.com")
+	require.Equal(t, []DNSRecord{
+		{Name: "_mattermost-cloud-verification.chat.example.com", Type: "TXT", Value: "token"},
+		{Name: "chat.example.com", Type: "CNAME", Value: "installation.cloud.com"},
+	}, dto.Records)
+
+	domain.State = InstallationDomainStateIssuingCertificate
+	require.True(t, domain.IsServed())
+	domain.State = InstallationDomainStateActive
+	require.True(t, domain.IsServed())
+}
+
+func TestInstallationDomainDTOsFromReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		domains, err := InstallationDomainDTOsFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Empty(t, domains)
+	})
+
+	t.Run("domains", func(t *testing.T) {
+		domains, err := InstallationDomainDTOsFromReader(bytes.NewReader([]byte(`[{"ID":"id","Domain":"chat.example.com","State":"active","Records":[{"Name":"chat.example.com","Type":"CNAME","Value":"installation.cloud.com"}]}]`)))
+		require.NoError(t, err)
+		require.Equal(t, []*InstallationDomainDTO{{
+			InstallationDomain: &InstallationDomain{ID: "id", Domain: "chat.example.com", State: InstallationDomainStateActive},
+			Records:            []DNSRecord{{Name: "chat.example.com", Type: "CNAME", Value: "installation.cloud.com"}},
+		}}, domains)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := InstallationDomainDTOsFromReader(bytes.NewReader([]byte(`{test`)))
+		require.Error(t, err)
+	})
+}
@@ -23,6 +23,9 @@ const (
 	// InstallationFilestoreBifrost is a filestore hosted via a shared Amazon S3
 	// bucket using the bifrost gateway.
 	InstallationFilestoreBifrost = "bifrost"
+	// InstallationFilestoreExternal is an S3 compatible bucket managed outside
+	// of the provisioner and supplied with the installation.
+	InstallationFilestoreExternal = "external"
 )
 
 // Filestore is the interface for managing Mattermost filestores.
@@ -30,6 +33,9 @@ type Filestore interface {
 	Provision(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
 	Teardown(keepData bool, store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
 	GenerateFilestoreSpecAndSecret(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*mmv1alpha1.Minio, *corev1.Secret, error)
+	Usage(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*StorageUsage, error)
+	RotateCredentials(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+	RevokeStaleCredentials(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
 }
 
 // MinioOperatorFilestore is a filestore backed by the MinIO operator.
@@ -63,6 +69,24 @@ func (f *MinioOperatorFilestore) GenerateFilestoreSpecAndSecret(store Installati
 	return nil, nil, nil
 }
 
+// Usage returns nil as the MinIO operator filestore usage can only be measured
+// from inside the kubernetes cluster.
+func (f *MinioOperatorFilestore) Usage(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*StorageUsage, error) {
+	return nil, nil
+}
+
+// RotateCredentials is a noop as the MinIO operator filestore credentials are
+// managed inside the kubernetes cluster.
+func (f *MinioOperatorFilestore) RotateCredentials(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return nil
+}
+
+// RevokeStaleCredentials is a noop as the MinIO operator filestore credentials
+// are managed inside the kubernetes cluster.
+func (f *MinioOperatorFilestore) RevokeStaleCredentials(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error {
+	return nil
+}
+
 // InternalFilestore returns true if the installation's filestore is internal
 // to the kubernetes cluster it is running on.
 func (i *Installation) InternalFilestore() bool {
@@ -74,5 +98,6 @@ func IsSupportedFilestore(filestore string) bool {
 	return filestore == InstallationFilestoreMinioOperator ||
 		filestore == InstallationFilestoreAwsS3 ||
 		filestore == InstallationFilestoreMultiTenantAwsS3 ||
-		filestore == InstallationFilestoreBifrost
+		filestore == InstallationFilestoreBifrost ||
+		filestore == InstallationFilestoreExternal
 }
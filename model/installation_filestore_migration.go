@@ -0,0 +1,145 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// FilestoreMigration tracks the progress of moving the files of an
+// installation from one filestore type to another.
+type FilestoreMigration struct {
+	SourceFilestore   string
+	TargetFilestore   string
+	SourceObjectCount int64
+	TargetObjectCount int64
+	CopyAttempts      int
+	// FinalSync is set once all objects were copied and the app was scaled
+	// down for the last copy, which picks up the files written in the
+	// meantime. The app is scaled back up on the target filestore.
+	FinalSync  bool
+	StartAt    int64
+	CompleteAt int64
+}
+
+// ToJSON marshals the filestore migration to JSON if it is not nil.
+func (m *FilestoreMigration) ToJSON() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// IsComplete returns true if the filestore migration has finished.
+func (m *FilestoreMigration) IsComplete() bool {
+	return m.CompleteAt != 0
+}
+
+// FilestoreLocation describes where the files of an installation are stored
+// and the credentials an in-cluster job can use to reach them.
+type FilestoreLocation struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// FilestoreMigrationConfig contains the information required to copy the
+// files of an installation between two filestores.
+type FilestoreMigrationConfig struct {
+	// Source is nil when the source filestore is internal to the cluster
+	// and can only be reached from the cluster installation namespace.
+	Source *FilestoreLocation
+	Target *FilestoreLocation
+}
+
+// FilestoreMigrationJobStatus is the status of the in-cluster job copying
+// files between filestores.
+type FilestoreMigrationJobStatus struct {
+	Complete          bool
+	Failed            bool
+	SourceObjectCount int64
+	TargetObjectCount int64
+}
+
+// FilestoreMigrator is the interface for managing the resources needed to
+// move the files of an installation between filestores.
+type FilestoreMigrator interface {
+	Setup(store InstallationDatabaseStoreInterface, logger log.FieldLogger) (*FilestoreMigrationConfig, error)
+	Cutover(store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+	Teardown(keepData bool, store InstallationDatabaseStoreInterface, logger log.FieldLogger) error
+}
+
+// MigrateInstallationFilestoreRequest specifies the parameters for moving an
+// installation to a new filestore.
+type MigrateInstallationFilestoreRequest struct {
+	Filestore string
+}
+
+// Validate validates the values of a filestore migration request.
+func (request *MigrateInstallationFilestoreRequest) Validate() error {
+	if !IsSupportedFilestore(request.Filestore) {
+		return errors.Errorf("unsupported filestore %s", request.Filestore)
+	}
+	if request.Filestore == InstallationFilestoreMinioOperator {
+		return errors.New("migrating to the MinIO operator filestore is not supported")
+	}
+
+	return nil
+}
+
+// NewMigrateInstallationFilestoreRequestFromReader will create a
+// MigrateInstallationFilestoreRequest from an io.Reader with JSON data.
+func NewMigrateInstallationFilestoreRequestFromReader(reader io.Reader) (*MigrateInstallationFilestoreRequest, error) {
+	var migrateRequest MigrateInstallationFilestoreRequest
+	err := json.NewDecoder(reader).Decode(&migrateRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode migrate filestore request")
+	}
+
+	err = migrateRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate filestore request failed validation")
+	}
+
+	return &migrateRequest, nil
+}
+
+// ValidateFilestoreMigration returns an error if the files of an installation
+// can't be moved between the given filestore types.
+func ValidateFilestoreMigration(source, target string) error {
+	if source == target {
+		return errors.Errorf("installation is already using filestore %s", target)
+	}
+
+	// External filestores are managed by their owners and can't be reached
+	// with provisioner credentials.
+	if source == InstallationFilestoreExternal || target == InstallationFilestoreExternal {
+		return errors.New("external filestores can't be migrated")
+	}
+
+	// The multitenant S3 and bifrost filestores keep installation files in the
+	// same bucket directory so there is nothing to copy between them.
+	if sharesFilestoreDirectory(source, target) {
+		return errors.Errorf("filestores %s and %s share the same storage location", source, target)
+	}
+
+	return nil
+}
+
+func sharesFilestoreDirectory(source, target string) bool {
+	shared := map[string]bool{
+		InstallationFilestoreMultiTenantAwsS3: true,
+		InstallationFilestoreBifrost:          true,
+	}
+
+	return shared[source] && shared[target]
+}
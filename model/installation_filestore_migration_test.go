@@ -0,0 +1,84 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMigrateInstallationFilestoreRequestFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		_, err := model.NewMigrateInstallationFilestoreRequestFromReader(bytes.NewReader([]byte("")))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := model.NewMigrateInstallationFilestoreRequestFromReader(bytes.NewReader([]byte("{test")))
+		require.Error(t, err)
+	})
+
+	t.Run("unsupported filestore", func(t *testing.T) {
+		_, err := model.NewMigrateInstallationFilestoreRequestFromReader(bytes.NewReader([]byte(`{"Filestore":"unknown"}`)))
+		require.Error(t, err)
+	})
+
+	t.Run("minio operator target", func(t *testing.T) {
+		_, err := model.NewMigrateInstallationFilestoreRequestFromReader(bytes.NewReader([]byte(`{"Filestore":"minio-operator"}`)))
+		require.Error(t, err)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		request, err := model.NewMigrateInstallationFilestoreRequestFromReader(bytes.NewReader([]byte(`{"Filestore":"aws-s3"}`)))
+		require.NoError(t, err)
+		assert.Equal(t, &model.MigrateInstallationFilestoreRequest{Filestore: model.InstallationFilestoreAwsS3}, request)
+	})
+}
+
+func TestValidateFilestoreMigration(t *testing.T) {
+	var testCases = []struct {
+		source      string
+		target      string
+		expectError bool
+	}{
+		{model.InstallationFilestoreAwsS3, model.InstallationFilestoreAwsS3, true},
+		{model.InstallationFilestoreMultiTenantAwsS3, model.InstallationFilestoreBifrost, true},
+		{model.InstallationFilestoreBifrost, model.InstallationFilestoreMultiTenantAwsS3, true},
+		{model.InstallationFilestoreExternal, model.InstallationFilestoreAwsS3, true},
+		{model.InstallationFilestoreAwsS3, model.InstallationFilestoreExternal, true},
+		{model.InstallationFilestoreMinioOperator, model.InstallationFilestoreAwsS3, false},
+		{model.InstallationFilestoreMinioOperator, model.InstallationFilestoreBifrost, false},
+		{model.InstallationFilestoreAwsS3, model.InstallationFilestoreMultiTenantAwsS3, false},
+		{model.InstallationFilestoreBifrost, model.InstallationFilestoreAwsS3, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.source+"-to-"+tc.target, func(t *testing.T) {
+			err := model.ValidateFilestoreMigration(tc.source, tc.target)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFilestoreMigrationToJSON(t *testing.T) {
+	var migration *model.FilestoreMigration
+	b, err := migration.ToJSON()
+	require.NoError(t, err)
+	assert.Nil(t, b)
+
+	migration = &model.FilestoreMigration{CopyAttempts: 1}
+	b, err = migration.ToJSON()
+	require.NoError(t, err)
+	assert.NotNil(t, b)
+	assert.False(t, migration.IsComplete())
+}
@@ -42,6 +42,7 @@ func TestIsSupportedFilestore(t *testing.T) {
 		{"unknown", false},
 		{model.InstallationFilestoreMinioOperator, true},
 		{model.InstallationFilestoreAwsS3, true},
+		{model.InstallationFilestoreExternal, true},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,136 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// NetworkIsolationProfileDefault isolates the installation namespace from
+	// other namespaces without restricting outbound traffic.
+	NetworkIsolationProfileDefault = "default"
+	// NetworkIsolationProfileRestricted additionally restricts outbound
+	// traffic to DNS, the installation namespace, the CIDR blocks of the
+	// cluster VPC hosting the database and S3 endpoints, and the egress
+	// allow-list of the installation. Clusters whose VPC is unknown allow
+	// all private address ranges instead. The profile is not supported on
+	// EKS clusters, which do not enforce network policies.
+	NetworkIsolationProfileRestricted = "restricted"
+)
+
+// maxNetworkEgressRules is the maximum number of egress allow-list rules of
+// an installation.
+const maxNetworkEgressRules = 50
+
+// NetworkIsolation is the network isolation profile of an installation.
+type NetworkIsolation struct {
+	Profile         string
+	EgressAllowList []NetworkEgressRule `json:"EgressAllowList,omitempty"`
+}
+
+// NetworkEgressRule allows outbound traffic of an installation to a CIDR on
+// the given ports, or on all ports if none are given.
+type NetworkEgressRule struct {
+	CIDR     string
+	Protocol string  `json:"Protocol,omitempty"`
+	Ports    []int32 `json:"Ports,omitempty"`
+}
+
+// IsRestricted returns true if the outbound traffic of the installation is
+// restricted.
+func (n *NetworkIsolation) IsRestricted() bool {
+	return n != nil && n.Profile == NetworkIsolationProfileRestricted
+}
+
+// ToJSON marshals the network isolation to JSON if it is not nil.
+func (n *NetworkIsolation) ToJSON() ([]byte, error) {
+	if n == nil {
+		return nil, nil
+	}
+	return json.Marshal(n)
+}
+
+// SetDefaults sets the default values of a network isolation profile.
+func (n *NetworkIsolation) SetDefaults() {
+	if len(n.Profile) == 0 {
+		n.Profile = NetworkIsolationProfileDefault
+	}
+	for i := range n.EgressAllowList {
+		n.EgressAllowList[i].Protocol = strings.ToUpper(n.EgressAllowList[i].Protocol)
+		if len(n.EgressAllowList[i].Protocol) == 0 {
+			n.EgressAllowList[i].Protocol = "TCP"
+		}
+	}
+}
+
+// Validate validates a network isolation profile.
+func (n *NetworkIsolation) Validate() error {
+	switch n.Profile {
+	case NetworkIsolationProfileDefault:
+		if len(n.EgressAllowList) != 0 {
+			return errors.Errorf("egress allow-list requires the %s profile", NetworkIsolationProfileRestricted)
+		}
+	case NetworkIsolationProfileRestricted:
+	default:
+		return errors.Errorf("unsupported network isolation profile %s", n.Profile)
+	}
+
+	if len(n.EgressAllowList) > maxNetworkEgressRules {
+		return errors.Errorf("egress allow-list has %d rules, but at most %d are supported", len(n.EgressAllowList), maxNetworkEgressRules)
+	}
+	for _, rule := range n.EgressAllowList {
+		err := rule.Validate()
+		if err != nil {
+			return errors.Wrapf(err, "invalid egress rule for %s", rule.CIDR)
+		}
+	}
+
+	return nil
+}
+
+// ValidateFilestore returns an error if an installation with the network
+// isolation profile can't reach the given filestore type. Restricted
+// installations reach S3 filestores through the regional S3 endpoints, but
+// can't reach the bifrost service of the cluster, and can only reach external
+// filestores through their egress allow-list.
+func (n *NetworkIsolation) ValidateFilestore(filestore string) error {
+	if !n.IsRestricted() {
+		return nil
+	}
+
+	switch filestore {
+	case InstallationFilestoreBifrost:
+		return errors.Errorf("the %s filestore is not supported by the %s profile", filestore, NetworkIsolationProfileRestricted)
+	case InstallationFilestoreExternal:
+		if len(n.EgressAllowList) == 0 {
+			return errors.Errorf("the %s filestore requires an egress allow-list with the %s profile", filestore, NetworkIsolationProfileRestricted)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates an egress allow-list rule.
+func (r *NetworkEgressRule) Validate() error {
+	_, _, err := net.ParseCIDR(r.CIDR)
+	if err != nil {
+		return errors.Errorf("invalid CIDR %q", r.CIDR)
+	}
+	if r.Protocol != "TCP" && r.Protocol != "UDP" {
+		return errors.Errorf("unsupported protocol %s", r.Protocol)
+	}
+	for _, port := range r.Ports {
+		if port < 1 || port > 65535 {
+			return errors.Errorf("port %d must be between 1 and 65535", port)
+		}
+	}
+
+	return nil
+}
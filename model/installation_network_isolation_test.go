@@ -0,0 +1,82 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkIsolationValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		isolation    *model.NetworkIsolation
+		requireError bool
+	}{
+		{"defaults", &model.NetworkIsolation{}, false},
+		{"restricted", &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted}, false},
+		{"unknown profile", &model.NetworkIsolation{Profile: "open"}, true},
+		{"allow-list", &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted, EgressAllowList: []model.NetworkEgressRule{{CIDR: "203.0.113.0/24", Ports: []int32{443}}}}, false},
+		{"udp allow-list", &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted, EgressAllowList: []model.NetworkEgressRule{{CIDR: "203.0.113.0/24", Protocol: "udp"}}}, false},
+		{"allow-list with default profile", &model.NetworkIsolation{EgressAllowList: []model.NetworkEgressRule{{CIDR: "203.0.113.0/24"}}}, true},
+		{"invalid CIDR", &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted, EgressAllowList: []model.NetworkEgressRule{{CIDR: "example.com"}}}, true},
+		{"invalid protocol", &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted, EgressAllowList: []model.NetworkEgressRule{{CIDR: "203.0.113.0/24", Protocol: "SCTP"}}}, true},
+		{"invalid port", &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted, EgressAllowList: []model.NetworkEgressRule{{CIDR: "203.0.113.0/24", Ports: []int32{70000}}}}, true},
+		{"too many rules", &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted, EgressAllowList: make([]model.NetworkEgressRule, 51)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			tc.isolation.SetDefaults()
+
+			if tc.requireError {
+				assert.Error(t, tc.isolation.Validate())
+			} else {
+				assert.NoError(t, tc.isolation.Validate())
+			}
+		})
+	}
+}
+
+func TestNetworkIsolationIsRestricted(t *testing.T) {
+	var isolation *model.NetworkIsolation
+	assert.False(t, isolation.IsRestricted())
+	assert.False(t, (&model.NetworkIsolation{Profile: model.NetworkIsolationProfileDefault}).IsRestricted())
+	assert.True(t, (&model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted}).IsRestricted())
+}
+
+func TestNetworkIsolationValidateFilestore(t *testing.T) {
+	restricted := &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted}
+	allowList := &model.NetworkIsolation{Profile: model.NetworkIsolationProfileRestricted, EgressAllowList: []model.NetworkEgressRule{{CIDR: "203.0.113.0/24"}}}
+
+	var testCases = []struct {
+		testName     string
+		isolation    *model.NetworkIsolation
+		filestore    string
+		requireError bool
+	}{
+		{"no isolation", nil, model.InstallationFilestoreBifrost, false},
+		{"default profile", &model.NetworkIsolation{Profile: model.NetworkIsolationProfileDefault}, model.InstallationFilestoreBifrost, false},
+		{"restricted s3", restricted, model.InstallationFilestoreAwsS3, false},
+		{"restricted multitenant s3", restricted, model.InstallationFilestoreMultiTenantAwsS3, false},
+		{"restricted minio", restricted, model.InstallationFilestoreMinioOperator, false},
+		{"restricted bifrost", restricted, model.InstallationFilestoreBifrost, true},
+		{"restricted bifrost with allow-list", allowList, model.InstallationFilestoreBifrost, true},
+		{"restricted external", restricted, model.InstallationFilestoreExternal, true},
+		{"restricted external with allow-list", allowList, model.InstallationFilestoreExternal, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.isolation.ValidateFilestore(tc.filestore))
+			} else {
+				assert.NoError(t, tc.isolation.ValidateFilestore(tc.filestore))
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -35,6 +36,7 @@ type CreateInstallationRequest struct {
 	License         string
 	Size            string
 	Affinity        string
+	NodeGroup       string
 	Database        string
 	Filestore       string
 	APISecurityLock bool
@@ -42,6 +44,20 @@ type CreateInstallationRequest struct {
 	Annotations     []string
 	// SingleTenantDatabaseConfig is ignored if Database is not single tenant mysql or postgres.
 	SingleTenantDatabaseConfig SingleTenantDatabaseRequest
+	// ExternalDatabaseConfig is required if Database is external and ignored
+	// otherwise.
+	ExternalDatabaseConfig *ExternalDatabaseRequest `json:"ExternalDatabaseConfig,omitempty"`
+	// ExternalFilestoreConfig is required if Filestore is external and ignored
+	// otherwise.
+	ExternalFilestoreConfig *ExternalFilestoreRequest `json:"ExternalFilestoreConfig,omitempty"`
+	// NetworkIsolation is the network isolation profile of the installation.
+	// The default profile is used if it is not set.
+	NetworkIsolation *NetworkIsolation `json:"NetworkIsolation,omitempty"`
+	// ResourceQuota overrides the resource quota of the installation
+	// namespace, which is otherwise derived from the installation size.
+	ResourceQuota *InstallationResourceQuota `json:"ResourceQuota,omitempty"`
+	// CustomSize overrides the replicas and resources of the Size preset.
+	CustomSize *InstallationCustomSize `json:"CustomSize,omitempty"`
 }
 
 // https://man7.org/linux/man-pages/man7/hostname.7.html
@@ -70,6 +86,12 @@ func (request *CreateInstallationRequest) SetDefaults() {
 	if IsSingleTenantRDS(request.Database) {
 		request.SingleTenantDatabaseConfig.SetDefaults()
 	}
+	if request.NetworkIsolation != nil {
+		request.NetworkIsolation.SetDefaults()
+	}
+	if request.CustomSize.IsEmpty() {
+		request.CustomSize = nil
+	}
 }
 
 // Validate validates the values of an installation create request.
@@ -91,6 +113,9 @@ func (request *CreateInstallationRequest) Validate() error {
 	if !IsSupportedAffinity(request.Affinity) {
 		return errors.Errorf("unsupported affinity %s", request.Affinity)
 	}
+	if len(request.NodeGroup) != 0 && !nodeGroupNamePattern.MatchString(request.NodeGroup) {
+		return errors.Errorf("invalid node group %s", request.NodeGroup)
+	}
 	if !IsSupportedDatabase(request.Database) {
 		return errors.Errorf("unsupported database %s", request.Database)
 	}
@@ -113,6 +138,55 @@ func (request *CreateInstallationRequest) Validate() error {
 			return errors.Wrap(err, "single tenant database config is invalid")
 		}
 	}
+	if request.Database == InstallationDatabaseExternal {
+		err = request.ExternalDatabaseConfig.Validate()
+		if err != nil {
+			return errors.Wrap(err, "external database config is invalid")
+		}
+	}
+	if request.Filestore == InstallationFilestoreExternal {
+		err = request.ExternalFilestoreConfig.Validate()
+		if err != nil {
+			return errors.Wrap(err, "external filestore config is invalid")
+		}
+	}
+	if request.NetworkIsolation != nil {
+		err = request.NetworkIsolation.Validate()
+		if err != nil {
+			return errors.Wrap(err, "network isolation is invalid")
+		}
+		err = request.NetworkIsolation.ValidateFilestore(request.Filestore)
+		if err != nil {
+			return errors.Wrap(err, "network isolation is invalid")
+		}
+	}
+	if request.ResourceQuota != nil {
+		err = request.ResourceQuota.Validate()
+		if err != nil {
+			return errors.Wrap(err, "resource quota is invalid")
+		}
+	}
+	if request.CustomSize != nil {
+		err = request.CustomSize.Validate()
+		if err != nil {
+			return errors.Wrap(err, "custom size is invalid")
+		}
+	}
+	installation := &Installation{
+		Size:          request.Size,
+		Database:      request.Database,
+		Filestore:     request.Filestore,
+		ResourceQuota: request.ResourceQuota,
+		CustomSize:    request.CustomSize,
+	}
+	err = installation.ValidateCustomSize()
+	if err != nil {
+		return errors.Wrap(err, "custom size is invalid")
+	}
+	err = installation.ValidateResourceQuota()
+	if err != nil {
+		return errors.Wrap(err, "resource quota is invalid")
+	}
 	return checkSpaces(request)
 }
 
@@ -234,6 +308,10 @@ type PatchInstallationRequest struct {
 	Size          *string
 	License       *string
 	MattermostEnv EnvVarMap
+	// CustomSize replaces the custom app size of the installation. An empty
+	// custom size removes it. The database and filestore sizes can only be
+	// set when creating the installation.
+	CustomSize *InstallationCustomSize `json:"CustomSize,omitempty"`
 }
 
 // Validate validates the values of a installation patch request.
@@ -250,6 +328,15 @@ func (p *PatchInstallationRequest) Validate() error {
 			return errors.Wrap(err, "invalid size")
 		}
 	}
+	if p.CustomSize != nil {
+		if p.CustomSize.Database != nil || p.CustomSize.Filestore != nil {
+			return errors.New("custom database and filestore sizes can only be set when creating an installation")
+		}
+		err := p.CustomSize.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid custom size")
+		}
+	}
 	// EnvVarMap validation is skipped as all configurations of this now imply
 	// a specific patch action should be taken.
 
@@ -281,6 +368,22 @@ func (p *PatchInstallationRequest) Apply(installation *Installation) bool {
 			applied = true
 		}
 	}
+	if p.CustomSize != nil {
+		// The operator doesn't resize the database and filestore of existing
+		// installations, so their custom sizes are kept as they are.
+		customSize := &InstallationCustomSize{App: p.CustomSize.App}
+		if installation.CustomSize != nil {
+			customSize.Database = installation.CustomSize.Database
+			customSize.Filestore = installation.CustomSize.Filestore
+		}
+		if customSize.IsEmpty() {
+			customSize = nil
+		}
+		if !reflect.DeepEqual(customSize, installation.CustomSize) {
+			applied = true
+			installation.CustomSize = customSize
+		}
+	}
 
 	return applied
 }
@@ -0,0 +1,136 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// resourceQuotaOverheadPercent is the share of the size requirements added to
+// the resource quota of an installation for sidecars and jobs that are not
+// accounted for by the installation size.
+const resourceQuotaOverheadPercent = 20
+
+// InstallationResourceQuota overrides the resource quota of an installation
+// namespace, which is otherwise derived from the installation size.
+type InstallationResourceQuota struct {
+	// CPU is the total CPU requests allowed in the namespace, e.g. "4".
+	CPU string `json:"CPU,omitempty"`
+	// Memory is the total memory requests allowed in the namespace, e.g.
+	// "8Gi".
+	Memory string `json:"Memory,omitempty"`
+}
+
+// ToJSON marshals the resource quota to JSON if it is not nil.
+func (q *InstallationResourceQuota) ToJSON() ([]byte, error) {
+	if q == nil {
+		return nil, nil
+	}
+	return json.Marshal(q)
+}
+
+// Validate validates a resource quota override.
+func (q *InstallationResourceQuota) Validate() error {
+	if len(q.CPU) == 0 && len(q.Memory) == 0 {
+		return errors.New("at least one of CPU or memory must be set")
+	}
+	if len(q.CPU) != 0 {
+		err := validatePositiveQuantity(q.CPU)
+		if err != nil {
+			return errors.Wrap(err, "invalid CPU")
+		}
+	}
+	if len(q.Memory) != 0 {
+		err := validatePositiveQuantity(q.Memory)
+		if err != nil {
+			return errors.Wrap(err, "invalid memory")
+		}
+	}
+
+	return nil
+}
+
+// ValidateResourceQuota returns an error when the resource quota override of
+// an installation is below the quota derived from its size, which would keep
+// the pods of the installation from being scheduled.
+func (i *Installation) ValidateResourceQuota() error {
+	if i.ResourceQuota == nil {
+		return nil
+	}
+
+	withoutOverride := *i
+	withoutOverride.ResourceQuota = nil
+	requiredCPU, requiredMemory, err := withoutOverride.CalculateResourceQuotaMilliRequests()
+	if err != nil {
+		return err
+	}
+	cpu, memory, err := i.CalculateResourceQuotaMilliRequests()
+	if err != nil {
+		return err
+	}
+
+	if cpu < requiredCPU {
+		return errors.Errorf("CPU quota %s is below the %s required by the installation size",
+			i.ResourceQuota.CPU, resource.NewMilliQuantity(requiredCPU, resource.DecimalSI))
+	}
+	if memory < requiredMemory {
+		return errors.Errorf("memory quota %s is below the %s required by the installation size",
+			i.ResourceQuota.Memory, resource.NewQuantity(requiredMemory/1000, resource.BinarySI))
+	}
+
+	return nil
+}
+
+func validatePositiveQuantity(value string) error {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return errors.Errorf("%q is not a valid quantity", value)
+	}
+	if quantity.Sign() <= 0 {
+		return errors.Errorf("%q must be positive", value)
+	}
+
+	return nil
+}
+
+// CalculateResourceQuotaMilliRequests returns the CPU and memory milli
+// requests reserved for the installation by the resource quota of its
+// namespace. Unless overridden, the quota covers the requirements of the
+// installation size including its custom size, one more app replica for
+// rolling updates and a share for overhead.
+func (i *Installation) CalculateResourceQuotaMilliRequests() (int64, int64, error) {
+	size, err := i.GetSize()
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid installation size")
+	}
+	size.App.Replicas++
+
+	cpu := size.CalculateCPUMilliRequirement(i.InternalDatabase(), i.InternalFilestore())
+	cpu += cpu * resourceQuotaOverheadPercent / 100
+	memory := size.CalculateMemoryMilliRequirement(i.InternalDatabase(), i.InternalFilestore())
+	memory += memory * resourceQuotaOverheadPercent / 100
+
+	if i.ResourceQuota != nil {
+		if len(i.ResourceQuota.CPU) != 0 {
+			quantity, err := resource.ParseQuantity(i.ResourceQuota.CPU)
+			if err != nil {
+				return 0, 0, errors.Wrap(err, "invalid CPU quota")
+			}
+			cpu = quantity.MilliValue()
+		}
+		if len(i.ResourceQuota.Memory) != 0 {
+			quantity, err := resource.ParseQuantity(i.ResourceQuota.Memory)
+			if err != nil {
+				return 0, 0, errors.Wrap(err, "invalid memory quota")
+			}
+			memory = quantity.MilliValue()
+		}
+	}
+
+	return cpu, memory, nil
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationResourceQuotaValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		quota        *model.InstallationResourceQuota
+		requireError bool
+	}{
+		{"empty", &model.InstallationResourceQuota{}, true},
+		{"cpu", &model.InstallationResourceQuota{CPU: "1500m"}, false},
+		{"memory", &model.InstallationResourceQuota{Memory: "8Gi"}, false},
+		{"cpu and memory", &model.InstallationResourceQuota{CPU: "4", Memory: "8Gi"}, false},
+		{"invalid cpu", &model.InstallationResourceQuota{CPU: "four"}, true},
+		{"invalid memory", &model.InstallationResourceQuota{Memory: "8 GB"}, true},
+		{"zero cpu", &model.InstallationResourceQuota{CPU: "0"}, true},
+		{"negative memory", &model.InstallationResourceQuota{Memory: "-1Gi"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.quota.Validate())
+			} else {
+				assert.NoError(t, tc.quota.Validate())
+			}
+		})
+	}
+}
+
+func TestCalculateResourceQuotaMilliRequests(t *testing.T) {
+	t.Run("external database and filestore", func(t *testing.T) {
+		installation := &model.Installation{
+			Size:      mmv1alpha1.Size100String,
+			Database:  model.InstallationDatabaseMultiTenantRDSPostgres,
+			Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+		}
+		cpu, memory, err := installation.CalculateResourceQuotaMilliRequests()
+		require.NoError(t, err)
+		// Two app replicas of 100m and 256Mi plus 20% overhead.
+		assert.Equal(t, int64(240), cpu)
+		assert.Equal(t, int64(512*1048576*1000*12/10), memory)
+	})
+
+	t.Run("internal database and filestore", func(t *testing.T) {
+		installation := &model.Installation{
+			Size:      mmv1alpha1.Size100String,
+			Database:  model.InstallationDatabaseMysqlOperator,
+			Filestore: model.InstallationFilestoreMinioOperator,
+		}
+		cpu, _, err := installation.CalculateResourceQuotaMilliRequests()
+		require.NoError(t, err)
+		assert.Equal(t, int64(600), cpu)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		installation := &model.Installation{
+			Size:          mmv1alpha1.Size100String,
+			ResourceQuota: &model.InstallationResourceQuota{Memory: "1Gi"},
+		}
+		cpu, memory, err := installation.CalculateResourceQuotaMilliRequests()
+		require.NoError(t, err)
+		assert.Equal(t, int64(240), cpu)
+		assert.Equal(t, int64(1024*1048576*1000), memory)
+	})
+
+	t.Run("custom size", func(t *testing.T) {
+		installation := &model.Installation{
+			Size:      mmv1alpha1.Size100String,
+			Database:  model.InstallationDatabaseMultiTenantRDSPostgres,
+			Filestore: model.InstallationFilestoreMultiTenantAwsS3,
+			CustomSize: &model.InstallationCustomSize{
+				App: &model.InstallationComponentSize{Replicas: 3, CPURequest: "500m"},
+			},
+		}
+		cpu, _, err := installation.CalculateResourceQuotaMilliRequests()
+		require.NoError(t, err)
+		// Four app replicas of 500m plus 20% overhead.
+		assert.Equal(t, int64(2400), cpu)
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		installation := &model.Installation{Size: "invalid"}
+		_, _, err := installation.CalculateResourceQuotaMilliRequests()
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateResourceQuota(t *testing.T) {
+	newInstallation := func(quota *model.InstallationResourceQuota) *model.Installation {
+		return &model.Installation{
+			Size:          mmv1alpha1.Size100String,
+			Database:      model.InstallationDatabaseMultiTenantRDSPostgres,
+			Filestore:     model.InstallationFilestoreMultiTenantAwsS3,
+			ResourceQuota: quota,
+		}
+	}
+
+	t.Run("no override", func(t *testing.T) {
+		assert.NoError(t, newInstallation(nil).ValidateResourceQuota())
+	})
+
+	t.Run("override above size", func(t *testing.T) {
+		assert.NoError(t, newInstallation(&model.InstallationResourceQuota{CPU: "240m", Memory: "8Gi"}).ValidateResourceQuota())
+	})
+
+	t.Run("cpu below size", func(t *testing.T) {
+		err := newInstallation(&model.InstallationResourceQuota{CPU: "200m"}).ValidateResourceQuota()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "240m")
+	})
+
+	t.Run("memory below size", func(t *testing.T) {
+		err := newInstallation(&model.InstallationResourceQuota{Memory: "512Mi"}).ValidateResourceQuota()
+		assert.Error(t, err)
+	})
+
+	t.Run("custom size above override", func(t *testing.T) {
+		installation := newInstallation(&model.InstallationResourceQuota{CPU: "1"})
+		installation.CustomSize = &model.InstallationCustomSize{
+			App: &model.InstallationComponentSize{Replicas: 3, CPURequest: "500m"},
+		}
+		assert.Error(t, installation.ValidateResourceQuota())
+	})
+}
@@ -11,6 +11,9 @@ const (
 	InstallationStateStable = "stable"
 	// InstallationStateCreationRequested is an installation waiting to be created.
 	InstallationStateCreationRequested = "creation-requested"
+	// InstallationStateCreationPending is an installation whose creation
+	// request is still being stored and that is not yet ready to be created.
+	InstallationStateCreationPending = "creation-pending"
 	// InstallationStateCreationPreProvisioning in an installation in the process
 	// of having managed services created along with any other preparation.
 	InstallationStateCreationPreProvisioning = "creation-pre-provisioning"
@@ -40,6 +43,30 @@ const (
 	InstallationStateUpdateInProgress = "update-in-progress"
 	// InstallationStateUpdateFailed is an installation that failed to update.
 	InstallationStateUpdateFailed = "update-failed"
+	// InstallationStateFilestoreMigrationRequested is an installation that is
+	// about to have its files moved to a new filestore.
+	InstallationStateFilestoreMigrationRequested = "filestore-migration-requested"
+	// InstallationStateFilestoreMigrationInProgress is an installation that is
+	// having its files copied to a new filestore.
+	InstallationStateFilestoreMigrationInProgress = "filestore-migration-in-progress"
+	// InstallationStateFilestoreMigrationFinalizing is an installation that
+	// has switched to a new filestore and is cleaning up the old one.
+	InstallationStateFilestoreMigrationFinalizing = "filestore-migration-finalizing"
+	// InstallationStateFilestoreMigrationFailed is an installation that failed
+	// to move to a new filestore.
+	InstallationStateFilestoreMigrationFailed = "filestore-migration-failed"
+	// InstallationStateCredentialRotationRequested is an installation that is
+	// about to have its database and filestore credentials replaced.
+	InstallationStateCredentialRotationRequested = "credential-rotation-requested"
+	// InstallationStateCredentialRotationInProgress is an installation that
+	// is being reconfigured and restarted with new credentials.
+	InstallationStateCredentialRotationInProgress = "credential-rotation-in-progress"
+	// InstallationStateCredentialRotationFinalizing is an installation that is
+	// waiting to run with new credentials before revoking the old ones.
+	InstallationStateCredentialRotationFinalizing = "credential-rotation-finalizing"
+	// InstallationStateCredentialRotationFailed is an installation that failed
+	// to rotate its credentials.
+	InstallationStateCredentialRotationFailed = "credential-rotation-failed"
 	// InstallationStateDeletionRequested is an installation to be deleted.
 	InstallationStateDeletionRequested = "deletion-requested"
 	// InstallationStateDeletionInProgress is an installation being deleted.
@@ -63,6 +90,7 @@ const (
 var AllInstallationStates = []string{
 	InstallationStateStable,
 	InstallationStateCreationRequested,
+	InstallationStateCreationPending,
 	InstallationStateCreationPreProvisioning,
 	InstallationStateCreationInProgress,
 	InstallationStateCreationDNS,
@@ -75,6 +103,14 @@ var AllInstallationStates = []string{
 	InstallationStateUpdateRequested,
 	InstallationStateUpdateInProgress,
 	InstallationStateUpdateFailed,
+	InstallationStateFilestoreMigrationRequested,
+	InstallationStateFilestoreMigrationInProgress,
+	InstallationStateFilestoreMigrationFinalizing,
+	InstallationStateFilestoreMigrationFailed,
+	InstallationStateCredentialRotationRequested,
+	InstallationStateCredentialRotationInProgress,
+	InstallationStateCredentialRotationFinalizing,
+	InstallationStateCredentialRotationFailed,
 	InstallationStateDeletionRequested,
 	InstallationStateDeletionInProgress,
 	InstallationStateDeletionFinalCleanup,
@@ -98,6 +134,12 @@ var AllInstallationStatesPendingWork = []string{
 	InstallationStateHibernationInProgress,
 	InstallationStateUpdateRequested,
 	InstallationStateUpdateInProgress,
+	InstallationStateFilestoreMigrationRequested,
+	InstallationStateFilestoreMigrationInProgress,
+	InstallationStateFilestoreMigrationFinalizing,
+	InstallationStateCredentialRotationRequested,
+	InstallationStateCredentialRotationInProgress,
+	InstallationStateCredentialRotationFinalizing,
 	InstallationStateDeletionRequested,
 	InstallationStateDeletionInProgress,
 	InstallationStateDeletionFinalCleanup,
@@ -112,6 +154,8 @@ var AllInstallationRequestStates = []string{
 	InstallationStateCreationRequested,
 	InstallationStateHibernationRequested,
 	InstallationStateUpdateRequested,
+	InstallationStateFilestoreMigrationRequested,
+	InstallationStateCredentialRotationRequested,
 	InstallationStateDeletionRequested,
 }
 
@@ -125,6 +169,10 @@ func (i *Installation) ValidTransitionState(newState string) bool {
 		return validTransitionToInstallationStateHibernationRequested(i.State)
 	case InstallationStateUpdateRequested:
 		return validTransitionToInstallationStateUpdateRequested(i.State)
+	case InstallationStateFilestoreMigrationRequested:
+		return validTransitionToInstallationStateFilestoreMigrationRequested(i.State)
+	case InstallationStateCredentialRotationRequested:
+		return validTransitionToInstallationStateCredentialRotationRequested(i.State)
 	case InstallationStateDeletionRequested:
 		return validTransitionToInstallationStateDeletionRequested(i.State)
 	}
@@ -135,6 +183,7 @@ func (i *Installation) ValidTransitionState(newState string) bool {
 func validTransitionToInstallationStateCreationRequested(currentState string) bool {
 	switch currentState {
 	case InstallationStateCreationRequested,
+		InstallationStateCreationPending,
 		InstallationStateCreationFailed:
 		return true
 	}
@@ -157,7 +206,31 @@ func validTransitionToInstallationStateUpdateRequested(currentState string) bool
 		InstallationStateHibernating,
 		InstallationStateUpdateRequested,
 		InstallationStateUpdateInProgress,
-		InstallationStateUpdateFailed:
+		InstallationStateUpdateFailed,
+		InstallationStateFilestoreMigrationFailed,
+		InstallationStateCredentialRotationFailed:
+		return true
+	}
+
+	return false
+}
+
+func validTransitionToInstallationStateFilestoreMigrationRequested(currentState string) bool {
+	switch currentState {
+	case InstallationStateStable,
+		InstallationStateFilestoreMigrationRequested,
+		InstallationStateFilestoreMigrationFailed:
+		return true
+	}
+
+	return false
+}
+
+func validTransitionToInstallationStateCredentialRotationRequested(currentState string) bool {
+	switch currentState {
+	case InstallationStateStable,
+		InstallationStateCredentialRotationRequested,
+		InstallationStateCredentialRotationFailed:
 		return true
 	}
 
@@ -168,6 +241,7 @@ func validTransitionToInstallationStateDeletionRequested(currentState string) bo
 	switch currentState {
 	case InstallationStateStable,
 		InstallationStateCreationRequested,
+		InstallationStateCreationPending,
 		InstallationStateCreationPreProvisioning,
 		InstallationStateCreationInProgress,
 		InstallationStateCreationDNS,
@@ -177,6 +251,8 @@ func validTransitionToInstallationStateDeletionRequested(currentState string) bo
 		InstallationStateUpdateRequested,
 		InstallationStateUpdateInProgress,
 		InstallationStateUpdateFailed,
+		InstallationStateFilestoreMigrationFailed,
+		InstallationStateCredentialRotationFailed,
 		InstallationStateDeletionRequested,
 		InstallationStateDeletionInProgress,
 		InstallationStateDeletionFinalCleanup,
@@ -0,0 +1,74 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// InstallationUsage is a point-in-time record of the storage used by an
+// installation.
+type InstallationUsage struct {
+	ID                   string
+	InstallationID       string
+	FilestoreBytes       int64
+	FilestoreObjectCount int64
+	DatabaseBytes        int64
+	CreateAt             int64
+}
+
+// InstallationUsageFilter describes the parameters used to constrain a set of
+// installation usage records.
+type InstallationUsageFilter struct {
+	InstallationID string
+	Page           int
+	PerPage        int
+}
+
+// GetInstallationUsageRequest describes the parameters to request a list of
+// installation usage records.
+type GetInstallationUsageRequest struct {
+	Page    int
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetInstallationUsageRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+
+	u.RawQuery = q.Encode()
+}
+
+// StorageUsage is the storage used by a single installation resource.
+type StorageUsage struct {
+	Bytes       int64
+	ObjectCount int64
+}
+
+// ClusterInstallationVolumeUsage is the storage used by the persistent volumes
+// of the in-cluster resources of a cluster installation.
+type ClusterInstallationVolumeUsage struct {
+	DatabaseBytes  int64
+	FilestoreBytes int64
+}
+
+// InstallationUsagesFromReader decodes a json-encoded list of installation
+// usage records from the given io.Reader.
+func InstallationUsagesFromReader(reader io.Reader) ([]*InstallationUsage, error) {
+	usages := []*InstallationUsage{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&usages)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return usages, nil
+}
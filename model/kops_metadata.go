@@ -18,8 +18,32 @@ type KopsMetadata struct {
 	NodeInstanceType   string
 	NodeMinCount       int64
 	NodeMaxCount       int64
-	ChangeRequest      *KopsMetadataRequestedState `json:"ChangeRequest,omitempty"`
-	Warnings           []string                    `json:"Warnings,omitempty"`
+	// NodeMixedInstancesPolicy is the mixed instances policy of the default
+	// node instance group, if there is one.
+	NodeMixedInstancesPolicy *MixedInstancesPolicy       `json:"NodeMixedInstancesPolicy,omitempty"`
+	NodeGroups               []*KopsNodeGroup            `json:"NodeGroups,omitempty"`
+	ChangeRequest            *KopsMetadataRequestedState `json:"ChangeRequest,omitempty"`
+	Warnings                 []string                    `json:"Warnings,omitempty"`
+
+	// StateStore is the kops state store of an imported cluster when it
+	// differs from the state store of the provisioner.
+	StateStore string `json:"StateStore,omitempty"`
+	// Imported is true for clusters created outside of the provisioner. The
+	// infrastructure of imported clusters is never changed or deleted.
+	Imported bool `json:"Imported,omitempty"`
+
+	// ManagedVPC is true when the VPC of the cluster is created and destroyed
+	// by the provisioner instead of being claimed from the pre-tagged VPC pool.
+	ManagedVPC bool `json:"ManagedVPC,omitempty"`
+	// ManagedVPCCIDR is the CIDR block of the managed VPC of the cluster.
+	ManagedVPCCIDR string `json:"ManagedVPCCIDR,omitempty"`
+	// VPCCIDRs are the CIDR blocks of the VPC claimed by the cluster. They
+	// are unknown for clusters not running in a claimed VPC.
+	VPCCIDRs []string `json:"VPCCIDRs,omitempty"`
+
+	// Autoscaling is true when the default node instance group is scaled by
+	// the cluster-autoscaler between the node min and max counts.
+	Autoscaling bool `json:"Autoscaling,omitempty"`
 }
 
 // KopsMetadataRequestedState is the requested state for kops metadata.
@@ -31,6 +55,70 @@ type KopsMetadataRequestedState struct {
 	NodeInstanceType   string `json:"NodeInstanceType,omitempty"`
 	NodeMinCount       int64  `json:"NodeMinCount,omitempty"`
 	NodeMaxCount       int64  `json:"NodeMaxCount,omitempty"`
+	// NodeMixedInstancesPolicy is the mixed instances policy to apply to the
+	// default node instance group. A policy without instance types removes
+	// the existing policy.
+	NodeMixedInstancesPolicy *MixedInstancesPolicy `json:"NodeMixedInstancesPolicy,omitempty"`
+	// Autoscaling tags the default node instance group for the
+	// cluster-autoscaler when the cluster is created.
+	Autoscaling bool `json:"Autoscaling,omitempty"`
+	// NodeGroups are the additional node groups to create or update.
+	NodeGroups []*KopsNodeGroup `json:"NodeGroups,omitempty"`
+	// DeleteNodeGroups are the names of the additional node groups to delete.
+	DeleteNodeGroups []string `json:"DeleteNodeGroups,omitempty"`
+	// UpgradeStrategy rolls the instance groups in stages when set.
+	UpgradeStrategy *KopsUpgradeStrategy `json:"UpgradeStrategy,omitempty"`
+	// UpgradeProgress tracks the progress of a staged upgrade.
+	UpgradeProgress *KopsUpgradeProgress `json:"UpgradeProgress,omitempty"`
+}
+
+// IsStagedUpgrade returns true if the change request is an upgrade rolling
+// the instance groups in stages.
+func (rs *KopsMetadataRequestedState) IsStagedUpgrade() bool {
+	return rs != nil && rs.UpgradeStrategy != nil
+}
+
+// KopsNodeGroup is an additional kops worker instance group, also known as a
+// node pool, that workloads can be scheduled on with a node selector.
+type KopsNodeGroup struct {
+	Name         string
+	InstanceType string
+	MinCount     int64
+	MaxCount     int64
+}
+
+// CanScale returns true if the node group can grow beyond its min count.
+func (ng *KopsNodeGroup) CanScale() bool {
+	return ng.MaxCount > ng.MinCount
+}
+
+// NodeSelector returns the node selector that schedules pods on the node
+// group. The label is applied to every node by kops.
+func (ng *KopsNodeGroup) NodeSelector() map[string]string {
+	return map[string]string{KopsInstanceGroupLabel: ng.Name}
+}
+
+// KopsInstanceGroupLabel is the node label kops sets to the name of the
+// instance group the node belongs to.
+const KopsInstanceGroupLabel = "kops.k8s.io/instancegroup"
+
+// GetNodeGroup returns the additional node group with the given name or nil
+// if there is none.
+func (km *KopsMetadata) GetNodeGroup(name string) *KopsNodeGroup {
+	for _, nodeGroup := range km.NodeGroups {
+		if nodeGroup.Name == name {
+			return nodeGroup
+		}
+	}
+
+	return nil
+}
+
+// IsAutoscaling returns true if the default node instance group of the
+// cluster is scaled by the cluster-autoscaler. The autoscaler of imported
+// clusters is not managed by the provisioner.
+func (km *KopsMetadata) IsAutoscaling() bool {
+	return km.Autoscaling && !km.Imported
 }
 
 // ClearChangeRequest clears the kops metadata change request.
@@ -0,0 +1,107 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DefaultSpotAllocationStrategy is the strategy used to pick spot instance
+// pools. Capacity optimized pools have the lowest chance of interruption.
+const DefaultSpotAllocationStrategy = "capacity-optimized"
+
+// MixedInstancesPolicy describes how a kops instance group mixes on-demand
+// and spot instances of several instance types.
+type MixedInstancesPolicy struct {
+	// Instances are the instance types the instance group can launch. The
+	// first instance type is also used as the machine type.
+	Instances []string
+	// OnDemandBase is the number of instances that are always on-demand.
+	OnDemandBase int64
+	// SpotPercentage is the percentage of instances above the on-demand
+	// base that are spot instances.
+	SpotPercentage int64
+}
+
+// HasSpot returns true if the policy can launch spot instances.
+func (p *MixedInstancesPolicy) HasSpot() bool {
+	return p != nil && p.SpotPercentage > 0
+}
+
+// Equal returns true if both policies are the same.
+func (p *MixedInstancesPolicy) Equal(other *MixedInstancesPolicy) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	if p.OnDemandBase != other.OnDemandBase || p.SpotPercentage != other.SpotPercentage {
+		return false
+	}
+	if len(p.Instances) != len(other.Instances) {
+		return false
+	}
+	for i := range p.Instances {
+		if p.Instances[i] != other.Instances[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MixedInstancesPolicyRequest specifies the parameters of a mixed instances
+// policy. A request without instance types removes the policy.
+type MixedInstancesPolicyRequest struct {
+	Instances      []string `json:"instances,omitempty"`
+	OnDemandBase   int64    `json:"on-demand-base,omitempty"`
+	SpotPercentage int64    `json:"spot-percentage,omitempty"`
+}
+
+// Validate validates the values of a mixed instances policy request.
+func (request *MixedInstancesPolicyRequest) Validate() error {
+	for _, instance := range request.Instances {
+		if len(instance) == 0 {
+			return errors.New("mixed instances policy instance type cannot be blank")
+		}
+	}
+	if request.OnDemandBase < 0 {
+		return errors.Errorf("mixed instances policy on-demand base (%d) can't be negative", request.OnDemandBase)
+	}
+	if request.SpotPercentage < 0 || request.SpotPercentage > 100 {
+		return errors.Errorf("mixed instances policy spot percentage (%d) must be between 0 and 100", request.SpotPercentage)
+	}
+	if len(request.Instances) == 0 && (request.OnDemandBase != 0 || request.SpotPercentage != 0) {
+		return errors.New("mixed instances policy requires at least one instance type")
+	}
+
+	return nil
+}
+
+// ValidateNodeInstanceType checks that the node instance type requested along
+// with the policy is the first instance type of the policy. Kops uses the
+// first instance type of a policy as the machine type of the instance group,
+// so a different node instance type would be silently overwritten.
+func (request *MixedInstancesPolicyRequest) ValidateNodeInstanceType(nodeInstanceType string) error {
+	if len(request.Instances) == 0 || nodeInstanceType == request.Instances[0] {
+		return nil
+	}
+
+	return errors.Errorf("node instance type %s must be the first mixed instances policy instance type %s", nodeInstanceType, request.Instances[0])
+}
+
+// ToMixedInstancesPolicy converts the request into a MixedInstancesPolicy.
+// An empty policy is returned when the request removes the policy.
+func (request *MixedInstancesPolicyRequest) ToMixedInstancesPolicy() *MixedInstancesPolicy {
+	return &MixedInstancesPolicy{
+		Instances:      request.Instances,
+		OnDemandBase:   request.OnDemandBase,
+		SpotPercentage: request.SpotPercentage,
+	}
+}
+
+// IsRemoval returns true if the policy describes the removal of a mixed
+// instances policy.
+func (p *MixedInstancesPolicy) IsRemoval() bool {
+	return p != nil && len(p.Instances) == 0
+}
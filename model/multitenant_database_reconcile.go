@@ -0,0 +1,129 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// MultitenantDatabaseReconcileStoreInterface is the interface necessary for
+// SQLStore functionality to reconcile multitenant databases against the
+// resources that exist in AWS.
+type MultitenantDatabaseReconcileStoreInterface interface {
+	GetInstallation(installationID string, includeGroupConfig, includeGroupConfigOverrides bool) (*Installation, error)
+	GetClusterInstallations(filter *ClusterInstallationFilter) ([]*ClusterInstallation, error)
+	GetCluster(id string) (*Cluster, error)
+	GetMultitenantDatabase(multitenantdatabaseID string) (*MultitenantDatabase, error)
+	GetMultitenantDatabases(filter *MultitenantDatabaseFilter) ([]*MultitenantDatabase, error)
+	UpdateMultitenantDatabase(multitenantDatabase *MultitenantDatabase) error
+	LockMultitenantDatabase(multitenantdatabaseID, lockerID string) (bool, error)
+	UnlockMultitenantDatabase(multitenantdatabaseID, lockerID string, force bool) (bool, error)
+}
+
+// ReconcileMultitenantDatabasesRequest specifies the parameters for a
+// multitenant database reconciliation.
+type ReconcileMultitenantDatabasesRequest struct {
+	// Repair indicates that inconsistencies should be fixed instead of only
+	// being reported.
+	Repair bool
+}
+
+// NewReconcileMultitenantDatabasesRequestFromReader will create a
+// ReconcileMultitenantDatabasesRequest from an io.Reader with JSON data.
+func NewReconcileMultitenantDatabasesRequestFromReader(reader io.Reader) (*ReconcileMultitenantDatabasesRequest, error) {
+	var reconcileRequest ReconcileMultitenantDatabasesRequest
+	err := json.NewDecoder(reader).Decode(&reconcileRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode reconcile multitenant databases request")
+	}
+
+	return &reconcileRequest, nil
+}
+
+// MultitenantDatabaseReconcileReport is the result of reconciling all
+// multitenant databases.
+type MultitenantDatabaseReconcileReport struct {
+	Repair    bool
+	Databases []*MultitenantDatabaseReconcileResult
+}
+
+// MultitenantDatabaseReconcileResult describes the inconsistencies found for a
+// single multitenant database.
+type MultitenantDatabaseReconcileResult struct {
+	ID           string
+	VpcID        string
+	DatabaseType string
+
+	// StoredInstallations is the number of installations recorded on the
+	// multitenant database in the datastore.
+	StoredInstallations int
+	// CounterTag is the installation counter tag value found on the RDS
+	// cluster.
+	CounterTag int
+	// DeletedInstallations are recorded on the multitenant database, but are
+	// deleted.
+	DeletedInstallations []string `json:",omitempty"`
+	// UnknownInstallations are recorded on the multitenant database, but don't
+	// exist in the datastore. They are never repaired automatically.
+	UnknownInstallations []string `json:",omitempty"`
+	// MissingDatabases are installations recorded on the multitenant database
+	// without a logical database on the RDS cluster.
+	MissingDatabases []string `json:",omitempty"`
+	// OrphanedDatabases are logical databases on the RDS cluster belonging to
+	// deleted installations.
+	OrphanedDatabases []string `json:",omitempty"`
+	// UntrackedDatabases are logical databases on the RDS cluster that are
+	// not recorded on the multitenant database, including those of
+	// installations unknown to the datastore, such as databases of another
+	// environment sharing the RDS cluster or created by hand. They are never
+	// dropped automatically.
+	UntrackedDatabases []string `json:",omitempty"`
+	// OrphanedSecrets are installation secrets for the RDS cluster belonging
+	// to deleted installations.
+	OrphanedSecrets []string `json:",omitempty"`
+	// UntrackedSecrets are installation secrets for the RDS cluster belonging
+	// to installations unknown to the datastore. They are never deleted
+	// automatically.
+	UntrackedSecrets []string `json:",omitempty"`
+
+	Repaired bool
+	Error    string `json:",omitempty"`
+}
+
+// CounterTagDrift returns true if the RDS counter tag doesn't match the number
+// of installations in the datastore.
+func (r *MultitenantDatabaseReconcileResult) CounterTagDrift() bool {
+	return r.CounterTag != r.StoredInstallations
+}
+
+// IsConsistent returns true if no inconsistencies were found.
+func (r *MultitenantDatabaseReconcileResult) IsConsistent() bool {
+	return len(r.Error) == 0 &&
+		!r.CounterTagDrift() &&
+		len(r.DeletedInstallations) == 0 &&
+		len(r.UnknownInstallations) == 0 &&
+		len(r.MissingDatabases) == 0 &&
+		len(r.OrphanedDatabases) == 0 &&
+		len(r.UntrackedDatabases) == 0 &&
+		len(r.OrphanedSecrets) == 0 &&
+		len(r.UntrackedSecrets) == 0
+}
+
+// MultitenantDatabaseReconcileReportFromReader decodes a json-encoded
+// reconcile report from the given io.Reader.
+func MultitenantDatabaseReconcileReportFromReader(reader io.Reader) (*MultitenantDatabaseReconcileReport, error) {
+	report := &MultitenantDatabaseReconcileReport{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(report)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ProvisionerKops is the kops cluster provisioner.
+	ProvisionerKops = "kops"
+	// ProvisionerEKS is the managed EKS cluster provisioner.
+	ProvisionerEKS = "eks"
+	// ProvisionerExternal manages clusters imported with a kubeconfig. The
+	// infrastructure of external clusters is not managed by the provisioner.
+	ProvisionerExternal = "external"
+)
+
+// CheckProvisioner normalizes the given provisioner, returning an error if invalid.
+func CheckProvisioner(provisioner string) (string, error) {
+	provisioner = strings.ToLower(provisioner)
+	if provisioner == ProvisionerKops || provisioner == ProvisionerEKS {
+		return provisioner, nil
+	}
+
+	return provisioner, fmt.Errorf("unsupported provisioner %s", provisioner)
+}
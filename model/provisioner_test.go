@@ -0,0 +1,39 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-cloud/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProvisioner(t *testing.T) {
+	var provisionerTests = []struct {
+		provisioner               string
+		expectedProvisionerString string
+		expectError               bool
+	}{
+		{"kops", "kops", false},
+		{"KOPS", "kops", false},
+		{"eks", "eks", false},
+		{"EKS", "eks", false},
+		{"gke", "gke", true},
+		{"", "", true},
+	}
+
+	for _, tt := range provisionerTests {
+		t.Run(tt.provisioner, func(t *testing.T) {
+			provisioner, err := model.CheckProvisioner(tt.provisioner)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedProvisionerString, provisioner)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	// TypeVPCPool is the string value that represents the pool of pre-tagged
+	// VPCs claimed by clusters.
+	TypeVPCPool = "vpc_pool"
+	// VPCPoolStateLowCapacity is the webhook state sent when few VPCs are
+	// left to be claimed by new clusters.
+	VPCPoolStateLowCapacity = "low-capacity"
+)
+
+// VPC is a VPC tagged for use by the provisioner along with its claim status
+// and whether its subnets and security groups are correctly tagged.
+type VPC struct {
+	ID            string
+	Region        string `json:"Region,omitempty"`
+	AssumeRoleARN string `json:"AssumeRoleARN,omitempty"`
+	Available     bool
+	ClusterID     string `json:"ClusterID,omitempty"`
+	Owner         string `json:"Owner,omitempty"`
+	Valid         bool
+	Error         string `json:"Error,omitempty"`
+}
+
+// IsClaimable returns true if the VPC can be claimed by a new cluster.
+func (v *VPC) IsClaimable() bool {
+	return v.Available && v.Valid
+}
+
+// CountClaimableVPCs returns the number of VPCs that can be claimed by new
+// clusters.
+func CountClaimableVPCs(vpcs []*VPC) int {
+	var count int
+	for _, vpc := range vpcs {
+		if vpc.IsClaimable() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// VPCsFromReader decodes a json-encoded list of VPCs from the given
+// io.Reader.
+func VPCsFromReader(reader io.Reader) ([]*VPC, error) {
+	vpcs := []*VPC{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&vpcs)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return vpcs, nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountClaimableVPCs(t *testing.T) {
+	vpcs := []*VPC{
+		{ID: "vpc1", Available: true, Valid: true},
+		{ID: "vpc2", Available: true, Valid: false, Error: "private subnet list is empty"},
+		{ID: "vpc3", Available: false, Valid: true, ClusterID: "cluster1"},
+		{ID: "vpc4", Available: true, Valid: true},
+	}
+
+	assert.True(t, vpcs[0].IsClaimable())
+	assert.False(t, vpcs[1].IsClaimable())
+	assert.False(t, vpcs[2].IsClaimable())
+	assert.Equal(t, 2, CountClaimableVPCs(vpcs))
+	assert.Equal(t, 0, CountClaimableVPCs(nil))
+}
+
+func TestVPCsFromReader(t *testing.T) {
+	t.Run("empty request", func(t *testing.T) {
+		vpcs, err := VPCsFromReader(strings.NewReader(``))
+		require.NoError(t, err)
+		require.Equal(t, []*VPC{}, vpcs)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		vpcs, err := VPCsFromReader(strings.NewReader(`{test`))
+		require.Error(t, err)
+		require.Nil(t, vpcs)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		vpcs, err := VPCsFromReader(strings.NewReader(`[{"ID":"vpc1","Available":false,"ClusterID":"cluster1","Valid":true}]`))
+		require.NoError(t, err)
+		require.Equal(t, []*VPC{{ID: "vpc1", ClusterID: "cluster1", Valid: true}}, vpcs)
+	})
+}
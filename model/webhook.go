@@ -17,6 +17,9 @@ const (
 	// TypeClusterInstallation is the string value that represents a cluster
 	// installation.
 	TypeClusterInstallation = "cluster_installaton"
+	// TypeInstallationDomain is the string value that represents a custom
+	// domain of an installation.
+	TypeInstallationDomain = "installation_domain"
 )
 
 // Webhook is